@@ -4,6 +4,7 @@ go 1.23
 
 require (
 	github.com/spf13/cobra v1.10.1
+	golang.org/x/text v0.22.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
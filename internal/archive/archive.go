@@ -0,0 +1,162 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// progressEvery is how many files are archived between progress reports
+const progressEvery = 1000
+
+// ExistsError is returned when the archive file already exists and may not be overwritten
+type ExistsError struct {
+	Path string
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("archive already exists: %s (use --force to overwrite)", e.Path)
+}
+
+// Stats describes a created archive
+type Stats struct {
+	Files int   // Regular files and symlinks archived
+	Bytes int64 // Uncompressed size of the archived files
+}
+
+// Create writes a gzip-compressed tarball of the directory dir to path
+// The top-level ".git" entry (the file linking a worktree to its repository) is left out.
+// Entries are named relative to dir, below a directory named after dir. Progress for large
+// trees is reported on progress (nil for none). An existing file at path is only replaced
+// if overwrite is set; a failed archive never leaves a partial file behind.
+func Create(path, dir string, progress io.Writer, overwrite bool) (Stats, error) {
+	if _, err := os.Lstat(path); err == nil && !overwrite {
+		return Stats{}, &ExistsError{Path: path}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Stats{}, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	stats, err := writeTarGz(tmp, dir, progress)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Stats{}, fmt.Errorf("failed to create archive: %w", err)
+	}
+	return stats, nil
+}
+
+// writeTarGz writes the tree below dir to w as a gzip-compressed tarball
+func writeTarGz(w io.Writer, dir string, progress io.Writer) (Stats, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	root := filepath.Base(dir)
+
+	var stats Stats
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			return nil // Sockets, devices, ...
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(filepath.Join(root, rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		if info.Mode().IsRegular() {
+			if err := copyFile(tw, path, hdr.Size); err != nil {
+				return err
+			}
+			stats.Bytes += hdr.Size
+		}
+		stats.Files++
+		if progress != nil && stats.Files%progressEvery == 0 {
+			fmt.Fprintf(progress, "  archived %d files (%s)...\n", stats.Files, FormatSize(stats.Bytes))
+		}
+		return nil
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return Stats{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Stats{}, err
+	}
+	return stats, nil
+}
+
+// copyFile copies size bytes of the file at path to w (the size recorded in its tar header)
+func copyFile(w io.Writer, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(w, f, size)
+	return err
+}
+
+// FormatSize formats a byte count for humans, e.g. "12.3 MB"
+func FormatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,131 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// readArchive returns the entry names and file contents of the tarball at path
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	entries := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(content)
+	}
+	return entries
+}
+
+func TestCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "feature")
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".git":        "gitdir: /repo/.git/worktrees/feature\n",
+		"README.md":   "# Feature\n",
+		"src/main.go": "package main\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "out", "feature.tar.gz")
+	stats, err := Create(path, dir, nil, false)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if stats.Files != 2 {
+		t.Errorf("Create() archived %d files, want 2", stats.Files)
+	}
+
+	entries := readArchive(t, path)
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"feature/", "feature/README.md", "feature/src/", "feature/src/main.go"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("archive entries = %q, want %q", names, want)
+	}
+	if entries["feature/src/main.go"] != "package main\n" {
+		t.Errorf("main.go content = %q", entries["feature/src/main.go"])
+	}
+
+	// An existing archive is only replaced with overwrite
+	var existsErr *ExistsError
+	if _, err := Create(path, dir, nil, false); !errors.As(err, &existsErr) {
+		t.Errorf("Create() over an existing archive error = %v, want ExistsError", err)
+	}
+	if _, err := Create(path, dir, nil, true); err != nil {
+		t.Errorf("Create() with overwrite error = %v", err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*tmp*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %q", leftovers)
+	}
+}
+
+func TestCreateProgress(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < progressEvery; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d", i)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var progress bytes.Buffer
+	if _, err := Create(filepath.Join(t.TempDir(), "big.tar.gz"), dir, &progress, false); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.Contains(progress.String(), "archived 1000 files (1.0 kB)") {
+		t.Errorf("progress = %q, want a report after %d files", progress.String(), progressEvery)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:             "0 B",
+		999:           "999 B",
+		1500:          "1.5 kB",
+		12_300_000:    "12.3 MB",
+		4_000_000_000: "4.0 GB",
+	}
+	for n, want := range tests {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
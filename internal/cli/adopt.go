@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// NotSameRepositoryError represents an error when the checkout to adopt is a clone of another repository
+type NotSameRepositoryError struct {
+	Path string
+}
+
+func (e *NotSameRepositoryError) Error() string {
+	return fmt.Sprintf("%s is not a checkout of this repository (different origin and no common history)", e.Path)
+}
+
+// CheckoutDirtyError represents an error when the checkout to adopt has uncommitted changes
+type CheckoutDirtyError struct {
+	Path string
+}
+
+func (e *CheckoutDirtyError) Error() string {
+	return fmt.Sprintf("%s has uncommitted changes: commit them first, or use --force to carry them over", e.Path)
+}
+
+// BranchDivergedError represents an error when a branch of the checkout can't be fast-forwarded to in this repository
+type BranchDivergedError struct {
+	Branch string
+	Path   string
+}
+
+func (e *BranchDivergedError) Error() string {
+	return fmt.Sprintf("branch '%s' in %s is not ahead of the branch in this repository\nRename it in the checkout first: git -C %s branch -m %s <new-name>",
+		e.Branch, e.Path, e.Path, e.Branch)
+}
+
+type adoptCmdConfig struct {
+	force bool
+}
+
+// adoptResult describes what wt adopt did
+type adoptResult struct {
+	Path    string   // The adopted checkout, now a worktree
+	Branch  string   // Empty for a detached HEAD
+	Fetched bool     // The branch was fetched from the checkout
+	Backup  string   // Where the original checkout was moved
+	Carried []string // Uncommitted changes carried over with --force
+}
+
+func newAdoptCmd() *cobra.Command {
+	cfg := &adoptCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "adopt <dir>",
+		Short: "Turn a separate clone into a worktree",
+		Long: `Turn a separate clone of this repository into a linked worktree.
+
+The directory must be a checkout of the same repository (same origin URL or
+common history). Its current branch is fetched into this repository (an existing
+branch is only fast-forwarded), the original checkout is moved aside to
+<dir>.orig, and a worktree for the branch is created at <dir>.
+
+The original checkout is kept so nothing is lost (e.g. ignored files or stashes);
+delete it once you've checked it. A checkout with uncommitted changes is refused
+unless --force, which carries the changes over to the new worktree.
+
+Examples:
+  wt adopt ../myproject-old
+  wt adopt ../myproject-old --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runAdoptWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Adopt even with uncommitted changes, carrying them over")
+
+	return cmd
+}
+
+var adoptCmd = newAdoptCmd()
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+}
+
+func runAdoptWithConfig(cmd *cobra.Command, args []string, cfg *adoptCmdConfig) error {
+	ctx := cmd.Context()
+
+	dir, err := gitx.AbsPath(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	result, err := adoptCheckout(ctx, cmd.ErrOrStderr(), dir, cfg.force)
+	if err != nil {
+		return err
+	}
+	printAdoptSuccess(cmd.OutOrStdout(), result, flagQuiet)
+	return nil
+}
+
+// adoptCheckout converts the separate checkout at dir into a worktree of the current repository
+func adoptCheckout(ctx context.Context, w io.Writer, dir string, force bool) (*adoptResult, error) {
+	checkout, err := gitx.InspectCheckout(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if !gitx.SamePath(checkout.Root, dir) {
+		return nil, fmt.Errorf("%s is inside the checkout at %s: adopt that directory instead", dir, checkout.Root)
+	}
+
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if gitx.SamePath(wt.Path, checkout.Root) {
+			return nil, fmt.Errorf("%s is already a worktree of this repository", checkout.Root)
+		}
+	}
+
+	same, err := isSameRepository(ctx, checkout.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare repositories: %w", err)
+	}
+	if !same {
+		return nil, &NotSameRepositoryError{Path: checkout.Root}
+	}
+
+	status, err := gitx.GetStatus(ctx, checkout.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	if status.IsDirty() && !force {
+		return nil, &CheckoutDirtyError{Path: checkout.Root}
+	}
+
+	result := &adoptResult{Path: checkout.Root, Branch: checkout.Branch}
+	if result.Fetched, err = fetchCheckoutHead(ctx, checkout); err != nil {
+		return nil, err
+	}
+
+	result.Backup = backupPath(checkout.Root)
+	if err := os.Rename(checkout.Root, result.Backup); err != nil {
+		return nil, fmt.Errorf("failed to move the checkout aside: %w", err)
+	}
+
+	if checkout.Branch != "" {
+		err = gitx.Add(ctx, checkout.Root, checkout.Branch, "", false)
+	} else {
+		err = gitx.AddDetached(ctx, checkout.Root, checkout.HEAD)
+	}
+	if err != nil {
+		_ = os.RemoveAll(checkout.Root)             // Whatever git created before failing
+		_ = os.Rename(result.Backup, checkout.Root) // Best-effort rollback
+		_ = gitx.Prune(ctx)
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	if status.IsDirty() {
+		result.Carried = carryOverChanges(ctx, w, result.Backup, checkout.Root)
+	}
+	return result, nil
+}
+
+// isSameRepository reports whether the checkout at dir is a clone of the current repository:
+// both have the same origin URL, or the checkout's history starts from a commit known here
+func isSameRepository(ctx context.Context, dir string) (bool, error) {
+	if origin := gitx.RemoteURL(ctx, dir, "origin"); origin != "" && normalizeRemoteURL(origin) == normalizeRemoteURL(gitx.RemoteURL(ctx, "", "origin")) {
+		return true, nil
+	}
+
+	roots, err := gitx.RootCommits(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	for _, root := range roots {
+		if gitx.CommitExists(ctx, root) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// normalizeRemoteURL strips the parts that don't change which repository a URL points to
+func normalizeRemoteURL(url string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+}
+
+// fetchCheckoutHead makes the commit checked out in checkout available in the current repository
+// The branch is fetched unless it already exists here at the same commit. An existing branch is
+// only fast-forwarded, and not while a worktree has it checked out. It reports whether anything
+// was fetched.
+func fetchCheckoutHead(ctx context.Context, checkout *gitx.Checkout) (bool, error) {
+	if checkout.Branch == "" {
+		if gitx.CommitExists(ctx, checkout.HEAD) {
+			return false, nil
+		}
+		if err := gitx.FetchFromCheckout(ctx, checkout.Root, "HEAD"); err != nil {
+			return false, fmt.Errorf("failed to fetch from %s: %w", checkout.Root, err)
+		}
+		return true, nil
+	}
+
+	branch := checkout.Branch
+	if existing, err := gitx.FindWorktreeByBranch(ctx, branch); err == nil && existing != nil {
+		return false, newBranchInUseError(branch, existing.Path)
+	}
+
+	exists, err := gitx.BranchExists(ctx, branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check branch existence: %w", err)
+	}
+	if exists {
+		if tip, err := gitx.ResolveCommit(ctx, "refs/heads/"+branch); err == nil && tip == checkout.HEAD {
+			return false, nil
+		}
+	}
+
+	// Without "+", git only fast-forwards an existing branch
+	if err := gitx.FetchFromCheckout(ctx, checkout.Root, "refs/heads/"+branch+":refs/heads/"+branch); err != nil {
+		var gitErr *gitx.GitError
+		if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "non-fast-forward") {
+			return false, &BranchDivergedError{Branch: branch, Path: checkout.Root}
+		}
+		return false, fmt.Errorf("failed to fetch from %s: %w", checkout.Root, err)
+	}
+	return true, nil
+}
+
+// carryOverChanges copies the uncommitted changes (including untracked files) from the original
+// checkout to the new worktree and returns the changed files. Failures only produce a warning:
+// the changes are still in the original checkout.
+func carryOverChanges(ctx context.Context, w io.Writer, from, to string) []string {
+	patch, err := os.CreateTemp("", "wt-adopt-*.patch")
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to carry over uncommitted changes (they are still in %s): %v\n", from, err)
+		return nil
+	}
+	patch.Close()
+	defer os.Remove(patch.Name())
+
+	files, err := gitx.WritePatch(ctx, from, patch.Name(), true)
+	if err == nil && len(files) > 0 {
+		err = gitx.ApplyPatch(ctx, to, patch.Name())
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to carry over uncommitted changes (they are still in %s): %v\n", from, err)
+		return nil
+	}
+	return files
+}
+
+// backupPath returns a free path next to dir to move the original checkout to, e.g. "proj-old.orig"
+func backupPath(dir string) string {
+	path := dir + ".orig"
+	for i := 2; pathExists(path); i++ {
+		path = fmt.Sprintf("%s.orig-%d", dir, i)
+	}
+	return path
+}
+
+// Output functions
+
+func printAdoptSuccess(w io.Writer, result *adoptResult, quiet bool) {
+	if quiet {
+		return
+	}
+
+	fmt.Fprintf(w, "✓ Adopted %s as a worktree\n", result.Path)
+	if result.Branch != "" {
+		fetched := ""
+		if result.Fetched {
+			fetched = " (fetched from the checkout)"
+		}
+		fmt.Fprintf(w, "  Branch: %s%s\n", result.Branch, fetched)
+	}
+	if len(result.Carried) > 0 {
+		fmt.Fprintf(w, "  Carried over %s\n", pluralize(len(result.Carried), "changed file"))
+	}
+	fmt.Fprintf(w, "  Original checkout: %s (delete it once you've checked nothing is missing)\n", result.Backup)
+}
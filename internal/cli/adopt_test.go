@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// cloneForTest clones repoPath to a new directory, checking out branch
+func cloneForTest(t *testing.T, repoPath, branch string) string {
+	t.Helper()
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	runGitForTest(t, filepath.Dir(clonePath), "clone", "-q", "-b", branch, repoPath, clonePath)
+	return clonePath
+}
+
+// assertAdopted checks that path is now a worktree with branch checked out
+func assertAdopted(t *testing.T, path, branch string) {
+	t.Helper()
+	wt, err := gitx.FindWorktreeByBranch(context.Background(), branch)
+	if err != nil || wt == nil {
+		t.Fatalf("no worktree for %s after adopting: %v", branch, err)
+	}
+	if !gitx.SamePath(wt.Path, path) {
+		t.Errorf("worktree for %s is at %s, want %s", branch, wt.Path, path)
+	}
+}
+
+func TestAdoptCheckoutSameBranch(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "feature")
+	clonePath := cloneForTest(t, repoPath, "feature")
+
+	result, err := adoptCheckout(context.Background(), io.Discard, clonePath, false)
+	if err != nil {
+		t.Fatalf("adoptCheckout() returned error: %v", err)
+	}
+	if result.Fetched {
+		t.Error("adoptCheckout() fetched a branch that was already up to date")
+	}
+	assertAdopted(t, clonePath, "feature")
+	if result.Backup != clonePath+".orig" || !pathExists(filepath.Join(result.Backup, ".git")) {
+		t.Errorf("original checkout should be kept at %s.orig, got %q", clonePath, result.Backup)
+	}
+}
+
+func TestAdoptCheckoutDifferentBranch(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	clonePath := cloneForTest(t, repoPath, "main")
+	runGitForTest(t, clonePath, "checkout", "-q", "-b", "new-work")
+	commitFileForTest(t, clonePath, "work.txt")
+
+	result, err := adoptCheckout(context.Background(), io.Discard, clonePath, false)
+	if err != nil {
+		t.Fatalf("adoptCheckout() returned error: %v", err)
+	}
+	if !result.Fetched {
+		t.Error("adoptCheckout() should have fetched the branch from the checkout")
+	}
+	assertAdopted(t, clonePath, "new-work")
+	if !pathExists(filepath.Join(clonePath, "work.txt")) {
+		t.Error("the adopted worktree is missing the checkout's commit")
+	}
+}
+
+func TestAdoptCheckoutDirty(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "feature")
+	clonePath := cloneForTest(t, repoPath, "feature")
+	if err := os.WriteFile(filepath.Join(clonePath, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(clonePath, "notes.txt"), []byte("notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var dirtyErr *CheckoutDirtyError
+	if _, err := adoptCheckout(ctx, io.Discard, clonePath, false); !errors.As(err, &dirtyErr) {
+		t.Fatalf("adoptCheckout() = %v, want CheckoutDirtyError", err)
+	}
+	if !pathExists(filepath.Join(clonePath, "notes.txt")) || pathExists(clonePath+".orig") {
+		t.Fatal("a refused adopt should leave the checkout untouched")
+	}
+
+	result, err := adoptCheckout(ctx, io.Discard, clonePath, true)
+	if err != nil {
+		t.Fatalf("adoptCheckout() with force returned error: %v", err)
+	}
+	if want := []string{"README.md", "notes.txt"}; !reflect.DeepEqual(result.Carried, want) {
+		t.Errorf("carried over %q, want %q", result.Carried, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(clonePath, "README.md")); string(content) != "# Changed\n" {
+		t.Errorf("README.md = %q, want the uncommitted change", content)
+	}
+}
+
+func TestAdoptCheckoutRefused(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+
+	t.Run("diverged branch", func(t *testing.T) {
+		runGitForTest(t, repoPath, "branch", "diverged")
+		clonePath := cloneForTest(t, repoPath, "diverged")
+		commitFileForTest(t, clonePath, "clone.txt")
+		runGitForTest(t, repoPath, "checkout", "-q", "diverged")
+		commitFileForTest(t, repoPath, "repo.txt")
+		runGitForTest(t, repoPath, "checkout", "-q", "main")
+
+		var divergedErr *BranchDivergedError
+		if _, err := adoptCheckout(ctx, io.Discard, clonePath, false); !errors.As(err, &divergedErr) {
+			t.Errorf("adoptCheckout() = %v, want BranchDivergedError", err)
+		}
+	})
+
+	t.Run("branch in use", func(t *testing.T) {
+		clonePath := cloneForTest(t, repoPath, "main")
+
+		var inUseErr *BranchInUseError
+		if _, err := adoptCheckout(ctx, io.Discard, clonePath, false); !errors.As(err, &inUseErr) {
+			t.Errorf("adoptCheckout() = %v, want BranchInUseError", err)
+		}
+	})
+
+	t.Run("other repository", func(t *testing.T) {
+		otherPath := filepath.Join(t.TempDir(), "other")
+		runGitForTest(t, filepath.Dir(otherPath), "init", "-q", "-b", "other", otherPath)
+		commitFileForTest(t, otherPath, "other.txt")
+
+		var notSameErr *NotSameRepositoryError
+		if _, err := adoptCheckout(ctx, io.Discard, otherPath, false); !errors.As(err, &notSameErr) {
+			t.Errorf("adoptCheckout() = %v, want NotSameRepositoryError", err)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/archive"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+type archiveCmdConfig struct {
+	dir   string
+	force bool
+	match matchOptions
+}
+
+func newArchiveCmd() *cobra.Command {
+	cfg := &archiveCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "archive [query]",
+		Short: "Save a worktree's files to a tarball",
+		Long: `Save the working directory of a worktree, including uncommitted and untracked
+files, to <branch>-<date>.tar.gz. The .git link file is left out.
+
+If query is not specified, select interactively. To archive a worktree right
+before removing it, use wt clean --archive <dir>.
+
+Examples:
+  wt archive feature                    # Writes ./feature-2024-05-01.tar.gz
+  wt archive feature --dir ~/wt-archive
+  wt archive feature --force            # Overwrite an existing archive`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runArchiveWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.dir, "dir", ".", "Directory to write the archive to")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Overwrite an existing archive")
+	addSelectionFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+var archiveCmd = newArchiveCmd()
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchiveWithConfig(cmd *cobra.Command, args []string, cfg *archiveCmdConfig) error {
+	ctx := cmd.Context()
+
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	worktrees := selectableWorktrees(all)
+	index, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), createDisplayItems(worktrees), query, selectOptions{prompt: "Select worktree to archive", match: cfg.match})
+	if err != nil {
+		return err
+	}
+
+	path, err := archiveWorktree(ctx, cmd.ErrOrStderr(), worktrees[index], cfg.dir, cfg.force)
+	if err != nil {
+		return err
+	}
+	printArchiveSuccess(cmd.OutOrStdout(), path, flagQuiet)
+	return nil
+}
+
+// archiveWorktree writes the files of wt to a tarball in dir and returns its path
+// Progress for large worktrees is written to progress.
+func archiveWorktree(ctx context.Context, progress io.Writer, wt gitx.Worktree, dir string, overwrite bool) (string, error) {
+	if !pathExists(wt.Path) {
+		return "", fmt.Errorf("cannot archive: worktree directory does not exist: %s", wt.Path)
+	}
+	absDir, err := gitx.AbsPath(ctx, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	path := filepath.Join(absDir, archiveFileName(wt, time.Now()))
+	if flagQuiet {
+		progress = nil
+	} else {
+		fmt.Fprintf(progress, "Archiving %s...\n", wt.Path)
+	}
+	if _, err := archive.Create(path, wt.Path, progress, overwrite); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// archiveFileName returns the archive file name for wt, e.g. "feature-login-2024-05-01.tar.gz"
+func archiveFileName(wt gitx.Worktree, now time.Time) string {
+	name := naming.Sanitize(wt.Branch)
+	if wt.IsDetached || name == "" {
+		head := wt.HEAD
+		if len(head) > 7 {
+			head = head[:7]
+		}
+		name = "detached-" + head
+	}
+	return fmt.Sprintf("%s-%s.tar.gz", name, now.Format("2006-01-02"))
+}
+
+// Output functions
+
+func printArchiveSuccess(w io.Writer, path string, quiet bool) {
+	if quiet {
+		fmt.Fprintln(w, path)
+		return
+	}
+	fmt.Fprintf(w, "✓ Archived to %s\n", path)
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/archive"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestArchiveFileName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		wt   gitx.Worktree
+		want string
+	}{
+		{"branch", gitx.Worktree{Branch: "main"}, "main-2024-05-01.tar.gz"},
+		{"branch with slash", gitx.Worktree{Branch: "feature/login"}, "feature-login-2024-05-01.tar.gz"},
+		{"detached", gitx.Worktree{IsDetached: true, HEAD: "0123456789abcdef"}, "detached-0123456-2024-05-01.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := archiveFileName(tt.wt, now); got != tt.want {
+				t.Errorf("archiveFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveWorktree(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-q", "-b", "feature", wtPath)
+	wt := gitx.Worktree{Path: wtPath, Branch: "feature"}
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	path, err := archiveWorktree(ctx, io.Discard, wt, dir, false)
+	if err != nil {
+		t.Fatalf("archiveWorktree() returned error: %v", err)
+	}
+	if want := filepath.Join(dir, archiveFileName(wt, time.Now())); path != want {
+		t.Errorf("archiveWorktree() = %q, want %q", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("archive was not written: %v", err)
+	}
+
+	// An existing archive is only replaced with --force
+	var existsErr *archive.ExistsError
+	_, err = archiveWorktree(ctx, io.Discard, wt, dir, false)
+	if !errors.As(err, &existsErr) {
+		t.Errorf("archiveWorktree() again = %v, want ExistsError", err)
+	}
+	if errorType(err) != "archive_exists" {
+		t.Errorf("errorType() = %q, want archive_exists", errorType(err))
+	}
+	if _, err := archiveWorktree(ctx, io.Discard, wt, dir, true); err != nil {
+		t.Errorf("archiveWorktree() with overwrite returned error: %v", err)
+	}
+}
@@ -1,15 +1,19 @@
 package cli
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/journal"
 )
 
 // NoRemovableWorktreesError represents an error when no removable worktrees are found
@@ -27,39 +31,96 @@ func (e *WorktreeRemovalCancelledError) Error() string {
 }
 
 type cleanCmdConfig struct {
-	force      bool
-	keepBranch bool
-	yes        bool
+	force           bool
+	keepBranch      bool
+	yes             bool
+	pruneRemoteRefs bool
+	archiveDir      string
+	detachDelete    bool
+	trash           bool
+	restore         bool
+	allowProtected  bool
+	all             bool
+	merged          bool
+	pattern         string
+	iKnow           bool
+	match           matchOptions
 }
 
 func newCleanCmd() *cobra.Command {
 	cfg := &cleanCmdConfig{}
 
 	cmd := &cobra.Command{
-		Use:   "clean [query]",
+		Use:   "clean [query | --all | --merged | --pattern <glob>]",
 		Short: "Remove worktrees",
 		Long: `Remove worktrees.
 
 If query is not specified, select interactively.
 After removal, prompts to delete the branch (can be suppressed with --keep-branch).
+Branches matching clean.protected_branches (by default main, master, develop and
+the default branch) are never deleted, even with --yes, unless --allow-protected.
+A locked worktree is shown with its lock reason and only removed after confirming
+to unlock it (--yes unlocks without asking).
+
+--all, --merged and --pattern remove several worktrees at once, after listing them
+and asking once. Worktrees with uncommitted changes are skipped unless --force. If
+this shell last used wt in another repository, --yes first shows the repository
+and asks for its name (--i-know skips this).
+
+With --null (-z), the messages go to stderr and stdout only gets the removed
+path, terminated by a NUL byte.
 
 Warning: Main worktree (repository root) cannot be removed.
 
 Options:
-  --force        Force removal even with uncommitted changes
-  --keep-branch  Keep the branch
-  --yes          Skip all confirmations`,
-		Args: cobra.MaximumNArgs(1),
+  --force              Force removal even with uncommitted changes
+  --keep-branch        Keep the branch
+  --yes                Skip all confirmations
+  --prune-remote-refs  Also delete the branch's remote-tracking ref (e.g. origin/feature)
+                       if the remote branch is gone
+  --archive <dir>      Save the worktree's files to <dir>/<branch>-<date>.tar.gz
+                       before removing it (see wt archive)
+  --detach-delete      Return right away and delete the worktree's files in the
+                       background (for very large worktrees)
+  --trash              Move the worktree to the trash instead of deleting it
+                       (or set clean.use_trash)
+  --restore            Restore a worktree from the trash at its original path
+  --allow-protected    Offer to delete the branch even if it is protected
+  --all                Remove all worktrees except the main worktree
+  --merged             Remove the worktrees whose branch is merged into the default branch
+  --pattern <glob>     Remove the worktrees whose branch matches the glob (e.g. 'feature/*')
+  --i-know             Don't ask for the repository name with --yes`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
 		RunE: func(c *cobra.Command, args []string) error {
+			cfg.yes = flagYes
 			return runCleanWithConfig(c, args, cfg)
 		},
 	}
 
-	cmd.Flags().BoolVar(&cfg.force, "force", false, "Force removal even with uncommitted changes (WARNING: may lose work)")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Force removal even with uncommitted changes, overwriting an existing --archive file (WARNING: may lose work)")
 	cmd.Flags().BoolVar(&cfg.keepBranch, "keep-branch", false, "Keep the branch")
-	cmd.Flags().BoolVar(&cfg.yes, "yes", false, "Skip all confirmations")
+	cmd.Flags().BoolVar(&cfg.pruneRemoteRefs, "prune-remote-refs", false, "Delete the remote-tracking ref of the deleted branch if the remote branch is gone (or set clean.prune_remote_refs)")
+	cmd.Flags().StringVar(&cfg.archiveDir, "archive", "", "Archive the worktree to a tarball in this directory before removing it")
+	cmd.Flags().BoolVar(&cfg.detachDelete, "detach-delete", false, "Delete the worktree's files in a background process instead of waiting")
+	cmd.Flags().BoolVar(&cfg.trash, "trash", false, "Move the worktree to the trash instead of deleting it (or set clean.use_trash)")
+	cmd.Flags().BoolVar(&cfg.restore, "restore", false, "Restore a worktree moved to the trash by wt clean")
+	cmd.Flags().BoolVar(&cfg.allowProtected, "allow-protected", false, "Offer to delete the branch even if it matches clean.protected_branches")
+	cmd.MarkFlagsMutuallyExclusive("trash", "detach-delete")
+	cmd.MarkFlagsMutuallyExclusive("restore", "trash")
+	cmd.MarkFlagsMutuallyExclusive("restore", "detach-delete")
+	cmd.MarkFlagsMutuallyExclusive("restore", "archive")
+	cmd.Flags().BoolVar(&cfg.all, "all", false, "Remove all worktrees except the main worktree")
+	cmd.Flags().BoolVar(&cfg.merged, "merged", false, "Remove the worktrees whose branch is merged into the default branch")
+	cmd.Flags().StringVar(&cfg.pattern, "pattern", "", "Remove the worktrees whose branch matches this glob (e.g. 'feature/*')")
+	cmd.Flags().BoolVar(&cfg.iKnow, "i-know", false, "With --yes, don't ask to confirm the repository if this shell last used wt in another one")
+	cmd.MarkFlagsMutuallyExclusive("all", "merged", "pattern")
+	for _, batch := range []string{"all", "merged", "pattern"} {
+		cmd.MarkFlagsMutuallyExclusive("restore", batch)
+	}
+	addSelectionFlags(cmd, &cfg.match)
 
-	return cmd
+	return withJSON(cmd)
 }
 
 var cleanCmd = newCleanCmd()
@@ -72,49 +133,255 @@ func init() {
 func runCleanWithConfig(cmd *cobra.Command, args []string, cfg *cleanCmdConfig) error {
 	ctx := cmd.Context()
 	w := cmd.OutOrStdout()
+	if jsonOutput() || flagNull {
+		// Keep stdout for the JSON summary (or the removed path with --null)
+		w = cmd.ErrOrStderr()
+	}
+	progress := newProgressPrinter(w, false, flagQuiet)
 
 	query := ""
 	if len(args) > 0 {
 		query = args[0]
 	}
 
+	if cfg.restore {
+		return runRestore(cmd, query, cfg)
+	}
+	if cfg.isBatch() && query != "" {
+		return &UsageError{Err: fmt.Errorf("a query can't be combined with --all, --merged or --pattern")}
+	}
+	if _, err := path.Match(cfg.pattern, ""); err != nil {
+		return &UsageError{Err: fmt.Errorf("invalid --pattern: %w", err)}
+	}
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeRemove); err != nil {
+		return err
+	}
+
 	// Get removable worktrees
 	validWorktrees, items, err := getRemovableWorktrees(ctx)
 	if err != nil {
 		return err
 	}
+	suggestRepair(ctx, cmd.ErrOrStderr(), validWorktrees)
+
+	if cfg.isBatch() {
+		return runCleanBatch(cmd, w, progress, validWorktrees, cfg)
+	}
 
 	// Select worktree to remove
-	selectedIndex, err := selectWorktreeByQueryOrInteractive(items, query, "Select worktree to remove")
+	selectedIndex, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), items, query, selectOptions{prompt: "Select worktree to remove", match: cfg.match})
 	if err != nil {
 		return err
 	}
 
 	selected := validWorktrees[selectedIndex]
 
-	// Confirm removal
+	// git refuses to remove a locked worktree: ask to unlock it first
+	if selected.IsLocked {
+		if err := confirmUnlock(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), selected, cfg.yes); err != nil {
+			return err
+		}
+	}
+
+	// Confirm removal (prompts go to stderr: stdout may be captured, e.g. by the shell function)
 	if !cfg.yes {
-		if !confirmRemoval(w, selected) {
+		status, _ := gitx.GetStatus(ctx, selected.Path) // Zero status if unknown: git reports the problem on removal
+		confirmed, err := confirmRemoval(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), selected, status, cfg.force)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
 			return &WorktreeRemovalCancelledError{}
 		}
 	}
 
-	// Remove worktree
-	if err := removeWorktree(ctx, w, selected, cfg); err != nil {
+	result, err := cleanWorktree(cmd, w, progress, selected, cfg)
+	if err != nil {
 		return err
 	}
 
+	// Clean up stale worktree administrative files
+	_ = gitx.Prune(ctx) // Ignore error: prune is best-effort cleanup
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+	if flagNull {
+		printPath(cmd.OutOrStdout(), result.Path)
+	}
+	return nil
+}
+
+// cleanWorktree removes a worktree whose removal was confirmed, and then its branch if appropriate
+// A locked worktree must have been confirmed for unlocking already.
+func cleanWorktree(cmd *cobra.Command, w io.Writer, progress *progressPrinter, selected gitx.Worktree, cfg *cleanCmdConfig) (*cleanResult, error) {
+	ctx := cmd.Context()
+	result := &cleanResult{Path: selected.Path, Branch: selected.Branch}
+
+	// Archive before anything is removed; a failed archive keeps the worktree
+	if cfg.archiveDir != "" {
+		path, err := archiveWorktree(ctx, cmd.ErrOrStderr(), selected, cfg.archiveDir, cfg.force)
+		if err != nil {
+			return nil, err
+		}
+		result.Archive = path
+		printArchiveSuccess(w, path, flagQuiet)
+	}
+
+	// Unlock only now that nothing else can stop the removal
+	if selected.IsLocked {
+		if err := gitx.Unlock(ctx, selected.Path); err != nil {
+			return nil, fmt.Errorf("failed to unlock worktree: %w", err)
+		}
+		result.Unlocked = true
+		selected.IsLocked = false
+	}
+
+	// Remove worktree
+	if err := removeWorktree(ctx, progress, selected, cfg, result); err != nil {
+		if result.Unlocked {
+			_ = gitx.Lock(ctx, selected.Path, selected.LockReason) // Leave it locked as it was
+		}
+		return nil, err
+	}
+	removeEmptyWorktreeParents(ctx, selected.Path)
+
 	// Handle branch deletion
-	if err := handleBranchDeletion(ctx, w, selected, cfg); err != nil {
+	if err := handleBranchDeletion(ctx, cmd.InOrStdin(), progress, cmd.ErrOrStderr(), selected, cfg, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isBatch reports whether several worktrees are removed at once (--all, --merged or --pattern)
+func (cfg *cleanCmdConfig) isBatch() bool {
+	return cfg.all || cfg.merged || cfg.pattern != ""
+}
+
+// runCleanBatch removes the worktrees selected by --all, --merged or --pattern
+// They are listed and confirmed once; with --yes, confirmBatchRepo makes sure they are in the
+// intended repository instead. Unlike a single removal, --json prints a list.
+func runCleanBatch(cmd *cobra.Command, w io.Writer, progress *progressPrinter, worktrees []gitx.Worktree, cfg *cleanCmdConfig) error {
+	ctx := cmd.Context()
+	r, errW := cmd.InOrStdin(), cmd.ErrOrStderr()
+
+	targets, err := batchCleanTargets(ctx, errW, worktrees, cfg)
+	if err != nil {
 		return err
 	}
+	results := []*cleanResult{}
+	if len(targets) == 0 {
+		progress.Println("No worktrees to remove")
+		if jsonOutput() {
+			return writeJSON(cmd.OutOrStdout(), results)
+		}
+		return nil
+	}
+
+	printBatchCleanTargets(errW, targets)
+	if cfg.yes {
+		if err := confirmBatchRepo(ctx, r, errW, cfg.iKnow); err != nil {
+			return err
+		}
+	} else {
+		confirmed, err := confirmWith(ctx, r, errW, fmt.Sprintf("Remove %d worktrees?", len(targets)), "--yes")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return &WorktreeRemovalCancelledError{}
+		}
+	}
+
+	for _, wt := range targets {
+		if wt.IsLocked {
+			if err := confirmUnlock(ctx, r, errW, wt, cfg.yes); err != nil {
+				return err
+			}
+		}
+		result, err := cleanWorktree(cmd, w, progress, wt, cfg)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		if flagNull {
+			printPath(cmd.OutOrStdout(), result.Path)
+		}
+	}
 
 	// Clean up stale worktree administrative files
 	_ = gitx.Prune(ctx) // Ignore error: prune is best-effort cleanup
 
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), results)
+	}
 	return nil
 }
 
+// batchCleanTargets returns the worktrees matching --all, --merged or --pattern
+// Worktrees with uncommitted changes are skipped with a notice on errW, unless --force.
+func batchCleanTargets(ctx context.Context, errW io.Writer, worktrees []gitx.Worktree, cfg *cleanCmdConfig) ([]gitx.Worktree, error) {
+	var merged map[string]bool
+	defaultBranch := ""
+	if cfg.merged {
+		var err error
+		if defaultBranch, err = gitx.DefaultBranch(ctx); err != nil {
+			return nil, err
+		}
+		names, err := gitx.ListBranchesMergedInto(ctx, defaultBranch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check merged branches: %w", err)
+		}
+		merged = make(map[string]bool, len(names))
+		for _, name := range names {
+			merged[name] = true
+		}
+	}
+
+	var targets []gitx.Worktree
+	for _, wt := range worktrees {
+		switch {
+		case cfg.merged && (wt.Branch == "" || wt.Branch == defaultBranch || !merged[wt.Branch]):
+			continue
+		case cfg.pattern != "" && !matchesPattern(cfg.pattern, wt.Branch):
+			continue
+		}
+		if !cfg.force {
+			if status, err := gitx.GetStatus(ctx, wt.Path); err == nil && status.IsDirty() {
+				fmt.Fprintf(errW, "Skipping %s: uncommitted changes (use --force to remove it anyway)\n", wt.Path)
+				continue
+			}
+		}
+		targets = append(targets, wt)
+	}
+	return targets, nil
+}
+
+// printBatchCleanTargets lists the worktrees a batch removal is about to remove
+func printBatchCleanTargets(w io.Writer, targets []gitx.Worktree) {
+	fmt.Fprintf(w, "Worktrees to remove:\n")
+	for _, wt := range targets {
+		fmt.Fprintf(w, "  %s\t%s\n", formatBranch(wt), wt.Path)
+	}
+}
+
+// cleanResult is the JSON summary of wt clean
+type cleanResult struct {
+	Path            string `json:"path"`
+	Branch          string `json:"branch"`
+	BranchDeleted   bool   `json:"branch_deleted"`
+	BranchCommit    string `json:"branch_commit,omitempty"` // Commit the deleted branch pointed at
+	RemoteRefPruned string `json:"remote_ref_pruned,omitempty"`
+	Archive         string `json:"archive,omitempty"` // Path of the tarball written by --archive
+	// The files are being deleted by a background process (--detach-delete)
+	BackgroundDelete bool `json:"background_delete,omitempty"`
+	// Where the worktree was moved to with --trash
+	Trash    string `json:"trash,omitempty"`
+	Unlocked bool   `json:"unlocked,omitempty"` // The worktree was locked
+	// The branch was kept because it matches clean.protected_branches
+	BranchProtected bool `json:"branch_protected,omitempty"`
+}
+
 func getRemovableWorktrees(ctx context.Context) ([]gitx.Worktree, []string, error) {
 	// Get worktree list
 	worktrees, err := gitx.List(ctx)
@@ -135,6 +402,8 @@ func getRemovableWorktrees(ctx context.Context) ([]gitx.Worktree, []string, erro
 	// Create list for display (exclude main worktree)
 	var items []string
 	var validWorktrees []gitx.Worktree
+	// Checking every worktree for changes is too slow on network filesystems
+	showDirty := !slowFSMode(ctx)
 
 	for _, wt := range worktrees {
 		// Skip main worktree
@@ -142,8 +411,16 @@ func getRemovableWorktrees(ctx context.Context) ([]gitx.Worktree, []string, erro
 			continue
 		}
 
-		branch := formatBranch(wt)
-		items = append(items, fmt.Sprintf("%s\t%s", branch, wt.Path))
+		item := formatDisplayItem(wt)
+		if showDirty {
+			if status, err := gitx.GetStatus(ctx, wt.Path); err == nil && status.IsDirty() {
+				item += "\t" + formatDirtyStatus(status)
+			}
+		}
+		if wt.IsLocked {
+			item += "\t" + formatLockReason(lockReasonOrDefault(wt.LockReason))
+		}
+		items = append(items, item)
 		validWorktrees = append(validWorktrees, wt)
 	}
 
@@ -154,21 +431,112 @@ func getRemovableWorktrees(ctx context.Context) ([]gitx.Worktree, []string, erro
 	return validWorktrees, items, nil
 }
 
-func confirmRemoval(w io.Writer, wt gitx.Worktree) bool {
-	printRemovalConfirmation(w, wt)
-	return confirm("Are you sure?")
+func confirmRemoval(ctx context.Context, r io.Reader, w io.Writer, wt gitx.Worktree, status gitx.Status, force bool) (bool, error) {
+	printRemovalConfirmation(w, wt, status, force)
+	return confirmWith(ctx, r, w, "Are you sure?", "--yes")
+}
+
+// confirmUnlock shows why the worktree is locked and asks whether to unlock it for removal
+// With --yes, it is unlocked without asking. Declining returns a WorktreeLockedError.
+func confirmUnlock(ctx context.Context, r io.Reader, w io.Writer, wt gitx.Worktree, yes bool) error {
+	fmt.Fprintf(w, "⚠ Worktree is locked: %s\n", lockReasonOrDefault(wt.LockReason))
+	if yes {
+		return nil
+	}
+	confirmed, err := confirmWith(ctx, r, w, "Unlock and remove it?", "--yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return &WorktreeLockedError{Path: wt.Path, Reason: wt.LockReason}
+	}
+	return nil
 }
 
-func removeWorktree(ctx context.Context, w io.Writer, wt gitx.Worktree, cfg *cleanCmdConfig) error {
-	if err := gitx.Remove(ctx, wt.Path, cfg.force); err != nil {
+// lockReasonOrDefault returns the lock reason to show for a locked worktree
+func lockReasonOrDefault(reason string) string {
+	if reason == "" {
+		return "no reason given"
+	}
+	return reason
+}
+
+// formatDirtyStatus formats the selection list marker for a worktree with uncommitted changes
+func formatDirtyStatus(status gitx.Status) string {
+	var parts []string
+	if status.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", status.Modified))
+	}
+	if status.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("%d untracked", status.Untracked))
+	}
+	return fmt.Sprintf("[dirty: %s]", strings.Join(parts, ", "))
+}
+
+func removeWorktree(ctx context.Context, progress *progressPrinter, wt gitx.Worktree, cfg *cleanCmdConfig, result *cleanResult) error {
+	var err error
+	switch {
+	case cfg.detachDelete:
+		result.BackgroundDelete, err = removeWorktreeDetached(ctx, progress.w, wt, cfg.force)
+	case shouldUseTrash(ctx, cfg):
+		result.Trash, err = moveWorktreeToTrash(ctx, wt, cfg.force)
+	default:
+		err = gitx.Remove(ctx, wt.Path, cfg.force)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
+	recordJournal(ctx, journal.Entry{Action: journal.WorktreeRemoved, Branch: wt.Branch, Commit: wt.HEAD, Path: wt.Path})
 
-	printRemovalSuccess(w, wt.Path, flagQuiet)
+	printRemovalSuccess(progress, result)
 	return nil
 }
 
-func handleBranchDeletion(ctx context.Context, w io.Writer, wt gitx.Worktree, cfg *cleanCmdConfig) error {
+// shouldUseTrash reports whether the worktree is moved to the trash (--trash or clean.use_trash)
+func shouldUseTrash(ctx context.Context, cfg *cleanCmdConfig) bool {
+	if cfg.trash {
+		return true
+	}
+	wtCfg, err := loadWorktreeConfig(ctx)
+	return err == nil && wtCfg.GetUseTrash()
+}
+
+// removeEmptyWorktreeParents removes directories left empty below the worktree directory after
+// the worktree at path went away (e.g. ".myproject-wt/feature" for nested branch directories)
+func removeEmptyWorktreeParents(ctx context.Context, path string) {
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return
+	}
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return
+	}
+	removeEmptyParents(path, worktreeBaseDir(repo, wtCfg))
+}
+
+// removeEmptyParents removes the empty directories between path and stop, bottom-up
+// stop itself and directories outside it are never removed.
+func removeEmptyParents(path, stop string) {
+	for dir := filepath.Dir(path); isStrictlyWithin(dir, stop); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty (or not removable): leave it and everything above
+		}
+	}
+}
+
+// isStrictlyWithin reports whether path is inside dir (and not dir itself)
+func isStrictlyWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// handleBranchDeletion deletes the branch of a removed worktree if appropriate, recording what was done in result
+// Results are written to progress; prompts and the warnings they are about go to errW.
+func handleBranchDeletion(ctx context.Context, r io.Reader, progress *progressPrinter, errW io.Writer, wt gitx.Worktree, cfg *cleanCmdConfig, result *cleanResult) error {
 	if cfg.keepBranch || wt.Branch == "" {
 		return nil
 	}
@@ -180,33 +548,137 @@ func handleBranchDeletion(ctx context.Context, w io.Writer, wt gitx.Worktree, cf
 	}
 
 	if inUse {
-		printBranchInUseWarning(w, wt.Branch, flagQuiet)
+		printBranchInUseWarning(progress, wt.Branch)
 		return nil
 	}
 
+	// Protected branches are not even offered for deletion: --yes doesn't override this
+	if !cfg.allowProtected {
+		pattern, err := protectedBranchPattern(ctx, wt.Branch)
+		if err != nil {
+			return err
+		}
+		if pattern != "" {
+			result.BranchProtected = true
+			printBranchProtectedMessage(progress, wt.Branch, pattern)
+			return nil
+		}
+	}
+
 	// Ask user if they want to delete the branch
-	shouldDelete := cfg.yes || confirm(fmt.Sprintf("Also delete branch '%s'?", wt.Branch))
-	if !shouldDelete {
-		return nil
+	if !cfg.yes {
+		shouldDelete, err := confirmWith(ctx, r, errW, fmt.Sprintf("Also delete branch '%s'?", wt.Branch), "--yes or --keep-branch")
+		if err != nil {
+			return err
+		}
+		if !shouldDelete {
+			return nil
+		}
 	}
 
 	// Check if branch is merged and determine if force delete is needed
-	forceDelete, shouldProceed := shouldForceDeleteBranch(ctx, w, wt.Branch, cfg.yes)
+	forceDelete, shouldProceed, err := shouldForceDeleteBranch(ctx, r, errW, wt.Branch, cfg.yes)
+	if err != nil {
+		return err
+	}
 	if !shouldProceed {
-		printBranchKeptMessage(w, wt.Branch, flagQuiet)
+		printBranchKeptMessage(progress, wt.Branch)
 		return nil
 	}
 
+	// Remember the upstream before deleting the branch removes its configuration
+	var upstream *gitx.Upstream
+	if shouldPruneRemoteRefs(ctx, cfg) {
+		upstream, _ = gitx.GetUpstream(ctx, wt.Branch) // nil if unknown: nothing to prune
+	}
+
 	// Delete branch
-	if err := gitx.DeleteBranch(ctx, wt.Branch, forceDelete); err != nil {
+	commit, err := deleteBranchRecorded(ctx, wt.Branch, forceDelete)
+	if err != nil {
 		return fmt.Errorf("failed to delete branch: %w", err)
 	}
 
-	printBranchDeletionSuccess(w, wt.Branch, flagQuiet)
+	result.BranchDeleted = true
+	result.BranchCommit = commit
+	printBranchDeletionSuccess(progress, wt.Branch, commit)
+
+	if upstream != nil {
+		result.RemoteRefPruned = pruneRemoteTrackingRef(ctx, progress, errW, upstream)
+	}
 	return nil
 }
 
-func shouldForceDeleteBranch(ctx context.Context, w io.Writer, branch string, autoYes bool) (forceDelete bool, shouldProceed bool) {
+// protectedBranchPattern returns the clean.protected_branches pattern that branch matches, or "" if it isn't protected
+// The default branch is only looked up when a pattern refers to it.
+func protectedBranchPattern(ctx context.Context, branch string) (string, error) {
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	patterns := wtCfg.GetProtectedBranches()
+
+	defaultBranch := ""
+	if slices.Contains(patterns, config.DefaultBranchPlaceholder) {
+		// Without origin/HEAD, main or master, there is no default branch to protect
+		defaultBranch, _ = gitx.DefaultBranch(ctx)
+	}
+	return matchProtectedBranch(branch, defaultBranch, patterns), nil
+}
+
+// matchProtectedBranch returns the first of patterns that branch matches, or "" if none does
+// Patterns are globs in which "*" doesn't match "/"; "{default}" matches defaultBranch (if not "").
+func matchProtectedBranch(branch, defaultBranch string, patterns []string) string {
+	for _, pattern := range patterns {
+		if pattern == config.DefaultBranchPlaceholder {
+			if defaultBranch != "" && branch == defaultBranch {
+				return pattern
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, branch); matched { // Patterns are validated when loaded
+			return pattern
+		}
+	}
+	return ""
+}
+
+// shouldPruneRemoteRefs reports whether remote-tracking refs of deleted branches should be pruned
+func shouldPruneRemoteRefs(ctx context.Context, cfg *cleanCmdConfig) bool {
+	if cfg.pruneRemoteRefs {
+		return true
+	}
+	wtCfg, err := loadWorktreeConfig(ctx)
+	return err == nil && wtCfg.GetPruneRemoteRefs()
+}
+
+// pruneRemoteTrackingRef deletes the remote-tracking ref of a deleted branch if the remote branch is gone
+// It returns the short name of the deleted ref, or "" if it was kept. Failures (e.g. the remote is
+// unreachable) only produce a warning on errW.
+func pruneRemoteTrackingRef(ctx context.Context, progress *progressPrinter, errW io.Writer, upstream *gitx.Upstream) string {
+	exists, err := gitx.RefExists(ctx, upstream.TrackingRef)
+	if err != nil || !exists {
+		return ""
+	}
+
+	name := strings.TrimPrefix(upstream.TrackingRef, "refs/remotes/")
+	onRemote, err := gitx.RemoteBranchExists(ctx, upstream.Remote, upstream.Branch)
+	if err != nil {
+		fmt.Fprintf(errW, "Warning: could not check remote branch, keeping %s: %v\n", name, err)
+		return ""
+	}
+	if onRemote {
+		return ""
+	}
+
+	if err := gitx.DeleteRemoteTrackingRef(ctx, upstream.TrackingRef); err != nil {
+		fmt.Fprintf(errW, "Warning: failed to delete remote-tracking ref %s: %v\n", name, err)
+		return ""
+	}
+	printRemoteRefPruned(progress, name)
+	return name
+}
+
+func shouldForceDeleteBranch(ctx context.Context, r io.Reader, w io.Writer, branch string, autoYes bool) (forceDelete bool, shouldProceed bool, err error) {
 	merged, err := gitx.IsBranchMerged(ctx, branch)
 	if err != nil {
 		if !flagQuiet {
@@ -216,73 +688,84 @@ func shouldForceDeleteBranch(ctx context.Context, w io.Writer, branch string, au
 	}
 
 	if merged {
-		return false, true
+		return false, true, nil
 	}
 
 	printBranchNotMergedWarning(w, branch)
-	if autoYes {
-		return true, true
+
+	// A stale local branch makes the merge check unreliable
+	if _, behind, hasUpstream, err := gitx.AheadBehind(ctx, ""); err == nil && hasUpstream && behind > 0 {
+		printStaleBaseWarning(w, behind)
 	}
 
-	if confirm("Force delete? (git branch -D)") {
-		return true, true
+	if autoYes {
+		return true, true, nil
 	}
 
-	return false, false
+	confirmed, err := confirmWith(ctx, r, w, "Force delete? (git branch -D)", "--yes or --keep-branch")
+	return confirmed, confirmed, err
 }
 
 // Output functions
 
-func printRemovalConfirmation(w io.Writer, wt gitx.Worktree) {
+func printRemovalConfirmation(w io.Writer, wt gitx.Worktree, status gitx.Status, force bool) {
 	fmt.Fprintf(w, "The following worktree will be removed:\n")
 	fmt.Fprintf(w, "  Path: %s\n", wt.Path)
 	if wt.Branch != "" {
 		fmt.Fprintf(w, "  Branch: %s\n", wt.Branch)
 	}
-}
-
-func printRemovalSuccess(w io.Writer, path string, quiet bool) {
-	if quiet {
+	if !status.IsDirty() {
 		return
 	}
-	fmt.Fprintf(w, "✓ Worktree removed: %s\n", path)
+	if force {
+		fmt.Fprintf(w, "⚠ This worktree has %d uncommitted changes; they will be lost\n", status.Total())
+	} else {
+		fmt.Fprintf(w, "⚠ This worktree has %d uncommitted changes; removal will require --force\n", status.Total())
+	}
 }
 
-func printBranchDeletionSuccess(w io.Writer, branch string, quiet bool) {
-	if quiet {
-		return
+func printRemovalSuccess(p *progressPrinter, result *cleanResult) {
+	switch {
+	case result.BackgroundDelete:
+		p.Printf("✓ Worktree removed: %s (files are being deleted in the background)\n", result.Path)
+	case result.Trash != "":
+		p.Printf("✓ Worktree moved to the trash: %s (restore with: wt clean --restore)\n", result.Path)
+	default:
+		p.Printf("✓ Worktree removed: %s\n", result.Path)
 	}
-	fmt.Fprintf(w, "✓ Branch deleted: %s\n", branch)
 }
 
-func printBranchInUseWarning(w io.Writer, branch string, quiet bool) {
-	if quiet {
-		return
+func printBranchDeletionSuccess(p *progressPrinter, branch, commit string) {
+	p.Printf("✓ Branch deleted: %s\n", branch)
+	if commit != "" {
+		p.Printf("  Recover with: git branch %s %s (or wt undo)\n", branch, commit)
 	}
-	fmt.Fprintf(w, "⚠ Branch '%s' is in use by other worktrees, keeping it\n", branch)
 }
 
-func printBranchNotMergedWarning(w io.Writer, branch string) {
-	fmt.Fprintf(w, "⚠ Branch '%s' is not merged\n", branch)
+func printRemoteRefPruned(p *progressPrinter, name string) {
+	p.Printf("✓ Remote-tracking ref deleted: %s (remote branch is gone)\n", name)
+}
+
+func printBranchInUseWarning(p *progressPrinter, branch string) {
+	p.Printf("⚠ Branch '%s' is in use by other worktrees, keeping it\n", branch)
 }
 
-func printBranchKeptMessage(w io.Writer, branch string, quiet bool) {
-	if quiet {
+func printBranchProtectedMessage(p *progressPrinter, branch, pattern string) {
+	if pattern == config.DefaultBranchPlaceholder {
+		p.Printf("Branch '%s' is the default branch and protected by clean.protected_branches, keeping it (use --allow-protected to delete it)\n", branch)
 		return
 	}
-	fmt.Fprintf(w, "Branch '%s' will be kept\n", branch)
+	p.Printf("Branch '%s' is protected by clean.protected_branches (%s), keeping it (use --allow-protected to delete it)\n", branch, pattern)
 }
 
-// confirm prompts user for confirmation
-func confirm(message string) bool {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s (y/N): ", message)
+func printBranchNotMergedWarning(w io.Writer, branch string) {
+	fmt.Fprintf(w, "⚠ Branch '%s' is not merged\n", branch)
+}
 
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return false
-	}
+func printStaleBaseWarning(w io.Writer, behind int) {
+	fmt.Fprintf(w, "⚠ The current branch is %d commits behind its upstream; the branch may already be merged there (run 'git pull' to update)\n", behind)
+}
 
-	input = strings.TrimSpace(strings.ToLower(input))
-	return input == "y" || input == "yes"
+func printBranchKeptMessage(p *progressPrinter, branch string) {
+	p.Printf("Branch '%s' will be kept\n", branch)
 }
@@ -1,8 +1,16 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
 )
 
 func TestNoRemovableWorktreesError(t *testing.T) {
@@ -26,9 +34,611 @@ func TestWorktreeRemovalCancelledError(t *testing.T) {
 	}
 }
 
-func TestConfirm(t *testing.T) {
-	// Note: This function reads from os.Stdin, so it's difficult to test without mocking.
-	// In a real test environment, you would use dependency injection or interfaces to make this testable.
-	// For now, we'll skip this test or use a mock stdin.
-	t.Skip("confirm() requires stdin interaction, skipping for now")
+func TestFormatDirtyStatus(t *testing.T) {
+	tests := []struct {
+		status gitx.Status
+		want   string
+	}{
+		{gitx.Status{Modified: 2}, "[dirty: 2 modified]"},
+		{gitx.Status{Untracked: 1}, "[dirty: 1 untracked]"},
+		{gitx.Status{Modified: 1, Untracked: 3}, "[dirty: 1 modified, 3 untracked]"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDirtyStatus(tt.status); got != tt.want {
+			t.Errorf("formatDirtyStatus(%+v) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPrintRemovalConfirmation(t *testing.T) {
+	wt := gitx.Worktree{Path: "/work/.repo-wt/feature", Branch: "feature"}
+
+	tests := []struct {
+		name    string
+		status  gitx.Status
+		force   bool
+		want    string
+		notWant string
+	}{
+		{
+			name:    "clean worktree",
+			status:  gitx.Status{},
+			notWant: "uncommitted",
+		},
+		{
+			name:   "dirty worktree without force",
+			status: gitx.Status{Modified: 1, Untracked: 1},
+			want:   "has 2 uncommitted changes; removal will require --force",
+		},
+		{
+			name:   "dirty worktree with force",
+			status: gitx.Status{Modified: 1},
+			force:  true,
+			want:   "has 1 uncommitted changes; they will be lost",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printRemovalConfirmation(&buf, wt, tt.status, tt.force)
+
+			output := buf.String()
+			if !strings.Contains(output, "Branch: feature") {
+				t.Errorf("output should contain the branch, got: %s", output)
+			}
+			if tt.want != "" && !strings.Contains(output, tt.want) {
+				t.Errorf("output should contain %q, got: %s", tt.want, output)
+			}
+			if tt.notWant != "" && strings.Contains(output, tt.notWant) {
+				t.Errorf("output should not contain %q, got: %s", tt.notWant, output)
+			}
+		})
+	}
+}
+
+func TestHandleBranchDeletionPrunesRemoteRefs(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, remotePath string)
+		wantPruned bool
+		wantOutput string
+	}{
+		{
+			name: "remote branch gone",
+			setup: func(t *testing.T, remotePath string) {
+				runGitForTest(t, remotePath, "branch", "-D", "feature")
+			},
+			wantPruned: true,
+			wantOutput: "Remote-tracking ref deleted: origin/feature",
+		},
+		{
+			name:       "remote branch still exists",
+			setup:      func(t *testing.T, remotePath string) {},
+			wantPruned: false,
+		},
+		{
+			name: "remote unreachable",
+			setup: func(t *testing.T, remotePath string) {
+				runGitForTest(t, ".", "remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+			},
+			wantPruned: false,
+			wantOutput: "Warning: could not check remote branch, keeping origin/feature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			repoPath := setupTestRepo(t)
+			remotePath := filepath.Join(t.TempDir(), "remote.git")
+			runGitForTest(t, repoPath, "clone", "--bare", repoPath, remotePath)
+			runGitForTest(t, repoPath, "remote", "add", "origin", remotePath)
+			runGitForTest(t, repoPath, "branch", "feature")
+			runGitForTest(t, repoPath, "push", "-u", "origin", "feature")
+			tt.setup(t, remotePath)
+
+			var buf bytes.Buffer
+			wt := gitx.Worktree{Path: filepath.Join(t.TempDir(), "removed"), Branch: "feature"}
+			cfg := &cleanCmdConfig{yes: true, pruneRemoteRefs: true}
+			result := &cleanResult{}
+			if err := handleBranchDeletion(context.Background(), strings.NewReader(""), newProgressPrinter(&buf, false, false), &buf, wt, cfg, result); err != nil {
+				t.Fatalf("handleBranchDeletion() returned error: %v", err)
+			}
+			if !result.BranchDeleted {
+				t.Error("result.BranchDeleted should be true")
+			}
+			if pruned := result.RemoteRefPruned == "origin/feature"; pruned != tt.wantPruned {
+				t.Errorf("result.RemoteRefPruned = %q, want pruned %v", result.RemoteRefPruned, tt.wantPruned)
+			}
+
+			exists, err := gitx.RefExists(context.Background(), "refs/remotes/origin/feature")
+			if err != nil {
+				t.Fatalf("RefExists() returned error: %v", err)
+			}
+			if exists == tt.wantPruned {
+				t.Errorf("remote-tracking ref exists = %v, want %v\n%s", exists, !tt.wantPruned, buf.String())
+			}
+			if tt.wantOutput != "" && !strings.Contains(buf.String(), tt.wantOutput) {
+				t.Errorf("output should contain %q, got: %s", tt.wantOutput, buf.String())
+			}
+		})
+	}
+}
+
+func TestHandleBranchDeletionKeepsRemoteRefsByDefault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	runGitForTest(t, repoPath, "clone", "--bare", repoPath, remotePath)
+	runGitForTest(t, repoPath, "remote", "add", "origin", remotePath)
+	runGitForTest(t, repoPath, "branch", "feature")
+	runGitForTest(t, repoPath, "push", "-u", "origin", "feature")
+	runGitForTest(t, remotePath, "branch", "-D", "feature")
+
+	var buf bytes.Buffer
+	wt := gitx.Worktree{Path: filepath.Join(t.TempDir(), "removed"), Branch: "feature"}
+	if err := handleBranchDeletion(context.Background(), strings.NewReader(""), newProgressPrinter(&buf, false, false), &buf, wt, &cleanCmdConfig{yes: true}, &cleanResult{}); err != nil {
+		t.Fatalf("handleBranchDeletion() returned error: %v", err)
+	}
+
+	exists, err := gitx.RefExists(context.Background(), "refs/remotes/origin/feature")
+	if err != nil {
+		t.Fatalf("RefExists() returned error: %v", err)
+	}
+	if !exists {
+		t.Error("remote-tracking ref should be kept without --prune-remote-refs")
+	}
+}
+
+func TestMatchProtectedBranch(t *testing.T) {
+	patterns := []string{"main", "release/*", "hotfix-*", "{default}"}
+	tests := []struct {
+		branch        string
+		defaultBranch string
+		want          string
+	}{
+		{branch: "main", want: "main"},
+		{branch: "release/1.0", want: "release/*"},
+		{branch: "release/1.0/fix", want: ""}, // "*" doesn't match "/"
+		{branch: "hotfix-login", want: "hotfix-*"},
+		{branch: "feature/login", want: ""},
+		{branch: "trunk", defaultBranch: "trunk", want: "{default}"},
+		{branch: "trunk", want: ""}, // No default branch known
+		{branch: "mainline", defaultBranch: "trunk", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := matchProtectedBranch(tt.branch, tt.defaultBranch, patterns); got != tt.want {
+				t.Errorf("matchProtectedBranch(%q, %q) = %q, want %q", tt.branch, tt.defaultBranch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleBranchDeletionKeepsProtectedBranches(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+	runGitForTest(t, repoPath, "branch", "develop")
+	runGitForTest(t, repoPath, "branch", "trunk")
+	// origin/HEAD makes "trunk" the default branch
+	runGitForTest(t, repoPath, "update-ref", "refs/remotes/origin/trunk", "HEAD")
+	runGitForTest(t, repoPath, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/trunk")
+
+	for _, branch := range []string{"develop", "trunk"} {
+		var buf bytes.Buffer
+		wt := gitx.Worktree{Path: filepath.Join(t.TempDir(), "removed"), Branch: branch}
+		result := &cleanResult{}
+		// --yes doesn't delete a protected branch, and nothing is asked
+		if err := handleBranchDeletion(ctx, strings.NewReader(""), newProgressPrinter(&buf, false, false), &buf, wt, &cleanCmdConfig{yes: true}, result); err != nil {
+			t.Fatalf("handleBranchDeletion(%s) returned error: %v", branch, err)
+		}
+		if result.BranchDeleted || !result.BranchProtected {
+			t.Errorf("handleBranchDeletion(%s) result = %+v, want the branch kept as protected", branch, result)
+		}
+		if !strings.Contains(buf.String(), "protected by clean.protected_branches") {
+			t.Errorf("output should say why %s is kept, got: %s", branch, buf.String())
+		}
+		if exists, _ := gitx.BranchExists(ctx, branch); !exists {
+			t.Errorf("protected branch %s was deleted", branch)
+		}
+	}
+
+	// Without {default}, the default branch is no longer protected
+	t.Setenv("WT_PROTECTED_BRANCHES", "main master develop")
+	wt := gitx.Worktree{Path: filepath.Join(t.TempDir(), "removed"), Branch: "trunk"}
+	result := &cleanResult{}
+	if err := handleBranchDeletion(ctx, strings.NewReader(""), newProgressPrinter(&bytes.Buffer{}, false, false), &bytes.Buffer{}, wt, &cleanCmdConfig{yes: true}, result); err != nil {
+		t.Fatalf("handleBranchDeletion(trunk) returned error: %v", err)
+	}
+	if !result.BranchDeleted {
+		t.Errorf("trunk should be deleted once {default} is not protected, result = %+v", result)
+	}
+
+	// --allow-protected offers the branch for deletion again
+	wt = gitx.Worktree{Path: filepath.Join(t.TempDir(), "removed"), Branch: "develop"}
+	result = &cleanResult{}
+	if err := handleBranchDeletion(ctx, strings.NewReader(""), newProgressPrinter(&bytes.Buffer{}, false, false), &bytes.Buffer{}, wt, &cleanCmdConfig{yes: true, allowProtected: true}, result); err != nil {
+		t.Fatalf("handleBranchDeletion(develop) returned error: %v", err)
+	}
+	if !result.BranchDeleted {
+		t.Errorf("develop should be deleted with --allow-protected, result = %+v", result)
+	}
+}
+
+func TestRemoveEmptyParents(t *testing.T) {
+	stop := t.TempDir()
+	removed := filepath.Join(stop, "feature", "auth", "login")
+	sibling := filepath.Join(stop, "feature", "signup")
+	if err := os.MkdirAll(filepath.Dir(removed), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.MkdirAll(sibling, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	removeEmptyParents(removed, stop)
+
+	// feature/auth became empty; feature still holds another worktree
+	if _, err := os.Stat(filepath.Join(stop, "feature", "auth")); !os.IsNotExist(err) {
+		t.Errorf("empty parent feature/auth should be removed, stat error = %v", err)
+	}
+	if _, err := os.Stat(sibling); err != nil {
+		t.Errorf("non-empty parent should be kept: %v", err)
+	}
+
+	// Once the last worktree below feature goes away, feature is removed too
+	if err := os.Remove(sibling); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	removeEmptyParents(sibling, stop)
+	if _, err := os.Stat(filepath.Join(stop, "feature")); !os.IsNotExist(err) {
+		t.Errorf("empty parent feature should be removed, stat error = %v", err)
+	}
+	if _, err := os.Stat(stop); err != nil {
+		t.Errorf("stop directory must never be removed: %v", err)
+	}
+
+	// Paths outside stop are left alone
+	outside := filepath.Join(t.TempDir(), "a", "b")
+	if err := os.MkdirAll(filepath.Dir(outside), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	removeEmptyParents(outside, stop)
+	if _, err := os.Stat(filepath.Dir(outside)); err != nil {
+		t.Errorf("directory outside stop should be kept: %v", err)
+	}
+}
+
+func TestCleanPromptsOnStderr(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+	runGitForTest(t, wtPath, "commit", "--allow-empty", "-m", "Unmerged work")
+
+	// Like the shell function, capture stdout; prompts must still be visible
+	var stdout, stderr bytes.Buffer
+	cmd := newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader("y\ny\ny\n"))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{}); err != nil {
+		t.Fatalf("runCleanWithConfig() returned error: %v", err)
+	}
+
+	for _, prompt := range []string{"The following worktree will be removed", "Are you sure?", "Also delete branch 'feature'?", "is not merged", "Force delete?"} {
+		if !strings.Contains(stderr.String(), prompt) {
+			t.Errorf("stderr should contain %q, got:\n%s", prompt, stderr.String())
+		}
+		if strings.Contains(stdout.String(), prompt) {
+			t.Errorf("stdout should not contain %q, got:\n%s", prompt, stdout.String())
+		}
+	}
+	for _, result := range []string{"✓ Worktree removed", "✓ Branch deleted: feature"} {
+		if !strings.Contains(stdout.String(), result) {
+			t.Errorf("stdout should contain %q, got:\n%s", result, stdout.String())
+		}
+	}
+}
+
+func TestRemoveWorktreeDetached(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+
+	addWorktree := func(t *testing.T, branch string) gitx.Worktree {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), branch)
+		runGitForTest(t, repoPath, "worktree", "add", "-b", branch, path)
+		if err := os.WriteFile(filepath.Join(path, "big.bin"), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGitForTest(t, path, "add", "big.bin")
+		runGitForTest(t, path, "commit", "-m", "Add big file")
+		return gitx.Worktree{Path: path, Branch: branch}
+	}
+	assertRemoved := func(t *testing.T, wt gitx.Worktree) {
+		t.Helper()
+		if pathExists(wt.Path) {
+			t.Errorf("%s still exists", wt.Path)
+		}
+		if list := runGitForTest(t, repoPath, "worktree", "list"); strings.Contains(list, wt.Path) {
+			t.Errorf("worktree is still registered:\n%s", list)
+		}
+	}
+
+	t.Run("deleted in the background", func(t *testing.T) {
+		var started []string
+		defer func(prev func(string) error) { startBackgroundDelete = prev }(startBackgroundDelete)
+		startBackgroundDelete = func(dir string) error {
+			started = append(started, dir)
+			return nil
+		}
+
+		wt := addWorktree(t, "background")
+		var buf bytes.Buffer
+		background, err := removeWorktreeDetached(ctx, &buf, wt, false)
+		if err != nil || !background {
+			t.Fatalf("removeWorktreeDetached() = %v, %v, want true, nil", background, err)
+		}
+		assertRemoved(t, wt)
+		if len(started) != 1 || filepath.Dir(started[0]) != filepath.Dir(wt.Path) || !strings.HasPrefix(filepath.Base(started[0]), deleteDirPrefix) {
+			t.Fatalf("background deletion started for %v, want a %s* directory next to %s", started, deleteDirPrefix, wt.Path)
+		}
+		if !pathExists(filepath.Join(started[0], "background", "big.bin")) {
+			t.Errorf("the worktree files should have been moved to %s", started[0])
+		}
+
+		// The detached process runs wt __delete
+		if err := runDelete(deleteCmd, started); err != nil {
+			t.Fatalf("runDelete() returned error: %v", err)
+		}
+		if pathExists(started[0]) {
+			t.Errorf("%s still exists after wt __delete", started[0])
+		}
+	})
+
+	t.Run("rename across devices", func(t *testing.T) {
+		defer func(prev func(string, string) error) { renameDir = prev }(renameDir)
+		renameDir = func(oldpath, newpath string) error {
+			return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+		}
+
+		wt := addWorktree(t, "cross-device")
+		var buf bytes.Buffer
+		background, err := removeWorktreeDetached(ctx, &buf, wt, false)
+		if err != nil || background {
+			t.Fatalf("removeWorktreeDetached() = %v, %v, want false, nil", background, err)
+		}
+		assertRemoved(t, wt)
+		if !strings.Contains(buf.String(), "deleting it now") {
+			t.Errorf("output should contain a notice about deleting synchronously, got: %s", buf.String())
+		}
+		entries, _ := os.ReadDir(filepath.Dir(wt.Path))
+		if len(entries) != 0 {
+			t.Errorf("nothing should be left next to the worktree, got %v", entries)
+		}
+	})
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		wt := addWorktree(t, "dirty")
+		if err := os.WriteFile(filepath.Join(wt.Path, "big.bin"), []byte("changed"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := removeWorktreeDetached(ctx, &buf, wt, false); err == nil {
+			t.Fatal("removeWorktreeDetached() expected error for uncommitted changes, got nil")
+		}
+		if !pathExists(filepath.Join(wt.Path, "big.bin")) {
+			t.Error("the worktree should have been kept")
+		}
+	})
+}
+
+func TestRunDeleteRefusesOtherDirectories(t *testing.T) {
+	dir := t.TempDir()
+	err := runDelete(deleteCmd, []string{dir})
+	if err == nil || !strings.Contains(err.Error(), "refusing to delete") {
+		t.Errorf("runDelete() error = %v, want refusal", err)
+	}
+	if !pathExists(dir) {
+		t.Errorf("%s should not have been deleted", dir)
+	}
+}
+
+func TestCleanLockedWorktree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+
+	addLocked := func(t *testing.T, branch string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), branch)
+		runGitForTest(t, repoPath, "worktree", "add", "-b", branch, path)
+		runGitForTest(t, repoPath, "worktree", "lock", "--reason", "on USB drive", path)
+		return path
+	}
+	isLocked := func(t *testing.T, path string) bool {
+		t.Helper()
+		worktrees, err := gitx.List(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, wt := range worktrees {
+			if gitx.SamePath(wt.Path, path) {
+				return wt.IsLocked
+			}
+		}
+		t.Fatalf("worktree %s not found", path)
+		return false
+	}
+	run := func(query, input string, cfg *cleanCmdConfig) (string, error) {
+		var stderr bytes.Buffer
+		cmd := newCleanCmd()
+		cmd.SetContext(context.Background())
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		cfg.keepBranch = true
+		err := runCleanWithConfig(cmd, []string{query}, cfg)
+		return stderr.String(), err
+	}
+
+	t.Run("declined", func(t *testing.T) {
+		path := addLocked(t, "declined")
+		stderr, err := run("declined", "n\n", &cleanCmdConfig{})
+		var lockedErr *WorktreeLockedError
+		if !errors.As(err, &lockedErr) || lockedErr.Reason != "on USB drive" {
+			t.Fatalf("runCleanWithConfig() error = %v, want WorktreeLockedError with the reason", err)
+		}
+		if !strings.Contains(stderr, "⚠ Worktree is locked: on USB drive") {
+			t.Errorf("stderr should show the lock reason, got: %s", stderr)
+		}
+		if !isLocked(t, path) {
+			t.Error("worktree should still be locked")
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		path := addLocked(t, "confirmed")
+		stderr, err := run("confirmed", "y\ny\n", &cleanCmdConfig{})
+		if err != nil {
+			t.Fatalf("runCleanWithConfig() returned error: %v", err)
+		}
+		if !strings.Contains(stderr, "Unlock and remove it?") {
+			t.Errorf("stderr should ask to unlock, got: %s", stderr)
+		}
+		if pathExists(path) {
+			t.Errorf("%s should have been removed", path)
+		}
+	})
+
+	t.Run("yes unlocks", func(t *testing.T) {
+		path := addLocked(t, "yes")
+		stderr, err := run("yes", "", &cleanCmdConfig{yes: true})
+		if err != nil {
+			t.Fatalf("runCleanWithConfig() returned error: %v", err)
+		}
+		if strings.Contains(stderr, "Unlock and remove it?") {
+			t.Errorf("--yes should not ask, got: %s", stderr)
+		}
+		if pathExists(path) {
+			t.Errorf("%s should have been removed", path)
+		}
+	})
+
+	t.Run("relocked when removal fails", func(t *testing.T) {
+		path := addLocked(t, "dirty")
+		if err := os.WriteFile(filepath.Join(path, "untracked.txt"), []byte("work"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run("dirty", "", &cleanCmdConfig{yes: true}); err == nil {
+			t.Fatal("runCleanWithConfig() expected error for uncommitted changes, got nil")
+		}
+		if !isLocked(t, path) {
+			t.Error("worktree should be locked again after the removal failed")
+		}
+	})
+}
+
+func TestCleanQuiet(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+	runGitForTest(t, wtPath, "commit", "--allow-empty", "-m", "Unmerged work")
+	quietForTest(t)
+
+	var stdout bytes.Buffer
+	cmd := newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{yes: true}); err != nil {
+		t.Fatalf("runCleanWithConfig() returned error: %v", err)
+	}
+	if pathExists(wtPath) {
+		t.Errorf("worktree %s should be removed", wtPath)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout with --quiet = %q, want nothing", stdout.String())
+	}
+}
+
+func TestCleanBatch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtDir := t.TempDir()
+	add := func(branch string) string {
+		path := filepath.Join(wtDir, branch)
+		runGitForTest(t, repoPath, "worktree", "add", "-b", branch, path)
+		return path
+	}
+	mergedPath := add("merged")
+	featurePath := add("feature-x")
+	runGitForTest(t, featurePath, "commit", "--allow-empty", "-m", "Unmerged work")
+	dirtyPath := add("feature-dirty")
+	if err := os.WriteFile(filepath.Join(dirtyPath, "wip.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args []string, cfg *cleanCmdConfig) (string, error) {
+		var stderr bytes.Buffer
+		cmd := newCleanCmd()
+		cmd.SetContext(context.Background())
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		err := runCleanWithConfig(cmd, args, cfg)
+		return stderr.String(), err
+	}
+
+	if _, err := run([]string{"feature"}, &cleanCmdConfig{all: true}); exitCode(err) != ExitUsage {
+		t.Errorf("a query with --all error = %v, want a usage error", err)
+	}
+
+	// --merged: the unmerged branch stays, the dirty worktree is skipped
+	stderr, err := run(nil, &cleanCmdConfig{merged: true, yes: true})
+	if err != nil {
+		t.Fatalf("clean --merged --yes returned error: %v\n%s", err, stderr)
+	}
+	if pathExists(mergedPath) || !pathExists(featurePath) || !pathExists(dirtyPath) {
+		t.Errorf("clean --merged should only remove %s", mergedPath)
+	}
+	if !strings.Contains(stderr, "Skipping "+dirtyPath) {
+		t.Errorf("stderr should mention the skipped dirty worktree, got:\n%s", stderr)
+	}
+
+	// --yes in another repository than this shell last used asks for its name
+	t.Setenv(sessionIDEnv, "4242")
+	if err := os.MkdirAll(filepath.Dir(sessionStatePath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sessionStatePath(), []byte("/elsewhere/other-repo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setNonInteractive(t)
+	var nonInteractive *NonInteractiveError
+	if _, err := run(nil, &cleanCmdConfig{pattern: "feature-*", yes: true}); !errors.As(err, &nonInteractive) || nonInteractive.Bypass != "--i-know" {
+		t.Fatalf("clean --pattern --yes in another repository error = %v, want NonInteractiveError naming --i-know", err)
+	}
+	if !pathExists(featurePath) {
+		t.Fatal("nothing should be removed before the repository is confirmed")
+	}
+
+	stderr, err = run(nil, &cleanCmdConfig{pattern: "feature-*", yes: true, iKnow: true})
+	if err != nil {
+		t.Fatalf("clean --pattern --yes --i-know returned error: %v\n%s", err, stderr)
+	}
+	if pathExists(featurePath) || !pathExists(dirtyPath) {
+		t.Errorf("clean --pattern should remove %s and skip %s", featurePath, dirtyPath)
+	}
+	if branches := runGitForTest(t, repoPath, "branch", "--list", "feature-x"); branches != "" {
+		t.Errorf("branch feature-x should be deleted, got %q", branches)
+	}
 }
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/ghx"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// completionContext returns the command context, or a background context when unset
+func completionContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// filterCompletions returns candidates that start with toComplete
+func filterCompletions(candidates []string, toComplete string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// completeWorktreeBranches completes branch names of existing worktrees (go, open, clean)
+func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Degrade silently outside a repository
+	worktrees, err := gitx.List(completionContext(cmd))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var branches []string
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+	}
+
+	return filterCompletions(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNewBranches completes local and remote branch names that have no worktree (new)
+func completeNewBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	branches, err := gitx.ListBranchesWithoutWorktree(completionContext(cmd))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return filterCompletions(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeys completes known configuration keys (config get/set/unset)
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(config.KnownKeys(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePRBranches completes local branch names for wt pr --branch: review/pr-<num> and the PR's head branch
+func completePRBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	prNumber, err := validatePRNumber(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	candidates := []string{fmt.Sprintf("review/pr-%d", prNumber)}
+	// Degrade silently without gh (or network)
+	if ghx.IsGhAvailable() {
+		if prInfo, err := ghx.GetPRInfo(completionContext(cmd), prNumber); err == nil && prInfo.HeadRefName != "" {
+			candidates = append(candidates, prInfo.HeadRefName)
+		}
+	}
+
+	return filterCompletions(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// setupTestRepo creates a temporary git repository and changes into it
+// The original working directory is restored when the test finishes.
+func setupTestRepo(t testing.TB) string {
+	t.Helper()
+
+	repoPath := filepath.Join(t.TempDir(), "test-repo")
+	if err := os.MkdirAll(repoPath, 0755); err != nil {
+		t.Fatalf("Failed to create test repo directory: %v", err)
+	}
+
+	runGitForTest(t, repoPath, "init", "-b", "main")
+	runGitForTest(t, repoPath, "config", "user.name", "Test User")
+	runGitForTest(t, repoPath, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Test Repo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create README: %v", err)
+	}
+	runGitForTest(t, repoPath, "add", "README.md")
+	runGitForTest(t, repoPath, "commit", "-m", "Initial commit")
+
+	chdirForTest(t, repoPath)
+	return repoPath
+}
+
+// chdirForTest changes the working directory and restores it on cleanup
+func chdirForTest(t testing.TB, dir string) {
+	t.Helper()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+}
+
+// runGitForTest runs a git command in dir and fails the test on error
+func runGitForTest(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+func TestCompleteWorktreeBranches(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", filepath.Join(t.TempDir(), "login"))
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "fix/crash", filepath.Join(t.TempDir(), "crash"))
+
+	tests := []struct {
+		name       string
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{
+			name: "all worktree branches",
+			want: []string{"main", "feature/login", "fix/crash"},
+		},
+		{
+			name:       "filtered by prefix",
+			toComplete: "fe",
+			want:       []string{"feature/login"},
+		},
+		{
+			name: "no completion after first argument",
+			args: []string{"main"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := completeWorktreeBranches(&cobra.Command{}, tt.args, tt.toComplete)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completeWorktreeBranches() = %v, want %v", got, tt.want)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("completeWorktreeBranches() directive = %v, want NoFileComp", directive)
+			}
+		})
+	}
+}
+
+func TestCompleteWorktreeBranchesOutsideRepo(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	got, _ := completeWorktreeBranches(&cobra.Command{}, nil, "")
+	if len(got) != 0 {
+		t.Errorf("completeWorktreeBranches() outside repo = %v, want none", got)
+	}
+}
+
+func TestCompleteNewBranches(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "develop")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "busy", filepath.Join(t.TempDir(), "busy"))
+
+	got, _ := completeNewBranches(&cobra.Command{}, nil, "")
+	want := []string{"develop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completeNewBranches() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteConfigKeys(t *testing.T) {
+	got, _ := completeConfigKeys(&cobra.Command{}, nil, "worktree.sub")
+	want := []string{"worktree.subdirectory_prefix", "worktree.subdirectory_suffix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completeConfigKeys() = %v, want %v", got, want)
+	}
+
+	got, _ = completeConfigKeys(&cobra.Command{}, []string{"worktree.directory_format"}, "")
+	if got != nil {
+		t.Errorf("completeConfigKeys() for value = %v, want nil", got)
+	}
+}
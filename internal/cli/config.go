@@ -1,12 +1,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/editor"
+	"github.com/toritori0318/git-wt/internal/gitx"
 )
 
 func newConfigCmd() *cobra.Command {
@@ -16,11 +23,65 @@ func newConfigCmd() *cobra.Command {
 		Long: `Manage wt configuration settings.
 
 Configuration file location: ~/.config/wt/config.yaml
+(override with --config <path> or the WT_CONFIG_FILE environment variable)
+
+Use "wt config edit" to open the file in an editor.
 
 Available settings:
   worktree.directory_format     - "subdirectory" or "sibling"
   worktree.subdirectory_prefix  - Prefix for subdirectory mode (default: ".")
-  worktree.subdirectory_suffix  - Suffix for subdirectory mode (default: "-wt")`,
+  worktree.subdirectory_suffix  - Suffix for subdirectory mode (default: "-wt")
+  worktree.init_submodules      - Initialize submodules in new worktrees (default: false)
+  worktree.lfs_pull             - Run git lfs pull in new worktrees of LFS repositories (default: true)
+  worktree.nested_branch_dirs   - Keep branch slashes as nested directories in subdirectory mode (default: false)
+  worktree.sanitize_ascii_only  - Replace non-ASCII characters in worktree directory names (default: true)
+  worktree.lowercase_dirs       - Lowercase branch names in worktree directory names (default: false)
+  worktree.path_template        - Path template for new worktrees, e.g. "{base}/{repo}-trees/{branch}"
+                                  (overrides directory_format and the subdirectory prefix/suffix; default: "")
+  worktree.collision_strategy   - When the worktree path is taken: "suffix", "error" or "prompt" (default: "suffix")
+  worktree.base_dir             - Directory to create new worktrees in instead of next to the repository;
+                                  may start with ~ and use $VARIABLES (default: "")
+  worktree.remote_check         - Warn in wt new when a remote branch of the same name has different history (default: true)
+  worktree.auto_set_upstream    - Push branches created by wt new and set their upstream, like --push (default: false)
+  worktree.default_remote       - Remote new branches are pushed to (default: "origin")
+  worktree.container_dir_mode   - Permission mode of the directories created to hold worktrees, e.g. "0750"
+                                  (default: "", following the umask)
+  selector.binary               - Fuzzy finder for interactive selection, e.g. "fzf", "sk" or "fzy" (default: "fzf")
+  selector.extra_args           - Additional fuzzy finder arguments, space-separated (default: "--height=40% --reverse")
+  editor.command                - Editor for wt open and wt config edit, with arguments, e.g. "emacsclient -n"
+                                  (takes precedence over WT_EDITOR, VISUAL and EDITOR; default: "")
+  editor.args                   - Additional editor arguments, space-separated (default: "")
+  editor.gui_editors            - Editors that wt open doesn't wait for, space-separated
+                                  (default: "code idea idea64 subl open xdg-open")
+  clean.prune_remote_refs       - Delete stale remote-tracking refs after deleting a branch (default: false)
+  clean.use_trash               - Move removed worktrees to the trash instead of deleting them (default: false)
+  clean.protected_branches      - Branches wt clean never deletes: glob patterns, space-separated;
+                                  {default} is the default branch (default: "main master develop {default}")
+  update_check.enabled          - Check for a new wt release once a day (default: false)
+  prompts.default_answer        - Answer to confirmation prompts when Enter is pressed: "yes" or "no" (default: "no")
+  prompt.format                 - Output of wt current for shell prompts; placeholders: {repo}, {branch}, {path}
+                                  (default: "{repo}:{branch}")
+  performance.slow_fs_mode      - For repositories on network filesystems: limit local git commands, skip dirty
+                                  markers in selection lists, cache the worktree list for a few seconds (default: false)
+  performance.git_timeout       - Time limit for each local git command in slow_fs_mode, "0" for none (default: "10s")
+
+Environment variable overrides (take precedence over the file):
+  WT_DIRECTORY_FORMAT, WT_SUBDIRECTORY_PREFIX, WT_SUBDIRECTORY_SUFFIX,
+  WT_INIT_SUBMODULES, WT_LFS_PULL, WT_NESTED_BRANCH_DIRS,
+  WT_SANITIZE_ASCII_ONLY, WT_LOWERCASE_DIRS, WT_PATH_TEMPLATE, WT_COLLISION_STRATEGY, WT_BASE_DIR,
+  WT_REMOTE_CHECK, WT_AUTO_SET_UPSTREAM, WT_DEFAULT_REMOTE, WT_CONTAINER_DIR_MODE,
+  WT_SELECTOR_BINARY, WT_SELECTOR_EXTRA_ARGS, WT_PRUNE_REMOTE_REFS, WT_USE_TRASH, WT_PROTECTED_BRANCHES,
+  WT_UPDATE_CHECK, WT_PROMPT_DEFAULT_ANSWER, WT_PROMPT_FORMAT, WT_SLOW_FS_MODE, WT_GIT_TIMEOUT
+
+WT_FZF_OPTS is appended to the fuzzy finder arguments on every invocation.
+Per-repository editors can be set in the editor.repos section of the file.
+
+Repository configuration file: <git common dir>/wt.yaml (e.g. .git/wt.yaml)
+Same format as the configuration file and shared by all worktrees of the
+repository. Settings in it (e.g. worktree.base_dir or editor.command) take
+precedence over the configuration file; environment variables still win.
+Change it with "wt config set --repo-local", "wt config unset --repo-local"
+or "wt config edit --repo-local".`,
 	}
 
 	// Disable interspersed flags to allow arguments that start with '-'
@@ -30,34 +91,62 @@ Available settings:
 	cmd.AddCommand(newConfigListCmd())
 	cmd.AddCommand(newConfigGetCmd())
 	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigUnsetCmd())
 	cmd.AddCommand(newConfigResetCmd())
+	cmd.AddCommand(newConfigEditCmd())
 
 	return cmd
 }
 
 func newConfigListCmd() *cobra.Command {
-	return &cobra.Command{
+	return withJSON(&cobra.Command{
 		Use:   "list",
 		Short: "List all configuration settings",
-		RunE:  runConfigList,
-	}
+		Long: `List all configuration settings.
+
+With --json, the settings are printed as an object of key -> {value, source}.`,
+		RunE: runConfigList,
+	})
 }
 
 func newConfigGetCmd() *cobra.Command {
-	return &cobra.Command{
+	return withJSON(&cobra.Command{
 		Use:   "get <key>",
 		Short: "Get a configuration value",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runConfigGet,
-	}
+		Long: `Get a configuration value.
+
+With --json, the value is printed as a JSON string.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE:              runConfigGet,
+	})
+}
+
+// configScopeConfig selects the file that config set, unset and edit change
+type configScopeConfig struct {
+	repoLocal bool
+}
+
+func addRepoLocalFlag(cmd *cobra.Command, scope *configScopeConfig) {
+	cmd.Flags().BoolVar(&scope.repoLocal, "repo-local", false, "Change the repository configuration file (<git common dir>/wt.yaml) instead")
 }
 
 func newConfigSetCmd() *cobra.Command {
+	scope := &configScopeConfig{}
+
 	cmd := &cobra.Command{
-		Use:   "set <key> <value>",
+		Use:   "set [--repo-local] <key> <value>",
 		Short: "Set a configuration value",
-		Args:  cobra.ExactArgs(2),
-		RunE:  runConfigSet,
+		Long: `Set a configuration value.
+
+With --repo-local, the value is written to the repository configuration file
+(<git common dir>/wt.yaml) and applies to this repository only. Flags must come
+before the key.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runConfigSet(c, args, scope)
+		},
 		// Allow unknown flags to pass through as values
 		FParseErrWhitelist: cobra.FParseErrWhitelist{
 			UnknownFlags: true,
@@ -65,6 +154,27 @@ func newConfigSetCmd() *cobra.Command {
 	}
 	// Disable flag parsing to treat all arguments as positional
 	cmd.Flags().SetInterspersed(false)
+	addRepoLocalFlag(cmd, scope)
+	return cmd
+}
+
+func newConfigUnsetCmd() *cobra.Command {
+	scope := &configScopeConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a configuration value (revert to default)",
+		Long: `Remove a configuration value (revert to default).
+
+With --repo-local, the value is removed from the repository configuration file,
+so that the repository uses the configuration file's value again.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runConfigUnset(c, args, scope)
+		},
+	}
+	addRepoLocalFlag(cmd, scope)
 	return cmd
 }
 
@@ -76,19 +186,52 @@ func newConfigResetCmd() *cobra.Command {
 	}
 }
 
+type configEditCmdConfig struct {
+	editor string
+	scope  configScopeConfig
+}
+
+func newConfigEditCmd() *cobra.Command {
+	cfg := &configEditCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open the configuration file in an editor",
+		Long: `Open the configuration file in an editor.
+
+If the file does not exist, it is created with the current settings and comments.
+After the editor exits, the file is validated. On errors, the offending line is
+shown and you are asked whether to re-open the editor.
+
+With --repo-local, the repository configuration file (<git common dir>/wt.yaml)
+is opened instead; a new one is created with commented examples only.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runConfigEdit(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.editor, "editor", "", "Specify editor to use")
+	addRepoLocalFlag(cmd, &cfg.scope)
+	return cmd
+}
+
 func runConfigList(cmd *cobra.Command, args []string) error {
 	configPath, err := config.GetDefaultConfigPath()
 	if err != nil {
 		return fmt.Errorf("failed to get config path: %w", err)
 	}
 
-	cfg, err := config.Load(configPath)
+	cfg, err := loadEffectiveConfig(cmd.Context(), configPath)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	w := cmd.OutOrStdout()
-	printConfigList(w, cfg, configPath)
+	if jsonOutput() {
+		return printConfigListJSON(w, cfg)
+	}
+	printConfigList(w, cfg, configPath, repoConfigPath(cmd.Context()))
 	return nil
 }
 
@@ -100,9 +243,9 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get config path: %w", err)
 	}
 
-	cfg, err := config.Load(configPath)
+	cfg, err := loadEffectiveConfig(cmd.Context(), configPath)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	value, err := getConfigValue(cfg, key)
@@ -110,33 +253,78 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), value)
+	}
+
 	fmt.Fprintln(cmd.OutOrStdout(), value)
 	return nil
 }
 
-func runConfigSet(cmd *cobra.Command, args []string) error {
+func runConfigSet(cmd *cobra.Command, args []string, scope *configScopeConfig) error {
 	key := args[0]
 	value := args[1]
 
-	configPath, err := config.GetDefaultConfigPath()
+	configPath, err := scope.path(cmd.Context())
 	if err != nil {
-		return fmt.Errorf("failed to get config path: %w", err)
+		return err
 	}
 
-	cfg, err := config.Load(configPath)
+	cfg, err := scope.load(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	if err := setConfigValue(cfg, key, value); err != nil {
 		return err
 	}
+	if scope.repoLocal {
+		cfg.MarkSet(key) // Written even if it equals the default, to override the configuration file
+	}
 
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "✓ Set %s = %s\n", key, value)
+	if scope.repoLocal {
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Set %s = %s in %s\n", key, value, configPath)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Set %s = %s\n", key, value)
+	}
+	printEnvOverrideNote(cmd.OutOrStdout(), key)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string, scope *configScopeConfig) error {
+	key := args[0]
+
+	configPath, err := scope.path(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := scope.load(configPath)
+	if err != nil {
+		return err
+	}
+
+	def, removed, err := cfg.Unset(key)
+	if err != nil {
+		return err
+	}
+
+	if removed {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	if scope.repoLocal {
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Unset %s in %s (the configuration file applies)\n", key, configPath)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ Unset %s (default: %s)\n", key, def)
+	}
+	printEnvOverrideNote(cmd.OutOrStdout(), key)
 	return nil
 }
 
@@ -159,18 +347,272 @@ func runConfigReset(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func printConfigList(w io.Writer, cfg *config.Config, configPath string) {
+func runConfigEdit(cmd *cobra.Command, args []string, cfg *configEditCmdConfig) error {
+	w := cmd.OutOrStdout()
+
+	configPath, err := cfg.scope.path(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// Create the file with current settings if it doesn't exist yet
+	// A new repository file sets nothing, so that it doesn't pin every default for the repository.
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if cfg.scope.repoLocal {
+			if err := config.WriteRepoTemplate(configPath); err != nil {
+				return err
+			}
+		} else {
+			current, err := config.Load(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if err := current.WriteTemplate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	e, err := editor.FindEditor(editor.Command{Line: cfg.editor}, configuredEditor(cmd.Context(), ""))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Wait for GUI editors too, so that the file is checked after it is closed
+		opts := editor.OpenOptions{GUIEditors: configuredGUIEditors(cmd.Context()), Wait: true}
+		if err := editor.OpenWithEditor(configPath, e, opts); err != nil {
+			return err
+		}
+
+		_, loadErr := config.Load(configPath)
+		if loadErr == nil {
+			break
+		}
+
+		data, _ := os.ReadFile(configPath)
+		printConfigError(cmd.ErrOrStderr(), configPath, data, loadErr)
+
+		if reopen, _ := confirmWith(cmd.Context(), cmd.InOrStdin(), cmd.ErrOrStderr(), "Re-open editor?", ""); !reopen {
+			return fmt.Errorf("invalid configuration: %w", loadErr)
+		}
+	}
+
+	if !flagQuiet {
+		fmt.Fprintf(w, "✓ Configuration saved: %s\n", configPath)
+	}
+	return nil
+}
+
+// path returns the file to change: the configuration file, or with --repo-local the repository
+// configuration file that loadEffectiveConfig applies
+func (s *configScopeConfig) path(ctx context.Context) (string, error) {
+	if !s.repoLocal {
+		path, err := config.GetDefaultConfigPath()
+		if err != nil {
+			return "", fmt.Errorf("failed to get config path: %w", err)
+		}
+		return path, nil
+	}
+	path := repoConfigPath(ctx)
+	if path == "" {
+		return "", &UsageError{Err: fmt.Errorf("--repo-local: not in a git repository")}
+	}
+	return path, nil
+}
+
+// load loads the file at path for changing it
+func (s *configScopeConfig) load(path string) (*config.Config, error) {
+	load := config.Load
+	if s.repoLocal {
+		load = config.LoadRepoFile
+	}
+	cfg, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// printConfigError prints a configuration error along with the offending line
+func printConfigError(w io.Writer, configPath string, data []byte, err error) {
+	fmt.Fprintf(w, "Error in %s: %v\n", configPath, err)
+
+	line := config.ErrorLine(data, err)
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return
+	}
+
+	// Show the offending line with one line of context on each side
+	for i := line - 1; i <= line+1; i++ {
+		if i < 1 || i > len(lines) {
+			continue
+		}
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(w, "%s %4d | %s\n", marker, i, lines[i-1])
+	}
+}
+
+// loadEffectiveConfig loads the config file and applies the repository configuration file
+// (when run inside a repository) and environment variable overrides
+func loadEffectiveConfig(ctx context.Context, configPath string) (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if path := repoConfigPath(ctx); path != "" {
+		if err := cfg.ApplyRepoFile(path); err != nil {
+			return nil, fmt.Errorf("failed to load repository config: %w", err)
+		}
+	}
+
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// repoConfigPath returns the path of the repository configuration file, or "" outside a repository
+// The file lives in the git directory shared by all worktrees, so it is never committed.
+func repoConfigPath(ctx context.Context) string {
+	dir, err := gitx.CommonDir(ctx, "")
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, config.RepoConfigFileName)
+}
+
+type configCacheKey struct{}
+
+// configCache holds the configuration loaded during a single command invocation
+type configCache struct {
+	once sync.Once
+	cfg  *config.Config
+	err  error
+}
+
+// withConfigCache returns a copy of ctx in which loadWorktreeConfig reads the configuration only once
+// This keeps settings consistent within a command even if the file changes while it runs.
+func withConfigCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, configCacheKey{}, &configCache{})
+}
+
+// loadWorktreeConfig loads the effective configuration from the default path
+// The result is shared for the rest of the command when ctx carries a cache (see withConfigCache);
+// callers must not modify it.
+func loadWorktreeConfig(ctx context.Context) (*config.Config, error) {
+	cache, ok := ctx.Value(configCacheKey{}).(*configCache)
+	if !ok {
+		return readWorktreeConfig(ctx)
+	}
+	cache.once.Do(func() {
+		cache.cfg, cache.err = readWorktreeConfig(ctx)
+	})
+	return cache.cfg, cache.err
+}
+
+// readWorktreeConfig reads the effective configuration from the default path
+func readWorktreeConfig(ctx context.Context) (*config.Config, error) {
+	configPath, err := config.GetDefaultConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	return loadEffectiveConfig(ctx, configPath)
+}
+
+// printEnvOverrideNote warns when an environment variable shadows the value just written
+func printEnvOverrideNote(w io.Writer, key string) {
+	for _, o := range config.EnvOverrides {
+		if o.Key != key {
+			continue
+		}
+		if _, ok := os.LookupEnv(o.Env); ok {
+			fmt.Fprintf(w, "Note: %s is set and overrides this value\n", o.Env)
+		}
+	}
+}
+
+func printConfigList(w io.Writer, cfg *config.Config, configPath, repoPath string) {
 	// Check if config file exists
 	fileStatus := "not found (using defaults)"
 	if _, err := os.Stat(configPath); err == nil {
 		fileStatus = "found"
 	}
 
-	fmt.Fprintf(w, "Configuration file: %s (%s)\n\n", configPath, fileStatus)
+	fmt.Fprintf(w, "Configuration file: %s (%s)\n", configPath, fileStatus)
+	if repoPath != "" {
+		repoStatus := "not found"
+		if _, err := os.Stat(repoPath); err == nil {
+			repoStatus = "found"
+		}
+		fmt.Fprintf(w, "Repository configuration file: %s (%s)\n", repoPath, repoStatus)
+	}
+	fmt.Fprintln(w)
 	fmt.Fprintln(w, "Settings:")
-	fmt.Fprintf(w, "  worktree.directory_format     = %s\n", cfg.GetDirectoryFormat())
-	fmt.Fprintf(w, "  worktree.subdirectory_prefix  = %s\n", cfg.GetSubdirectoryPrefix())
-	fmt.Fprintf(w, "  worktree.subdirectory_suffix  = %s\n", cfg.GetSubdirectorySuffix())
+	printConfigSetting(w, cfg, "worktree.directory_format", cfg.GetDirectoryFormat())
+	printConfigSetting(w, cfg, "worktree.subdirectory_prefix", cfg.GetSubdirectoryPrefix())
+	printConfigSetting(w, cfg, "worktree.subdirectory_suffix", cfg.GetSubdirectorySuffix())
+	printConfigSetting(w, cfg, "worktree.init_submodules", strconv.FormatBool(cfg.GetInitSubmodules()))
+	printConfigSetting(w, cfg, "worktree.lfs_pull", strconv.FormatBool(cfg.GetLFSPull()))
+	printConfigSetting(w, cfg, "worktree.nested_branch_dirs", strconv.FormatBool(cfg.GetNestedBranchDirs()))
+	printConfigSetting(w, cfg, "worktree.sanitize_ascii_only", strconv.FormatBool(cfg.GetSanitizeASCIIOnly()))
+	printConfigSetting(w, cfg, "worktree.lowercase_dirs", strconv.FormatBool(cfg.GetLowercaseDirs()))
+	printConfigSetting(w, cfg, "worktree.path_template", cfg.GetPathTemplate())
+	printConfigSetting(w, cfg, "worktree.collision_strategy", cfg.GetCollisionStrategy())
+	printConfigSetting(w, cfg, "worktree.base_dir", cfg.GetBaseDir())
+	printConfigSetting(w, cfg, "worktree.remote_check", strconv.FormatBool(cfg.GetRemoteCheck()))
+	printConfigSetting(w, cfg, "worktree.auto_set_upstream", strconv.FormatBool(cfg.GetAutoSetUpstream()))
+	printConfigSetting(w, cfg, "worktree.default_remote", cfg.GetDefaultRemote())
+	printConfigSetting(w, cfg, "worktree.container_dir_mode", cfg.GetContainerDirMode())
+	printConfigSetting(w, cfg, "selector.binary", cfg.GetSelectorBinary())
+	printConfigSetting(w, cfg, "selector.extra_args", strings.Join(cfg.GetSelectorExtraArgs(), " "))
+	printConfigSetting(w, cfg, "editor.command", cfg.GetEditorCommand())
+	printConfigSetting(w, cfg, "editor.args", strings.Join(cfg.GetEditorArgs(), " "))
+	printConfigSetting(w, cfg, "editor.gui_editors", strings.Join(cfg.GetEditorGUIEditors(), " "))
+	printConfigSetting(w, cfg, "clean.prune_remote_refs", strconv.FormatBool(cfg.GetPruneRemoteRefs()))
+	printConfigSetting(w, cfg, "clean.use_trash", strconv.FormatBool(cfg.GetUseTrash()))
+	printConfigSetting(w, cfg, "clean.protected_branches", strings.Join(cfg.GetProtectedBranches(), " "))
+	printConfigSetting(w, cfg, "update_check.enabled", strconv.FormatBool(cfg.GetUpdateCheck()))
+	printConfigSetting(w, cfg, "prompts.default_answer", cfg.GetPromptDefaultAnswer())
+	printConfigSetting(w, cfg, "prompt.format", cfg.GetPromptFormat())
+	printConfigSetting(w, cfg, "performance.slow_fs_mode", strconv.FormatBool(cfg.GetSlowFSMode()))
+	printConfigSetting(w, cfg, "performance.git_timeout", cfg.GetGitTimeout().String())
+}
+
+// printConfigSetting prints a single setting, marking values that came from the environment
+// or the repository configuration file
+func printConfigSetting(w io.Writer, cfg *config.Config, key, value string) {
+	line := fmt.Sprintf("  %-29s = %s", key, value)
+	if env := cfg.EnvSource(key); env != "" {
+		line += fmt.Sprintf("  (from %s)", env)
+	} else if cfg.Source(key) == config.SourceRepo {
+		line += "  (from repository config)"
+	}
+	fmt.Fprintln(w, line)
+}
+
+// configEntry is the JSON representation of a single setting
+type configEntry struct {
+	Value  string        `json:"value"`
+	Source config.Source `json:"source"`
+}
+
+func printConfigListJSON(w io.Writer, cfg *config.Config) error {
+	entries := make(map[string]configEntry)
+	for _, key := range config.KnownKeys() {
+		value, err := getConfigValue(cfg, key)
+		if err != nil {
+			return err
+		}
+		entries[key] = configEntry{Value: value, Source: cfg.Source(key)}
+	}
+	return writeJSON(w, entries)
 }
 
 func getConfigValue(cfg *config.Config, key string) (string, error) {
@@ -181,8 +623,58 @@ func getConfigValue(cfg *config.Config, key string) (string, error) {
 		return cfg.GetSubdirectoryPrefix(), nil
 	case "worktree.subdirectory_suffix":
 		return cfg.GetSubdirectorySuffix(), nil
+	case "worktree.init_submodules":
+		return strconv.FormatBool(cfg.GetInitSubmodules()), nil
+	case "worktree.lfs_pull":
+		return strconv.FormatBool(cfg.GetLFSPull()), nil
+	case "worktree.nested_branch_dirs":
+		return strconv.FormatBool(cfg.GetNestedBranchDirs()), nil
+	case "worktree.sanitize_ascii_only":
+		return strconv.FormatBool(cfg.GetSanitizeASCIIOnly()), nil
+	case "worktree.lowercase_dirs":
+		return strconv.FormatBool(cfg.GetLowercaseDirs()), nil
+	case "worktree.path_template":
+		return cfg.GetPathTemplate(), nil
+	case "worktree.collision_strategy":
+		return cfg.GetCollisionStrategy(), nil
+	case "worktree.base_dir":
+		return cfg.GetBaseDir(), nil
+	case "worktree.remote_check":
+		return strconv.FormatBool(cfg.GetRemoteCheck()), nil
+	case "worktree.auto_set_upstream":
+		return strconv.FormatBool(cfg.GetAutoSetUpstream()), nil
+	case "worktree.default_remote":
+		return cfg.GetDefaultRemote(), nil
+	case "worktree.container_dir_mode":
+		return cfg.GetContainerDirMode(), nil
+	case "selector.binary":
+		return cfg.GetSelectorBinary(), nil
+	case "selector.extra_args":
+		return strings.Join(cfg.GetSelectorExtraArgs(), " "), nil
+	case "editor.command":
+		return cfg.GetEditorCommand(), nil
+	case "editor.args":
+		return strings.Join(cfg.GetEditorArgs(), " "), nil
+	case "editor.gui_editors":
+		return strings.Join(cfg.GetEditorGUIEditors(), " "), nil
+	case "clean.prune_remote_refs":
+		return strconv.FormatBool(cfg.GetPruneRemoteRefs()), nil
+	case "clean.use_trash":
+		return strconv.FormatBool(cfg.GetUseTrash()), nil
+	case "clean.protected_branches":
+		return strings.Join(cfg.GetProtectedBranches(), " "), nil
+	case "update_check.enabled":
+		return strconv.FormatBool(cfg.GetUpdateCheck()), nil
+	case "prompts.default_answer":
+		return cfg.GetPromptDefaultAnswer(), nil
+	case "prompt.format":
+		return cfg.GetPromptFormat(), nil
+	case "performance.slow_fs_mode":
+		return strconv.FormatBool(cfg.GetSlowFSMode()), nil
+	case "performance.git_timeout":
+		return cfg.GetGitTimeout().String(), nil
 	default:
-		return "", fmt.Errorf("unknown config key: %s", key)
+		return "", &config.UnknownKeyError{Key: key}
 	}
 }
 
@@ -194,8 +686,58 @@ func setConfigValue(cfg *config.Config, key, value string) error {
 		return cfg.SetSubdirectoryPrefix(value)
 	case "worktree.subdirectory_suffix":
 		return cfg.SetSubdirectorySuffix(value)
+	case "worktree.init_submodules":
+		return cfg.SetInitSubmodules(value)
+	case "worktree.lfs_pull":
+		return cfg.SetLFSPull(value)
+	case "worktree.nested_branch_dirs":
+		return cfg.SetNestedBranchDirs(value)
+	case "worktree.sanitize_ascii_only":
+		return cfg.SetSanitizeASCIIOnly(value)
+	case "worktree.lowercase_dirs":
+		return cfg.SetLowercaseDirs(value)
+	case "worktree.path_template":
+		return cfg.SetPathTemplate(value)
+	case "worktree.collision_strategy":
+		return cfg.SetCollisionStrategy(value)
+	case "worktree.base_dir":
+		return cfg.SetBaseDir(value)
+	case "worktree.remote_check":
+		return cfg.SetRemoteCheck(value)
+	case "worktree.auto_set_upstream":
+		return cfg.SetAutoSetUpstream(value)
+	case "worktree.default_remote":
+		return cfg.SetDefaultRemote(value)
+	case "worktree.container_dir_mode":
+		return cfg.SetContainerDirMode(value)
+	case "selector.binary":
+		return cfg.SetSelectorBinary(value)
+	case "selector.extra_args":
+		return cfg.SetSelectorExtraArgs(value)
+	case "editor.command":
+		return cfg.SetEditorCommand(value)
+	case "editor.args":
+		return cfg.SetEditorArgs(value)
+	case "editor.gui_editors":
+		return cfg.SetEditorGUIEditors(value)
+	case "clean.prune_remote_refs":
+		return cfg.SetPruneRemoteRefs(value)
+	case "clean.use_trash":
+		return cfg.SetUseTrash(value)
+	case "clean.protected_branches":
+		return cfg.SetProtectedBranches(value)
+	case "update_check.enabled":
+		return cfg.SetUpdateCheck(value)
+	case "prompts.default_answer":
+		return cfg.SetPromptDefaultAnswer(value)
+	case "prompt.format":
+		return cfg.SetPromptFormat(value)
+	case "performance.slow_fs_mode":
+		return cfg.SetSlowFSMode(value)
+	case "performance.git_timeout":
+		return cfg.SetGitTimeout(value)
 	default:
-		return fmt.Errorf("unknown config key: %s", key)
+		return &config.UnknownKeyError{Key: key}
 	}
 }
 
@@ -2,8 +2,13 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -27,7 +32,7 @@ func TestPrintConfigList(t *testing.T) {
 	configPath := "/tmp/nonexistent/config.yaml"
 
 	var buf bytes.Buffer
-	printConfigList(&buf, cfg, configPath)
+	printConfigList(&buf, cfg, configPath, "")
 
 	output := buf.String()
 	if !strings.Contains(output, "Configuration file:") {
@@ -48,6 +53,9 @@ func TestPrintConfigList(t *testing.T) {
 	if !strings.Contains(output, "-wt") {
 		t.Errorf("output should contain '-wt', got: %s", output)
 	}
+	if !strings.Contains(output, "worktree.subdirectory_prefix") {
+		t.Errorf("output should contain 'worktree.subdirectory_prefix', got: %s", output)
+	}
 }
 
 func TestPrintConfigListWithExistingFile(t *testing.T) {
@@ -66,7 +74,7 @@ func TestPrintConfigListWithExistingFile(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	printConfigList(&buf, cfg, configPath)
+	printConfigList(&buf, cfg, configPath, "")
 
 	output := buf.String()
 	if !strings.Contains(output, "Configuration file:") {
@@ -87,6 +95,7 @@ func TestGetConfigValue(t *testing.T) {
 	cfg := &config.Config{
 		Worktree: config.WorktreeConfig{
 			DirectoryFormat:    "sibling",
+			SubdirectoryPrefix: "_",
 			SubdirectorySuffix: "-custom",
 		},
 	}
@@ -102,6 +111,11 @@ func TestGetConfigValue(t *testing.T) {
 			key:  "worktree.directory_format",
 			want: "sibling",
 		},
+		{
+			name: "get subdirectory_prefix",
+			key:  "worktree.subdirectory_prefix",
+			want: "_",
+		},
 		{
 			name: "get subdirectory_suffix",
 			key:  "worktree.subdirectory_suffix",
@@ -163,6 +177,28 @@ func TestSetConfigValue(t *testing.T) {
 			value:   "invalid",
 			wantErr: true,
 		},
+		{
+			name:  "set subdirectory_prefix",
+			key:   "worktree.subdirectory_prefix",
+			value: "_",
+			check: func(cfg *config.Config) bool {
+				return cfg.Worktree.SubdirectoryPrefix == "_"
+			},
+		},
+		{
+			name:  "set subdirectory_prefix to empty",
+			key:   "worktree.subdirectory_prefix",
+			value: "",
+			check: func(cfg *config.Config) bool {
+				return cfg.Worktree.SubdirectoryPrefix == ""
+			},
+		},
+		{
+			name:    "set subdirectory_prefix with path separator",
+			key:     "worktree.subdirectory_prefix",
+			value:   "nested/",
+			wantErr: true,
+		},
 		{
 			name:  "set subdirectory_suffix",
 			key:   "worktree.subdirectory_suffix",
@@ -220,3 +256,305 @@ func TestConfigCommands(t *testing.T) {
 	// This is a simplified test - in real scenario, you might use dependency injection
 	t.Skip("CLI integration test - requires environment setup")
 }
+
+func TestPrintConfigError(t *testing.T) {
+	data := []byte("worktree:\n  directory_format: invalid\n  subdirectory_suffix: -wt\n")
+	err := &config.ValidationError{Key: "worktree.directory_format", Msg: "invalid directory_format"}
+
+	var buf bytes.Buffer
+	printConfigError(&buf, "/tmp/config.yaml", data, err)
+
+	output := buf.String()
+	if !strings.Contains(output, "invalid directory_format") {
+		t.Errorf("output should contain the error message, got: %s", output)
+	}
+	if !strings.Contains(output, ">    2 |   directory_format: invalid") {
+		t.Errorf("output should mark the offending line, got: %s", output)
+	}
+	if !strings.Contains(output, "     1 | worktree:") {
+		t.Errorf("output should include surrounding context, got: %s", output)
+	}
+}
+
+func TestPrintConfigListMarksEnvOverrides(t *testing.T) {
+	t.Setenv("WT_DIRECTORY_FORMAT", "sibling")
+
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		t.Fatalf("ApplyEnvOverrides() returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	printConfigList(&buf, cfg, "/tmp/nonexistent/config.yaml", "")
+
+	output := buf.String()
+	if !strings.Contains(output, "sibling  (from WT_DIRECTORY_FORMAT)") {
+		t.Errorf("output should mark env override, got: %s", output)
+	}
+	if strings.Contains(output, "-wt  (from") {
+		t.Errorf("output should not mark file values, got: %s", output)
+	}
+}
+
+func TestConfigSetWritesToOverriddenPath(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "alt", "wt.yaml")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "xdg"))
+
+	origOverride := config.PathOverride
+	config.PathOverride = configPath
+	defer func() { config.PathOverride = origOverride }()
+
+	cmd := newConfigSetCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	if err := runConfigSet(cmd, []string{"worktree.directory_format", "sibling"}, &configScopeConfig{}); err != nil {
+		t.Fatalf("runConfigSet() returned error: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.GetDirectoryFormat(); got != "sibling" {
+		t.Errorf("GetDirectoryFormat() = %q, want %q", got, "sibling")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "xdg", "wt", "config.yaml")); !os.IsNotExist(err) {
+		t.Errorf("default config path should not be written")
+	}
+}
+
+func TestPrintConfigListJSON(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("worktree:\n  directory_format: sibling\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := printConfigListJSON(&buf, cfg); err != nil {
+		t.Fatalf("printConfigListJSON() returned error: %v", err)
+	}
+
+	var got map[string]configEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]configEntry{
+		"worktree.directory_format":    {Value: "sibling", Source: config.SourceFile},
+		"worktree.subdirectory_prefix": {Value: ".", Source: config.SourceDefault},
+		"worktree.subdirectory_suffix": {Value: "-wt", Source: config.SourceDefault},
+		"worktree.init_submodules":     {Value: "false", Source: config.SourceDefault},
+		"worktree.lfs_pull":            {Value: "true", Source: config.SourceDefault},
+		"worktree.nested_branch_dirs":  {Value: "false", Source: config.SourceDefault},
+		"worktree.sanitize_ascii_only": {Value: "true", Source: config.SourceDefault},
+		"worktree.lowercase_dirs":      {Value: "false", Source: config.SourceDefault},
+		"worktree.path_template":       {Value: "", Source: config.SourceDefault},
+		"worktree.collision_strategy":  {Value: "suffix", Source: config.SourceDefault},
+		"worktree.base_dir":            {Value: "", Source: config.SourceDefault},
+		"selector.binary":              {Value: "fzf", Source: config.SourceDefault},
+		"selector.extra_args":          {Value: "--height=40% --reverse", Source: config.SourceDefault},
+		"editor.command":               {Value: "", Source: config.SourceDefault},
+		"editor.args":                  {Value: "", Source: config.SourceDefault},
+		"editor.gui_editors":           {Value: "code idea idea64 subl open xdg-open", Source: config.SourceDefault},
+		"clean.prune_remote_refs":      {Value: "false", Source: config.SourceDefault},
+		"clean.use_trash":              {Value: "false", Source: config.SourceDefault},
+		"clean.protected_branches":     {Value: "main master develop {default}", Source: config.SourceDefault},
+		"update_check.enabled":         {Value: "false", Source: config.SourceDefault},
+		"prompts.default_answer":       {Value: "no", Source: config.SourceDefault},
+		"prompt.format":                {Value: "{repo}:{branch}", Source: config.SourceDefault},
+		"worktree.remote_check":        {Value: "true", Source: config.SourceDefault},
+		"worktree.auto_set_upstream":   {Value: "false", Source: config.SourceDefault},
+		"worktree.default_remote":      {Value: "origin", Source: config.SourceDefault},
+		"worktree.container_dir_mode":  {Value: "", Source: config.SourceDefault},
+		"performance.slow_fs_mode":     {Value: "false", Source: config.SourceDefault},
+		"performance.git_timeout":      {Value: "10s", Source: config.SourceDefault},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("printConfigListJSON() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadWorktreeConfigReadsFileOncePerCommand(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.ConfigFileEnv, configPath)
+	if err := os.WriteFile(configPath, []byte("worktree:\n  subdirectory_suffix: -trees\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	ctx := withConfigCache(context.Background())
+	first, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		t.Fatalf("loadWorktreeConfig() returned error: %v", err)
+	}
+
+	// Any further read of the file would now fail to parse
+	if err := os.WriteFile(configPath, []byte("worktree: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		cfg, err := loadWorktreeConfig(ctx)
+		if err != nil {
+			t.Fatalf("loadWorktreeConfig() call %d re-read the file: %v", i+2, err)
+		}
+		if cfg != first || cfg.GetSubdirectorySuffix() != "-trees" {
+			t.Errorf("loadWorktreeConfig() call %d = %+v, want the first result", i+2, cfg.Worktree)
+		}
+	}
+
+	// Without a cache the file is read on every call
+	if _, err := loadWorktreeConfig(context.Background()); err == nil {
+		t.Error("loadWorktreeConfig() without cache should re-read the (now invalid) file")
+	}
+}
+
+func TestRepoConfigOverrides(t *testing.T) {
+	repoPath := setupTestRepo(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	origOverride := config.PathOverride
+	config.PathOverride = configPath
+	defer func() { config.PathOverride = origOverride }()
+
+	userConfig := "editor:\n  command: vim\n  repos:\n    test-repo: code\n"
+	if err := os.WriteFile(configPath, []byte(userConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	baseDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WT_TEST_FAST_DISK", baseDir)
+	repoConfig := "worktree:\n  base_dir: $WT_TEST_FAST_DISK\neditor:\n  command: idea\n"
+	if err := os.WriteFile(filepath.Join(repoPath, ".git", config.RepoConfigFileName), []byte(repoConfig), 0644); err != nil {
+		t.Fatalf("Failed to write repository config file: %v", err)
+	}
+
+	ctx := context.Background()
+	got, err := resolveAndValidateBaseDir(ctx, "", "/default")
+	if err != nil {
+		t.Fatalf("resolveAndValidateBaseDir() error = %v", err)
+	}
+	if got != baseDir {
+		t.Errorf("resolveAndValidateBaseDir() = %q, want %q from the repository config", got, baseDir)
+	}
+
+	// --base-dir still wins
+	flagDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := resolveAndValidateBaseDir(ctx, flagDir, "/default"); err != nil || got != flagDir {
+		t.Errorf("resolveAndValidateBaseDir(--base-dir) = %q, %v, want %q", got, err, flagDir)
+	}
+
+	// The repository's editor.command beats editor.repos from the user's file
+	if got := configuredEditor(ctx, "test-repo"); got.Line != "idea" {
+		t.Errorf("configuredEditor() = %q, want %q", got.Line, "idea")
+	}
+
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		t.Fatalf("loadWorktreeConfig() error = %v", err)
+	}
+	var buf bytes.Buffer
+	printConfigList(&buf, cfg, configPath, repoConfigPath(ctx))
+	output := buf.String()
+	if !strings.Contains(output, "Repository configuration file:") {
+		t.Errorf("output should show the repository configuration file, got: %s", output)
+	}
+	if !strings.Contains(output, baseDir+"  (from repository config)") {
+		t.Errorf("output should show the expanded base_dir and its source, got: %s", output)
+	}
+}
+
+func TestConfigSetUnsetRepoLocal(t *testing.T) {
+	repoPath := setupTestRepo(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	origOverride := config.PathOverride
+	config.PathOverride = configPath
+	defer func() { config.PathOverride = origOverride }()
+
+	scope := &configScopeConfig{repoLocal: true}
+	cmd := newConfigSetCmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetContext(context.Background())
+	// The default value is written too: it overrides the configuration file
+	if err := runConfigSet(cmd, []string{"worktree.remote_check", "true"}, scope); err != nil {
+		t.Fatalf("runConfigSet(--repo-local) returned error: %v", err)
+	}
+	if err := runConfigSet(cmd, []string{"editor.command", "idea"}, scope); err != nil {
+		t.Fatalf("runConfigSet(--repo-local) returned error: %v", err)
+	}
+
+	repoFile := filepath.Join(repoPath, ".git", config.RepoConfigFileName)
+	data, err := os.ReadFile(repoFile)
+	if err != nil {
+		t.Fatalf("the repository config file was not written: %v", err)
+	}
+	want := "worktree:\n  remote_check: true\neditor:\n  command: idea\n"
+	if string(data) != want {
+		t.Errorf("repository config file = %q, want only the set keys %q", data, want)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("the configuration file should not be written")
+	}
+
+	unsetCmd := newConfigUnsetCmd()
+	unsetCmd.SetOut(&buf)
+	unsetCmd.SetContext(context.Background())
+	if err := runConfigUnset(unsetCmd, []string{"editor.command"}, scope); err != nil {
+		t.Fatalf("runConfigUnset(--repo-local) returned error: %v", err)
+	}
+	data, err = os.ReadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "editor") || !strings.Contains(string(data), "remote_check: true") {
+		t.Errorf("unset should remove only editor.command, got: %q", data)
+	}
+}
+
+func TestConfigEditReportsErrorsOnStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses true as the editor")
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	origOverride := config.PathOverride
+	config.PathOverride = configPath
+	defer func() { config.PathOverride = origOverride }()
+	if err := os.WriteFile(configPath, []byte("worktree:\n  directory_format: nested\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	orig := isTerminal
+	isTerminal = func(io.Reader) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+
+	cmd := newConfigEditCmd()
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetIn(strings.NewReader("n\n"))
+	cmd.SetContext(context.Background())
+	if err := runConfigEdit(cmd, nil, &configEditCmdConfig{editor: "true"}); err == nil {
+		t.Fatal("runConfigEdit() error = nil, want the validation error")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout should stay empty, got: %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Error in "+configPath) || !strings.Contains(stderr.String(), "Re-open editor?") {
+		t.Errorf("the error and the prompt should go to stderr, got: %s", stderr.String())
+	}
+}
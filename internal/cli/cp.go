@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// NoChangesError represents an error when the source worktree has no uncommitted changes to copy
+type NoChangesError struct {
+	Path string
+}
+
+func (e *NoChangesError) Error() string {
+	return fmt.Sprintf("no uncommitted changes in %s", e.Path)
+}
+
+// ChangesConflictError represents an error when the changes don't apply cleanly in the destination
+type ChangesConflictError struct {
+	Path  string
+	Files []string
+}
+
+func (e *ChangesConflictError) Error() string {
+	return fmt.Sprintf("changes conflict in %s (nothing was changed):\n  %s", e.Path, strings.Join(e.Files, "\n  "))
+}
+
+type cpCmdConfig struct {
+	includeUntracked bool
+	move             bool
+	match            matchOptions
+}
+
+func newCpCmd() *cobra.Command {
+	cfg := &cpCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "cp [[source-query] dest-query]",
+		Short: "Copy uncommitted changes to another worktree",
+		Long: `Copy the uncommitted changes (staged and unstaged) of one worktree to another.
+
+With two queries, the first selects the source and the second the destination.
+With one, it selects the destination and the source is the current worktree; without
+any, the destination is selected interactively.
+
+The changes are applied with a 3-way merge (git apply --3way). They are checked first:
+if any file would conflict, the destination is left untouched and the conflicting
+files are listed. With --move, the changes are discarded from the source once they
+were applied.
+
+Examples:
+  wt cp feature                   # Copy the current changes to the feature worktree
+  wt cp --move fix                # Move them instead
+  wt cp -u feature main           # Copy from feature to main, including untracked files
+  wt cp                           # Select the destination interactively`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runCpWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&cfg.includeUntracked, "include-untracked", "u", false, "Also copy untracked files (ignored files are never copied)")
+	cmd.Flags().BoolVar(&cfg.move, "move", false, "Discard the changes from the source after applying them")
+	addSelectionFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+var cpCmd = newCpCmd()
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+}
+
+func runCpWithConfig(cmd *cobra.Command, args []string, cfg *cpCmdConfig) error {
+	ctx := cmd.Context()
+
+	sourceQuery, destQuery := "", ""
+	switch len(args) {
+	case 1:
+		destQuery = args[0]
+	case 2:
+		sourceQuery, destQuery = args[0], args[1]
+	}
+
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	worktrees := selectableWorktrees(all)
+
+	source, err := selectCpSource(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), worktrees, sourceQuery, cfg.match)
+	if err != nil {
+		return err
+	}
+
+	// The destination is any other worktree
+	var candidates []gitx.Worktree
+	for _, wt := range worktrees {
+		if !gitx.SamePath(wt.Path, source.Path) {
+			candidates = append(candidates, wt)
+		}
+	}
+	if len(candidates) == 0 {
+		return &NoWorktreesError{}
+	}
+	index, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), createDisplayItems(candidates), destQuery, selectOptions{prompt: "Select destination worktree", match: cfg.match})
+	if err != nil {
+		return err
+	}
+	dest := candidates[index]
+
+	files, err := copyChanges(ctx, source, dest, cfg)
+	if err != nil {
+		return err
+	}
+
+	printCopySuccess(cmd.OutOrStdout(), source, dest, files, cfg.move, flagQuiet)
+	return nil
+}
+
+// selectCpSource returns the worktree matching query, or the current worktree without a query
+func selectCpSource(ctx context.Context, r io.Reader, w io.Writer, worktrees []gitx.Worktree, query string, match matchOptions) (gitx.Worktree, error) {
+	if query != "" {
+		index, err := selectItem(ctx, r, w, createDisplayItems(worktrees), query, selectOptions{prompt: "Select source worktree", match: match})
+		if err != nil {
+			return gitx.Worktree{}, err
+		}
+		return worktrees[index], nil
+	}
+
+	current, err := gitx.GetCurrentWorktree(ctx)
+	if err != nil {
+		return gitx.Worktree{}, fmt.Errorf("not in a worktree: give the source worktree as the first argument")
+	}
+	return *current, nil
+}
+
+// copyChanges applies the uncommitted changes of source in dest and returns the changed files
+// With cfg.move, the changes are discarded from source afterwards.
+func copyChanges(ctx context.Context, source, dest gitx.Worktree, cfg *cpCmdConfig) ([]string, error) {
+	patch, err := os.CreateTemp("", "wt-cp-*.patch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create patch file: %w", err)
+	}
+	patch.Close()
+	defer os.Remove(patch.Name())
+
+	// Remember the untracked files now, so that --move only deletes what was copied
+	var untracked []string
+	if cfg.move && cfg.includeUntracked {
+		if untracked, err = gitx.UntrackedFiles(ctx, source.Path); err != nil {
+			return nil, fmt.Errorf("failed to list untracked files: %w", err)
+		}
+	}
+
+	files, err := gitx.WritePatch(ctx, source.Path, patch.Name(), cfg.includeUntracked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create patch: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, &NoChangesError{Path: source.Path}
+	}
+
+	if err := gitx.ApplyPatch(ctx, dest.Path, patch.Name()); err != nil {
+		var conflictErr *gitx.PatchConflictError
+		if errors.As(err, &conflictErr) {
+			return nil, &ChangesConflictError{Path: dest.Path, Files: conflictErr.Files}
+		}
+		return nil, fmt.Errorf("failed to apply changes: %w", err)
+	}
+
+	if cfg.move {
+		if err := gitx.DiscardChanges(ctx, source.Path, untracked); err != nil {
+			return nil, fmt.Errorf("changes were copied, but discarding them from %s failed: %w", source.Path, err)
+		}
+	}
+	return files, nil
+}
+
+// selectSourceWorktree returns the worktree matching query for wt new --from
+func selectSourceWorktree(ctx context.Context, r io.Reader, w io.Writer, query string) (*gitx.Worktree, error) {
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	worktrees := selectableWorktrees(all)
+	index, err := selectItem(ctx, r, w, createDisplayItems(worktrees), query, selectOptions{prompt: "Select worktree to duplicate"})
+	if err != nil {
+		return nil, err
+	}
+	return &worktrees[index], nil
+}
+
+// carryChangesToNewWorktree copies the uncommitted changes of source to the worktree wt new just created
+// at path with branch, and returns the changed files (none if source is clean). If the changes can't
+// be applied, the new worktree and branch are removed again.
+func carryChangesToNewWorktree(ctx context.Context, source gitx.Worktree, path, branch string, includeUntracked bool) ([]string, error) {
+	dest := gitx.Worktree{Path: path, Branch: branch}
+	files, err := copyChanges(ctx, source, dest, &cpCmdConfig{includeUntracked: includeUntracked})
+	if isError[*NoChangesError](err) {
+		return nil, nil
+	}
+	if err != nil {
+		removeNewWorktree(ctx, path, branch)
+		return nil, fmt.Errorf("failed to copy the uncommitted changes of %s, removed the new worktree again: %w", source.Path, err)
+	}
+	return files, nil
+}
+
+// Output functions
+
+func printDuplicatedFrom(p *progressPrinter, source *gitx.Worktree, carried []string) {
+	if source == nil {
+		return
+	}
+	p.Printf("  From: %s (%s)\n", formatBranch(*source), source.Path)
+	if len(carried) == 0 {
+		p.Printf("  No uncommitted changes to carry over\n")
+		return
+	}
+	p.Printf("  Carried over %s\n", pluralize(len(carried), "changed file"))
+}
+
+func printCopySuccess(w io.Writer, source, dest gitx.Worktree, files []string, moved, quiet bool) {
+	if quiet {
+		return
+	}
+
+	verb := "Copied"
+	if moved {
+		verb = "Moved"
+	}
+	fmt.Fprintf(w, "✓ %s changes to %s\n", verb, pluralize(len(files), "file"))
+	fmt.Fprintf(w, "  From: %s (%s)\n", formatBranch(source), source.Path)
+	fmt.Fprintf(w, "  To: %s (%s)\n", formatBranch(dest), dest.Path)
+}
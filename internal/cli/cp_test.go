@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestCopyChanges(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	destPath := filepath.Join(t.TempDir(), "dest")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "dest", destPath)
+	ctx := context.Background()
+
+	source := gitx.Worktree{Path: repoPath, Branch: "main"}
+	dest := gitx.Worktree{Path: destPath, Branch: "dest"}
+
+	if _, err := copyChanges(ctx, source, dest, &cpCmdConfig{}); !isError[*NoChangesError](err) {
+		t.Fatalf("copyChanges() without changes error = %v, want NoChangesError", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := copyChanges(ctx, source, dest, &cpCmdConfig{includeUntracked: true, move: true})
+	if err != nil {
+		t.Fatalf("copyChanges() returned error: %v", err)
+	}
+	if want := []string{"README.md", "new.txt"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("copyChanges() = %q, want %q", files, want)
+	}
+	if got := runGitForTest(t, repoPath, "status", "--porcelain"); got != "" {
+		t.Errorf("source status after --move = %q, want clean", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(destPath, "new.txt")); string(got) != "new\n" {
+		t.Errorf("new.txt in destination = %q, want %q", got, "new\n")
+	}
+
+	// Copying back conflicts with a different change and leaves the source untouched
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = copyChanges(ctx, dest, source, &cpCmdConfig{})
+	var conflictErr *ChangesConflictError
+	if !errors.As(err, &conflictErr) || !reflect.DeepEqual(conflictErr.Files, []string{"README.md"}) {
+		t.Fatalf("copyChanges() error = %v, want a conflict in README.md", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(repoPath, "README.md")); string(got) != "# Other\n" {
+		t.Errorf("README.md after conflict = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// NotInWorktreeError represents an error when the current directory is not inside a worktree
+type NotInWorktreeError struct{}
+
+func (e *NotInWorktreeError) Error() string {
+	return "current directory is not in any worktree"
+}
+
+func newCurrentCmd() *cobra.Command {
+	return asPlumbing(&cobra.Command{
+		Use:   "current",
+		Short: "Print a short identifier of the current worktree for shell prompts",
+		Long: `Print a short identifier of the current worktree, e.g. "myrepo:feature-auth".
+
+The output is formatted with prompt.format (placeholders: {repo}, {branch}, {path};
+default "{repo}:{branch}"). A detached HEAD shows its abbreviated commit as {branch}.
+
+wt current is meant to run on every prompt: it runs a single git command and reads
+prompt.format from the user configuration file and WT_PROMPT_FORMAT only (not from
+the repository configuration file). Outside a worktree it prints nothing and exits
+with code 4. Nothing is written to stderr (unless --debug).
+
+Examples:
+  PS1='$(wt current) \$ '                         # bash
+  RPROMPT='$(wt current)'                         # zsh (with setopt prompt_subst)
+  WT_PROMPT_FORMAT='{branch}' wt current`,
+		Args: cobra.NoArgs,
+		RunE: runCurrent,
+	})
+}
+
+var currentCmd = newCurrentCmd()
+
+func init() {
+	rootCmd.AddCommand(currentCmd)
+}
+
+func runCurrent(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cfg, err := loadPromptConfig()
+	if err != nil {
+		return err
+	}
+
+	// One 'git worktree list' both finds the current worktree and names the repository
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return &NotInWorktreeError{}
+	}
+	current, err := gitx.GetCurrentWorktree(ctx)
+	if err != nil || current.IsBare {
+		return &NotInWorktreeError{}
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), formatCurrent(cfg.GetPromptFormat(), worktrees[0], *current))
+	return nil
+}
+
+// loadPromptConfig reads the user configuration file and environment overrides
+// The repository configuration file is skipped: finding it would take another git command.
+// Unknown-key warnings are dropped unless --debug, as they would end up in the prompt.
+func loadPromptConfig() (*config.Config, error) {
+	if !flagDebug {
+		prev := config.WarningOutput
+		config.WarningOutput = io.Discard
+		defer func() { config.WarningOutput = prev }()
+	}
+
+	configPath, err := config.GetDefaultConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// formatCurrent expands the placeholders of format for wt in the repository whose main worktree is main
+func formatCurrent(format string, main, wt gitx.Worktree) string {
+	repo := filepath.Base(main.Path)
+	if main.IsBare {
+		// proj.git -> proj
+		repo = strings.TrimSuffix(repo, ".git")
+	}
+	branch := wt.Branch
+	if wt.IsDetached || branch == "" {
+		branch = wt.HEAD
+		if len(branch) > 7 {
+			branch = branch[:7]
+		}
+	}
+	return strings.NewReplacer("{repo}", repo, "{branch}", branch, "{path}", wt.Path).Replace(format)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestFormatCurrent(t *testing.T) {
+	main := gitx.Worktree{Path: "/src/myrepo", Branch: "main"}
+	feature := gitx.Worktree{Path: "/src/myrepo-feature-auth", Branch: "feature-auth"}
+
+	tests := []struct {
+		name   string
+		format string
+		main   gitx.Worktree
+		wt     gitx.Worktree
+		want   string
+	}{
+		{name: "default", format: "{repo}:{branch}", main: main, wt: feature, want: "myrepo:feature-auth"},
+		{name: "path", format: "{branch} {path}", main: main, wt: feature, want: "feature-auth /src/myrepo-feature-auth"},
+		{name: "detached", format: "{branch}", main: main, wt: gitx.Worktree{HEAD: "0123456789abcdef", IsDetached: true}, want: "0123456"},
+		{name: "bare repository", format: "{repo}", main: gitx.Worktree{Path: "/src/myrepo.git", IsBare: true}, wt: feature, want: "myrepo"},
+		{name: "literal text", format: "wt[{branch}]", main: main, wt: main, want: "wt[main]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCurrent(tt.format, tt.main, tt.wt); got != tt.want {
+				t.Errorf("formatCurrent(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+// runCurrentForTest runs wt current and returns its stdout, stderr and error
+func runCurrentForTest(t testing.TB) (string, string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs([]string{"current"})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestCurrent(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	featurePath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature-auth", featurePath)
+
+	chdirForTest(t, featurePath)
+	stdout, stderr, err := runCurrentForTest(t)
+	if err != nil {
+		t.Fatalf("wt current returned error: %v", err)
+	}
+	if stdout != "test-repo:feature-auth\n" || stderr != "" {
+		t.Errorf("output = %q, %q, want %q", stdout, stderr, "test-repo:feature-auth\n")
+	}
+
+	t.Setenv("WT_PROMPT_FORMAT", "{branch}@{path}")
+	stdout, _, err = runCurrentForTest(t)
+	if err != nil {
+		t.Fatalf("wt current returned error: %v", err)
+	}
+	if want := "feature-auth@" + featurePath + "\n"; stdout != want {
+		t.Errorf("output = %q, want %q", stdout, want)
+	}
+}
+
+func TestCurrentOutsideWorktree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	chdirForTest(t, t.TempDir())
+
+	stdout, stderr, err := runCurrentForTest(t)
+	if exitCode(err) != ExitNotFound {
+		t.Errorf("wt current error = %v, want exit code %d", err, ExitNotFound)
+	}
+	if stdout != "" || stderr != "" {
+		t.Errorf("output = %q, %q, want nothing", stdout, stderr)
+	}
+}
+
+// BenchmarkCurrent measures wt current, which runs on every shell prompt and should stay well under 50ms
+func BenchmarkCurrent(b *testing.B) {
+	b.Setenv("XDG_CONFIG_HOME", b.TempDir())
+	setupTestRepo(b)
+
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := runCurrentForTest(b); err != nil {
+			b.Fatalf("wt current returned error: %v", err)
+		}
+	}
+	if perOp := time.Since(start) / time.Duration(b.N); perOp > 50*time.Millisecond {
+		b.Errorf("wt current took %s per run, want under 50ms", perOp)
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/logx"
+)
+
+// deleteDirPrefix starts the names of the directories removed worktrees are moved to
+// for deletion in the background; wt __delete refuses to delete anything else.
+const deleteDirPrefix = ".wt-delete-"
+
+// deleteCommand is the hidden subcommand that deletes a moved-aside worktree directory
+const deleteCommand = "__delete"
+
+var (
+	// renameDir moves a directory (replaced in tests to simulate a rename across devices)
+	renameDir = os.Rename
+
+	// startBackgroundDelete starts the process deleting dir (replaced in tests, where the
+	// executable is the test binary)
+	startBackgroundDelete = startDetachedDelete
+)
+
+// newDeleteCmd returns the command run by the detached process; as a plumbing command it
+// prints nothing and skips the update check.
+func newDeleteCmd() *cobra.Command {
+	return asPlumbing(&cobra.Command{
+		Use:    deleteCommand + " <dir>",
+		Short:  "Delete a worktree directory moved aside by wt clean --detach-delete",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE:   runDelete,
+	})
+}
+
+var deleteCmd = newDeleteCmd()
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+	if !strings.HasPrefix(filepath.Base(dir), deleteDirPrefix) {
+		return fmt.Errorf("refusing to delete %s: not a directory created by wt clean --detach-delete", dir)
+	}
+
+	start := time.Now()
+	err := os.RemoveAll(dir)
+	c := logx.Command{Name: "wt", Args: []string{deleteCommand, dir}, Duration: time.Since(start), Err: err}
+	if err != nil {
+		c.Stderr = err.Error()
+	}
+	logx.LogCommand(c)
+	return err
+}
+
+// removeWorktreeDetached removes the worktree at wt.Path without waiting for its files to be deleted
+// The directory is moved aside next to the worktree, the worktree metadata is removed, and a detached
+// wt __delete process deletes the files. If the directory can't be moved (e.g. across devices) or the
+// process can't be started, the files are deleted right away instead, with a notice on w.
+// It reports whether the files are being deleted in the background.
+func removeWorktreeDetached(ctx context.Context, w io.Writer, wt gitx.Worktree, force bool) (bool, error) {
+	if err := checkMovable(ctx, wt, force); err != nil {
+		return false, err
+	}
+
+	deleteDir, err := moveAside(wt.Path)
+	if err != nil {
+		fmt.Fprintf(w, "⚠ Could not move the worktree aside for deletion in the background (%v); deleting it now\n", err)
+		return false, gitx.Remove(ctx, wt.Path, force)
+	}
+
+	// With the directory gone, this only removes the worktree metadata
+	if err := gitx.Remove(ctx, wt.Path, true); err != nil {
+		_ = renameDir(filepath.Join(deleteDir, filepath.Base(wt.Path)), wt.Path) // Best-effort rollback
+		_ = os.Remove(deleteDir)
+		return false, err
+	}
+
+	if err := startBackgroundDelete(deleteDir); err != nil {
+		fmt.Fprintf(w, "⚠ Could not start deletion in the background (%v); deleting it now\n", err)
+		return false, os.RemoveAll(deleteDir)
+	}
+	return true, nil
+}
+
+// checkMovable returns an error if the worktree can't be moved away before removing it from git:
+// git refuses to remove a locked worktree, and can't check a moved one for uncommitted changes
+func checkMovable(ctx context.Context, wt gitx.Worktree, force bool) error {
+	if wt.IsLocked {
+		return &WorktreeLockedError{Path: wt.Path, Reason: wt.LockReason}
+	}
+	if force {
+		return nil
+	}
+	status, err := gitx.GetStatus(ctx, wt.Path)
+	if err != nil {
+		return err
+	}
+	if status.IsDirty() {
+		return fmt.Errorf("%s contains modified or untracked files, use --force to delete it", wt.Path)
+	}
+	return nil
+}
+
+// moveAside moves the directory at path into a new directory next to it and returns the new directory
+// Staying on the same filesystem keeps the rename atomic.
+func moveAside(path string) (string, error) {
+	deleteDir, err := os.MkdirTemp(filepath.Dir(path), deleteDirPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	if err := renameDir(path, filepath.Join(deleteDir, filepath.Base(path))); err != nil {
+		_ = os.Remove(deleteDir)
+		return "", err
+	}
+	return deleteDir, nil
+}
+
+// startDetachedDelete starts wt __delete on dir as a process that outlives wt
+// The debug log file is passed on so that the process can record when it's done.
+func startDetachedDelete(dir string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{deleteCommand, dir}
+	if logx.Enabled() {
+		args = append(args, "--debug-log", logx.Path)
+	}
+
+	cmd := exec.Command(exe, args...)
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
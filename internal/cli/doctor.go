@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/ghx"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/selectx"
+	"github.com/toritori0318/git-wt/internal/tablex"
+	"github.com/toritori0318/git-wt/internal/tmux"
+)
+
+// Check status values reported by wt doctor
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is the result of a single diagnostic check
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// DoctorFailedError represents an error when one or more hard checks failed
+type DoctorFailedError struct {
+	Failures int
+}
+
+func (e *DoctorFailedError) Error() string {
+	if e.Failures == 1 {
+		return "doctor found 1 problem"
+	}
+	return fmt.Sprintf("doctor found %d problems", e.Failures)
+}
+
+func newDoctorCmd() *cobra.Command {
+	return withJSON(&cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and setup",
+		Long: `Check that wt's dependencies and setup are in place.
+
+Checks git (and its version), the current repository, gh and its login,
+fzf, tmux, the shell function, the configuration file, and that worktrees
+can be created in the configured location.
+
+Missing optional tools are reported as warnings. The command exits with a
+non-zero status only when a check fails.
+
+Examples:
+  wt doctor          # Human-readable report
+  wt doctor --json   # Machine-readable report`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	})
+}
+
+var doctorCmd = newDoctorCmd()
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	checks := runDoctorChecks(cmd.Context())
+
+	if jsonOutput() {
+		if err := writeJSON(cmd.OutOrStdout(), checks); err != nil {
+			return err
+		}
+	} else {
+		printDoctorChecks(cmd.OutOrStdout(), checks)
+	}
+
+	failures := 0
+	for _, c := range checks {
+		if c.Status == checkFail {
+			failures++
+		}
+	}
+	if failures > 0 {
+		if jsonOutput() {
+			// The failed checks are in the report
+			return &reportedError{err: &DoctorFailedError{Failures: failures}}
+		}
+		return &DoctorFailedError{Failures: failures}
+	}
+	return nil
+}
+
+// runDoctorChecks runs all diagnostic checks in display order
+func runDoctorChecks(ctx context.Context) []doctorCheck {
+	checks := []doctorCheck{checkGit(ctx)}
+
+	repoCheck, repo := checkRepo(ctx)
+	checks = append(checks, repoCheck, checkGh(), checkSelector(ctx), checkTmux(), checkShellHook())
+
+	configCheck, wtCfg := checkConfig()
+	checks = append(checks, configCheck)
+
+	// The worktree location depends on both the repository and the configuration
+	if repo != nil && wtCfg != nil {
+		checks = append(checks, checkWorktreeDir(worktreeBaseDir(repo, wtCfg)))
+	}
+
+	return checks
+}
+
+// printDoctorChecks prints one line per check, with the hint indented below
+func printDoctorChecks(w io.Writer, checks []doctorCheck) {
+	table := newTable(w, "")
+	for _, c := range checks {
+		mark, color := "✓", tablex.Green
+		switch c.Status {
+		case checkWarn:
+			mark, color = "!", tablex.Yellow
+		case checkFail:
+			mark, color = "✗", tablex.Red
+		}
+		table.AddCells(tablex.Cell{Text: mark + " " + c.Name, Color: color}, tablex.Cell{Text: c.Message})
+		if c.Hint != "" {
+			table.AddRow("", "→ "+c.Hint)
+		}
+	}
+	_ = table.Render(w)
+}
+
+func checkGit(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "git"}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		check.Status = checkFail
+		check.Message = "not found"
+		check.Hint = "install git: https://git-scm.com/downloads"
+		return check
+	}
+
+	version, err := gitx.GetVersion(ctx)
+	if err != nil {
+		check.Status = checkFail
+		check.Message = err.Error()
+		return check
+	}
+
+	if !version.AtLeast(gitx.FeatureWorktree.Version) {
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("version %s is too old (requires %s or later)", version, gitx.FeatureWorktree.Version)
+		check.Hint = "upgrade git"
+		return check
+	}
+	if !version.AtLeast(gitx.MinGitVersion) {
+		var missing []string
+		for _, f := range gitx.Features {
+			if !version.AtLeast(f.Version) {
+				missing = append(missing, fmt.Sprintf("%s (%s)", f.Name, f.Version))
+			}
+		}
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("version %s lacks %s", version, strings.Join(missing, ", "))
+		check.Hint = fmt.Sprintf("upgrade git to %s or later", gitx.MinGitVersion)
+		return check
+	}
+
+	check.Status = checkPass
+	check.Message = "version " + version.String()
+	return check
+}
+
+func checkRepo(ctx context.Context) (doctorCheck, *gitx.Repo) {
+	check := doctorCheck{Name: "repository"}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		check.Status = checkWarn
+		check.Message = "not inside a git repository"
+		check.Hint = "run wt doctor inside a repository (or pass --repo) to check the worktree location"
+		return check, nil
+	}
+
+	check.Status = checkPass
+	check.Message = repo.Root
+	return check, repo
+}
+
+func checkGh() doctorCheck {
+	check := doctorCheck{Name: "gh"}
+
+	if !ghx.IsGhAvailable() {
+		check.Status = checkWarn
+		check.Message = "not found (needed for wt pr)"
+		check.Hint = "install GitHub CLI: https://cli.github.com/"
+		return check
+	}
+
+	if !ghx.IsGhAuthenticated() {
+		check.Status = checkWarn
+		check.Message = "not logged in (needed for wt pr)"
+		check.Hint = "run: gh auth login"
+		return check
+	}
+
+	check.Status = checkPass
+	check.Message = "installed and logged in"
+	return check
+}
+
+// checkSelector checks the fuzzy finder configured in selector.binary (fzf by default)
+func checkSelector(ctx context.Context) doctorCheck {
+	selector := configuredSelector(ctx)
+	if !selectx.IsSelectorAvailable(selector) {
+		hint := "install " + selector.Binary + " or change selector.binary"
+		if selector.Binary == config.DefaultSelectorBinary {
+			hint = "install fzf for interactive selection: https://github.com/junegunn/fzf"
+		}
+		return doctorCheck{
+			Name:    selector.Binary,
+			Status:  checkWarn,
+			Message: "not found (numbered selection is used instead)",
+			Hint:    hint,
+		}
+	}
+	return doctorCheck{Name: selector.Binary, Status: checkPass, Message: "installed"}
+}
+
+func checkTmux() doctorCheck {
+	if !tmux.IsTmuxAvailable() {
+		return doctorCheck{
+			Name:    "tmux",
+			Status:  checkWarn,
+			Message: "not found (needed for wt tmux)",
+			Hint:    "install tmux: https://github.com/tmux/tmux",
+		}
+	}
+	return doctorCheck{Name: "tmux", Status: checkPass, Message: "installed"}
+}
+
+func checkShellHook() doctorCheck {
+	if !shellFunctionActive() {
+		return doctorCheck{
+			Name:    "shell function",
+			Status:  checkWarn,
+			Message: "not configured (wt go/new cannot change directory)",
+			Hint:    "run: wt hook install",
+		}
+	}
+	return doctorCheck{Name: "shell function", Status: checkPass, Message: "configured"}
+}
+
+func checkConfig() (doctorCheck, *config.Config) {
+	check := doctorCheck{Name: "config"}
+
+	configPath, err := config.GetDefaultConfigPath()
+	if err != nil {
+		check.Status = checkFail
+		check.Message = err.Error()
+		return check, nil
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("%s: %v", configPath, err)
+		check.Hint = "run: wt config edit"
+		return check, nil
+	}
+
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		check.Status = checkFail
+		check.Message = err.Error()
+		return check, nil
+	}
+
+	check.Status = checkPass
+	check.Message = configPath
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		check.Message += " (not found, using defaults)"
+	}
+	return check, cfg
+}
+
+// worktreeBaseDir returns the directory new worktrees are created in
+func worktreeBaseDir(repo *gitx.Repo, cfg *config.Config) string {
+	dir, _ := naming.WorktreeContainer(configuredBaseDir(repo, cfg), repo.Name, cfg)
+	return dir
+}
+
+// checkWorktreeDir verifies that worktrees can be created in dir
+// If dir doesn't exist yet, its nearest existing parent must be writable.
+func checkWorktreeDir(dir string) doctorCheck {
+	check := doctorCheck{Name: "worktree dir"}
+
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".wt-doctor-*")
+	if err != nil {
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("%s is not writable", existing)
+		check.Hint = "fix the directory permissions or change worktree.directory_format / worktree.subdirectory_prefix"
+		return check
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	check.Status = checkPass
+	check.Message = dir
+	return check
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestDoctorFailedError(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     string
+	}{
+		{1, "doctor found 1 problem"},
+		{3, "doctor found 3 problems"},
+	}
+
+	for _, tt := range tests {
+		err := &DoctorFailedError{Failures: tt.failures}
+		if got := err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestPrintDoctorChecks(t *testing.T) {
+	checks := []doctorCheck{
+		{Name: "git", Status: checkPass, Message: "version 2.43.0"},
+		{Name: "fzf", Status: checkWarn, Message: "not found", Hint: "install fzf"},
+		{Name: "config", Status: checkFail, Message: "invalid"},
+	}
+
+	var buf bytes.Buffer
+	printDoctorChecks(&buf, checks)
+
+	output := buf.String()
+	for _, want := range []string{"✓ git", "! fzf", "✗ config", "→ install fzf"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got: %s", want, output)
+		}
+	}
+}
+
+func TestCheckShellHook(t *testing.T) {
+	t.Setenv("WT_SHELL_FUNCTION", "")
+	if got := checkShellHook(); got.Status != checkWarn {
+		t.Errorf("checkShellHook() status = %q, want %q", got.Status, checkWarn)
+	}
+
+	t.Setenv("WT_SHELL_FUNCTION", "1")
+	if got := checkShellHook(); got.Status != checkPass {
+		t.Errorf("checkShellHook() status = %q, want %q", got.Status, checkPass)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	origOverride := config.PathOverride
+	config.PathOverride = configPath
+	defer func() { config.PathOverride = origOverride }()
+
+	check, cfg := checkConfig()
+	if check.Status != checkPass || cfg == nil {
+		t.Errorf("checkConfig() with missing file = %+v, want pass", check)
+	}
+
+	if err := os.WriteFile(configPath, []byte("worktree:\n  directory_format: invalid\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	check, cfg = checkConfig()
+	if check.Status != checkFail || cfg != nil {
+		t.Errorf("checkConfig() with invalid file = %+v, want fail", check)
+	}
+}
+
+func TestWorktreeBaseDir(t *testing.T) {
+	repo := &gitx.Repo{Root: "/work/myproject", Name: "myproject", Parent: "/work"}
+
+	cfg := &config.Config{Worktree: config.WorktreeConfig{
+		DirectoryFormat:    config.DirectoryFormatSubdirectory,
+		SubdirectoryPrefix: ".",
+		SubdirectorySuffix: "-wt",
+	}}
+	if got := worktreeBaseDir(repo, cfg); got != filepath.Join("/work", ".myproject-wt") {
+		t.Errorf("worktreeBaseDir() = %q, want %q", got, "/work/.myproject-wt")
+	}
+
+	cfg.Worktree.DirectoryFormat = "sibling"
+	if got := worktreeBaseDir(repo, cfg); got != "/work" {
+		t.Errorf("worktreeBaseDir() = %q, want %q", got, "/work")
+	}
+}
+
+func TestCheckWorktreeDir(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// A directory that doesn't exist yet is fine if its parent is writable
+	if got := checkWorktreeDir(filepath.Join(tempDir, ".repo-wt")); got.Status != checkPass {
+		t.Errorf("checkWorktreeDir() = %+v, want pass", got)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("permission checks don't apply to root")
+	}
+
+	readOnly := filepath.Join(tempDir, "readonly")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if got := checkWorktreeDir(filepath.Join(readOnly, ".repo-wt")); got.Status != checkFail {
+		t.Errorf("checkWorktreeDir() = %+v, want fail", got)
+	}
+}
+
+// fakeGitVersion makes git --version report version for the rest of the test
+// Other git commands fail: the test must not get that far.
+func fakeGitVersion(t *testing.T, version string) {
+	t.Helper()
+	t.Cleanup(gitx.SetRunner(gitVersionRunner(version)))
+}
+
+type gitVersionRunner string
+
+func (r gitVersionRunner) Run(ctx context.Context, dir string, args ...string) (string, string, error) {
+	if len(args) == 1 && args[0] == "--version" {
+		return "git version " + string(r), "", nil
+	}
+	return "", "unexpected git command", errors.New("exit status 1")
+}
+
+func TestCheckGitVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		wantStatus  string
+		wantMessage string
+	}{
+		{version: "2.43.0", wantStatus: checkPass, wantMessage: "version 2.43.0"},
+		{version: "2.20.1", wantStatus: checkWarn, wantMessage: "version 2.20.1 lacks git worktree repair (2.29.0)"},
+		{version: "2.4.0", wantStatus: checkFail, wantMessage: "version 2.4.0 is too old (requires 2.5.0 or later)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			fakeGitVersion(t, tt.version)
+			check := checkGit(context.Background())
+			if check.Status != tt.wantStatus || check.Message != tt.wantMessage {
+				t.Errorf("checkGit() = %s %q, want %s %q", check.Status, check.Message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
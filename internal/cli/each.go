@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/logx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// EachFailedError represents an error when the command of wt each failed in some worktrees
+type EachFailedError struct {
+	Failed int
+	Total  int
+}
+
+func (e *EachFailedError) Error() string {
+	return fmt.Sprintf("command failed in %d of %d worktrees", e.Failed, e.Total)
+}
+
+type eachCmdConfig struct {
+	pattern        string
+	excludeMain    bool
+	excludeCurrent bool
+	parallel       int
+	match          matchOptions
+}
+
+// eachResult is the outcome of running the command in one worktree
+type eachResult struct {
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	ExitCode int    `json:"exit_code"` // -1 if the command couldn't be run
+	Duration int64  `json:"duration_ms"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newEachCmd() *cobra.Command {
+	cfg := &eachCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "each [query] -- <command> [args...]",
+		Short: "Run a command in every worktree",
+		Long: `Run a command in every worktree, with the worktree as working directory.
+
+Each output line is prefixed with the branch name. With --parallel, the command runs
+in several worktrees at once; the output of each worktree is printed when it finishes
+so that lines don't interleave. A summary is printed at the end, and wt each exits
+non-zero if the command failed in any worktree.
+
+Restrict the worktrees with a query (matched like wt go), a --pattern glob on the
+branch name, --exclude-main and --exclude-current.
+
+With --json, prints [{path, branch, exit_code, duration_ms, stdout, stderr}] instead.
+
+Examples:
+  wt each -- git pull --rebase
+  wt each --parallel 4 -- npm ci
+  wt each --pattern 'feature/*' --exclude-main -- git status --short
+  wt each auth -- make test`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runEachWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.pattern, "pattern", "", "Only worktrees whose branch matches this glob (e.g. 'feature/*')")
+	cmd.Flags().BoolVar(&cfg.excludeMain, "exclude-main", false, "Skip the main worktree")
+	cmd.Flags().BoolVar(&cfg.excludeCurrent, "exclude-current", false, "Skip the worktree of the current directory")
+	cmd.Flags().IntVarP(&cfg.parallel, "parallel", "p", 1, "Number of worktrees to run the command in at once")
+	addMatchFlags(cmd, &cfg.match)
+
+	return withJSON(cmd)
+}
+
+var eachCmd = newEachCmd()
+
+func init() {
+	rootCmd.AddCommand(eachCmd)
+}
+
+func runEachWithConfig(cmd *cobra.Command, args []string, cfg *eachCmdConfig) error {
+	ctx := cmd.Context()
+
+	query, command, err := splitEachArgs(args, cmd.ArgsLenAtDash())
+	if err != nil {
+		return err
+	}
+	if cfg.parallel < 1 {
+		return &UsageError{Err: fmt.Errorf("invalid --parallel: %d (must be at least 1)", cfg.parallel)}
+	}
+	if _, err := path.Match(cfg.pattern, ""); err != nil {
+		return &UsageError{Err: fmt.Errorf("invalid --pattern: %w", err)}
+	}
+
+	worktrees, err := eachWorktrees(ctx, query, cfg)
+	if err != nil {
+		return err
+	}
+
+	// In JSON mode the output is captured into the results instead of printed
+	stdout, stderr := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	if jsonOutput() {
+		stdout, stderr = io.Discard, io.Discard
+	}
+	results := runInWorktrees(ctx, stdout, stderr, worktrees, command, cfg.parallel)
+
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := writeJSON(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+		if failed > 0 {
+			// The failures are in the results
+			return &reportedError{err: &EachFailedError{Failed: failed, Total: len(results)}}
+		}
+		return nil
+	}
+
+	printEachSummary(cmd.OutOrStdout(), results)
+	if failed > 0 {
+		return &EachFailedError{Failed: failed, Total: len(results)}
+	}
+	return nil
+}
+
+// splitEachArgs splits the arguments of wt each into the query and the command after "--"
+// Without "--", all arguments are the command.
+func splitEachArgs(args []string, dashAt int) (string, []string, error) {
+	var query string
+	command := args
+	if dashAt >= 0 {
+		if dashAt > 1 {
+			return "", nil, &UsageError{Err: fmt.Errorf("only one query is allowed before --, got %d", dashAt)}
+		}
+		if dashAt == 1 {
+			query = args[0]
+		}
+		command = args[dashAt:]
+	}
+	if len(command) == 0 {
+		return "", nil, &UsageError{Err: fmt.Errorf("no command given (usage: wt each [query] -- <command> [args...])")}
+	}
+	return query, command, nil
+}
+
+// eachWorktrees returns the worktrees wt each runs in, in the order of git worktree list
+func eachWorktrees(ctx context.Context, query string, cfg *eachCmdConfig) ([]gitx.Worktree, error) {
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	var mainPath, currentPath string
+	if len(all) > 0 {
+		// The main worktree is always listed first
+		mainPath = all[0].Path
+	}
+	if current, err := gitx.GetCurrentWorktree(ctx); err == nil {
+		currentPath = current.Path
+	}
+
+	var worktrees []gitx.Worktree
+	for _, wt := range selectableWorktrees(all) {
+		switch {
+		case cfg.excludeMain && wt.Path == mainPath:
+			continue
+		case cfg.excludeCurrent && wt.Path == currentPath:
+			continue
+		case cfg.pattern != "" && !matchesPattern(cfg.pattern, wt.Branch):
+			continue
+		}
+		worktrees = append(worktrees, wt)
+	}
+
+	if query != "" {
+		if worktrees, err = filterWorktreesByQuery(worktrees, query, cfg.match.useFuzzy()); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(worktrees) == 0 {
+		return nil, &NoWorktreesError{}
+	}
+	return worktrees, nil
+}
+
+// filterWorktreesByQuery returns the worktrees matching query (like wt go), keeping the list order
+// rather than the match ranking
+func filterWorktreesByQuery(worktrees []gitx.Worktree, query string, fuzzy bool) ([]gitx.Worktree, error) {
+	items := createDisplayItems(worktrees)
+	filtered, err := selectx.FilterIndexedByQuery(items, query, fuzzy)
+	if err != nil {
+		return nil, newNoMatchError(query, items)
+	}
+	keep := make([]bool, len(worktrees))
+	for _, f := range filtered {
+		keep[f.Index] = true
+	}
+	var matched []gitx.Worktree
+	for i, wt := range worktrees {
+		if keep[i] {
+			matched = append(matched, wt)
+		}
+	}
+	return matched, nil
+}
+
+// matchesPattern reports whether branch matches the glob pattern ("*" doesn't match "/")
+func matchesPattern(pattern, branch string) bool {
+	matched, _ := path.Match(pattern, branch)
+	return matched
+}
+
+// runInWorktrees runs command in each worktree, at most parallel at a time, and returns the results in order
+// Running one at a time, output is streamed as it comes; otherwise the output of each worktree is
+// buffered and printed when it finishes.
+func runInWorktrees(ctx context.Context, stdout, stderr io.Writer, worktrees []gitx.Worktree, command []string, parallel int) []eachResult {
+	results := make([]eachResult, len(worktrees))
+	var wg sync.WaitGroup
+	var outputMu sync.Mutex
+	sem := make(chan struct{}, parallel)
+
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func(r *eachResult, wt gitx.Worktree) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if parallel == 1 {
+				*r = runInWorktree(ctx, stdout, stderr, wt, command)
+				return
+			}
+
+			var outBuf, errBuf bytes.Buffer
+			*r = runInWorktree(ctx, &outBuf, &errBuf, wt, command)
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			stdout.Write(outBuf.Bytes())
+			stderr.Write(errBuf.Bytes())
+		}(&results[i], wt)
+
+		// One at a time, wait so that the worktrees run in list order
+		if parallel == 1 {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runInWorktree runs command in wt, writing its output to stdout and stderr prefixed with the branch
+func runInWorktree(ctx context.Context, stdout, stderr io.Writer, wt gitx.Worktree, command []string) eachResult {
+	result := eachResult{Path: wt.Path, Branch: wt.Branch}
+	if !pathExists(wt.Path) {
+		result.ExitCode = -1
+		result.Error = "worktree directory does not exist"
+		fmt.Fprintf(stderr, "[%s] %s: %s\n", formatBranch(wt), result.Error, wt.Path)
+		return result
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	prefix := fmt.Sprintf("[%s] ", formatBranch(wt))
+	outWriter := newPrefixWriter(stdout, prefix)
+	errWriter := newPrefixWriter(stderr, prefix)
+
+	c := exec.CommandContext(ctx, command[0], command[1:]...)
+	c.Dir = wt.Path
+	c.Env = gitx.Environ() // git in the command works on this worktree, whatever GIT_DIR says
+	c.Stdout = io.MultiWriter(outWriter, &outBuf)
+	c.Stderr = io.MultiWriter(errWriter, &errBuf)
+	c.WaitDelay = 5 * time.Second
+
+	start := time.Now()
+	err := c.Run()
+	duration := time.Since(start)
+	outWriter.Flush()
+	errWriter.Flush()
+	logx.LogCommand(logx.Command{Name: command[0], Args: command[1:], Dir: wt.Path, Duration: duration, Err: err, Stderr: errBuf.String()})
+
+	result.ExitCode = logx.ExitCode(err)
+	result.Duration = duration.Milliseconds()
+	result.Stdout = outBuf.String()
+	result.Stderr = errBuf.String()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// prefixWriter writes each line with a prefix, holding back an incomplete last line until Flush
+type prefixWriter struct {
+	w       io.Writer
+	prefix  string
+	pending []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.pending = append(p.pending, b...)
+	for {
+		i := bytes.IndexByte(p.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, p.pending[:i+1]); err != nil {
+			return 0, err
+		}
+		p.pending = p.pending[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes an incomplete last line, ending it with a newline
+func (p *prefixWriter) Flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+	fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.pending)
+	p.pending = nil
+}
+
+// Output functions
+
+func printEachSummary(w io.Writer, results []eachResult) {
+	fmt.Fprintf(w, "\nSummary:\n")
+	table := newTable(w, "  ")
+	table.SetMaxWidth(0, maxBranchWidth)
+	for _, r := range results {
+		status, color := "ok", tablex.Green
+		switch {
+		case r.ExitCode == -1:
+			status, color = "failed: "+r.Error, tablex.Red
+		case r.ExitCode != 0:
+			status, color = fmt.Sprintf("failed (exit %d)", r.ExitCode), tablex.Red
+		}
+		branch := r.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		table.AddCells(
+			tablex.Cell{Text: branch},
+			tablex.Cell{Text: status, Color: color},
+			tablex.Cell{Text: formatDuration(r.Duration)},
+			tablex.Cell{Text: r.Path},
+		)
+	}
+	_ = table.Render(w)
+}
+
+// formatDuration formats milliseconds for the summary of wt each, e.g. "1.2s"
+func formatDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitEachArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		dashAt      int
+		wantQuery   string
+		wantCommand []string
+		wantErr     bool
+	}{
+		{name: "no dash", args: []string{"git", "status"}, dashAt: -1, wantCommand: []string{"git", "status"}},
+		{name: "dash only", args: []string{"git", "status"}, dashAt: 0, wantCommand: []string{"git", "status"}},
+		{name: "query", args: []string{"auth", "make", "test"}, dashAt: 1, wantQuery: "auth", wantCommand: []string{"make", "test"}},
+		{name: "two queries", args: []string{"a", "b", "make"}, dashAt: 2, wantErr: true},
+		{name: "no command", args: []string{"auth"}, dashAt: 1, wantErr: true},
+		{name: "empty", args: nil, dashAt: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, command, err := splitEachArgs(tt.args, tt.dashAt)
+			if tt.wantErr {
+				if exitCode(err) != ExitUsage {
+					t.Errorf("splitEachArgs() error = %v, want a usage error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitEachArgs() returned error: %v", err)
+			}
+			if query != tt.wantQuery || !reflect.DeepEqual(command, tt.wantCommand) {
+				t.Errorf("splitEachArgs() = %q, %q, want %q, %q", query, command, tt.wantQuery, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestEachWorktrees(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", filepath.Join(t.TempDir(), "login"))
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "fix/crash", filepath.Join(t.TempDir(), "crash"))
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		query string
+		cfg   eachCmdConfig
+		want  []string
+	}{
+		{name: "all", want: []string{"main", "feature/login", "fix/crash"}},
+		{name: "pattern", cfg: eachCmdConfig{pattern: "feature/*"}, want: []string{"feature/login"}},
+		{name: "exclude main", cfg: eachCmdConfig{excludeMain: true}, want: []string{"feature/login", "fix/crash"}},
+		{name: "exclude current", cfg: eachCmdConfig{excludeCurrent: true}, want: []string{"feature/login", "fix/crash"}},
+		{name: "query", query: "crash", want: []string{"fix/crash"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worktrees, err := eachWorktrees(ctx, tt.query, &tt.cfg)
+			if err != nil {
+				t.Fatalf("eachWorktrees() returned error: %v", err)
+			}
+			var got []string
+			for _, wt := range worktrees {
+				got = append(got, wt.Branch)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eachWorktrees() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := eachWorktrees(ctx, "", &eachCmdConfig{pattern: "release/*"}); exitCode(err) != ExitNotFound {
+		t.Errorf("eachWorktrees() with no matches error = %v, want a not-found error", err)
+	}
+}
+
+func TestRunInWorktrees(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", filepath.Join(t.TempDir(), "feature"))
+	ctx := context.Background()
+
+	worktrees, err := eachWorktrees(ctx, "", &eachCmdConfig{})
+	if err != nil {
+		t.Fatalf("eachWorktrees() returned error: %v", err)
+	}
+
+	for _, parallel := range []int{1, 2} {
+		var stdout, stderr bytes.Buffer
+		results := runInWorktrees(ctx, &stdout, &stderr, worktrees, []string{"git", "branch", "--show-current"}, parallel)
+
+		if len(results) != 2 || results[0].Branch != "main" || results[1].Branch != "feature" {
+			t.Fatalf("parallel %d: results = %+v, want main and feature in order", parallel, results)
+		}
+		for _, r := range results {
+			if r.ExitCode != 0 || r.Stdout != r.Branch+"\n" {
+				t.Errorf("parallel %d: result = %+v, want exit 0 and the branch on stdout", parallel, r)
+			}
+		}
+		for _, want := range []string{"[main] main\n", "[feature] feature\n"} {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("parallel %d: output should contain %q, got:\n%s", parallel, want, stdout.String())
+			}
+		}
+	}
+
+	results := runInWorktrees(ctx, &bytes.Buffer{}, &bytes.Buffer{}, worktrees, []string{"git", "rev-parse", "--verify", "missing"}, 1)
+	for _, r := range results {
+		if r.ExitCode == 0 {
+			t.Errorf("result = %+v, want a failure", r)
+		}
+	}
+	var buf bytes.Buffer
+	printEachSummary(&buf, results)
+	if !strings.Contains(buf.String(), "failed (exit 128)") {
+		t.Errorf("summary should report the exit code, got:\n%s", buf.String())
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf, "[x] ")
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\nthree"))
+	w.Flush()
+
+	if want := "[x] one\n[x] two\n[x] three\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEachFailedErrorType(t *testing.T) {
+	err := error(&EachFailedError{Failed: 1, Total: 3})
+	if got := errorType(err); got != "each_failed" {
+		t.Errorf("errorType() = %q, want %q", got, "each_failed")
+	}
+	if !errors.As(withExitCode(err), new(*EachFailedError)) || exitCode(err) != ExitError {
+		t.Errorf("exitCode() = %d, want %d", exitCode(err), ExitError)
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// Exit codes of wt, so that scripts can tell failure classes apart
+// Commands passed through to git worktree exit with git's own code.
+const (
+	ExitError         = 1 // Any other failure
+	ExitUsage         = 2 // Invalid flags or arguments
+	ExitNotRepository = 3 // Not inside a git repository
+	ExitNotFound      = 4 // No worktree found or matched
+	ExitCancelled     = 5 // Cancelled by the user (selection or confirmation)
+	ExitToolMissing   = 6 // Required external tool or shell integration missing (gh, tmux, git, ...)
+)
+
+// exitCodeHelp documents the exit codes in wt --help
+const exitCodeHelp = `Exit Codes:
+  0  Success
+  1  Error
+  2  Invalid flags or arguments
+  3  Not a git repository
+  4  No worktree found or matched
+  5  Cancelled by the user
+  6  Required tool or shell integration missing (gh, tmux, git, ...)
+`
+
+// UsageError represents an invalid flag or argument
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// exitCodes maps typed errors to exit codes, most specific first
+var exitCodes = []struct {
+	code  int
+	match func(error) bool
+}{
+	{ExitUsage, isError[*UsageError]},
+	{ExitUsage, isError[*JSONUnsupportedError]},
+	{ExitUsage, isError[*InvalidPRNumberError]},
+	{ExitUsage, isError[*InvalidFunctionNameError]},
+	{ExitUsage, isError[*UnsupportedShellError]},
+	{ExitUsage, isError[*NonInteractiveError]},
+	{ExitCancelled, isError[*selectx.CancelledError]},
+	{ExitCancelled, isError[*WorktreeRemovalCancelledError]},
+	{ExitCancelled, isError[*OperationCancelledError]},
+	{ExitCancelled, isError[*RepoMismatchError]},
+	{ExitNotFound, isError[*NoWorktreesError]},
+	{ExitNotFound, isError[*NoMatchError]},
+	{ExitNotFound, isError[*NothingToUndoError]},
+	{ExitNotFound, isError[*StashNotFoundError]},
+	{ExitNotFound, isError[*WorkspaceNotFoundError]},
+	{ExitNotFound, isError[*AmbiguousMatchError]},
+	{ExitNotFound, isError[*NotInWorktreeError]},
+	{ExitNotFound, isError[*IndexOutOfRangeError]},
+	{ExitNotFound, isError[*NoRemovableWorktreesError]},
+	{ExitNotFound, isError[*NoTrashedWorktreesError]},
+	{ExitNotFound, isError[*NoLockCandidatesError]},
+	{ExitToolMissing, isError[*GhNotFoundError]},
+	{ExitToolMissing, isError[*TmuxNotFoundError]},
+	{ExitToolMissing, isError[*gitx.GitNotFoundError]},
+	{ExitToolMissing, isError[*gitx.MinVersionError]},
+	{ExitToolMissing, isError[*ShellFunctionNotConfiguredError]},
+	{ExitNotRepository, isError[*NotRepositoryError]},
+	{ExitNotRepository, gitx.IsNotRepository},
+}
+
+// exitCode returns the exit code for err
+// An ExitCodeError (e.g. the exit status of a passed-through git command) keeps its code.
+func exitCode(err error) int {
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	for _, c := range exitCodes {
+		if c.match(err) {
+			return c.code
+		}
+	}
+	return ExitError
+}
+
+// withExitCode wraps err in an ExitCodeError carrying its exit code
+func withExitCode(err error) error {
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitCodeError{Code: exitCode(err), Err: err}
+}
+
+// markUsageErrors makes cobra's flag and argument errors of cmd and its subcommands UsageErrors
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &UsageError{Err: err}
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return &UsageError{Err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
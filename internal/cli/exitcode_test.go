@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "untyped", err: errors.New("boom"), want: ExitError},
+		{name: "usage", err: &UsageError{Err: errors.New("unknown flag: --nope")}, want: ExitUsage},
+		{name: "json unsupported", err: &JSONUnsupportedError{Command: "wt tmux"}, want: ExitUsage},
+		{name: "not a repository", err: fmt.Errorf("failed to get worktrees: %w", &gitx.GitError{Args: []string{"worktree"}, Stderr: "fatal: not a git repository (or any of the parent directories): .git"}), want: ExitNotRepository},
+		{name: "other git error", err: &gitx.GitError{Args: []string{"worktree"}, Stderr: "fatal: bad revision"}, want: ExitError},
+		{name: "no worktrees", err: &NoWorktreesError{}, want: ExitNotFound},
+		{name: "no match", err: &NoMatchError{Query: "x"}, want: ExitNotFound},
+		{name: "removal cancelled", err: &WorktreeRemovalCancelledError{}, want: ExitCancelled},
+		{name: "selection cancelled", err: fmt.Errorf("selection failed: %w", &selectx.CancelledError{}), want: ExitCancelled},
+		{name: "operation cancelled", err: &OperationCancelledError{}, want: ExitCancelled},
+		{name: "gh missing", err: &GhNotFoundError{}, want: ExitToolMissing},
+		{name: "tmux missing", err: &TmuxNotFoundError{}, want: ExitToolMissing},
+		{name: "git missing", err: &gitx.GitNotFoundError{}, want: ExitToolMissing},
+		{name: "git too old", err: fmt.Errorf("failed: %w", &gitx.MinVersionError{Feature: "git worktree move"}), want: ExitToolMissing},
+		{name: "shell function missing", err: &ShellFunctionNotConfiguredError{}, want: ExitToolMissing},
+		{name: "explicit code", err: &ExitCodeError{Code: 128, Err: &NoMatchError{}}, want: 128},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+
+			var exitErr *ExitCodeError
+			if err := withExitCode(tt.err); !errors.As(err, &exitErr) || exitErr.Code != tt.want {
+				t.Errorf("withExitCode() = %v, want exit code %d", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "wt"}
+	sub := &cobra.Command{Use: "sub", Args: cobra.ExactArgs(1), RunE: func(*cobra.Command, []string) error { return nil }}
+	root.AddCommand(sub)
+	markUsageErrors(root)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "argument count", args: []string{"sub"}},
+		{name: "unknown flag", args: []string{"sub", "x", "--nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.SetArgs(tt.args)
+			root.SilenceErrors, root.SilenceUsage = true, true
+			err := root.Execute()
+			if exitCode(err) != ExitUsage {
+				t.Errorf("Execute(%q) = %v, want a usage error", tt.args, err)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/toritori0318/git-wt/internal/gitx"
@@ -26,17 +30,63 @@ func (e *IndexOutOfRangeError) Error() string {
 	return fmt.Sprintf("index out of range: %d (max: %d)", e.Index, e.Max)
 }
 
+// maxSuggestions is the number of "did you mean" names NoMatchError shows at most
+const maxSuggestions = 3
+
 // NoMatchError represents an error when no matching worktree is found
 type NoMatchError struct {
-	Query string
+	Query       string
+	Suggestions []string // Names close to the query, closest first
+	Path        bool     // The query is an existing path that isn't inside any worktree
 }
 
 func (e *NoMatchError) Error() string {
-	return fmt.Sprintf("no matching worktree found: %s", e.Query)
+	if e.Path {
+		return fmt.Sprintf("no worktree contains the path: %s (matched as a path since it exists)", e.Query)
+	}
+	msg := fmt.Sprintf("no matching worktree found: %s", e.Query)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(e.Suggestions, ", "))
+	}
+	return msg
+}
+
+// newNoMatchError returns a NoMatchError for query, suggesting the items whose names
+// (their first column, e.g. the branch) look like a typo of it
+func newNoMatchError(query string, items []string) *NoMatchError {
+	names := make([]string, len(items))
+	for i, item := range items {
+		_, text, _ := selectx.SplitIndex(item)
+		names[i], _, _ = strings.Cut(text, "\t")
+	}
+	return &NoMatchError{Query: query, Suggestions: selectx.Suggest(query, names, maxSuggestions)}
 }
 
 type goCmdConfig struct {
-	index int
+	index       int
+	aheadBehind bool
+	cd          bool
+	match       matchOptions
+}
+
+// matchOptions holds the flags controlling how a query is matched against worktrees
+// and, for commands that select worktrees, whether the fuzzy finder is used
+type matchOptions struct {
+	fuzzy   bool
+	noFuzzy bool
+	noFzf   bool
+}
+
+// addMatchFlags registers the query matching flags on cmd
+func addMatchFlags(cmd *cobra.Command, opts *matchOptions) {
+	cmd.Flags().BoolVar(&opts.fuzzy, "fuzzy", false, "Match the query as a subsequence (e.g. \"falogin\" for feature-auth-login) if no worktree contains it (default)")
+	cmd.Flags().BoolVar(&opts.noFuzzy, "no-fuzzy", false, "Only match worktrees containing the query")
+	cmd.MarkFlagsMutuallyExclusive("fuzzy", "no-fuzzy")
+}
+
+// useFuzzy reports whether the query may match as a subsequence
+func (o matchOptions) useFuzzy() bool {
+	return !o.noFuzzy
 }
 
 func newGoCmd() *cobra.Command {
@@ -48,21 +98,32 @@ func newGoCmd() *cobra.Command {
 		Long: `Navigate between worktrees.
 
 If query is not specified, select interactively (using fzf or numbered selection).
-If query is specified, filter by partial match.
+If query is specified, filter by partial match. If no worktree contains the query,
+worktrees containing its characters in order are offered instead (disable with --no-fuzzy).
+If query is an existing path (absolute, ".", or relative with a "/"), the worktree
+containing it is selected instead, e.g. to jump to the root of the current worktree.
 
 Examples:
   wt go                    # Interactive selection
   wt go feature            # Select worktree containing "feature"
-  wt go --quiet feature    # Output path only (for shell function)`,
-		Args: cobra.MaximumNArgs(1),
+  wt go falogin            # Fuzzy match, e.g. feature-auth-login
+  wt go .                  # Root of the worktree containing the current directory
+  wt go --cd feature       # Output path only (for shell function; --quiet also works)
+  wt go --ahead-behind     # Show commits ahead/behind upstream in the list
+  wt go --no-fzf           # Numbered selection even if fzf is installed`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
 		RunE: func(c *cobra.Command, args []string) error {
 			return runGoWithConfig(c, args, cfg)
 		},
 	}
 
-	cmd.Flags().IntVar(&cfg.index, "index", -1, "Non-interactive mode: select specified index")
+	cmd.Flags().IntVar(&cfg.index, "index", -1, "Non-interactive mode: select the worktree with this index (as shown in the selection list)")
+	cmd.Flags().BoolVar(&cfg.aheadBehind, "ahead-behind", false, "Show commits ahead/behind upstream for each worktree")
+	cmd.Flags().BoolVar(&cfg.cd, "cd", false, "Output only the worktree path (for cd with shell function)")
+	addSelectionFlags(cmd, &cfg.match)
 
-	return cmd
+	return withJSON(cmd)
 }
 
 var goCmd = newGoCmd()
@@ -75,6 +136,11 @@ func init() {
 func runGoWithConfig(cmd *cobra.Command, args []string, cfg *goCmdConfig) error {
 	ctx := cmd.Context()
 
+	// Check if shell function is configured when using --cd
+	if err := checkShellFunction(cfg.cd); err != nil {
+		return err
+	}
+
 	query := ""
 	if len(args) > 0 {
 		query = args[0]
@@ -85,16 +151,21 @@ func runGoWithConfig(cmd *cobra.Command, args []string, cfg *goCmdConfig) error
 	if err != nil {
 		return fmt.Errorf("failed to get worktrees: %w", err)
 	}
+	worktrees = selectableWorktrees(worktrees)
 
 	if len(worktrees) == 0 {
 		return &NoWorktreesError{}
 	}
+	suggestRepair(ctx, cmd.ErrOrStderr(), worktrees)
 
 	// Create display items
 	items := createDisplayItems(worktrees)
+	if cfg.aheadBehind {
+		addAheadBehind(ctx, worktrees, items)
+	}
 
 	// Select worktree
-	selectedIndex, err := selectWorktreeIndex(worktrees, items, cfg, query)
+	selectedIndex, err := selectWorktreeIndex(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), worktrees, items, cfg, query)
 	if err != nil {
 		return err
 	}
@@ -103,20 +174,58 @@ func runGoWithConfig(cmd *cobra.Command, args []string, cfg *goCmdConfig) error
 	selected := worktrees[selectedIndex]
 
 	// Output result
-	printGoResult(cmd.OutOrStdout(), &selected, query, flagQuiet)
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), newWorktreeJSON(selected))
+	}
+	// --quiet prints the path like --cd (hooks from older versions use it)
+	printGoResult(cmd.OutOrStdout(), &selected, query, cfg.cd || flagQuiet)
 
 	return nil
 }
 
+// selectableWorktrees excludes entries that have no working tree (a bare main repository)
+func selectableWorktrees(worktrees []gitx.Worktree) []gitx.Worktree {
+	result := make([]gitx.Worktree, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if !wt.IsBare {
+			result = append(result, wt)
+		}
+	}
+	return result
+}
+
+// createDisplayItems returns the selection list items for worktrees, e.g. "[2]\tfeature\t/path"
 func createDisplayItems(worktrees []gitx.Worktree) []string {
 	items := make([]string, len(worktrees))
 	for i, wt := range worktrees {
-		branch := formatBranch(wt)
-		items[i] = fmt.Sprintf("%s\t%s", branch, wt.Path)
+		items[i] = formatDisplayItem(wt)
 	}
 	return items
 }
 
+// formatDisplayItem formats wt for a selection list, starting with its index in 'git worktree list'
+// The index stays the same however the list is filtered, so it can be passed to --index or as the query.
+func formatDisplayItem(wt gitx.Worktree) string {
+	return selectx.IndexedItem(wt.Index, fmt.Sprintf("%s\t%s", formatBranch(wt), wt.Path))
+}
+
+// addAheadBehind appends the upstream ahead/behind counts to each display item
+// Worktrees without an upstream are left unchanged.
+func addAheadBehind(ctx context.Context, worktrees []gitx.Worktree, items []string) {
+	for i, wt := range worktrees {
+		ahead, behind, hasUpstream, err := gitx.AheadBehind(ctx, wt.Path)
+		if err != nil || !hasUpstream {
+			continue
+		}
+		items[i] += "\t" + formatAheadBehind(ahead, behind)
+	}
+}
+
+// formatAheadBehind formats ahead/behind counts, e.g. "[↑2 ↓1]"
+func formatAheadBehind(ahead, behind int) string {
+	return fmt.Sprintf("[↑%d ↓%d]", ahead, behind)
+}
+
 func formatBranch(wt gitx.Worktree) string {
 	if !wt.IsDetached {
 		return wt.Branch
@@ -130,66 +239,55 @@ func formatBranch(wt gitx.Worktree) string {
 }
 
 func selectWorktreeIndex(
+	ctx context.Context,
+	r io.Reader,
+	w io.Writer,
 	worktrees []gitx.Worktree,
 	items []string,
 	cfg *goCmdConfig,
 	query string,
 ) (int, error) {
-	// Case 1: Direct index selection
+	// Case 1: Direct index selection (the index shown in the selection list)
 	if cfg.index >= 0 {
-		if cfg.index >= len(worktrees) {
-			return 0, &IndexOutOfRangeError{
-				Index: cfg.index,
-				Max:   len(worktrees) - 1,
-			}
-		}
-		return cfg.index, nil
+		return selectItem(ctx, r, w, items, "", selectOptions{index: &cfg.index})
 	}
 
-	// Case 2: Query-based selection
-	if query != "" {
-		return selectByQuery(items, query)
+	// Case 2: Path of a file or directory inside a worktree
+	if idx, ok, err := selectByPath(ctx, worktrees, query); ok {
+		return idx, err
 	}
 
-	// Case 3: Interactive selection
-	return selectWorktree(items, "Select worktree")
+	// Case 3: Query-based or interactive selection
+	return selectItem(ctx, r, w, items, query, selectOptions{prompt: "Select worktree", match: cfg.match})
 }
 
-func selectByQuery(items []string, query string) (int, error) {
-	filtered, err := selectx.FilterByQuery(items, query)
-	if err != nil {
-		return 0, &NoMatchError{Query: query}
-	}
-
-	if len(filtered) == 1 {
-		// Auto-select if only one match
-		return filtered[0].Index, nil
-	}
-
-	// Select from multiple matches
-	filteredItems := make([]string, len(filtered))
-	for i, f := range filtered {
-		filteredItems[i] = f.Text
+// selectByPath selects the worktree containing the path query refers to
+// ok is false if query isn't a path: only ".", "..", absolute paths and relative paths with a
+// separator that exist are, so that branch names such as "feature/x" are still matched as queries.
+func selectByPath(ctx context.Context, worktrees []gitx.Worktree, query string) (idx int, ok bool, err error) {
+	if query != "." && query != ".." && !filepath.IsAbs(query) && !strings.ContainsRune(query, filepath.Separator) {
+		return 0, false, nil
 	}
-
-	idx, err := selectWorktree(filteredItems, "Select worktree")
+	path, err := gitx.AbsPath(ctx, query)
 	if err != nil {
-		return 0, err
+		return 0, false, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return 0, false, nil
 	}
 
-	return filtered[idx].Index, nil
-}
-
-func selectWorktree(items []string, prompt string) (int, error) {
-	if selectx.IsFzfAvailable() {
-		return selectx.SelectWithFzf(items, prompt)
+	found := gitx.FindWorktreeContaining(worktrees, path)
+	for i := range worktrees {
+		if &worktrees[i] == found {
+			return i, true, nil
+		}
 	}
-	return selectx.SelectWithPrompt(items, prompt)
+	return 0, true, &NoMatchError{Query: path, Path: true}
 }
 
 func printGoResult(w io.Writer, selected *gitx.Worktree, query string, quiet bool) {
 	if quiet {
-		fmt.Fprintln(w, selected.Path)
+		printCdPath(w, selected.Path, selected.Branch)
 		return
 	}
 
@@ -1,10 +1,19 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
 )
 
 func TestNoWorktreesError(t *testing.T) {
@@ -60,6 +69,27 @@ func TestNoMatchError(t *testing.T) {
 	if !strings.Contains(errMsg, "no match") {
 		t.Errorf("NoMatchError should contain 'no match', got: %s", errMsg)
 	}
+
+	pathErr := &NoMatchError{Query: "/tmp/other", Path: true}
+	if msg := pathErr.Error(); !strings.Contains(msg, "/tmp/other") || !strings.Contains(msg, "path") {
+		t.Errorf("NoMatchError for a path should mention the path matching, got: %s", msg)
+	}
+}
+
+func TestNewNoMatchErrorSuggestions(t *testing.T) {
+	items := []string{
+		formatDisplayItem(gitx.Worktree{Path: "/src/proj", Branch: "main"}),
+		formatDisplayItem(gitx.Worktree{Path: "/src/.proj-wt/feature/login", Branch: "feature/login", Index: 1}),
+		formatDisplayItem(gitx.Worktree{Path: "/src/.proj-wt/feature/search", Branch: "feature/search", Index: 2}),
+	}
+
+	err := newNoMatchError("feature/logn", items)
+	if want := "no matching worktree found: feature/logn (did you mean feature/login?)"; err.Error() != want {
+		t.Errorf("newNoMatchError() = %q, want %q", err.Error(), want)
+	}
+	if err := newNoMatchError("hotfix/payments", items); len(err.Suggestions) != 0 || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("newNoMatchError() for an unrelated query = %q, want no suggestions", err.Error())
+	}
 }
 
 func TestFormatBranch(t *testing.T) {
@@ -147,6 +177,7 @@ func TestCreateDisplayItems(t *testing.T) {
 				{
 					Branch: "feature/test",
 					Path:   "/path/to/repo-feature-test",
+					Index:  2,
 				},
 			},
 			wantCount: 2,
@@ -154,6 +185,10 @@ func TestCreateDisplayItems(t *testing.T) {
 				if len(items) != 2 {
 					t.Errorf("expected 2 items, got %d", len(items))
 				}
+				// The index column is the position in 'git worktree list', not in this list
+				if want := "[2]\tfeature/test\t/path/to/repo-feature-test"; items[1] != want {
+					t.Errorf("item = %q, want %q", items[1], want)
+				}
 			},
 		},
 		{
@@ -190,3 +225,254 @@ func TestCreateDisplayItems(t *testing.T) {
 	}
 }
 
+func TestSelectWorktreeIndex(t *testing.T) {
+	// The bare main repository (index 0) is not selectable
+	worktrees := []gitx.Worktree{
+		{Branch: "main", Path: "/work/.proj-wt/main", Index: 1},
+		{Branch: "feature", Path: "/work/.proj-wt/feature", Index: 2},
+	}
+	items := createDisplayItems(worktrees)
+
+	idx, err := selectWorktreeIndex(context.Background(), strings.NewReader(""), io.Discard, worktrees, items, &goCmdConfig{index: 2}, "")
+	if err != nil || idx != 1 {
+		t.Errorf("selectWorktreeIndex(--index 2) = %d, %v, want 1", idx, err)
+	}
+
+	for _, index := range []int{0, 3} {
+		_, err := selectWorktreeIndex(context.Background(), strings.NewReader(""), io.Discard, worktrees, items, &goCmdConfig{index: index}, "")
+		var rangeErr *IndexOutOfRangeError
+		if !errors.As(err, &rangeErr) || rangeErr.Max != 2 {
+			t.Errorf("selectWorktreeIndex(--index %d) error = %v, want IndexOutOfRangeError with max 2", index, err)
+		}
+	}
+
+	idx, err = selectWorktreeIndex(context.Background(), strings.NewReader(""), io.Discard, worktrees, items, &goCmdConfig{index: -1}, "1")
+	if err != nil || idx != 0 {
+		t.Errorf("selectWorktreeIndex(query \"1\") = %d, %v, want 0", idx, err)
+	}
+}
+
+func TestFormatAheadBehind(t *testing.T) {
+	tests := []struct {
+		ahead, behind int
+		want          string
+	}{
+		{0, 0, "[↑0 ↓0]"},
+		{2, 1, "[↑2 ↓1]"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAheadBehind(tt.ahead, tt.behind); got != tt.want {
+			t.Errorf("formatAheadBehind(%d, %d) = %q, want %q", tt.ahead, tt.behind, got, tt.want)
+		}
+	}
+}
+
+func TestAddAheadBehindWithoutUpstream(t *testing.T) {
+	repoPath := setupTestRepo(t)
+
+	worktrees := []gitx.Worktree{{Path: repoPath, Branch: "main"}}
+	items := createDisplayItems(worktrees)
+	want := items[0]
+
+	addAheadBehind(context.Background(), worktrees, items)
+	if items[0] != want {
+		t.Errorf("addAheadBehind() changed item without upstream: %q, want %q", items[0], want)
+	}
+}
+
+func TestSelectableWorktrees(t *testing.T) {
+	worktrees := []gitx.Worktree{
+		{Path: "/work/proj.git", IsBare: true},
+		{Path: "/work/.proj-wt/main", Branch: "main"},
+	}
+
+	got := selectableWorktrees(worktrees)
+	if len(got) != 1 || got[0].Branch != "main" {
+		t.Errorf("selectableWorktrees() = %+v, want only the main branch worktree", got)
+	}
+}
+
+func TestSelectByQueryFindsLowercaseDirWorktree(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Worktree.LowercaseDirs = true
+
+	path, err := naming.GenerateWorktreePathWithConfig("/work", "myproject", "Feature/JIRA-123", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join("/work", ".myproject-wt", "feature-jira-123"); path != want {
+		t.Fatalf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+
+	items := createDisplayItems([]gitx.Worktree{
+		{Branch: "main", Path: "/work/myproject"},
+		{Branch: "Feature/JIRA-123", Path: path},
+	})
+
+	// Mixed-case queries match the branch and the lowercase directory name
+	for _, query := range []string{"JIRA-123", "jira-123", "Feature-Jira", "FEATURE/jira"} {
+		idx, err := selectItem(context.Background(), strings.NewReader(""), io.Discard, items, query, selectOptions{})
+		if err != nil {
+			t.Errorf("selectItem(%q) returned error: %v", query, err)
+			continue
+		}
+		if idx != 1 {
+			t.Errorf("selectItem(%q) = %d, want 1", query, idx)
+		}
+	}
+}
+
+func TestOpenRevealFlagConflicts(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: []string{"--reveal"}},
+		{args: []string{"--editor", "code", "--wait"}},
+		{args: []string{"--reveal", "--editor", "code"}, wantErr: true},
+		{args: []string{"--reveal", "--wait"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cmd := newOpenCmd()
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("ParseFlags(%q) returned error: %v", tt.args, err)
+		}
+		if err := cmd.ValidateFlagGroups(); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateFlagGroups() for %q error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOpenEach(t *testing.T) {
+	failing := map[string]bool{"/work/b": true, "/work/c": true}
+	open := func(opened *[]string) func(string) error {
+		return func(path string) error {
+			*opened = append(*opened, path)
+			if failing[path] {
+				return errors.New("exit status 1")
+			}
+			return nil
+		}
+	}
+
+	t.Run("all paths are opened despite failures", func(t *testing.T) {
+		var opened []string
+		var errOut bytes.Buffer
+		err := openEach(&errOut, []string{"/work/a", "/work/b", "/work/c"}, open(&opened))
+
+		var openErr *OpenFailedError
+		if !errors.As(err, &openErr) || openErr.Failed != 2 || openErr.Total != 3 {
+			t.Fatalf("openEach() error = %v, want OpenFailedError for 2 of 3", err)
+		}
+		if want := []string{"/work/a", "/work/b", "/work/c"}; !reflect.DeepEqual(opened, want) {
+			t.Errorf("openEach() opened %q, want %q", opened, want)
+		}
+		for _, want := range []string{"Failed to open /work/b: exit status 1", "Failed to open /work/c: exit status 1"} {
+			if !strings.Contains(errOut.String(), want) {
+				t.Errorf("openEach() output missing %q:\n%s", want, errOut.String())
+			}
+		}
+	})
+
+	t.Run("single path returns its error", func(t *testing.T) {
+		var opened []string
+		var errOut bytes.Buffer
+		err := openEach(&errOut, []string{"/work/b"}, open(&opened))
+		if err == nil || err.Error() != "exit status 1" {
+			t.Errorf("openEach() error = %v, want the error of the path", err)
+		}
+		if errOut.Len() != 0 {
+			t.Errorf("openEach() output = %q, want none", errOut.String())
+		}
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		var opened []string
+		if err := openEach(io.Discard, []string{"/work/a", "/work/d"}, open(&opened)); err != nil {
+			t.Errorf("openEach() returned error: %v", err)
+		}
+	})
+}
+
+func TestRunGoCd(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	featurePath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", featurePath)
+
+	run := func(cfg *goCmdConfig) (string, error) {
+		var buf bytes.Buffer
+		cmd := newGoCmd()
+		cmd.SetOut(&buf)
+		cmd.SetErr(io.Discard)
+		cmd.SetContext(context.Background())
+		err := runGoWithConfig(cmd, []string{"login"}, cfg)
+		return buf.String(), err
+	}
+
+	// --cd requires the shell function
+	t.Setenv("WT_SHELL_FUNCTION", "")
+	if _, err := run(&goCmdConfig{cd: true, index: -1}); !errors.As(err, new(*ShellFunctionNotConfiguredError)) {
+		t.Errorf("runGoWithConfig() error = %v, want ShellFunctionNotConfiguredError", err)
+	}
+
+	t.Setenv("WT_SHELL_FUNCTION", "1")
+	output, err := run(&goCmdConfig{cd: true, index: -1})
+	if err != nil {
+		t.Fatalf("runGoWithConfig() returned error: %v", err)
+	}
+	if output != featurePath+"\n" {
+		t.Errorf("output = %q, want %q", output, featurePath+"\n")
+	}
+
+	// Without --cd, the destination comes with a hint
+	output, err = run(&goCmdConfig{index: -1})
+	if err != nil {
+		t.Fatalf("runGoWithConfig() returned error: %v", err)
+	}
+	if !strings.HasPrefix(output, "Destination: "+featurePath+"\n") {
+		t.Errorf("output = %q, want the destination and a hint", output)
+	}
+}
+
+func TestSelectByPath(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	featurePath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", featurePath)
+	if err := os.Mkdir(filepath.Join(featurePath, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	worktrees, err := gitx.List(context.Background())
+	if err != nil {
+		t.Fatalf("gitx.List() error = %v", err)
+	}
+
+	tests := []struct {
+		query  string
+		ok     bool
+		branch string
+	}{
+		{".", true, "main"},
+		{filepath.Join(featurePath, "sub"), true, "feature/login"},
+		{"feature/login", false, ""}, // Not an existing path: matched as a query
+		{"login", false, ""},
+	}
+	for _, tt := range tests {
+		idx, ok, err := selectByPath(context.Background(), worktrees, tt.query)
+		if ok != tt.ok || err != nil || (ok && worktrees[idx].Branch != tt.branch) {
+			t.Errorf("selectByPath(%q) = %d, %v, %v, want %q", tt.query, idx, ok, err, tt.branch)
+		}
+	}
+
+	outside := t.TempDir()
+	_, ok, err := selectByPath(context.Background(), worktrees, outside)
+	var noMatch *NoMatchError
+	if !ok || !errors.As(err, &noMatch) || !noMatch.Path {
+		t.Errorf("selectByPath(%q) = %v, %v, want NoMatchError for the path", outside, ok, err)
+	}
+}
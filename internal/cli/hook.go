@@ -4,6 +4,11 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,9 +25,31 @@ var (
 
 	//go:embed hook_fish.fish
 	fishHook string
+
+	//go:embed hook_powershell.ps1
+	powershellHook string
 )
 
-var supportedShells = []string{"bash", "zsh", "fish"}
+var supportedShells = []string{"bash", "zsh", "fish", "powershell", "pwsh"}
+
+const (
+	// defaultFunctionName is the default name of the shell wrapper function
+	defaultFunctionName = "wt"
+	// functionNamePlaceholder is replaced with the wrapper function name in hook scripts
+	functionNamePlaceholder = "__WT_NAME__"
+)
+
+// functionNameRegex matches names that are valid shell function identifiers in all supported shells
+var functionNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// InvalidFunctionNameError represents an error when the wrapper function name is not a valid identifier
+type InvalidFunctionNameError struct {
+	Name string
+}
+
+func (e *InvalidFunctionNameError) Error() string {
+	return fmt.Sprintf("invalid function name: %q (must start with a letter or underscore and contain only letters, digits, '_' or '-')", e.Name)
+}
 
 // UnsupportedShellError represents an error when an unsupported shell is specified
 type UnsupportedShellError struct {
@@ -36,21 +63,24 @@ func (e *UnsupportedShellError) Error() string {
 }
 
 type hookCmdConfig struct {
-	// Future extensions (e.g., --format, --output-file, etc.)
+	name string // Wrapper function name (default: "wt")
 }
 
 func newHookCmd() *cobra.Command {
 	cfg := &hookCmdConfig{}
 
 	cmd := &cobra.Command{
-		Use:   "hook <shell>",
+		Use:   "hook [shell]",
 		Short: "Output shell hook scripts",
 		Long: `Output shell hook scripts to stdout.
 
 To enable actual directory navigation with wt go command,
 this script must be added to your shell configuration file.
 
-Supported shells: bash, zsh, fish
+Supported shells: bash, zsh, fish, powershell (pwsh)
+
+If shell is omitted, it is detected from $SHELL (or, if that is not set,
+from the parent process).
 
 Examples:
   # Bash
@@ -63,8 +93,21 @@ Examples:
 
   # Fish
   wt hook fish > ~/.config/fish/functions/wt.fish
-  exec fish`,
-		Args: cobra.ExactArgs(1),
+  exec fish
+
+  # PowerShell (adds to profile.ps1)
+  Add-Content $PROFILE 'Invoke-Expression (& wt hook powershell | Out-String)'
+  . $PROFILE
+
+  # Detect the shell automatically
+  eval "$(wt hook)"
+
+  # Use a different function name (the wt binary is still invoked)
+  eval "$(wt hook zsh --name gwt)"
+
+  # Or let wt edit your shell configuration file
+  wt hook install`,
+		Args: cobra.MaximumNArgs(1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return supportedShells, cobra.ShellCompDirectiveNoFileComp
@@ -78,6 +121,10 @@ Examples:
 		DisableAutoGenTag: true,
 	}
 
+	cmd.Flags().StringVar(&cfg.name, "name", defaultFunctionName, "Name of the generated shell function")
+
+	cmd.AddCommand(newHookInstallCmd())
+
 	return cmd
 }
 
@@ -89,7 +136,16 @@ func init() {
 }
 
 func runHookWithConfig(cmd *cobra.Command, args []string, cfg *hookCmdConfig) error {
-	shell := args[0]
+	var shell, detectedFrom string
+	if len(args) > 0 {
+		shell = args[0]
+	} else {
+		detected, from, err := detectShell()
+		if err != nil {
+			return err
+		}
+		shell, detectedFrom = detected, from
+	}
 
 	// Validate shell
 	if err := validateShell(shell); err != nil {
@@ -97,17 +153,54 @@ func runHookWithConfig(cmd *cobra.Command, args []string, cfg *hookCmdConfig) er
 	}
 
 	// Get shell script
-	script, err := getShellScript(shell)
+	script, err := getShellScript(shell, cfg.name)
 	if err != nil {
 		return err
 	}
 
-	// Output script
+	// Output script (all supported shells use '#' comments)
+	if detectedFrom != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "# wt hook: detected shell %s (from %s)\n", shell, detectedFrom)
+	}
 	printHookScript(cmd.OutOrStdout(), script)
 
 	return nil
 }
 
+// parentProcessName returns the command name of the parent process, or "" if unknown
+// It is a variable so tests can replace it.
+var parentProcessName = func() string {
+	ppid := os.Getppid()
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", ppid)); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	output, err := exec.Command("ps", "-p", strconv.Itoa(ppid), "-o", "comm=").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// detectShell returns the user's shell and where it was detected from
+// $SHELL is used when set; otherwise the parent process name is tried.
+func detectShell() (string, string, error) {
+	shellPath, from := os.Getenv("SHELL"), "$SHELL"
+	if shellPath == "" {
+		shellPath, from = parentProcessName(), "parent process"
+	}
+	if shellPath == "" {
+		return "", "", &UnsupportedShellError{Shell: "(unknown: $SHELL is not set)", SupportedShells: supportedShells}
+	}
+
+	// Login shells may be reported as "-zsh"; Windows names end in ".exe"
+	shell := strings.ToLower(filepath.Base(shellPath))
+	shell = strings.TrimPrefix(strings.TrimSuffix(shell, ".exe"), "-")
+	if err := validateShell(shell); err != nil {
+		return "", "", err
+	}
+	return shell, from, nil
+}
+
 func validateShell(shell string) error {
 	normalizedShell := strings.ToLower(strings.TrimSpace(shell))
 
@@ -123,16 +216,26 @@ func validateShell(shell string) error {
 	}
 }
 
-func getShellScript(shell string) (string, error) {
+func getShellScript(shell, name string) (string, error) {
+	if name == "" {
+		name = defaultFunctionName
+	}
+	if !functionNameRegex.MatchString(name) {
+		return "", &InvalidFunctionNameError{Name: name}
+	}
+
 	normalizedShell := strings.ToLower(strings.TrimSpace(shell))
 
+	var script string
 	switch normalizedShell {
 	case "bash":
-		return bashHook, nil
+		script = bashHook
 	case "zsh":
-		return zshHook, nil
+		script = zshHook
 	case "fish":
-		return fishHook, nil
+		script = fishHook
+	case "powershell", "pwsh":
+		script = powershellHook
 	default:
 		// Should not reach here as validateShell already checked
 		return "", &UnsupportedShellError{
@@ -140,6 +243,8 @@ func getShellScript(shell string) (string, error) {
 			SupportedShells: supportedShells,
 		}
 	}
+
+	return strings.ReplaceAll(script, functionNamePlaceholder, name), nil
 }
 
 func printHookScript(w io.Writer, script string) {
@@ -1,9 +1,17 @@
 # wt - Git worktree helper
 # Shell function: wt go / any command --cd executes actual cd
+# After changing directory, WT_CURRENT_WORKTREE and WT_CURRENT_BRANCH are exported
+# (wt prints "path<TAB>branch" with --porcelain-cd, terminated by NUL with --null;
+# a line without a tab is just the path)
+#
+# Prompt: wt current prints e.g. "myrepo:feature-auth" (prompt.format) and nothing outside a worktree
+#   PS1='$(command wt current) '"$PS1"
 
-function wt() {
+function __WT_NAME__() {
   # Set environment variable to indicate shell function is active
   export WT_SHELL_FUNCTION=1
+  # Identify this shell, so that wt knows which repository it last worked on here
+  export WT_SESSION_ID=$$
   if [[ "$1" == "go" ]]; then
     shift
     # Fast-path: delegate help/version directly to binary
@@ -16,9 +24,20 @@ function wt() {
       esac
     done
 
-    local out
-    out="$(command wt go --quiet "$@")"
-    local code=$?
+    # Read NUL-separated fields: the output, then the exit code. With --null, wt ends
+    # "path<TAB>branch" with a NUL byte; versions without --null ignore the flag and
+    # print a line instead, which leaves one field less.
+    local part out code nul=0
+    local -a fields=()
+    while IFS= read -r -d '' part; do
+      fields+=("$part")
+    done < <(command wt --null --porcelain-cd go --cd "$@"; printf '\0%d\0' "$?")
+    out="${fields[0]}" code="${fields[${#fields[@]}-1]}"
+    if (( ${#fields[@]} > 2 )); then
+      nul=1
+    else
+      out="${out%$'\n'}"
+    fi
 
     # If command failed, print output and return code
     if (( code != 0 )); then
@@ -26,26 +45,41 @@ function wt() {
       return $code
     fi
 
-    # Only cd when output is exactly one line and is a directory
-    if [[ -n "$out" && "$out" != *$'\n'* && -d "$out" ]]; then
-      builtin cd -- "$out" || return 1
+    # Only cd when output is a directory (a single line, unless it is NUL-terminated)
+    local dir="${out%$'\t'*}" branch=""
+    [[ "$out" == *$'\t'* ]] && branch="${out##*$'\t'}"
+    if [[ -n "$dir" && ( $nul == 1 || "$out" != *$'\n'* ) && -d "$dir" ]]; then
+      builtin cd -- "$dir" || return 1
+      export WT_CURRENT_WORKTREE="$dir" WT_CURRENT_BRANCH="$branch"
     else
       # Not a path: show the output (help, usage, etc.)
       printf '%s\n' "$out"
     fi
   elif [[ "$*" == *"--cd"* ]]; then
     # If --cd flag exists, get path and cd
-    local out
-    out="$(command wt "$@")"
-    local code=$?
+    # Same fields as for go above
+    local part out code nul=0
+    local -a fields=()
+    while IFS= read -r -d '' part; do
+      fields+=("$part")
+    done < <(command wt --null --porcelain-cd "$@"; printf '\0%d\0' "$?")
+    out="${fields[0]}" code="${fields[${#fields[@]}-1]}"
+    if (( ${#fields[@]} > 2 )); then
+      nul=1
+    else
+      out="${out%$'\n'}"
+    fi
 
     if (( code != 0 )); then
       printf '%s\n' "$out"
       return $code
     fi
 
-    if [[ -n "$out" && "$out" != *$'\n'* && -d "$out" ]]; then
-      builtin cd -- "$out" || return 1
+    local dir="${out%$'\t'*}" branch=""
+    [[ "$out" == *$'\t'* ]] && branch="${out##*$'\t'}"
+    if [[ -n "$dir" && ( $nul == 1 || "$out" != *$'\n'* ) && -d "$dir" ]]; then
+      builtin cd -- "$dir" || return 1
+      export WT_CURRENT_WORKTREE="$dir" WT_CURRENT_BRANCH="$branch"
     else
       printf '%s\n' "$out"
     fi
@@ -55,22 +89,6 @@ function wt() {
   fi
 }
 
-# Bash completion
-_wt_completion() {
-  local cur="${COMP_WORDS[COMP_CWORD]}"
-  local prev="${COMP_WORDS[COMP_CWORD-1]}"
-
-  # Subcommands
-  if [[ $COMP_CWORD -eq 1 ]]; then
-    COMPREPLY=($(compgen -W "new go clean open pr hook help" -- "$cur"))
-    return
-  fi
-
-  # Complete branch names for wt go
-  if [[ "${COMP_WORDS[1]}" == "go" ]] && [[ $COMP_CWORD -eq 2 ]]; then
-    local branches=$(git worktree list --porcelain 2>/dev/null | grep "^branch" | awk '{print $2}' | sed 's|refs/heads/||')
-    COMPREPLY=($(compgen -W "$branches" -- "$cur"))
-    return
-  fi
-}
-complete -F _wt_completion wt
+# Bash completion: the dynamic completion of "wt completion bash", also for this function's name
+eval "$(command wt completion bash)"
+complete -o default -F __start_wt __WT_NAME__
@@ -1,9 +1,16 @@
 # wt - Git worktree helper
 # Shell function: wt go / any command --cd executes actual cd
+# After changing directory, WT_CURRENT_WORKTREE and WT_CURRENT_BRANCH are exported
+# (wt prints "path<TAB>branch" with --porcelain-cd; a line without a tab is just the path)
+#
+# Prompt: wt current prints e.g. "myrepo:feature-auth" (prompt.format) and nothing outside a worktree
+#   function fish_right_prompt; command wt current; end
 
-function wt
+function __WT_NAME__
     # Set environment variable to indicate shell function is active
     set -gx WT_SHELL_FUNCTION 1
+    # Identify this shell, so that wt knows which repository it last worked on here
+    set -gx WT_SESSION_ID $fish_pid
     if test (count $argv) -gt 0; and test $argv[1] = "go"
         set -e argv[1]
         # Fast-path: delegate help/version directly to binary
@@ -15,7 +22,7 @@ function wt
             end
         end
 
-        set -l out (command wt go --quiet $argv)
+        set -l out (command wt go --cd --porcelain-cd $argv)
         set -l code $status
 
         # If command failed, print output and return code
@@ -25,15 +32,18 @@ function wt
         end
 
         # Only cd when output is exactly one line and is a directory
-        if test -n "$out"; and not string match -q '*\n*' "$out"; and test -d "$out"
-            cd "$out"
+        set -l parts (string split -m 1 \t -- "$out")
+        if test -n "$parts[1]"; and not string match -q '*\n*' "$out"; and test -d "$parts[1]"
+            cd "$parts[1]"
+            set -gx WT_CURRENT_WORKTREE "$parts[1]"
+            set -gx WT_CURRENT_BRANCH "$parts[2]"
         else
             # Not a path: show the output
             printf '%s\n' "$out"
         end
     else if contains -- --cd $argv
         # If --cd flag exists, get path and cd
-        set -l out (command wt $argv)
+        set -l out (command wt --porcelain-cd $argv)
         set -l code $status
 
         if test $code -ne 0
@@ -41,8 +51,11 @@ function wt
             return $code
         end
 
-        if test -n "$out"; and not string match -q '*\n*' "$out"; and test -d "$out"
-            cd "$out"
+        set -l parts (string split -m 1 \t -- "$out")
+        if test -n "$parts[1]"; and not string match -q '*\n*' "$out"; and test -d "$parts[1]"
+            cd "$parts[1]"
+            set -gx WT_CURRENT_WORKTREE "$parts[1]"
+            set -gx WT_CURRENT_BRANCH "$parts[2]"
         else
             printf '%s\n' "$out"
         end
@@ -52,17 +65,8 @@ function wt
     end
 end
 
-# Completion configuration
-complete -c wt -f
-
-# Subcommand completion
-complete -c wt -n "__fish_use_subcommand" -a "new" -d "Create new worktree"
-complete -c wt -n "__fish_use_subcommand" -a "go" -d "Navigate between worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "clean" -d "Remove worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "open" -d "Open worktree in editor"
-complete -c wt -n "__fish_use_subcommand" -a "pr" -d "Create worktree for PR review"
-complete -c wt -n "__fish_use_subcommand" -a "hook" -d "Output shell hook scripts"
-complete -c wt -n "__fish_use_subcommand" -a "help" -d "Show help"
-
-# Branch name completion for wt go
-complete -c wt -n "__fish_seen_subcommand_from go" -a "(git worktree list --porcelain 2>/dev/null | grep '^branch' | awk '{print \$2}' | sed 's|refs/heads/||')"
+# Completion: the dynamic completion of "wt completion fish", also for this function's name
+command wt completion fish | source
+if test __WT_NAME__ != wt
+    complete -c __WT_NAME__ --wraps wt
+end
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// hookBlockStart and hookBlockEnd delimit the block managed by wt hook install
+	hookBlockStart = "# >>> wt hook >>>"
+	hookBlockEnd   = "# <<< wt hook <<<"
+)
+
+type hookInstallCmdConfig struct {
+	name   string
+	dryRun bool
+	remove bool
+}
+
+func newHookInstallCmd() *cobra.Command {
+	cfg := &hookInstallCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "install [shell]",
+		Short: "Add the shell hook to your shell configuration file",
+		Long: `Add the shell hook to your shell configuration file.
+
+The shell is detected from $SHELL when omitted. The hook is added as a
+clearly delimited block, so running this command again does not add duplicates.
+
+Files:
+  bash:       ~/.bashrc
+  zsh:        $ZDOTDIR/.zshrc (or ~/.zshrc)
+  fish:       $XDG_CONFIG_HOME/fish/functions/<name>.fish (function file is written directly)
+  powershell: PowerShell profile (Microsoft.PowerShell_profile.ps1)
+
+Examples:
+  wt hook install            # Detect shell and install
+  wt hook install zsh        # Install for zsh
+  wt hook install --dry-run  # Show what would change
+  wt hook install --remove   # Uninstall`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return supportedShells, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			return runHookInstall(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.name, "name", defaultFunctionName, "Name of the generated shell function")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Show the change without writing it")
+	cmd.Flags().BoolVar(&cfg.remove, "remove", false, "Remove the hook instead of installing it")
+
+	return cmd
+}
+
+func runHookInstall(cmd *cobra.Command, args []string, cfg *hookInstallCmdConfig) error {
+	w := cmd.OutOrStdout()
+
+	var shell string
+	if len(args) > 0 {
+		shell = args[0]
+	} else {
+		detected, _, err := detectShell()
+		if err != nil {
+			return err
+		}
+		shell = detected
+	}
+
+	if err := validateShell(shell); err != nil {
+		return err
+	}
+	return installHook(w, strings.ToLower(strings.TrimSpace(shell)), cfg)
+}
+
+// installHook adds the hook for shell to its configuration file (or removes it with cfg.remove)
+// Installing again replaces the block, so the file never gets the hook twice.
+func installHook(w io.Writer, shell string, cfg *hookInstallCmdConfig) error {
+	if !functionNameRegex.MatchString(cfg.name) {
+		return &InvalidFunctionNameError{Name: cfg.name}
+	}
+
+	path, err := hookRCFile(shell, cfg.name)
+	if err != nil {
+		return err
+	}
+
+	// Fish autoloads functions from a dedicated file, so write the script there directly
+	if shell == "fish" {
+		return installFishFunction(w, path, cfg)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var updated string
+	var changed bool
+	if cfg.remove {
+		updated, changed = removeHookBlock(string(current))
+	} else {
+		updated, changed = installHookBlock(string(current), hookBlock(shell, cfg.name))
+	}
+
+	return writeHookChange(w, path, updated, changed, cfg)
+}
+
+// installFishFunction writes (or removes) the fish function file
+func installFishFunction(w io.Writer, path string, cfg *hookInstallCmdConfig) error {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	exists := err == nil
+
+	// Never touch a function file that wt didn't write
+	if exists && !strings.HasPrefix(string(current), hookBlockStart) {
+		return fmt.Errorf("%s exists and was not created by wt hook install; remove it manually", path)
+	}
+
+	if cfg.remove {
+		if !exists {
+			fmt.Fprintf(w, "No wt hook found in %s\n", path)
+			return nil
+		}
+		if cfg.dryRun {
+			fmt.Fprintf(w, "Would remove %s\n", path)
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		fmt.Fprintf(w, "✓ Removed %s\n", path)
+		return nil
+	}
+
+	script, err := getShellScript("fish", cfg.name)
+	if err != nil {
+		return err
+	}
+	content := hookBlockStart + "\n" + script
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += hookBlockEnd + "\n"
+
+	return writeHookChange(w, path, content, string(current) != content, cfg)
+}
+
+// writeHookChange writes the updated rc file content and reports what changed
+func writeHookChange(w io.Writer, path, content string, changed bool, cfg *hookInstallCmdConfig) error {
+	if !changed {
+		if cfg.remove {
+			fmt.Fprintf(w, "No wt hook found in %s\n", path)
+		} else {
+			fmt.Fprintf(w, "wt hook is already installed in %s\n", path)
+		}
+		return nil
+	}
+
+	if cfg.dryRun {
+		action := "add the following to"
+		if cfg.remove {
+			action = "remove the wt hook block from"
+		}
+		fmt.Fprintf(w, "Would %s %s\n", action, path)
+		if !cfg.remove {
+			fmt.Fprintln(w, extractHookBlock(content))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if cfg.remove {
+		fmt.Fprintf(w, "✓ Removed wt hook from %s\n", path)
+		return nil
+	}
+	fmt.Fprintf(w, "✓ Installed wt hook in %s\n", path)
+	fmt.Fprintln(w, "Restart your shell or run: exec $SHELL")
+	return nil
+}
+
+// hookRCFile returns the configuration file the hook is installed into
+func hookRCFile(shell, name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		return filepath.Join(configHome, "fish", "functions", name+".fish"), nil
+	case "powershell", "pwsh":
+		if runtime.GOOS == "windows" {
+			return filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), nil
+		}
+		return filepath.Join(configHome, "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	default:
+		return "", &UnsupportedShellError{Shell: shell, SupportedShells: supportedShells}
+	}
+}
+
+// hookInstalled reports whether the hook block of wt hook install is in the configuration file of shell
+func hookInstalled(shell, name string) bool {
+	path, err := hookRCFile(shell, name)
+	if err != nil {
+		return false
+	}
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), hookBlockStart)
+}
+
+// hookBlock returns the delimited block that loads the hook for the given shell
+func hookBlock(shell, name string) string {
+	hookArgs := shell
+	if name != defaultFunctionName {
+		hookArgs += " --name " + name
+	}
+
+	line := fmt.Sprintf(`eval "$(wt hook %s)"`, hookArgs)
+	if shell == "powershell" || shell == "pwsh" {
+		line = fmt.Sprintf(`Invoke-Expression (& wt hook %s | Out-String)`, hookArgs)
+	}
+
+	return hookBlockStart + "\n" + line + "\n" + hookBlockEnd + "\n"
+}
+
+// installHookBlock adds or replaces the wt hook block in content
+func installHookBlock(content, block string) (string, bool) {
+	start := strings.Index(content, hookBlockStart)
+	end := strings.Index(content, hookBlockEnd)
+	if start >= 0 && end > start {
+		// Replace the existing block (including its trailing newline)
+		end += len(hookBlockEnd)
+		if end < len(content) && content[end] == '\n' {
+			end++
+		}
+		updated := content[:start] + block + content[end:]
+		return updated, updated != content
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block, true
+}
+
+// removeHookBlock removes the wt hook block from content
+func removeHookBlock(content string) (string, bool) {
+	start := strings.Index(content, hookBlockStart)
+	end := strings.Index(content, hookBlockEnd)
+	if start < 0 || end < start {
+		return content, false
+	}
+
+	end += len(hookBlockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	// Drop the blank separator line added on install
+	before := content[:start]
+	if strings.HasSuffix(before, "\n\n") {
+		before = before[:len(before)-1]
+	}
+	return before + content[end:], true
+}
+
+// extractHookBlock returns the wt hook block within content (without the trailing newline)
+func extractHookBlock(content string) string {
+	start := strings.Index(content, hookBlockStart)
+	end := strings.Index(content, hookBlockEnd)
+	if start < 0 || end < start {
+		return strings.TrimRight(content, "\n")
+	}
+	return content[start : end+len(hookBlockEnd)]
+}
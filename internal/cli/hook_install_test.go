@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallHookBlock(t *testing.T) {
+	block := hookBlock("bash", "wt")
+
+	tests := []struct {
+		name        string
+		content     string
+		want        string
+		wantChanged bool
+	}{
+		{
+			name:        "empty file",
+			content:     "",
+			want:        block,
+			wantChanged: true,
+		},
+		{
+			name:        "appends after existing content",
+			content:     "export PATH=$HOME/bin:$PATH",
+			want:        "export PATH=$HOME/bin:$PATH\n\n" + block,
+			wantChanged: true,
+		},
+		{
+			name:        "already installed",
+			content:     "alias ll='ls -l'\n\n" + block,
+			want:        "alias ll='ls -l'\n\n" + block,
+			wantChanged: false,
+		},
+		{
+			name:        "replaces outdated block",
+			content:     "a\n" + hookBlockStart + "\nold\n" + hookBlockEnd + "\nb\n",
+			want:        "a\n" + block + "b\n",
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := installHookBlock(tt.content, block)
+			if got != tt.want {
+				t.Errorf("installHookBlock() = %q, want %q", got, tt.want)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("installHookBlock() changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestRemoveHookBlock(t *testing.T) {
+	original := "alias ll='ls -l'\n"
+	installed, _ := installHookBlock(original, hookBlock("zsh", "wt"))
+
+	got, changed := removeHookBlock(installed)
+	if !changed {
+		t.Errorf("removeHookBlock() changed = false, want true")
+	}
+	if got != original {
+		t.Errorf("removeHookBlock() = %q, want %q", got, original)
+	}
+
+	if _, changed := removeHookBlock(original); changed {
+		t.Errorf("removeHookBlock() without block changed = true, want false")
+	}
+}
+
+func TestHookBlock(t *testing.T) {
+	tests := []struct {
+		shell string
+		name  string
+		want  string
+	}{
+		{shell: "bash", name: "wt", want: `eval "$(wt hook bash)"`},
+		{shell: "zsh", name: "gwt", want: `eval "$(wt hook zsh --name gwt)"`},
+		{shell: "powershell", name: "wt", want: `Invoke-Expression (& wt hook powershell | Out-String)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got := hookBlock(tt.shell, tt.name)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("hookBlock() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookRCFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("ZDOTDIR", "")
+
+	tests := []struct {
+		name    string
+		shell   string
+		zdotdir string
+		want    string
+	}{
+		{name: "bash", shell: "bash", want: filepath.Join(home, ".bashrc")},
+		{name: "zsh", shell: "zsh", want: filepath.Join(home, ".zshrc")},
+		{name: "zsh with ZDOTDIR", shell: "zsh", zdotdir: "/opt/zsh", want: filepath.Join("/opt/zsh", ".zshrc")},
+		{name: "fish", shell: "fish", want: filepath.Join(home, ".config", "fish", "functions", "wt.fish")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ZDOTDIR", tt.zdotdir)
+			got, err := hookRCFile(tt.shell, "wt")
+			if err != nil {
+				t.Fatalf("hookRCFile() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hookRCFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHookInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	rcFile := filepath.Join(home, ".zshrc")
+	if err := os.WriteFile(rcFile, []byte("export EDITOR=vim\n"), 0644); err != nil {
+		t.Fatalf("Failed to write rc file: %v", err)
+	}
+
+	run := func(cfg *hookInstallCmdConfig) string {
+		var buf bytes.Buffer
+		cmd := newHookInstallCmd()
+		cmd.SetOut(&buf)
+		if cfg.name == "" {
+			cfg.name = defaultFunctionName
+		}
+		if err := runHookInstall(cmd, nil, cfg); err != nil {
+			t.Fatalf("runHookInstall() returned error: %v", err)
+		}
+		return buf.String()
+	}
+	read := func() string {
+		data, err := os.ReadFile(rcFile)
+		if err != nil {
+			t.Fatalf("Failed to read rc file: %v", err)
+		}
+		return string(data)
+	}
+
+	// Dry run does not write
+	out := run(&hookInstallCmdConfig{dryRun: true})
+	if !strings.Contains(out, "Would add") || strings.Contains(read(), hookBlockStart) {
+		t.Errorf("dry run should only print the change, output: %s", out)
+	}
+
+	// Install twice: only one block
+	run(&hookInstallCmdConfig{})
+	out = run(&hookInstallCmdConfig{})
+	if !strings.Contains(out, "already installed") {
+		t.Errorf("second install should report already installed, got: %s", out)
+	}
+	if n := strings.Count(read(), hookBlockStart); n != 1 {
+		t.Errorf("rc file contains %d hook blocks, want 1", n)
+	}
+
+	// Remove restores the original content
+	run(&hookInstallCmdConfig{remove: true})
+	if got := read(); got != "export EDITOR=vim\n" {
+		t.Errorf("rc file after remove = %q, want original content", got)
+	}
+}
@@ -0,0 +1,75 @@
+# wt - Git worktree helper
+# Shell function: wt go / any command --cd executes actual cd
+# After changing directory, WT_CURRENT_WORKTREE and WT_CURRENT_BRANCH are exported
+# (wt prints "path<TAB>branch" with --porcelain-cd; a line without a tab is just the path)
+#
+# Prompt: wt current prints e.g. "myrepo:feature-auth" (prompt.format) and nothing outside a worktree
+#   function prompt { "$(wt.exe current) PS $($executionContext.SessionState.Path.CurrentLocation)> " }
+
+function __WT_NAME__ {
+    # Set environment variable to indicate shell function is active
+    $env:WT_SHELL_FUNCTION = "1"
+    # Identify this shell, so that wt knows which repository it last worked on here
+    $env:WT_SESSION_ID = "$PID"
+
+    # Resolve the wt executable (not this function)
+    $wtExe = (Get-Command -Name wt -CommandType Application -ErrorAction Stop | Select-Object -First 1).Source
+
+    if ($args.Count -gt 0 -and $args[0] -eq "go") {
+        $rest = @($args | Select-Object -Skip 1)
+        # Fast-path: delegate help/version directly to binary
+        foreach ($arg in $rest) {
+            if ($arg -in @("-h", "--help", "help", "--version")) {
+                & $wtExe go @rest
+                return
+            }
+        }
+
+        $out = & $wtExe go --cd --porcelain-cd @rest
+        $code = $LASTEXITCODE
+
+        # If command failed, print output and return code
+        if ($code -ne 0) {
+            if ($out) { $out | Write-Output }
+            $global:LASTEXITCODE = $code
+            return
+        }
+
+        # Only cd when output is exactly one line and is a directory
+        $parts = if ($out -is [string]) { $out -split "`t", 2 } else { @() }
+        if ($parts.Count -gt 0 -and $parts[0] -ne "" -and (Test-Path -LiteralPath $parts[0] -PathType Container)) {
+            Set-Location -LiteralPath $parts[0]
+            $env:WT_CURRENT_WORKTREE = $parts[0]
+            $env:WT_CURRENT_BRANCH = if ($parts.Count -gt 1) { $parts[1] } else { "" }
+        } else {
+            # Not a path: show the output (help, usage, etc.)
+            if ($out) { $out | Write-Output }
+        }
+    } elseif ($args -contains "--cd") {
+        # If --cd flag exists, get path and cd
+        $out = & $wtExe --porcelain-cd @args
+        $code = $LASTEXITCODE
+
+        if ($code -ne 0) {
+            if ($out) { $out | Write-Output }
+            $global:LASTEXITCODE = $code
+            return
+        }
+
+        $parts = if ($out -is [string]) { $out -split "`t", 2 } else { @() }
+        if ($parts.Count -gt 0 -and $parts[0] -ne "" -and (Test-Path -LiteralPath $parts[0] -PathType Container)) {
+            Set-Location -LiteralPath $parts[0]
+            $env:WT_CURRENT_WORKTREE = $parts[0]
+            $env:WT_CURRENT_BRANCH = if ($parts.Count -gt 1) { $parts[1] } else { "" }
+        } else {
+            if ($out) { $out | Write-Output }
+        }
+    } else {
+        # Delegate other commands to binary
+        & $wtExe @args
+    }
+}
+
+# PowerShell completion: the dynamic completion of "wt completion powershell", also for this function's name
+& (Get-Command -Name wt -CommandType Application | Select-Object -First 1).Source completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -CommandName __WT_NAME__ -ScriptBlock ${__wtCompleterBlock}
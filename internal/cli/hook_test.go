@@ -2,6 +2,10 @@ package cli
 
 import (
 	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -43,8 +47,18 @@ func TestValidateShell(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "unsupported shell returns error",
+			name:    "powershell is supported",
 			shell:   "powershell",
+			wantErr: false,
+		},
+		{
+			name:    "pwsh is supported",
+			shell:   "pwsh",
+			wantErr: false,
+		},
+		{
+			name:    "unsupported shell returns error",
+			shell:   "tcsh",
 			wantErr: true,
 		},
 		{
@@ -96,6 +110,18 @@ func TestGetShellScript(t *testing.T) {
 			wantErr: false,
 			wantLen: true,
 		},
+		{
+			name:    "powershell returns script",
+			shell:   "powershell",
+			wantErr: false,
+			wantLen: true,
+		},
+		{
+			name:    "pwsh returns script",
+			shell:   "pwsh",
+			wantErr: false,
+			wantLen: true,
+		},
 		{
 			name:    "uppercase is normalized",
 			shell:   "BASH",
@@ -112,7 +138,7 @@ func TestGetShellScript(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			script, err := getShellScript(tt.shell)
+			script, err := getShellScript(tt.shell, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getShellScript() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -129,6 +155,96 @@ func TestGetShellScript(t *testing.T) {
 	}
 }
 
+func TestShellScriptsUseCdOutput(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			script, err := getShellScript(shell, "")
+			if err != nil {
+				t.Fatalf("getShellScript(%q) returned error: %v", shell, err)
+			}
+			for _, want := range []string{"go --cd", "--porcelain-cd", "WT_CURRENT_WORKTREE", "WT_CURRENT_BRANCH"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("%s script does not contain %q", shell, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBashHookExportsCurrentWorktree(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	script, err := getShellScript("bash", "")
+	if err != nil {
+		t.Fatalf("getShellScript() returned error: %v", err)
+	}
+
+	// A stub wt printing the NUL-terminated line of --porcelain-cd --null, or the line or
+	// only the path like older versions
+	binDir := t.TempDir()
+	target := t.TempDir()
+	oddTarget := filepath.Join(t.TempDir(), "new\nline ")
+	if err := os.Mkdir(oddTarget, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	stub := `#!/bin/sh
+if [ "$1" = "completion" ]; then
+  exit 0
+elif [ -n "$WT_STUB_PATH_ONLY" ]; then
+  echo "$WT_STUB_TARGET"
+elif [ "$1" = "--null" ] && [ -z "$WT_STUB_NO_NULL" ]; then
+  printf '%s\tfeature/auth\0' "$WT_STUB_TARGET"
+else
+  printf '%s\tfeature/auth\n' "$WT_STUB_TARGET"
+fi
+`
+	if err := os.WriteFile(filepath.Join(binDir, "wt"), []byte(stub), 0755); err != nil {
+		t.Fatalf("failed to write stub: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     string
+		target   string
+		pathOnly bool
+		noNull   bool
+		want     string
+	}{
+		{name: "go", args: "go feature", want: target + "|" + target + "|feature/auth"},
+		{name: "new --cd", args: "new feature/auth --cd", want: target + "|" + target + "|feature/auth"},
+		{name: "path only", args: "go feature", pathOnly: true, want: target + "|" + target + "|"},
+		{name: "without --null", args: "go feature", noNull: true, want: target + "|" + target + "|feature/auth"},
+		{name: "newline in path", args: "go feature", target: oddTarget, want: oddTarget + "|" + oddTarget + "|feature/auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(bash, "--norc", "--noprofile", "-c", script+"\nwt "+tt.args+` && printf '%s|%s|%s' "$PWD" "$WT_CURRENT_WORKTREE" "$WT_CURRENT_BRANCH"`)
+			cmd.Dir = t.TempDir()
+			stubTarget := target
+			if tt.target != "" {
+				stubTarget = tt.target
+			}
+			cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"), "WT_STUB_TARGET="+stubTarget)
+			if tt.pathOnly {
+				cmd.Env = append(cmd.Env, "WT_STUB_PATH_ONLY=1")
+			}
+			if tt.noNull {
+				cmd.Env = append(cmd.Env, "WT_STUB_NO_NULL=1")
+			}
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("bash failed: %v\n%s", err, out)
+			}
+			if string(out) != tt.want {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
 func TestPrintHookScript(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -195,8 +311,34 @@ func TestRunHookWithConfig(t *testing.T) {
 			},
 		},
 		{
-			name:    "unsupported shell returns error",
+			name:    "powershell outputs script",
 			args:    []string{"powershell"},
+			wantErr: false,
+			check: func(t *testing.T, output string) {
+				if !strings.Contains(output, "function wt") {
+					t.Errorf("output doesn't contain powershell function definition")
+				}
+				if !strings.Contains(output, "Set-Location") {
+					t.Errorf("output doesn't change directory with Set-Location")
+				}
+				if !strings.Contains(output, "$env:WT_SHELL_FUNCTION") {
+					t.Errorf("output doesn't set WT_SHELL_FUNCTION")
+				}
+			},
+		},
+		{
+			name:    "pwsh outputs script",
+			args:    []string{"pwsh"},
+			wantErr: false,
+			check: func(t *testing.T, output string) {
+				if !strings.Contains(output, "function wt") {
+					t.Errorf("output doesn't contain powershell function definition")
+				}
+			},
+		},
+		{
+			name:    "unsupported shell returns error",
+			args:    []string{"tcsh"},
 			wantErr: true,
 			check:   nil,
 		},
@@ -222,6 +364,71 @@ func TestRunHookWithConfig(t *testing.T) {
 	}
 }
 
+func TestGetShellScriptWithFunctionName(t *testing.T) {
+	tests := []struct {
+		shell    string
+		wantDefs []string
+	}{
+		{
+			shell:    "bash",
+			wantDefs: []string{"function gwt() {", "complete -o default -F __start_wt gwt"},
+		},
+		{
+			shell:    "zsh",
+			wantDefs: []string{"function gwt() {", "compdef _wt gwt"},
+		},
+		{
+			shell:    "fish",
+			wantDefs: []string{"function gwt\n", "complete -c gwt --wraps wt"},
+		},
+		{
+			shell:    "powershell",
+			wantDefs: []string{"function gwt {", "Register-ArgumentCompleter -CommandName gwt "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			script, err := getShellScript(tt.shell, "gwt")
+			if err != nil {
+				t.Fatalf("getShellScript() returned error: %v", err)
+			}
+
+			for _, want := range tt.wantDefs {
+				if !strings.Contains(script, want) {
+					t.Errorf("script should contain %q", want)
+				}
+			}
+			if strings.Contains(script, functionNamePlaceholder) {
+				t.Errorf("script still contains placeholder %s", functionNamePlaceholder)
+			}
+
+			// The real binary must still be invoked, not the wrapper
+			if tt.shell == "powershell" {
+				if !strings.Contains(script, "Get-Command -Name wt -CommandType Application") {
+					t.Errorf("script should resolve the wt executable")
+				}
+			} else if !strings.Contains(script, "command wt ") {
+				t.Errorf("script should invoke the wt binary via 'command wt'")
+			}
+			if strings.Contains(script, "command gwt") {
+				t.Errorf("script should not invoke the wrapper name as a command")
+			}
+		})
+	}
+}
+
+func TestGetShellScriptInvalidFunctionName(t *testing.T) {
+	for _, name := range []string{"1wt", "w t", "wt;rm", "$(wt)"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := getShellScript("bash", name)
+			if _, ok := err.(*InvalidFunctionNameError); !ok {
+				t.Errorf("getShellScript(%q) error = %v, want *InvalidFunctionNameError", name, err)
+			}
+		})
+	}
+}
+
 func TestUnsupportedShellError(t *testing.T) {
 	err := &UnsupportedShellError{
 		Shell:           "powershell",
@@ -236,3 +443,85 @@ func TestUnsupportedShellError(t *testing.T) {
 		t.Errorf("error message should contain supported shells, got: %s", errMsg)
 	}
 }
+
+func TestDetectShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		shellEnv string
+		parent   string
+		want     string
+		wantFrom string
+		wantErr  bool
+	}{
+		{
+			name:     "zsh from SHELL",
+			shellEnv: "/usr/bin/zsh",
+			parent:   "bash",
+			want:     "zsh",
+			wantFrom: "$SHELL",
+		},
+		{
+			name:     "SHELL unset falls back to parent process",
+			shellEnv: "",
+			parent:   "-fish",
+			want:     "fish",
+			wantFrom: "parent process",
+		},
+		{
+			name:     "SHELL unset and parent unknown",
+			shellEnv: "",
+			parent:   "",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported shell from SHELL",
+			shellEnv: "/bin/dash",
+			parent:   "bash",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SHELL", tt.shellEnv)
+			origParent := parentProcessName
+			parentProcessName = func() string { return tt.parent }
+			defer func() { parentProcessName = origParent }()
+
+			got, from, err := detectShell()
+			if tt.wantErr {
+				var unsupported *UnsupportedShellError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("detectShell() error = %v, want UnsupportedShellError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectShell() returned error: %v", err)
+			}
+			if got != tt.want || from != tt.wantFrom {
+				t.Errorf("detectShell() = %q, %q, want %q, %q", got, from, tt.want, tt.wantFrom)
+			}
+		})
+	}
+}
+
+func TestRunHookWithConfigDetectsShell(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	var buf bytes.Buffer
+	cmd := newHookCmd()
+	cmd.SetOut(&buf)
+
+	if err := runHookWithConfig(cmd, nil, &hookCmdConfig{}); err != nil {
+		t.Fatalf("runHookWithConfig() returned error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "# wt hook: detected shell zsh (from $SHELL)\n") {
+		t.Errorf("output should start with the detection header, got: %s", output)
+	}
+	if !strings.Contains(output, "compdef") {
+		t.Errorf("output should contain the zsh script, got: %s", output)
+	}
+}
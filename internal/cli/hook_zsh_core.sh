@@ -1,9 +1,18 @@
 # wt - Git worktree helper
 # Shell function: wt go / any command --cd executes actual cd
+# After changing directory, WT_CURRENT_WORKTREE and WT_CURRENT_BRANCH are exported
+# (wt prints "path<TAB>branch" with --porcelain-cd, terminated by NUL with --null;
+# a line without a tab is just the path)
+#
+# Prompt: wt current prints e.g. "myrepo:feature-auth" (prompt.format) and nothing outside a worktree
+#   setopt prompt_subst
+#   RPROMPT='$(command wt current)'
 
-function wt() {
+function __WT_NAME__() {
   # Set environment variable to indicate shell function is active
   export WT_SHELL_FUNCTION=1
+  # Identify this shell, so that wt knows which repository it last worked on here
+  export WT_SESSION_ID=$$
   if [[ "$1" == "go" ]]; then
     shift
     # Fast-path: delegate help/version directly to binary
@@ -17,8 +26,14 @@ function wt() {
     done
 
     local out
-    out="$(command wt go --quiet "$@")"
+    out="$(command wt --null --porcelain-cd go --cd "$@")"
     local code=$?
+    # With --null, wt ends "path<TAB>branch" with a NUL byte (zsh keeps it in the variable);
+    # versions without --null ignore the flag and print a line instead
+    local nul=0
+    if [[ "$out" == *$'\0' ]]; then
+      out="${out%$'\0'}" nul=1
+    fi
 
     # If command failed, print output and return code
     if (( code != 0 )); then
@@ -26,9 +41,12 @@ function wt() {
       return $code
     fi
 
-    # Only cd when output is exactly one line and is a directory
-    if [[ -n "$out" && "$out" != *$'\n'* && -d "$out" ]]; then
-      builtin cd -- "$out" || return 1
+    # Only cd when output is a directory (a single line, unless it is NUL-terminated)
+    local dir="${out%$'\t'*}" branch=""
+    [[ "$out" == *$'\t'* ]] && branch="${out##*$'\t'}"
+    if [[ -n "$dir" && ( $nul == 1 || "$out" != *$'\n'* ) && -d "$dir" ]]; then
+      builtin cd -- "$dir" || return 1
+      export WT_CURRENT_WORKTREE="$dir" WT_CURRENT_BRANCH="$branch"
     else
       # Not a path: show the output (help, usage, etc.)
       printf '%s\n' "$out"
@@ -36,16 +54,25 @@ function wt() {
   elif [[ "$*" == *"--cd"* ]]; then
     # If --cd flag exists, get path and cd
     local out
-    out="$(command wt "$@")"
+    out="$(command wt --null --porcelain-cd "$@")"
     local code=$?
+    # With --null, wt ends "path<TAB>branch" with a NUL byte (zsh keeps it in the variable);
+    # versions without --null ignore the flag and print a line instead
+    local nul=0
+    if [[ "$out" == *$'\0' ]]; then
+      out="${out%$'\0'}" nul=1
+    fi
 
     if (( code != 0 )); then
       printf '%s\n' "$out"
       return $code
     fi
 
-    if [[ -n "$out" && "$out" != *$'\n'* && -d "$out" ]]; then
-      builtin cd -- "$out" || return 1
+    local dir="${out%$'\t'*}" branch=""
+    [[ "$out" == *$'\t'* ]] && branch="${out##*$'\t'}"
+    if [[ -n "$dir" && ( $nul == 1 || "$out" != *$'\n'* ) && -d "$dir" ]]; then
+      builtin cd -- "$dir" || return 1
+      export WT_CURRENT_WORKTREE="$dir" WT_CURRENT_BRANCH="$branch"
     else
       printf '%s\n' "$out"
     fi
@@ -55,26 +82,6 @@ function wt() {
   fi
 }
 
-# Zsh completion
-_wt() {
-  local -a subcmds
-  subcmds=(
-    'new:Create new worktree'
-    'go:Navigate between worktrees'
-    'clean:Remove worktrees'
-    'open:Open worktree in editor'
-    'pr:Create worktree for PR review'
-    'hook:Output shell hook scripts'
-    'help:Show help'
-  )
-
-  if (( CURRENT == 2 )); then
-    _describe 'wt commands' subcmds
-  elif (( CURRENT == 3 )) && [[ "${words[2]}" == "go" ]]; then
-    local -a branches
-    branches=(${(f)"$(git worktree list --porcelain 2>/dev/null | grep '^branch' | awk '{print $2}' | sed 's|refs/heads/||')"})
-    _describe 'branches' branches
-  fi
-}
-
-compdef _wt wt
+# Zsh completion: the dynamic completion of "wt completion zsh", also for this function's name
+eval "$(command wt completion zsh)"
+compdef _wt __WT_NAME__
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+type infoCmdConfig struct {
+	match matchOptions
+}
+
+// worktreeInfo is the output of wt info
+type worktreeInfo struct {
+	worktreeJSON
+	Main       bool       `json:"main"`
+	Current    bool       `json:"current"`
+	Upstream   string     `json:"upstream,omitempty"` // e.g. "origin/main"
+	Ahead      int        `json:"ahead"`
+	Behind     int        `json:"behind"`
+	Dirty      bool       `json:"dirty"`
+	Modified   int        `json:"modified"`
+	Untracked  int        `json:"untracked"`
+	PR         int        `json:"pr,omitempty"`          // The PR the worktree was created for by wt pr
+	LastCommit *time.Time `json:"last_commit,omitempty"` // Committer date of HEAD
+}
+
+func newInfoCmd() *cobra.Command {
+	cfg := &infoCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "info [query]",
+		Short: "Show details about the current worktree",
+		Long: `Show details about the current worktree, or the worktree matching query.
+
+Prints the branch and its upstream with commits ahead/behind, uncommitted changes,
+the worktree path, whether it is the main worktree, the lock state, the PR it was
+created for by wt pr, and the age of the last commit. Works from any subdirectory of
+a worktree; nothing is changed.
+
+Examples:
+  wt info
+  wt info feature
+  wt info --json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runInfoWithConfig(c, args, cfg)
+		},
+	}
+
+	addSelectionFlags(cmd, &cfg.match)
+
+	return withJSON(cmd)
+}
+
+var infoCmd = newInfoCmd()
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
+
+func runInfoWithConfig(cmd *cobra.Command, args []string, cfg *infoCmdConfig) error {
+	ctx := cmd.Context()
+
+	all, err := gitx.List(ctx)
+	if err != nil {
+		if gitx.IsNotRepository(err) {
+			return &NotRepositoryError{}
+		}
+		return fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	var currentPath string
+	if current, err := gitx.GetCurrentWorktree(ctx); err == nil {
+		currentPath = current.Path
+	}
+
+	var selected gitx.Worktree
+	if len(args) > 0 {
+		worktrees := selectableWorktrees(all)
+		index, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), createDisplayItems(worktrees), args[0], selectOptions{prompt: "Select worktree", match: cfg.match})
+		if err != nil {
+			return err
+		}
+		selected = worktrees[index]
+	} else {
+		if currentPath == "" {
+			return fmt.Errorf("current directory is not in any worktree (give a query to select one)")
+		}
+		for _, wt := range all {
+			if wt.Path == currentPath {
+				selected = wt
+			}
+		}
+	}
+
+	info := collectWorktreeInfo(ctx, selected)
+	info.Main = len(all) > 0 && all[0].Path == selected.Path // The main worktree is always listed first
+	info.Current = selected.Path == currentPath
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), info)
+	}
+	printWorktreeInfo(cmd.OutOrStdout(), info, time.Now())
+	return nil
+}
+
+// collectWorktreeInfo gathers the details of wt; details that can't be determined are left empty
+func collectWorktreeInfo(ctx context.Context, wt gitx.Worktree) *worktreeInfo {
+	info := &worktreeInfo{worktreeJSON: newWorktreeJSON(wt)}
+	if wt.IsBare || !pathExists(wt.Path) {
+		return info
+	}
+
+	if wt.Branch != "" {
+		if upstream, err := gitx.GetUpstream(ctx, wt.Branch); err == nil && upstream != nil {
+			info.Upstream = upstream.Remote + "/" + upstream.Branch
+		}
+		if pr, err := gitx.GetBranchPR(ctx, wt.Branch); err == nil {
+			info.PR = pr
+		}
+	}
+	if ahead, behind, hasUpstream, err := gitx.AheadBehind(ctx, wt.Path); err == nil && hasUpstream {
+		info.Ahead, info.Behind = ahead, behind
+	}
+	if changes, err := gitx.GetStatus(ctx, wt.Path); err == nil {
+		info.Dirty = changes.IsDirty()
+		info.Modified = changes.Modified
+		info.Untracked = changes.Untracked
+	}
+	if t, err := gitx.LastCommitTime(ctx, wt.Path); err == nil {
+		info.LastCommit = &t
+	}
+	return info
+}
+
+// Output functions
+
+func printWorktreeInfo(w io.Writer, info *worktreeInfo, now time.Time) {
+	branch := info.Branch
+	if info.Detached {
+		branch = formatBranch(gitx.Worktree{IsDetached: true, HEAD: info.HEAD})
+	}
+	fmt.Fprintf(w, "Branch:      %s\n", branch)
+
+	upstream := "none"
+	if info.Upstream != "" {
+		upstream = fmt.Sprintf("%s %s", info.Upstream, formatAheadBehind(info.Ahead, info.Behind))
+	}
+	fmt.Fprintf(w, "Upstream:    %s\n", upstream)
+
+	changes := "clean"
+	if info.Dirty {
+		changes = fmt.Sprintf("%d modified, %d untracked", info.Modified, info.Untracked)
+	}
+	fmt.Fprintf(w, "Changes:     %s\n", changes)
+	fmt.Fprintf(w, "Path:        %s\n", info.Path)
+	fmt.Fprintf(w, "Main:        %s\n", formatYesNo(info.Main))
+
+	locked := formatYesNo(info.Locked)
+	if info.LockReason != "" {
+		locked += " (" + info.LockReason + ")"
+	}
+	fmt.Fprintf(w, "Locked:      %s\n", locked)
+
+	if info.PR > 0 {
+		fmt.Fprintf(w, "PR:          #%d\n", info.PR)
+	}
+	if info.LastCommit != nil {
+		fmt.Fprintf(w, "Last commit: %s\n", formatAge(now.Sub(*info.LastCommit)))
+	}
+}
+
+func formatYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// formatAge formats how long ago something happened, e.g. "3 days ago"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralize(int(d/time.Minute), "minute") + " ago"
+	case d < 24*time.Hour:
+		return pluralize(int(d/time.Hour), "hour") + " ago"
+	case d < 30*24*time.Hour:
+		return pluralize(int(d/(24*time.Hour)), "day") + " ago"
+	case d < 365*24*time.Hour:
+		return pluralize(int(d/(30*24*time.Hour)), "month") + " ago"
+	default:
+		return pluralize(int(d/(365*24*time.Hour)), "year") + " ago"
+	}
+}
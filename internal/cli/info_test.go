@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestCollectWorktreeInfo(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	featurePath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", featurePath)
+	runGitForTest(t, repoPath, "worktree", "lock", "--reason", "on USB", featurePath)
+	if err := os.WriteFile(filepath.Join(featurePath, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := gitx.SetBranchPR(ctx, "feature", 42); err != nil {
+		t.Fatalf("SetBranchPR() returned error: %v", err)
+	}
+
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := collectWorktreeInfo(ctx, worktrees[1])
+	if info.Branch != "feature" || info.PR != 42 || !info.Dirty || info.Untracked != 1 || !info.Locked || info.LastCommit == nil {
+		t.Errorf("collectWorktreeInfo() = %+v", info)
+	}
+
+	var buf bytes.Buffer
+	printWorktreeInfo(&buf, info, info.LastCommit.Add(3*time.Hour))
+	for _, want := range []string{"Branch:      feature", "Upstream:    none", "Changes:     0 modified, 1 untracked", "Locked:      yes (on USB)", "PR:          #42", "Last commit: 3 hours ago"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 10 * time.Second, want: "just now"},
+		{d: time.Minute, want: "1 minute ago"},
+		{d: 5 * time.Hour, want: "5 hours ago"},
+		{d: 3 * 24 * time.Hour, want: "3 days ago"},
+		{d: 65 * 24 * time.Hour, want: "2 months ago"},
+		{d: 800 * 24 * time.Hour, want: "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
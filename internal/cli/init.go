@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// initExampleBranch is the branch the example worktree path of wt init is shown for
+const initExampleBranch = "feature/login"
+
+type initCmdConfig struct {
+	defaults bool // Accept the current value of every question without asking
+	noConfig bool
+	noHook   bool
+}
+
+func newInitCmd() *cobra.Command {
+	cfg := &initCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up wt: directory layout, shell hook and optional tools",
+		Long: `Set up wt step by step:
+
+  1. Choose where worktrees go (worktree.directory_format and subdirectory_suffix),
+     with an example path for the current repository, and write the configuration file
+  2. Add the shell hook to your shell configuration file (like wt hook install)
+  3. Check for fzf, tmux and gh, with install hints
+
+Every question shows the current value, which Enter keeps, so running wt init again
+changes nothing unless you answer differently. Skip steps with --no-config and --no-hook.
+
+Options:
+  --defaults   Don't ask: keep the current values and install the hook (for scripts)
+  --no-config  Skip the directory layout and the configuration file
+  --no-hook    Skip the shell hook
+
+Examples:
+  wt init
+  wt init --defaults --no-hook`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runInitWithConfig(c, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.defaults, "defaults", false, "Keep the current values without asking and install the hook")
+	cmd.Flags().BoolVar(&cfg.noConfig, "no-config", false, "Skip the directory layout and the configuration file")
+	cmd.Flags().BoolVar(&cfg.noHook, "no-hook", false, "Skip the shell hook")
+
+	return cmd
+}
+
+var initCmd = newInitCmd()
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInitWithConfig(cmd *cobra.Command, cfg *initCmdConfig) error {
+	ctx := cmd.Context()
+	r, w := cmd.InOrStdin(), cmd.OutOrStdout()
+
+	if !cfg.defaults && !isTerminal(r) {
+		return &NonInteractiveError{Prompt: "wt init", Bypass: "--defaults"}
+	}
+	ask := func(question, current string) string {
+		if cfg.defaults {
+			return current
+		}
+		return askWithDefault(r, w, question, current)
+	}
+
+	if !cfg.noConfig {
+		fmt.Fprintln(w, "Worktree directory layout")
+		if err := initConfig(ctx, w, ask); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+
+	if !cfg.noHook {
+		fmt.Fprintln(w, "Shell hook (lets wt go and wt new change the directory)")
+		if err := initHook(ctx, r, w, cfg.defaults); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Optional tools")
+	printDoctorChecks(w, []doctorCheck{checkSelector(ctx), checkTmux(), checkGh()})
+
+	fmt.Fprintln(w, "\nDone. Create your first worktree with: wt new <branch>")
+	return nil
+}
+
+// initConfig asks for the directory layout and writes the configuration file
+// The values are set with the setters of wt config set, which validate them; an invalid answer is asked again.
+func initConfig(ctx context.Context, w io.Writer, ask func(question, current string) string) error {
+	configPath, err := config.GetDefaultConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	before := [2]string{cfg.GetDirectoryFormat(), cfg.GetSubdirectorySuffix()}
+
+	repoName, baseDir := initExampleRepo(ctx, cfg)
+	for _, format := range []string{config.DirectoryFormatSubdirectory, config.DirectoryFormatSibling} {
+		example := *cfg
+		_ = example.SetDirectoryFormat(format)
+		fmt.Fprintf(w, "  %-12s %s\n", format, initExamplePath(&example, repoName, baseDir))
+	}
+	askSetting(w, "Directory format (subdirectory or sibling)", cfg.GetDirectoryFormat, cfg.SetDirectoryFormat, ask)
+	if cfg.GetDirectoryFormat() == config.DirectoryFormatSubdirectory {
+		askSetting(w, "Suffix of the worktrees directory", cfg.GetSubdirectorySuffix, cfg.SetSubdirectorySuffix, ask)
+	}
+	fmt.Fprintf(w, "  Worktrees will be created like: %s\n", initExamplePath(cfg, repoName, baseDir))
+
+	_, statErr := os.Stat(configPath)
+	switch {
+	case os.IsNotExist(statErr):
+		// A new file gets every setting with comments, to be adjusted later with wt config edit
+		if err := cfg.WriteTemplate(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "✓ Wrote %s\n", configPath)
+	case before != [2]string{cfg.GetDirectoryFormat(), cfg.GetSubdirectorySuffix()}:
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Fprintf(w, "✓ Updated %s\n", configPath)
+	default:
+		fmt.Fprintf(w, "✓ %s is up to date\n", configPath)
+	}
+	return nil
+}
+
+// askSetting asks for a setting until set accepts the answer (Enter keeps the current value)
+func askSetting(w io.Writer, question string, get func() string, set func(string) error, ask func(question, current string) string) {
+	for {
+		err := set(ask(question, get()))
+		if err == nil {
+			return
+		}
+		fmt.Fprintf(w, "  %v\n", err)
+	}
+}
+
+// initExampleRepo returns the repository name and base directory to show example paths for:
+// the current repository, or a made-up one outside of repositories
+func initExampleRepo(ctx context.Context, cfg *config.Config) (name, baseDir string) {
+	if repo, err := gitx.GetRepo(ctx, flagRepo); err == nil {
+		return repo.Name, configuredBaseDir(repo, cfg)
+	}
+	home, _ := os.UserHomeDir()
+	return "myproject", filepath.Join(home, "src")
+}
+
+// initExamplePath returns the path a worktree of initExampleBranch gets with cfg
+func initExamplePath(cfg *config.Config, repoName, baseDir string) string {
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, repoName, initExampleBranch, nil, cfg)
+	if err != nil {
+		return "(" + err.Error() + ")"
+	}
+	return path
+}
+
+// initHook offers to add the shell hook with the logic of wt hook install
+func initHook(ctx context.Context, r io.Reader, w io.Writer, defaults bool) error {
+	shell, _, err := detectShell()
+	if err != nil {
+		fmt.Fprintf(w, "! %v\n  Add it yourself, see: wt hook --help\n", err)
+		return nil
+	}
+	if hookInstalled(shell, defaultFunctionName) {
+		path, _ := hookRCFile(shell, defaultFunctionName)
+		fmt.Fprintf(w, "✓ Already installed in %s\n", path)
+		return nil
+	}
+
+	if !defaults {
+		path, err := hookRCFile(shell, defaultFunctionName)
+		if err != nil {
+			return err
+		}
+		ok, err := confirmWith(ctx, r, w, fmt.Sprintf("Add the %s hook to %s?", shell, path), "--defaults or --no-hook")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(w, "  Skipped (run wt hook install later)")
+			return nil
+		}
+	}
+	return installHook(w, shell, &hookInstallCmdConfig{name: defaultFunctionName})
+}
+
+// askWithDefault asks question on w and returns the trimmed answer read from r, or current for an
+// empty answer (or when r has no more input)
+func askWithDefault(r io.Reader, w io.Writer, question, current string) string {
+	fmt.Fprintf(w, "%s [%s]: ", question, current)
+	input, err := selectx.ReadLine(r)
+	if err != nil {
+		fmt.Fprintln(w)
+		return current
+	}
+	if answer := strings.TrimSpace(input); answer != "" {
+		return answer
+	}
+	return current
+}
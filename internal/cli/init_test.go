@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/config"
+)
+
+// setupInitEnv points the config file and the shell configuration file of wt init to a temporary home
+func setupInitEnv(t *testing.T) (configPath, rcFile string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("SHELL", "/bin/bash")
+	return filepath.Join(home, ".config", "wt", "config.yaml"), filepath.Join(home, ".bashrc")
+}
+
+func runInitForTest(t *testing.T, input string, cfg *initCmdConfig) string {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := newInitCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetOut(&buf)
+	if err := runInitWithConfig(cmd, cfg); err != nil {
+		t.Fatalf("runInitWithConfig() returned error: %v\noutput: %s", err, buf.String())
+	}
+	return buf.String()
+}
+
+func TestRunInitDefaults(t *testing.T) {
+	setupTestRepo(t)
+	configPath, rcFile := setupInitEnv(t)
+
+	out := runInitForTest(t, "", &initCmdConfig{defaults: true})
+	if !strings.Contains(out, "Wrote "+configPath) {
+		t.Errorf("first run should write the config file, output: %s", out)
+	}
+	if !strings.Contains(out, filepath.Join(".test-repo-wt", "feature-login")) {
+		t.Errorf("output should show an example path for the current repository, got: %s", out)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load written config: %v", err)
+	}
+	if got := cfg.GetDirectoryFormat(); got != config.DirectoryFormatSubdirectory {
+		t.Errorf("directory_format = %q, want the default", got)
+	}
+
+	// Running again changes nothing
+	out = runInitForTest(t, "", &initCmdConfig{defaults: true})
+	if !strings.Contains(out, "up to date") || !strings.Contains(out, "Already installed") {
+		t.Errorf("second run should report everything as done, output: %s", out)
+	}
+	data, err := os.ReadFile(rcFile)
+	if err != nil {
+		t.Fatalf("Failed to read rc file: %v", err)
+	}
+	if n := strings.Count(string(data), hookBlockStart); n != 1 {
+		t.Errorf("rc file contains %d hook blocks, want 1", n)
+	}
+}
+
+func TestRunInitInteractive(t *testing.T) {
+	setupTestRepo(t)
+	configPath, rcFile := setupInitEnv(t)
+	orig := isTerminal
+	isTerminal = func(io.Reader) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+
+	// An invalid format is asked again; the hook is declined
+	out := runInitForTest(t, "nested\nsibling\nn\n", &initCmdConfig{})
+	if !strings.Contains(out, "invalid") {
+		t.Errorf("invalid answer should be reported, output: %s", out)
+	}
+	if !strings.Contains(out, "test-repo-feature-login") {
+		t.Errorf("output should show the chosen layout, got: %s", out)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load written config: %v", err)
+	}
+	if got := cfg.GetDirectoryFormat(); got != config.DirectoryFormatSibling {
+		t.Errorf("directory_format = %q, want sibling", got)
+	}
+	if _, err := os.Stat(rcFile); !os.IsNotExist(err) {
+		t.Errorf("declined hook should not create %s", rcFile)
+	}
+
+	// Enter keeps the current value
+	out = runInitForTest(t, "\n", &initCmdConfig{noHook: true})
+	if !strings.Contains(out, "[sibling]") || !strings.Contains(out, "up to date") {
+		t.Errorf("re-run should offer the current value and keep it, output: %s", out)
+	}
+}
+
+func TestRunInitNonInteractive(t *testing.T) {
+	setNonInteractive(t)
+	setupInitEnv(t)
+
+	cmd := newInitCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(io.Discard)
+	err := runInitWithConfig(cmd, &initCmdConfig{})
+	var nonInteractive *NonInteractiveError
+	if !errors.As(err, &nonInteractive) {
+		t.Fatalf("runInitWithConfig() error = %v, want NonInteractiveError", err)
+	}
+}
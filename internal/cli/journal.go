@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/journal"
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// NothingToUndoError represents an error when the journal has no deleted branch to recreate
+type NothingToUndoError struct{}
+
+func (e *NothingToUndoError) Error() string {
+	return "nothing to undo: no deleted branch recorded for this repository (see wt log)"
+}
+
+// logTimeFormat is the time of an entry in the wt log output
+const logTimeFormat = "2006-01-02 15:04"
+
+type logCmdConfig struct {
+	limit int
+}
+
+func newLogCmd() *cobra.Command {
+	cfg := &logCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the worktrees and branches wt removed",
+		Long: `Show the journal of worktrees removed and branches deleted in this repository,
+most recent first, with the commit each one pointed at.
+
+wt clean, wt prune-branches and wt workspace clean record what they remove. The journal
+is kept in the state directory ($XDG_STATE_HOME/wt/journal, default ~/.local/state)
+and only holds the most recent entries.
+
+Options:
+  -n, --limit <n>  Show at most n entries (0 for all; default 20)
+
+Examples:
+  wt log
+  wt log -n 5`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runLogWithConfig(c, cfg)
+		},
+	}
+
+	cmd.Flags().IntVarP(&cfg.limit, "limit", "n", 20, "Show at most this many entries (0 for all)")
+
+	return withJSON(cmd)
+}
+
+func newUndoCmd() *cobra.Command {
+	return withJSON(&cobra.Command{
+		Use:   "undo",
+		Short: "Recreate the branch wt deleted last",
+		Long: `Recreate the branch wt deleted last in this repository, at the commit it pointed at.
+
+Only the branch comes back, not the files of its worktree: create a worktree for it
+with wt new <branch>, or use wt clean --trash to keep removed worktrees restorable.
+Running wt undo again recreates the branch deleted before that one.
+
+The commit has to still exist: git removes unreachable commits eventually (gc).`,
+		Args: cobra.NoArgs,
+		RunE: runUndo,
+	})
+}
+
+var (
+	logCmd  = newLogCmd()
+	undoCmd = newUndoCmd()
+)
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runLogWithConfig(cmd *cobra.Command, cfg *logCmdConfig) error {
+	if cfg.limit < 0 {
+		return &UsageError{Err: fmt.Errorf("invalid --limit: %d (must not be negative)", cfg.limit)}
+	}
+
+	entries, err := readJournal(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// Most recent first
+	recent := make([]journal.Entry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		if cfg.limit > 0 && len(recent) == cfg.limit {
+			break
+		}
+		recent = append(recent, entries[i])
+	}
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), recent)
+	}
+	printJournal(cmd.OutOrStdout(), recent)
+	return nil
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	entries, err := readJournal(ctx)
+	if err != nil {
+		return err
+	}
+	last := journal.LastDeletedBranch(entries)
+	if last == nil {
+		return &NothingToUndoError{}
+	}
+
+	exists, err := gitx.BranchExists(ctx, last.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to check branch existence: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("branch '%s' exists again; recreate it under another name with: git branch <name> %s", last.Branch, last.Commit)
+	}
+	if !gitx.CommitExists(ctx, last.Commit) {
+		return fmt.Errorf("commit %s of branch '%s' no longer exists (removed by git gc)", last.Commit, last.Branch)
+	}
+	if err := gitx.CreateBranch(ctx, last.Branch, last.Commit); err != nil {
+		return fmt.Errorf("failed to recreate branch: %w", err)
+	}
+	recordJournal(ctx, journal.Entry{Action: journal.BranchRestored, Branch: last.Branch, Commit: last.Commit})
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), last)
+	}
+	printBranchRestored(cmd.OutOrStdout(), last, flagQuiet)
+	return nil
+}
+
+// journalPath returns the journal file of the current repository
+func journalPath(ctx context.Context) (string, error) {
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		if gitx.IsNotRepository(err) {
+			return "", &NotRepositoryError{}
+		}
+		return "", fmt.Errorf("failed to get repository information: %w", err)
+	}
+	return journal.Path(repo.Root)
+}
+
+// readJournal returns the journal entries of the current repository, oldest first
+func readJournal(ctx context.Context) ([]journal.Entry, error) {
+	path, err := journalPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := journal.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the journal: %w", err)
+	}
+	return entries, nil
+}
+
+// recordJournal appends e to the journal of the current repository
+// Failures are ignored: the journal must never stop the removal it records.
+func recordJournal(ctx context.Context, e journal.Entry) {
+	path, err := journalPath(ctx)
+	if err != nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	_ = journal.Append(path, e)
+}
+
+// deleteBranchRecorded deletes branch like gitx.DeleteBranch and records the commit it pointed at in
+// the journal. It returns that commit ("" if unknown), for the recovery hint.
+func deleteBranchRecorded(ctx context.Context, branch string, force bool) (string, error) {
+	commit, _ := gitx.ResolveCommit(ctx, "refs/heads/"+branch)
+	if err := gitx.DeleteBranch(ctx, branch, force); err != nil {
+		return "", err
+	}
+	if commit != "" {
+		recordJournal(ctx, journal.Entry{Action: journal.BranchDeleted, Branch: branch, Commit: commit})
+	}
+	return commit, nil
+}
+
+// Output functions
+
+func printJournal(w io.Writer, entries []journal.Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "Nothing recorded yet")
+		return
+	}
+	table := newTable(w, "")
+	table.SetMaxWidth(2, maxBranchWidth)
+	for _, e := range entries {
+		table.AddCells(
+			tablex.Cell{Text: e.Time.Local().Format(logTimeFormat), Color: tablex.Dim},
+			tablex.Cell{Text: strings.ReplaceAll(e.Action, "_", " ")},
+			tablex.Cell{Text: e.Branch},
+			tablex.Cell{Text: shortCommit(e.Commit), Color: tablex.Yellow},
+			tablex.Cell{Text: e.Path},
+		)
+	}
+	_ = table.Render(w)
+}
+
+func printBranchRestored(w io.Writer, e *journal.Entry, quiet bool) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(w, "✓ Branch recreated: %s at %s (deleted %s)\n", e.Branch, shortCommit(e.Commit), e.Time.Local().Format(logTimeFormat))
+	fmt.Fprintf(w, "  Create a worktree for it with: wt new %s\n", e.Branch)
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
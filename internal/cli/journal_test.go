@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanJournalAndUndo(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+	runGitForTest(t, wtPath, "commit", "--allow-empty", "-m", "Unmerged work")
+	commit := strings.TrimSpace(runGitForTest(t, wtPath, "rev-parse", "HEAD"))
+
+	var stdout bytes.Buffer
+	cmd := newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{yes: true}); err != nil {
+		t.Fatalf("runCleanWithConfig() returned error: %v", err)
+	}
+	if want := "Recover with: git branch feature " + commit; !strings.Contains(stdout.String(), want) {
+		t.Errorf("clean output = %q, want %q", stdout.String(), want)
+	}
+
+	// Most recent first: the branch deletion, then the worktree removal
+	stdout.Reset()
+	cmd = newLogCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&stdout)
+	if err := runLogWithConfig(cmd, &logCmdConfig{}); err != nil {
+		t.Fatalf("runLogWithConfig() returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "branch deleted") || !strings.Contains(lines[1], "worktree removed") || !strings.Contains(lines[1], wtPath) {
+		t.Errorf("log output = %q, want the branch deletion and then the worktree removal", stdout.String())
+	}
+
+	stdout.Reset()
+	cmd = newUndoCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&stdout)
+	if err := runUndo(cmd, nil); err != nil {
+		t.Fatalf("runUndo() returned error: %v", err)
+	}
+	if got := strings.TrimSpace(runGitForTest(t, repoPath, "rev-parse", "feature")); got != commit {
+		t.Errorf("recreated branch points at %s, want %s", got, commit)
+	}
+
+	// The only deletion is undone now
+	var nothing *NothingToUndoError
+	if err := runUndo(cmd, nil); !errors.As(err, &nothing) {
+		t.Errorf("runUndo() a second time error = %v, want NothingToUndoError", err)
+	}
+}
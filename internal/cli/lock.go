@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// NoLockCandidatesError represents an error when there are no worktrees to lock or unlock
+type NoLockCandidatesError struct {
+	Locked bool // Whether locked worktrees were being searched for
+}
+
+func (e *NoLockCandidatesError) Error() string {
+	if e.Locked {
+		return "no locked worktrees found"
+	}
+	return "no unlocked worktrees found (main worktree cannot be locked)"
+}
+
+// MainWorktreeLockError represents an error when trying to lock or unlock the main worktree
+type MainWorktreeLockError struct{}
+
+func (e *MainWorktreeLockError) Error() string {
+	return "the main worktree (repository root) cannot be locked or unlocked"
+}
+
+type lockCmdConfig struct {
+	reason string
+	match  matchOptions
+}
+
+type unlockCmdConfig struct {
+	match matchOptions
+}
+
+func newLockCmd() *cobra.Command {
+	cfg := &lockCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "lock [query]",
+		Short: "Lock a worktree",
+		Long: `Lock a worktree to prevent it from being pruned, moved or removed.
+
+If query is not specified, select interactively from unlocked worktrees.
+
+Warning: Main worktree (repository root) cannot be locked.
+
+Examples:
+  wt lock                              # Select interactively
+  wt lock feature --reason "on USB"    # Lock worktree containing "feature"`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runLockWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.reason, "reason", "", "Reason for locking")
+	addSelectionFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+func newUnlockCmd() *cobra.Command {
+	cfg := &unlockCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "unlock [query]",
+		Short: "Unlock a worktree",
+		Long: `Unlock a locked worktree.
+
+If query is not specified, select interactively from locked worktrees.
+
+Examples:
+  wt unlock            # Select interactively
+  wt unlock feature    # Unlock worktree containing "feature"`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runUnlockWithConfig(c, args, cfg)
+		},
+	}
+
+	addSelectionFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+var (
+	lockCmd   = newLockCmd()
+	unlockCmd = newUnlockCmd()
+)
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+}
+
+func runLockWithConfig(cmd *cobra.Command, args []string, cfg *lockCmdConfig) error {
+	ctx := cmd.Context()
+
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeLock); err != nil {
+		return err
+	}
+
+	selected, err := selectLockCandidate(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), args, false, "Select worktree to lock", cfg.match)
+	if err != nil {
+		return err
+	}
+
+	if err := gitx.Lock(ctx, selected.Path, cfg.reason); err != nil {
+		return fmt.Errorf("failed to lock worktree: %w", err)
+	}
+
+	printLockSuccess(cmd.OutOrStdout(), selected.Path, cfg.reason, flagQuiet)
+	return nil
+}
+
+func runUnlockWithConfig(cmd *cobra.Command, args []string, cfg *unlockCmdConfig) error {
+	ctx := cmd.Context()
+
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeLock); err != nil {
+		return err
+	}
+
+	selected, err := selectLockCandidate(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), args, true, "Select worktree to unlock", cfg.match)
+	if err != nil {
+		return err
+	}
+
+	if err := gitx.Unlock(ctx, selected.Path); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %w", err)
+	}
+
+	printUnlockSuccess(cmd.OutOrStdout(), selected.Path, flagQuiet)
+	return nil
+}
+
+// selectLockCandidate selects a non-main worktree that is locked (or unlocked if locked is false)
+func selectLockCandidate(ctx context.Context, r io.Reader, w io.Writer, args []string, locked bool, prompt string, match matchOptions) (gitx.Worktree, error) {
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return gitx.Worktree{}, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return gitx.Worktree{}, fmt.Errorf("failed to get repository information: %w", err)
+	}
+
+	candidates, items, mainItem := filterLockCandidates(worktrees, repo.Root, locked)
+
+	// Refuse explicitly when the query only matches the main worktree
+	if query != "" && mainItem != "" {
+		if _, err := selectx.FilterIndexedByQuery(items, query, match.useFuzzy()); err != nil {
+			if _, mainErr := selectx.FilterIndexedByQuery([]string{mainItem}, query, match.useFuzzy()); mainErr == nil {
+				return gitx.Worktree{}, &MainWorktreeLockError{}
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return gitx.Worktree{}, &NoLockCandidatesError{Locked: locked}
+	}
+
+	selectedIndex, err := selectItem(ctx, r, w, items, query, selectOptions{prompt: prompt, match: match})
+	if err != nil {
+		return gitx.Worktree{}, err
+	}
+
+	return candidates[selectedIndex], nil
+}
+
+// filterLockCandidates returns the non-main worktrees with the given lock state and their display items
+// The main worktree's display item is returned separately.
+func filterLockCandidates(worktrees []gitx.Worktree, mainRoot string, locked bool) ([]gitx.Worktree, []string, string) {
+	var candidates []gitx.Worktree
+	var items []string
+	mainItem := ""
+
+	for _, wt := range worktrees {
+		item := formatDisplayItem(wt)
+		if wt.Path == mainRoot {
+			mainItem = item
+			continue
+		}
+		if wt.IsLocked != locked {
+			continue
+		}
+		if wt.LockReason != "" {
+			item += "\t" + formatLockReason(wt.LockReason)
+		}
+		candidates = append(candidates, wt)
+		items = append(items, item)
+	}
+
+	return candidates, items, mainItem
+}
+
+// formatLockReason formats a lock reason for display
+func formatLockReason(reason string) string {
+	return fmt.Sprintf("[locked: %s]", reason)
+}
+
+// Output functions
+
+func printLockSuccess(w io.Writer, path, reason string, quiet bool) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(w, "✓ Worktree locked: %s\n", path)
+	if reason != "" {
+		fmt.Fprintf(w, "  Reason: %s\n", reason)
+	}
+}
+
+func printUnlockSuccess(w io.Writer, path string, quiet bool) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(w, "✓ Worktree unlocked: %s\n", path)
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestNoLockCandidatesError(t *testing.T) {
+	if got := (&NoLockCandidatesError{Locked: true}).Error(); got != "no locked worktrees found" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := (&NoLockCandidatesError{}).Error(); !strings.Contains(got, "main worktree cannot be locked") {
+		t.Errorf("Error() = %q, want it to mention the main worktree", got)
+	}
+}
+
+func TestFilterLockCandidates(t *testing.T) {
+	worktrees := []gitx.Worktree{
+		{Path: "/work/repo", Branch: "main"},
+		{Path: "/work/.repo-wt/a", Branch: "a", Index: 1},
+		{Path: "/work/.repo-wt/b", Branch: "b", IsLocked: true, LockReason: "on USB", Index: 2},
+		{Path: "/work/.repo-wt/c", Branch: "c", IsLocked: true, Index: 3},
+	}
+
+	tests := []struct {
+		name      string
+		locked    bool
+		wantItems []string
+	}{
+		{
+			name:      "unlocked",
+			locked:    false,
+			wantItems: []string{"[1]\ta\t/work/.repo-wt/a"},
+		},
+		{
+			name:      "locked",
+			locked:    true,
+			wantItems: []string{"[2]\tb\t/work/.repo-wt/b\t[locked: on USB]", "[3]\tc\t/work/.repo-wt/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, items, mainItem := filterLockCandidates(worktrees, "/work/repo", tt.locked)
+			if !reflect.DeepEqual(items, tt.wantItems) {
+				t.Errorf("items = %q, want %q", items, tt.wantItems)
+			}
+			if len(candidates) != len(items) {
+				t.Errorf("got %d candidates for %d items", len(candidates), len(items))
+			}
+			if mainItem != "[0]\tmain\t/work/repo" {
+				t.Errorf("mainItem = %q, want %q", mainItem, "[0]\tmain\t/work/repo")
+			}
+		})
+	}
+}
+
+func TestRunLockAndUnlock(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+
+	ctx := context.Background()
+
+	// Lock with a reason
+	var buf bytes.Buffer
+	cmd := newLockCmd()
+	cmd.SetOut(&buf)
+	cmd.SetContext(ctx)
+	if err := runLockWithConfig(cmd, []string{"feature"}, &lockCmdConfig{reason: "on USB"}); err != nil {
+		t.Fatalf("runLockWithConfig() returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Reason: on USB") {
+		t.Errorf("output should contain the reason, got: %s", buf.String())
+	}
+
+	wt, err := gitx.FindWorktreeByBranch(ctx, "feature")
+	if err != nil || wt == nil {
+		t.Fatalf("FindWorktreeByBranch() = %v, %v", wt, err)
+	}
+	if !wt.IsLocked || wt.LockReason != "on USB" {
+		t.Errorf("worktree lock state = %v, %q, want locked with reason", wt.IsLocked, wt.LockReason)
+	}
+
+	// Nothing left to lock
+	cmd = newLockCmd()
+	cmd.SetContext(ctx)
+	if err := runLockWithConfig(cmd, nil, &lockCmdConfig{}); !errors.As(err, new(*NoLockCandidatesError)) {
+		t.Errorf("runLockWithConfig() error = %v, want NoLockCandidatesError", err)
+	}
+
+	// The main worktree is refused
+	cmd = newLockCmd()
+	cmd.SetContext(ctx)
+	if err := runLockWithConfig(cmd, []string{"main"}, &lockCmdConfig{}); !errors.As(err, new(*MainWorktreeLockError)) {
+		t.Errorf("runLockWithConfig() error = %v, want MainWorktreeLockError", err)
+	}
+
+	// Unlock
+	buf.Reset()
+	cmd = newUnlockCmd()
+	cmd.SetOut(&buf)
+	cmd.SetContext(ctx)
+	if err := runUnlockWithConfig(cmd, []string{"feature"}, &unlockCmdConfig{}); err != nil {
+		t.Fatalf("runUnlockWithConfig() returned error: %v", err)
+	}
+	wt, _ = gitx.FindWorktreeByBranch(ctx, "feature")
+	if wt == nil || wt.IsLocked {
+		t.Errorf("worktree should be unlocked")
+	}
+}
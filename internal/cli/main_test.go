@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain keeps the state wt records (journal, sessions) out of the home directory
+func TestMain(m *testing.M) {
+	stateHome, err := os.MkdirTemp("", "wt-state-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", stateHome)
+	code := m.Run()
+	os.RemoveAll(stateHome)
+	os.Exit(code)
+}
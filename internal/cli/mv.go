@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+// WorktreeLockedError represents an error when the worktree to move or remove is locked
+type WorktreeLockedError struct {
+	Path   string
+	Reason string // Empty if no reason was given when locking
+}
+
+func (e *WorktreeLockedError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("worktree is locked: %s (%s)\nUnlock it first: wt unlock %s", e.Path, e.Reason, e.Path)
+	}
+	return fmt.Sprintf("worktree is locked: %s\nUnlock it first: wt unlock %s", e.Path, e.Path)
+}
+
+// DestinationExistsError represents an error when the move destination already exists
+type DestinationExistsError struct {
+	Path string
+}
+
+func (e *DestinationExistsError) Error() string {
+	return fmt.Sprintf("destination already exists: %s", e.Path)
+}
+
+// AlreadyAtDestinationError represents an error when the worktree is already where it would be moved
+type AlreadyAtDestinationError struct {
+	Path string
+}
+
+func (e *AlreadyAtDestinationError) Error() string {
+	return fmt.Sprintf("worktree is already at %s", e.Path)
+}
+
+type mvCmdConfig struct {
+	renameBranch bool
+	cd           bool
+	match        matchOptions
+}
+
+func newMvCmd() *cobra.Command {
+	cfg := &mvCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "mv <query> [new-branch-or-path]",
+		Short: "Move or rename a worktree",
+		Long: `Move or rename a worktree directory.
+
+The worktree to move is selected by query (interactively if several match).
+The destination is interpreted as:
+  - a path, if it is absolute or starts with ./ or ../
+  - otherwise a branch name, placed using the naming convention
+If the destination is omitted, the worktree is moved to where the naming
+convention puts its current branch (e.g. after changing the configuration).
+
+Warning: Main worktree (repository root) cannot be moved.
+
+Examples:
+  wt mv feature feature/login        # Move to the path for feature/login
+  wt mv feature feature/login --rename-branch  # Also rename the branch
+  wt mv feature ../scratch           # Move to an explicit path
+  wt mv feature                      # Re-apply the naming convention`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runMvWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.renameBranch, "rename-branch", false, "Also rename the branch to the new branch name (git branch -m)")
+	cmd.Flags().BoolVar(&cfg.cd, "cd", false, "Output new worktree path to stdout after moving (for cd with shell function)")
+	addSelectionFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+var mvCmd = newMvCmd()
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMvWithConfig(cmd *cobra.Command, args []string, cfg *mvCmdConfig) error {
+	ctx := cmd.Context()
+
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeMove); err != nil {
+		return err
+	}
+
+	// Check if shell function is configured when using --cd
+	if err := checkShellFunction(cfg.cd); err != nil {
+		return err
+	}
+
+	query := args[0]
+	destination := ""
+	if len(args) > 1 {
+		destination = args[1]
+	}
+
+	// Select the worktree to move (the main worktree is excluded)
+	worktrees, items, err := getRemovableWorktrees(ctx)
+	if err != nil {
+		return err
+	}
+	selectedIndex, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), items, query, selectOptions{prompt: "Select worktree to move", match: cfg.match})
+	if err != nil {
+		return err
+	}
+	selected := worktrees[selectedIndex]
+
+	if selected.IsLocked {
+		return &WorktreeLockedError{Path: selected.Path, Reason: selected.LockReason}
+	}
+
+	// Determine the new branch name, if any
+	newBranch := ""
+	if cfg.renameBranch {
+		if destination == "" || isPathArgument(destination) {
+			return fmt.Errorf("--rename-branch requires a new branch name")
+		}
+		if selected.Branch == "" {
+			return fmt.Errorf("cannot rename branch: worktree is in detached HEAD state")
+		}
+		if err := validateBranchName(destination); err != nil {
+			return err
+		}
+		newBranch = destination
+	}
+
+	newPath, err := resolveMoveDestination(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), selected, destination)
+	if err != nil {
+		return err
+	}
+
+	// Rename the branch first so a failed move can be rolled back
+	if newBranch != "" {
+		if err := gitx.RenameBranch(ctx, selected.Branch, newBranch); err != nil {
+			return fmt.Errorf("failed to rename branch: %w", err)
+		}
+	}
+
+	// git worktree move doesn't create missing parent directories (e.g. nested branch directories)
+	if err := prepareWorktreeParent(ctx, newPath); err != nil {
+		if newBranch != "" {
+			_ = gitx.RenameBranch(ctx, newBranch, selected.Branch) // Best-effort rollback
+		}
+		return err
+	}
+
+	if err := gitx.Move(ctx, selected.Path, newPath); err != nil {
+		if newBranch != "" {
+			_ = gitx.RenameBranch(ctx, newBranch, selected.Branch) // Best-effort rollback
+		}
+		removeEmptyWorktreeParents(ctx, newPath)
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+	removeEmptyWorktreeParents(ctx, selected.Path)
+
+	branch := selected.Branch
+	if newBranch != "" {
+		branch = newBranch
+	}
+	printMoveSuccess(cmd.OutOrStdout(), selected.Path, newPath, branch, cfg.cd, flagQuiet)
+
+	return nil
+}
+
+// resolveMoveDestination returns the absolute path the worktree should be moved to
+func resolveMoveDestination(ctx context.Context, r io.Reader, errW io.Writer, wt gitx.Worktree, destination string) (string, error) {
+	if destination != "" && isPathArgument(destination) {
+		path, err := gitx.AbsPath(ctx, destination)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+		}
+		if filepath.Clean(path) == filepath.Clean(wt.Path) {
+			return "", &AlreadyAtDestinationError{Path: wt.Path}
+		}
+		if _, err := os.Stat(path); err == nil {
+			return "", &DestinationExistsError{Path: path}
+		}
+		return path, nil
+	}
+
+	branch := destination
+	if branch == "" {
+		if wt.Branch == "" {
+			return "", fmt.Errorf("cannot determine destination: worktree is in detached HEAD state (specify a new branch name or path)")
+		}
+		branch = wt.Branch
+	}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository information: %w", err)
+	}
+
+	baseDir, err := resolveAndValidateBaseDir(ctx, "", repo.Parent)
+	if err != nil {
+		return "", err
+	}
+
+	newPath, err := generateWorktreePath(ctx, r, errW, baseDir, repo.Name, branch, wt.Path)
+	var collision *naming.CollisionError
+	if errors.As(err, &collision) && collision.Path == wt.Path {
+		return "", &AlreadyAtDestinationError{Path: wt.Path}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate worktree path: %w", err)
+	}
+
+	// When re-applying the convention, the path for the current branch may be taken by the worktree itself
+	if destination == "" && isConventionPathFor(wt.Path, newPath) {
+		return "", &AlreadyAtDestinationError{Path: wt.Path}
+	}
+
+	return newPath, nil
+}
+
+// isConventionPathFor reports whether current is already the convention path that generated was derived from
+// generated skips paths that exist (including current) by adding a numbered suffix, so suffixes are ignored.
+func isConventionPathFor(current, generated string) bool {
+	if filepath.Dir(current) != filepath.Dir(generated) {
+		return false
+	}
+	return trimNumberSuffix(filepath.Base(current)) == trimNumberSuffix(filepath.Base(generated))
+}
+
+// trimNumberSuffix removes a "-<n>" suffix added for uniqueness, e.g. "feature-2" -> "feature"
+func trimNumberSuffix(name string) string {
+	i := strings.LastIndex(name, "-")
+	if i < 0 || i == len(name)-1 {
+		return name
+	}
+	for _, r := range name[i+1:] {
+		if r < '0' || r > '9' {
+			return name
+		}
+	}
+	return name[:i]
+}
+
+// isPathArgument reports whether arg should be treated as a path rather than a branch name
+func isPathArgument(arg string) bool {
+	return filepath.IsAbs(arg) || strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../")
+}
+
+func printMoveSuccess(w io.Writer, oldPath, newPath, branch string, cdMode, quiet bool) {
+	if cdMode {
+		printCdPath(w, newPath, branch)
+		return
+	}
+
+	if quiet {
+		return
+	}
+
+	fmt.Fprintf(w, "✓ Moved worktree\n")
+	if branch != "" {
+		fmt.Fprintf(w, "  Branch: %s\n", branch)
+	}
+	fmt.Fprintf(w, "  From: %s\n", oldPath)
+	fmt.Fprintf(w, "  To: %s\n", newPath)
+}
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestMvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "locked",
+			err:  &WorktreeLockedError{Path: "/work/.repo-wt/feature"},
+			want: "worktree is locked: /work/.repo-wt/feature",
+		},
+		{
+			name: "locked with reason",
+			err:  &WorktreeLockedError{Path: "/work/.repo-wt/feature", Reason: "on USB drive"},
+			want: "worktree is locked: /work/.repo-wt/feature (on USB drive)",
+		},
+		{
+			name: "destination exists",
+			err:  &DestinationExistsError{Path: "/work/other"},
+			want: "destination already exists: /work/other",
+		},
+		{
+			name: "already at destination",
+			err:  &AlreadyAtDestinationError{Path: "/work/.repo-wt/feature"},
+			want: "worktree is already at /work/.repo-wt/feature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.err.Error(), tt.want) {
+				t.Errorf("Error() = %q, want it to contain %q", tt.err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPathArgument(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"feature/login", false},
+		{"login", false},
+		{"./login", true},
+		{"../scratch", true},
+		{"/tmp/scratch", true},
+	}
+
+	for _, tt := range tests {
+		if got := isPathArgument(tt.arg); got != tt.want {
+			t.Errorf("isPathArgument(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestIsConventionPathFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		generated string
+		want      bool
+	}{
+		{
+			name:      "already at convention path",
+			current:   "/work/.repo-wt/feature",
+			generated: "/work/.repo-wt/feature-2",
+			want:      true,
+		},
+		{
+			name:      "at numbered convention path",
+			current:   "/work/.repo-wt/feature-2",
+			generated: "/work/.repo-wt/feature-3",
+			want:      true,
+		},
+		{
+			name:      "different directory",
+			current:   "/work/repo-feature",
+			generated: "/work/.repo-wt/feature",
+			want:      false,
+		},
+		{
+			name:      "different name",
+			current:   "/work/.repo-wt/old",
+			generated: "/work/.repo-wt/feature",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConventionPathFor(tt.current, tt.generated); got != tt.want {
+				t.Errorf("isConventionPathFor(%q, %q) = %v, want %v", tt.current, tt.generated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunMvWithConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtDir := filepath.Join(filepath.Dir(repoPath), ".test-repo-wt")
+	oldPath := filepath.Join(wtDir, "old")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/old", oldPath)
+
+	run := func(args []string, cfg *mvCmdConfig) (string, error) {
+		var buf bytes.Buffer
+		cmd := newMvCmd()
+		cmd.SetOut(&buf)
+		cmd.SetContext(context.Background())
+		err := runMvWithConfig(cmd, args, cfg)
+		return buf.String(), err
+	}
+
+	// Move to the convention path of another branch name, renaming the branch
+	output, err := run([]string{"feature/old", "feature/new"}, &mvCmdConfig{renameBranch: true})
+	if err != nil {
+		t.Fatalf("runMvWithConfig() returned error: %v", err)
+	}
+	newPath := filepath.Join(wtDir, "feature-new")
+	if !strings.Contains(output, "To: "+newPath) {
+		t.Errorf("output should contain the new path, got: %s", output)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old path should no longer exist")
+	}
+	if branch := strings.TrimSpace(runGitForTest(t, newPath, "branch", "--show-current")); branch != "feature/new" {
+		t.Errorf("branch = %q, want %q", branch, "feature/new")
+	}
+
+	// Re-applying the convention is a no-op
+	if _, err := run([]string{"feature/new"}, &mvCmdConfig{}); !errors.As(err, new(*AlreadyAtDestinationError)) {
+		t.Errorf("runMvWithConfig() error = %v, want AlreadyAtDestinationError", err)
+	}
+
+	// Existing destination path
+	existing := filepath.Join(t.TempDir(), "existing")
+	if err := os.Mkdir(existing, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, err := run([]string{"feature/new", existing}, &mvCmdConfig{}); !errors.As(err, new(*DestinationExistsError)) {
+		t.Errorf("runMvWithConfig() error = %v, want DestinationExistsError", err)
+	}
+
+	// Locked worktree
+	runGitForTest(t, repoPath, "worktree", "lock", newPath)
+	if _, err := run([]string{"feature/new", "feature/other"}, &mvCmdConfig{}); !errors.As(err, new(*WorktreeLockedError)) {
+		t.Errorf("runMvWithConfig() error = %v, want WorktreeLockedError", err)
+	}
+	runGitForTest(t, repoPath, "worktree", "unlock", newPath)
+
+	// Explicit path with --cd prints only the new path
+	t.Setenv("WT_SHELL_FUNCTION", "1")
+	target := filepath.Join(t.TempDir(), "moved")
+	output, err = run([]string{"feature/new", target}, &mvCmdConfig{cd: true})
+	if err != nil {
+		t.Fatalf("runMvWithConfig() returned error: %v", err)
+	}
+	if output != target+"\n" {
+		t.Errorf("output = %q, want %q", output, target+"\n")
+	}
+}
+
+func TestFeatureChecksRequireGitVersion(t *testing.T) {
+	fakeGitVersion(t, "2.16.2")
+
+	tests := []struct {
+		name string
+		run  func(cmd *cobra.Command) error
+	}{
+		{name: "mv", run: func(cmd *cobra.Command) error { return runMvWithConfig(cmd, []string{"a", "b"}, &mvCmdConfig{}) }},
+		{name: "repair", run: func(cmd *cobra.Command) error { return runRepairWithConfig(cmd, nil, &repairCmdConfig{}) }},
+		{name: "clean", run: func(cmd *cobra.Command) error { return runCleanWithConfig(cmd, []string{"a"}, &cleanCmdConfig{}) }},
+		{name: "clean --restore", run: func(cmd *cobra.Command) error { return runCleanWithConfig(cmd, nil, &cleanCmdConfig{restore: true}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			err := tt.run(cmd)
+			var minErr *gitx.MinVersionError
+			if !errors.As(err, &minErr) {
+				t.Fatalf("error = %v, want a MinVersionError", err)
+			}
+			if exitCode(err) != ExitToolMissing {
+				t.Errorf("exit code = %d, want %d", exitCode(err), ExitToolMissing)
+			}
+		})
+	}
+}
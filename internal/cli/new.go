@@ -9,24 +9,48 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/gitx"
-	"github.com/toritori0318/git-wt/internal/naming"
 )
 
 // BranchInUseError represents an error when a branch is already in use
 type BranchInUseError struct {
-	Branch string
-	Path   string
+	Branch        string
+	Path          string
+	ShellFunction bool // wt go can only change directory with the shell function
+}
+
+// newBranchInUseError returns a BranchInUseError suggesting how to get to the worktree at path
+func newBranchInUseError(branch, path string) *BranchInUseError {
+	return &BranchInUseError{Branch: branch, Path: path, ShellFunction: shellFunctionActive()}
 }
 
 func (e *BranchInUseError) Error() string {
-	return fmt.Sprintf("branch '%s' is already in use at %s.\nNavigate: wt go %s\nOpen: wt open %s",
-		e.Branch, e.Path, e.Branch, e.Branch)
+	return fmt.Sprintf("branch '%s' is already in use at %s.\nNavigate: %s\nOpen: wt open %s",
+		e.Branch, e.Path, navigateCommand(e.Branch, e.Path, e.ShellFunction), e.Branch)
+}
+
+// navigateCommand returns the command that gets to the worktree of branch at path:
+// wt go with the shell function, which changes directory, or else cd
+func navigateCommand(branch, path string, shellFunction bool) string {
+	if shellFunction {
+		return "wt go " + branch
+	}
+	return "cd " + path
 }
 
 type newCmdConfig struct {
-	baseDir string
-	cd      bool
+	baseDir          string
+	cd               bool
+	noRemoteCheck    bool
+	push             bool
+	setUpstreamOnly  bool
+	fromStash        string
+	dropStash        bool
+	from             string
+	includeUntracked bool
+	force            bool
+	setup            setupOptions
 }
 
 func newNewCmd() *cobra.Command {
@@ -39,7 +63,46 @@ func newNewCmd() *cobra.Command {
 
 Worktrees are automatically placed using the naming convention <repo>-<branch>.
 If an existing branch is specified, that branch will be checked out.
-For new branches, they are created from start-point (defaults to current HEAD if omitted).`,
+For new branches, they are created from start-point (defaults to current HEAD if omitted).
+
+If a remote already has a branch of the same name (as of the last fetch) that the new
+branch wouldn't contain, pushing it later fails. wt new warns about this and offers to
+base the new branch on the remote branch instead (--yes accepts). It also warns when an
+existing local branch has no history in common with the remote branch. Skip the check
+with --no-remote-check or worktree.remote_check: false.
+
+With --push (or worktree.auto_set_upstream: true), a newly created branch is pushed to
+worktree.default_remote (default "origin") and set as its upstream (git push -u), so the
+first git push needs no arguments. --set-upstream-only only records the upstream without
+pushing. An existing branch keeps its upstream, and a failed push (e.g. offline) only
+warns: the worktree is created either way.
+
+With --from-stash, the new branch starts at the commit a stash entry was made on and the
+stash is applied to the worktree (like git stash branch, but in a new worktree). Without a
+value the latest entry is used; --from-stash=<stash> takes a ref ("stash@{2}"), an index
+("2") or text from the stash message, offering the matching entries for selection if
+there are several. --drop drops the entry once it is applied. If the stash doesn't apply,
+the new worktree and branch are removed again and the entry is kept.
+
+With --from <query>, the new branch starts at the HEAD of the worktree matching query and
+that worktree's uncommitted changes (staged and unstaged, with -u also untracked files)
+are copied to the new worktree like wt cp, e.g. to try another approach to the same change.
+
+With --force, a worktree path that is taken by a leftover is reused instead of getting a
+numbered suffix: a registration whose directory is gone is pruned, and a directory git
+doesn't know as a worktree (e.g. after a crashed removal) is reused if it is an old worktree
+of this repository, or else moved to the trash, after a confirmation showing its size
+(--yes accepts). A branch registered to a worktree whose directory is gone is freed the same
+way. A live worktree is never touched.
+
+Examples:
+  wt new feature/login
+  wt new hotfix v1.2.0
+  wt new feature/login --push
+  wt new resume-login --from-stash
+  wt new resume-login --from-stash=login --drop
+  wt new feature/login-v2 --from login -u
+  wt new feature/login --force`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 || len(args) > 2 {
 				cmd.Help()
@@ -50,15 +113,28 @@ For new branches, they are created from start-point (defaults to current HEAD if
 			}
 			return nil
 		},
+		ValidArgsFunction: completeNewBranches,
 		RunE: func(c *cobra.Command, args []string) error {
 			return runNewWithConfig(c, args, cfg)
 		},
 	}
 
-	cmd.Flags().StringVar(&cfg.baseDir, "base-dir", "", "Base directory for worktree placement (defaults to repository parent)")
+	cmd.Flags().StringVar(&cfg.baseDir, "base-dir", "", "Base directory for worktree placement (defaults to worktree.base_dir or the repository parent)")
 	cmd.Flags().BoolVar(&cfg.cd, "cd", false, "Output worktree path to stdout after creation (for cd with shell function)")
-
-	return cmd
+	cmd.Flags().BoolVar(&cfg.noRemoteCheck, "no-remote-check", false, "Don't check for a remote branch of the same name with different history")
+	cmd.Flags().BoolVar(&cfg.push, "push", false, "Push a new branch to worktree.default_remote and set its upstream")
+	cmd.Flags().BoolVar(&cfg.setUpstreamOnly, "set-upstream-only", false, "Set the upstream of a new branch to worktree.default_remote without pushing")
+	cmd.Flags().StringVar(&cfg.fromStash, "from-stash", "", "Start the branch at a stash entry's base commit and apply the stash (default: the latest entry)")
+	cmd.Flags().Lookup("from-stash").NoOptDefVal = latestStash
+	cmd.Flags().BoolVar(&cfg.dropStash, "drop", false, "Drop the stash entry once --from-stash applied it")
+	cmd.Flags().StringVar(&cfg.from, "from", "", "Start at the HEAD of the worktree matching this query and copy its uncommitted changes")
+	cmd.Flags().BoolVarP(&cfg.includeUntracked, "include-untracked", "u", false, "With --from, also copy untracked files (ignored files are never copied)")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Reuse the worktree path and branch if only a stale directory or registration holds them")
+	cmd.MarkFlagsMutuallyExclusive("push", "set-upstream-only")
+	cmd.MarkFlagsMutuallyExclusive("from", "from-stash")
+	addSetupFlags(cmd, &cfg.setup)
+
+	return withJSON(cmd)
 }
 
 var newCmd = newNewCmd()
@@ -86,75 +162,271 @@ func runNewWithConfig(cmd *cobra.Command, args []string, cfg *newCmdConfig) erro
 		startPoint = args[1]
 	}
 
+	result, source, err := createNewWorktree(ctx, cmd, branch, startPoint, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Success message
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+	progress := newProgressPrinter(cmd.OutOrStdout(), cfg.cd, flagQuiet)
+	printSuccess(progress, result.Path, branch)
+	printReused(progress, result.Reused)
+	printUpstreamSet(progress, result.Upstream, result.Pushed)
+	printStashApplied(progress, result.Stash, result.StashDropped)
+	printDuplicatedFrom(progress, source, result.Carried)
+
+	return nil
+}
+
+// createNewWorktree creates the worktree for branch in the repository ctx runs git in, as wt new does
+// It returns the result and, with --from, the worktree that was duplicated. Prompts, warnings and
+// progress go to cmd's stderr; nothing is written to stdout.
+func createNewWorktree(ctx context.Context, cmd *cobra.Command, branch, startPoint string, cfg *newCmdConfig) (*newResult, *gitx.Worktree, error) {
 	// Get repository information
 	repo, err := gitx.GetRepo(ctx, flagRepo)
 	if err != nil {
-		return fmt.Errorf("failed to get repository information: %w", err)
+		return nil, nil, fmt.Errorf("failed to get repository information: %w", err)
+	}
+
+	// The stash entry determines the start point
+	var stash *gitx.Stash
+	if cfg.fromStash != "" {
+		if startPoint != "" {
+			return nil, nil, &UsageError{Err: fmt.Errorf("--from-stash starts the branch at the stash's base commit: don't give a start point")}
+		}
+		if stash, err = resolveStash(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), cfg.fromStash); err != nil {
+			return nil, nil, err
+		}
+		startPoint = stash.Base
+	} else if cfg.dropStash {
+		return nil, nil, &UsageError{Err: fmt.Errorf("--drop requires --from-stash")}
+	}
+
+	// So does the worktree to duplicate
+	var source *gitx.Worktree
+	if cfg.from != "" {
+		if startPoint != "" {
+			return nil, nil, &UsageError{Err: fmt.Errorf("--from starts the branch at the source worktree's HEAD: don't give a start point")}
+		}
+		if source, err = selectSourceWorktree(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), cfg.from); err != nil {
+			return nil, nil, err
+		}
+		startPoint = source.HEAD
+	} else if cfg.includeUntracked {
+		return nil, nil, &UsageError{Err: fmt.Errorf("--include-untracked requires --from")}
 	}
 
 	// Determine and validate base directory
-	baseDir, err := resolveAndValidateBaseDir(cfg.baseDir, repo.Parent)
+	baseDir, err := resolveAndValidateBaseDir(ctx, cfg.baseDir, repo.Parent)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	// Sanitize branch name
-	sanitized := naming.Sanitize(branch)
-
 	// Generate worktree path
-	worktreePath, err := naming.GenerateWorktreePath(baseDir, repo.Name, sanitized)
+	var worktreePath string
+	var stale *staleDir
+	if cfg.force {
+		if err := pruneStaleBranchWorktree(ctx, branch); err != nil {
+			return nil, nil, err
+		}
+		// Before the path: a live worktree of the branch is the error to report, not its path
+		if err := checkBranchNotInUse(ctx, branch); err != nil {
+			return nil, nil, err
+		}
+		worktreePath, stale, err = forceWorktreePath(ctx, baseDir, repo.Name, branch)
+	} else {
+		worktreePath, err = generateWorktreePath(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), baseDir, repo.Name, branch, "")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to generate worktree path: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate worktree path: %w", err)
 	}
 
 	// Check if branch is already in use by another worktree
 	if err := checkBranchNotInUse(ctx, branch); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Check if branch exists
 	branchExists, err := gitx.BranchExists(ctx, branch)
 	if err != nil {
-		return fmt.Errorf("failed to check branch existence: %w", err)
+		return nil, nil, fmt.Errorf("failed to check branch existence: %w", err)
+	}
+
+	if stash != nil && branchExists {
+		return nil, nil, fmt.Errorf("branch '%s' already exists: --from-stash creates a new branch at the stash's base commit", branch)
+	}
+	if source != nil && branchExists {
+		return nil, nil, fmt.Errorf("branch '%s' already exists: --from creates a new branch at the source worktree's HEAD", branch)
+	}
+
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check for a remote branch of the same name that pushing would conflict with
+	// (not for a stash or a source worktree, whose changes apply to the commit they were made on)
+	if !cfg.noRemoteCheck && wtCfg.GetRemoteCheck() && stash == nil && source == nil {
+		startPoint, err = checkRemoteBranch(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), branch, startPoint, branchExists)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// An old worktree left at the path is reused, unless a stash or another worktree's changes go there
+	reuse := stale != nil && stale.OldWorktree && stash == nil && source == nil
+	if stale != nil {
+		if err := confirmStaleDir(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), stale, reuse); err != nil {
+			return nil, nil, err
+		}
+		if !reuse {
+			if err := trashStaleDir(stale); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	// Create worktree
 	createNewBranch := !branchExists
-	if err := gitx.Add(ctx, worktreePath, branch, startPoint, createNewBranch); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	if err := prepareWorktreeParent(ctx, worktreePath); err != nil {
+		return nil, nil, err
+	}
+	if reuse {
+		if err := linkWorktreeDir(ctx, worktreePath, branch, startPoint, createNewBranch, nil); err != nil {
+			return nil, nil, fmt.Errorf("failed to reuse %s: %w", worktreePath, err)
+		}
+	} else if err := gitx.AddWithProgress(ctx, worktreePath, branch, startPoint, createNewBranch, worktreeAddProgress(cmd, cfg.cd)); err != nil {
+		return nil, nil, fmt.Errorf("failed to create worktree: %w", err)
 	}
 
-	// Success message
-	printSuccess(cmd.OutOrStdout(), worktreePath, branch, cfg.cd, flagQuiet)
+	// Apply the stash before anything else touches the worktree
+	result := newResult{Path: worktreePath, Branch: branch, CreatedBranch: createNewBranch, Reused: reuse}
+	if stash != nil {
+		if err := applyStashToWorktree(ctx, worktreePath, branch, stash); err != nil {
+			return nil, nil, err
+		}
+		result.Stash = stash.Ref
+		if cfg.dropStash {
+			result.StashDropped = dropAppliedStash(ctx, cmd.ErrOrStderr(), stash)
+		}
+	}
+	if source != nil {
+		result.From = source.Path
+		if result.Carried, err = carryChangesToNewWorktree(ctx, *source, worktreePath, branch, cfg.includeUntracked); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	return nil
+	// Initialize submodules and LFS objects
+	if err := setupWorktree(ctx, cmd.ErrOrStderr(), worktreePath, cfg.setup); err != nil {
+		return nil, nil, err
+	}
+
+	// Push or track the new branch; an existing branch keeps its upstream
+	if action := upstreamAction(cfg, wtCfg); action != "" && createNewBranch {
+		result.Upstream, result.Pushed = setNewBranchUpstream(ctx, cmd.ErrOrStderr(), worktreePath, branch, wtCfg.GetDefaultRemote(), action)
+	}
+
+	return &result, source, nil
+}
+
+// removeNewWorktree removes a worktree wt new just created, with its new branch, after a later step failed
+func removeNewWorktree(ctx context.Context, path, branch string) {
+	_ = gitx.Remove(ctx, path, true) // Best-effort rollback
+	_ = gitx.DeleteBranch(ctx, branch, true)
+	_ = gitx.Prune(ctx)
+	removeEmptyWorktreeParents(ctx, path)
+}
+
+// worktreeAddProgress returns where git shows its checkout progress while creating a worktree (nil for nowhere)
+// Large repositories take a while to check out; progress is hidden with --quiet, --json and --cd.
+func worktreeAddProgress(cmd *cobra.Command, cdMode bool) io.Writer {
+	if flagQuiet || cdMode || jsonOutput() {
+		return nil
+	}
+	return cmd.ErrOrStderr()
 }
 
+// validateBranchName checks branch against the rules of git check-ref-format --branch, so that
+// a bad name is reported before anything is fetched or created (shared by new, pr, mv and tmux)
 func validateBranchName(branch string) error {
 	if strings.TrimSpace(branch) == "" {
 		return fmt.Errorf("branch name cannot be empty")
 	}
-
-	// Check for Git-prohibited patterns
-	if strings.Contains(branch, "..") || strings.HasPrefix(branch, "-") {
-		return fmt.Errorf("invalid branch name: %s", branch)
+	if reason := branchNameProblem(branch); reason != "" {
+		return fmt.Errorf("invalid branch name: %s (%s)", branch, reason)
 	}
-
 	return nil
 }
 
-func resolveAndValidateBaseDir(customBaseDir, defaultBaseDir string) (string, error) {
+// branchNameProblem returns why git would reject branch as a branch name, or "" if it wouldn't
+func branchNameProblem(branch string) string {
+	switch {
+	case strings.HasPrefix(branch, "-"):
+		return "cannot start with '-'"
+	case branch == "@" || branch == "HEAD":
+		return fmt.Sprintf("'%s' is reserved", branch)
+	case strings.Contains(branch, ".."):
+		return "cannot contain '..'"
+	case strings.Contains(branch, "@{"):
+		return "cannot contain '@{'"
+	case strings.HasPrefix(branch, "/") || strings.HasSuffix(branch, "/") || strings.Contains(branch, "//"):
+		return "cannot start or end with '/' or contain '//'"
+	case strings.HasSuffix(branch, "."):
+		return "cannot end with '.'"
+	}
+	for _, r := range branch {
+		if r < 0x20 || r == 0x7f || r == ' ' {
+			return "cannot contain spaces or control characters"
+		}
+		if strings.ContainsRune(`~^:?*[\`, r) {
+			return fmt.Sprintf("cannot contain '%c'", r)
+		}
+	}
+	for _, component := range strings.Split(branch, "/") {
+		if strings.HasPrefix(component, ".") {
+			return "no part can start with '.'"
+		}
+		if strings.HasSuffix(component, ".lock") {
+			return "no part can end with '.lock'"
+		}
+	}
+	return ""
+}
+
+// resolveAndValidateBaseDir returns the canonical base directory, resolving a relative one against
+// the --cwd directory. Without --base-dir, worktree.base_dir from the configuration is used (and
+// created on first use), and defaultBaseDir when that isn't set either.
+func resolveAndValidateBaseDir(ctx context.Context, customBaseDir, defaultBaseDir string) (string, error) {
 	baseDir := customBaseDir
+	configured := false
 	if baseDir == "" {
-		return defaultBaseDir, nil
+		if cfg, err := loadWorktreeConfig(ctx); err == nil {
+			baseDir = cfg.GetBaseDir()
+		}
+		if baseDir == "" {
+			return defaultBaseDir, nil
+		}
+		configured = true
 	}
 
-	// Normalize path (resolve relative paths and symlinks)
-	absPath, err := filepath.Abs(baseDir)
+	// Normalize path (resolve relative paths against -C/--cwd, and symlinks below)
+	absPath, err := gitx.AbsPath(ctx, baseDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
 	}
 
+	// The configured directory is the home of all worktrees, so it's fine to create it;
+	// a mistyped --base-dir is more likely a mistake
+	if configured {
+		if err := os.MkdirAll(absPath, 0755); err != nil {
+			return "", fmt.Errorf("failed to create base directory (worktree.base_dir): %w", err)
+		}
+	}
+
 	// Validate user-specified base directory
 	info, err := os.Stat(absPath)
 	if err != nil {
@@ -179,6 +451,15 @@ func resolveAndValidateBaseDir(customBaseDir, defaultBaseDir string) (string, er
 	return canonicalPath, nil
 }
 
+// configuredBaseDir returns the directory worktrees of repo are placed in without --base-dir:
+// worktree.base_dir if it is set, otherwise the repository's parent directory
+func configuredBaseDir(repo *gitx.Repo, cfg *config.Config) string {
+	if dir := cfg.GetBaseDir(); dir != "" {
+		return dir
+	}
+	return repo.Parent
+}
+
 func checkBranchNotInUse(ctx context.Context, branch string) error {
 	existingWT, err := gitx.FindWorktreeByBranch(ctx, branch)
 	if err != nil {
@@ -186,26 +467,143 @@ func checkBranchNotInUse(ctx context.Context, branch string) error {
 	}
 
 	if existingWT != nil {
-		return &BranchInUseError{
-			Branch: branch,
-			Path:   existingWT.Path,
-		}
+		return newBranchInUseError(branch, existingWT.Path)
 	}
 
 	return nil
 }
 
-func printSuccess(w io.Writer, worktreePath, branch string, cdMode, quiet bool) {
-	if cdMode {
-		fmt.Fprintln(w, worktreePath)
-		return
+// checkRemoteBranch warns when a remote-tracking branch of the same name has history that branch lacks
+// For a new branch, it offers to start from the remote branch instead and returns the start point to use.
+// Without a terminal it only warns. For an existing local branch, it warns if the two have no common history.
+func checkRemoteBranch(ctx context.Context, r io.Reader, w io.Writer, branch, startPoint string, branchExists bool) (string, error) {
+	remoteRef, err := gitx.FindRemoteTrackingBranch(ctx, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to check remote branches: %w", err)
 	}
+	if remoteRef == "" {
+		return startPoint, nil
+	}
+	remoteBranch := strings.TrimPrefix(remoteRef, "refs/remotes/")
 
-	if quiet {
+	if branchExists {
+		base, err := gitx.MergeBase(ctx, "refs/heads/"+branch, remoteRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to compare with %s: %w", remoteBranch, err)
+		}
+		if base == "" {
+			fmt.Fprintf(w, "Warning: branch '%s' has no history in common with %s; pushing it will fail\n", branch, remoteBranch)
+		}
+		return startPoint, nil
+	}
+
+	from := startPoint
+	if from == "" {
+		from = "HEAD"
+	}
+	contained, err := gitx.IsAncestor(ctx, remoteRef, from)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare with %s: %w", remoteBranch, err)
+	}
+	if contained {
+		return startPoint, nil
+	}
+
+	fmt.Fprintf(w, "Warning: %s already exists with commits %s doesn't have; pushing the new branch will fail\n", remoteBranch, from)
+	if !flagYes {
+		if !isTerminal(r) {
+			return startPoint, nil
+		}
+		confirmed, err := confirmWith(ctx, r, w, fmt.Sprintf("Base '%s' on %s instead?", branch, remoteBranch), "--yes or --no-remote-check")
+		if err != nil || !confirmed {
+			return startPoint, err
+		}
+	}
+	fmt.Fprintf(w, "Basing '%s' on %s\n", branch, remoteBranch)
+	return remoteRef, nil
+}
+
+// newResult is the JSON output of wt new
+type newResult struct {
+	Path          string   `json:"path"`
+	Branch        string   `json:"branch"`
+	CreatedBranch bool     `json:"created_branch"`
+	Upstream      string   `json:"upstream,omitempty"` // e.g. "origin/feature", set with --push or --set-upstream-only
+	Pushed        bool     `json:"pushed,omitempty"`
+	Stash         string   `json:"stash,omitempty"` // The stash entry applied with --from-stash, e.g. "stash@{0}"
+	StashDropped  bool     `json:"stash_dropped,omitempty"`
+	From          string   `json:"from,omitempty"`    // The worktree duplicated with --from
+	Carried       []string `json:"carried,omitempty"` // Uncommitted changes copied from it
+	Reused        bool     `json:"reused,omitempty"`  // With --force, the files of a stale directory at the path were kept
+}
+
+// What wt new does about the upstream of a branch it creates
+const (
+	upstreamPush   = "push"   // git push -u <remote> <branch>
+	upstreamConfig = "config" // Only set branch.<name>.remote and branch.<name>.merge
+)
+
+// upstreamAction returns what to do about the upstream of a new branch ("" for nothing)
+// The flags take precedence over worktree.auto_set_upstream.
+func upstreamAction(cfg *newCmdConfig, wtCfg *config.Config) string {
+	switch {
+	case cfg.setUpstreamOnly:
+		return upstreamConfig
+	case cfg.push || wtCfg.GetAutoSetUpstream():
+		return upstreamPush
+	}
+	return ""
+}
+
+// setNewBranchUpstream pushes branch from the worktree at path to remote and sets it as the upstream,
+// or only sets the upstream for upstreamConfig. It returns the upstream (e.g. "origin/feature") and
+// whether the branch was pushed. Failures (no such remote, no permission, offline) only produce a
+// warning on w and return "": the worktree was created all the same.
+func setNewBranchUpstream(ctx context.Context, w io.Writer, path, branch, remote, action string) (string, bool) {
+	if gitx.RemoteURL(ctx, "", remote) == "" {
+		fmt.Fprintf(w, "Warning: remote '%s' not found, not setting an upstream for %s (set worktree.default_remote)\n", remote, branch)
+		return "", false
+	}
+
+	if action == upstreamConfig {
+		if err := gitx.SetUpstreamConfig(ctx, branch, remote); err != nil {
+			fmt.Fprintf(w, "Warning: failed to set the upstream of %s: %v\n", branch, err)
+			return "", false
+		}
+		return remote + "/" + branch, false
+	}
+
+	if err := gitx.PushSetUpstream(ctx, path, remote, branch); err != nil {
+		fmt.Fprintf(w, "Warning: failed to push %s to %s (push later with: git push -u %s %s): %v\n", branch, remote, remote, branch, err)
+		return "", false
+	}
+	return remote + "/" + branch, true
+}
+
+func printSuccess(p *progressPrinter, worktreePath, branch string) {
+	if p.cdMode {
+		printCdPath(p.w, worktreePath, branch)
 		return
 	}
 
-	fmt.Fprintf(w, "✓ Created worktree\n")
-	fmt.Fprintf(w, "  Branch: %s\n", branch)
-	fmt.Fprintf(w, "  Path: %s\n", worktreePath)
+	p.Printf("✓ Created worktree\n")
+	p.Printf("  Branch: %s\n", branch)
+	p.Printf("  Path: %s\n", worktreePath)
+}
+
+func printReused(p *progressPrinter, reused bool) {
+	if reused {
+		p.Printf("  Reused the files of the old worktree (see git status for differences)\n")
+	}
+}
+
+func printUpstreamSet(p *progressPrinter, upstream string, pushed bool) {
+	if upstream == "" {
+		return
+	}
+	if pushed {
+		p.Printf("  Upstream: %s (pushed)\n", upstream)
+		return
+	}
+	p.Printf("  Upstream: %s (created on the first push)\n", upstream)
 }
@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
 )
 
 func TestBranchInUseError(t *testing.T) {
@@ -25,6 +32,18 @@ func TestBranchInUseError(t *testing.T) {
 	}
 }
 
+func TestNewBranchInUseErrorNavigate(t *testing.T) {
+	t.Setenv("WT_SHELL_FUNCTION", "")
+	if msg := newBranchInUseError("feature", "/src/.proj-wt/feature").Error(); !strings.Contains(msg, "Navigate: cd /src/.proj-wt/feature") {
+		t.Errorf("BranchInUseError without the shell function = %q, want the cd command", msg)
+	}
+
+	t.Setenv("WT_SHELL_FUNCTION", "1")
+	if msg := newBranchInUseError("feature", "/src/.proj-wt/feature").Error(); !strings.Contains(msg, "Navigate: wt go feature") {
+		t.Errorf("BranchInUseError with the shell function = %q, want wt go", msg)
+	}
+}
+
 func TestValidateBranchName(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -71,6 +90,11 @@ func TestValidateBranchName(t *testing.T) {
 			branchName: "feature_test",
 			wantErr:    false,
 		},
+		{
+			name:       "valid nested name with dots",
+			branchName: "review/pr-123.v2",
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -81,4 +105,439 @@ func TestValidateBranchName(t *testing.T) {
 			}
 		})
 	}
+
+	// Names git check-ref-format --branch rejects
+	for _, name := range []string{"feature test", "feat~1", "feat^", "a:b", "what?", "glob*", "[x]", `back\slash`,
+		"/feature", "feature/", "feature//x", "feature.", "feature.lock", "feature/.hidden", "a@{b", "@", "HEAD"} {
+		if err := validateBranchName(name); err == nil {
+			t.Errorf("validateBranchName(%q) should fail", name)
+		}
+	}
+}
+
+func TestRunNewFromBareRepositoryWorktree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+
+	parent := t.TempDir()
+	barePath := filepath.Join(parent, "proj.git")
+	runGitForTest(t, parent, "clone", "--bare", repoPath, barePath)
+	mainPath := filepath.Join(parent, ".proj-wt", "main")
+	runGitForTest(t, barePath, "worktree", "add", mainPath, "main")
+	chdirForTest(t, mainPath)
+
+	var buf bytes.Buffer
+	cmd := newNewCmd()
+	cmd.SetOut(&buf)
+	cmd.SetContext(context.Background())
+	if err := runNewWithConfig(cmd, []string{"feature/login"}, &newCmdConfig{}); err != nil {
+		t.Fatalf("runNewWithConfig() returned error: %v", err)
+	}
+
+	want := filepath.Join(parent, ".proj-wt", "feature-login")
+	if !strings.Contains(buf.String(), "Path: "+want) {
+		t.Errorf("output should contain path %s, got: %s", want, buf.String())
+	}
+	if _, err := os.Stat(filepath.Join(want, ".git")); err != nil {
+		t.Errorf("worktree was not created at %s: %v", want, err)
+	}
+}
+
+func TestRunNewRemoteCheck(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+
+	// origin/feature/x is a commit ahead of main, as if a teammate had pushed it
+	runGitForTest(t, repoPath, "remote", "add", "origin", "https://example.com/repo.git")
+	runGitForTest(t, repoPath, "commit", "--allow-empty", "-m", "Teammate's work")
+	runGitForTest(t, repoPath, "update-ref", "refs/remotes/origin/feature/x", "HEAD")
+	runGitForTest(t, repoPath, "reset", "--hard", "HEAD~1")
+	head := strings.TrimSpace(runGitForTest(t, repoPath, "rev-parse", "HEAD"))
+	remote := strings.TrimSpace(runGitForTest(t, repoPath, "rev-parse", "refs/remotes/origin/feature/x"))
+
+	tests := []struct {
+		name     string
+		branch   string
+		input    string
+		cfg      newCmdConfig
+		wantHead string
+		wantWarn bool
+	}{
+		{name: "accept remote", branch: "feature/x", input: "y\n", wantHead: remote, wantWarn: true},
+		{name: "decline remote", branch: "feature/x", input: "n\n", wantHead: head, wantWarn: true},
+		{name: "no remote check", branch: "feature/x", cfg: newCmdConfig{noRemoteCheck: true}, wantHead: head},
+		{name: "no remote branch", branch: "feature/y", wantHead: head},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := newNewCmd()
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetContext(context.Background())
+			tt.cfg.baseDir = t.TempDir()
+			if err := runNewWithConfig(cmd, []string{tt.branch}, &tt.cfg); err != nil {
+				t.Fatalf("runNewWithConfig() returned error: %v", err)
+			}
+			_, path, _ := strings.Cut(stdout.String(), "Path: ")
+			runGitForTest(t, repoPath, "worktree", "remove", "--force", strings.TrimSpace(path))
+			defer runGitForTest(t, repoPath, "branch", "-D", tt.branch)
+
+			if got := strings.TrimSpace(runGitForTest(t, repoPath, "rev-parse", "refs/heads/"+tt.branch)); got != tt.wantHead {
+				t.Errorf("branch %s is at %s, want %s", tt.branch, got, tt.wantHead)
+			}
+			if got := strings.Contains(stderr.String(), "Warning: origin/feature/x already exists"); got != tt.wantWarn {
+				t.Errorf("warning shown = %v, want %v; stderr: %s", got, tt.wantWarn, stderr.String())
+			}
+		})
+	}
+
+	t.Run("unrelated local branch", func(t *testing.T) {
+		orphan := strings.TrimSpace(runGitForTest(t, repoPath, "commit-tree", "-m", "Unrelated", "HEAD^{tree}"))
+		runGitForTest(t, repoPath, "branch", "feature/x", orphan)
+
+		var stderr bytes.Buffer
+		cmd := newNewCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		cmd.SetContext(context.Background())
+		if err := runNewWithConfig(cmd, []string{"feature/x"}, &newCmdConfig{baseDir: t.TempDir()}); err != nil {
+			t.Fatalf("runNewWithConfig() returned error: %v", err)
+		}
+		if !strings.Contains(stderr.String(), "has no history in common with origin/feature/x") {
+			t.Errorf("stderr should warn about unrelated history, got: %s", stderr.String())
+		}
+	})
+}
+
+func TestRunNewSetUpstream(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	runGitForTest(t, repoPath, "init", "--bare", "--quiet", remotePath)
+	runGitForTest(t, repoPath, "remote", "add", "origin", remotePath)
+	runGitForTest(t, repoPath, "branch", "existing")
+
+	tests := []struct {
+		name         string
+		branch       string
+		cfg          newCmdConfig
+		env          map[string]string
+		wantUpstream bool
+		wantPushed   bool
+		wantWarning  string
+	}{
+		{name: "push", branch: "pushed", cfg: newCmdConfig{push: true}, wantUpstream: true, wantPushed: true},
+		{name: "set upstream only", branch: "tracked", cfg: newCmdConfig{setUpstreamOnly: true}, wantUpstream: true},
+		{name: "auto set upstream", branch: "auto", env: map[string]string{"WT_AUTO_SET_UPSTREAM": "true"}, wantUpstream: true, wantPushed: true},
+		{name: "off by default", branch: "plain"},
+		{name: "existing branch", branch: "existing", cfg: newCmdConfig{push: true}},
+		{name: "missing remote", branch: "nowhere", cfg: newCmdConfig{push: true}, env: map[string]string{"WT_DEFAULT_REMOTE": "upstream"}, wantWarning: "remote 'upstream' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var stdout, stderr bytes.Buffer
+			cmd := newNewCmd()
+			cmd.SetIn(strings.NewReader(""))
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetContext(context.Background())
+			tt.cfg.baseDir = t.TempDir()
+			if err := runNewWithConfig(cmd, []string{tt.branch}, &tt.cfg); err != nil {
+				t.Fatalf("runNewWithConfig() returned error: %v", err)
+			}
+
+			upstream := strings.TrimSpace(runGitForTest(t, repoPath, "for-each-ref", "--format=%(upstream:short)", "refs/heads/"+tt.branch))
+			if got := upstream == "origin/"+tt.branch; got != tt.wantUpstream {
+				t.Errorf("upstream = %q, want set %v", upstream, tt.wantUpstream)
+			}
+			pushed := strings.TrimSpace(runGitForTest(t, remotePath, "branch", "--list", tt.branch)) != ""
+			if pushed != tt.wantPushed {
+				t.Errorf("pushed = %v, want %v", pushed, tt.wantPushed)
+			}
+			if tt.wantUpstream && !strings.Contains(stdout.String(), "Upstream: origin/"+tt.branch) {
+				t.Errorf("stdout should show the upstream, got:\n%s", stdout.String())
+			}
+			if tt.wantWarning != "" && !strings.Contains(stderr.String(), tt.wantWarning) {
+				t.Errorf("stderr should contain %q, got:\n%s", tt.wantWarning, stderr.String())
+			}
+		})
+	}
+
+	t.Run("failed push", func(t *testing.T) {
+		runGitForTest(t, repoPath, "remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+
+		var stderr bytes.Buffer
+		cmd := newNewCmd()
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		cmd.SetContext(context.Background())
+		cfg := &newCmdConfig{baseDir: t.TempDir(), push: true}
+		if err := runNewWithConfig(cmd, []string{"offline"}, cfg); err != nil {
+			t.Fatalf("runNewWithConfig() should only warn about a failed push, got: %v", err)
+		}
+		if !strings.Contains(stderr.String(), "Warning: failed to push offline to origin") {
+			t.Errorf("stderr should warn about the failed push, got:\n%s", stderr.String())
+		}
+		if exists, _ := gitx.BranchExists(context.Background(), "offline"); !exists {
+			t.Error("the branch should be created despite the failed push")
+		}
+	})
+}
+
+func TestRunNewFromStash(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	base := strings.TrimSpace(runGitForTest(t, repoPath, "rev-parse", "HEAD"))
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("README.md", "# Login work\n")
+	runGitForTest(t, repoPath, "stash", "push", "--quiet", "-m", "login work")
+	writeFile("notes.txt", "notes\n")
+	runGitForTest(t, repoPath, "stash", "push", "--quiet", "--include-untracked", "-m", "notes")
+	// HEAD moves on after stashing: the worktree must start at the stash's base
+	writeFile("later.txt", "later\n")
+	runGitForTest(t, repoPath, "add", "later.txt")
+	runGitForTest(t, repoPath, "commit", "--quiet", "-m", "Later commit")
+
+	runNew := func(t *testing.T, args []string, cfg newCmdConfig) (string, error) {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newNewCmd()
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetContext(context.Background())
+		cfg.baseDir = t.TempDir()
+		err := runNewWithConfig(cmd, args, &cfg)
+		return stdout.String(), err
+	}
+
+	t.Run("matching message, dropped", func(t *testing.T) {
+		stdout, err := runNew(t, []string{"resume-login"}, newCmdConfig{fromStash: "login", dropStash: true})
+		if err != nil {
+			t.Fatalf("runNewWithConfig() returned error: %v", err)
+		}
+		wt, err := gitx.FindWorktreeByBranch(context.Background(), "resume-login")
+		if err != nil || wt == nil {
+			t.Fatalf("worktree for resume-login not found: %v", err)
+		}
+		if wt.HEAD != base {
+			t.Errorf("worktree HEAD = %s, want the stash's base %s", wt.HEAD, base)
+		}
+		if content, _ := os.ReadFile(filepath.Join(wt.Path, "README.md")); string(content) != "# Login work\n" {
+			t.Errorf("README.md = %q, want the stashed change", content)
+		}
+		if list := runGitForTest(t, repoPath, "stash", "list"); strings.Contains(list, "login work") {
+			t.Errorf("stash entry should be dropped, stash list:\n%s", list)
+		}
+		if !strings.Contains(stdout, "Stash: stash@{1} applied and dropped") {
+			t.Errorf("stdout should report the stash, got:\n%s", stdout)
+		}
+	})
+
+	t.Run("apply fails", func(t *testing.T) {
+		// A post-checkout hook creates the stash's untracked file, so that the stash can't be applied
+		hook := filepath.Join(repoPath, ".git", "hooks", "post-checkout")
+		if err := os.WriteFile(hook, []byte("#!/bin/sh\necho hook > notes.txt\n"), 0755); err != nil {
+			t.Fatalf("Failed to write hook: %v", err)
+		}
+		t.Cleanup(func() { os.Remove(hook) })
+
+		_, err := runNew(t, []string{"resume-notes"}, newCmdConfig{fromStash: latestStash, dropStash: true})
+		if err == nil || !strings.Contains(err.Error(), "failed to apply stash@{0}") {
+			t.Fatalf("runNewWithConfig() error = %v, want a failed apply", err)
+		}
+		if wt, _ := gitx.FindWorktreeByBranch(context.Background(), "resume-notes"); wt != nil {
+			t.Errorf("half-made worktree was kept at %s", wt.Path)
+		}
+		if exists, _ := gitx.BranchExists(context.Background(), "resume-notes"); exists {
+			t.Error("branch resume-notes was kept")
+		}
+		if list := runGitForTest(t, repoPath, "stash", "list"); !strings.Contains(list, "notes") {
+			t.Errorf("stash entry should be kept, stash list:\n%s", list)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := runNew(t, []string{"resume-other"}, newCmdConfig{fromStash: "unknown"})
+		if !isError[*StashNotFoundError](err) {
+			t.Errorf("runNewWithConfig() error = %v, want StashNotFoundError", err)
+		}
+	})
+
+	t.Run("start point", func(t *testing.T) {
+		_, err := runNew(t, []string{"resume-other", "HEAD"}, newCmdConfig{fromStash: latestStash})
+		if !isError[*UsageError](err) {
+			t.Errorf("runNewWithConfig() error = %v, want UsageError", err)
+		}
+	})
+}
+
+func TestRunNewFrom(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	sourcePath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "--quiet", "-b", "feature", sourcePath)
+	if err := os.WriteFile(filepath.Join(sourcePath, "committed.txt"), []byte("committed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGitForTest(t, sourcePath, "add", "committed.txt")
+	runGitForTest(t, sourcePath, "commit", "--quiet", "-m", "Feature commit")
+	sourceHEAD := strings.TrimSpace(runGitForTest(t, sourcePath, "rev-parse", "HEAD"))
+	if err := os.WriteFile(filepath.Join(sourcePath, "README.md"), []byte("# Approach\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "untracked.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		branch        string
+		cfg           newCmdConfig
+		wantUntracked bool
+		wantOutput    string
+	}{
+		{name: "tracked changes", branch: "approach-a", cfg: newCmdConfig{from: "feature"}, wantOutput: "Carried over 1 changed file"},
+		{name: "with untracked files", branch: "approach-b", cfg: newCmdConfig{from: "feature", includeUntracked: true}, wantUntracked: true, wantOutput: "Carried over 2 changed files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			cmd := newNewCmd()
+			cmd.SetIn(strings.NewReader(""))
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetContext(context.Background())
+			tt.cfg.baseDir = t.TempDir()
+			if err := runNewWithConfig(cmd, []string{tt.branch}, &tt.cfg); err != nil {
+				t.Fatalf("runNewWithConfig() returned error: %v", err)
+			}
+
+			wt, err := gitx.FindWorktreeByBranch(context.Background(), tt.branch)
+			if err != nil || wt == nil {
+				t.Fatalf("worktree for %s not found: %v", tt.branch, err)
+			}
+			if wt.HEAD != sourceHEAD {
+				t.Errorf("worktree HEAD = %s, want the source's HEAD %s", wt.HEAD, sourceHEAD)
+			}
+			if content, _ := os.ReadFile(filepath.Join(wt.Path, "README.md")); string(content) != "# Approach\n" {
+				t.Errorf("README.md = %q, want the uncommitted change", content)
+			}
+			if _, err := os.Stat(filepath.Join(wt.Path, "untracked.txt")); (err == nil) != tt.wantUntracked {
+				t.Errorf("untracked.txt copied = %v, want %v", err == nil, tt.wantUntracked)
+			}
+			if !strings.Contains(stdout.String(), "From: feature ("+sourcePath+")") || !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("stdout should name the source and contain %q, got:\n%s", tt.wantOutput, stdout.String())
+			}
+		})
+	}
+
+	// The source keeps its changes
+	if content, _ := os.ReadFile(filepath.Join(sourcePath, "README.md")); string(content) != "# Approach\n" {
+		t.Errorf("source README.md = %q, want its changes kept", content)
+	}
+}
+
+func TestRunNewForce(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir()) // The trash
+	repoPath := setupTestRepo(t)
+	baseDir := t.TempDir()
+	orig := flagYes
+	flagYes = true
+	t.Cleanup(func() { flagYes = orig })
+
+	runNew := func(branch string, force bool) (*newResult, error) {
+		var stdout bytes.Buffer
+		cmd := newNewCmd()
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetContext(context.Background())
+		result, _, err := createNewWorktree(cmd.Context(), cmd, branch, "", &newCmdConfig{baseDir: baseDir, force: force, noRemoteCheck: true})
+		return result, err
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// An old worktree whose registration is gone is reused with its files
+	first, err := runNew("crashed", false)
+	if err != nil {
+		t.Fatalf("createNewWorktree() returned error: %v", err)
+	}
+	write(filepath.Join(first.Path, "work.txt"), "unsaved work\n")
+	if err := os.RemoveAll(filepath.Join(repoPath, ".git", "worktrees", filepath.Base(first.Path))); err != nil {
+		t.Fatalf("Failed to remove registration: %v", err)
+	}
+	result, err := runNew("crashed", true)
+	if err != nil {
+		t.Fatalf("createNewWorktree(--force) returned error: %v", err)
+	}
+	if result.Path != first.Path || !result.Reused {
+		t.Errorf("createNewWorktree(--force) = %+v, want the old path %s reused", result, first.Path)
+	}
+	if content, _ := os.ReadFile(filepath.Join(result.Path, "work.txt")); string(content) != "unsaved work\n" {
+		t.Errorf("work.txt = %q, want the old worktree's file kept", content)
+	}
+	if status := runGitForTest(t, result.Path, "status", "--porcelain"); strings.TrimSpace(status) != "?? work.txt" {
+		t.Errorf("git status = %q, want only the kept file", status)
+	}
+
+	// Without --force, another directory at the path gets a numbered path; with it, it goes to the trash
+	foreign := filepath.Join(filepath.Dir(first.Path), "leftover")
+	write(filepath.Join(foreign, "junk.txt"), "junk\n")
+	result, err = runNew("leftover", false)
+	if err != nil || result.Path != foreign+"-2" {
+		t.Fatalf("createNewWorktree() = %+v, %v, want %s-2", result, err, foreign)
+	}
+	runGitForTest(t, repoPath, "worktree", "remove", result.Path)
+	runGitForTest(t, repoPath, "branch", "-D", "leftover")
+	result, err = runNew("leftover", true)
+	if err != nil {
+		t.Fatalf("createNewWorktree(--force) returned error: %v", err)
+	}
+	if result.Path != foreign || result.Reused {
+		t.Errorf("createNewWorktree(--force) = %+v, want a new worktree at %s", result, foreign)
+	}
+	if _, err := os.Stat(filepath.Join(foreign, "junk.txt")); !os.IsNotExist(err) {
+		t.Errorf("junk.txt should have been moved to the trash with its directory")
+	}
+
+	// A branch registered to a worktree whose directory is gone is freed
+	gone := filepath.Join(t.TempDir(), "gone")
+	runGitForTest(t, repoPath, "worktree", "add", "--quiet", "-b", "gone", gone)
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatalf("Failed to remove worktree: %v", err)
+	}
+	if _, err := runNew("gone", true); err != nil {
+		t.Errorf("createNewWorktree(--force) for a prunable worktree's branch returned error: %v", err)
+	}
+
+	// A live worktree is never touched
+	_, err = runNew("crashed", true)
+	var inUse *BranchInUseError
+	if !errors.As(err, &inUse) {
+		t.Errorf("createNewWorktree(--force) for a live worktree's branch error = %v, want BranchInUseError", err)
+	}
 }
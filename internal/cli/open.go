@@ -1,16 +1,34 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/editor"
 	"github.com/toritori0318/git-wt/internal/gitx"
 )
 
+// OpenFailedError represents an error when some of the selected worktrees could not be opened
+type OpenFailedError struct {
+	Failed int
+	Total  int
+}
+
+func (e *OpenFailedError) Error() string {
+	return fmt.Sprintf("failed to open %d of %d worktrees", e.Failed, e.Total)
+}
+
 type openCmdConfig struct {
-	editor string
+	editor      string
+	wait        bool
+	reveal      bool
+	multi       bool
+	aheadBehind bool
+	match       matchOptions
 }
 
 func newOpenCmd() *cobra.Command {
@@ -24,23 +42,54 @@ func newOpenCmd() *cobra.Command {
 If query is not specified, select interactively.
 Editor is determined by the following priority:
   1. --editor flag
-  2. WT_EDITOR environment variable
-  3. VISUAL environment variable
-  4. EDITOR environment variable
-  5. code, idea, subl, vim, vi (in order of availability)
-  6. macOS: open, Linux: xdg-open
+  2. editor.command in the repository configuration file (.git/wt.yaml)
+  3. editor.repos.<repository> in the configuration file
+  4. editor.command (with editor.args) in the configuration file
+  5. WT_EDITOR environment variable
+  6. VISUAL environment variable
+  7. EDITOR environment variable
+  8. code, idea, subl, vim, vi (in order of availability; on Windows also the
+     VS Code and IntelliJ IDEA launchers in their default install locations)
+  9. macOS: open, Linux: xdg-open, Windows: start (the file association)
+
+Editor commands may include arguments, quoted like in a shell
+(e.g. --editor "emacsclient -n" or WT_EDITOR="code --new-window").
+
+GUI editors (editor.gui_editors: code, idea, idea64, subl, open and xdg-open by default) are
+started in the background and wt returns right away; use --wait to block until the
+editor is closed (code, idea, idea64 and subl get --wait, open gets -W). Terminal editors
+such as vim always run in the terminal until they exit.
+
+--multi selects several worktrees (narrowed down by the query, if given) and opens
+each of them; a query matching a single worktree opens it without asking.
+
+--reveal shows the worktree in the file manager instead (open on macOS, xdg-open
+on Linux, explorer on Windows).
 
 Examples:
   wt open                      # Select interactively and open with default editor
   wt open feature              # Open worktree containing "feature"
-  wt open --editor code main   # Open main with VS Code`,
-		Args: cobra.MaximumNArgs(1),
+  wt open --editor code main   # Open main with VS Code
+  wt open --editor "code -n"   # Open in a new VS Code window
+  wt open --wait feature       # Return only after the editor is closed
+  wt open --reveal feature     # Show the worktree in Finder/Explorer/the file manager
+  wt open --multi review       # Select several worktrees matching "review" and open each
+  wt open --ahead-behind       # Show commits ahead/behind upstream in the list`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
 		RunE: func(c *cobra.Command, args []string) error {
 			return runOpenWithConfig(c, args, cfg)
 		},
 	}
 
 	cmd.Flags().StringVar(&cfg.editor, "editor", "", "Specify editor to use")
+	cmd.Flags().BoolVar(&cfg.wait, "wait", false, "Wait until GUI editors are closed")
+	cmd.Flags().BoolVar(&cfg.reveal, "reveal", false, "Show the worktree in the system file manager instead of an editor")
+	cmd.Flags().BoolVar(&cfg.multi, "multi", false, "Select and open several worktrees")
+	cmd.Flags().BoolVar(&cfg.aheadBehind, "ahead-behind", false, "Show commits ahead/behind upstream for each worktree")
+	addSelectionFlags(cmd, &cfg.match)
+	cmd.MarkFlagsMutuallyExclusive("reveal", "editor")
+	cmd.MarkFlagsMutuallyExclusive("reveal", "wait")
 	return cmd
 }
 
@@ -64,45 +113,110 @@ func runOpenWithConfig(cmd *cobra.Command, args []string, cfg *openCmdConfig) er
 	if err != nil {
 		return fmt.Errorf("failed to get worktrees: %w", err)
 	}
+	worktrees = selectableWorktrees(worktrees)
 
 	if len(worktrees) == 0 {
 		return &NoWorktreesError{}
 	}
+	suggestRepair(ctx, cmd.ErrOrStderr(), worktrees)
 
 	// Create display items (reuse from go.go)
 	items := createDisplayItems(worktrees)
+	if cfg.aheadBehind {
+		addAheadBehind(ctx, worktrees, items)
+	}
 
-	// Select worktree
-	selectedIndex, err := selectWorktreeByQueryOrInteractive(items, query, "Select worktree to open")
+	// Select worktrees
+	prompt := "Select worktree to open"
+	if cfg.multi {
+		prompt = "Select worktrees to open"
+	}
+	selectedIndexes, err := selectItems(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), items, query, selectOptions{prompt: prompt, match: cfg.match, multi: cfg.multi})
 	if err != nil {
 		return err
 	}
 
-	// Selected worktree
-	selected := worktrees[selectedIndex]
+	paths := make([]string, len(selectedIndexes))
+	for i, idx := range selectedIndexes {
+		paths[i] = worktrees[idx].Path
+	}
+
+	w := cmd.OutOrStdout()
+	if cfg.reveal {
+		fm, err := editor.FindFileManager()
+		if err != nil {
+			return err
+		}
+		progress := newProgressPrinter(w, false, flagQuiet)
+		return openEach(cmd.ErrOrStderr(), paths, func(path string) error {
+			progress.Printf("Revealing %s with '%s'...\n", path, fm)
+			return editor.Reveal(path, fm)
+		})
+	}
 
 	// Find editor
-	editorPath, err := editor.FindEditor(cfg.editor)
+	repoName := ""
+	if repo, err := gitx.GetRepo(ctx, flagRepo); err == nil {
+		repoName = repo.Name
+	}
+	e, err := editor.FindEditor(editor.Command{Line: cfg.editor}, configuredEditor(ctx, repoName))
 	if err != nil {
 		return err
 	}
 
-	// Output message
-	printOpeningMessage(cmd.OutOrStdout(), selected.Path, editorPath, flagQuiet)
+	// Open in editor (using the resolved editor to avoid duplicate FindEditor call)
+	opts := editor.OpenOptions{GUIEditors: configuredGUIEditors(ctx), Wait: cfg.wait}
+	return openEach(cmd.ErrOrStderr(), paths, func(path string) error {
+		printOpeningMessage(w, path, e.String(), flagQuiet)
+		return editor.OpenWithEditor(path, e, opts)
+	})
+}
 
-	// Open in editor (using resolved path to avoid duplicate FindEditor call)
-	if err := editor.OpenWithPath(selected.Path, editorPath); err != nil {
-		return err
+// openEach calls open for every path, reporting failures to errW and continuing with the rest
+// A single path returns its error as is; otherwise failures are summarized in an OpenFailedError.
+func openEach(errW io.Writer, paths []string, open func(path string) error) error {
+	if len(paths) == 1 {
+		return open(paths[0])
 	}
 
+	failed := 0
+	for _, path := range paths {
+		if err := open(path); err != nil {
+			fmt.Fprintf(errW, "Failed to open %s: %v\n", path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &OpenFailedError{Failed: failed, Total: len(paths)}
+	}
 	return nil
 }
 
-func selectWorktreeByQueryOrInteractive(items []string, query string, prompt string) (int, error) {
-	if query != "" {
-		return selectByQuery(items, query)
+// configuredEditor returns the editor command from the configuration file
+// editor.command from the repository configuration file comes first, then the editor for repoName
+// in editor.repos, then editor.command and editor.args. An unreadable configuration yields an
+// empty command, so the environment variables still apply.
+func configuredEditor(ctx context.Context, repoName string) editor.Command {
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return editor.Command{}
+	}
+	if cfg.Source("editor.command") == config.SourceRepo {
+		return editor.Command{Line: cfg.GetEditorCommand(), Args: cfg.GetEditorArgs()}
+	}
+	if line := cfg.GetRepoEditor(repoName); line != "" {
+		return editor.Command{Line: line}
+	}
+	return editor.Command{Line: cfg.GetEditorCommand(), Args: cfg.GetEditorArgs()}
+}
+
+// configuredGUIEditors returns the editors that are started without waiting for them
+func configuredGUIEditors(ctx context.Context) []string {
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return strings.Fields(config.DefaultEditorGUIEditors)
 	}
-	return selectWorktree(items, prompt)
+	return cfg.GetEditorGUIEditors()
 }
 
 func printOpeningMessage(w io.Writer, path, editorPath string, quiet bool) {
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunOpenQuiet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses 'true' as the editor")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", filepath.Join(t.TempDir(), "feature"))
+
+	run := func() string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newOpenCmd()
+		cmd.SetContext(context.Background())
+		cmd.SetIn(strings.NewReader(""))
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		if err := runOpenWithConfig(cmd, []string{"feature"}, &openCmdConfig{editor: "true"}); err != nil {
+			t.Fatalf("runOpenWithConfig() returned error: %v", err)
+		}
+		return stdout.String()
+	}
+
+	if out := run(); !strings.Contains(out, "Opening ") {
+		t.Errorf("stdout = %q, want the opening message", out)
+	}
+	quietForTest(t)
+	if out := run(); out != "" {
+		t.Errorf("stdout with --quiet = %q, want nothing", out)
+	}
+}
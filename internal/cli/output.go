@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/archive"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// jsonAnnotation marks the commands that support the global --json flag
+const jsonAnnotation = "wt_json"
+
+// JSONUnsupportedError represents an error when a command has no JSON output
+type JSONUnsupportedError struct {
+	Command string
+}
+
+func (e *JSONUnsupportedError) Error() string {
+	return fmt.Sprintf("%s is unsupported with --json", e.Command)
+}
+
+// reportedError wraps an error whose details are already part of the JSON output (e.g. the
+// failed checks of wt doctor); Execute exits non-zero without writing an error document
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string { return e.err.Error() }
+func (e *reportedError) Unwrap() error { return e.err }
+
+// withJSON marks cmd as supporting --json and returns it
+func withJSON(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[jsonAnnotation] = "true"
+	return cmd
+}
+
+// supportsJSON reports whether cmd supports --json
+func supportsJSON(cmd *cobra.Command) bool {
+	return cmd.Annotations[jsonAnnotation] == "true"
+}
+
+// jsonOutput reports whether output should be a single JSON document (--json)
+func jsonOutput() bool {
+	return flagJSON
+}
+
+// writeJSON writes v as indented JSON followed by a newline
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// worktreeJSON is the JSON form of a worktree
+type worktreeJSON struct {
+	Path        string `json:"path"`
+	Branch      string `json:"branch"`
+	HEAD        string `json:"head"`
+	Detached    bool   `json:"detached"`
+	Bare        bool   `json:"bare"`
+	Locked      bool   `json:"locked"`
+	LockReason  string `json:"lock_reason,omitempty"`
+	Prunable    bool   `json:"prunable"`
+	PruneReason string `json:"prune_reason,omitempty"`
+	Index       int    `json:"index"` // As shown in the selection lists and accepted by --index
+}
+
+func newWorktreeJSON(wt gitx.Worktree) worktreeJSON {
+	return worktreeJSON{
+		Path:        wt.Path,
+		Branch:      wt.Branch,
+		HEAD:        wt.HEAD,
+		Detached:    wt.IsDetached,
+		Bare:        wt.IsBare,
+		Locked:      wt.IsLocked,
+		LockReason:  wt.LockReason,
+		Prunable:    wt.IsPrunable,
+		PruneReason: wt.PruneReason,
+		Index:       wt.Index,
+	}
+}
+
+// jsonErrorDocument is written to stdout instead of the error message when --json is set
+type jsonErrorDocument struct {
+	Error jsonErrorBody `json:"error"`
+}
+
+type jsonErrorBody struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// isError reports whether err has an error of type T in its chain
+func isError[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
+
+// errorTypes maps typed errors to the stable "type" of JSON error output, most specific first
+var errorTypes = []struct {
+	name  string
+	match func(error) bool
+}{
+	{"json_unsupported", isError[*JSONUnsupportedError]},
+	{"usage_error", isError[*UsageError]},
+	{"non_interactive", isError[*NonInteractiveError]},
+	{"nothing_to_undo", isError[*NothingToUndoError]},
+	{"repo_mismatch", isError[*RepoMismatchError]},
+	{"selection_cancelled", isError[*selectx.CancelledError]},
+	{"branch_in_use", isError[*BranchInUseError]},
+	{"no_worktrees", isError[*NoWorktreesError]},
+	{"index_out_of_range", isError[*IndexOutOfRangeError]},
+	{"no_match", isError[*NoMatchError]},
+	{"stash_not_found", isError[*StashNotFoundError]},
+	{"workspace_not_found", isError[*WorkspaceNotFoundError]},
+	{"ambiguous_match", isError[*AmbiguousMatchError]},
+	{"not_in_worktree", isError[*NotInWorktreeError]},
+	{"no_removable_worktrees", isError[*NoRemovableWorktreesError]},
+	{"no_trashed_worktrees", isError[*NoTrashedWorktreesError]},
+	{"removal_cancelled", isError[*WorktreeRemovalCancelledError]},
+	{"operation_cancelled", isError[*OperationCancelledError]},
+	{"worktree_locked", isError[*WorktreeLockedError]},
+	{"destination_exists", isError[*DestinationExistsError]},
+	{"already_at_destination", isError[*AlreadyAtDestinationError]},
+	{"no_lock_candidates", isError[*NoLockCandidatesError]},
+	{"main_worktree_lock", isError[*MainWorktreeLockError]},
+	{"invalid_function_name", isError[*InvalidFunctionNameError]},
+	{"unsupported_shell", isError[*UnsupportedShellError]},
+	{"shell_function_not_configured", isError[*ShellFunctionNotConfiguredError]},
+	{"open_failed", isError[*OpenFailedError]},
+	{"doctor_failed", isError[*DoctorFailedError]},
+	{"each_failed", isError[*EachFailedError]},
+	{"sync_failed", isError[*SyncFailedError]},
+	{"workspace_failed", isError[*WorkspaceFailedError]},
+	{"no_changes", isError[*NoChangesError]},
+	{"changes_conflict", isError[*ChangesConflictError]},
+	{"archive_exists", isError[*archive.ExistsError]},
+	{"not_same_repository", isError[*NotSameRepositoryError]},
+	{"checkout_dirty", isError[*CheckoutDirtyError]},
+	{"branch_diverged", isError[*BranchDivergedError]},
+	{"gh_not_found", isError[*GhNotFoundError]},
+	{"tmux_not_found", isError[*TmuxNotFoundError]},
+	{"git_not_found", isError[*gitx.GitNotFoundError]},
+	{"git_too_old", isError[*gitx.MinVersionError]},
+	{"invalid_pr_number", isError[*InvalidPRNumberError]},
+	{"path_collision", isError[*naming.CollisionError]},
+	{"worktree_dir_unusable", isError[*WorktreeDirError]},
+	{"unknown_config_key", isError[*config.UnknownKeyError]},
+	{"invalid_config", isError[*config.ValidationError]},
+	{"git_timeout", isError[*gitx.TimeoutError]},
+	{"not_a_repository", isError[*NotRepositoryError]},
+	{"not_a_repository", gitx.IsNotRepository},
+	{"git_error", isError[*gitx.GitError]},
+	{"exit_status", isError[*ExitCodeError]},
+}
+
+// isCancellation reports whether err is the user cancelling a selection or declining to go on
+func isCancellation(err error) bool {
+	return isError[*selectx.CancelledError](err) || isError[*OperationCancelledError](err)
+}
+
+// errorType returns the stable "type" of err in JSON error output ("error" if untyped)
+func errorType(err error) string {
+	for _, t := range errorTypes {
+		if t.match(err) {
+			return t.name
+		}
+	}
+	return "error"
+}
+
+// printError reports err on stderr, or as a JSON error document on stdout with --json
+func printError(stdout, stderr io.Writer, err error) {
+	if isError[*reportedError](err) {
+		return
+	}
+	if !jsonOutput() {
+		if isCancellation(err) {
+			// Not a failure: the user knows, and scripts have the exit code
+			if !flagQuiet {
+				fmt.Fprintln(stderr, "Cancelled")
+			}
+			return
+		}
+		fmt.Fprintln(stderr, err)
+		return
+	}
+	doc := jsonErrorDocument{Error: jsonErrorBody{Type: errorType(err), Message: err.Error()}}
+	if writeErr := writeJSON(stdout, doc); writeErr != nil {
+		fmt.Fprintln(stderr, err)
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "typed", err: &NoMatchError{Query: "x"}, want: "no_match"},
+		{name: "wrapped", err: fmt.Errorf("failed: %w", &BranchInUseError{Branch: "b", Path: "/p"}), want: "branch_in_use"},
+		{name: "cancelled selection", err: &ExitCodeError{Code: ExitCancelled, Err: &selectx.CancelledError{}}, want: "selection_cancelled"},
+		{name: "unsupported", err: &JSONUnsupportedError{Command: "wt tmux"}, want: "json_unsupported"},
+		{name: "untyped", err: fmt.Errorf("boom"), want: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorType(tt.err); got != tt.want {
+				t.Errorf("errorType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	orig := flagJSON
+	t.Cleanup(func() { flagJSON = orig })
+
+	t.Run("text", func(t *testing.T) {
+		flagJSON = false
+		var stdout, stderr bytes.Buffer
+		printError(&stdout, &stderr, &NoWorktreesError{})
+		if stdout.Len() != 0 || stderr.String() != (&NoWorktreesError{}).Error()+"\n" {
+			t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		flagJSON = true
+		var stdout, stderr bytes.Buffer
+		printError(&stdout, &stderr, &NoWorktreesError{})
+		if stderr.Len() != 0 {
+			t.Errorf("stderr = %q, want empty", stderr.String())
+		}
+
+		var got jsonErrorDocument
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+		}
+		want := jsonErrorBody{Type: "no_worktrees", Message: (&NoWorktreesError{}).Error()}
+		if got.Error != want {
+			t.Errorf("error = %+v, want %+v", got.Error, want)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		flagJSON = false
+		origQuiet := flagQuiet
+		t.Cleanup(func() { flagQuiet = origQuiet })
+		for _, err := range []error{&selectx.CancelledError{}, fmt.Errorf("failed: %w", &OperationCancelledError{})} {
+			var stdout, stderr bytes.Buffer
+			flagQuiet = false
+			printError(&stdout, &stderr, err)
+			if stdout.Len() != 0 || stderr.String() != "Cancelled\n" {
+				t.Errorf("printError(%v): stdout = %q, stderr = %q, want a single Cancelled line", err, stdout.String(), stderr.String())
+			}
+
+			stderr.Reset()
+			flagQuiet = true
+			printError(&stdout, &stderr, err)
+			if stderr.Len() != 0 {
+				t.Errorf("printError(%v) with --quiet: stderr = %q, want nothing", err, stderr.String())
+			}
+		}
+	})
+
+	t.Run("already reported", func(t *testing.T) {
+		flagJSON = true
+		var stdout, stderr bytes.Buffer
+		printError(&stdout, &stderr, &reportedError{err: &DoctorFailedError{Failures: 1}})
+		if stdout.Len() != 0 || stderr.Len() != 0 {
+			t.Errorf("stdout = %q, stderr = %q, want nothing", stdout.String(), stderr.String())
+		}
+	})
+}
+
+func TestSupportsJSON(t *testing.T) {
+	for _, cmd := range []*cobra.Command{newCmd, goCmd, cleanCmd, newDoctorCmd(), newConfigListCmd(), newConfigGetCmd()} {
+		if !supportsJSON(cmd) {
+			t.Errorf("%s should support --json", cmd.Name())
+		}
+	}
+	for _, cmd := range []*cobra.Command{newOpenCmd(), newConfigSetCmd()} {
+		if supportsJSON(cmd) {
+			t.Errorf("%s should not support --json", cmd.Name())
+		}
+	}
+}
+
+func TestRunListJSONRejectsOtherCommands(t *testing.T) {
+	err := runListJSON(&cobra.Command{}, []string{"prune"})
+	if errorType(err) != "json_unsupported" {
+		t.Errorf("runListJSON(prune) = %v, want JSONUnsupportedError", err)
+	}
+}
+
+func TestHasJSONFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"list", "--json"}, want: true},
+		{args: []string{"--json", "prune"}, want: true},
+		{args: []string{"list"}, want: false},
+		{args: []string{"add", "--", "--json"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := hasJSONFlag(tt.args); got != tt.want {
+			t.Errorf("hasJSONFlag(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+// quietForTest runs the rest of the test with --quiet
+func quietForTest(t *testing.T) {
+	t.Helper()
+	orig := flagQuiet
+	flagQuiet = true
+	t.Cleanup(func() { flagQuiet = orig })
+}
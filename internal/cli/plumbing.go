@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// plumbingAnnotation marks commands meant for command substitution in scripts:
+// they never prompt and write nothing to stderr unless --debug is set
+const plumbingAnnotation = "wt_plumbing"
+
+// AmbiguousMatchError represents an error when a query matches several worktrees and prompting isn't allowed
+type AmbiguousMatchError struct {
+	Query    string
+	Branches []string
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("query %q matches %d worktrees: %s", e.Query, len(e.Branches), strings.Join(e.Branches, ", "))
+}
+
+type pathCmdConfig struct {
+	branchExact bool
+	match       matchOptions
+}
+
+// asPlumbing marks cmd as a plumbing command and returns it
+func asPlumbing(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[plumbingAnnotation] = "true"
+	return cmd
+}
+
+// isPlumbing reports whether cmd is a plumbing command
+func isPlumbing(cmd *cobra.Command) bool {
+	return cmd != nil && cmd.Annotations[plumbingAnnotation] == "true"
+}
+
+func newRootPathCmd() *cobra.Command {
+	return asPlumbing(&cobra.Command{
+		Use:   "root",
+		Short: "Print the main repository root",
+		Long: `Print the root of the main worktree and nothing else.
+
+Nothing is written to stderr (unless --debug); failures are reported by the exit code
+only, so that it is safe in command substitutions.
+
+Examples:
+  cd "$(wt root)"`,
+		Args: cobra.NoArgs,
+		RunE: runRootPath,
+	})
+}
+
+func newPathCmd() *cobra.Command {
+	cfg := &pathCmdConfig{}
+
+	cmd := asPlumbing(&cobra.Command{
+		Use:   "path <query>",
+		Short: "Print the path of the worktree matching a query",
+		Long: `Print the path of the worktree matching query and nothing else.
+
+Unlike wt go, wt path never prompts: if no worktree or several worktrees match, it
+exits with code 4. A worktree whose branch equals the query wins over other matches.
+Nothing is written to stderr (unless --debug), so that it is safe in command
+substitutions. With --null (-z), the path is terminated by a NUL byte instead of
+a newline, so that any path survives a pipeline.
+
+Examples:
+  cd "$(wt path feature)"
+  wt path -z feature | xargs -0 ls
+  wt path --branch-exact main`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runPathWithConfig(c, args, cfg)
+		},
+	})
+
+	cmd.Flags().BoolVar(&cfg.branchExact, "branch-exact", false, "Only match the worktree whose branch is exactly the query")
+	addMatchFlags(cmd, &cfg.match)
+
+	return cmd
+}
+
+var (
+	rootPathCmd = newRootPathCmd()
+	pathCmd     = newPathCmd()
+)
+
+func init() {
+	rootCmd.AddCommand(rootPathCmd)
+	rootCmd.AddCommand(pathCmd)
+}
+
+func runRootPath(cmd *cobra.Command, args []string) error {
+	repo, err := gitx.GetRepo(cmd.Context(), flagRepo)
+	if err != nil {
+		if gitx.IsNotRepository(err) {
+			return &NotRepositoryError{}
+		}
+		return fmt.Errorf("failed to get repository information: %w", err)
+	}
+	printPath(cmd.OutOrStdout(), repo.Root)
+	return nil
+}
+
+func runPathWithConfig(cmd *cobra.Command, args []string, cfg *pathCmdConfig) error {
+	wt, err := findWorktreeForPath(cmd.Context(), args[0], cfg)
+	if err != nil {
+		return err
+	}
+	printPath(cmd.OutOrStdout(), wt.Path)
+	return nil
+}
+
+// findWorktreeForPath returns the single worktree matching query, without ever prompting
+func findWorktreeForPath(ctx context.Context, query string, cfg *pathCmdConfig) (gitx.Worktree, error) {
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return gitx.Worktree{}, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	worktrees := selectableWorktrees(all)
+
+	// A branch equal to the query is unambiguous
+	for _, wt := range worktrees {
+		if wt.Branch == query {
+			return wt, nil
+		}
+	}
+	items := createDisplayItems(worktrees)
+	if cfg.branchExact {
+		return gitx.Worktree{}, newNoMatchError(query, items)
+	}
+
+	filtered, err := selectx.FilterIndexedByQuery(items, query, cfg.match.useFuzzy())
+	if err != nil {
+		return gitx.Worktree{}, newNoMatchError(query, items)
+	}
+	if len(filtered) > 1 {
+		branches := make([]string, len(filtered))
+		for i, f := range filtered {
+			branches[i] = formatBranch(worktrees[f.Index])
+		}
+		return gitx.Worktree{}, &AmbiguousMatchError{Query: query, Branches: branches}
+	}
+	return worktrees[filtered[0].Index], nil
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestFindWorktreeForPath(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	loginPath := filepath.Join(t.TempDir(), "login")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", loginPath)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/logout", filepath.Join(t.TempDir(), "logout"))
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "main-fix", filepath.Join(t.TempDir(), "main-fix"))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		cfg      pathCmdConfig
+		wantPath string
+		wantCode int
+	}{
+		{name: "single match", query: "login", wantPath: loginPath},
+		{name: "exact branch wins", query: "main", wantPath: repoPath},
+		{name: "ambiguous", query: "feature", wantCode: ExitNotFound},
+		{name: "no match", query: "nothing", wantCode: ExitNotFound},
+		{name: "branch exact", query: "feature/login", cfg: pathCmdConfig{branchExact: true}, wantPath: loginPath},
+		{name: "branch exact without partial match", query: "login", cfg: pathCmdConfig{branchExact: true}, wantCode: ExitNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt, err := findWorktreeForPath(ctx, tt.query, &tt.cfg)
+			if tt.wantCode != 0 {
+				if err == nil || exitCode(err) != tt.wantCode {
+					t.Fatalf("findWorktreeForPath(%q) error = %v, want exit code %d", tt.query, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findWorktreeForPath(%q) returned error: %v", tt.query, err)
+			}
+			if wt.Path != tt.wantPath {
+				t.Errorf("findWorktreeForPath(%q) = %s, want %s", tt.query, wt.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestPlumbingErrorsAreSilent(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/a", filepath.Join(t.TempDir(), "a"))
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/b", filepath.Join(t.TempDir(), "b"))
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs([]string{"path", "feature"})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := Execute()
+	if exitCode(err) != ExitNotFound {
+		t.Errorf("Execute() error = %v, want exit code %d", err, ExitNotFound)
+	}
+	if stdout.Len() != 0 || stderr.Len() != 0 {
+		t.Errorf("output = %q, %q, want nothing", stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	rootCmd.SetArgs([]string{"root"})
+	if err := Execute(); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if got := strings.TrimSpace(stdout.String()); filepath.Base(got) != "test-repo" {
+		t.Errorf("wt root = %q, want the repository root", got)
+	}
+}
+
+func TestRootIgnoresGitDirEnv(t *testing.T) {
+	otherPath := setupTestRepo(t)
+	repoPath := setupTestRepo(t)
+	// A git hook or an IDE pointing git at an unrelated repository
+	t.Setenv("GIT_DIR", filepath.Join(otherPath, ".git"))
+
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		flagRespectGitEnv = false
+		gitx.RespectGitEnv = false
+	})
+
+	rootCmd.SetArgs([]string{"root"})
+	if err := Execute(); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if got := strings.TrimSpace(stdout.String()); !gitx.SamePath(got, repoPath) {
+		t.Errorf("wt root = %q, want the repository of the current directory %q", got, repoPath)
+	}
+
+	stdout.Reset()
+	rootCmd.SetArgs([]string{"--respect-git-env", "root"})
+	if err := Execute(); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if got := strings.TrimSpace(stdout.String()); !gitx.SamePath(got, otherPath) {
+		t.Errorf("wt --respect-git-env root = %q, want the repository of GIT_DIR %q", got, otherPath)
+	}
+}
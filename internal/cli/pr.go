@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strconv"
@@ -8,7 +9,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/toritori0318/git-wt/internal/ghx"
 	"github.com/toritori0318/git-wt/internal/gitx"
-	"github.com/toritori0318/git-wt/internal/naming"
 )
 
 // GhNotFoundError represents an error when GitHub CLI is not found
@@ -27,11 +27,19 @@ func (e *InvalidPRNumberError) Error() string {
 	return fmt.Sprintf("invalid PR number: %s", e.Input)
 }
 
+// OperationCancelledError represents an error when the user declines to go on at a prompt
+type OperationCancelledError struct{}
+
+func (e *OperationCancelledError) Error() string {
+	return "operation cancelled"
+}
+
 type prCmdConfig struct {
 	branch string
 	remote string
 	cd     bool
 	force  bool
+	setup  setupOptions
 }
 
 func newPrCmd() *cobra.Command {
@@ -47,13 +55,15 @@ Supports PRs from forks.
 
 Branch Naming:
   By default, uses the PR's original branch name (e.g., feature/auth).
+  A name given with --branch is checked like a new branch name before
+  anything is fetched, and gets the same handling of existing branches.
 
 Existing Branch Handling:
   - If branch exists in a worktree:
     - Without --cd: Shows info and exits
     - With --cd: Prompts to navigate to existing worktree
   - If branch exists locally (not in worktree): Prompts to create worktree with existing branch
-  - Use --force to skip all prompts
+  - Use --force (or the global --yes) to skip all prompts
 
 Prerequisites:
   - GitHub CLI (gh) must be installed
@@ -74,6 +84,8 @@ Examples:
 	cmd.Flags().StringVar(&cfg.remote, "remote", "", "Remote name (default: auto-detect)")
 	cmd.Flags().BoolVar(&cfg.cd, "cd", false, "Output only worktree path (for shell function)")
 	cmd.Flags().BoolVar(&cfg.force, "force", false, "Skip all prompts and use existing branches")
+	addSetupFlags(cmd, &cfg.setup)
+	_ = cmd.RegisterFlagCompletionFunc("branch", completePRBranches)
 
 	return cmd
 }
@@ -88,6 +100,7 @@ func init() {
 func runPRWithConfig(cmd *cobra.Command, args []string, cfg *prCmdConfig) error {
 	ctx := cmd.Context()
 	w := cmd.OutOrStdout()
+	progress := newProgressPrinter(w, cfg.cd, flagQuiet)
 
 	// Check if shell function is configured when using --cd
 	if err := checkShellFunction(cfg.cd); err != nil {
@@ -100,6 +113,13 @@ func runPRWithConfig(cmd *cobra.Command, args []string, cfg *prCmdConfig) error
 		return err
 	}
 
+	// A custom branch name can be validated before anything is fetched
+	if cfg.branch != "" {
+		if err := validateBranchName(cfg.branch); err != nil {
+			return &UsageError{Err: fmt.Errorf("--branch: %w", err)}
+		}
+	}
+
 	// Check GitHub CLI
 	if !ghx.IsGhAvailable() {
 		return &GhNotFoundError{}
@@ -111,115 +131,160 @@ func runPRWithConfig(cmd *cobra.Command, args []string, cfg *prCmdConfig) error
 		return fmt.Errorf("failed to get repository info: %w", err)
 	}
 
-	// Fetch PR info
-	printPRProgress(w, "Fetching PR #%d info...\n", prNumber, cfg.cd, flagQuiet)
-	prInfo, err := ghx.GetPRInfo(prNumber)
+	wt, err := createPRWorktree(cmd, repo, prNumber, cfg, progress)
 	if err != nil {
-		return fmt.Errorf("failed to get PR info: %w", err)
-	}
-
-	printPRInfo(w, prInfo, cfg.cd, flagQuiet)
-
-	// Determine local branch name
-	localBranch := cfg.branch
-	if localBranch == "" {
-		localBranch = prInfo.HeadRefName
-	}
-
-	// Validate branch name
-	if err := validateBranchName(localBranch); err != nil {
-		return fmt.Errorf("invalid branch name '%s': %w", localBranch, err)
-	}
-
-	// Check if branch is already in use by a worktree
-	existingWT, err := gitx.FindWorktreeByBranch(ctx, localBranch)
-	if err != nil {
-		return fmt.Errorf("failed to search worktrees: %w", err)
+		return err
 	}
 
-	if existingWT != nil {
+	if wt.Existing {
 		// Branch is in use by worktree
 		if cfg.cd {
 			// With --cd: prompt to navigate (or auto-navigate with --force)
-			if cfg.force {
+			if cfg.force || flagYes {
 				// Force mode: auto-navigate without prompt
-				fmt.Fprintln(w, existingWT.Path)
+				printCdPath(w, wt.Path, wt.Branch)
 				return nil
 			}
+			// Not on stdout: with --cd, it may only contain the path
 			if !flagQuiet {
-				fmt.Fprintf(w, "Branch '%s' is already in use by worktree.\n", localBranch)
+				fmt.Fprintf(cmd.ErrOrStderr(), "Branch '%s' is already in use by worktree.\n", wt.Branch)
 			}
-			if confirmed, err := confirmNavigate(w, localBranch, existingWT.Path); err != nil {
+			if confirmed, err := confirmNavigate(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), wt.Branch, wt.Path); err != nil {
 				return err
 			} else if confirmed {
 				// User wants to navigate - output path for shell function
-				fmt.Fprintln(w, existingWT.Path)
+				printCdPath(w, wt.Path, wt.Branch)
 				return nil
 			}
 			// User declined navigation
-			return fmt.Errorf("operation cancelled")
+			return &OperationCancelledError{}
 		}
-		// Without --cd: show info and exit
-		fmt.Fprintf(w, "Branch '%s' is already in use by worktree: %s\n", localBranch, existingWT.Path)
-		fmt.Fprintf(w, "Path: %s\n", existingWT.Path)
+		// Without --cd: show info and exit (only the path with --quiet)
+		if flagQuiet {
+			printPath(w, wt.Path)
+			return nil
+		}
+		fmt.Fprintf(w, "Branch '%s' is already in use by worktree: %s\n", wt.Branch, wt.Path)
+		fmt.Fprintf(w, "Path: %s\n", wt.Path)
+		fmt.Fprintf(w, "Navigate: %s\n", navigateCommand(wt.Branch, wt.Path, shellFunctionActive()))
 		return nil
 	}
 
+	// Output result
+	printPRSuccess(progress, wt.Path, prNumber, wt.Branch)
+
+	return nil
+}
+
+// prWorktree is the worktree of a PR branch
+type prWorktree struct {
+	Path     string
+	Branch   string
+	Existing bool // The branch was already checked out in this worktree: nothing was created
+}
+
+// createPRWorktree fetches the branch of PR prNumber and creates a worktree for it (shared by pr and tmux pr)
+// If the branch is already checked out in a worktree, that worktree is returned with Existing set.
+// A local branch of the same name is used after confirmation (or with cfg.force).
+func createPRWorktree(cmd *cobra.Command, repo *gitx.Repo, prNumber int, cfg *prCmdConfig, progress *progressPrinter) (*prWorktree, error) {
+	ctx := cmd.Context()
+
+	// Fetch PR info
+	progress.Printf("Fetching PR #%d info...\n", prNumber)
+	prInfo, err := ghx.GetPRInfo(ctx, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR info: %w", err)
+	}
+
+	printPRInfo(progress, prInfo)
+
+	// Determine local branch name
+	localBranch := cfg.branch
+	if localBranch == "" {
+		localBranch = prInfo.HeadRefName
+	}
+
+	// Validate the PR's branch name (a custom one was validated by the caller)
+	if err := validateBranchName(localBranch); err != nil {
+		return nil, fmt.Errorf("invalid branch name '%s': %w", localBranch, err)
+	}
+
+	// Check if branch is already in use by a worktree
+	existingWT, err := gitx.FindWorktreeByBranch(ctx, localBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search worktrees: %w", err)
+	}
+	if existingWT != nil {
+		return &prWorktree{Path: existingWT.Path, Branch: localBranch, Existing: true}, nil
+	}
+
 	// Check if branch already exists locally
 	branchExists, err := gitx.BranchExists(ctx, localBranch)
 	if err != nil {
-		return fmt.Errorf("failed to check if branch exists: %w", err)
+		return nil, fmt.Errorf("failed to check if branch exists: %w", err)
 	}
 
 	if branchExists {
 		// Branch exists but not in worktree - prompt to use it (or auto-use with --force)
-		if !cfg.force {
-			if confirmed, err := confirmUseExisting(w, localBranch, cfg.cd, flagQuiet); err != nil {
-				return err
+		if !cfg.force && !flagYes {
+			if confirmed, err := confirmUseExisting(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), localBranch, cfg.cd, flagQuiet); err != nil {
+				return nil, err
 			} else if !confirmed {
-				return fmt.Errorf("operation cancelled")
+				return nil, &OperationCancelledError{}
 			}
 		}
 		// User confirmed or force mode - will use existing branch for worktree
 	}
 
 	// Determine remote and setup temporary remote if needed
-	remote, tempRemote, err := determineRemote(w, cfg.remote, prInfo, prNumber, cfg.cd, flagQuiet)
+	remote, tempRemote, err := determineRemote(ctx, progress, cfg.remote, prInfo, prNumber)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Ensure temporary remote cleanup
 	if tempRemote != "" {
 		defer func() {
-			printPRProgress(w, "Removing temporary remote: %s\n", tempRemote, cfg.cd, flagQuiet)
-			_ = ghx.RemoveRemote(tempRemote) // Ignore error: cleanup is best-effort
+			progress.Printf("Removing temporary remote: %s\n", tempRemote)
+			_ = ghx.RemoveRemote(ctx, tempRemote) // Ignore error: cleanup is best-effort
 		}()
 	}
 
 	// Fetch branch
-	printPRProgress(w, "Fetching branch: %s/%s -> %s\n", remote, prInfo.HeadRefName, localBranch, cfg.cd, flagQuiet)
-	if err := ghx.FetchPRBranch(remote, prInfo.HeadRefName, localBranch); err != nil {
-		return fmt.Errorf("failed to fetch PR branch: %w", err)
+	progress.Printf("Fetching branch: %s/%s -> %s\n", remote, prInfo.HeadRefName, localBranch)
+	if err := ghx.FetchPRBranch(ctx, remote, prInfo.HeadRefName, localBranch); err != nil {
+		return nil, fmt.Errorf("failed to fetch PR branch: %w", err)
+	}
+
+	baseDir, err := resolveAndValidateBaseDir(ctx, "", repo.Parent)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate worktree path
-	sanitized := naming.Sanitize(fmt.Sprintf("pr-%d-%s", prNumber, prInfo.HeadRefName))
-	worktreePath, err := naming.GenerateWorktreePath(repo.Parent, repo.Name, sanitized)
+	worktreePath, err := generateWorktreePath(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), baseDir, repo.Name, fmt.Sprintf("pr-%d-%s", prNumber, prInfo.HeadRefName), "")
 	if err != nil {
-		return fmt.Errorf("failed to generate worktree path: %w", err)
+		return nil, fmt.Errorf("failed to generate worktree path: %w", err)
 	}
 
 	// Create worktree
-	printPRProgress(w, "Creating worktree: %s\n", worktreePath, cfg.cd, flagQuiet)
-	if err := gitx.Add(ctx, worktreePath, localBranch, "", false); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	progress.Printf("Creating worktree: %s\n", worktreePath)
+	if err := prepareWorktreeParent(ctx, worktreePath); err != nil {
+		return nil, err
+	}
+	if err := gitx.AddWithProgress(ctx, worktreePath, localBranch, "", false, worktreeAddProgress(cmd, cfg.cd)); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
 	}
 
-	// Output result
-	printPRSuccess(w, worktreePath, prNumber, localBranch, cfg.cd, flagQuiet)
+	// Remember the PR for wt info (best-effort)
+	_ = gitx.SetBranchPR(ctx, localBranch, prNumber)
 
-	return nil
+	// Initialize submodules and LFS objects
+	if err := setupWorktree(ctx, cmd.ErrOrStderr(), worktreePath, cfg.setup); err != nil {
+		return nil, err
+	}
+
+	return &prWorktree{Path: worktreePath, Branch: localBranch}, nil
 }
 
 func validatePRNumber(input string) (int, error) {
@@ -234,33 +299,32 @@ func validatePRNumber(input string) (int, error) {
 }
 
 // confirmNavigate asks user if they want to navigate to an existing worktree
-func confirmNavigate(w io.Writer, branch, path string) (bool, error) {
-	confirmed := confirm("Navigate to existing worktree?")
-	return confirmed, nil
+// The prompt goes to w, which must not be stdout: the path is printed there for the shell function.
+func confirmNavigate(ctx context.Context, r io.Reader, w io.Writer, branch, path string) (bool, error) {
+	return confirmWith(ctx, r, w, "Navigate to existing worktree?", "--force or --yes")
 }
 
 // confirmUseExisting asks user if they want to use an existing branch for new worktree
-func confirmUseExisting(w io.Writer, branch string, cdMode, quiet bool) (bool, error) {
+func confirmUseExisting(ctx context.Context, r io.Reader, w io.Writer, branch string, cdMode, quiet bool) (bool, error) {
 	if cdMode || quiet {
 		// In cd or quiet mode, assume yes
 		return true, nil
 	}
 	fmt.Fprintf(w, "Branch '%s' already exists locally.\n", branch)
-	confirmed := confirm("Create new worktree using existing branch?")
-	return confirmed, nil
+	return confirmWith(ctx, r, w, "Create new worktree using existing branch?", "--force or --yes")
 }
 
-func determineRemote(w io.Writer, userRemote string, prInfo *ghx.PRInfo, prNumber int, cdMode, quiet bool) (remote, tempRemote string, err error) {
+func determineRemote(ctx context.Context, progress *progressPrinter, userRemote string, prInfo *ghx.PRInfo, prNumber int) (remote, tempRemote string, err error) {
 	if userRemote != "" {
 		return userRemote, "", nil
 	}
 
 	if prInfo.IsCrossRepository {
 		// For fork PRs, add temporary remote if needed
-		if !ghx.RemoteExists(prInfo.HeadOwner) {
+		if !ghx.RemoteExists(ctx, prInfo.HeadOwner) {
 			tempRemote = fmt.Sprintf("wt-pr-%d", prNumber)
-			printPRProgress(w, "Adding temporary remote: %s (%s/%s)\n", tempRemote, prInfo.HeadOwner, prInfo.HeadRepo, cdMode, quiet)
-			if err := ghx.AddRemote(tempRemote, prInfo.HeadOwner, prInfo.HeadRepo); err != nil {
+			progress.Printf("Adding temporary remote: %s (%s/%s)\n", tempRemote, prInfo.HeadOwner, prInfo.HeadRepo)
+			if err := ghx.AddRemote(ctx, tempRemote, prInfo.HeadOwner, prInfo.HeadRepo); err != nil {
 				return "", "", fmt.Errorf("failed to add temporary remote: %w", err)
 			}
 			return tempRemote, tempRemote, nil
@@ -272,46 +336,21 @@ func determineRemote(w io.Writer, userRemote string, prInfo *ghx.PRInfo, prNumbe
 	return "origin", "", nil
 }
 
-func printPRProgress(w io.Writer, format string, args ...interface{}) {
-	// Extract cdMode and quiet from the end of args
-	if len(args) < 2 {
-		return
-	}
-	cdMode, ok1 := args[len(args)-2].(bool)
-	quiet, ok2 := args[len(args)-1].(bool)
-	if !ok1 || !ok2 {
-		return
-	}
-
-	if cdMode || quiet {
-		return
-	}
-
-	fmt.Fprintf(w, format, args[:len(args)-2]...)
-}
-
-func printPRInfo(w io.Writer, prInfo *ghx.PRInfo, cdMode, quiet bool) {
-	if cdMode || quiet {
-		return
-	}
-	fmt.Fprintf(w, "  Branch: %s\n", prInfo.HeadRefName)
-	fmt.Fprintf(w, "  Owner: %s\n", prInfo.HeadOwner)
+func printPRInfo(p *progressPrinter, prInfo *ghx.PRInfo) {
+	p.Printf("  Branch: %s\n", prInfo.HeadRefName)
+	p.Printf("  Owner: %s\n", prInfo.HeadOwner)
 }
 
-func printPRSuccess(w io.Writer, worktreePath string, prNumber int, localBranch string, cdMode, quiet bool) {
-	if cdMode {
-		fmt.Fprintln(w, worktreePath)
-		return
-	}
-
-	if quiet {
+func printPRSuccess(p *progressPrinter, worktreePath string, prNumber int, localBranch string) {
+	if p.cdMode {
+		printCdPath(p.w, worktreePath, localBranch)
 		return
 	}
 
-	fmt.Fprintf(w, "\n✓ PR review worktree created\n")
-	fmt.Fprintf(w, "  PR: #%d\n", prNumber)
-	fmt.Fprintf(w, "  Branch: %s\n", localBranch)
-	fmt.Fprintf(w, "  Path: %s\n", worktreePath)
-	fmt.Fprintf(w, "\nNavigate: cd %s\n", worktreePath)
-	fmt.Fprintf(w, "Or: wt go pr-%d\n", prNumber)
+	p.Printf("\n✓ PR review worktree created\n")
+	p.Printf("  PR: #%d\n", prNumber)
+	p.Printf("  Branch: %s\n", localBranch)
+	p.Printf("  Path: %s\n", worktreePath)
+	p.Printf("\nNavigate: cd %s\n", worktreePath)
+	p.Printf("Or: wt go pr-%d\n", prNumber)
 }
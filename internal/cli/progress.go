@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+)
+
+// progressPrinter writes the human-readable messages of a command, which are dropped with --quiet
+// and with --cd (stdout then carries only the path for the shell function)
+// Create one per command run with newProgressPrinter and pass it to the output functions.
+type progressPrinter struct {
+	w      io.Writer
+	cdMode bool
+	quiet  bool
+}
+
+func newProgressPrinter(w io.Writer, cdMode, quiet bool) *progressPrinter {
+	return &progressPrinter{w: w, cdMode: cdMode, quiet: quiet}
+}
+
+// enabled reports whether messages are printed
+func (p *progressPrinter) enabled() bool {
+	return !p.cdMode && !p.quiet
+}
+
+func (p *progressPrinter) Printf(format string, args ...interface{}) {
+	if p.enabled() {
+		fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *progressPrinter) Println(args ...interface{}) {
+	if p.enabled() {
+		fmt.Fprintln(p.w, args...)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressPrinter(t *testing.T) {
+	tests := []struct {
+		name   string
+		cdMode bool
+		quiet  bool
+		want   string
+	}{
+		{name: "normal", want: "Fetching PR #12 info...\ndone\n"},
+		{name: "cd mode", cdMode: true, want: ""},
+		{name: "quiet", quiet: true, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := newProgressPrinter(&buf, tt.cdMode, tt.quiet)
+			p.Printf("Fetching PR #%d info...\n", 12)
+			p.Println("done")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintPRSuccess(t *testing.T) {
+	var buf bytes.Buffer
+	printPRSuccess(newProgressPrinter(&buf, false, false), "/tmp/repo-pr-12", 12, "feature")
+	if out := buf.String(); !strings.Contains(out, "PR: #12") || !strings.Contains(out, "Path: /tmp/repo-pr-12") {
+		t.Errorf("printPRSuccess() output = %q, want the PR number and path", out)
+	}
+
+	// With --cd, stdout carries only the path, even with --quiet
+	buf.Reset()
+	printPRSuccess(newProgressPrinter(&buf, true, true), "/tmp/repo-pr-12", 12, "feature")
+	if got := buf.String(); got != "/tmp/repo-pr-12\n" {
+		t.Errorf("printPRSuccess() in cd mode = %q, want only the path", got)
+	}
+}
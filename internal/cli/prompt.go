@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// NonInteractiveError represents a confirmation prompt that can't be asked because stdin is not a terminal
+type NonInteractiveError struct {
+	Prompt string
+	Bypass string // Flags that skip the prompt, e.g. "--yes"; empty if there are none
+}
+
+func (e *NonInteractiveError) Error() string {
+	if e.Bypass == "" {
+		return fmt.Sprintf("cannot ask %q: stdin is not a terminal", e.Prompt)
+	}
+	return fmt.Sprintf("cannot ask %q: stdin is not a terminal (requires %s in non-interactive mode)", e.Prompt, e.Bypass)
+}
+
+// isTerminal reports whether prompts read from r can be answered by a person
+// Readers other than files (e.g. input provided by tests) are treated as interactive.
+// It is a variable so that tests can simulate a non-interactive stdin.
+var isTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return true
+	}
+	return isTerminalFile(f)
+}
+
+// isTerminalFile reports whether f is a terminal (not merely a character device like /dev/null)
+func isTerminalFile(f *os.File) bool {
+	return isTerminalFd(f.Fd())
+}
+
+// confirmWith writes the confirmation prompt to w and reads the answer from r
+// Pressing Enter gives prompts.default_answer. When r is not a terminal, nobody could answer, so it
+// fails with a NonInteractiveError naming bypass, the flags that skip the prompt.
+func confirmWith(ctx context.Context, r io.Reader, w io.Writer, message, bypass string) (bool, error) {
+	if !isTerminal(r) {
+		return false, &NonInteractiveError{Prompt: message, Bypass: bypass}
+	}
+
+	defaultYes := promptDefaultAnswer(ctx) == config.PromptAnswerYes
+	hint := "y/N"
+	if defaultYes {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(w, "%s (%s): ", message, hint)
+
+	input, err := selectx.ReadLine(r)
+	if err != nil {
+		fmt.Fprintln(w)
+		return false, nil
+	}
+
+	switch strings.TrimSpace(strings.ToLower(input)) {
+	case "y", "yes":
+		return true, nil
+	case "":
+		return defaultYes, nil
+	default:
+		return false, nil
+	}
+}
+
+// promptDefaultAnswer returns the configured answer for an empty reply ("no" if the configuration is unreadable)
+func promptDefaultAnswer(ctx context.Context) string {
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return config.DefaultPromptAnswer
+	}
+	return cfg.GetPromptDefaultAnswer()
+}
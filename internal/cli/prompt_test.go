@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setNonInteractive makes prompts behave as if stdin were not a terminal until the test finishes
+func setNonInteractive(t *testing.T) {
+	t.Helper()
+	orig := isTerminal
+	isTerminal = func(io.Reader) bool { return false }
+	t.Cleanup(func() { isTerminal = orig })
+}
+
+func TestIsTerminalDevNull(t *testing.T) {
+	// /dev/null is a character device, but not a terminal: nobody can answer a prompt there
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	if isTerminal(devNull) {
+		t.Errorf("isTerminal(%s) = true, want false", os.DevNull)
+	}
+	_, err = confirmWith(context.Background(), devNull, io.Discard, "Remove?", "--yes")
+	var nonInteractive *NonInteractiveError
+	if !errors.As(err, &nonInteractive) {
+		t.Errorf("confirmWith() with stdin from %s error = %v, want NonInteractiveError", os.DevNull, err)
+	}
+}
+
+func TestConfirmWith(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WT_PROMPT_DEFAULT_ANSWER", "no")
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: " yes \r\n", want: true},
+		{input: "y", want: false}, // No newline before EOF
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		got, err := confirmWith(context.Background(), strings.NewReader(tt.input), &buf, "Are you sure?", "--yes")
+		if err != nil {
+			t.Fatalf("confirmWith(%q) returned error: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("confirmWith(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if !strings.HasPrefix(buf.String(), "Are you sure? (y/N): ") {
+			t.Errorf("confirmWith(%q) prompt = %q", tt.input, buf.String())
+		}
+	}
+}
+
+func TestConfirmWithSharedInput(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Each prompt consumes only its own line
+	r := strings.NewReader("y\nn\ny\n")
+	var got []bool
+	for i := 0; i < 3; i++ {
+		confirmed, err := confirmWith(context.Background(), r, io.Discard, "Continue?", "--yes")
+		if err != nil {
+			t.Fatalf("confirmWith() returned error: %v", err)
+		}
+		got = append(got, confirmed)
+	}
+	if want := []bool{true, false, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("confirmWith() answers = %v, want %v", got, want)
+	}
+}
+
+func TestConfirmWithDefaultAnswer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WT_PROMPT_DEFAULT_ANSWER", "yes")
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	confirmed, err := confirmWith(ctx, strings.NewReader("\n"), &buf, "Continue?", "--yes")
+	if err != nil || !confirmed {
+		t.Errorf("confirmWith(Enter) = %v, %v, want true with default_answer yes", confirmed, err)
+	}
+	if !strings.HasPrefix(buf.String(), "Continue? (Y/n): ") {
+		t.Errorf("confirmWith() prompt = %q, want the default capitalized", buf.String())
+	}
+
+	// An explicit answer still counts, and so does end of input
+	if confirmed, _ := confirmWith(ctx, strings.NewReader("n\n"), io.Discard, "Continue?", "--yes"); confirmed {
+		t.Errorf("confirmWith(n) = true, want false")
+	}
+	if confirmed, _ := confirmWith(ctx, strings.NewReader(""), io.Discard, "Continue?", "--yes"); confirmed {
+		t.Errorf("confirmWith(EOF) = true, want false")
+	}
+}
+
+func TestConfirmWithNonInteractive(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	setNonInteractive(t)
+
+	var buf bytes.Buffer
+	confirmed, err := confirmWith(context.Background(), strings.NewReader("y\n"), &buf, "Are you sure?", "--yes")
+	var nonInteractive *NonInteractiveError
+	if !errors.As(err, &nonInteractive) || confirmed {
+		t.Fatalf("confirmWith() = %v, %v, want NonInteractiveError", confirmed, err)
+	}
+	if !strings.Contains(err.Error(), "requires --yes in non-interactive mode") {
+		t.Errorf("error should name the bypass flag, got: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be prompted, got: %q", buf.String())
+	}
+	if got := exitCode(err); got != ExitUsage {
+		t.Errorf("exitCode() = %d, want %d", got, ExitUsage)
+	}
+	if got := errorType(err); got != "non_interactive" {
+		t.Errorf("errorType() = %q, want %q", got, "non_interactive")
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	// Test input is answered by the test
+	if !isTerminal(strings.NewReader("")) {
+		t.Error("isTerminal(strings.Reader) = false, want true")
+	}
+
+	// A pipe or file has nobody at the other end
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte("y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("isTerminal(regular file) = true, want false")
+	}
+}
+
+func TestCleanFailsFastWithoutTerminal(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+	setNonInteractive(t)
+
+	cmd := newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(io.Discard)
+	err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{})
+	var nonInteractive *NonInteractiveError
+	if !errors.As(err, &nonInteractive) || nonInteractive.Bypass != "--yes" {
+		t.Fatalf("runCleanWithConfig() error = %v, want NonInteractiveError bypassed by --yes", err)
+	}
+	if !pathExists(wtPath) {
+		t.Error("worktree should not be removed")
+	}
+
+	// --yes answers every prompt
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{yes: true}); err != nil {
+		t.Fatalf("runCleanWithConfig(--yes) error = %v", err)
+	}
+	if pathExists(wtPath) {
+		t.Error("worktree should be removed with --yes")
+	}
+}
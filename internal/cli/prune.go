@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+type pruneCmdConfig struct {
+	dryRun  bool
+	expire  string
+	verbose bool // Accepted for compatibility with 'git worktree prune -v'; wt always reports
+	yes     bool
+	iKnow   bool
+}
+
+// prunedWorktree is a worktree registration removed by wt prune
+type prunedWorktree struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch"` // Empty for a detached HEAD or if unknown
+	Reason string `json:"reason"`
+}
+
+// pruneResult is the JSON output of wt prune
+type pruneResult struct {
+	DryRun          bool             `json:"dry_run"`
+	Pruned          []prunedWorktree `json:"pruned"`
+	DeletedBranches []string         `json:"deleted_branches"`
+	Orphans         []string         `json:"orphans"` // Worktree directories git doesn't know about
+}
+
+func newPruneCmd() *cobra.Command {
+	cfg := &pruneCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove registrations of worktrees whose directories are gone",
+		Long: `Remove the registrations of worktrees whose directories were deleted or moved
+without wt (git worktree prune), and list what was removed.
+
+The branches of the pruned worktrees are left without a worktree. Those merged into
+the default branch are offered for deletion (--yes deletes them without asking; see
+wt prune-branches for the others). Directories in the worktree directory that git
+doesn't know about are reported too.
+
+If this shell last used wt in another repository, --yes first shows the repository
+and asks for its name (--i-know skips this).
+
+Options:
+  --dry-run       Only show what would be pruned
+  --expire <time> Only prune registrations older than <time> (e.g. "2.weeks.ago")
+  --i-know        Don't ask for the repository name with --yes
+
+Examples:
+  wt prune
+  wt prune --dry-run
+  wt prune --expire 1.month.ago --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg.yes = flagYes
+			return runPruneWithConfig(c, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&cfg.dryRun, "dry-run", "n", false, "Only show what would be pruned")
+	cmd.Flags().StringVar(&cfg.expire, "expire", "", "Only prune registrations older than this time (e.g. 2.weeks.ago)")
+	cmd.Flags().BoolVarP(&cfg.verbose, "verbose", "v", false, "Report pruned worktrees (always on)")
+	_ = cmd.Flags().MarkHidden("verbose")
+	cmd.Flags().BoolVar(&cfg.iKnow, "i-know", false, "With --yes, don't ask to confirm the repository if this shell last used wt in another one")
+
+	return withJSON(cmd)
+}
+
+var pruneCmd = newPruneCmd()
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPruneWithConfig(cmd *cobra.Command, cfg *pruneCmdConfig) error {
+	ctx := cmd.Context()
+	w := cmd.OutOrStdout()
+	if jsonOutput() {
+		// Keep stdout for the JSON summary
+		w = cmd.ErrOrStderr()
+	}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return fmt.Errorf("failed to get repository information: %w", err)
+	}
+	if cfg.yes && !cfg.dryRun {
+		if err := confirmBatchRepo(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), cfg.iKnow); err != nil {
+			return err
+		}
+	}
+	// The branches of the registrations are only known before they are pruned
+	before, err := gitx.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	pruned, err := gitx.PruneVerbose(ctx, gitx.PruneOptions{DryRun: cfg.dryRun, Expire: cfg.expire})
+	if err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+
+	result := &pruneResult{DryRun: cfg.dryRun, Pruned: []prunedWorktree{}, DeletedBranches: []string{}}
+	for _, p := range pruned {
+		entry := prunedWorktree{Path: p.Path, Reason: p.Reason}
+		if entry.Path == "" {
+			entry.Path = p.Name
+		}
+		for _, wt := range before {
+			if p.Path != "" && gitx.SamePath(wt.Path, p.Path) {
+				entry.Branch = wt.Branch
+			}
+		}
+		result.Pruned = append(result.Pruned, entry)
+	}
+	printPruned(w, result.Pruned, cfg.dryRun, flagQuiet)
+
+	merged, err := mergedBranchesWithoutWorktree(ctx, result.Pruned, cfg.dryRun)
+	if err != nil {
+		return err
+	}
+	if len(merged) > 0 {
+		result.DeletedBranches, err = offerBranchDeletion(ctx, cmd.InOrStdin(), w, cmd.ErrOrStderr(), merged, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	after, err := gitx.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	result.Orphans = findOrphanWorktreeDirs(ctx, repo, after)
+	printOrphans(w, result.Orphans, flagQuiet)
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+	return nil
+}
+
+// mergedBranchesWithoutWorktree returns the branches of the pruned worktrees that are merged into
+// the default branch and, once pruned, not checked out anywhere
+// In a dry run the worktrees are still registered, so they aren't checked for other worktrees.
+func mergedBranchesWithoutWorktree(ctx context.Context, pruned []prunedWorktree, dryRun bool) ([]branchCandidate, error) {
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+	defaultBranch, err := gitx.DefaultBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mergedBranches, err := gitx.ListBranchesMergedInto(ctx, defaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check merged branches: %w", err)
+	}
+	merged := make(map[string]bool, len(mergedBranches))
+	for _, name := range mergedBranches {
+		merged[name] = true
+	}
+
+	var candidates []branchCandidate
+	seen := make(map[string]bool)
+	for _, p := range pruned {
+		if p.Branch == "" || p.Branch == defaultBranch || !merged[p.Branch] || seen[p.Branch] {
+			continue
+		}
+		seen[p.Branch] = true
+		if !dryRun {
+			inUse, err := gitx.IsUsingBranch(ctx, p.Branch, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to check branch usage: %w", err)
+			}
+			if inUse {
+				continue
+			}
+		}
+		candidates = append(candidates, branchCandidate{Name: p.Branch, Merged: true})
+	}
+	return candidates, nil
+}
+
+// offerBranchDeletion asks whether to delete the merged branches left without a worktree and returns the deleted ones
+// With --yes they are deleted without asking. Without a terminal (or in a dry run), they are only listed.
+func offerBranchDeletion(ctx context.Context, r io.Reader, w, errW io.Writer, branches []branchCandidate, cfg *pruneCmdConfig) ([]string, error) {
+	names := make([]string, len(branches))
+	for i, b := range branches {
+		names[i] = b.Name
+	}
+	if cfg.dryRun || (!cfg.yes && !isTerminal(r)) {
+		if !flagQuiet {
+			fmt.Fprintf(w, "Merged branches left without a worktree: %s\n", strings.Join(names, ", "))
+			fmt.Fprintf(w, "  Run: wt prune-branches --merged-only to delete them\n")
+		}
+		return []string{}, nil
+	}
+
+	if !cfg.yes {
+		fmt.Fprintf(errW, "Merged branches left without a worktree: %s\n", strings.Join(names, ", "))
+		confirmed, err := confirmWith(ctx, r, errW, "Delete them?", "--yes")
+		if err != nil || !confirmed {
+			return []string{}, err
+		}
+	}
+	deleted, err := deleteBranches(ctx, r, w, errW, branches, true)
+	if deleted == nil {
+		deleted = []string{}
+	}
+	return deleted, err
+}
+
+// Output functions
+
+func printPruned(w io.Writer, pruned []prunedWorktree, dryRun, quiet bool) {
+	if quiet {
+		return
+	}
+	if len(pruned) == 0 {
+		fmt.Fprintf(w, "No worktrees to prune\n")
+		return
+	}
+	for _, p := range pruned {
+		branch := ""
+		if p.Branch != "" {
+			branch = fmt.Sprintf(" [%s]", p.Branch)
+		}
+		if dryRun {
+			fmt.Fprintf(w, "Would prune %s%s (%s)\n", p.Path, branch, p.Reason)
+		} else {
+			fmt.Fprintf(w, "✓ Pruned %s%s (%s)\n", p.Path, branch, p.Reason)
+		}
+	}
+}
+
+func printOrphans(w io.Writer, orphans []string, quiet bool) {
+	if quiet || len(orphans) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nOrphan directories (not registered with git):\n")
+	for _, path := range orphans {
+		fmt.Fprintf(w, "  %s\n", path)
+	}
+	fmt.Fprintf(w, "  Run: wt repair <dir> to register a moved worktree, or delete the directory\n")
+}
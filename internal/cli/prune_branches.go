@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+type pruneBranchesCmdConfig struct {
+	yes        bool
+	mergedOnly bool
+	dryRun     bool
+	iKnow      bool
+}
+
+// branchCandidate is a local branch wt prune-branches may delete
+type branchCandidate struct {
+	Name   string
+	Merged bool // Merged into the default branch
+}
+
+func newPruneBranchesCmd() *cobra.Command {
+	cfg := &pruneBranchesCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "prune-branches",
+		Short: "Delete local branches that have no worktree",
+		Long: `Delete local branches that aren't checked out in any worktree.
+
+Lists the local branches other than the default branch that have no worktree (e.g.
+left behind by wt clean --keep-branch), marking whether each is merged into the
+default branch, and lets you select the ones to delete. Deleting an unmerged branch
+asks for confirmation, like wt clean.
+
+Options:
+  --merged-only  Only consider branches merged into the default branch
+  --yes          Delete all listed branches without asking (unmerged ones too,
+                 unless --merged-only)
+  --dry-run      Only list the branches
+  --i-know       Don't ask for the repository name with --yes
+
+If this shell last used wt in another repository, --yes first shows the repository
+and asks for its name, so that branches aren't deleted in the wrong one.
+
+Examples:
+  wt prune-branches
+  wt prune-branches --merged-only --yes
+  wt prune-branches --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg.yes = flagYes
+			return runPruneBranchesWithConfig(c, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.mergedOnly, "merged-only", false, "Only consider branches merged into the default branch")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "List the branches without deleting them")
+	cmd.Flags().BoolVar(&cfg.iKnow, "i-know", false, "With --yes, don't ask to confirm the repository if this shell last used wt in another one")
+
+	return cmd
+}
+
+var pruneBranchesCmd = newPruneBranchesCmd()
+
+func init() {
+	rootCmd.AddCommand(pruneBranchesCmd)
+}
+
+func runPruneBranchesWithConfig(cmd *cobra.Command, cfg *pruneBranchesCmdConfig) error {
+	ctx := cmd.Context()
+	r, w := cmd.InOrStdin(), cmd.OutOrStdout()
+
+	defaultBranch, err := gitx.DefaultBranch(ctx)
+	if err != nil {
+		return err
+	}
+	candidates, err := findPrunableBranches(ctx, defaultBranch, cfg.mergedOnly)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		if !flagQuiet {
+			fmt.Fprintf(w, "No branches to prune\n")
+		}
+		return nil
+	}
+
+	if cfg.dryRun {
+		printPrunableBranches(w, candidates, defaultBranch)
+		return nil
+	}
+
+	selected := candidates
+	if cfg.yes {
+		if err := confirmBatchRepo(ctx, r, cmd.ErrOrStderr(), cfg.iKnow); err != nil {
+			return err
+		}
+	} else {
+		indexes, err := pickItems(ctx, r, cmd.ErrOrStderr(), createBranchItems(candidates, defaultBranch), "", selectOptions{prompt: "Select branches to delete", multi: true})
+		if err != nil {
+			return err
+		}
+		selected = make([]branchCandidate, len(indexes))
+		for i, idx := range indexes {
+			selected[i] = candidates[idx]
+		}
+	}
+
+	deleted, err := deleteBranches(ctx, r, w, cmd.ErrOrStderr(), selected, cfg.yes)
+	printPruneBranchesSummary(w, candidates, deleted, flagQuiet)
+	return err
+}
+
+// findPrunableBranches returns the local branches other than defaultBranch that no worktree has checked out
+func findPrunableBranches(ctx context.Context, defaultBranch string, mergedOnly bool) ([]branchCandidate, error) {
+	branches, err := gitx.ListLocalBranches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	mergedBranches, err := gitx.ListBranchesMergedInto(ctx, defaultBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check merged branches: %w", err)
+	}
+	merged := make(map[string]bool, len(mergedBranches))
+	for _, name := range mergedBranches {
+		merged[name] = true
+	}
+
+	var candidates []branchCandidate
+	for _, name := range branches {
+		if name == defaultBranch || (mergedOnly && !merged[name]) {
+			continue
+		}
+		inUse, err := gitx.IsUsingBranch(ctx, name, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check branch usage: %w", err)
+		}
+		if inUse {
+			continue
+		}
+		candidates = append(candidates, branchCandidate{Name: name, Merged: merged[name]})
+	}
+	return candidates, nil
+}
+
+// deleteBranches deletes the branches, confirming unmerged ones unless autoYes, and returns the deleted ones
+// It stops at a confirmation that can't be asked (see confirmWith). Results are written to w;
+// prompts and the warnings they are about go to errW.
+func deleteBranches(ctx context.Context, r io.Reader, w, errW io.Writer, branches []branchCandidate, autoYes bool) ([]string, error) {
+	progress := newProgressPrinter(w, false, flagQuiet)
+	var deleted []string
+	for _, b := range branches {
+		if !b.Merged {
+			printBranchNotMergedWarning(errW, b.Name)
+			if !autoYes {
+				confirmed, err := confirmWith(ctx, r, errW, "Force delete? (git branch -D)", "--yes")
+				if err != nil {
+					return deleted, err
+				}
+				if !confirmed {
+					printBranchKeptMessage(progress, b.Name)
+					continue
+				}
+			}
+		}
+
+		// Merged branches are merged into the default branch, not necessarily into HEAD,
+		// so git branch -d could refuse them
+		commit, err := deleteBranchRecorded(ctx, b.Name, true)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: failed to delete branch %s: %v\n", b.Name, err)
+			continue
+		}
+		deleted = append(deleted, b.Name)
+		printBranchDeletionSuccess(progress, b.Name, commit)
+	}
+	return deleted, nil
+}
+
+// createBranchItems creates the selection list of branches, e.g. "feature/x\tmerged into main"
+func createBranchItems(branches []branchCandidate, defaultBranch string) []string {
+	items := make([]string, len(branches))
+	for i, b := range branches {
+		items[i] = b.Name + "\t" + formatMerged(b, defaultBranch)
+	}
+	return items
+}
+
+func formatMerged(b branchCandidate, defaultBranch string) string {
+	if b.Merged {
+		return "merged into " + defaultBranch
+	}
+	return "not merged"
+}
+
+// Output functions
+
+func printPrunableBranches(w io.Writer, branches []branchCandidate, defaultBranch string) {
+	fmt.Fprintf(w, "Branches without a worktree:\n")
+	for _, b := range branches {
+		fmt.Fprintf(w, "  %s (%s)\n", b.Name, formatMerged(b, defaultBranch))
+	}
+}
+
+func printPruneBranchesSummary(w io.Writer, candidates []branchCandidate, deleted []string, quiet bool) {
+	if quiet {
+		return
+	}
+	isDeleted := make(map[string]bool, len(deleted))
+	for _, name := range deleted {
+		isDeleted[name] = true
+	}
+	var kept []string
+	for _, b := range candidates {
+		if !isDeleted[b.Name] {
+			kept = append(kept, b.Name)
+		}
+	}
+
+	fmt.Fprintf(w, "\nSummary: deleted %d, kept %d", len(deleted), len(kept))
+	if len(kept) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(kept, ", "))
+	}
+	fmt.Fprintln(w)
+}
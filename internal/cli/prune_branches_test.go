@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestFindPrunableBranches(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "merged")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "in-use", filepath.Join(t.TempDir(), "in-use"))
+	runGitForTest(t, repoPath, "checkout", "-q", "-b", "unmerged")
+	commitFileForTest(t, repoPath, "unmerged.txt")
+	runGitForTest(t, repoPath, "checkout", "-q", "main")
+	ctx := context.Background()
+
+	defaultBranch, err := gitx.DefaultBranch(ctx)
+	if err != nil || defaultBranch != "main" {
+		t.Fatalf("DefaultBranch() = %q, %v, want main", defaultBranch, err)
+	}
+
+	got, err := findPrunableBranches(ctx, defaultBranch, false)
+	if err != nil {
+		t.Fatalf("findPrunableBranches() returned error: %v", err)
+	}
+	want := []branchCandidate{{Name: "merged", Merged: true}, {Name: "unmerged", Merged: false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findPrunableBranches() = %+v, want %+v", got, want)
+	}
+
+	got, err = findPrunableBranches(ctx, defaultBranch, true)
+	if err != nil {
+		t.Fatalf("findPrunableBranches() returned error: %v", err)
+	}
+	if want := want[:1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("findPrunableBranches() merged only = %+v, want %+v", got, want)
+	}
+}
+
+func TestDeleteBranches(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "merged")
+	runGitForTest(t, repoPath, "branch", "unmerged")
+	ctx := context.Background()
+
+	// Declining the force delete keeps the unmerged branch
+	var out, errOut bytes.Buffer
+	branches := []branchCandidate{{Name: "merged", Merged: true}, {Name: "unmerged", Merged: false}}
+	deleted, _ := deleteBranches(ctx, strings.NewReader("n\n"), &out, &errOut, branches, false)
+	if want := []string{"merged"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleteBranches() = %q, want %q", deleted, want)
+	}
+	// The prompt and its warning go to the error stream, results to the output
+	if !strings.Contains(errOut.String(), "Branch 'unmerged' is not merged") || !strings.Contains(errOut.String(), "Force delete?") {
+		t.Errorf("error output should warn about the unmerged branch and ask, got:\n%s", errOut.String())
+	}
+	if strings.Contains(out.String(), "Force delete?") {
+		t.Errorf("output should not contain the prompt, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	printPruneBranchesSummary(&out, branches, deleted, false)
+	if want := "Summary: deleted 1, kept 1 (unmerged)"; !strings.Contains(out.String(), want) {
+		t.Errorf("summary = %q, want it to contain %q", out.String(), want)
+	}
+
+	if exists, _ := gitx.BranchExists(ctx, "unmerged"); !exists {
+		t.Error("unmerged branch was deleted")
+	}
+	deleted, _ = deleteBranches(ctx, strings.NewReader(""), &out, &errOut, branches[1:], true)
+	if want := []string{"unmerged"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleteBranches() with --yes = %q, want %q", deleted, want)
+	}
+}
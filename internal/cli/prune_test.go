@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunPrune(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	container := filepath.Join(filepath.Dir(repoPath), ".test-repo-wt")
+
+	// Two worktrees deleted without wt: one branch merged into main, one with its own commit
+	mergedPath := filepath.Join(container, "merged")
+	unmergedPath := filepath.Join(container, "unmerged")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "merged", mergedPath)
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "unmerged", unmergedPath)
+	runGitForTest(t, unmergedPath, "commit", "--allow-empty", "-m", "Unmerged work")
+	for _, path := range []string{mergedPath, unmergedPath} {
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A directory that looks like a worktree but isn't registered
+	orphanPath := filepath.Join(container, "orphan")
+	if err := os.MkdirAll(orphanPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphanPath, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(input string, cfg *pruneCmdConfig) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		cmd := newPruneCmd()
+		cmd.SetContext(context.Background())
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		if err := runPruneWithConfig(cmd, cfg); err != nil {
+			t.Fatalf("runPruneWithConfig() returned error: %v", err)
+		}
+		return stdout.String()
+	}
+
+	out := run("", &pruneCmdConfig{dryRun: true})
+	for _, want := range []string{"Would prune " + mergedPath + " [merged]", "Would prune " + unmergedPath + " [unmerged]", "Merged branches left without a worktree: merged", orphanPath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run output should contain %q, got:\n%s", want, out)
+		}
+	}
+	if list := runGitForTest(t, repoPath, "worktree", "list"); !strings.Contains(list, mergedPath) {
+		t.Errorf("a dry run should keep the registrations:\n%s", list)
+	}
+
+	out = run("y\n", &pruneCmdConfig{})
+	for _, want := range []string{"✓ Pruned " + mergedPath + " [merged]", "✓ Pruned " + unmergedPath, "✓ Branch deleted: merged", "Orphan directories", orphanPath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	branches := strings.Fields(runGitForTest(t, repoPath, "branch", "--format=%(refname:short)"))
+	if !reflect.DeepEqual(branches, []string{"main", "unmerged"}) {
+		t.Errorf("branches = %q, want only the merged branch deleted", branches)
+	}
+
+	out = run("", &pruneCmdConfig{})
+	if !strings.Contains(out, "No worktrees to prune") {
+		t.Errorf("output should say there is nothing to prune, got:\n%s", out)
+	}
+}
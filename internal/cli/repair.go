@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+type repairCmdConfig struct {
+	scan bool
+}
+
+func newRepairCmd() *cobra.Command {
+	cfg := &repairCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "repair [path...]",
+		Short: "Repair worktree links after moving directories",
+		Long: `Repair worktree administrative links after the repository or worktree
+directories were moved (e.g. a renamed parent folder or a synced directory).
+
+Without arguments, repairs the links of the current repository.
+With paths, also repairs the worktrees at those paths.
+With --scan, the arguments are directories to search for worktrees
+(defaults to the configured worktree directory of the current repository).
+
+Examples:
+  wt repair                          # Repair links of the current repository
+  wt repair ../.myproject-wt/login   # Repair a moved worktree
+  wt repair --scan                   # Repair all worktrees in the worktree directory
+  wt repair --scan ~/old/.myproject-wt`,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runRepairWithConfig(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.scan, "scan", false, "Treat arguments as directories to search for worktrees")
+
+	return cmd
+}
+
+var repairCmd = newRepairCmd()
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
+
+func runRepairWithConfig(cmd *cobra.Command, args []string, cfg *repairCmdConfig) error {
+	ctx := cmd.Context()
+
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeRepair); err != nil {
+		return err
+	}
+
+	paths := args
+	if cfg.scan {
+		var err error
+		paths, err = scanForWorktrees(ctx, args)
+		if err != nil {
+			return err
+		}
+	}
+
+	links, err := gitx.Repair(ctx, paths...)
+	if err != nil {
+		return fmt.Errorf("failed to repair worktrees: %w", err)
+	}
+
+	printRepairResult(cmd.OutOrStdout(), links, flagQuiet)
+	return nil
+}
+
+// scanForWorktrees returns the worktree directories found directly under dirs
+// With no dirs, the configured worktree directory of the current repository is searched.
+func scanForWorktrees(ctx context.Context, dirs []string) ([]string, error) {
+	prefix := ""
+	nested := false
+	if len(dirs) == 0 {
+		repo, err := gitx.GetRepo(ctx, flagRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository information (specify directories to scan): %w", err)
+		}
+		wtCfg, err := loadWorktreeConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// In sibling mode the directory is shared with other repositories, so only scan names with the prefix
+		var dir string
+		dir, prefix = naming.WorktreeContainer(configuredBaseDir(repo, wtCfg), repo.Name, wtCfg)
+		dirs = []string{dir}
+
+		// Worktrees of branches like feature/login may be nested below the directory
+		nested = naming.NestsBranchDirs(wtCfg)
+	}
+
+	var paths []string
+	for _, dir := range dirs {
+		found, err := findWorktreeDirs(dir, prefix, nested)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// findWorktreeDirs returns subdirectories of dir that look like linked worktrees (contain a .git file)
+// With nested, directories that aren't worktrees or repositories are searched recursively.
+func findWorktreeDirs(dir, prefix string, nested bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if isLinkedWorktreeDir(path) {
+			paths = append(paths, path)
+			continue
+		}
+		if nested {
+			if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+				continue // A repository: don't descend
+			}
+			found, err := findWorktreeDirs(path, "", true)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, found...)
+		}
+	}
+	return paths, nil
+}
+
+// isLinkedWorktreeDir reports whether path contains a .git file (as linked worktrees do)
+func isLinkedWorktreeDir(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// findMovedWorktrees returns directories that look like moved copies of worktrees whose paths no longer exist
+// Directories with the same name are searched for in the worktree directory and next to the repository.
+func findMovedWorktrees(ctx context.Context, worktrees []gitx.Worktree) []string {
+	var missing []gitx.Worktree
+	for _, wt := range worktrees {
+		if !pathExists(wt.Path) {
+			missing = append(missing, wt)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return nil
+	}
+	searchDirs := []string{repo.Parent}
+	if wtCfg, err := loadWorktreeConfig(ctx); err == nil {
+		searchDirs = append([]string{worktreeBaseDir(repo, wtCfg)}, searchDirs...)
+	}
+
+	var candidates []string
+	for _, wt := range missing {
+		for _, dir := range searchDirs {
+			candidate := filepath.Join(dir, filepath.Base(wt.Path))
+			if isLinkedWorktreeDir(candidate) {
+				candidates = append(candidates, candidate)
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// suggestRepair prints a hint when worktrees appear to have been moved
+func suggestRepair(ctx context.Context, w io.Writer, worktrees []gitx.Worktree) {
+	candidates := findMovedWorktrees(ctx, worktrees)
+	if len(candidates) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Hint: some worktrees were not found at their recorded paths but seem to have been moved.\n")
+	fmt.Fprintf(w, "  Run: wt repair %s\n", strings.Join(candidates, " "))
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Output functions
+
+func printRepairResult(w io.Writer, links []gitx.RepairedLink, quiet bool) {
+	if quiet {
+		return
+	}
+	if len(links) == 0 {
+		fmt.Fprintln(w, "Nothing to repair")
+		return
+	}
+	for _, link := range links {
+		fmt.Fprintf(w, "✓ Repaired (%s): %s\n", link.Problem, link.Path)
+	}
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestFindWorktreeDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"repo-a", "repo-b", "other-c"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+			t.Fatalf("Failed to write .git file: %v", err)
+		}
+	}
+	// A main repository has a .git directory, not a file
+	if err := os.MkdirAll(filepath.Join(dir, "repo", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	got, err := findWorktreeDirs(dir, "", false)
+	if err != nil {
+		t.Fatalf("findWorktreeDirs() returned error: %v", err)
+	}
+	want := []string{filepath.Join(dir, "other-c"), filepath.Join(dir, "repo-a"), filepath.Join(dir, "repo-b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findWorktreeDirs() = %v, want %v", got, want)
+	}
+
+	got, err = findWorktreeDirs(dir, "repo-", false)
+	if err != nil {
+		t.Fatalf("findWorktreeDirs() returned error: %v", err)
+	}
+	want = []string{filepath.Join(dir, "repo-a"), filepath.Join(dir, "repo-b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findWorktreeDirs() with prefix = %v, want %v", got, want)
+	}
+}
+
+func TestFindWorktreeDirsNested(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main", filepath.Join("feature", "auth", "login"), filepath.Join("feature", "signup")} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+			t.Fatalf("Failed to write .git file: %v", err)
+		}
+	}
+	// Repositories are not searched
+	if err := os.MkdirAll(filepath.Join(dir, "repo", ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "repo", "sub", "wt"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "repo", "sub", "wt", ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .git file: %v", err)
+	}
+
+	got, err := findWorktreeDirs(dir, "", true)
+	if err != nil {
+		t.Fatalf("findWorktreeDirs() returned error: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "feature", "auth", "login"),
+		filepath.Join(dir, "feature", "signup"),
+		filepath.Join(dir, "main"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findWorktreeDirs() nested = %v, want %v", got, want)
+	}
+
+	got, err = findWorktreeDirs(dir, "", false)
+	if err != nil {
+		t.Fatalf("findWorktreeDirs() returned error: %v", err)
+	}
+	if want := []string{filepath.Join(dir, "main")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("findWorktreeDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestPrintRepairResult(t *testing.T) {
+	var buf bytes.Buffer
+	printRepairResult(&buf, nil, false)
+	if !strings.Contains(buf.String(), "Nothing to repair") {
+		t.Errorf("output = %q, want 'Nothing to repair'", buf.String())
+	}
+
+	buf.Reset()
+	printRepairResult(&buf, []gitx.RepairedLink{{Problem: "gitdir incorrect", Path: "/work/x"}}, false)
+	if !strings.Contains(buf.String(), "✓ Repaired (gitdir incorrect): /work/x") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestRepairMovedWorktree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+
+	// Worktree created elsewhere, then moved into the worktree directory behind git's back
+	wtDir := filepath.Join(filepath.Dir(repoPath), ".test-repo-wt")
+	oldPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", oldPath)
+	if err := os.MkdirAll(wtDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	movedPath := filepath.Join(wtDir, "feature")
+	if err := os.Rename(oldPath, movedPath); err != nil {
+		t.Fatalf("Failed to move worktree: %v", err)
+	}
+
+	// The move is detected and repair is suggested
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	var hint bytes.Buffer
+	suggestRepair(ctx, &hint, worktrees)
+	if !strings.Contains(hint.String(), "wt repair "+movedPath) {
+		t.Errorf("suggestRepair() output = %q, want a repair hint for %s", hint.String(), movedPath)
+	}
+
+	// wt repair --scan finds and fixes it
+	var buf bytes.Buffer
+	cmd := newRepairCmd()
+	cmd.SetOut(&buf)
+	cmd.SetContext(ctx)
+	if err := runRepairWithConfig(cmd, nil, &repairCmdConfig{scan: true}); err != nil {
+		t.Fatalf("runRepairWithConfig() returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "✓ Repaired") {
+		t.Errorf("output = %q, want a repaired link", buf.String())
+	}
+
+	wt, err := gitx.FindWorktreeByBranch(ctx, "feature")
+	if err != nil || wt == nil || wt.Path != movedPath {
+		t.Errorf("FindWorktreeByBranch() = %+v, %v, want path %s", wt, err, movedPath)
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// sessionIDEnv identifies the shell wt runs in; the shell function exports it (the shell's PID)
+const sessionIDEnv = "WT_SESSION_ID"
+
+// RepoMismatchError represents a batch deletion refused because the repository name wasn't confirmed
+type RepoMismatchError struct {
+	Repo string // Name of the repository the command would change
+	Last string // Root of the repository wt last worked on in this shell
+}
+
+func (e *RepoMismatchError) Error() string {
+	return fmt.Sprintf("not deleting anything in %s: this shell last used wt in %s (confirm by typing the repository name, or pass --i-know)", e.Repo, e.Last)
+}
+
+// sessionStatePath returns the file recording the repository wt last worked on in this shell
+// ($XDG_STATE_HOME/wt/sessions/<id>), or "" without the shell function
+func sessionStatePath() string {
+	id := os.Getenv(sessionIDEnv)
+	if _, err := strconv.Atoi(id); err != nil {
+		return ""
+	}
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sessions", id)
+}
+
+// stateDir returns the directory wt keeps state in ($XDG_STATE_HOME/wt, default ~/.local/state/wt),
+// or "" if there is no home directory
+func stateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "wt")
+}
+
+// lastSessionRepo returns the root of the repository wt last worked on in this shell ("" if unknown)
+func lastSessionRepo() string {
+	path := sessionStatePath()
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// recordSessionRepo remembers the repository of ctx as the one wt last worked on in this shell
+// It runs after every successful command, reusing the repository the command resolved (commands
+// that never needed it don't run git for this); failures are ignored.
+func recordSessionRepo(ctx context.Context) {
+	path := sessionStatePath()
+	if path == "" {
+		return
+	}
+	repo, ok := gitx.SessionRepo(ctx)
+	if !ok || repo.Root == lastSessionRepo() {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		_ = os.WriteFile(path, []byte(repo.Root+"\n"), 0644)
+	}
+}
+
+// confirmBatchRepo makes sure a batch deletion confirmed with --yes runs in the intended repository
+// If wt last worked on another repository in this shell, the repository is shown and its name
+// has to be typed to go on. iKnow (--i-know) skips the check.
+func confirmBatchRepo(ctx context.Context, r io.Reader, w io.Writer, iKnow bool) error {
+	last := lastSessionRepo()
+	if iKnow || last == "" {
+		return nil
+	}
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil || repo.Root == last {
+		return nil // The command reports a missing repository itself
+	}
+
+	printRepoMismatchWarning(w, repo, last)
+	prompt := fmt.Sprintf("Type the repository name (%s) to continue", repo.Name)
+	if !isTerminal(r) {
+		return &NonInteractiveError{Prompt: prompt, Bypass: "--i-know"}
+	}
+	fmt.Fprintf(w, "%s: ", prompt)
+	input, err := selectx.ReadLine(r)
+	if err != nil || strings.TrimSpace(input) != repo.Name {
+		return &RepoMismatchError{Repo: repo.Name, Last: last}
+	}
+	return nil
+}
+
+// Output functions
+
+func printRepoMismatchWarning(w io.Writer, repo *gitx.Repo, last string) {
+	fmt.Fprintf(w, "\n⚠ Repository: %s\n", repo.Name)
+	fmt.Fprintf(w, "  %s\n", repo.Root)
+	fmt.Fprintf(w, "  This shell last used wt in %s\n\n", last)
+}
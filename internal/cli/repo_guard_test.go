@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestConfirmBatchRepo(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(sessionIDEnv, "4242")
+	otherPath := setupTestRepo(t)
+	ctx := gitx.WithSession(context.Background(), gitx.NewSession())
+
+	// Nothing is recorded for a command that didn't need the repository
+	recordSessionRepo(ctx)
+	if got := lastSessionRepo(); got != "" {
+		t.Fatalf("lastSessionRepo() = %q, want nothing recorded", got)
+	}
+	if _, err := gitx.GetRepo(ctx, ""); err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+	recordSessionRepo(ctx)
+	if got := lastSessionRepo(); got == "" || !strings.HasSuffix(got, "test-repo") {
+		t.Fatalf("lastSessionRepo() = %q, want the recorded repository", got)
+	}
+
+	// Same repository: nothing to confirm
+	if err := confirmBatchRepo(ctx, strings.NewReader(""), io.Discard, false); err != nil {
+		t.Errorf("confirmBatchRepo() in the recorded repository error = %v, want nil", err)
+	}
+
+	setupTestRepo(t)
+	ctx = context.Background()
+	orig := isTerminal
+	isTerminal = func(io.Reader) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+
+	var stderr bytes.Buffer
+	if err := confirmBatchRepo(ctx, strings.NewReader("test-repo\n"), &stderr, false); err != nil {
+		t.Errorf("confirmBatchRepo() with the repository name error = %v, want nil", err)
+	}
+	if !strings.Contains(stderr.String(), "This shell last used wt in "+otherPath) {
+		t.Errorf("warning = %q, want the last repository shown", stderr.String())
+	}
+
+	var mismatch *RepoMismatchError
+	if err := confirmBatchRepo(ctx, strings.NewReader("other\n"), io.Discard, false); !errors.As(err, &mismatch) {
+		t.Errorf("confirmBatchRepo() with a wrong name error = %v, want RepoMismatchError", err)
+	}
+	if err := confirmBatchRepo(ctx, strings.NewReader(""), io.Discard, true); err != nil {
+		t.Errorf("confirmBatchRepo() with --i-know error = %v, want nil", err)
+	}
+
+	setNonInteractive(t)
+	var nonInteractive *NonInteractiveError
+	if err := confirmBatchRepo(ctx, strings.NewReader(""), io.Discard, false); !errors.As(err, &nonInteractive) || nonInteractive.Bypass != "--i-know" {
+		t.Errorf("confirmBatchRepo() without a terminal error = %v, want NonInteractiveError naming --i-know", err)
+	}
+}
@@ -1,24 +1,47 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/logx"
 )
 
 var (
 	// Global flags
-	flagRepo  string
-	flagQuiet bool
-	flagDebug bool
+	flagRepo     string
+	flagQuiet    bool
+	flagDebug    bool
+	flagDebugLog string
+	flagConfig   string
+	flagStrict   bool
+	flagTimeout  time.Duration
+	flagJSON     bool
+	flagCwd      string
+	flagYes      bool
+	// flagPorcelainCd makes --cd print "path<TAB>branch" (set by the shell hooks)
+	flagPorcelainCd bool
+	// flagNoAlign prints tables as tab-separated values even on a terminal
+	flagNoAlign bool
+	// flagNull terminates printed paths with NUL instead of a newline (like git's -z)
+	flagNull bool
+	// flagRespectGitEnv lets git follow GIT_DIR, GIT_WORK_TREE, ... instead of removing them
+	flagRespectGitEnv bool
+	// flagNoCache reads the worktree list from git in slow filesystem mode instead of the cache
+	flagNoCache bool
 
 	// Version information (set by main package)
 	versionInfo = "dev"
@@ -40,7 +63,7 @@ func SetVersionInfo(version, commit, date string) {
 	versionInfo = version
 	commitInfo = commit
 	dateInfo = date
-	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
+	rootCmd.Version = versionString(readBuildInfo())
 }
 
 // ShellFunctionNotConfiguredError represents an error when shell function is not configured
@@ -54,33 +77,64 @@ To enable directory navigation with --cd flag, configure your shell:
   Bash:   echo 'eval "$(wt hook bash)"' >> ~/.bashrc
   Zsh:    echo 'eval "$(wt hook zsh)"' >> ~/.zshrc
   Fish:   wt hook fish > ~/.config/fish/functions/wt.fish
+  PowerShell: Add-Content $PROFILE 'Invoke-Expression (& wt hook powershell | Out-String)'
 
 Then restart your shell or run: exec $SHELL`
 }
 
 // checkShellFunction checks if shell function is configured when using --cd flag
+// The shell function (see wt hook) sets WT_SHELL_FUNCTION and reads the line printed by
+// printCdPath; with --porcelain-cd it also exports WT_CURRENT_WORKTREE and WT_CURRENT_BRANCH.
 func checkShellFunction(cdMode bool) error {
 	if !cdMode {
 		return nil
 	}
 
-	// Check if WT_SHELL_FUNCTION environment variable is set
-	if os.Getenv("WT_SHELL_FUNCTION") == "" {
+	if !shellFunctionActive() {
 		return &ShellFunctionNotConfiguredError{}
 	}
 
 	return nil
 }
 
+// shellFunctionActive reports whether wt runs from the shell function, which sets WT_SHELL_FUNCTION
+func shellFunctionActive() bool {
+	return os.Getenv("WT_SHELL_FUNCTION") != ""
+}
+
+// printCdPath prints the line the shell function changes directory to: the worktree path,
+// or "path<TAB>branch" with --porcelain-cd (branch is empty for a detached HEAD)
+func printCdPath(w io.Writer, path, branch string) {
+	if flagPorcelainCd {
+		printPath(w, path+"\t"+branch)
+		return
+	}
+	printPath(w, path)
+}
+
+// printPath prints a path for scripts, terminated by a newline or with --null by a NUL byte
+func printPath(w io.Writer, path string) {
+	if flagNull {
+		fmt.Fprintf(w, "%s\x00", path)
+		return
+	}
+	fmt.Fprintln(w, path)
+}
+
+// checkNullFlag refuses --null together with --json, which has its own format
+func checkNullFlag() error {
+	if flagNull && jsonOutput() {
+		return &UsageError{Err: errors.New("--null can't be combined with --json")}
+	}
+	return nil
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "wt",
 	Short: "Git worktree helper CLI",
 	Long: `wt is a CLI tool that wraps git worktree commands with conventions and shortcuts.
 It manages worktrees in sibling directories with automatic naming conventions.`,
-	Version: "dev",
-	CompletionOptions: cobra.CompletionOptions{
-		DisableDefaultCmd: true,
-	},
+	Version:       "dev",
 	SilenceErrors: true,
 	SilenceUsage:  true,
 	// Allow unknown flags to pass through to subcommands
@@ -89,21 +143,85 @@ It manages worktrees in sibling directories with automatic naming conventions.`,
 		UnknownFlags: true,
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Don't mix formats: commands without JSON output refuse --json
+		if jsonOutput() && !supportsJSON(cmd) {
+			return &JSONUnsupportedError{Command: cmd.CommandPath()}
+		}
+		if err := checkNullFlag(); err != nil {
+			return err
+		}
+
 		// Set debug mode
 		if flagDebug {
 			gitx.Debug = true
 		}
+		setupDebugLog()
 
-		// Check if git command is available
+		// Work on the repository of the current directory even if GIT_DIR points elsewhere
+		gitx.RespectGitEnv = flagRespectGitEnv
+
+		// Limit git commands that may contact a remote (fetch, submodule update, ...)
+		if flagTimeout < 0 {
+			return fmt.Errorf("invalid --timeout: %s (must not be negative)", flagTimeout)
+		}
+		gitx.NetworkTimeout = flagTimeout
+
+		// Redirect configuration to an alternate file
+		if flagConfig != "" {
+			config.PathOverride = flagConfig
+		}
+		if flagStrict {
+			config.Strict = true
+		}
+
+		// Read the configuration at most once per command
+		cmd.SetContext(withConfigCache(cmd.Context()))
+
+		// Run git as if wt was started in another directory
+		if flagCwd != "" {
+			dir, err := resolveCwd(flagCwd)
+			if err != nil {
+				return err
+			}
+			cmd.SetContext(gitx.WithWorkDir(cmd.Context(), dir))
+		}
+
+		// Check if git command is available (wt doctor reports this itself; wt version and wt upgrade don't need it)
+		if cmd.Name() == "doctor" || cmd.Name() == "version" || cmd.Name() == "upgrade" {
+			return nil
+		}
 		if err := gitx.CheckGitInstalled(); err != nil {
 			return err
 		}
 
+		// Share the worktree list and repository information across the command's git calls
+		cmd.SetContext(gitx.WithSession(cmd.Context(), gitx.NewSession()))
+		applySlowFSMode(cmd.Context())
+
+		// Every command needs git worktree; newer features are checked by the commands using them.
+		// Plumbing commands skip the check: they run on every shell prompt and must stay fast.
+		if !isPlumbing(cmd) {
+			if err := gitx.RequireVersion(cmd.Context(), gitx.FeatureWorktree); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// Opt-in daily notice about new releases (update_check.enabled)
+		checkForUpdate(cmd)
+		// Remember the repository for the wrong-repository check of batch deletions (see confirmBatchRepo)
+		if !isPlumbing(cmd) && cmd.Context() != nil {
+			recordSessionRepo(cmd.Context())
+		}
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If no arguments, show help
 		if len(args) == 0 {
+			if jsonOutput() {
+				return &JSONUnsupportedError{Command: cmd.CommandPath()}
+			}
 			return cmd.Help()
 		}
 
@@ -118,9 +236,22 @@ func init() {
 	// This allows subcommands to handle their own arguments correctly
 	rootCmd.TraverseChildren = true
 
+	rootCmd.PersistentFlags().StringVarP(&flagCwd, "cwd", "C", "", "Run as if wt was started in this directory (like git -C)")
 	rootCmd.PersistentFlags().StringVar(&flagRepo, "repo", "", "Manually specify repository root path")
 	rootCmd.PersistentFlags().BoolVar(&flagQuiet, "quiet", false, "Minimal output")
+	rootCmd.PersistentFlags().BoolVar(&flagYes, "yes", false, "Answer yes to confirmation prompts (required to confirm when stdin is not a terminal)")
 	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Debug mode (show command execution)")
+	rootCmd.PersistentFlags().StringVar(&flagDebugLog, "debug-log", "", "Append a trace of external commands to this file (or set WT_DEBUG_LOG)")
+	rootCmd.PersistentFlags().StringVar(&flagConfig, "config", "", "Path to config file (overrides WT_CONFIG_FILE and ~/.config/wt/config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&flagStrict, "strict-config", false, "Treat unknown config keys as errors (or set WT_STRICT_CONFIG=1)")
+	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, `Output a single JSON document on stdout (errors as {"error": {"type", "message"}})`)
+	rootCmd.PersistentFlags().BoolVar(&flagPorcelainCd, "porcelain-cd", false, "Print \"path<TAB>branch\" instead of the path for --cd (used by the shell hooks)")
+	_ = rootCmd.PersistentFlags().MarkHidden("porcelain-cd")
+	rootCmd.PersistentFlags().BoolVar(&flagNoAlign, "no-align", false, "Print tables as tab-separated values (the default when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&flagRespectGitEnv, "respect-git-env", false, "Let GIT_DIR, GIT_WORK_TREE, GIT_INDEX_FILE and GIT_COMMON_DIR select the repository instead of ignoring them")
+	rootCmd.PersistentFlags().BoolVar(&flagNoCache, "no-cache", false, "Read the worktree list from git instead of the cache of performance.slow_fs_mode")
+	rootCmd.PersistentFlags().BoolVarP(&flagNull, "null", "z", false, "Terminate printed paths with NUL instead of a newline (--cd, path, root, list, clean)")
+	rootCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", gitx.DefaultNetworkTimeout, "Time limit for git operations that contact a remote (0 for no limit)")
 
 	// Disable interspersed flags to allow subcommand arguments that start with '-'
 	// This prevents arguments like "-wttt" from being interpreted as global flags
@@ -138,9 +269,12 @@ Git Worktree Passthrough:
     wt list              -> git worktree list
     wt add <path> <ref>  -> git worktree add <path> <ref>
     wt remove <path>     -> git worktree remove <path>
-    wt lock <path>       -> git worktree lock <path>
     wt prune             -> git worktree prune
-{{end}}`)
+
+` + exitCodeHelp + `{{end}}`)
+
+	// The passthrough supports --json for "wt list"
+	withJSON(rootCmd)
 
 	// Register subcommands
 	rootCmd.AddCommand(newCmd)
@@ -148,16 +282,23 @@ Git Worktree Passthrough:
 
 // Execute runs the root command
 func Execute() error {
-	if err := rootCmd.Execute(); err != nil {
+	markUsageErrors(rootCmd)
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
 		// Pass through to git worktree for unknown command/flag errors
 		if shouldPassthrough(err) {
 			if pe := passthroughToGitWorktree(rootCmd, os.Args[1:]); pe != nil {
+				if jsonOutput() {
+					printError(rootCmd.OutOrStdout(), rootCmd.ErrOrStderr(), pe)
+				}
 				return pe
 			}
 			return nil
 		}
-		fmt.Fprintln(rootCmd.ErrOrStderr(), err)
-		return err
+		// Plumbing commands report failures by the exit code only
+		if !isPlumbing(cmd) || flagDebug {
+			printError(rootCmd.OutOrStdout(), rootCmd.ErrOrStderr(), err)
+		}
+		return withExitCode(err)
 	}
 	return nil
 }
@@ -172,7 +313,7 @@ func shouldPassthrough(err error) bool {
 	// This prevents issues with arguments that look like flags (e.g., "-wttt")
 	if len(os.Args) > 1 {
 		subcommand := os.Args[1]
-		knownSubcommands := []string{"config", "new", "go", "clean", "pr", "open", "hook", "tmux"}
+		knownSubcommands := []string{"config", "new", "go", "clean", "pr", "open", "hook", "tmux", "completion", "doctor", "mv", "lock", "unlock", "repair", "version", "status", "upgrade", "each", "sync", "cp", "root", "path", "info", "prune-branches", "archive", "adopt", "current", "prune", "workspace", "log", "undo", "init", deleteCommand}
 		for _, known := range knownSubcommands {
 			if subcommand == known {
 				return false
@@ -203,13 +344,13 @@ func filterPassthroughArgs(args []string) []string {
 			return out
 
 		// Boolean persistent flags (do not forward)
-		case a == "--debug", a == "--quiet":
+		case a == "--debug", a == "--quiet", a == "--strict-config", a == "--json", a == "--porcelain-cd", a == "--null", a == "--no-align", a == "--no-cache", a == "--respect-git-env":
 			continue
 
 		// Value persistent flag forms
-		case strings.HasPrefix(a, "--repo="):
+		case strings.HasPrefix(a, "--repo="), strings.HasPrefix(a, "--config="), strings.HasPrefix(a, "--timeout="), strings.HasPrefix(a, "--cwd="), strings.HasPrefix(a, "--debug-log="):
 			continue
-		case a == "--repo":
+		case a == "--repo", a == "--config", a == "--timeout", a == "--cwd", a == "-C", a == "--debug-log":
 			// Skip the value token
 			skipNext = true
 			continue
@@ -221,6 +362,86 @@ func filterPassthroughArgs(args []string) []string {
 	return out
 }
 
+// setupDebugLog sets the file external commands are logged to: --debug-log, or WT_DEBUG_LOG
+func setupDebugLog() {
+	logx.Path = flagDebugLog
+	if logx.Path == "" {
+		logx.Path = os.Getenv(logx.EnvPath)
+	}
+}
+
+// resolveCwd returns the absolute path of the --cwd directory, checking that it is a directory
+func resolveCwd(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid --cwd: %w", err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return "", &UsageError{Err: fmt.Errorf("invalid --cwd: %w", err)}
+	}
+	if !info.IsDir() {
+		return "", &UsageError{Err: fmt.Errorf("invalid --cwd: %s is not a directory", absDir)}
+	}
+	return absDir, nil
+}
+
+// hasJSONFlag reports whether args contain --json before the end of flags ("--")
+func hasJSONFlag(args []string) bool {
+	return hasPassthroughFlag(args, "--json")
+}
+
+// hasPassthroughFlag reports whether args contain the boolean flag name before the end of flags ("--")
+func hasPassthroughFlag(args []string, name string) bool {
+	for _, a := range args {
+		if a == "--" {
+			return false
+		}
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// quietPassthroughArgs forwards --quiet to the git worktree subcommands that support it (add)
+// The others print nothing but their results, which --quiet keeps.
+func quietPassthroughArgs(args []string) []string {
+	if len(args) == 0 || args[0] != "add" || hasPassthroughFlag(args, "--quiet") || hasPassthroughFlag(args, "-q") {
+		return args
+	}
+	return append([]string{"add", "--quiet"}, args[1:]...)
+}
+
+// passthroughFlagValue returns the value of the first flag named one of names in args before "--"
+// Both "--flag value" and "--flag=value" are recognized.
+func passthroughFlagValue(args []string, names ...string) string {
+	for i, a := range args {
+		if a == "--" {
+			return ""
+		}
+		for _, name := range names {
+			if a == name && i+1 < len(args) {
+				return args[i+1]
+			}
+			if value, ok := strings.CutPrefix(a, name+"="); ok {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// gitWorktreeArgs returns the git arguments running git worktree with args (already filtered)
+// The repository given with --repo is forwarded as "git -C <repo>", since git itself doesn't know --repo.
+func gitWorktreeArgs(args []string, repo string) []string {
+	gitArgs := []string{"worktree"}
+	if repo != "" {
+		gitArgs = append([]string{"-C", repo}, gitArgs...)
+	}
+	return append(gitArgs, args...)
+}
+
 // passthroughToGitWorktree passes unknown commands to git worktree
 func passthroughToGitWorktree(cmd *cobra.Command, rawArgs []string) error {
 	// Resolve git path
@@ -228,23 +449,77 @@ func passthroughToGitWorktree(cmd *cobra.Command, rawArgs []string) error {
 	if err != nil {
 		return fmt.Errorf("git command not found: %w", err)
 	}
+	if err := gitx.RequireVersion(context.Background(), gitx.FeatureWorktree); err != nil {
+		return err
+	}
 
-	args := append([]string{"worktree"}, filterPassthroughArgs(rawArgs)...)
+	// Global flags after the git worktree subcommand aren't parsed by cobra
+	if hasJSONFlag(rawArgs) {
+		flagJSON = true
+	}
+	if hasPassthroughFlag(rawArgs, "--quiet") {
+		flagQuiet = true
+	}
+	if flagRepo == "" {
+		flagRepo = passthroughFlagValue(rawArgs, "--repo")
+	}
+	if flagCwd == "" {
+		flagCwd = passthroughFlagValue(rawArgs, "--cwd", "-C")
+	}
+	if flagDebugLog == "" {
+		flagDebugLog = passthroughFlagValue(rawArgs, "--debug-log")
+	}
+	if hasPassthroughFlag(rawArgs, "--null") {
+		flagNull = true
+	}
+	if hasPassthroughFlag(rawArgs, "--respect-git-env") {
+		flagRespectGitEnv = true
+	}
+	gitx.RespectGitEnv = flagRespectGitEnv
+	setupDebugLog()
+	if err := checkNullFlag(); err != nil {
+		return err
+	}
+	passArgs := filterPassthroughArgs(rawArgs)
+	if jsonOutput() {
+		return runListJSON(cmd, passArgs)
+	}
+	// "wt list -z" prints only the paths; "wt list --porcelain -z" is git's own format
+	if isNullList(passArgs) {
+		flagNull = true
+		return runListNull(cmd)
+	}
+
+	if flagQuiet {
+		passArgs = quietPassthroughArgs(passArgs)
+	}
+	args := gitWorktreeArgs(passArgs, flagRepo)
 
 	// Context that cancels on SIGINT/SIGTERM
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	c := exec.CommandContext(ctx, gitPath, args...)
+	if flagCwd != "" {
+		dir, err := resolveCwd(flagCwd)
+		if err != nil {
+			return err
+		}
+		c.Dir = dir
+	}
+	c.Env = gitx.Environ()
 	c.Stdin = cmd.InOrStdin()
-	c.Stdout = cmd.OutOrStdout()
+	c.Stdout = passthroughStdout(cmd.OutOrStdout(), passArgs)
 	c.Stderr = cmd.ErrOrStderr()
 
 	if flagDebug {
 		fmt.Fprintf(cmd.ErrOrStderr(), "+ %s %s\n", gitPath, strings.Join(args, " "))
 	}
 
-	if err := c.Run(); err != nil {
+	start := time.Now()
+	err = c.Run()
+	logx.LogCommand(logx.Command{Name: "git", Args: args, Dir: c.Dir, Duration: time.Since(start), Err: err})
+	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			return &ExitCodeError{Code: exitErr.ExitCode(), Err: err}
@@ -254,3 +529,119 @@ func passthroughToGitWorktree(cmd *cobra.Command, rawArgs []string) error {
 
 	return nil
 }
+
+// passthroughStdout returns the writer for the output of the git worktree command passArgs
+// On a terminal, a plain "wt list" shows the index the selection lists and --index use (one line per
+// worktree without --verbose). Redirected, the output stays git's for scripts that parse it.
+func passthroughStdout(w io.Writer, passArgs []string) io.Writer {
+	if len(passArgs) == 1 && passArgs[0] == "list" && isTerminalOutput(w) {
+		return &indexedLineWriter{w: w}
+	}
+	return w
+}
+
+// indexedLineWriter prefixes each line written to w with its index, e.g. "[0] "
+type indexedLineWriter struct {
+	w       io.Writer
+	index   int
+	midLine bool
+}
+
+func (iw *indexedLineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if !iw.midLine {
+			if _, err := fmt.Fprintf(iw.w, "[%d] ", iw.index); err != nil {
+				return 0, err
+			}
+			iw.index++
+			iw.midLine = true
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			iw.midLine = false
+		}
+		if _, err := iw.w.Write(line); err != nil {
+			return 0, err
+		}
+		p = p[len(line):]
+	}
+	return n, nil
+}
+
+// isNullList reports whether args ask for the NUL-terminated paths of "wt list --null" (or -z)
+func isNullList(args []string) bool {
+	if !flagNull && !hasPassthroughFlag(args, "-z") {
+		return false
+	}
+	var rest []string
+	for _, a := range args {
+		if a != "-z" {
+			rest = append(rest, a)
+		}
+	}
+	return len(rest) == 1 && rest[0] == "list"
+}
+
+// runListJSON prints the worktrees as JSON for "wt list --json"
+// git worktree has no JSON output, so other passthrough commands are refused.
+func runListJSON(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return &JSONUnsupportedError{Command: "wt " + strings.Join(args, " ")}
+	}
+
+	worktrees, err := listPassthroughWorktrees(cmd)
+	if err != nil {
+		return err
+	}
+
+	result := make([]worktreeJSON, len(worktrees))
+	for i, wt := range worktrees {
+		result[i] = newWorktreeJSON(wt)
+	}
+	return writeJSON(cmd.OutOrStdout(), result)
+}
+
+// runListNull prints the worktree paths, each terminated by NUL, for "wt list --null"
+func runListNull(cmd *cobra.Command) error {
+	worktrees, err := listPassthroughWorktrees(cmd)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		printPath(cmd.OutOrStdout(), wt.Path)
+	}
+	return nil
+}
+
+// listPassthroughWorktrees returns the worktrees "wt list" shows, honoring -C and --repo
+func listPassthroughWorktrees(cmd *cobra.Command) ([]gitx.Worktree, error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := gitx.CheckGitInstalled(); err != nil {
+		return nil, err
+	}
+	if flagCwd != "" {
+		dir, err := resolveCwd(flagCwd)
+		if err != nil {
+			return nil, err
+		}
+		ctx = gitx.WithWorkDir(ctx, dir)
+	}
+	if flagRepo != "" {
+		// List the worktrees of the --repo repository, like git -C
+		repo, err := gitx.AbsPath(ctx, flagRepo)
+		if err != nil {
+			return nil, err
+		}
+		ctx = gitx.WithWorkDir(ctx, repo)
+	}
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	return worktrees, nil
+}
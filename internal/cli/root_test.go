@@ -1,9 +1,17 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
 )
 
 func TestExitCodeError(t *testing.T) {
@@ -107,6 +115,21 @@ func TestFilterPassthroughArgs(t *testing.T) {
 			args: []string{"--quiet", "list"},
 			want: []string{"list"},
 		},
+		{
+			name: "remove cwd flag",
+			args: []string{"-C", "../repo", "list", "--cwd=/src/repo"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove debug-log flag",
+			args: []string{"--debug-log", "/tmp/wt.log", "list", "--debug-log=/tmp/wt.log"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove json flag",
+			args: []string{"list", "--json"},
+			want: []string{"list"},
+		},
 		{
 			name: "remove repo flag with value",
 			args: []string{"list", "--repo", "/path/to/repo"},
@@ -117,6 +140,41 @@ func TestFilterPassthroughArgs(t *testing.T) {
 			args: []string{"list", "--repo=/path/to/repo"},
 			want: []string{"list"},
 		},
+		{
+			name: "remove config flag with value",
+			args: []string{"--config", "/tmp/wt.yaml", "list"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove config flag with equals",
+			args: []string{"list", "--config=/tmp/wt.yaml"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove strict-config flag",
+			args: []string{"list", "--strict-config"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove timeout flag with value",
+			args: []string{"list", "--timeout", "30s"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove timeout flag with equals",
+			args: []string{"--timeout=1m", "list"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove porcelain-cd flag",
+			args: []string{"--porcelain-cd", "list"},
+			want: []string{"list"},
+		},
+		{
+			name: "remove null flag but keep git's -z",
+			args: []string{"--null", "list", "--porcelain", "-z"},
+			want: []string{"list", "--porcelain", "-z"},
+		},
 		{
 			name: "keep other flags",
 			args: []string{"list", "--porcelain", "-v"},
@@ -149,6 +207,60 @@ func TestFilterPassthroughArgs(t *testing.T) {
 	}
 }
 
+func TestPrintCdPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		porcelain bool
+		null      bool
+		branch    string
+		want      string
+	}{
+		{name: "path only", branch: "feature", want: "/src/proj-feature\n"},
+		{name: "porcelain", porcelain: true, branch: "feature", want: "/src/proj-feature\tfeature\n"},
+		{name: "porcelain detached", porcelain: true, want: "/src/proj-feature\t\n"},
+		{name: "null", null: true, branch: "feature", want: "/src/proj-feature\x00"},
+		{name: "porcelain null", porcelain: true, null: true, branch: "feature", want: "/src/proj-feature\tfeature\x00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig, origNull := flagPorcelainCd, flagNull
+			flagPorcelainCd, flagNull = tt.porcelain, tt.null
+			t.Cleanup(func() { flagPorcelainCd, flagNull = orig, origNull })
+
+			var buf bytes.Buffer
+			printCdPath(&buf, "/src/proj-feature", tt.branch)
+			if buf.String() != tt.want {
+				t.Errorf("printCdPath() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNullList(t *testing.T) {
+	tests := []struct {
+		args     []string
+		flagNull bool
+		want     bool
+	}{
+		{args: []string{"list", "-z"}, want: true},
+		{args: []string{"-z", "list"}, want: true},
+		{args: []string{"list"}, flagNull: true, want: true},
+		{args: []string{"list"}, want: false},
+		{args: []string{"list", "--porcelain", "-z"}, want: false}, // git's own format
+		{args: []string{"prune", "-z"}, want: false},
+	}
+
+	for _, tt := range tests {
+		orig := flagNull
+		flagNull = tt.flagNull
+		if got := isNullList(tt.args); got != tt.want {
+			t.Errorf("isNullList(%q) with flagNull=%v = %v, want %v", tt.args, tt.flagNull, got, tt.want)
+		}
+		flagNull = orig
+	}
+}
+
 func TestSetVersionInfo(t *testing.T) {
 	// Save original values
 	origVersion := versionInfo
@@ -192,3 +304,219 @@ func TestSetVersionInfo(t *testing.T) {
 		t.Errorf("rootCmd.Version should contain date %q, got %q", date, rootCmd.Version)
 	}
 }
+
+func TestResolveCwd(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := resolveCwd(dir)
+	if err != nil || got != dir {
+		t.Errorf("resolveCwd(%q) = %q, %v, want %q", dir, got, err, dir)
+	}
+
+	for _, bad := range []string{file, filepath.Join(dir, "missing")} {
+		if _, err := resolveCwd(bad); exitCode(err) != ExitUsage {
+			t.Errorf("resolveCwd(%q) error = %v, want a usage error", bad, err)
+		}
+	}
+}
+
+func TestResolveAndValidateBaseDirWorkDir(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "base"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := gitx.WithWorkDir(context.Background(), dir)
+	got, err := resolveAndValidateBaseDir(ctx, "base", "/default")
+	if err != nil {
+		t.Fatalf("resolveAndValidateBaseDir() error = %v", err)
+	}
+	if want := filepath.Join(dir, "base"); got != want {
+		t.Errorf("resolveAndValidateBaseDir() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveAndValidateBaseDirConfigured(t *testing.T) {
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("WT_BASE_DIR", "")
+	os.Unsetenv("WT_BASE_DIR")
+	repoPath := setupTestRepo(t)
+
+	ctx := context.Background()
+	repo, err := gitx.GetRepo(ctx, "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+
+	// Unset: the repository's parent
+	got, err := resolveAndValidateBaseDir(ctx, "", repo.Parent)
+	if err != nil || got != repo.Parent {
+		t.Errorf("resolveAndValidateBaseDir() without worktree.base_dir = %q, %v, want %q", got, err, repo.Parent)
+	}
+
+	// Set: expanded and created on first use
+	t.Setenv("WT_BASE_DIR", "~/worktrees")
+	want := filepath.Join(home, "worktrees")
+	got, err = resolveAndValidateBaseDir(ctx, "", repo.Parent)
+	if err != nil {
+		t.Fatalf("resolveAndValidateBaseDir() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("resolveAndValidateBaseDir() = %q, want %q", got, want)
+	}
+	info, err := os.Stat(want)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("base directory was not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&^0755 != 0 {
+		t.Errorf("base directory mode = %v, want at most 0755", info.Mode().Perm())
+	}
+
+	// The container of subdirectory mode moves below the base directory
+	path, err := generateWorktreePath(ctx, strings.NewReader(""), &bytes.Buffer{}, got, repo.Name, "feature/login", "")
+	if err != nil {
+		t.Fatalf("generateWorktreePath() returned error: %v", err)
+	}
+	if wantPath := filepath.Join(want, ".test-repo-wt", "feature-login"); path != wantPath {
+		t.Errorf("generateWorktreePath() = %q, want %q", path, wantPath)
+	}
+
+	// --base-dir takes precedence, and is not created
+	flagDir := filepath.Join(repoPath, "..")
+	if got, err := resolveAndValidateBaseDir(ctx, flagDir, repo.Parent); err != nil || got != repo.Parent {
+		t.Errorf("resolveAndValidateBaseDir(--base-dir) = %q, %v, want %q", got, err, repo.Parent)
+	}
+	missing := filepath.Join(home, "missing")
+	if _, err := resolveAndValidateBaseDir(ctx, missing, repo.Parent); err == nil {
+		t.Errorf("resolveAndValidateBaseDir(--base-dir %s) error = nil, want error for a missing directory", missing)
+	}
+	if pathExists(missing) {
+		t.Errorf("--base-dir should not be created")
+	}
+}
+
+func TestGitWorktreeArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "no repo",
+			args: []string{"list"},
+			want: []string{"worktree", "list"},
+		},
+		{
+			name: "repo flag with value",
+			args: []string{"--repo", "/path/to/repo", "list"},
+			want: []string{"-C", "/path/to/repo", "worktree", "list"},
+		},
+		{
+			name: "repo flag with equals",
+			args: []string{"list", "--repo=/path/to/repo", "--porcelain"},
+			want: []string{"-C", "/path/to/repo", "worktree", "list", "--porcelain"},
+		},
+		{
+			name: "double dash stops stripping",
+			args: []string{"--repo", "/path/to/repo", "add", "--", "--repo", "x"},
+			want: []string{"-C", "/path/to/repo", "worktree", "add", "--repo", "x"},
+		},
+		{
+			name: "repo flag after double dash is not a repo",
+			args: []string{"add", "--", "--repo=x"},
+			want: []string{"worktree", "add", "--repo=x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := passthroughFlagValue(tt.args, "--repo")
+			got := gitWorktreeArgs(filterPassthroughArgs(tt.args), repo)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gitWorktreeArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassthroughFlagValue(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"-C", "dir", "list"}, want: "dir"},
+		{args: []string{"list", "--cwd=dir"}, want: "dir"},
+		{args: []string{"list", "--cwd"}, want: ""},
+		{args: []string{"list"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := passthroughFlagValue(tt.args, "--cwd", "-C"); got != tt.want {
+			t.Errorf("passthroughFlagValue(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestQuietPassthroughArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"add", "../feature", "main"}, want: []string{"add", "--quiet", "../feature", "main"}},
+		{args: []string{"add", "-q", "../feature"}, want: []string{"add", "-q", "../feature"}},
+		{args: []string{"list"}, want: []string{"list"}},
+		{args: []string{"remove", "../feature"}, want: []string{"remove", "../feature"}},
+		{args: []string{}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := quietPassthroughArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("quietPassthroughArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestPassthroughStdout(t *testing.T) {
+	orig := isTerminalOutput
+	t.Cleanup(func() { isTerminalOutput = orig })
+	var out bytes.Buffer
+
+	isTerminalOutput = func(io.Writer) bool { return true }
+	if _, ok := passthroughStdout(&out, []string{"list"}).(*indexedLineWriter); !ok {
+		t.Error("wt list on a terminal should show indexes")
+	}
+	if passthroughStdout(&out, []string{"list", "--porcelain"}) != io.Writer(&out) {
+		t.Error("wt list --porcelain should be passed through unchanged")
+	}
+
+	isTerminalOutput = func(io.Writer) bool { return false }
+	if passthroughStdout(&out, []string{"list"}) != io.Writer(&out) {
+		t.Error("redirected wt list should be passed through unchanged")
+	}
+}
+
+func TestIndexedLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &indexedLineWriter{w: &out}
+	for _, chunk := range []string{"/work/repo  abc123 [main]\n/work/.repo", "-wt/fix  def456 [fix]\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write() returned error: %v", err)
+		}
+	}
+	want := "[0] /work/repo  abc123 [main]\n[1] /work/.repo-wt/fix  def456 [fix]\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
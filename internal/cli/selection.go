@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// selectOptions controls how selectItems selects worktrees (or other items)
+type selectOptions struct {
+	prompt string
+	match  matchOptions // How the query is matched, and --no-fzf
+	multi  bool         // Let the user select any number of items
+	index  *int         // Select the item with this index (see formatDisplayItem) without asking
+}
+
+// addSelectionFlags registers the flags of commands that select worktrees: the query matching flags and --no-fzf
+func addSelectionFlags(cmd *cobra.Command, opts *matchOptions) {
+	addMatchFlags(cmd, opts)
+	cmd.Flags().BoolVar(&opts.noFzf, "no-fzf", false, "Select from a numbered list instead of the fuzzy finder")
+}
+
+// selectItems selects items the way every worktree picker does, and returns their positions in items
+//   - With an index, the item with that index is selected
+//   - With a query, a single matching item is selected without asking; several are offered with
+//     the query already typed into the finder
+//   - Otherwise, all items are offered
+//
+// Unless opts.multi, exactly one position is returned.
+func selectItems(ctx context.Context, r io.Reader, w io.Writer, items []string, query string, opts selectOptions) ([]int, error) {
+	if opts.index != nil {
+		return selectByIndex(items, *opts.index)
+	}
+	if query == "" {
+		return pickItems(ctx, r, w, items, "", opts)
+	}
+
+	filtered, err := selectx.FilterIndexedByQuery(items, query, opts.match.useFuzzy())
+	if err != nil {
+		return nil, newNoMatchError(query, items)
+	}
+	if len(filtered) == 1 {
+		return []int{filtered[0].Index}, nil
+	}
+
+	filteredItems := make([]string, len(filtered))
+	for i, f := range filtered {
+		filteredItems[i] = f.Text
+	}
+	// Start the finder with the query, so that it doesn't have to be typed again
+	selected, err := pickItems(ctx, r, w, filteredItems, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(selected))
+	for i, idx := range selected {
+		indexes[i] = filtered[idx].Index
+	}
+	return indexes, nil
+}
+
+// selectItem selects a single item with selectItems
+func selectItem(ctx context.Context, r io.Reader, w io.Writer, items []string, query string, opts selectOptions) (int, error) {
+	opts.multi = false
+	selected, err := selectItems(ctx, r, w, items, query, opts)
+	if err != nil {
+		return 0, err
+	}
+	return selected[0], nil
+}
+
+// selectByIndex returns the position of the item with index (see formatDisplayItem)
+func selectByIndex(items []string, index int) ([]int, error) {
+	maxIndex := 0
+	for i, item := range items {
+		itemIndex, _, ok := selectx.SplitIndex(item)
+		if !ok {
+			continue
+		}
+		if itemIndex == index {
+			return []int{i}, nil
+		}
+		maxIndex = max(maxIndex, itemIndex)
+	}
+	return nil, &IndexOutOfRangeError{Index: index, Max: maxIndex}
+}
+
+// pickItems lets the user select from items with the configured fuzzy finder, or a numbered list read from r
+// The numbered list uses the worktree indexes of the items (see formatDisplayItem) if they have them.
+func pickItems(ctx context.Context, r io.Reader, w io.Writer, items []string, initialQuery string, opts selectOptions) ([]int, error) {
+	selectOpts := selectx.SelectOptions{Prompt: opts.prompt, InitialQuery: initialQuery, Indexed: true}
+	selector := configuredSelector(ctx)
+	useFinder := !opts.match.noFzf && selectx.IsSelectorAvailable(selector)
+
+	if opts.multi {
+		if useFinder {
+			return selectx.SelectMultipleWith(selector, items, selectOpts)
+		}
+		return selectx.SelectMultipleWithPrompt(r, w, items, selectOpts)
+	}
+
+	var idx int
+	var err error
+	if useFinder {
+		idx, err = selectx.SelectWith(selector, items, selectOpts)
+	} else {
+		idx, err = selectx.SelectWithPrompt(r, w, items, selectOpts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []int{idx}, nil
+}
+
+// configuredSelector returns the fuzzy finder set in the selector section of the configuration
+// Falls back to the default selector if the configuration can't be loaded.
+func configuredSelector(ctx context.Context) selectx.Selector {
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return selectx.DefaultSelector()
+	}
+	return selectx.Selector{Binary: cfg.GetSelectorBinary(), ExtraArgs: cfg.GetSelectorExtraArgs()}
+}
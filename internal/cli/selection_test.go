@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestSelectItems(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WT_SELECTOR_BINARY", "wt-test-missing-finder") // Use the numbered selection
+
+	items := createDisplayItems([]gitx.Worktree{
+		{Branch: "main", Path: "/work/myproject", Index: 0},
+		{Branch: "feature/a", Path: "/work/.myproject-wt/feature-a", Index: 1},
+		{Branch: "fix/b", Path: "/work/.myproject-wt/fix-b", Index: 2},
+		{Branch: "feature/c", Path: "/work/.myproject-wt/feature-c", Index: 3},
+	})
+	two := 2
+
+	tests := []struct {
+		name       string
+		query      string
+		input      string
+		opts       selectOptions
+		want       []int
+		wantPrompt string // "" if the selection must not ask
+	}{
+		{name: "single match is selected without asking", query: "fix", want: []int{2}},
+		{name: "index as query", query: "2", want: []int{2}},
+		{name: "index", opts: selectOptions{index: &two}, want: []int{2}},
+		{name: "multiple matches are offered with the query", query: "feature", input: "3\n", want: []int{3}, wantPrompt: `Select worktree (matching "feature"):`},
+		{name: "without query", input: "1\n", want: []int{1}, wantPrompt: "Select worktree:"},
+		{name: "multi without query", input: "0,2-3\n", opts: selectOptions{multi: true}, want: []int{0, 2, 3}, wantPrompt: "Select worktree"},
+		{name: "multi query narrows to a range", query: "feature", input: "1-3\n", opts: selectOptions{multi: true}, want: []int{1, 3}, wantPrompt: "feature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := tt.opts
+			opts.prompt = "Select worktree"
+			got, err := selectItems(context.Background(), strings.NewReader(tt.input), &out, items, tt.query, opts)
+			if err != nil {
+				t.Fatalf("selectItems() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectItems() = %v, want %v", got, tt.want)
+			}
+			if tt.wantPrompt == "" && out.Len() != 0 {
+				t.Errorf("selectItems() prompted: %q", out.String())
+			}
+			if tt.wantPrompt != "" && !strings.Contains(out.String(), tt.wantPrompt) {
+				t.Errorf("selectItems() output = %q, want %q", out.String(), tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestSelectItemsErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WT_SELECTOR_BINARY", "wt-test-missing-finder")
+
+	items := createDisplayItems([]gitx.Worktree{
+		{Branch: "main", Path: "/work/myproject", Index: 1},
+		{Branch: "feature-auth-login", Path: "/work/.myproject-wt/feature-auth-login", Index: 2},
+	})
+
+	// Fuzzy matching applies unless --no-fuzzy
+	if idx, err := selectItem(context.Background(), strings.NewReader(""), io.Discard, items, "falogin", selectOptions{}); err != nil || idx != 1 {
+		t.Errorf("selectItem(falogin) = %d, %v, want 1", idx, err)
+	}
+	_, err := selectItem(context.Background(), strings.NewReader(""), io.Discard, items, "falogin", selectOptions{match: matchOptions{noFuzzy: true}})
+	var noMatch *NoMatchError
+	if !errors.As(err, &noMatch) {
+		t.Errorf("selectItem() with --no-fuzzy error = %v, want NoMatchError", err)
+	}
+
+	for _, index := range []int{0, 3} {
+		_, err := selectItem(context.Background(), strings.NewReader(""), io.Discard, items, "", selectOptions{index: &index})
+		var rangeErr *IndexOutOfRangeError
+		if !errors.As(err, &rangeErr) || rangeErr.Max != 2 {
+			t.Errorf("selectItem(index %d) error = %v, want IndexOutOfRangeError with max 2", index, err)
+		}
+	}
+
+	_, err = selectItems(context.Background(), strings.NewReader("q\n"), io.Discard, items, "", selectOptions{multi: true})
+	var cancelled *selectx.CancelledError
+	if !errors.As(err, &cancelled) {
+		t.Errorf("selectItems() error = %v, want CancelledError", err)
+	}
+}
+
+func TestPickItemsNoFzf(t *testing.T) {
+	// A finder that would select the second item without asking
+	dir := t.TempDir()
+	finder := filepath.Join(dir, "finder")
+	if err := os.WriteFile(finder, []byte("#!/bin/sh\nsed -n 2p\n"), 0755); err != nil {
+		t.Fatalf("Failed to write finder: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WT_SELECTOR_BINARY", finder)
+
+	items := []string{"one", "two", "three"}
+	got, err := pickItems(context.Background(), strings.NewReader(""), io.Discard, items, "", selectOptions{})
+	if err != nil || !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("pickItems() = %v, %v, want the finder's selection [1]", got, err)
+	}
+
+	var out bytes.Buffer
+	got, err = pickItems(context.Background(), strings.NewReader("3\n"), &out, items, "", selectOptions{prompt: "Select", match: matchOptions{noFzf: true}})
+	if err != nil || !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("pickItems() with --no-fzf = %v, %v, want the numbered selection [2]", got, err)
+	}
+	if !strings.Contains(out.String(), "Select") {
+		t.Errorf("pickItems() with --no-fzf should show the numbered list, output: %q", out.String())
+	}
+}
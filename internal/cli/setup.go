@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// setupOptions holds the flags controlling the setup of newly created worktrees
+type setupOptions struct {
+	submodules   bool
+	noSubmodules bool
+}
+
+// addSetupFlags registers the worktree setup flags on cmd
+func addSetupFlags(cmd *cobra.Command, opts *setupOptions) {
+	cmd.Flags().BoolVar(&opts.submodules, "submodules", false, "Initialize submodules in the new worktree (overrides worktree.init_submodules)")
+	cmd.Flags().BoolVar(&opts.noSubmodules, "no-submodules", false, "Don't initialize submodules in the new worktree (overrides worktree.init_submodules)")
+	cmd.MarkFlagsMutuallyExclusive("submodules", "no-submodules")
+}
+
+// setupWorktree initializes submodules and pulls LFS objects in a newly created worktree
+// Progress is written to w (stderr, so that --cd output stays clean), and left out with --quiet or --json.
+func setupWorktree(ctx context.Context, w io.Writer, path string, opts setupOptions) error {
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	quiet := flagQuiet || jsonOutput()
+	progress := w
+	if quiet {
+		progress = io.Discard
+	}
+
+	initSubmodules := wtCfg.GetInitSubmodules()
+	if opts.submodules {
+		initSubmodules = true
+	} else if opts.noSubmodules {
+		initSubmodules = false
+	}
+
+	if initSubmodules && gitx.HasSubmodules(path) {
+		if !quiet {
+			fmt.Fprintf(w, "Initializing submodules...\n")
+		}
+		if err := gitx.UpdateSubmodules(ctx, path, progress); err != nil {
+			return fmt.Errorf("worktree created at %s, but failed to initialize submodules: %w", path, err)
+		}
+	}
+
+	if wtCfg.GetLFSPull() && gitx.UsesLFS(path) {
+		if !gitx.IsLFSAvailable() {
+			// Only complain when LFS pulls were explicitly requested
+			if wtCfg.Source("worktree.lfs_pull") != config.SourceDefault {
+				fmt.Fprintf(w, "Warning: git-lfs not found; skipping git lfs pull\n")
+			}
+			return nil
+		}
+		if !quiet {
+			fmt.Fprintf(w, "Pulling LFS objects...\n")
+		}
+		if err := gitx.LFSPull(ctx, path, progress); err != nil {
+			return fmt.Errorf("worktree created at %s, but failed to pull LFS objects: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunNewInitializesSubmodules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// Local file:// submodules are blocked by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	libPath := setupTestRepo(t)
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "submodule", "add", libPath, "lib")
+	runGitForTest(t, repoPath, "commit", "-m", "Add submodule")
+
+	tests := []struct {
+		name    string
+		env     string
+		setup   setupOptions
+		quiet   bool
+		wantLib bool
+	}{
+		{name: "default", wantLib: false},
+		{name: "config", env: "true", wantLib: true},
+		{name: "flag", setup: setupOptions{submodules: true}, wantLib: true},
+		{name: "flag overrides config", env: "true", setup: setupOptions{noSubmodules: true}, wantLib: false},
+		{name: "quiet", setup: setupOptions{submodules: true}, quiet: true, wantLib: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WT_INIT_SUBMODULES", tt.env)
+			if tt.env == "" {
+				os.Unsetenv("WT_INIT_SUBMODULES")
+			}
+			if tt.quiet {
+				quietForTest(t)
+			}
+
+			var out, errOut bytes.Buffer
+			cmd := newNewCmd()
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+			cmd.SetContext(context.Background())
+			branch := "feature-" + string(rune('a'+i))
+			if err := runNewWithConfig(cmd, []string{branch}, &newCmdConfig{setup: tt.setup}); err != nil {
+				t.Fatalf("runNewWithConfig() returned error: %v\n%s", err, errOut.String())
+			}
+
+			wtPath := strings.TrimPrefix(strings.TrimSpace(findLine(out.String(), "Path: ")), "Path: ")
+			_, err := os.Stat(filepath.Join(wtPath, "lib", "README.md"))
+			if gotLib := err == nil; gotLib != tt.wantLib {
+				t.Errorf("submodule checked out = %v, want %v", gotLib, tt.wantLib)
+			}
+			if tt.quiet {
+				if errOut.Len() != 0 {
+					t.Errorf("expected no progress with --quiet, got: %q", errOut.String())
+				}
+			} else if tt.wantLib && !strings.Contains(errOut.String(), "Initializing submodules") {
+				t.Errorf("progress should go to stderr, got: %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestSetupWorktreeLFSUnavailable(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir()) // git-lfs cannot be found
+
+	dir := t.TempDir()
+	attrs := "*.bin filter=lfs diff=lfs merge=lfs -text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+
+	// Skipped silently when lfs_pull is only the default
+	var buf bytes.Buffer
+	if err := setupWorktree(context.Background(), &buf, dir, setupOptions{}); err != nil {
+		t.Fatalf("setupWorktree() returned error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got: %q", buf.String())
+	}
+
+	// Warns when lfs_pull was explicitly requested
+	t.Setenv("WT_LFS_PULL", "true")
+	buf.Reset()
+	if err := setupWorktree(context.Background(), &buf, dir, setupOptions{}); err != nil {
+		t.Fatalf("setupWorktree() returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "git-lfs not found") {
+		t.Errorf("expected a git-lfs warning, got: %q", buf.String())
+	}
+}
+
+// findLine returns the first line of s containing substr
+func findLine(s, substr string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.Contains(line, substr) {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+// applySlowFSMode configures git for repositories on slow network filesystems (performance.slow_fs_mode):
+// local git commands are limited to performance.git_timeout, and the worktree list is cached in the
+// state directory for a few seconds unless --no-cache is given
+func applySlowFSMode(ctx context.Context) {
+	gitx.LocalTimeout = 0
+	gitx.ListCacheDir = ""
+	cfg, err := loadWorktreeConfig(ctx)
+	if err != nil || !cfg.GetSlowFSMode() {
+		return
+	}
+
+	gitx.LocalTimeout = cfg.GetGitTimeout()
+	if dir := stateDir(); dir != "" && !flagNoCache {
+		gitx.ListCacheDir = filepath.Join(dir, "cache")
+	}
+}
+
+// slowFSMode reports whether performance.slow_fs_mode is enabled
+// Decorations that run git in every worktree (e.g. the dirty markers of wt clean) are skipped then.
+func slowFSMode(ctx context.Context) bool {
+	cfg, err := loadWorktreeConfig(ctx)
+	return err == nil && cfg.GetSlowFSMode()
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestApplySlowFSMode(t *testing.T) {
+	setupTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	origNoCache := flagNoCache
+	t.Cleanup(func() {
+		flagNoCache = origNoCache
+		gitx.LocalTimeout, gitx.ListCacheDir = 0, ""
+	})
+
+	t.Setenv("WT_SLOW_FS_MODE", "false")
+	applySlowFSMode(context.Background())
+	if gitx.LocalTimeout != 0 || gitx.ListCacheDir != "" {
+		t.Errorf("without slow_fs_mode: timeout = %s, cache = %q, want neither", gitx.LocalTimeout, gitx.ListCacheDir)
+	}
+
+	t.Setenv("WT_SLOW_FS_MODE", "true")
+	t.Setenv("WT_GIT_TIMEOUT", "3s")
+	applySlowFSMode(context.Background())
+	if want := filepath.Join(stateHome, "wt", "cache"); gitx.LocalTimeout != 3*time.Second || gitx.ListCacheDir != want {
+		t.Errorf("with slow_fs_mode: timeout = %s, cache = %q, want 3s and %q", gitx.LocalTimeout, gitx.ListCacheDir, want)
+	}
+
+	// --no-cache keeps the timeout but reads the worktree list from git
+	flagNoCache = true
+	applySlowFSMode(context.Background())
+	if gitx.LocalTimeout != 3*time.Second || gitx.ListCacheDir != "" {
+		t.Errorf("with --no-cache: timeout = %s, cache = %q, want 3s and no cache", gitx.LocalTimeout, gitx.ListCacheDir)
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/toritori0318/git-wt/internal/archive"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/trash"
+)
+
+// staleDir is a directory at the path of a new worktree that git doesn't know as a worktree,
+// e.g. left behind by a removal that crashed after git forgot the worktree
+type staleDir struct {
+	Path string
+	Size int64
+	// OldWorktree is set if the directory is a linked worktree of this repository whose
+	// registration is gone: its files can be reused for the new worktree
+	OldWorktree bool
+}
+
+// forceWorktreePath returns the path for a new worktree of branch with wt new --force
+// Unlike generateWorktreePath, a taken path isn't replaced with a numbered one: a registration whose
+// directory is gone is pruned, and an unregistered directory is returned as stale, to be reused or
+// moved to the trash by the caller. A path used by a live worktree stays a *naming.CollisionError.
+func forceWorktreePath(ctx context.Context, baseDir, repoName, branch string) (string, *staleDir, error) {
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	registered := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		registered[i] = wt.Path
+	}
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	strict := *wtCfg
+	_ = strict.SetCollisionStrategy(config.CollisionStrategyError)
+
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, repoName, branch, registered, &strict)
+	var collision *naming.CollisionError
+	if !errors.As(err, &collision) {
+		return path, nil, err
+	}
+	path = collision.Path
+
+	if collision.Registered {
+		if pathExists(path) {
+			return "", nil, collision // A live worktree: never touched
+		}
+		if err := gitx.Prune(ctx); err != nil {
+			return "", nil, fmt.Errorf("failed to prune worktrees: %w", err)
+		}
+		if pathExists(path) {
+			return "", nil, collision
+		}
+		return path, nil, nil
+	}
+
+	stale := &staleDir{Path: path, Size: dirSize(path), OldWorktree: isOldWorktreeDir(ctx, path)}
+	return path, stale, nil
+}
+
+// pruneStaleBranchWorktree prunes the registration of the worktree branch is checked out in if its
+// directory is gone, so that the branch can be checked out again. A live worktree is left alone.
+func pruneStaleBranchWorktree(ctx context.Context, branch string) error {
+	wt, err := gitx.FindWorktreeByBranch(ctx, branch)
+	if err != nil {
+		return fmt.Errorf("failed to search worktrees: %w", err)
+	}
+	if wt == nil || !wt.IsPrunable || wt.IsLocked {
+		return nil
+	}
+	if err := gitx.Prune(ctx); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+	return nil
+}
+
+// confirmStaleDir asks whether to reuse or trash a stale directory (--yes accepts)
+// Declining returns the collision the directory caused without --force.
+func confirmStaleDir(ctx context.Context, r io.Reader, w io.Writer, stale *staleDir, reuse bool) error {
+	kind := "not a worktree of this repository"
+	if stale.OldWorktree {
+		kind = "an old worktree of this repository"
+	}
+	fmt.Fprintf(w, "%s exists but is not a registered worktree (%s, %s)\n", stale.Path, kind, archive.FormatSize(stale.Size))
+
+	if flagYes {
+		return nil
+	}
+	question := "Move it to the trash and create the worktree there?"
+	if reuse {
+		question = "Reuse its files for the new worktree? (differences show up as uncommitted changes)"
+	}
+	confirmed, err := confirmWith(ctx, r, w, question, "--yes")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return &naming.CollisionError{Path: stale.Path}
+	}
+	return nil
+}
+
+// trashStaleDir moves a stale directory to the trash, so that it can still be recovered from there
+func trashStaleDir(stale *staleDir) error {
+	if _, err := trash.Move(stale.Path, nil); err != nil {
+		return fmt.Errorf("failed to move %s to the trash (remove it yourself): %w", stale.Path, err)
+	}
+	return nil
+}
+
+// isOldWorktreeDir reports whether path is a linked worktree of the repository of ctx,
+// i.e. its .git file points into <git common dir>/worktrees
+func isOldWorktreeDir(ctx context.Context, path string) bool {
+	if !isLinkedWorktreeDir(path) {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return false
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return false
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+	commonDir, err := gitx.CommonDir(ctx, "")
+	if err != nil {
+		return false
+	}
+	return gitx.SamePath(filepath.Dir(gitDir), filepath.Join(commonDir, "worktrees"))
+}
+
+// dirSize returns the total size of the files under path (what can be read of it)
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// latestStash is the stash entry wt new --from-stash uses without a value
+const latestStash = "stash@{0}"
+
+// StashNotFoundError represents an error when no stash entry matches --from-stash
+type StashNotFoundError struct {
+	Query string // Empty if there are no stash entries at all
+}
+
+func (e *StashNotFoundError) Error() string {
+	if e.Query == "" {
+		return "no stash entries found"
+	}
+	return fmt.Sprintf("no matching stash entry found: %s", e.Query)
+}
+
+// resolveStash returns the stash entry query refers to: a ref such as "stash@{1}", its index ("1"),
+// or text from the stash message. If text matches several entries, they are offered for selection.
+func resolveStash(ctx context.Context, r io.Reader, w io.Writer, query string) (*gitx.Stash, error) {
+	stashes, err := gitx.ListStashes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stash entries: %w", err)
+	}
+	if len(stashes) == 0 {
+		return nil, &StashNotFoundError{}
+	}
+
+	ref := query
+	if _, err := strconv.Atoi(query); err == nil {
+		ref = "stash@{" + query + "}"
+	}
+	for i := range stashes {
+		if stashes[i].Ref == ref {
+			return &stashes[i], nil
+		}
+	}
+
+	items := make([]string, len(stashes))
+	for i, s := range stashes {
+		items[i] = s.Ref + ": " + s.Message
+	}
+	filtered, err := selectx.FilterByQuery(items, query, false)
+	if err != nil {
+		return nil, &StashNotFoundError{Query: query}
+	}
+	if len(filtered) == 1 {
+		return &stashes[filtered[0].Index], nil
+	}
+
+	filteredItems := make([]string, len(filtered))
+	for i, f := range filtered {
+		filteredItems[i] = f.Text
+	}
+	selected, err := pickItems(ctx, r, w, filteredItems, query, selectOptions{prompt: "Select stash"})
+	if err != nil {
+		return nil, err
+	}
+	return &stashes[filtered[selected[0]].Index], nil
+}
+
+// applyStashToWorktree applies stash to the new worktree at path, created with branch at the stash's base
+// If the stash doesn't apply (e.g. it conflicts with files the setup changed), the worktree and branch
+// are removed again, so that nothing half-made is left behind; the stash entry is kept either way.
+func applyStashToWorktree(ctx context.Context, path, branch string, stash *gitx.Stash) error {
+	err := gitx.ApplyStash(ctx, path, stash.Commit)
+	if err == nil {
+		return nil
+	}
+
+	removeNewWorktree(ctx, path, branch)
+	return fmt.Errorf("failed to apply %s, removed the new worktree again (the stash entry is kept): %w", stash.Ref, err)
+}
+
+// dropAppliedStash drops the stash entry applied to a new worktree and returns whether it was dropped
+// The entry is looked up again by commit, in case its ref changed in the meantime. Failures only
+// produce a warning on w: the changes are in the worktree either way.
+func dropAppliedStash(ctx context.Context, w io.Writer, stash *gitx.Stash) bool {
+	stashes, err := gitx.ListStashes(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to drop %s: %v\n", stash.Ref, err)
+		return false
+	}
+	for _, s := range stashes {
+		if s.Commit != stash.Commit {
+			continue
+		}
+		if err := gitx.DropStash(ctx, s.Ref); err != nil {
+			fmt.Fprintf(w, "Warning: failed to drop %s: %v\n", s.Ref, err)
+			return false
+		}
+		return true
+	}
+	fmt.Fprintf(w, "Warning: %s is no longer in the stash list, nothing dropped\n", stash.Ref)
+	return false
+}
+
+// Output functions
+
+func printStashApplied(p *progressPrinter, ref string, dropped bool) {
+	if ref == "" {
+		return
+	}
+	if dropped {
+		p.Printf("  Stash: %s applied and dropped\n", ref)
+		return
+	}
+	p.Printf("  Stash: %s applied (drop it with: git stash drop %s)\n", ref, ref)
+}
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// statusConcurrency limits the git status commands run at the same time
+const statusConcurrency = 8
+
+// NotRepositoryError represents an error when wt is run outside of a git repository
+type NotRepositoryError struct{}
+
+func (e *NotRepositoryError) Error() string {
+	return "not in a git repository"
+}
+
+// worktreeStatus is the state of a single worktree in wt status
+type worktreeStatus struct {
+	worktreeJSON
+	Current   bool   `json:"current"`
+	Missing   bool   `json:"missing"` // The worktree directory doesn't exist
+	Dirty     bool   `json:"dirty"`
+	Modified  int    `json:"modified"`
+	Untracked int    `json:"untracked"`
+	Error     string `json:"error,omitempty"` // Why the changes couldn't be checked
+}
+
+// repoStatus is the output of wt status
+type repoStatus struct {
+	Root      string           `json:"root"`
+	Current   *worktreeStatus  `json:"current"` // nil outside of the repository's worktrees
+	Worktrees []worktreeStatus `json:"worktrees"`
+	Dirty     int              `json:"dirty"`
+	Locked    int              `json:"locked"`
+	Prunable  int              `json:"prunable"`
+	Orphans   []string         `json:"orphans"` // Worktree directories git doesn't know about
+}
+
+func newStatusCmd() *cobra.Command {
+	return withJSON(&cobra.Command{
+		Use:   "status",
+		Short: "Show a summary of the repository and its worktrees",
+		Long: `Show where you are and what exists: the current worktree, the main repository root,
+and each worktree with its uncommitted changes, lock and prune state.
+
+Stale state is listed at the end: prunable worktrees (whose directory is gone) and
+orphan directories in the worktree directory that git doesn't know about.
+
+Examples:
+  wt status
+  wt status --json`,
+		Args: cobra.NoArgs,
+		RunE: runStatus,
+	})
+}
+
+var statusCmd = newStatusCmd()
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	status, err := collectStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), status)
+	}
+	printStatus(cmd.OutOrStdout(), status)
+	return nil
+}
+
+// collectStatus gathers the state of the repository and checks its worktrees concurrently
+func collectStatus(ctx context.Context) (*repoStatus, error) {
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		if gitx.IsNotRepository(err) {
+			return nil, &NotRepositoryError{}
+		}
+		return nil, fmt.Errorf("failed to get repository information: %w", err)
+	}
+
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	var currentPath string
+	if current, err := gitx.GetCurrentWorktree(ctx); err == nil {
+		currentPath = current.Path
+	}
+
+	status := &repoStatus{Root: repo.Root, Worktrees: make([]worktreeStatus, len(worktrees))}
+	for i, wt := range worktrees {
+		status.Worktrees[i] = worktreeStatus{worktreeJSON: newWorktreeJSON(wt), Current: wt.Path == currentPath}
+	}
+	checkWorktreeChanges(ctx, worktrees, status.Worktrees)
+
+	for i, wt := range worktrees {
+		s := &status.Worktrees[i]
+		if s.Current {
+			status.Current = s
+		}
+		if s.Dirty {
+			status.Dirty++
+		}
+		if wt.IsLocked {
+			status.Locked++
+		}
+		if wt.IsPrunable {
+			status.Prunable++
+		}
+	}
+
+	status.Orphans = findOrphanWorktreeDirs(ctx, repo, worktrees)
+	return status, nil
+}
+
+// checkWorktreeChanges fills in the uncommitted changes of each worktree, running git concurrently
+func checkWorktreeChanges(ctx context.Context, worktrees []gitx.Worktree, statuses []worktreeStatus) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, statusConcurrency)
+
+	for i, wt := range worktrees {
+		if wt.IsBare {
+			continue
+		}
+		if !pathExists(wt.Path) {
+			statuses[i].Missing = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *worktreeStatus, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			changes, err := gitx.GetStatus(ctx, path)
+			if err != nil {
+				s.Error = err.Error()
+				return
+			}
+			s.Dirty = changes.IsDirty()
+			s.Modified = changes.Modified
+			s.Untracked = changes.Untracked
+		}(&statuses[i], wt.Path)
+	}
+	wg.Wait()
+}
+
+// findOrphanWorktreeDirs returns directories in the worktree directory that look like linked
+// worktrees but aren't registered with git (e.g. left behind or moved from elsewhere)
+func findOrphanWorktreeDirs(ctx context.Context, repo *gitx.Repo, worktrees []gitx.Worktree) []string {
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return []string{}
+	}
+	dir, prefix := naming.WorktreeContainer(configuredBaseDir(repo, wtCfg), repo.Name, wtCfg)
+	if _, err := os.Stat(dir); err != nil {
+		return []string{}
+	}
+	found, err := findWorktreeDirs(dir, prefix, naming.NestsBranchDirs(wtCfg))
+	if err != nil {
+		return []string{}
+	}
+
+	orphans := []string{}
+	for _, path := range found {
+		registered := false
+		for _, wt := range worktrees {
+			if gitx.SamePath(path, wt.Path) {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			orphans = append(orphans, path)
+		}
+	}
+	return orphans
+}
+
+// Output functions
+
+func printStatus(w io.Writer, status *repoStatus) {
+	fmt.Fprintf(w, "Repository: %s\n", status.Root)
+	if status.Current != nil {
+		fmt.Fprintf(w, "Current:    %s (%s)\n", formatStatusBranch(status.Current), formatChanges(status.Current))
+	} else {
+		fmt.Fprintf(w, "Current:    not in a worktree of this repository\n")
+	}
+	fmt.Fprintf(w, "Worktrees:  %d (%d dirty, %d locked, %d prunable)\n",
+		len(status.Worktrees), status.Dirty, status.Locked, status.Prunable)
+
+	fmt.Fprintln(w)
+	table := newTable(w, "")
+	table.SetMaxWidth(0, maxBranchWidth+2) // With the marker
+	for i := range status.Worktrees {
+		s := &status.Worktrees[i]
+		marker, color := " ", tablex.NoColor
+		if s.Current {
+			marker, color = "*", tablex.Green
+		}
+		table.AddCells(
+			tablex.Cell{Text: marker + " " + formatStatusBranch(s), Color: color},
+			tablex.Cell{Text: s.Path},
+			tablex.Cell{Text: formatWorktreeState(s), Color: worktreeStateColor(s)},
+		)
+	}
+	_ = table.Render(w)
+
+	if status.Prunable == 0 && len(status.Orphans) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nStale:\n")
+	for i := range status.Worktrees {
+		if s := &status.Worktrees[i]; s.Prunable {
+			fmt.Fprintf(w, "  prunable: %s (%s)\n", s.Path, s.PruneReason)
+		}
+	}
+	for _, path := range status.Orphans {
+		fmt.Fprintf(w, "  orphan directory: %s\n", path)
+	}
+	if status.Prunable > 0 {
+		fmt.Fprintf(w, "  Run: wt prune (removes prunable entries)\n")
+	}
+	if len(status.Orphans) > 0 {
+		fmt.Fprintf(w, "  Run: wt repair <dir> to register a moved worktree, or delete the directory\n")
+	}
+}
+
+// formatStatusBranch formats the branch of a worktree in wt status
+func formatStatusBranch(s *worktreeStatus) string {
+	switch {
+	case s.Bare:
+		return "(bare)"
+	case s.Detached:
+		headShort := s.HEAD
+		if len(headShort) > 7 {
+			headShort = headShort[:7]
+		}
+		return fmt.Sprintf("(detached: %s)", headShort)
+	default:
+		return s.Branch
+	}
+}
+
+// formatChanges describes the uncommitted changes of a worktree, e.g. "2 modified, 1 untracked"
+func formatChanges(s *worktreeStatus) string {
+	switch {
+	case s.Bare:
+		return "no working tree"
+	case s.Missing:
+		return "missing"
+	case s.Error != "":
+		return "unknown"
+	case !s.Dirty:
+		return "clean"
+	}
+	var parts []string
+	if s.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", s.Modified))
+	}
+	if s.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("%d untracked", s.Untracked))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatWorktreeState describes a worktree in the list of wt status, e.g. "clean, locked"
+// worktreeStateColor highlights worktrees that need attention: red when prunable or missing,
+// yellow when dirty or locked
+func worktreeStateColor(s *worktreeStatus) tablex.Color {
+	switch {
+	case s.Prunable || s.Missing:
+		return tablex.Red
+	case s.Dirty || s.Locked:
+		return tablex.Yellow
+	default:
+		return tablex.NoColor
+	}
+}
+
+func formatWorktreeState(s *worktreeStatus) string {
+	state := formatChanges(s)
+	if s.Locked {
+		state += ", locked"
+	}
+	if s.Prunable {
+		state += ", prunable"
+	}
+	return state
+}
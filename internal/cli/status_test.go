@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+func TestCollectStatus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		t.Fatalf("loadWorktreeConfig() returned error: %v", err)
+	}
+	container, prefix := naming.WorktreeContainer(filepath.Dir(repoPath), "test-repo", wtCfg)
+
+	dirty := filepath.Join(container, prefix+"dirty")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "dirty", dirty)
+	if err := os.WriteFile(filepath.Join(dirty, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	locked := filepath.Join(container, prefix+"locked")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "locked", locked)
+	runGitForTest(t, repoPath, "worktree", "lock", locked)
+	gone := filepath.Join(t.TempDir(), "gone")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "gone", gone)
+	if err := os.RemoveAll(gone); err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(container, prefix+"orphan")
+	if err := os.MkdirAll(orphan, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphan, ".git"), []byte("gitdir: /nowhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	chdirForTest(t, dirty)
+	status, err := collectStatus(ctx)
+	if err != nil {
+		t.Fatalf("collectStatus() returned error: %v", err)
+	}
+
+	if len(status.Worktrees) != 4 {
+		t.Fatalf("collectStatus() found %d worktrees, want 4", len(status.Worktrees))
+	}
+	if status.Current == nil || status.Current.Branch != "dirty" {
+		t.Errorf("Current = %+v, want the dirty worktree", status.Current)
+	}
+	if status.Dirty != 1 || status.Locked != 1 || status.Prunable != 1 {
+		t.Errorf("counts = %d dirty, %d locked, %d prunable, want 1 each", status.Dirty, status.Locked, status.Prunable)
+	}
+	if status.Current != nil && status.Current.Untracked != 1 {
+		t.Errorf("Current.Untracked = %d, want 1", status.Current.Untracked)
+	}
+	if len(status.Orphans) != 1 || filepath.Base(status.Orphans[0]) != prefix+"orphan" {
+		t.Errorf("Orphans = %q, want the orphan directory", status.Orphans)
+	}
+
+	var buf bytes.Buffer
+	printStatus(&buf, status)
+	for _, want := range []string{"Current:    dirty (1 untracked)", "Worktrees:  4 (1 dirty, 1 locked, 1 prunable)", "missing, prunable", "orphan directory: "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCollectStatusOutsideRepository(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	_, err := collectStatus(context.Background())
+	if err == nil || err.Error() != "not in a git repository" {
+		t.Fatalf("collectStatus() error = %v, want %q", err, "not in a git repository")
+	}
+	if exitCode(err) != ExitNotRepository {
+		t.Errorf("exitCode() = %d, want %d", exitCode(err), ExitNotRepository)
+	}
+}
+
+func TestFormatChanges(t *testing.T) {
+	tests := []struct {
+		status worktreeStatus
+		want   string
+	}{
+		{status: worktreeStatus{}, want: "clean"},
+		{status: worktreeStatus{Dirty: true, Modified: 2, Untracked: 1}, want: "2 modified, 1 untracked"},
+		{status: worktreeStatus{Missing: true}, want: "missing"},
+		{status: worktreeStatus{Error: "boom"}, want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := formatChanges(&tt.status); got != tt.want {
+			t.Errorf("formatChanges(%+v) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/selectx"
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// Outcomes of wt sync for a worktree
+const (
+	syncUpdated   = "updated"
+	syncUpToDate  = "up-to-date"
+	syncSkipped   = "skipped"
+	syncFailed    = "failed"
+	syncWouldSync = "would-update" // --dry-run
+)
+
+// SyncFailedError represents an error when wt sync failed to update some worktrees
+type SyncFailedError struct {
+	Failed int
+}
+
+func (e *SyncFailedError) Error() string {
+	return fmt.Sprintf("failed to sync %d worktree(s)", e.Failed)
+}
+
+type syncCmdConfig struct {
+	rebase   bool
+	include  string
+	exclude  string
+	dryRun   bool
+	parallel int
+	match    matchOptions
+}
+
+// syncResult is the outcome of wt sync for one worktree
+type syncResult struct {
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream,omitempty"` // e.g. "origin/main"
+	Result   string `json:"result"`             // updated, up-to-date, would-update, skipped or failed
+	Reason   string `json:"reason,omitempty"`   // Why the worktree was skipped or failed
+	Commits  int    `json:"commits,omitempty"`  // Commits pulled from the upstream
+
+	remote string
+}
+
+func newSyncCmd() *cobra.Command {
+	cfg := &syncCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Fast-forward clean worktrees to their upstreams",
+		Long: `Bring every worktree up to date with the branch it tracks.
+
+Each remote is fetched once, then worktrees whose branch has an upstream are
+fast-forwarded (git merge --ff-only @{upstream}). Worktrees with uncommitted changes
+and branches that have diverged from their upstream are skipped with the reason;
+with --rebase, diverged branches are rebased onto the upstream instead (a rebase that
+stops on a conflict is aborted). Worktrees without an upstream are skipped.
+
+Restrict the worktrees with --include and --exclude queries (matched like wt go).
+With --dry-run, remotes are fetched but no worktree is changed.
+
+Examples:
+  wt sync
+  wt sync --dry-run
+  wt sync --rebase --exclude main
+  wt sync --include feature --parallel 8`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return runSyncWithConfig(c, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.rebase, "rebase", false, "Rebase branches that have diverged from their upstream instead of skipping them")
+	cmd.Flags().StringVar(&cfg.include, "include", "", "Only sync worktrees matching this query")
+	cmd.Flags().StringVar(&cfg.exclude, "exclude", "", "Skip worktrees containing this query")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Fetch and show what would be updated without changing any worktree")
+	cmd.Flags().IntVarP(&cfg.parallel, "parallel", "p", 4, "Number of remotes to fetch at once")
+	addMatchFlags(cmd, &cfg.match)
+
+	return withJSON(cmd)
+}
+
+var syncCmd = newSyncCmd()
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSyncWithConfig(cmd *cobra.Command, cfg *syncCmdConfig) error {
+	ctx := cmd.Context()
+	w := cmd.OutOrStdout()
+	if jsonOutput() {
+		// Keep stdout for the JSON results
+		w = cmd.ErrOrStderr()
+	}
+
+	if cfg.parallel < 1 {
+		return &UsageError{Err: fmt.Errorf("invalid --parallel: %d (must be at least 1)", cfg.parallel)}
+	}
+
+	worktrees, err := syncWorktrees(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	results := make([]syncResult, len(worktrees))
+	for i, wt := range worktrees {
+		results[i] = newSyncResult(ctx, wt)
+	}
+
+	fetchRemotes(ctx, w, results, cfg.parallel)
+	for i := range results {
+		if results[i].Result == "" {
+			syncWorktree(ctx, &results[i], cfg)
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Result == syncFailed {
+			failed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := writeJSON(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+		if failed > 0 {
+			// The failures are in the results
+			return &reportedError{err: &SyncFailedError{Failed: failed}}
+		}
+		return nil
+	}
+
+	printSyncResults(cmd.OutOrStdout(), results)
+	if failed > 0 {
+		return &SyncFailedError{Failed: failed}
+	}
+	return nil
+}
+
+// syncWorktrees returns the worktrees wt sync considers, in the order of git worktree list
+func syncWorktrees(ctx context.Context, cfg *syncCmdConfig) ([]gitx.Worktree, error) {
+	all, err := gitx.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktrees: %w", err)
+	}
+
+	worktrees := selectableWorktrees(all)
+	if cfg.include != "" {
+		if worktrees, err = filterWorktreesByQuery(worktrees, cfg.include, cfg.match.useFuzzy()); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.exclude != "" {
+		worktrees = excludeWorktreesByQuery(worktrees, cfg.exclude)
+	}
+
+	if len(worktrees) == 0 {
+		return nil, &NoWorktreesError{}
+	}
+	return worktrees, nil
+}
+
+// excludeWorktreesByQuery returns the worktrees that don't contain query
+// Only exact, prefix and substring matches are excluded, never fuzzy ones.
+func excludeWorktreesByQuery(worktrees []gitx.Worktree, query string) []gitx.Worktree {
+	filtered, err := selectx.FilterIndexedByQuery(createDisplayItems(worktrees), query, false)
+	if err != nil {
+		return worktrees
+	}
+	excluded := make([]bool, len(worktrees))
+	for _, f := range filtered {
+		excluded[f.Index] = true
+	}
+	var kept []gitx.Worktree
+	for i, wt := range worktrees {
+		if !excluded[i] {
+			kept = append(kept, wt)
+		}
+	}
+	return kept
+}
+
+// newSyncResult returns the result for wt, already decided if the worktree can't be synced
+func newSyncResult(ctx context.Context, wt gitx.Worktree) syncResult {
+	r := syncResult{Path: wt.Path, Branch: wt.Branch}
+	switch {
+	case wt.IsDetached:
+		r.Result, r.Reason = syncSkipped, "detached HEAD"
+		return r
+	case !pathExists(wt.Path):
+		r.Result, r.Reason = syncSkipped, "worktree directory does not exist"
+		return r
+	}
+
+	upstream, err := gitx.GetUpstream(ctx, wt.Branch)
+	switch {
+	case err != nil:
+		r.Result, r.Reason = syncFailed, err.Error()
+	case upstream == nil:
+		r.Result, r.Reason = syncSkipped, "no upstream"
+	default:
+		r.Upstream = upstream.Remote + "/" + upstream.Branch
+		r.remote = upstream.Remote
+	}
+	return r
+}
+
+// fetchRemotes fetches each remote tracked by a pending result once, at most parallel at a time
+// The results of worktrees tracking a remote that couldn't be fetched are marked as failed.
+func fetchRemotes(ctx context.Context, w io.Writer, results []syncResult, parallel int) {
+	seen := make(map[string]bool)
+	var remotes []string
+	for _, r := range results {
+		if r.Result == "" && !seen[r.remote] {
+			seen[r.remote] = true
+			remotes = append(remotes, r.remote)
+		}
+	}
+	sort.Strings(remotes)
+
+	errs := make([]error, len(remotes))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for i, remote := range remotes {
+		if !flagQuiet {
+			fmt.Fprintf(w, "Fetching %s...\n", remote)
+		}
+		wg.Add(1)
+		go func(i int, remote string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = gitx.Fetch(ctx, remote)
+		}(i, remote)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		for j := range results {
+			if r := &results[j]; r.Result == "" && r.remote == remotes[i] {
+				r.Result, r.Reason = syncFailed, fmt.Sprintf("fetching %s failed: %v", remotes[i], err)
+			}
+		}
+	}
+}
+
+// syncWorktree fast-forwards (or with --rebase, rebases) the worktree of r onto its upstream
+func syncWorktree(ctx context.Context, r *syncResult, cfg *syncCmdConfig) {
+	changes, err := gitx.GetStatus(ctx, r.Path)
+	if err != nil {
+		r.Result, r.Reason = syncFailed, err.Error()
+		return
+	}
+	if changes.IsDirty() {
+		r.Result, r.Reason = syncSkipped, "uncommitted changes"
+		return
+	}
+
+	ahead, behind, _, err := gitx.AheadBehind(ctx, r.Path)
+	if err != nil {
+		r.Result, r.Reason = syncFailed, err.Error()
+		return
+	}
+	switch {
+	case behind == 0:
+		r.Result = syncUpToDate
+		return
+	case ahead > 0 && !cfg.rebase:
+		r.Result, r.Reason = syncSkipped, fmt.Sprintf("diverged (%d ahead, %d behind)", ahead, behind)
+		return
+	case cfg.dryRun:
+		r.Result, r.Commits = syncWouldSync, behind
+		return
+	}
+
+	if ahead > 0 {
+		err = gitx.RebaseOnUpstream(ctx, r.Path)
+	} else {
+		err = gitx.FastForward(ctx, r.Path)
+	}
+	if err != nil {
+		r.Result, r.Reason = syncFailed, err.Error()
+		return
+	}
+	r.Result, r.Commits = syncUpdated, behind
+}
+
+// Output functions
+
+func printSyncResults(w io.Writer, results []syncResult) {
+	table := newTable(w, "  ")
+	table.SetMaxWidth(0, maxBranchWidth)
+	for _, r := range results {
+		branch := r.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		table.AddCells(tablex.Cell{Text: branch}, tablex.Cell{Text: formatSyncResult(r), Color: syncResultColor(r.Result)})
+	}
+	_ = table.Render(w)
+}
+
+// syncResultColor colors a sync result: green when updated, red when failed, yellow when skipped
+func syncResultColor(result string) tablex.Color {
+	switch result {
+	case syncUpdated, syncWouldSync:
+		return tablex.Green
+	case syncFailed:
+		return tablex.Red
+	case syncSkipped:
+		return tablex.Yellow
+	default:
+		return tablex.NoColor
+	}
+}
+
+// formatSyncResult describes the outcome for a worktree, e.g. "updated (3 commits from origin/main)"
+func formatSyncResult(r syncResult) string {
+	switch r.Result {
+	case syncUpdated, syncWouldSync:
+		return fmt.Sprintf("%s (%s from %s)", r.Result, pluralize(r.Commits, "commit"), r.Upstream)
+	case syncSkipped, syncFailed:
+		return fmt.Sprintf("%s (%s)", r.Result, r.Reason)
+	default:
+		return r.Result
+	}
+}
+
+// pluralize formats n with noun, e.g. "1 commit" or "3 commits"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestSyncWorktrees(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	runGitForTest(t, repoPath, "init", "--bare", remotePath)
+	runGitForTest(t, repoPath, "remote", "add", "origin", remotePath)
+	runGitForTest(t, repoPath, "push", "-u", "origin", "main")
+
+	behind := filepath.Join(t.TempDir(), "behind")
+	diverged := filepath.Join(t.TempDir(), "diverged")
+	dirty := filepath.Join(t.TempDir(), "dirty")
+	for _, dir := range []string{behind, diverged, dirty} {
+		branch := filepath.Base(dir)
+		runGitForTest(t, repoPath, "worktree", "add", "-b", branch, dir)
+		runGitForTest(t, dir, "push", "-u", "origin", branch)
+	}
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "local", filepath.Join(t.TempDir(), "local"))
+
+	// Push a commit to every branch from another clone
+	otherPath := filepath.Join(t.TempDir(), "other")
+	runGitForTest(t, repoPath, "clone", remotePath, otherPath)
+	for _, branch := range []string{"behind", "diverged", "dirty"} {
+		runGitForTest(t, otherPath, "checkout", branch)
+		commitFileForTest(t, otherPath, branch+"-remote.txt")
+		runGitForTest(t, otherPath, "push", "origin", branch)
+	}
+	commitFileForTest(t, diverged, "local.txt")
+	if err := os.WriteFile(filepath.Join(dirty, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	run := func(cfg syncCmdConfig) map[string]string {
+		t.Helper()
+		cfg.parallel = 1
+		worktrees, err := syncWorktrees(ctx, &cfg)
+		if err != nil {
+			t.Fatalf("syncWorktrees() returned error: %v", err)
+		}
+		results := make([]syncResult, len(worktrees))
+		for i, wt := range worktrees {
+			results[i] = newSyncResult(ctx, wt)
+		}
+		fetchRemotes(ctx, io.Discard, results, cfg.parallel)
+		got := make(map[string]string)
+		for i := range results {
+			if results[i].Result == "" {
+				syncWorktree(ctx, &results[i], &cfg)
+			}
+			got[results[i].Branch] = formatSyncResult(results[i])
+		}
+		return got
+	}
+
+	got := run(syncCmdConfig{dryRun: true, exclude: "main"})
+	want := map[string]string{
+		"behind":   "would-update (1 commit from origin/behind)",
+		"diverged": "skipped (diverged (1 ahead, 1 behind))",
+		"dirty":    "skipped (uncommitted changes)",
+		"local":    "skipped (no upstream)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dry run = %v, want %v", got, want)
+	}
+	if ahead, behindCount, _, _ := gitx.AheadBehind(ctx, behind); ahead != 0 || behindCount != 1 {
+		t.Errorf("dry run changed the behind worktree: %d ahead, %d behind", ahead, behindCount)
+	}
+
+	got = run(syncCmdConfig{rebase: true})
+	want = map[string]string{
+		"main":     "up-to-date",
+		"behind":   "updated (1 commit from origin/behind)",
+		"diverged": "updated (1 commit from origin/diverged)",
+		"dirty":    "skipped (uncommitted changes)",
+		"local":    "skipped (no upstream)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sync = %v, want %v", got, want)
+	}
+
+	got = run(syncCmdConfig{include: "behind"})
+	if want := map[string]string{"behind": "up-to-date"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sync --include = %v, want %v", got, want)
+	}
+}
+
+// commitFileForTest creates file in dir and commits it
+func commitFileForTest(t *testing.T, dir, file string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+	runGitForTest(t, dir, "add", file)
+	runGitForTest(t, dir, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-m", "Add "+file)
+}
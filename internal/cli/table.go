@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// maxBranchWidth is the widest a branch name gets in tables before it is cut with "…"
+const maxBranchWidth = 48
+
+// isTerminalOutput reports whether output written to w shows up in a terminal
+// Writers other than files (e.g. buffers in tests) are treated as terminals without colors.
+// It is a variable so that tests can simulate redirected output.
+var isTerminalOutput = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+	return isTerminalFile(f)
+}
+
+// newTable returns a table for w: aligned with the rows indented by indent, and colored on a
+// terminal unless NO_COLOR is set. When w is redirected or with --no-align, the rows are
+// printed as tab-separated values instead.
+func newTable(w io.Writer, indent string) *tablex.Table {
+	align := !flagNoAlign && isTerminalOutput(w)
+	_, isFile := w.(*os.File)
+	return tablex.New(tablex.Options{
+		Align:  align,
+		Color:  align && isFile && os.Getenv("NO_COLOR") == "",
+		Indent: indent,
+	})
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewTableTSV(t *testing.T) {
+	origAlign, origTerminal := flagNoAlign, isTerminalOutput
+	t.Cleanup(func() { flagNoAlign, isTerminalOutput = origAlign, origTerminal })
+
+	render := func() string {
+		var buf bytes.Buffer
+		table := newTable(&buf, "  ")
+		table.AddRow("feature/x", "ok", "/src/a")
+		table.AddRow("main", "failed", "/src/b")
+		_ = table.Render(&buf)
+		return buf.String()
+	}
+
+	tests := []struct {
+		name     string
+		noAlign  bool
+		terminal bool
+		want     string
+	}{
+		{"terminal", false, true, "  feature/x  ok      /src/a\n  main       failed  /src/b\n"},
+		{"redirected", false, false, "feature/x\tok\t/src/a\nmain\tfailed\t/src/b\n"},
+		{"--no-align", true, true, "feature/x\tok\t/src/a\nmain\tfailed\t/src/b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagNoAlign = tt.noAlign
+			terminal := tt.terminal
+			isTerminalOutput = func(io.Writer) bool { return terminal }
+			if got := render(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package cli
+
+import "syscall"
+
+// ioctlGetTermios is the ioctl request that reads the terminal attributes of a file
+const ioctlGetTermios = syscall.TIOCGETA
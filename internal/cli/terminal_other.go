@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package cli
+
+// isTerminalFd reports whether fd is a terminal
+// Without a way to tell, nothing is a terminal: prompts then ask for their bypass flags.
+func isTerminalFd(fd uintptr) bool {
+	return false
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package cli
+
+import "syscall"
+
+// isTerminalFd reports whether fd is a console: only consoles have a console mode
+// (other character devices, like NUL, don't)
+func isTerminalFd(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
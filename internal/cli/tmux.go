@@ -1,8 +1,14 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/toritori0318/git-wt/internal/gitx"
@@ -10,15 +16,35 @@ import (
 	"github.com/toritori0318/git-wt/internal/tmux"
 )
 
+const (
+	// lockRetryAttempts limits how often creating a worktree is tried while git's lock files are taken
+	lockRetryAttempts = 5
+	// lockRetryDelay is the wait before the first retry; it doubles with every attempt
+	lockRetryDelay = 100 * time.Millisecond
+)
+
+// TmuxNotFoundError represents an error when tmux is not installed
+type TmuxNotFoundError struct{}
+
+func (e *TmuxNotFoundError) Error() string {
+	return "tmux is not installed. Install with: brew install tmux (macOS) or apt install tmux (Linux)"
+}
+
 type tmuxNewConfig struct {
-	baseDir     string
-	count       int
-	layout      string
-	syncPanes   bool
-	noAttach    bool
-	sessionName string
+	baseDir            string
+	count              int
+	jobs               int
+	layout             string
+	syncPanes          bool
+	noAttach           bool
+	sessionName        string
+	sessionPerWorktree bool
+	setup              setupOptions
 }
 
+// newTmuxManager creates the manager of a tmux session (replaced in tests)
+var newTmuxManager = tmux.NewManager
+
 // newTmuxCmd creates the root tmux command
 func newTmuxCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -29,6 +55,7 @@ func newTmuxCmd() *cobra.Command {
 
 	// Add subcommands
 	cmd.AddCommand(newTmuxNewCmd())
+	cmd.AddCommand(newTmuxPRCmd())
 
 	return cmd
 }
@@ -42,23 +69,35 @@ func newTmuxNewCmd() *cobra.Command {
 		Long: `Create one or more worktrees and launch them in a tmux session.
 
 Creates worktrees with numbered suffixes (branch-1, branch-2, etc.) and opens them in tmux panes.
+The worktrees are created in parallel (see --jobs).
+
+With --session-per-worktree, each worktree gets its own detached session of a
+single pane instead, named <session-name>-1, <session-name>-2, etc., so that they
+can be attached from different terminals (--layout and --sync-panes don't apply).
+The session to attach is then chosen from a list, or with --no-attach the attach
+commands are printed.
 
 Examples:
   wt tmux new feature/auth
   wt tmux new feature/auth --count 3
-  wt tmux new feature/auth main --count 3 --sync-panes`,
+  wt tmux new feature/auth main --count 3 --sync-panes
+  wt tmux new feature/auth --count 6 --jobs 2
+  wt tmux new feature/auth --count 3 --session-per-worktree --no-attach`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(c *cobra.Command, args []string) error {
 			return runTmuxNew(c, args, cfg)
 		},
 	}
 
-	cmd.Flags().StringVar(&cfg.baseDir, "base-dir", "", "Base directory for worktree placement (defaults to repository parent)")
+	cmd.Flags().StringVar(&cfg.baseDir, "base-dir", "", "Base directory for worktree placement (defaults to worktree.base_dir or the repository parent)")
 	cmd.Flags().IntVar(&cfg.count, "count", 1, "Number of worktrees to create")
+	cmd.Flags().IntVar(&cfg.jobs, "jobs", runtime.GOMAXPROCS(0), "Number of worktrees to create at once")
 	cmd.Flags().StringVar(&cfg.layout, "layout", "tiled", "Tmux layout (tiled/horizontal/vertical)")
 	cmd.Flags().BoolVar(&cfg.syncPanes, "sync-panes", false, "Enable tmux synchronize-panes (send same input to all panes)")
 	cmd.Flags().BoolVar(&cfg.noAttach, "no-attach", false, "Don't attach to tmux session")
 	cmd.Flags().StringVar(&cfg.sessionName, "session-name", "", "Custom tmux session name")
+	cmd.Flags().BoolVar(&cfg.sessionPerWorktree, "session-per-worktree", false, "Create a separate session per worktree instead of one session with a pane per worktree")
+	addSetupFlags(cmd, &cfg.setup)
 
 	return cmd
 }
@@ -96,7 +135,7 @@ func validateLayout(layout string) error {
 
 func runTmuxNew(cmd *cobra.Command, args []string, cfg *tmuxNewConfig) error {
 	ctx := cmd.Context()
-	w := cmd.OutOrStdout()
+	progress := newProgressPrinter(cmd.OutOrStdout(), false, flagQuiet)
 
 	// Parse arguments
 	branchPrefix := args[0]
@@ -111,7 +150,7 @@ func runTmuxNew(cmd *cobra.Command, args []string, cfg *tmuxNewConfig) error {
 
 	// Check tmux availability
 	if !tmux.IsTmuxAvailable() {
-		return fmt.Errorf("tmux is not installed. Install with: brew install tmux (macOS) or apt install tmux (Linux)")
+		return &TmuxNotFoundError{}
 	}
 
 	// Validate layout
@@ -123,6 +162,12 @@ func runTmuxNew(cmd *cobra.Command, args []string, cfg *tmuxNewConfig) error {
 	if cfg.count < 1 {
 		return fmt.Errorf("count must be at least 1")
 	}
+	if cfg.jobs < 1 {
+		return &UsageError{Err: fmt.Errorf("invalid --jobs: %d (must be at least 1)", cfg.jobs)}
+	}
+	if cfg.sessionPerWorktree && (cmd.Flags().Changed("layout") || cfg.syncPanes) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: --layout and --sync-panes are ignored with --session-per-worktree\n")
+	}
 
 	// Get repository information
 	repo, err := gitx.GetRepo(ctx, flagRepo)
@@ -131,14 +176,14 @@ func runTmuxNew(cmd *cobra.Command, args []string, cfg *tmuxNewConfig) error {
 	}
 
 	// Determine and validate base directory
-	baseDir, err := resolveAndValidateBaseDir(cfg.baseDir, repo.Parent)
+	baseDir, err := resolveAndValidateBaseDir(ctx, cfg.baseDir, repo.Parent)
 	if err != nil {
 		return err
 	}
 
 	// Create worktrees
-	fmt.Fprintf(w, "Creating worktrees...\n")
-	panes, err := createMultipleWorktrees(ctx, branchPrefix, startPoint, cfg.count, repo, baseDir, w)
+	progress.Printf("Creating worktrees...\n")
+	panes, err := createMultipleWorktrees(ctx, branchPrefix, startPoint, cfg.count, cfg.jobs, repo, baseDir, cfg.setup, cmd.InOrStdin(), progress, cmd.ErrOrStderr())
 	if err != nil {
 		return err
 	}
@@ -153,54 +198,234 @@ func runTmuxNew(cmd *cobra.Command, args []string, cfg *tmuxNewConfig) error {
 		tmuxName = naming.Sanitize(tmuxName)
 	}
 
-	tm := tmux.NewManager(tmuxName)
-
-	// Kill existing session if it exists
-	if tm.SessionExists() {
-		if err := tm.KillSession(); err != nil {
-			return err
-		}
+	if cfg.sessionPerWorktree {
+		return startWorktreeSessions(cmd, progress, tmuxName, panes, cfg.noAttach)
 	}
 
-	fmt.Fprintf(w, "\nStarting tmux session...\n")
-	tmuxCfg := tmux.SessionConfig{
+	return launchTmuxSession(progress, tmux.SessionConfig{
 		SessionName: tmuxName,
 		Panes:       panes,
 		Layout:      cfg.layout,
 		SyncPanes:   cfg.syncPanes,
 		NoAttach:    cfg.noAttach,
 		Debug:       flagDebug,
+	})
+}
+
+// launchTmuxSession creates the session of sessionCfg, replacing an existing one of the same name,
+// and attaches to it unless sessionCfg.NoAttach
+func launchTmuxSession(progress *progressPrinter, sessionCfg tmux.SessionConfig) error {
+	tm := newTmuxManager(sessionCfg.SessionName)
+
+	// Kill existing session if it exists
+	if tm.SessionExists() {
+		if err := tm.KillSession(); err != nil {
+			return err
+		}
 	}
 
-	if err := tm.CreateSession(tmuxCfg); err != nil {
+	progress.Printf("\nStarting tmux session...\n")
+	if err := tm.CreateSession(sessionCfg); err != nil {
 		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
-	fmt.Fprintf(w, "✓ Tmux session created: %s\n", tmuxName)
+	progress.Printf("✓ Tmux session created: %s\n", sessionCfg.SessionName)
 
-	if cfg.noAttach {
-		fmt.Fprintf(w, "\nSession running in background\n")
-		fmt.Fprintf(w, "Attach with: tmux attach -t %s\n", tmuxName)
-	} else {
-		fmt.Fprintf(w, "\nAttaching to tmux session (Ctrl-b d to detach)...\n")
-		if err := tm.AttachSession(); err != nil {
+	if sessionCfg.NoAttach {
+		progress.Printf("\nSession running in background\n")
+		progress.Printf("Attach with: tmux attach -t %s\n", sessionCfg.SessionName)
+		return nil
+	}
+	progress.Printf("\nAttaching to tmux session (Ctrl-b d to detach)...\n")
+	return tm.AttachSession()
+}
+
+// startWorktreeSessions starts a session per worktree (see createWorktreeSessions), then attaches to
+// the one chosen from them. With noAttach, or without a terminal to choose in, the attach commands are
+// printed instead, also with --quiet.
+func startWorktreeSessions(cmd *cobra.Command, progress *progressPrinter, baseName string, panes []tmux.Pane, noAttach bool) error {
+	progress.Printf("\nStarting tmux sessions...\n")
+	names, err := createWorktreeSessions(baseName, panes)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		progress.Printf("✓ Tmux session created: %s (%s)\n", name, panes[i].BranchName)
+	}
+
+	if noAttach || !isTerminal(cmd.InOrStdin()) {
+		progress.Printf("\nSessions running in background\n")
+		progress.Printf("Attach with:\n")
+		// The result of the command: printed even with --quiet
+		for _, name := range names {
+			fmt.Fprintf(cmd.OutOrStdout(), "tmux attach -t %s\n", name)
+		}
+		return nil
+	}
+
+	selected := 0
+	if len(names) > 1 {
+		items := make([]string, len(names))
+		for i, name := range names {
+			items[i] = fmt.Sprintf("%s\t%s", name, panes[i].WorktreePath)
+		}
+		selected, err = selectItem(cmd.Context(), cmd.InOrStdin(), cmd.ErrOrStderr(), items, "", selectOptions{prompt: "Select session to attach"})
+		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	progress.Printf("\nAttaching to tmux session %s (Ctrl-b d to detach)...\n", names[selected])
+	return newTmuxManager(names[selected]).AttachSession()
 }
 
+// createWorktreeSessions creates a detached session of a single pane for each of panes, named
+// <baseName>-1, <baseName>-2, ... like the worktree branches. An existing session of the same name
+// is replaced. It returns the session names.
+func createWorktreeSessions(baseName string, panes []tmux.Pane) ([]string, error) {
+	names := make([]string, 0, len(panes))
+	for i, pane := range panes {
+		name := fmt.Sprintf("%s-%d", baseName, i+1)
+		tm := newTmuxManager(name)
+		if tm.SessionExists() {
+			if err := tm.KillSession(); err != nil {
+				return names, err
+			}
+		}
+
+		sessionCfg := tmux.SessionConfig{
+			SessionName: name,
+			Panes:       []tmux.Pane{pane},
+			NoAttach:    true,
+			Debug:       flagDebug,
+		}
+		if err := tm.CreateSession(sessionCfg); err != nil {
+			return names, fmt.Errorf("failed to create tmux session %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// plannedWorktree is a worktree that createMultipleWorktrees is about to create
+type plannedWorktree struct {
+	branch       string
+	path         string
+	createBranch bool
+}
+
+// createMultipleWorktrees creates count worktrees for branchPrefix-1, branchPrefix-2, ..., checking out at most jobs at a time
+// Output is reported per worktree in order. If registering one fails, all are removed again. If a
+// checkout fails, the others are cancelled, the unfinished ones removed, and the error names the
+// worktrees that were created.
 func createMultipleWorktrees(
 	ctx context.Context,
 	branchPrefix string,
 	startPoint string,
 	count int,
+	jobs int,
 	repo *gitx.Repo,
 	baseDir string,
-	w interface{ Write([]byte) (int, error) },
+	setup setupOptions,
+	r io.Reader,
+	progress *progressPrinter,
+	errW io.Writer,
 ) ([]tmux.Pane, error) {
+	// Plan all paths up front: generateWorktreePath picks a free path (and may prompt), so
+	// running it concurrently could hand the same path to two worktrees
+	plans, err := planWorktrees(ctx, r, errW, branchPrefix, count, repo, baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the worktrees one by one (concurrent 'git worktree add' runs trip over each
+	// other's administrative files), then do the slow checkouts in parallel
+	for i, plan := range plans {
+		if err := registerWorktree(ctx, plan, startPoint); err != nil {
+			// Remove what this run registered, so that running it again doesn't fail with
+			// "already exists" (uses the caller's context: ctx may be cancelled)
+			for _, registered := range plans[:i] {
+				rollbackWorktree(context.WithoutCancel(ctx), registered)
+			}
+			return nil, err
+		}
+	}
+
+	errs := make([]error, len(plans))
+	outputs := make([]bytes.Buffer, len(plans))
+	failure := checkoutWorktrees(ctx, plans, jobs, setup, outputs, errs)
+
 	var panes []tmux.Pane
+	var created []string
+	for i, plan := range plans {
+		_, _ = errW.Write(outputs[i].Bytes())
+		if failure != nil {
+			// Don't leave half-created worktrees behind (uses the caller's context: ctx may be cancelled)
+			if errs[i] != nil {
+				rollbackWorktree(context.WithoutCancel(ctx), plan)
+				continue
+			}
+			created = append(created, plan.branch)
+		}
+		progress.Printf("  ✓ %s -> %s\n", plan.branch, plan.path)
+		panes = append(panes, tmux.Pane{
+			WorktreePath: plan.path,
+			BranchName:   plan.branch,
+		})
+	}
+
+	if failure != nil {
+		if len(created) > 0 {
+			return nil, fmt.Errorf("%w\nCreated before the failure: %s", failure, strings.Join(created, ", "))
+		}
+		return nil, failure
+	}
+	return panes, nil
+}
+
+// checkoutWorktrees checks out and initializes the registered worktrees, at most jobs at a time
+// Progress of worktree i goes to outputs[i] and its error to errs[i]. The first failure cancels
+// the others and is returned.
+func checkoutWorktrees(ctx context.Context, plans []plannedWorktree, jobs int, setup setupOptions, outputs []bytes.Buffer, errs []error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var failure error
+	var failOnce sync.Once
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, jobs)
+	for i := range plans {
+		// Start in order, and not at all once a worktree failed
+		sem <- struct{}{}
+		if errs[i] = ctx.Err(); errs[i] != nil {
+			<-sem
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if errs[i] = checkoutPlannedWorktree(ctx, &outputs[i], plans[i], setup); errs[i] != nil {
+				// Only the first failure is the cause; the cancelled ones fail because of it
+				failOnce.Do(func() {
+					failure = errs[i]
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if failure == nil {
+		failure = ctx.Err() // Cancelled from outside (e.g. Ctrl-C)
+	}
+	return failure
+}
+
+// planWorktrees picks the branch names and paths of the worktrees createMultipleWorktrees creates
+func planWorktrees(ctx context.Context, r io.Reader, errW io.Writer, branchPrefix string, count int, repo *gitx.Repo, baseDir string) ([]plannedWorktree, error) {
+	plans := make([]plannedWorktree, 0, count)
+	planned := make(map[string]bool, count)
 
 	for i := 1; i <= count; i++ {
 		// Generate branch name with number suffix
@@ -211,14 +436,15 @@ func createMultipleWorktrees(
 			return nil, err
 		}
 
-		// Sanitize branch name
-		sanitized := naming.Sanitize(branchName)
-
 		// Generate worktree path
-		worktreePath, err := naming.GenerateWorktreePath(baseDir, repo.Name, sanitized)
+		worktreePath, err := generateWorktreePath(ctx, r, errW, baseDir, repo.Name, branchName, "")
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate worktree path for %s: %w", branchName, err)
 		}
+		if planned[worktreePath] {
+			return nil, fmt.Errorf("worktree path for %s is the same as for another branch: %s", branchName, worktreePath)
+		}
+		planned[worktreePath] = true
 
 		// Check if branch exists
 		exists, err := gitx.BranchExists(ctx, branchName)
@@ -226,18 +452,71 @@ func createMultipleWorktrees(
 			return nil, fmt.Errorf("failed to check branch existence for %s: %w", branchName, err)
 		}
 
-		// Create worktree
-		if err := gitx.Add(ctx, worktreePath, branchName, startPoint, !exists); err != nil {
-			return nil, fmt.Errorf("failed to create worktree for %s: %w", branchName, err)
+		plans = append(plans, plannedWorktree{branch: branchName, path: worktreePath, createBranch: !exists})
+	}
+	return plans, nil
+}
+
+// checkoutPlannedWorktree checks out the files of a registered worktree and initializes it, writing progress to w
+func checkoutPlannedWorktree(ctx context.Context, w io.Writer, plan plannedWorktree, setup setupOptions) error {
+	if err := gitx.CheckoutWorktree(ctx, plan.path); err != nil {
+		return fmt.Errorf("failed to check out worktree for %s: %w", plan.branch, err)
+	}
+
+	// Initialize submodules and LFS objects
+	return setupWorktree(ctx, w, plan.path, setup)
+}
+
+// registerWorktree registers a planned worktree (replaced in tests)
+var registerWorktree = addWorktreeWithRetry
+
+// addWorktreeWithRetry registers the worktree, retrying with backoff while another git process holds a lock
+// If it fails, a branch created by a failed attempt is deleted again.
+func addWorktreeWithRetry(ctx context.Context, plan plannedWorktree, startPoint string) error {
+	if err := prepareWorktreeParent(ctx, plan.path); err != nil {
+		return err
+	}
+
+	createdBranch := false
+	delay := lockRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := gitx.AddWithoutCheckout(ctx, plan.path, plan.branch, startPoint, plan.createBranch)
+		if err == nil {
+			return nil
+		}
+		if !gitx.IsLockContention(err) || attempt == lockRetryAttempts {
+			return addWorktreeFailed(ctx, plan, createdBranch, err)
 		}
 
-		fmt.Fprintf(w, "  ✓ %s -> %s\n", branchName, worktreePath)
+		select {
+		case <-ctx.Done():
+			return addWorktreeFailed(ctx, plan, createdBranch, err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+
+		// The failed attempt may have created the branch before the lock got in the way
+		if plan.createBranch {
+			if exists, _ := gitx.BranchExists(ctx, plan.branch); exists {
+				plan.createBranch = false
+				createdBranch = true
+			}
+		}
+	}
+}
 
-		panes = append(panes, tmux.Pane{
-			WorktreePath: worktreePath,
-			BranchName:   branchName,
-		})
+// addWorktreeFailed deletes the branch addWorktreeWithRetry created, if any, and returns its error
+func addWorktreeFailed(ctx context.Context, plan plannedWorktree, createdBranch bool, err error) error {
+	if createdBranch {
+		_ = gitx.DeleteBranch(context.WithoutCancel(ctx), plan.branch, true)
 	}
+	return fmt.Errorf("failed to create worktree for %s: %w", plan.branch, err)
+}
 
-	return panes, nil
+// rollbackWorktree removes a worktree that failed to be set up, and its branch if it was created for it
+func rollbackWorktree(ctx context.Context, plan plannedWorktree) {
+	_ = gitx.Remove(ctx, plan.path, true)
+	if plan.createBranch {
+		_ = gitx.DeleteBranch(ctx, plan.branch, true)
+	}
 }
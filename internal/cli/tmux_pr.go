@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/ghx"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/tmux"
+)
+
+type tmuxPRConfig struct {
+	layout      string
+	windows     bool
+	noAttach    bool
+	sessionName string
+	force       bool
+	setup       setupOptions
+}
+
+func newTmuxPRCmd() *cobra.Command {
+	cfg := &tmuxPRConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "pr <pr-number>...",
+		Short: "Create PR review worktrees and open them in a tmux session",
+		Long: `Create a review worktree for each PR (like wt pr) and open them side by side in
+one tmux session, named after the PR numbers.
+
+A PR whose worktree can't be created doesn't stop the others: the session is
+created from the worktrees that succeeded, and the failures are listed. A PR
+branch that is already checked out in a worktree opens that worktree.
+
+Examples:
+  wt tmux pr 101 102 103
+  wt tmux pr 101 102 --windows       # A window per PR instead of panes
+  wt tmux pr 101 102 --force         # Use existing local branches without asking`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runTmuxPR(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.layout, "layout", "tiled", "Tmux layout (tiled/horizontal/vertical)")
+	cmd.Flags().BoolVar(&cfg.windows, "windows", false, "Open each PR in a window of its own instead of a pane")
+	cmd.Flags().BoolVar(&cfg.noAttach, "no-attach", false, "Don't attach to tmux session")
+	cmd.Flags().StringVar(&cfg.sessionName, "session-name", "", "Custom tmux session name")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Skip all prompts and use existing branches")
+	addSetupFlags(cmd, &cfg.setup)
+
+	return cmd
+}
+
+func runTmuxPR(cmd *cobra.Command, args []string, cfg *tmuxPRConfig) error {
+	ctx := cmd.Context()
+	progress := newProgressPrinter(cmd.OutOrStdout(), false, flagQuiet)
+
+	prNumbers := make([]int, len(args))
+	for i, arg := range args {
+		prNumber, err := validatePRNumber(arg)
+		if err != nil {
+			return err
+		}
+		prNumbers[i] = prNumber
+	}
+
+	if !tmux.IsTmuxAvailable() {
+		return &TmuxNotFoundError{}
+	}
+	if !ghx.IsGhAvailable() {
+		return &GhNotFoundError{}
+	}
+	if err := validateLayout(cfg.layout); err != nil {
+		return err
+	}
+
+	repo, err := gitx.GetRepo(ctx, flagRepo)
+	if err != nil {
+		return fmt.Errorf("failed to get repository info: %w", err)
+	}
+
+	panes, err := createPRWorktrees(cmd, progress, repo, prNumbers, cfg)
+	if err != nil {
+		return err
+	}
+
+	return launchTmuxSession(progress, tmux.SessionConfig{
+		SessionName: tmuxPRSessionName(repo.Name, prNumbers, cfg.sessionName),
+		Panes:       panes,
+		Layout:      cfg.layout,
+		Windows:     cfg.windows,
+		NoAttach:    cfg.noAttach,
+		Debug:       flagDebug,
+	})
+}
+
+// createPRWorktrees creates the review worktree of each PR with createPRWorktree and returns them as panes
+// A failing PR is skipped with a warning. Only if none succeeds, the failures are returned.
+func createPRWorktrees(cmd *cobra.Command, progress *progressPrinter, repo *gitx.Repo, prNumbers []int, cfg *tmuxPRConfig) ([]tmux.Pane, error) {
+	prCfg := &prCmdConfig{force: cfg.force, setup: cfg.setup}
+
+	var panes []tmux.Pane
+	var failures []error
+	for _, prNumber := range prNumbers {
+		progress.Printf("\nPR #%d\n", prNumber)
+		wt, err := createPRWorktree(cmd, repo, prNumber, prCfg, progress)
+		if err != nil {
+			if ctxErr := cmd.Context().Err(); ctxErr != nil {
+				return nil, ctxErr // Interrupted: don't go on with the others
+			}
+			failures = append(failures, fmt.Errorf("PR #%d: %w", prNumber, err))
+			continue
+		}
+		if wt.Existing {
+			progress.Printf("  ✓ Using the worktree of branch %s: %s\n", wt.Branch, wt.Path)
+		} else {
+			progress.Printf("  ✓ %s -> %s\n", wt.Branch, wt.Path)
+		}
+		panes = append(panes, tmux.Pane{WorktreePath: wt.Path, BranchName: wt.Branch})
+	}
+
+	if len(panes) == 0 {
+		return nil, fmt.Errorf("no PR worktree could be created:\n%w", errors.Join(failures...))
+	}
+	printPRFailures(cmd.ErrOrStderr(), failures)
+	return panes, nil
+}
+
+// tmuxPRSessionName returns the session name for the PRs: the custom name if set, else one made of the PR numbers
+func tmuxPRSessionName(repoName string, prNumbers []int, custom string) string {
+	if custom != "" {
+		// Sanitize user-specified session name to prevent command injection
+		return naming.Sanitize(custom)
+	}
+	numbers := make([]string, len(prNumbers))
+	for i, n := range prNumbers {
+		numbers[i] = strconv.Itoa(n)
+	}
+	return naming.Sanitize(fmt.Sprintf("wt-%s-pr-%s", repoName, strings.Join(numbers, "-")))
+}
+
+// Output functions
+
+func printPRFailures(w io.Writer, failures []error) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nWarning: skipped %d PR(s):\n", len(failures))
+	for _, err := range failures {
+		fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(err.Error(), "\n", "\n    "))
+	}
+}
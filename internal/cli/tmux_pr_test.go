@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestCreatePRWorktrees(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the gh stub is a shell script")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	runGitForTest(t, repoPath, "branch", "feat-a")
+	runGitForTest(t, repoPath, "branch", "feat-b")
+	runGitForTest(t, repoPath, "remote", "add", "origin", repoPath)
+
+	// PR 102 doesn't exist
+	binDir := t.TempDir()
+	script := `#!/bin/sh
+case "$3" in
+101) branch=feat-a ;;
+103) branch=feat-b ;;
+*) echo "no pull requests found" >&2; exit 1 ;;
+esac
+echo '{"headRefName":"'$branch'","headRepositoryOwner":{"login":"octocat"},"headRepository":{"name":"test-repo"},"isCrossRepository":false}'
+`
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	repo, err := gitx.GetRepo(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+	var stderr bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(""))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&stderr)
+
+	panes, err := createPRWorktrees(cmd, newProgressPrinter(io.Discard, false, false), repo, []int{101, 102, 103}, &tmuxPRConfig{force: true})
+	if err != nil {
+		t.Fatalf("createPRWorktrees() returned error: %v", err)
+	}
+	if len(panes) != 2 || panes[0].BranchName != "feat-a" || panes[1].BranchName != "feat-b" {
+		t.Fatalf("createPRWorktrees() = %+v, want the worktrees of PR 101 and 103", panes)
+	}
+	for _, pane := range panes {
+		if _, err := os.Stat(pane.WorktreePath); err != nil {
+			t.Errorf("worktree %s was not created: %v", pane.WorktreePath, err)
+		}
+	}
+	if !strings.Contains(stderr.String(), "PR #102") {
+		t.Errorf("the failed PR should be listed, stderr: %s", stderr.String())
+	}
+
+	// Existing worktrees are reused; without any success, the failures are returned
+	if panes, err := createPRWorktrees(cmd, newProgressPrinter(io.Discard, false, false), repo, []int{101}, &tmuxPRConfig{}); err != nil || len(panes) != 1 {
+		t.Errorf("createPRWorktrees() for an existing worktree = %+v, %v", panes, err)
+	}
+	if _, err := createPRWorktrees(cmd, newProgressPrinter(io.Discard, false, false), repo, []int{102}, &tmuxPRConfig{}); err == nil || !strings.Contains(err.Error(), "PR #102") {
+		t.Errorf("createPRWorktrees() error = %v, want the failure of PR 102", err)
+	}
+}
+
+func TestTmuxPRSessionName(t *testing.T) {
+	if got := tmuxPRSessionName("myapp", []int{101, 102}, ""); got != "wt-myapp-pr-101-102" {
+		t.Errorf("tmuxPRSessionName() = %q, want wt-myapp-pr-101-102", got)
+	}
+	if got := tmuxPRSessionName("myapp", []int{101}, "review; ls"); got != "review-ls" {
+		t.Errorf("tmuxPRSessionName() with a custom name = %q, want review-ls", got)
+	}
+}
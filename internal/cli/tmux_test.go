@@ -1,9 +1,21 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
 	"github.com/toritori0318/git-wt/internal/naming"
+	"github.com/toritori0318/git-wt/internal/tmux"
 )
 
 func TestSessionNameSanitization(t *testing.T) {
@@ -111,3 +123,215 @@ func TestValidateLayout(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateMultipleWorktrees(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+	repo, err := gitx.GetRepo(ctx, "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	panes, err := createMultipleWorktrees(ctx, "multi", "", 4, 3, repo, t.TempDir(), setupOptions{}, strings.NewReader(""), newProgressPrinter(&out, false, flagQuiet), io.Discard)
+	if err != nil {
+		t.Fatalf("createMultipleWorktrees() returned error: %v", err)
+	}
+	if len(panes) != 4 {
+		t.Fatalf("createMultipleWorktrees() created %d worktrees, want 4", len(panes))
+	}
+	for i, pane := range panes {
+		if want := "multi-" + string(rune('1'+i)); pane.BranchName != want {
+			t.Errorf("pane %d branch = %q, want %q (panes must stay in order)", i, pane.BranchName, want)
+		}
+		if !pathExists(pane.WorktreePath) {
+			t.Errorf("worktree %s was not created", pane.WorktreePath)
+		}
+	}
+	if lines := strings.Count(out.String(), "✓"); lines != 4 {
+		t.Errorf("output has %d success lines, want 4:\n%s", lines, out.String())
+	}
+
+	// A failure stops the remaining worktrees and removes the unfinished ones
+	runGitForTest(t, repoPath, "branch", "broken-2/sub") // refs/heads/broken-2 can't be created
+	_, err = createMultipleWorktrees(ctx, "broken", "", 3, 2, repo, t.TempDir(), setupOptions{}, strings.NewReader(""), newProgressPrinter(io.Discard, false, false), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "broken-2") {
+		t.Fatalf("createMultipleWorktrees() = %v, want an error for broken-2", err)
+	}
+	for _, branch := range []string{"broken-1", "broken-3"} {
+		if exists, _ := gitx.BranchExists(ctx, branch); exists {
+			t.Errorf("branch %s should not be left behind", branch)
+		}
+		if wt, _ := gitx.FindWorktreeByBranch(ctx, branch); wt != nil {
+			t.Errorf("worktree %s should not be left behind", wt.Path)
+		}
+	}
+}
+
+func TestCreateMultipleWorktreesRollsBackRegistration(t *testing.T) {
+	setupTestRepo(t)
+	ctx := context.Background()
+	repo, err := gitx.GetRepo(ctx, "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+
+	// Registering the second worktree fails
+	orig := registerWorktree
+	registerWorktree = func(ctx context.Context, plan plannedWorktree, startPoint string) error {
+		if plan.branch == "retry-2" {
+			return errors.New("injected failure")
+		}
+		return orig(ctx, plan, startPoint)
+	}
+	baseDir := t.TempDir()
+	_, err = createMultipleWorktrees(ctx, "retry", "", 3, 2, repo, baseDir, setupOptions{}, strings.NewReader(""), newProgressPrinter(io.Discard, false, false), io.Discard)
+	registerWorktree = orig
+	if err == nil || !strings.Contains(err.Error(), "injected failure") {
+		t.Fatalf("createMultipleWorktrees() = %v, want the injected failure", err)
+	}
+	if exists, _ := gitx.BranchExists(ctx, "retry-1"); exists {
+		t.Error("branch retry-1 should be deleted again")
+	}
+	if wt, _ := gitx.FindWorktreeByBranch(ctx, "retry-1"); wt != nil {
+		t.Errorf("worktree %s should be removed again", wt.Path)
+	}
+
+	// Running it again creates the same worktrees
+	panes, err := createMultipleWorktrees(ctx, "retry", "", 3, 2, repo, baseDir, setupOptions{}, strings.NewReader(""), newProgressPrinter(io.Discard, false, false), io.Discard)
+	if err != nil {
+		t.Fatalf("createMultipleWorktrees() after the failure returned error: %v", err)
+	}
+	if len(panes) != 3 || panes[0].BranchName != "retry-1" {
+		t.Errorf("createMultipleWorktrees() after the failure = %+v, want retry-1 to retry-3", panes)
+	}
+}
+
+func TestAddWorktreeWithRetry(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	lock := filepath.Join(repoPath, ".git", "refs", "heads", "locked.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(lockRetryDelay + lockRetryDelay/2)
+		_ = os.Remove(lock)
+	}()
+
+	path := filepath.Join(t.TempDir(), "locked")
+	plan := plannedWorktree{branch: "locked", path: path, createBranch: true}
+	if err := addWorktreeWithRetry(context.Background(), plan, ""); err != nil {
+		t.Fatalf("addWorktreeWithRetry() returned error: %v", err)
+	}
+	if wt, _ := gitx.FindWorktreeByBranch(context.Background(), "locked"); wt == nil {
+		t.Error("worktree was not created after the lock was released")
+	}
+}
+
+func TestCreateMultipleWorktreesQuiet(t *testing.T) {
+	setupTestRepo(t)
+	quietForTest(t)
+	ctx := context.Background()
+	repo, err := gitx.GetRepo(ctx, "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	panes, err := createMultipleWorktrees(ctx, "quiet", "", 2, 2, repo, t.TempDir(), setupOptions{}, strings.NewReader(""), newProgressPrinter(&out, false, flagQuiet), io.Discard)
+	if err != nil {
+		t.Fatalf("createMultipleWorktrees() returned error: %v", err)
+	}
+	if len(panes) != 2 {
+		t.Fatalf("createMultipleWorktrees() created %d worktrees, want 2", len(panes))
+	}
+	if out.Len() != 0 {
+		t.Errorf("output with --quiet = %q, want nothing", out.String())
+	}
+}
+
+// fakeTmux records tmux commands and knows the sessions created with them
+type fakeTmux struct {
+	calls    [][]string
+	sessions map[string]bool
+}
+
+func (f *fakeTmux) Run(name string, args ...string) error {
+	f.calls = append(f.calls, args)
+	switch args[0] {
+	case "new-session":
+		f.sessions[args[3]] = true
+	case "has-session":
+		if !f.sessions[args[2]] {
+			return errors.New("no such session")
+		}
+	}
+	return nil
+}
+
+func (f *fakeTmux) Output(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, args)
+	return nil, nil
+}
+
+func TestCreateWorktreeSessions(t *testing.T) {
+	fake := &fakeTmux{sessions: map[string]bool{"wt-repo-feat-2": true}}
+	orig := newTmuxManager
+	newTmuxManager = func(name string) *tmux.Manager { return tmux.NewManagerWithExecutor(name, fake) }
+	t.Cleanup(func() { newTmuxManager = orig })
+
+	panes := []tmux.Pane{
+		{WorktreePath: "/work/feat-1", BranchName: "feat-1"},
+		{WorktreePath: "/work/feat-2", BranchName: "feat-2"},
+		{WorktreePath: "/work/feat-3", BranchName: "feat-3"},
+	}
+	names, err := createWorktreeSessions("wt-repo-feat", panes)
+	if err != nil {
+		t.Fatalf("createWorktreeSessions() returned error: %v", err)
+	}
+	want := []string{"wt-repo-feat-1", "wt-repo-feat-2", "wt-repo-feat-3"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("createWorktreeSessions() = %v, want %v", names, want)
+	}
+
+	var created, killed []string
+	for _, call := range fake.calls {
+		switch call[0] {
+		case "new-session":
+			created = append(created, call[3]+" "+call[5])
+		case "kill-session":
+			killed = append(killed, call[2])
+		case "split-window", "select-layout", "set-window-option":
+			t.Errorf("a session per worktree has a single pane, but ran: tmux %v", call)
+		}
+	}
+	wantCreated := []string{"wt-repo-feat-1 /work/feat-1", "wt-repo-feat-2 /work/feat-2", "wt-repo-feat-3 /work/feat-3"}
+	if !reflect.DeepEqual(created, wantCreated) {
+		t.Errorf("created sessions = %v, want %v", created, wantCreated)
+	}
+	if !reflect.DeepEqual(killed, []string{"wt-repo-feat-2"}) {
+		t.Errorf("killed sessions = %v, want the existing wt-repo-feat-2", killed)
+	}
+}
+
+func TestStartWorktreeSessionsQuiet(t *testing.T) {
+	fake := &fakeTmux{sessions: map[string]bool{}}
+	orig := newTmuxManager
+	newTmuxManager = func(name string) *tmux.Manager { return tmux.NewManagerWithExecutor(name, fake) }
+	t.Cleanup(func() { newTmuxManager = orig })
+	quietForTest(t)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	panes := []tmux.Pane{{WorktreePath: "/work/feat-1", BranchName: "feat-1"}, {WorktreePath: "/work/feat-2", BranchName: "feat-2"}}
+	if err := startWorktreeSessions(cmd, newProgressPrinter(&out, false, true), "wt-repo-feat", panes, true); err != nil {
+		t.Fatalf("startWorktreeSessions() returned error: %v", err)
+	}
+
+	// The attach commands are all a user of --session-per-worktree needs
+	want := "tmux attach -t wt-repo-feat-1\ntmux attach -t wt-repo-feat-2\n"
+	if out.String() != want {
+		t.Errorf("output with --quiet = %q, want %q", out.String(), want)
+	}
+}
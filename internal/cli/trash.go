@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/trash"
+)
+
+// Keys wt adds to the info files of trashed worktrees
+const (
+	trashKeyRepo   = "X-Wt-Repo"   // Common git directory of the repository
+	trashKeyBranch = "X-Wt-Branch" // Empty for a detached HEAD
+	trashKeyHead   = "X-Wt-Head"   // Commit checked out when the worktree was removed
+)
+
+// NoTrashedWorktreesError represents an error when the trash holds no worktrees of the repository
+type NoTrashedWorktreesError struct{}
+
+func (e *NoTrashedWorktreesError) Error() string {
+	return "no worktrees of this repository in the trash"
+}
+
+// moveWorktreeToTrash moves the worktree at wt.Path to the trash, then removes it from git
+// It returns where the worktree is in the trash.
+func moveWorktreeToTrash(ctx context.Context, wt gitx.Worktree, force bool) (string, error) {
+	if err := checkMovable(ctx, wt, force); err != nil {
+		return "", err
+	}
+	commonDir, err := gitx.CommonDir(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	item, err := trash.Move(wt.Path, map[string]string{
+		trashKeyRepo:   commonDir,
+		trashKeyBranch: wt.Branch,
+		trashKeyHead:   wt.HEAD,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w (remove it without --trash or clean.use_trash to delete it instead)", err)
+	}
+
+	// With the directory gone, this only removes the worktree metadata
+	if err := gitx.Remove(ctx, wt.Path, true); err != nil {
+		_ = trash.Restore(*item) // Best-effort rollback
+		return "", err
+	}
+	return item.Path, nil
+}
+
+// restoreResult is the JSON output of wt clean --restore
+type restoreResult struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+}
+
+func runRestore(cmd *cobra.Command, query string, cfg *cleanCmdConfig) error {
+	ctx := cmd.Context()
+
+	// The restored worktree is linked up again with git worktree repair
+	if err := gitx.RequireVersion(ctx, gitx.FeatureWorktreeRepair); err != nil {
+		return err
+	}
+
+	items, err := trashedWorktrees(ctx)
+	if err != nil {
+		return err
+	}
+	lines := make([]string, len(items))
+	for i, item := range items {
+		branch := item.Meta[trashKeyBranch]
+		if branch == "" {
+			branch = "(detached)"
+		}
+		lines[i] = fmt.Sprintf("%s\t%s\ttrashed %s", branch, item.OriginalPath, item.DeletedAt.Format("2006-01-02 15:04"))
+	}
+
+	selectedIndex, err := selectItem(ctx, cmd.InOrStdin(), cmd.ErrOrStderr(), lines, query, selectOptions{prompt: "Select worktree to restore", match: cfg.match})
+	if err != nil {
+		return err
+	}
+	item := items[selectedIndex]
+
+	if err := restoreWorktree(ctx, item); err != nil {
+		return err
+	}
+
+	result := restoreResult{Path: item.OriginalPath, Branch: item.Meta[trashKeyBranch]}
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+	printRestoreSuccess(cmd.OutOrStdout(), result, flagQuiet)
+	return nil
+}
+
+// trashedWorktrees returns the worktrees of the current repository in the trash
+func trashedWorktrees(ctx context.Context) ([]trash.Item, error) {
+	commonDir, err := gitx.CommonDir(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	items, err := trash.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []trash.Item
+	for _, item := range items {
+		if repo := item.Meta[trashKeyRepo]; repo != "" && gitx.SamePath(repo, commonDir) {
+			worktrees = append(worktrees, item)
+		}
+	}
+	if len(worktrees) == 0 {
+		return nil, &NoTrashedWorktreesError{}
+	}
+	return worktrees, nil
+}
+
+// restoreWorktree moves a trashed worktree back to its original path and registers it with git again
+// (see linkWorktreeDir). The branch is recreated at the trashed commit if it was deleted since.
+// Uncommitted changes are kept.
+func restoreWorktree(ctx context.Context, item trash.Item) error {
+	path := item.OriginalPath
+	if pathExists(path) {
+		return fmt.Errorf("cannot restore %s: the path already exists", path)
+	}
+	branch, head := item.Meta[trashKeyBranch], item.Meta[trashKeyHead]
+
+	ref, startPoint, createBranch, err := restoreCheckout(ctx, branch, head)
+	if err != nil {
+		return err
+	}
+
+	if err := prepareWorktreeParent(ctx, path); err != nil {
+		return err
+	}
+	return linkWorktreeDir(ctx, path, ref, startPoint, createBranch, func() error { return trash.Restore(item) })
+}
+
+// linkWorktreeDir registers the directory at path as a worktree of ref, keeping its files
+// git only creates worktrees in new directories, so the worktree is registered in a temporary
+// directory, whose .git file then replaces the one at path. place, if not nil, puts the directory
+// at path once the registration succeeded. Afterwards, the files that differ from ref show up as
+// uncommitted changes.
+func linkWorktreeDir(ctx context.Context, path, ref, startPoint string, createBranch bool, place func() error) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(path), ".wt-restore-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	registered := filepath.Join(tmpDir, filepath.Base(path))
+
+	if err := gitx.AddWithoutCheckout(ctx, registered, ref, startPoint, createBranch); err != nil {
+		return fmt.Errorf("failed to register worktree: %w", err)
+	}
+	if place != nil {
+		if err := place(); err != nil {
+			_ = gitx.Remove(ctx, registered, true)
+			return err
+		}
+	}
+	if err := os.Rename(filepath.Join(registered, ".git"), filepath.Join(path, ".git")); err != nil {
+		_ = gitx.Remove(ctx, registered, true)
+		return fmt.Errorf("failed to link %s to the repository: %w", path, err)
+	}
+	if _, err := gitx.Repair(ctx, path); err != nil {
+		return fmt.Errorf("failed to link %s to the repository: %w", path, err)
+	}
+	// The index is empty after --no-checkout: match it to HEAD so only real changes show up
+	if err := gitx.ResetIndex(ctx, path); err != nil {
+		return fmt.Errorf("failed to refresh the index: %w", err)
+	}
+	return nil
+}
+
+// restoreCheckout returns how to check out a restored worktree: its branch if it still exists,
+// otherwise a new branch (or detached HEAD) at the commit it had when it was trashed
+func restoreCheckout(ctx context.Context, branch, head string) (ref, startPoint string, createBranch bool, err error) {
+	if branch != "" {
+		if err := checkBranchNotInUse(ctx, branch); err != nil {
+			return "", "", false, err
+		}
+		exists, err := gitx.BranchExists(ctx, branch)
+		if err != nil {
+			return "", "", false, fmt.Errorf("failed to check branch existence: %w", err)
+		}
+		if exists {
+			return branch, "", false, nil
+		}
+	}
+	if head == "" || !gitx.CommitExists(ctx, head) {
+		return "", "", false, fmt.Errorf("cannot restore: branch '%s' and commit %s no longer exist", branch, head)
+	}
+	if branch == "" {
+		return head, "", false, nil
+	}
+	return branch, head, true, nil
+}
+
+// Output functions
+
+func printRestoreSuccess(w io.Writer, result restoreResult, quiet bool) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(w, "✓ Worktree restored: %s\n", result.Path)
+	if result.Branch != "" {
+		fmt.Fprintf(w, "  Branch: %s\n", result.Branch)
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+)
+
+func TestCleanTrashAndRestore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature", wtPath)
+	runGitForTest(t, wtPath, "commit", "--allow-empty", "-m", "Unmerged work")
+	head := strings.TrimSpace(runGitForTest(t, wtPath, "rev-parse", "HEAD"))
+	if err := os.WriteFile(filepath.Join(wtPath, "notes.txt"), []byte("uncommitted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{trash: true, force: true, yes: true, keepBranch: true}); err != nil {
+		t.Fatalf("wt clean --trash returned error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "✓ Worktree moved to the trash: "+wtPath) {
+		t.Errorf("output should report the move to the trash, got: %s", stdout.String())
+	}
+	if pathExists(wtPath) {
+		t.Fatalf("%s should have been moved to the trash", wtPath)
+	}
+	trashed := filepath.Join(os.Getenv("XDG_DATA_HOME"), "Trash", "files", "feature", "notes.txt")
+	if !pathExists(trashed) {
+		t.Fatalf("worktree files should be in the trash at %s", trashed)
+	}
+	if list := runGitForTest(t, repoPath, "worktree", "list"); strings.Contains(list, wtPath) {
+		t.Errorf("worktree is still registered:\n%s", list)
+	}
+
+	// The branch was deleted after trashing: restoring recreates it at the trashed commit
+	runGitForTest(t, repoPath, "branch", "-D", "feature")
+
+	stdout.Reset()
+	cmd = newCleanCmd()
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	if err := runCleanWithConfig(cmd, []string{"feature"}, &cleanCmdConfig{restore: true}); err != nil {
+		t.Fatalf("wt clean --restore returned error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "✓ Worktree restored: "+wtPath) {
+		t.Errorf("output should report the restore, got: %s", stdout.String())
+	}
+
+	wt, err := gitx.FindWorktreeByBranch(context.Background(), "feature")
+	if err != nil || wt == nil || !gitx.SamePath(wt.Path, wtPath) {
+		t.Fatalf("FindWorktreeByBranch(feature) = %+v, %v, want %s", wt, err, wtPath)
+	}
+	if got := strings.TrimSpace(runGitForTest(t, wtPath, "rev-parse", "HEAD")); got != head {
+		t.Errorf("restored HEAD = %s, want %s", got, head)
+	}
+	if status := runGitForTest(t, wtPath, "status", "--porcelain"); strings.TrimSpace(status) != "?? notes.txt" {
+		t.Errorf("status of the restored worktree = %q, want only the uncommitted file", status)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(wtPath)); len(entries) != 1 {
+		t.Errorf("temporary directories were left next to the worktree: %v", entries)
+	}
+
+	// Nothing left to restore
+	cmd = newCleanCmd()
+	cmd.SetContext(context.Background())
+	err = runCleanWithConfig(cmd, nil, &cleanCmdConfig{restore: true})
+	if exitCode(err) != ExitNotFound {
+		t.Errorf("wt clean --restore with an empty trash: error = %v, want exit code %d", err, ExitNotFound)
+	}
+}
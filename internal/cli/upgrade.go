@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/update"
+)
+
+const (
+	// upgradeCheckTimeout limits the GitHub request of wt upgrade --check
+	upgradeCheckTimeout = 10 * time.Second
+	// passiveCheckTimeout limits the daily update check so it doesn't hold up other commands
+	passiveCheckTimeout = 2 * time.Second
+)
+
+// UpgradeCheckRequiredError is returned when wt upgrade is run without --check
+type UpgradeCheckRequiredError struct{}
+
+func (e *UpgradeCheckRequiredError) Error() string {
+	return "wt doesn't download updates itself: use 'wt upgrade --check' to look for a new version"
+}
+
+// upgradeResult is the output of wt upgrade --check
+type upgradeResult struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"update_available"`
+	URL             string `json:"url"`
+}
+
+type upgradeCmdConfig struct {
+	check bool
+}
+
+func newUpgradeCmd() *cobra.Command {
+	cfg := &upgradeCmdConfig{}
+
+	cmd := withJSON(&cobra.Command{
+		Use:   "upgrade",
+		Short: "Check for a newer version of wt",
+		Long: `Check GitHub releases for a newer version of wt.
+
+wt only reports new versions; install them the same way you installed wt
+(e.g. brew upgrade, go install or a release download).
+
+Set update_check.enabled to also check at most once a day while running other
+commands. WT_NO_UPDATE_CHECK disables that check.
+
+Examples:
+  wt upgrade --check
+  wt upgrade --check --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(cmd, cfg)
+		},
+	})
+
+	cmd.Flags().BoolVar(&cfg.check, "check", false, "Check whether a newer version is available")
+
+	return cmd
+}
+
+var upgradeCmd = newUpgradeCmd()
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, cfg *upgradeCmdConfig) error {
+	if !cfg.check {
+		return &UsageError{Err: &UpgradeCheckRequiredError{}}
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), upgradeCheckTimeout)
+	defer cancel()
+
+	release, err := update.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := readBuildInfo().Version
+	result := upgradeResult{
+		Current:         current,
+		Latest:          release.Version,
+		UpdateAvailable: update.IsNewer(release.Version, current),
+		URL:             release.URL,
+	}
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), result)
+	}
+	printUpgradeResult(cmd.OutOrStdout(), result)
+	return nil
+}
+
+// checkForUpdate runs the opt-in daily update check after a command and reports a newer version on stderr
+// It stays silent when the check is disabled, not due, or fails.
+func checkForUpdate(cmd *cobra.Command) {
+	if jsonOutput() || isPlumbing(cmd) {
+		return
+	}
+	switch cmd.Name() {
+	case "upgrade", "hook", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+	if os.Getenv(update.DisableEnv) != "" {
+		return
+	}
+	wtCfg, err := loadWorktreeConfig(cmd.Context())
+	if err != nil || !wtCfg.GetUpdateCheck() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), passiveCheckTimeout)
+	defer cancel()
+
+	current := readBuildInfo().Version
+	if release := update.CheckDaily(ctx, current, time.Now()); release != nil {
+		printUpdateNotice(cmd.ErrOrStderr(), current, release)
+	}
+}
+
+// Output functions
+
+func printUpgradeResult(w io.Writer, result upgradeResult) {
+	if !update.IsVersion(result.Current) {
+		fmt.Fprintf(w, "wt %s is a development build; the latest release is %s\n", result.Current, result.Latest)
+		fmt.Fprintf(w, "  %s\n", result.URL)
+		return
+	}
+	if !result.UpdateAvailable {
+		fmt.Fprintf(w, "wt %s is up to date (latest release: %s)\n", result.Current, result.Latest)
+		return
+	}
+	fmt.Fprintf(w, "A new version of wt is available: %s (current: %s)\n", result.Latest, result.Current)
+	fmt.Fprintf(w, "  %s\n", result.URL)
+}
+
+func printUpdateNotice(w io.Writer, current string, release *update.Release) {
+	fmt.Fprintf(w, "\nA new version of wt is available: %s (current: %s)\n", release.Version, current)
+	fmt.Fprintf(w, "  %s\n", release.URL)
+	fmt.Fprintf(w, "  (disable this check with: wt config set update_check.enabled false)\n")
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// buildInfo describes the running wt binary
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Module    string `json:"module"` // Module path the binary was built from (differs in forks)
+}
+
+// readBuildInfo returns the build information set with SetVersionInfo
+// Values left at their defaults (e.g. for go install builds without -ldflags) are filled in from
+// the module version and VCS information embedded by the Go toolchain.
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   versionInfo,
+		Commit:    commitInfo,
+		Date:      dateInfo,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = bi.Main.Path
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch {
+		case s.Key == "vcs.revision" && info.Commit == "unknown":
+			info.Commit = s.Value
+		case s.Key == "vcs.time" && info.Date == "unknown":
+			info.Date = s.Value
+		}
+	}
+	return info
+}
+
+// versionString returns the version shown by wt version and wt --version
+func versionString(info buildInfo) string {
+	return fmt.Sprintf("%s (commit: %s, built: %s)", info.Version, info.Commit, info.Date)
+}
+
+func newVersionCmd() *cobra.Command {
+	return withJSON(&cobra.Command{
+		Use:   "version",
+		Short: "Show version information",
+		Long: `Show the version, commit and build date of wt, and the Go toolchain,
+platform and module it was built with.
+
+With --json, prints {version, commit, date, go_version, os, arch, module}.`,
+		Args: cobra.NoArgs,
+		RunE: runVersion,
+	})
+}
+
+var versionCmd = newVersionCmd()
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := readBuildInfo()
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), info)
+	}
+	printVersion(cmd.OutOrStdout(), info)
+	return nil
+}
+
+func printVersion(w io.Writer, info buildInfo) {
+	fmt.Fprintf(w, "wt version %s\n", versionString(info))
+	fmt.Fprintf(w, "  %s %s/%s", info.GoVersion, info.OS, info.Arch)
+	if info.Module != "" {
+		fmt.Fprintf(w, ", module %s", info.Module)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadBuildInfo(t *testing.T) {
+	origVersion, origCommit, origDate := versionInfo, commitInfo, dateInfo
+	t.Cleanup(func() { versionInfo, commitInfo, dateInfo = origVersion, origCommit, origDate })
+
+	versionInfo, commitInfo, dateInfo = "1.2.3", "abc123", "2024-01-01"
+	info := readBuildInfo()
+
+	if info.Version != "1.2.3" || info.Commit != "abc123" || info.Date != "2024-01-01" {
+		t.Errorf("readBuildInfo() = %+v, want the values set with SetVersionInfo", info)
+	}
+	if info.GoVersion != runtime.Version() || info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("readBuildInfo() = %+v, want the running toolchain and platform", info)
+	}
+}
+
+func TestRunVersionJSON(t *testing.T) {
+	orig := flagJSON
+	t.Cleanup(func() { flagJSON = orig })
+	flagJSON = true
+
+	var buf bytes.Buffer
+	cmd := newVersionCmd()
+	cmd.SetOut(&buf)
+	if err := runVersion(cmd, nil); err != nil {
+		t.Fatalf("runVersion() returned error: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	for _, key := range []string{"version", "commit", "date", "go_version", "os", "arch", "module"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("output is missing %q: %s", key, buf.String())
+		}
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	var buf bytes.Buffer
+	printVersion(&buf, buildInfo{
+		Version: "1.2.3", Commit: "abc123", Date: "2024-01-01",
+		GoVersion: "go1.23.0", OS: "linux", Arch: "amd64", Module: "github.com/toritori0318/git-wt",
+	})
+
+	want := "wt version 1.2.3 (commit: abc123, built: 2024-01-01)\n  go1.23.0 linux/amd64, module github.com/toritori0318/git-wt\n"
+	if buf.String() != want {
+		t.Errorf("printVersion() = %q, want %q", buf.String(), want)
+	}
+	if !strings.HasPrefix(buf.String(), "wt version "+versionString(buildInfo{Version: "1.2.3", Commit: "abc123", Date: "2024-01-01"})) {
+		t.Error("printVersion() should start like wt --version")
+	}
+}
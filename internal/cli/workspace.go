@@ -0,0 +1,469 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/tablex"
+)
+
+// Outcomes of wt workspace for a repository
+const (
+	workspaceCreated = "created"
+	workspaceRemoved = "removed"
+	workspaceSkipped = "skipped"
+	workspaceFailed  = "failed"
+)
+
+// WorkspaceNotFoundError represents an error when the workspaces file has no workspace of the given name
+type WorkspaceNotFoundError struct {
+	Name  string
+	Known []string // Names of the defined workspaces
+	Path  string   // The workspaces file
+}
+
+func (e *WorkspaceNotFoundError) Error() string {
+	if len(e.Known) == 0 {
+		return fmt.Sprintf("no workspaces defined (add them to %s)", e.Path)
+	}
+	return fmt.Sprintf("unknown workspace: %s (defined: %s)", e.Name, strings.Join(e.Known, ", "))
+}
+
+// WorkspaceFailedError represents an error when wt workspace failed in some repositories
+type WorkspaceFailedError struct {
+	Failed int
+	Total  int
+}
+
+func (e *WorkspaceFailedError) Error() string {
+	return fmt.Sprintf("failed in %d of %d repositories", e.Failed, e.Total)
+}
+
+// workspaceResult is the outcome of wt workspace new or clean for one repository
+type workspaceResult struct {
+	Repo          string `json:"repo"`             // Repository root from the workspaces file
+	Path          string `json:"path,omitempty"`   // The worktree of the branch
+	Result        string `json:"result"`           // created, removed, skipped or failed
+	Reason        string `json:"reason,omitempty"` // Why the repository was skipped or failed, or why the branch was kept
+	BranchDeleted bool   `json:"branch_deleted,omitempty"`
+}
+
+type workspaceNewCmdConfig struct {
+	push          bool
+	noRemoteCheck bool
+	setup         setupOptions
+}
+
+type workspaceCleanCmdConfig struct {
+	force      bool
+	keepBranch bool
+}
+
+type workspaceGoCmdConfig struct {
+	cd bool
+}
+
+func newWorkspaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Create and remove worktrees of a branch across several repositories",
+		Long: `Work on the same branch in a group of repositories at once.
+
+Workspaces are defined in ~/.config/wt/workspaces.yaml ($XDG_CONFIG_HOME/wt), which maps
+each workspace name to the roots of its repositories:
+
+  services:
+    - ~/src/api
+    - ~/src/billing
+    - ~/src/web
+
+The workspace name can be left out when the file defines only one workspace. Each
+repository is handled like wt new or wt clean in it (with its own configuration and
+.wt.yaml); a failure in one repository doesn't stop the others, and the results are
+listed at the end.
+
+Examples:
+  wt workspace new services feature/x
+  wt workspace go services feature/x web --cd
+  wt workspace clean services feature/x`,
+	}
+
+	cmd.AddCommand(newWorkspaceNewCmd(), newWorkspaceCleanCmd(), newWorkspaceGoCmd())
+	return cmd
+}
+
+func newWorkspaceNewCmd() *cobra.Command {
+	cfg := &workspaceNewCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "new [<workspace>] <branch>",
+		Short: "Create a worktree for branch in every repository of a workspace",
+		Long: `Create a worktree for branch in every repository of a workspace, like wt new.
+
+An existing branch is checked out; otherwise the branch is created from the
+repository's current HEAD.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runWorkspaceNew(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.push, "push", false, "Push new branches to worktree.default_remote and set their upstream")
+	cmd.Flags().BoolVar(&cfg.noRemoteCheck, "no-remote-check", false, "Don't check for remote branches of the same name with different history")
+	addSetupFlags(cmd, &cfg.setup)
+
+	return withJSON(cmd)
+}
+
+func newWorkspaceCleanCmd() *cobra.Command {
+	cfg := &workspaceCleanCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "clean [<workspace>] <branch>",
+		Short: "Remove the worktrees of branch in every repository of a workspace",
+		Long: `Remove the worktrees of branch in every repository of a workspace, like wt clean.
+
+The worktrees are listed and removed after one confirmation (--yes skips it).
+Repositories without a worktree for branch are skipped. The branch is then deleted
+where it is merged; unmerged and protected branches (clean.protected_branches) are
+kept, and so are all branches with --keep-branch.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runWorkspaceClean(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Remove worktrees even with uncommitted changes (WARNING: may lose work)")
+	cmd.Flags().BoolVar(&cfg.keepBranch, "keep-branch", false, "Keep the branches")
+
+	return withJSON(cmd)
+}
+
+func newWorkspaceGoCmd() *cobra.Command {
+	cfg := &workspaceGoCmdConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "go [<workspace>] <branch> [<repo>]",
+		Short: "Print the path of branch's worktree in one repository of a workspace",
+		Long: `Print the path of branch's worktree in one repository of a workspace.
+
+The repositories that have a worktree for branch are offered for selection, or
+narrowed down by the repo query (matched like wt go against the repository name and
+worktree path). With --cd and the shell function, the shell changes to the worktree.`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runWorkspaceGo(c, args, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.cd, "cd", false, "Output only the worktree path (for cd with shell function)")
+
+	return withJSON(cmd)
+}
+
+func init() {
+	rootCmd.AddCommand(newWorkspaceCmd())
+}
+
+func runWorkspaceNew(cmd *cobra.Command, args []string, cfg *workspaceNewCmdConfig) error {
+	ws, rest, err := resolveWorkspaceArgs(args, 1)
+	if err != nil {
+		return err
+	}
+	branch := rest[0]
+	if err := validateBranchName(branch); err != nil {
+		return err
+	}
+
+	newCfg := &newCmdConfig{push: cfg.push, noRemoteCheck: cfg.noRemoteCheck, setup: cfg.setup}
+	results := make([]workspaceResult, len(ws.Repos))
+	for i, repoPath := range ws.Repos {
+		results[i] = workspaceResult{Repo: repoPath}
+		ctx := workspaceRepoContext(cmd.Context(), repoPath)
+		if _, err := gitx.GetRepo(ctx, ""); err != nil {
+			results[i].fail(fmt.Errorf("not a git repository"))
+			continue
+		}
+
+		printWorkspaceRepoHeader(cmd.ErrOrStderr(), repoPath)
+		created, _, err := createNewWorktree(ctx, cmd, branch, "", newCfg)
+		if err != nil {
+			results[i].fail(err)
+			continue
+		}
+		results[i].Path = created.Path
+		results[i].Result = workspaceCreated
+	}
+
+	return reportWorkspaceResults(cmd, results)
+}
+
+func runWorkspaceClean(cmd *cobra.Command, args []string, cfg *workspaceCleanCmdConfig) error {
+	ws, rest, err := resolveWorkspaceArgs(args, 1)
+	if err != nil {
+		return err
+	}
+	branch := rest[0]
+
+	// Find all worktrees first, so that a single confirmation covers them
+	results := make([]workspaceResult, len(ws.Repos))
+	worktrees := make([]*gitx.Worktree, len(ws.Repos))
+	for i, repoPath := range ws.Repos {
+		results[i] = workspaceResult{Repo: repoPath}
+		ctx := workspaceRepoContext(cmd.Context(), repoPath)
+		repo, err := gitx.GetRepo(ctx, "")
+		if err != nil {
+			results[i].fail(fmt.Errorf("not a git repository"))
+			continue
+		}
+		wt, err := gitx.FindWorktreeByBranch(ctx, branch)
+		switch {
+		case err != nil:
+			results[i].fail(fmt.Errorf("failed to get worktrees: %w", err))
+		case wt == nil:
+			results[i].Result = workspaceSkipped
+			results[i].Reason = "no worktree for " + branch
+		case wt.Path == repo.Root:
+			results[i].Result = workspaceSkipped
+			results[i].Reason = "the main worktree has the branch checked out"
+		default:
+			worktrees[i] = wt
+			results[i].Path = wt.Path
+		}
+	}
+
+	if !flagYes && hasWorktrees(worktrees) {
+		printWorkspaceRemovalConfirmation(cmd.ErrOrStderr(), ws.Name, worktrees)
+		confirmed, err := confirmWith(cmd.Context(), cmd.InOrStdin(), cmd.ErrOrStderr(), "Are you sure?", "--yes")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return &WorktreeRemovalCancelledError{}
+		}
+	}
+
+	for i, wt := range worktrees {
+		if wt != nil {
+			ctx := workspaceRepoContext(cmd.Context(), ws.Repos[i])
+			removeWorkspaceWorktree(ctx, *wt, cfg, &results[i])
+		}
+	}
+
+	return reportWorkspaceResults(cmd, results)
+}
+
+func runWorkspaceGo(cmd *cobra.Command, args []string, cfg *workspaceGoCmdConfig) error {
+	if err := checkShellFunction(cfg.cd); err != nil {
+		return err
+	}
+
+	ws, rest, err := resolveWorkspaceArgs(args, 2)
+	if err != nil {
+		return err
+	}
+	branch := rest[0]
+	query := ""
+	if len(rest) > 1 {
+		query = rest[1]
+	}
+
+	var found []gitx.Worktree
+	var items []string
+	for _, repoPath := range ws.Repos {
+		ctx := workspaceRepoContext(cmd.Context(), repoPath)
+		wt, err := gitx.FindWorktreeByBranch(ctx, branch)
+		if err != nil || wt == nil {
+			continue
+		}
+		found = append(found, *wt)
+		items = append(items, fmt.Sprintf("%s\t%s", filepath.Base(repoPath), wt.Path))
+	}
+	if len(found) == 0 {
+		return &NoMatchError{Query: branch}
+	}
+
+	index, err := selectItem(cmd.Context(), cmd.InOrStdin(), cmd.ErrOrStderr(), items, query, selectOptions{prompt: "Select repository"})
+	if err != nil {
+		return err
+	}
+	selected := found[index]
+
+	if jsonOutput() {
+		return writeJSON(cmd.OutOrStdout(), newWorktreeJSON(selected))
+	}
+	printGoResult(cmd.OutOrStdout(), &selected, "", cfg.cd || flagQuiet)
+	return nil
+}
+
+// resolveWorkspaceArgs returns the workspace args start with and the arguments after it
+// The name may be left out when the workspaces file defines a single workspace; at most
+// maxRest arguments may follow.
+func resolveWorkspaceArgs(args []string, maxRest int) (*config.Workspace, []string, error) {
+	if flagRepo != "" {
+		return nil, nil, &UsageError{Err: fmt.Errorf("--repo doesn't apply to wt workspace: the repositories are listed in the workspaces file")}
+	}
+
+	path, err := config.GetWorkspacesPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	workspaces, err := config.LoadWorkspaces(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(args) > 1 {
+		for i := range workspaces {
+			if workspaces[i].Name == args[0] {
+				return &workspaces[i], args[1:], nil
+			}
+		}
+	}
+	if len(workspaces) == 1 && len(args) <= maxRest {
+		return &workspaces[0], args, nil
+	}
+
+	known := make([]string, len(workspaces))
+	for i, ws := range workspaces {
+		known[i] = ws.Name
+	}
+	return nil, nil, &WorkspaceNotFoundError{Name: args[0], Known: known, Path: path}
+}
+
+// workspaceRepoContext returns a copy of ctx that runs git in the repository at path
+// The repository gets its own caches of git results and configuration (including its .wt.yaml).
+func workspaceRepoContext(ctx context.Context, path string) context.Context {
+	ctx = gitx.WithWorkDir(ctx, path)
+	ctx = gitx.WithSession(ctx, gitx.NewSession())
+	return withConfigCache(ctx)
+}
+
+// removeWorkspaceWorktree removes wt and, where it is merged, its branch, recording the outcome in result
+func removeWorkspaceWorktree(ctx context.Context, wt gitx.Worktree, cfg *workspaceCleanCmdConfig, result *workspaceResult) {
+	if wt.IsLocked {
+		result.fail(fmt.Errorf("worktree is locked: %s", lockReasonOrDefault(wt.LockReason)))
+		return
+	}
+	if err := removeWorktree(ctx, newProgressPrinter(io.Discard, false, true), wt, &cleanCmdConfig{force: cfg.force}, &cleanResult{Path: wt.Path}); err != nil {
+		result.fail(err)
+		return
+	}
+	removeEmptyWorktreeParents(ctx, wt.Path)
+	_ = gitx.Prune(ctx) // Best-effort cleanup
+	result.Result = workspaceRemoved
+
+	if cfg.keepBranch || wt.Branch == "" {
+		return
+	}
+	result.Reason = keepWorkspaceBranchReason(ctx, wt)
+	if result.Reason != "" {
+		return
+	}
+	if _, err := deleteBranchRecorded(ctx, wt.Branch, false); err != nil {
+		result.Reason = fmt.Sprintf("branch kept: %v", err)
+		return
+	}
+	result.BranchDeleted = true
+}
+
+// keepWorkspaceBranchReason returns why wt's branch is kept after removing wt, or "" to delete it
+// Without a prompt for each repository, only merged branches are deleted.
+func keepWorkspaceBranchReason(ctx context.Context, wt gitx.Worktree) string {
+	if inUse, err := gitx.IsUsingBranch(ctx, wt.Branch, wt.Path); err != nil || inUse {
+		return "branch kept: in use by another worktree"
+	}
+	if pattern, err := protectedBranchPattern(ctx, wt.Branch); err != nil || pattern != "" {
+		return "branch kept: protected"
+	}
+	if merged, err := gitx.IsBranchMerged(ctx, wt.Branch); err != nil || !merged {
+		return "branch kept: not merged"
+	}
+	return ""
+}
+
+// fail records err as the reason the repository failed (the first line: details are for single-repository commands)
+func (r *workspaceResult) fail(err error) {
+	r.Result = workspaceFailed
+	r.Reason, _, _ = strings.Cut(err.Error(), "\n")
+}
+
+// hasWorktrees reports whether any repository has a worktree to remove
+func hasWorktrees(worktrees []*gitx.Worktree) bool {
+	for _, wt := range worktrees {
+		if wt != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reportWorkspaceResults prints the results of all repositories and returns an error if any failed
+func reportWorkspaceResults(cmd *cobra.Command, results []workspaceResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Result == workspaceFailed {
+			failed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := writeJSON(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+		if failed > 0 {
+			// The failures are in the results
+			return &reportedError{err: &WorkspaceFailedError{Failed: failed, Total: len(results)}}
+		}
+		return nil
+	}
+
+	printWorkspaceResults(cmd.OutOrStdout(), results)
+	if failed > 0 {
+		return &WorkspaceFailedError{Failed: failed, Total: len(results)}
+	}
+	return nil
+}
+
+// Output functions
+
+func printWorkspaceRepoHeader(w io.Writer, repoPath string) {
+	if flagQuiet {
+		return
+	}
+	fmt.Fprintf(w, "==> %s\n", filepath.Base(repoPath))
+}
+
+func printWorkspaceRemovalConfirmation(w io.Writer, name string, worktrees []*gitx.Worktree) {
+	fmt.Fprintf(w, "The following worktrees of workspace %s will be removed:\n", name)
+	for _, wt := range worktrees {
+		if wt != nil {
+			fmt.Fprintf(w, "  %s\n", wt.Path)
+		}
+	}
+}
+
+func printWorkspaceResults(w io.Writer, results []workspaceResult) {
+	table := newTable(w, "  ")
+	for _, r := range results {
+		detail, color := r.Path, tablex.Green
+		switch {
+		case r.Result == workspaceFailed:
+			detail, color = r.Reason, tablex.Red
+		case r.Result == workspaceSkipped:
+			detail, color = r.Reason, tablex.Yellow
+		case r.BranchDeleted:
+			detail += " (branch deleted)"
+		case r.Reason != "":
+			detail += " (" + r.Reason + ")"
+		}
+		table.AddCells(tablex.Cell{Text: filepath.Base(r.Repo)}, tablex.Cell{Text: r.Result, Color: color}, tablex.Cell{Text: detail})
+	}
+	_ = table.Render(w)
+}
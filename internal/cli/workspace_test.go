@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorkspacesForTest writes the workspaces file under $XDG_CONFIG_HOME
+func writeWorkspacesForTest(t *testing.T, content string) {
+	t.Helper()
+
+	dir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "wt")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "workspaces.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workspaces file: %v", err)
+	}
+}
+
+func TestResolveWorkspaceArgs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	_, _, err := resolveWorkspaceArgs([]string{"feature"}, 1)
+	var notFound *WorkspaceNotFoundError
+	if !errors.As(err, &notFound) || !strings.Contains(err.Error(), "no workspaces defined") {
+		t.Errorf("resolveWorkspaceArgs() without workspaces error = %v, want WorkspaceNotFoundError", err)
+	}
+
+	writeWorkspacesForTest(t, "services:\n  - /src/api\n")
+	ws, rest, err := resolveWorkspaceArgs([]string{"feature"}, 1)
+	if err != nil || ws.Name != "services" || len(rest) != 1 {
+		t.Errorf("resolveWorkspaceArgs() with a single workspace = %v, %q, %v, want services and the branch", ws, rest, err)
+	}
+	ws, rest, err = resolveWorkspaceArgs([]string{"services", "feature", "api"}, 2)
+	if err != nil || ws.Name != "services" || len(rest) != 2 {
+		t.Errorf("resolveWorkspaceArgs() with the name = %v, %q, %v, want services and two arguments", ws, rest, err)
+	}
+
+	writeWorkspacesForTest(t, "services:\n  - /src/api\nweb:\n  - /src/web\n")
+	if _, _, err := resolveWorkspaceArgs([]string{"feature"}, 1); !errors.As(err, &notFound) || !strings.Contains(err.Error(), "defined: services, web") {
+		t.Errorf("resolveWorkspaceArgs() without a name error = %v, want the workspaces listed", err)
+	}
+}
+
+func TestRunWorkspaceNewAndClean(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	apiPath := setupTestRepo(t)
+	webPath := setupTestRepo(t)
+	missingPath := filepath.Join(t.TempDir(), "missing")
+	writeWorkspacesForTest(t, "services:\n  - "+apiPath+"\n  - "+webPath+"\n  - "+missingPath+"\n")
+
+	// A failing repository doesn't stop the others
+	var stdout bytes.Buffer
+	cmd := newWorkspaceNewCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetContext(context.Background())
+	err := runWorkspaceNew(cmd, []string{"services", "feature/x"}, &workspaceNewCmdConfig{noRemoteCheck: true})
+	var failed *WorkspaceFailedError
+	if !errors.As(err, &failed) || failed.Failed != 1 || failed.Total != 3 {
+		t.Fatalf("runWorkspaceNew() error = %v, want 1 of 3 repositories failed", err)
+	}
+	for _, repo := range []string{apiPath, webPath} {
+		if list := runGitForTest(t, repo, "worktree", "list"); !strings.Contains(list, "[feature/x]") {
+			t.Errorf("worktree list of %s = %q, want a worktree for feature/x", repo, list)
+		}
+	}
+	if !strings.Contains(stdout.String(), "failed   not a git repository") {
+		t.Errorf("output = %q, want the failed repository listed", stdout.String())
+	}
+
+	// The branch is merged (it has no commits of its own), so it is deleted as well
+	orig := flagYes
+	flagYes = true
+	t.Cleanup(func() { flagYes = orig })
+	stdout.Reset()
+	cmd = newWorkspaceCleanCmd()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetContext(context.Background())
+	if err := runWorkspaceClean(cmd, []string{"services", "feature/x"}, &workspaceCleanCmdConfig{}); !errors.As(err, &failed) {
+		t.Fatalf("runWorkspaceClean() error = %v, want WorkspaceFailedError for the missing repository", err)
+	}
+	for _, repo := range []string{apiPath, webPath} {
+		if list := runGitForTest(t, repo, "worktree", "list"); strings.Contains(list, "feature/x") {
+			t.Errorf("worktree list of %s = %q, want the feature/x worktree removed", repo, list)
+		}
+		if branches := runGitForTest(t, repo, "branch", "--list", "feature/x"); branches != "" {
+			t.Errorf("branches of %s = %q, want feature/x deleted", repo, branches)
+		}
+	}
+	if got := strings.Count(stdout.String(), "removed"); got != 2 {
+		t.Errorf("output = %q, want two removed worktrees", stdout.String())
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/toritori0318/git-wt/internal/config"
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+// WorktreeDirError represents a directory that can't hold new worktrees
+type WorktreeDirError struct {
+	Path    string
+	Problem string
+}
+
+func (e *WorktreeDirError) Error() string {
+	return fmt.Sprintf("cannot create worktrees in %s: %s", e.Path, e.Problem)
+}
+
+// prepareWorktreeParent creates the missing directories above the worktree at path
+// Created directories get worktree.container_dir_mode (or git's default, following the umask).
+// An existing directory, or a symbolic link to one, is used as is; it must be writable, so that
+// the failure is reported here instead of as a git error.
+func prepareWorktreeParent(ctx context.Context, path string) error {
+	var missing []string
+	dir := filepath.Dir(path)
+	for {
+		info, err := os.Stat(dir) // Follows symbolic links
+		if err == nil {
+			if !info.IsDir() {
+				return &WorktreeDirError{Path: dir, Problem: "it exists but is not a directory"}
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return &WorktreeDirError{Path: dir, Problem: errors.Unwrap(err).Error()}
+		}
+		if _, err := os.Lstat(dir); err == nil {
+			return &WorktreeDirError{Path: dir, Problem: "it is a broken symbolic link"}
+		}
+		missing = append(missing, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".wt-*")
+	if err != nil {
+		return &WorktreeDirError{Path: dir, Problem: "it is not writable"}
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	if len(missing) == 0 {
+		return nil
+	}
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	perm := wtCfg.ContainerDirPerm()
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0777); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		// Mkdir applies the umask, which would mask bits the configured mode asks for
+		if perm != 0 {
+			if err := os.Chmod(missing[i], perm); err != nil {
+				return fmt.Errorf("failed to set directory permissions: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// generateWorktreePath generates the path for a new worktree of branch
+// Paths of registered worktrees count as taken even if their directory was deleted. With
+// worktree.collision_strategy "prompt", the user is asked on errW whether to use a numbered path.
+// current is the path of a worktree being moved ("" otherwise); a collision with it is never prompted for.
+func generateWorktreePath(ctx context.Context, r io.Reader, errW io.Writer, baseDir, repoName, branch, current string) (string, error) {
+	worktrees, err := gitx.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktrees: %w", err)
+	}
+	registered := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		registered[i] = wt.Path
+	}
+
+	wtCfg, err := loadWorktreeConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, repoName, branch, registered, wtCfg)
+	var collision *naming.CollisionError
+	if !errors.As(err, &collision) || collision.Path == current {
+		return path, err
+	}
+	if wtCfg.GetCollisionStrategy() != config.CollisionStrategyPrompt {
+		return "", err
+	}
+	return promptCollision(ctx, r, errW, collision)
+}
+
+// promptCollision asks whether to use the numbered alternative for a taken worktree path
+// With --yes, the alternative is used without asking.
+func promptCollision(ctx context.Context, r io.Reader, w io.Writer, collision *naming.CollisionError) (string, error) {
+	if collision.Alternative == "" {
+		return "", collision
+	}
+	if flagYes {
+		return collision.Alternative, nil
+	}
+	confirmed, err := confirmWith(ctx, r, w, fmt.Sprintf("Worktree path %s is taken. Use %s instead?", collision.Path, collision.Alternative), "--yes")
+	if err != nil {
+		return "", err
+	}
+	if !confirmed {
+		return "", collision
+	}
+	return collision.Alternative, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+func TestGenerateWorktreePathDetectsRegisteredWorktree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repoPath := setupTestRepo(t)
+	ctx := context.Background()
+
+	repo, err := gitx.GetRepo(ctx, "")
+	if err != nil {
+		t.Fatalf("GetRepo() returned error: %v", err)
+	}
+
+	// Create a worktree, then delete its directory without telling git
+	wtPath := filepath.Join(repo.Parent, ".test-repo-wt", "feature-login")
+	runGitForTest(t, repoPath, "worktree", "add", "-b", "feature/login", wtPath)
+	if err := os.RemoveAll(wtPath); err != nil {
+		t.Fatalf("Failed to remove worktree directory: %v", err)
+	}
+
+	// suffix (default): the registered path is not reused
+	path, err := generateWorktreePath(ctx, strings.NewReader(""), &bytes.Buffer{}, repo.Parent, repo.Name, "feature/login", "")
+	if err != nil {
+		t.Fatalf("generateWorktreePath() returned error: %v", err)
+	}
+	if want := wtPath + "-2"; path != want {
+		t.Errorf("generateWorktreePath() = %q, want %q", path, want)
+	}
+
+	// error: the stale registration is reported
+	t.Setenv("WT_COLLISION_STRATEGY", "error")
+	_, err = generateWorktreePath(ctx, strings.NewReader(""), &bytes.Buffer{}, repo.Parent, repo.Name, "feature/login", "")
+	var collision *naming.CollisionError
+	if !errors.As(err, &collision) || !collision.Registered || collision.Path != wtPath {
+		t.Errorf("generateWorktreePath() error = %v, want registered collision at %q", err, wtPath)
+	}
+}
+
+func TestPromptCollision(t *testing.T) {
+	collision := &naming.CollisionError{Path: "/work/.repo-wt/feature", Alternative: "/work/.repo-wt/feature-2"}
+
+	var out bytes.Buffer
+	path, err := promptCollision(context.Background(), strings.NewReader("y\n"), &out, collision)
+	if err != nil {
+		t.Fatalf("promptCollision() returned error: %v", err)
+	}
+	if path != collision.Alternative {
+		t.Errorf("promptCollision() = %q, want %q", path, collision.Alternative)
+	}
+	if !strings.Contains(out.String(), collision.Path) || !strings.Contains(out.String(), collision.Alternative) {
+		t.Errorf("prompt should show both paths, got: %q", out.String())
+	}
+
+	if _, err := promptCollision(context.Background(), strings.NewReader("n\n"), &out, collision); !errors.Is(err, collision) {
+		t.Errorf("promptCollision() declined error = %v, want the collision", err)
+	}
+
+	// Nothing to offer without an alternative
+	out.Reset()
+	if _, err := promptCollision(context.Background(), strings.NewReader("y\n"), &out, &naming.CollisionError{Path: "/x"}); err == nil {
+		t.Error("promptCollision() without alternative expected error, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("promptCollision() without alternative should not prompt, got: %q", out.String())
+	}
+}
+
+func TestPrepareWorktreeParent(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Missing directories are created with worktree.container_dir_mode
+	t.Setenv("WT_CONTAINER_DIR_MODE", "0750")
+	path := filepath.Join(dir, ".repo-wt", "feature", "login")
+	if err := prepareWorktreeParent(ctx, path); err != nil {
+		t.Fatalf("prepareWorktreeParent() returned error: %v", err)
+	}
+	for _, created := range []string{filepath.Join(dir, ".repo-wt"), filepath.Join(dir, ".repo-wt", "feature")} {
+		info, err := os.Stat(created)
+		if err != nil {
+			t.Fatalf("%s was not created: %v", created, err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o750 {
+			t.Errorf("%s has mode %o, want 750", created, perm)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the worktree directory itself was created: %v", err)
+	}
+
+	// A symlinked container is followed
+	target := t.TempDir()
+	linked := filepath.Join(dir, ".linked-wt")
+	if err := os.Symlink(target, linked); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := prepareWorktreeParent(ctx, filepath.Join(linked, "feature")); err != nil {
+		t.Errorf("prepareWorktreeParent() through a symlink returned error: %v", err)
+	}
+
+	// A file or a broken symlink in place of the container is reported
+	file := filepath.Join(dir, ".file-wt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	broken := filepath.Join(dir, ".broken-wt")
+	if err := os.Symlink(filepath.Join(dir, "missing"), broken); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	for _, container := range []string{file, broken} {
+		err := prepareWorktreeParent(ctx, filepath.Join(container, "feature"))
+		var dirErr *WorktreeDirError
+		if !errors.As(err, &dirErr) || dirErr.Path != container {
+			t.Errorf("prepareWorktreeParent() error = %v, want WorktreeDirError for %s", err, container)
+		}
+	}
+}
+
+func TestPrepareWorktreeParentNotWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to any directory")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	container := filepath.Join(t.TempDir(), ".repo-wt")
+	if err := os.Mkdir(container, 0555); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(container, 0755) })
+
+	err := prepareWorktreeParent(context.Background(), filepath.Join(container, "feature"))
+	var dirErr *WorktreeDirError
+	if !errors.As(err, &dirErr) || dirErr.Path != container {
+		t.Errorf("prepareWorktreeParent() error = %v, want WorktreeDirError for %s", err, container)
+	}
+}
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,25 +21,291 @@ const (
 	// DirectoryFormatSibling uses <repo>-<branch> format (legacy)
 	DirectoryFormatSibling = "sibling"
 
+	// CollisionStrategySuffix adds a numbered suffix (-2, -3, ...) when the worktree path is taken
+	CollisionStrategySuffix = "suffix"
+	// CollisionStrategyError fails when the worktree path is taken
+	CollisionStrategyError = "error"
+	// CollisionStrategyPrompt asks whether to use a numbered suffix when the worktree path is taken
+	CollisionStrategyPrompt = "prompt"
+
+	// PromptAnswerYes makes pressing Enter at a confirmation prompt answer yes
+	PromptAnswerYes = "yes"
+	// PromptAnswerNo makes pressing Enter at a confirmation prompt answer no
+	PromptAnswerNo = "no"
+
 	// DefaultDirectoryFormat is the default directory format
 	DefaultDirectoryFormat = DirectoryFormatSubdirectory
 	// DefaultSubdirectoryPrefix is the default prefix for subdirectory mode
 	DefaultSubdirectoryPrefix = "."
 	// DefaultSubdirectorySuffix is the default suffix for subdirectory mode
 	DefaultSubdirectorySuffix = "-wt"
+	// DefaultInitSubmodules is the default for initializing submodules in new worktrees
+	DefaultInitSubmodules = false
+	// DefaultLFSPull is the default for running git lfs pull in new worktrees of LFS repositories
+	DefaultLFSPull = true
+	// DefaultRemoteCheck is the default for checking remote branches of the same name in wt new
+	DefaultRemoteCheck = true
+	// DefaultAutoSetUpstream is the default for pushing new branches with an upstream in wt new
+	DefaultAutoSetUpstream = false
+	// DefaultRemote is the default remote new branches are pushed to
+	DefaultRemote = "origin"
+	// DefaultContainerDirMode is the default permission mode of created worktree directories ("" follows the umask)
+	DefaultContainerDirMode = ""
+	// DefaultSelectorBinary is the default fuzzy finder for interactive selection
+	DefaultSelectorBinary = "fzf"
+	// DefaultSelectorExtraArgs are the default additional fuzzy finder arguments (space-separated)
+	DefaultSelectorExtraArgs = "--height=40% --reverse"
+	// DefaultEditorCommand is the default editor command (empty: use environment variables and fallbacks)
+	DefaultEditorCommand = ""
+	// DefaultEditorArgs are the default additional editor arguments (space-separated)
+	DefaultEditorArgs = ""
+	// DefaultEditorGUIEditors are the default editors started without waiting for them (space-separated)
+	DefaultEditorGUIEditors = "code idea idea64 subl open xdg-open"
+	// DefaultPruneRemoteRefs is the default for deleting stale remote-tracking refs in wt clean
+	DefaultPruneRemoteRefs = false
+	// DefaultUseTrash is the default for moving worktrees removed by wt clean to the trash
+	DefaultUseTrash = false
+	// DefaultProtectedBranches are the default branch patterns wt clean never deletes (space-separated)
+	DefaultProtectedBranches = "main master develop " + DefaultBranchPlaceholder
+	// DefaultBranchPlaceholder stands for the repository's default branch in clean.protected_branches
+	DefaultBranchPlaceholder = "{default}"
+	// DefaultUpdateCheck is the default for checking for new wt releases once a day
+	DefaultUpdateCheck = false
+	// DefaultNestedBranchDirs is the default for keeping branch slashes as nested directories in subdirectory mode
+	DefaultNestedBranchDirs = false
+	// DefaultSanitizeASCIIOnly is the default for replacing non-ASCII characters in worktree directory names
+	// It is true so that branches keep the directory names of earlier versions.
+	DefaultSanitizeASCIIOnly = true
+	// DefaultCollisionStrategy is the default behavior when the worktree path is taken
+	DefaultCollisionStrategy = CollisionStrategySuffix
+	// DefaultLowercaseDirs is the default for lowercasing branch names in worktree directory names
+	DefaultLowercaseDirs = false
+	// DefaultPromptAnswer is the default answer to confirmation prompts when Enter is pressed
+	DefaultPromptAnswer = PromptAnswerNo
+	// DefaultPromptFormat is the default format of wt current for shell prompts
+	DefaultPromptFormat = "{repo}:{branch}"
+	// DefaultPathTemplate is the default worktree path template (empty: use directory_format)
+	DefaultPathTemplate = ""
+	// DefaultBaseDir is the default base directory for new worktrees (empty: the repository's parent directory)
+	DefaultBaseDir = ""
+	// DefaultSlowFSMode is the default for the mode for repositories on slow network filesystems
+	DefaultSlowFSMode = false
+	// DefaultGitTimeout is the default time limit for local git commands in slow filesystem mode
+	DefaultGitTimeout = "10s"
+
+	// RepoConfigFileName is the name of the repository configuration file in the git directory
+	// It is shared by all worktrees of the repository and overrides the user configuration file.
+	RepoConfigFileName = "wt.yaml"
 )
 
+// PathTemplatePlaceholders lists the placeholders supported in worktree.path_template
+var PathTemplatePlaceholders = []string{"{base}", "{repo}", "{branch}", "{branch_sanitized}", "{date}"}
+
+// PromptFormatPlaceholders lists the placeholders supported in prompt.format
+var PromptFormatPlaceholders = []string{"{repo}", "{branch}", "{path}"}
+
+// pathTemplatePlaceholderRegex matches a placeholder in a path template
+var pathTemplatePlaceholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// ValidationError represents an invalid configuration value
+type ValidationError struct {
+	Key string // Dotted key path (e.g. "worktree.directory_format")
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
 // Config represents the application configuration
 type Config struct {
-	Worktree WorktreeConfig `yaml:"worktree"`
-	path     string         // Path to config file (not serialized)
+	Worktree    WorktreeConfig    `yaml:"worktree"`
+	Selector    SelectorConfig    `yaml:"selector"`
+	Editor      EditorConfig      `yaml:"editor"`
+	Clean       CleanConfig       `yaml:"clean"`
+	UpdateCheck UpdateCheckConfig `yaml:"update_check"`
+	Prompts     PromptsConfig     `yaml:"prompts"`
+	Prompt      PromptConfig      `yaml:"prompt"`
+	Performance PerformanceConfig `yaml:"performance"`
+	path        string            // Path to config file (not serialized)
+	doc         *yaml.Node        // Parsed file contents, preserved across Save (nil if no file)
+	repoPath    string            // Path to the repository configuration file, if one was applied
+	partial     bool              // Save writes only the keys set in the file (see LoadRepoFile)
+	sources     map[string]Source // Config key -> where its value came from (default if absent)
+	envSources  map[string]string // Config key -> environment variable that overrode it
+}
+
+// Source describes where a configuration value came from
+type Source string
+
+const (
+	// SourceDefault means the built-in default is in effect
+	SourceDefault Source = "default"
+	// SourceFile means the value was read from the configuration file
+	SourceFile Source = "file"
+	// SourceRepo means the value was read from the repository configuration file
+	SourceRepo Source = "repo"
+	// SourceEnv means the value was overridden by an environment variable
+	SourceEnv Source = "env"
+	// SourceFlag means the value was overridden by a command-line flag
+	SourceFlag Source = "flag"
+)
+
+// setting describes a configuration key persisted by Save
+type setting struct {
+	key string
+	get func(*Config) string
+	set func(*Config, string) error
+	def string
+	tag string // YAML tag used when writing the value ("!!str" if empty)
+}
+
+// settings lists all known configuration keys
+var settings = []setting{
+	{key: "worktree.directory_format", get: (*Config).GetDirectoryFormat, set: (*Config).SetDirectoryFormat, def: DefaultDirectoryFormat},
+	{key: "worktree.subdirectory_prefix", get: (*Config).GetSubdirectoryPrefix, set: (*Config).SetSubdirectoryPrefix, def: DefaultSubdirectoryPrefix},
+	{key: "worktree.subdirectory_suffix", get: (*Config).GetSubdirectorySuffix, set: (*Config).SetSubdirectorySuffix, def: DefaultSubdirectorySuffix},
+	{key: "worktree.init_submodules", get: (*Config).getInitSubmodules, set: (*Config).SetInitSubmodules, def: strconv.FormatBool(DefaultInitSubmodules), tag: "!!bool"},
+	{key: "worktree.lfs_pull", get: (*Config).getLFSPull, set: (*Config).SetLFSPull, def: strconv.FormatBool(DefaultLFSPull), tag: "!!bool"},
+	{key: "worktree.nested_branch_dirs", get: (*Config).getNestedBranchDirs, set: (*Config).SetNestedBranchDirs, def: strconv.FormatBool(DefaultNestedBranchDirs), tag: "!!bool"},
+	{key: "worktree.sanitize_ascii_only", get: (*Config).getSanitizeASCIIOnly, set: (*Config).SetSanitizeASCIIOnly, def: strconv.FormatBool(DefaultSanitizeASCIIOnly), tag: "!!bool"},
+	{key: "worktree.lowercase_dirs", get: (*Config).getLowercaseDirs, set: (*Config).SetLowercaseDirs, def: strconv.FormatBool(DefaultLowercaseDirs), tag: "!!bool"},
+	{key: "worktree.path_template", get: (*Config).GetPathTemplate, set: (*Config).SetPathTemplate, def: DefaultPathTemplate},
+	{key: "worktree.collision_strategy", get: (*Config).GetCollisionStrategy, set: (*Config).SetCollisionStrategy, def: DefaultCollisionStrategy},
+	{key: "worktree.base_dir", get: (*Config).getBaseDir, set: (*Config).SetBaseDir, def: DefaultBaseDir},
+	{key: "worktree.remote_check", get: (*Config).getRemoteCheck, set: (*Config).SetRemoteCheck, def: strconv.FormatBool(DefaultRemoteCheck), tag: "!!bool"},
+	{key: "worktree.auto_set_upstream", get: (*Config).getAutoSetUpstream, set: (*Config).SetAutoSetUpstream, def: strconv.FormatBool(DefaultAutoSetUpstream), tag: "!!bool"},
+	{key: "worktree.default_remote", get: (*Config).GetDefaultRemote, set: (*Config).SetDefaultRemote, def: DefaultRemote},
+	{key: "worktree.container_dir_mode", get: (*Config).GetContainerDirMode, set: (*Config).SetContainerDirMode, def: DefaultContainerDirMode},
+	{key: "selector.binary", get: (*Config).GetSelectorBinary, set: (*Config).SetSelectorBinary, def: DefaultSelectorBinary},
+	{key: "selector.extra_args", get: (*Config).getSelectorExtraArgs, set: (*Config).SetSelectorExtraArgs, def: DefaultSelectorExtraArgs, tag: "!!seq"},
+	{key: "editor.command", get: (*Config).GetEditorCommand, set: (*Config).SetEditorCommand, def: DefaultEditorCommand},
+	{key: "editor.args", get: (*Config).getEditorArgs, set: (*Config).SetEditorArgs, def: DefaultEditorArgs, tag: "!!seq"},
+	{key: "editor.gui_editors", get: (*Config).getEditorGUIEditors, set: (*Config).SetEditorGUIEditors, def: DefaultEditorGUIEditors, tag: "!!seq"},
+	{key: "clean.prune_remote_refs", get: (*Config).getPruneRemoteRefs, set: (*Config).SetPruneRemoteRefs, def: strconv.FormatBool(DefaultPruneRemoteRefs), tag: "!!bool"},
+	{key: "clean.use_trash", get: (*Config).getUseTrash, set: (*Config).SetUseTrash, def: strconv.FormatBool(DefaultUseTrash), tag: "!!bool"},
+	{key: "clean.protected_branches", get: (*Config).getProtectedBranches, set: (*Config).SetProtectedBranches, def: DefaultProtectedBranches, tag: "!!seq"},
+	{key: "update_check.enabled", get: (*Config).getUpdateCheck, set: (*Config).SetUpdateCheck, def: strconv.FormatBool(DefaultUpdateCheck), tag: "!!bool"},
+	{key: "prompts.default_answer", get: (*Config).GetPromptDefaultAnswer, set: (*Config).SetPromptDefaultAnswer, def: DefaultPromptAnswer},
+	{key: "prompt.format", get: (*Config).GetPromptFormat, set: (*Config).SetPromptFormat, def: DefaultPromptFormat},
+	{key: "performance.slow_fs_mode", get: (*Config).getSlowFSMode, set: (*Config).SetSlowFSMode, def: strconv.FormatBool(DefaultSlowFSMode), tag: "!!bool"},
+	{key: "performance.git_timeout", get: (*Config).getGitTimeout, set: (*Config).SetGitTimeout, def: DefaultGitTimeout},
+}
+
+// mapSections lists sections whose keys are chosen by the user (e.g. repository names)
+var mapSections = []string{"editor.repos"}
+
+// KnownKeys returns all known configuration keys
+func KnownKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// UnknownKeyError represents an error when a configuration key is not recognized
+type UnknownKeyError struct {
+	Key string
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown config key: %s\nKnown keys: %s", e.Key, strings.Join(KnownKeys(), ", "))
+}
+
+// EnvOverride maps an environment variable to a configuration key
+type EnvOverride struct {
+	Env string                      // Environment variable name
+	Key string                      // Dotted config key
+	Set func(*Config, string) error // Setter that validates the value
+}
+
+// EnvOverrides lists the environment variables that override configuration keys
+var EnvOverrides = []EnvOverride{
+	{Env: "WT_DIRECTORY_FORMAT", Key: "worktree.directory_format", Set: (*Config).SetDirectoryFormat},
+	{Env: "WT_SUBDIRECTORY_PREFIX", Key: "worktree.subdirectory_prefix", Set: (*Config).SetSubdirectoryPrefix},
+	{Env: "WT_SUBDIRECTORY_SUFFIX", Key: "worktree.subdirectory_suffix", Set: (*Config).SetSubdirectorySuffix},
+	{Env: "WT_INIT_SUBMODULES", Key: "worktree.init_submodules", Set: (*Config).SetInitSubmodules},
+	{Env: "WT_LFS_PULL", Key: "worktree.lfs_pull", Set: (*Config).SetLFSPull},
+	{Env: "WT_NESTED_BRANCH_DIRS", Key: "worktree.nested_branch_dirs", Set: (*Config).SetNestedBranchDirs},
+	{Env: "WT_SANITIZE_ASCII_ONLY", Key: "worktree.sanitize_ascii_only", Set: (*Config).SetSanitizeASCIIOnly},
+	{Env: "WT_LOWERCASE_DIRS", Key: "worktree.lowercase_dirs", Set: (*Config).SetLowercaseDirs},
+	{Env: "WT_PATH_TEMPLATE", Key: "worktree.path_template", Set: (*Config).SetPathTemplate},
+	{Env: "WT_COLLISION_STRATEGY", Key: "worktree.collision_strategy", Set: (*Config).SetCollisionStrategy},
+	{Env: "WT_BASE_DIR", Key: "worktree.base_dir", Set: (*Config).SetBaseDir},
+	{Env: "WT_REMOTE_CHECK", Key: "worktree.remote_check", Set: (*Config).SetRemoteCheck},
+	{Env: "WT_AUTO_SET_UPSTREAM", Key: "worktree.auto_set_upstream", Set: (*Config).SetAutoSetUpstream},
+	{Env: "WT_DEFAULT_REMOTE", Key: "worktree.default_remote", Set: (*Config).SetDefaultRemote},
+	{Env: "WT_CONTAINER_DIR_MODE", Key: "worktree.container_dir_mode", Set: (*Config).SetContainerDirMode},
+	{Env: "WT_SELECTOR_BINARY", Key: "selector.binary", Set: (*Config).SetSelectorBinary},
+	{Env: "WT_SELECTOR_EXTRA_ARGS", Key: "selector.extra_args", Set: (*Config).SetSelectorExtraArgs},
+	{Env: "WT_PRUNE_REMOTE_REFS", Key: "clean.prune_remote_refs", Set: (*Config).SetPruneRemoteRefs},
+	{Env: "WT_USE_TRASH", Key: "clean.use_trash", Set: (*Config).SetUseTrash},
+	{Env: "WT_PROTECTED_BRANCHES", Key: "clean.protected_branches", Set: (*Config).SetProtectedBranches},
+	{Env: "WT_UPDATE_CHECK", Key: "update_check.enabled", Set: (*Config).SetUpdateCheck},
+	{Env: "WT_PROMPT_DEFAULT_ANSWER", Key: "prompts.default_answer", Set: (*Config).SetPromptDefaultAnswer},
+	{Env: "WT_PROMPT_FORMAT", Key: "prompt.format", Set: (*Config).SetPromptFormat},
+	{Env: "WT_SLOW_FS_MODE", Key: "performance.slow_fs_mode", Set: (*Config).SetSlowFSMode},
+	{Env: "WT_GIT_TIMEOUT", Key: "performance.git_timeout", Set: (*Config).SetGitTimeout},
 }
 
 // WorktreeConfig represents worktree-specific configuration
 type WorktreeConfig struct {
-	DirectoryFormat     string `yaml:"directory_format"`
-	SubdirectoryPrefix  string `yaml:"subdirectory_prefix"`
-	SubdirectorySuffix  string `yaml:"subdirectory_suffix"`
+	DirectoryFormat    string `yaml:"directory_format"`
+	SubdirectoryPrefix string `yaml:"subdirectory_prefix"`
+	SubdirectorySuffix string `yaml:"subdirectory_suffix"`
+	InitSubmodules     bool   `yaml:"init_submodules"`
+	LFSPull            bool   `yaml:"lfs_pull"`
+	NestedBranchDirs   bool   `yaml:"nested_branch_dirs"`
+	SanitizeASCIIOnly  bool   `yaml:"sanitize_ascii_only"`
+	LowercaseDirs      bool   `yaml:"lowercase_dirs"`
+	PathTemplate       string `yaml:"path_template"`
+	CollisionStrategy  string `yaml:"collision_strategy"`
+	BaseDir            string `yaml:"base_dir"`
+	RemoteCheck        bool   `yaml:"remote_check"`
+	AutoSetUpstream    bool   `yaml:"auto_set_upstream"`
+	DefaultRemote      string `yaml:"default_remote"`
+	ContainerDirMode   string `yaml:"container_dir_mode"`
+}
+
+// SelectorConfig represents configuration for the interactive fuzzy finder
+type SelectorConfig struct {
+	Binary    string   `yaml:"binary"`
+	ExtraArgs []string `yaml:"extra_args"`
+}
+
+// EditorConfig represents configuration for wt open and wt config edit
+type EditorConfig struct {
+	Command    string            `yaml:"command"`
+	Args       []string          `yaml:"args"`
+	GUIEditors []string          `yaml:"gui_editors"`
+	Repos      map[string]string `yaml:"repos"` // Repository name -> command line replacing command and args
+}
+
+// CleanConfig represents configuration for wt clean
+type CleanConfig struct {
+	PruneRemoteRefs   bool     `yaml:"prune_remote_refs"`
+	UseTrash          bool     `yaml:"use_trash"`
+	ProtectedBranches []string `yaml:"protected_branches"`
+}
+
+// UpdateCheckConfig represents configuration for the passive new-version check
+type UpdateCheckConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// PromptsConfig represents configuration for confirmation prompts
+type PromptsConfig struct {
+	DefaultAnswer string `yaml:"default_answer"`
+}
+
+// PromptConfig represents configuration for shell prompt integration (wt current)
+type PromptConfig struct {
+	Format string `yaml:"format"`
+}
+
+// PerformanceConfig represents configuration for repositories on slow (e.g. network) filesystems
+type PerformanceConfig struct {
+	SlowFSMode bool   `yaml:"slow_fs_mode"`
+	GitTimeout string `yaml:"git_timeout"`
 }
 
 // Load loads configuration from the specified path
@@ -44,6 +317,45 @@ func Load(path string) (*Config, error) {
 			DirectoryFormat:    DefaultDirectoryFormat,
 			SubdirectoryPrefix: DefaultSubdirectoryPrefix,
 			SubdirectorySuffix: DefaultSubdirectorySuffix,
+			InitSubmodules:     DefaultInitSubmodules,
+			LFSPull:            DefaultLFSPull,
+			NestedBranchDirs:   DefaultNestedBranchDirs,
+			SanitizeASCIIOnly:  DefaultSanitizeASCIIOnly,
+			LowercaseDirs:      DefaultLowercaseDirs,
+			PathTemplate:       DefaultPathTemplate,
+			CollisionStrategy:  DefaultCollisionStrategy,
+			RemoteCheck:        DefaultRemoteCheck,
+			AutoSetUpstream:    DefaultAutoSetUpstream,
+			DefaultRemote:      DefaultRemote,
+			ContainerDirMode:   DefaultContainerDirMode,
+			BaseDir:            DefaultBaseDir,
+		},
+		Selector: SelectorConfig{
+			Binary:    DefaultSelectorBinary,
+			ExtraArgs: strings.Fields(DefaultSelectorExtraArgs),
+		},
+		Editor: EditorConfig{
+			Command:    DefaultEditorCommand,
+			Args:       strings.Fields(DefaultEditorArgs),
+			GUIEditors: strings.Fields(DefaultEditorGUIEditors),
+		},
+		Clean: CleanConfig{
+			PruneRemoteRefs:   DefaultPruneRemoteRefs,
+			UseTrash:          DefaultUseTrash,
+			ProtectedBranches: strings.Fields(DefaultProtectedBranches),
+		},
+		UpdateCheck: UpdateCheckConfig{
+			Enabled: DefaultUpdateCheck,
+		},
+		Prompts: PromptsConfig{
+			DefaultAnswer: DefaultPromptAnswer,
+		},
+		Prompt: PromptConfig{
+			Format: DefaultPromptFormat,
+		},
+		Performance: PerformanceConfig{
+			SlowFSMode: DefaultSlowFSMode,
+			GitTimeout: DefaultGitTimeout,
 		},
 	}
 
@@ -63,6 +375,24 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Keep the document tree so Save can preserve comments and unknown keys
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err == nil && doc.Kind == yaml.DocumentNode {
+		cfg.doc = &doc
+	}
+
+	// Detect misspelled or unsupported keys
+	if err := checkUnknownKeys(path, cfg.doc); err != nil {
+		return nil, err
+	}
+
+	// Record which keys were set by the file
+	for _, key := range KnownKeys() {
+		if keyNode, _ := lookupNode(cfg.doc, key); keyNode != nil {
+			cfg.setSource(key, SourceFile)
+		}
+	}
+
 	// Validate
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -71,6 +401,124 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// ApplyRepoFile overrides configuration values with the repository configuration file at path
+// The file has the same format as the user configuration file; a missing file changes nothing.
+// The result mixes both files, so it must not be saved.
+func (c *Config) ApplyRepoFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read repository config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse repository config file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode {
+		return nil // Empty file
+	}
+	if err := checkUnknownKeys(path, &doc); err != nil {
+		return err
+	}
+
+	// Decoding onto the loaded configuration replaces only the keys present in the file
+	if err := doc.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse repository config file: %w", err)
+	}
+	c.repoPath = path
+	for _, key := range KnownKeys() {
+		if keyNode, _ := lookupNode(&doc, key); keyNode != nil {
+			c.setSource(key, SourceRepo)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadRepoFile loads the repository configuration file at path for changing it and saving it again
+// Unlike a file loaded with Load, Save then writes only the keys set in the file (see MarkSet), so
+// that the others keep coming from the configuration file.
+func LoadRepoFile(path string) (*Config, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.partial = true
+	return cfg, nil
+}
+
+// MarkSet records that key was set in the file, so that Save writes it even if it has the default value
+func (c *Config) MarkSet(key string) {
+	c.setSource(key, SourceFile)
+}
+
+// RepoPath returns the path of the repository configuration file applied by ApplyRepoFile ("" if none)
+func (c *Config) RepoPath() string {
+	return c.repoPath
+}
+
+// ApplyEnvOverrides overrides configuration values with environment variables listed in EnvOverrides
+// A variable that is set (even to an empty string) takes precedence over the file value.
+func ApplyEnvOverrides(cfg *Config) error {
+	for _, o := range EnvOverrides {
+		value, ok := os.LookupEnv(o.Env)
+		if !ok {
+			continue
+		}
+		if err := o.Set(cfg, value); err != nil {
+			return fmt.Errorf("invalid value in %s: %w", o.Env, err)
+		}
+		if cfg.envSources == nil {
+			cfg.envSources = make(map[string]string)
+		}
+		cfg.envSources[o.Key] = o.Env
+		cfg.setSource(o.Key, SourceEnv)
+	}
+	return nil
+}
+
+// SetFromFlag overrides a configuration value from a command-line flag
+func (c *Config) SetFromFlag(key, value string) error {
+	for _, s := range settings {
+		if s.key != key {
+			continue
+		}
+		if err := s.set(c, value); err != nil {
+			return err
+		}
+		c.setSource(key, SourceFlag)
+		return nil
+	}
+	return &UnknownKeyError{Key: key}
+}
+
+// Source returns where the value of key came from
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// setSource records where the value of key came from
+func (c *Config) setSource(key string, source Source) {
+	if c.sources == nil {
+		c.sources = make(map[string]Source)
+	}
+	c.sources[key] = source
+}
+
+// EnvSource returns the environment variable that overrode key, or "" if none did
+func (c *Config) EnvSource(key string) string {
+	return c.envSources[key]
+}
+
 // GetDirectoryFormat returns the directory format setting
 func (c *Config) GetDirectoryFormat() string {
 	return c.Worktree.DirectoryFormat
@@ -86,18 +534,257 @@ func (c *Config) GetSubdirectorySuffix() string {
 	return c.Worktree.SubdirectorySuffix
 }
 
+// GetInitSubmodules returns whether submodules are initialized in new worktrees
+func (c *Config) GetInitSubmodules() bool {
+	return c.Worktree.InitSubmodules
+}
+
+// GetLFSPull returns whether git lfs pull runs in new worktrees of LFS repositories
+func (c *Config) GetLFSPull() bool {
+	return c.Worktree.LFSPull
+}
+
+// GetRemoteCheck returns whether wt new checks for a remote branch of the same name with different history
+func (c *Config) GetRemoteCheck() bool {
+	return c.Worktree.RemoteCheck
+}
+
+// GetAutoSetUpstream returns whether wt new pushes a new branch and sets its upstream
+func (c *Config) GetAutoSetUpstream() bool {
+	return c.Worktree.AutoSetUpstream
+}
+
+// GetDefaultRemote returns the remote new branches are pushed to
+func (c *Config) GetDefaultRemote() string {
+	if c.Worktree.DefaultRemote == "" {
+		return DefaultRemote
+	}
+	return c.Worktree.DefaultRemote
+}
+
+// GetContainerDirMode returns the permission mode of created worktree directories as written, e.g. "0750"
+func (c *Config) GetContainerDirMode() string {
+	return c.Worktree.ContainerDirMode
+}
+
+// ContainerDirPerm returns the permission mode of created worktree directories (0 to follow the umask)
+func (c *Config) ContainerDirPerm() os.FileMode {
+	perm, _ := ParseDirMode(c.Worktree.ContainerDirMode) // Validated when loaded
+	return perm
+}
+
+// GetNestedBranchDirs returns whether branch slashes become nested directories in subdirectory mode
+func (c *Config) GetNestedBranchDirs() bool {
+	return c.Worktree.NestedBranchDirs
+}
+
+// GetSanitizeASCIIOnly returns whether non-ASCII characters are replaced in worktree directory names
+func (c *Config) GetSanitizeASCIIOnly() bool {
+	return c.Worktree.SanitizeASCIIOnly
+}
+
+// GetLowercaseDirs returns whether branch names are lowercased in worktree directory names
+func (c *Config) GetLowercaseDirs() bool {
+	return c.Worktree.LowercaseDirs
+}
+
+// GetPathTemplate returns the worktree path template ("" if directory_format is used)
+func (c *Config) GetPathTemplate() string {
+	return c.Worktree.PathTemplate
+}
+
+// GetCollisionStrategy returns what happens when the path for a new worktree is taken
+func (c *Config) GetCollisionStrategy() string {
+	if c.Worktree.CollisionStrategy == "" {
+		return DefaultCollisionStrategy
+	}
+	return c.Worktree.CollisionStrategy
+}
+
+// GetBaseDir returns the base directory for new worktrees with "~" and environment variables expanded
+// An empty result means the parent directory of the repository.
+func (c *Config) GetBaseDir() string {
+	return ExpandPath(c.Worktree.BaseDir)
+}
+
+func (c *Config) getBaseDir() string { return c.Worktree.BaseDir }
+
+// GetSelectorBinary returns the fuzzy finder command used for interactive selection
+func (c *Config) GetSelectorBinary() string {
+	if c.Selector.Binary == "" {
+		return DefaultSelectorBinary
+	}
+	return c.Selector.Binary
+}
+
+// GetSelectorExtraArgs returns the additional fuzzy finder arguments
+func (c *Config) GetSelectorExtraArgs() []string {
+	return c.Selector.ExtraArgs
+}
+
+func (c *Config) getSelectorExtraArgs() string { return strings.Join(c.Selector.ExtraArgs, " ") }
+
+// GetEditorCommand returns the configured editor command line ("" to use environment variables)
+func (c *Config) GetEditorCommand() string {
+	return c.Editor.Command
+}
+
+// GetEditorArgs returns the additional arguments for the configured editor command
+func (c *Config) GetEditorArgs() []string {
+	return c.Editor.Args
+}
+
+func (c *Config) getEditorArgs() string { return strings.Join(c.Editor.Args, " ") }
+
+// GetEditorGUIEditors returns the editors that wt open starts without waiting for them to exit
+func (c *Config) GetEditorGUIEditors() []string {
+	return c.Editor.GUIEditors
+}
+
+func (c *Config) getEditorGUIEditors() string { return strings.Join(c.Editor.GUIEditors, " ") }
+
+// GetRepoEditor returns the editor command line configured for the repository repoName, or ""
+// A repository editor replaces editor.command and editor.args.
+func (c *Config) GetRepoEditor(repoName string) string {
+	return c.Editor.Repos[repoName]
+}
+
+// GetPruneRemoteRefs returns whether wt clean deletes remote-tracking refs of branches gone from the remote
+func (c *Config) GetPruneRemoteRefs() bool {
+	return c.Clean.PruneRemoteRefs
+}
+
+// GetUseTrash returns whether wt clean moves removed worktrees to the trash instead of deleting them
+func (c *Config) GetUseTrash() bool {
+	return c.Clean.UseTrash
+}
+
+// GetProtectedBranches returns the glob patterns of branches wt clean never deletes
+// The pattern "{default}" stands for the repository's default branch.
+func (c *Config) GetProtectedBranches() []string {
+	return c.Clean.ProtectedBranches
+}
+
+func (c *Config) getProtectedBranches() string { return strings.Join(c.Clean.ProtectedBranches, " ") }
+
+// GetUpdateCheck returns whether wt checks for a new release at most once a day
+func (c *Config) GetUpdateCheck() bool {
+	return c.UpdateCheck.Enabled
+}
+
+func (c *Config) getUpdateCheck() string { return strconv.FormatBool(c.UpdateCheck.Enabled) }
+
+// GetSlowFSMode returns whether wt limits local git commands, skips the dirty markers of
+// selection lists and caches the worktree list (for repositories on network filesystems)
+func (c *Config) GetSlowFSMode() bool {
+	return c.Performance.SlowFSMode
+}
+
+func (c *Config) getSlowFSMode() string { return strconv.FormatBool(c.Performance.SlowFSMode) }
+
+// GetGitTimeout returns the time limit for local git commands in slow filesystem mode (0 for none)
+func (c *Config) GetGitTimeout() time.Duration {
+	d, err := parseGitTimeout(c.Performance.GitTimeout)
+	if err != nil {
+		d, _ = parseGitTimeout(DefaultGitTimeout)
+	}
+	return d
+}
+
+func (c *Config) getGitTimeout() string {
+	if c.Performance.GitTimeout == "" {
+		return DefaultGitTimeout
+	}
+	return c.Performance.GitTimeout
+}
+
+// GetPromptDefaultAnswer returns the answer to confirmation prompts when Enter is pressed ("yes" or "no")
+func (c *Config) GetPromptDefaultAnswer() string {
+	if c.Prompts.DefaultAnswer == "" {
+		return DefaultPromptAnswer
+	}
+	return c.Prompts.DefaultAnswer
+}
+
+func (c *Config) getInitSubmodules() string  { return strconv.FormatBool(c.Worktree.InitSubmodules) }
+func (c *Config) getLFSPull() string         { return strconv.FormatBool(c.Worktree.LFSPull) }
+func (c *Config) getRemoteCheck() string     { return strconv.FormatBool(c.Worktree.RemoteCheck) }
+func (c *Config) getAutoSetUpstream() string { return strconv.FormatBool(c.Worktree.AutoSetUpstream) }
+func (c *Config) getPruneRemoteRefs() string { return strconv.FormatBool(c.Clean.PruneRemoteRefs) }
+func (c *Config) getUseTrash() string        { return strconv.FormatBool(c.Clean.UseTrash) }
+
+func (c *Config) getNestedBranchDirs() string {
+	return strconv.FormatBool(c.Worktree.NestedBranchDirs)
+}
+
+func (c *Config) getLowercaseDirs() string {
+	return strconv.FormatBool(c.Worktree.LowercaseDirs)
+}
+
+func (c *Config) getSanitizeASCIIOnly() string {
+	return strconv.FormatBool(c.Worktree.SanitizeASCIIOnly)
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	format := c.Worktree.DirectoryFormat
 	if format != DirectoryFormatSubdirectory && format != DirectoryFormatSibling {
-		return fmt.Errorf("invalid directory_format: %q (must be %q or %q)",
-			format, DirectoryFormatSubdirectory, DirectoryFormatSibling)
+		return &ValidationError{
+			Key: "worktree.directory_format",
+			Msg: fmt.Sprintf("invalid directory_format: %q (must be %q or %q)",
+				format, DirectoryFormatSubdirectory, DirectoryFormatSibling),
+		}
+	}
+
+	// Validate subdirectory prefix does not contain path separators
+	if err := validateSubdirectoryPrefix(c.Worktree.SubdirectoryPrefix); err != nil {
+		return &ValidationError{Key: "worktree.subdirectory_prefix", Msg: err.Error()}
 	}
 
 	// Validate subdirectory suffix starts with hyphen
 	suffix := c.Worktree.SubdirectorySuffix
 	if suffix != "" && suffix[0] != '-' {
-		return fmt.Errorf("subdirectory_suffix must start with '-', got %q", suffix)
+		return &ValidationError{
+			Key: "worktree.subdirectory_suffix",
+			Msg: fmt.Sprintf("subdirectory_suffix must start with '-', got %q", suffix),
+		}
+	}
+
+	if err := ValidatePathTemplate(c.Worktree.PathTemplate); err != nil {
+		return &ValidationError{Key: "worktree.path_template", Msg: err.Error()}
+	}
+
+	if err := validateBaseDir(c.Worktree.BaseDir); err != nil {
+		return &ValidationError{Key: "worktree.base_dir", Msg: err.Error()}
+	}
+
+	if _, err := ParseDirMode(c.Worktree.ContainerDirMode); err != nil {
+		return &ValidationError{Key: "worktree.container_dir_mode", Msg: err.Error()}
+	}
+
+	// An empty strategy (e.g. "collision_strategy:" in the file) means the default
+	if strategy := c.Worktree.CollisionStrategy; strategy != "" {
+		if err := validateCollisionStrategy(strategy); err != nil {
+			return &ValidationError{Key: "worktree.collision_strategy", Msg: err.Error()}
+		}
+	}
+
+	if answer := c.Prompts.DefaultAnswer; answer != "" {
+		if err := validatePromptAnswer(answer); err != nil {
+			return &ValidationError{Key: "prompts.default_answer", Msg: err.Error()}
+		}
+	}
+
+	if err := validatePromptFormat(c.Prompt.Format); err != nil {
+		return &ValidationError{Key: "prompt.format", Msg: err.Error()}
+	}
+
+	if err := validateProtectedBranches(c.Clean.ProtectedBranches); err != nil {
+		return &ValidationError{Key: "clean.protected_branches", Msg: err.Error()}
+	}
+
+	if _, err := parseGitTimeout(c.Performance.GitTimeout); err != nil {
+		return &ValidationError{Key: "performance.git_timeout", Msg: err.Error()}
 	}
 
 	return nil
@@ -112,13 +799,23 @@ func (c *Config) SetDirectoryFormat(format string) error {
 	return nil
 }
 
-// SetSubdirectoryPrefix sets the subdirectory prefix
+// SetSubdirectoryPrefix sets and validates the subdirectory prefix
 func (c *Config) SetSubdirectoryPrefix(prefix string) error {
-	// No validation needed - any string is valid as prefix
+	if err := validateSubdirectoryPrefix(prefix); err != nil {
+		return err
+	}
 	c.Worktree.SubdirectoryPrefix = prefix
 	return nil
 }
 
+// validateSubdirectoryPrefix rejects prefixes that would produce nested directories
+func validateSubdirectoryPrefix(prefix string) error {
+	if strings.ContainsAny(prefix, `/\`) {
+		return fmt.Errorf("subdirectory_prefix must not contain path separators, got %q", prefix)
+	}
+	return nil
+}
+
 // SetSubdirectorySuffix sets and validates the subdirectory suffix
 func (c *Config) SetSubdirectorySuffix(suffix string) error {
 	if suffix != "" && suffix[0] != '-' {
@@ -128,8 +825,394 @@ func (c *Config) SetSubdirectorySuffix(suffix string) error {
 	return nil
 }
 
+// SetPathTemplate sets and validates the worktree path template
+func (c *Config) SetPathTemplate(tmpl string) error {
+	if err := ValidatePathTemplate(tmpl); err != nil {
+		return err
+	}
+	c.Worktree.PathTemplate = tmpl
+	return nil
+}
+
+// ValidatePathTemplate checks that tmpl only uses known placeholders, contains the branch
+// and doesn't escape its directory with ".." (an empty template is valid)
+func ValidatePathTemplate(tmpl string) error {
+	if tmpl == "" {
+		return nil
+	}
+
+	hasBranch := false
+	for _, placeholder := range pathTemplatePlaceholderRegex.FindAllString(tmpl, -1) {
+		known := false
+		for _, p := range PathTemplatePlaceholders {
+			if placeholder == p {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("path_template contains unknown placeholder %s (supported: %s)",
+				placeholder, strings.Join(PathTemplatePlaceholders, ", "))
+		}
+		if placeholder == "{branch}" || placeholder == "{branch_sanitized}" {
+			hasBranch = true
+		}
+	}
+	if !hasBranch {
+		return fmt.Errorf("path_template must contain {branch} or {branch_sanitized}, got %q", tmpl)
+	}
+
+	for _, part := range strings.FieldsFunc(tmpl, func(c rune) bool { return c == '/' || c == '\\' }) {
+		if part == ".." {
+			return fmt.Errorf("path_template must not contain '..', got %q", tmpl)
+		}
+	}
+
+	return nil
+}
+
+// SetCollisionStrategy sets and validates the collision strategy
+func (c *Config) SetCollisionStrategy(strategy string) error {
+	if err := validateCollisionStrategy(strategy); err != nil {
+		return err
+	}
+	c.Worktree.CollisionStrategy = strategy
+	return nil
+}
+
+// validateCollisionStrategy rejects unknown collision strategies
+func validateCollisionStrategy(strategy string) error {
+	switch strategy {
+	case CollisionStrategySuffix, CollisionStrategyError, CollisionStrategyPrompt:
+		return nil
+	}
+	return fmt.Errorf("invalid value for collision_strategy: %q (must be %q, %q or %q)",
+		strategy, CollisionStrategySuffix, CollisionStrategyError, CollisionStrategyPrompt)
+}
+
+// SetPromptDefaultAnswer sets and validates the answer to confirmation prompts when Enter is pressed
+func (c *Config) SetPromptDefaultAnswer(answer string) error {
+	if err := validatePromptAnswer(answer); err != nil {
+		return err
+	}
+	c.Prompts.DefaultAnswer = answer
+	return nil
+}
+
+// GetPromptFormat returns the format of wt current, e.g. "{repo}:{branch}"
+func (c *Config) GetPromptFormat() string {
+	if c.Prompt.Format == "" {
+		return DefaultPromptFormat
+	}
+	return c.Prompt.Format
+}
+
+// SetPromptFormat sets and validates the format of wt current (empty for the default)
+func (c *Config) SetPromptFormat(format string) error {
+	if err := validatePromptFormat(format); err != nil {
+		return err
+	}
+	c.Prompt.Format = format
+	return nil
+}
+
+// validatePromptFormat rejects unknown placeholders in a prompt format
+func validatePromptFormat(format string) error {
+	for _, placeholder := range pathTemplatePlaceholderRegex.FindAllString(format, -1) {
+		known := false
+		for _, p := range PromptFormatPlaceholders {
+			if placeholder == p {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("format contains unknown placeholder %s (supported: %s)",
+				placeholder, strings.Join(PromptFormatPlaceholders, ", "))
+		}
+	}
+	return nil
+}
+
+// validatePromptAnswer rejects default answers other than "yes" and "no"
+func validatePromptAnswer(answer string) error {
+	switch answer {
+	case PromptAnswerYes, PromptAnswerNo:
+		return nil
+	}
+	return fmt.Errorf("invalid value for default_answer: %q (must be %q or %q)", answer, PromptAnswerYes, PromptAnswerNo)
+}
+
+// SetBaseDir sets and validates the base directory for new worktrees (empty for the repository's parent)
+func (c *Config) SetBaseDir(dir string) error {
+	dir = strings.TrimSpace(dir)
+	if err := validateBaseDir(dir); err != nil {
+		return err
+	}
+	c.Worktree.BaseDir = dir
+	return nil
+}
+
+// validateBaseDir checks that a base directory is absolute once expanded
+// Relative paths are rejected because it would be unclear what they are relative to.
+func validateBaseDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if expanded := ExpandPath(dir); !filepath.IsAbs(expanded) {
+		return fmt.Errorf("invalid base_dir: %q (must be an absolute path, may start with ~ or use $VARIABLES; expands to %q)", dir, expanded)
+	}
+	return nil
+}
+
+// ExpandPath expands environment variables ($VAR or ${VAR}) and a leading "~" in path
+func ExpandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// SetInitSubmodules sets whether submodules are initialized in new worktrees from a boolean string
+func (c *Config) SetInitSubmodules(value string) error {
+	b, err := parseBool("init_submodules", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.InitSubmodules = b
+	return nil
+}
+
+// SetLFSPull sets whether git lfs pull runs in new worktrees from a boolean string
+func (c *Config) SetLFSPull(value string) error {
+	b, err := parseBool("lfs_pull", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.LFSPull = b
+	return nil
+}
+
+// SetRemoteCheck sets whether wt new checks for a remote branch of the same name from a boolean string
+func (c *Config) SetRemoteCheck(value string) error {
+	b, err := parseBool("remote_check", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.RemoteCheck = b
+	return nil
+}
+
+// SetAutoSetUpstream sets whether wt new pushes a new branch and sets its upstream from a boolean string
+func (c *Config) SetAutoSetUpstream(value string) error {
+	b, err := parseBool("auto_set_upstream", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.AutoSetUpstream = b
+	return nil
+}
+
+// SetDefaultRemote sets the remote new branches are pushed to
+func (c *Config) SetDefaultRemote(remote string) error {
+	if strings.TrimSpace(remote) == "" || strings.ContainsAny(remote, " \t\n") {
+		return fmt.Errorf("worktree.default_remote must be a remote name, got %q", remote)
+	}
+	c.Worktree.DefaultRemote = remote
+	return nil
+}
+
+// SetContainerDirMode sets and validates the permission mode of created worktree directories (octal, e.g. "0750")
+func (c *Config) SetContainerDirMode(value string) error {
+	value = strings.TrimSpace(value)
+	if _, err := ParseDirMode(value); err != nil {
+		return err
+	}
+	c.Worktree.ContainerDirMode = value
+	return nil
+}
+
+// ParseDirMode parses an octal directory permission mode such as "0750", "750" or "0o750" ("" is 0)
+// The owner must keep full access, or wt could not create worktrees in the directory.
+func ParseDirMode(value string) (os.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+	if err != nil || n > 0o777 {
+		return 0, fmt.Errorf("invalid container_dir_mode: %q (must be an octal permission mode such as 0750)", value)
+	}
+	if n&0o700 != 0o700 {
+		return 0, fmt.Errorf("invalid container_dir_mode: %q (the owner needs read, write and search permission: 07xx)", value)
+	}
+	return os.FileMode(n), nil
+}
+
+// SetNestedBranchDirs sets whether branch slashes become nested directories from a boolean string
+func (c *Config) SetNestedBranchDirs(value string) error {
+	b, err := parseBool("nested_branch_dirs", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.NestedBranchDirs = b
+	return nil
+}
+
+// SetSanitizeASCIIOnly sets whether non-ASCII characters are replaced in worktree directory names from a boolean string
+func (c *Config) SetSanitizeASCIIOnly(value string) error {
+	b, err := parseBool("sanitize_ascii_only", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.SanitizeASCIIOnly = b
+	return nil
+}
+
+// SetLowercaseDirs sets whether branch names are lowercased in worktree directory names from a boolean string
+func (c *Config) SetLowercaseDirs(value string) error {
+	b, err := parseBool("lowercase_dirs", value)
+	if err != nil {
+		return err
+	}
+	c.Worktree.LowercaseDirs = b
+	return nil
+}
+
+// SetSelectorBinary sets and validates the fuzzy finder command
+func (c *Config) SetSelectorBinary(binary string) error {
+	if strings.TrimSpace(binary) == "" {
+		return fmt.Errorf("selector.binary must not be empty")
+	}
+	c.Selector.Binary = binary
+	return nil
+}
+
+// SetSelectorExtraArgs sets the additional fuzzy finder arguments from a space-separated string
+func (c *Config) SetSelectorExtraArgs(args string) error {
+	c.Selector.ExtraArgs = strings.Fields(args)
+	return nil
+}
+
+// SetEditorCommand sets the editor command line (empty to use environment variables)
+func (c *Config) SetEditorCommand(command string) error {
+	c.Editor.Command = strings.TrimSpace(command)
+	return nil
+}
+
+// SetEditorArgs sets the additional editor arguments from a space-separated string
+func (c *Config) SetEditorArgs(args string) error {
+	c.Editor.Args = strings.Fields(args)
+	return nil
+}
+
+// SetEditorGUIEditors sets the editors started without waiting from a space-separated string
+func (c *Config) SetEditorGUIEditors(editors string) error {
+	c.Editor.GUIEditors = strings.Fields(editors)
+	return nil
+}
+
+// SetPruneRemoteRefs sets whether wt clean deletes stale remote-tracking refs from a boolean string
+func (c *Config) SetPruneRemoteRefs(value string) error {
+	b, err := parseBool("prune_remote_refs", value)
+	if err != nil {
+		return err
+	}
+	c.Clean.PruneRemoteRefs = b
+	return nil
+}
+
+// SetUseTrash sets whether wt clean moves removed worktrees to the trash from a boolean string
+func (c *Config) SetUseTrash(value string) error {
+	b, err := parseBool("use_trash", value)
+	if err != nil {
+		return err
+	}
+	c.Clean.UseTrash = b
+	return nil
+}
+
+// SetProtectedBranches sets and validates the protected branch patterns from a space-separated string
+func (c *Config) SetProtectedBranches(patterns string) error {
+	fields := strings.Fields(patterns)
+	if err := validateProtectedBranches(fields); err != nil {
+		return err
+	}
+	c.Clean.ProtectedBranches = fields
+	return nil
+}
+
+// validateProtectedBranches checks that the protected branch patterns are valid globs
+func validateProtectedBranches(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected_branches pattern: %q (%v)", pattern, err)
+		}
+	}
+	return nil
+}
+
+// SetUpdateCheck sets whether wt checks for new releases from a boolean string
+func (c *Config) SetUpdateCheck(value string) error {
+	b, err := parseBool("update_check.enabled", value)
+	if err != nil {
+		return err
+	}
+	c.UpdateCheck.Enabled = b
+	return nil
+}
+
+// SetSlowFSMode sets the slow filesystem mode from a boolean string
+func (c *Config) SetSlowFSMode(value string) error {
+	b, err := parseBool("performance.slow_fs_mode", value)
+	if err != nil {
+		return err
+	}
+	c.Performance.SlowFSMode = b
+	return nil
+}
+
+// SetGitTimeout sets and validates the time limit for local git commands in slow filesystem mode
+func (c *Config) SetGitTimeout(value string) error {
+	if _, err := parseGitTimeout(value); err != nil {
+		return err
+	}
+	c.Performance.GitTimeout = value
+	return nil
+}
+
+// parseGitTimeout parses performance.git_timeout, a duration such as "10s" ("" is the default, "0" no limit)
+func parseGitTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		value = DefaultGitTimeout
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid value for git_timeout: %s (must be a duration such as \"10s\", or \"0\" for no limit)", value)
+	}
+	return d, nil
+}
+
+// parseBool parses a boolean setting value
+func parseBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %s (must be 'true' or 'false')", name, value)
+	}
+	return b, nil
+}
+
 // Save saves the configuration to the file
+// When the file already exists, only changed keys are updated; comments, ordering
+// and unknown keys are preserved.
 func (c *Config) Save() error {
+	// Values from the repository configuration file would leak into the user's file
+	if c.repoPath != "" {
+		return fmt.Errorf("cannot save a configuration that includes the repository configuration file %s", c.repoPath)
+	}
+
 	// Validate before saving
 	if err := c.Validate(); err != nil {
 		return err
@@ -141,20 +1224,206 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	doc := c.doc
+	if doc == nil && c.partial {
+		doc = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if doc == nil {
+		// New file: write all settings
+		doc = &yaml.Node{Kind: yaml.DocumentNode}
+		for _, s := range settings {
+			setNodeValue(doc, s.key, s.get(c), s.tag)
+		}
+	} else {
+		// Existing file: update only keys whose value changed
+		for _, s := range settings {
+			value := s.get(c)
+			_, valueNode := lookupNode(doc, s.key)
+			if valueNode != nil {
+				if current, ok := nodeValue(valueNode); ok && current == value {
+					continue
+				}
+			}
+			if valueNode == nil && value == s.def && c.Source(s.key) != SourceFile {
+				continue
+			}
+			setNodeValue(doc, s.key, value, s.tag)
+		}
+	}
+
 	// Marshal to YAML
-	data, err := yaml.Marshal(c)
-	if err != nil {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
 	// Write to file with restrictive permissions (0600 for security)
-	if err := os.WriteFile(c.path, data, 0600); err != nil {
+	if err := os.WriteFile(c.path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	c.doc = doc
+	return nil
+}
+
+// WriteTemplate writes the current settings to the file as a commented template
+func (c *Config) WriteTemplate() error {
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	content := fmt.Sprintf(`# wt configuration file
+# See CONFIGURATION.md for details on each setting.
+
+worktree:
+  # Directory layout: "subdirectory" (<prefix><repo><suffix>/<branch>) or "sibling" (<repo>-<branch>)
+  directory_format: %s
+  # Prefix for the worktrees directory in subdirectory mode (must not contain path separators)
+  subdirectory_prefix: %q
+  # Suffix for the worktrees directory in subdirectory mode (must start with '-')
+  subdirectory_suffix: %q
+  # Run "git submodule update --init --recursive" in new worktrees
+  init_submodules: %t
+  # Run "git lfs pull" in new worktrees of repositories that use Git LFS
+  lfs_pull: %t
+  # Keep slashes in branch names as nested directories in subdirectory mode (feature/login -> feature/login)
+  nested_branch_dirs: %t
+  # Replace non-ASCII characters in worktree directory names (false keeps letters of any script)
+  sanitize_ascii_only: %t
+  # Lowercase branch names in worktree directory names (Feature/JIRA-123 -> feature-jira-123)
+  lowercase_dirs: %t
+  # Path template for new worktrees; overrides the three layout keys above when set
+  # Placeholders: {base}, {repo}, {branch}, {branch_sanitized}, {date}
+  path_template: %q
+  # When the worktree path is taken: "suffix" (add -2, -3, ...), "error" or "prompt"
+  collision_strategy: %s
+  # Directory to create new worktrees in instead of next to the repository ("" for the parent directory);
+  # may start with ~ and use $VARIABLES
+  base_dir: %q
+  # Warn in wt new when a remote-tracking branch of the same name has different history
+  # (and offer to start the new branch from it)
+  remote_check: %t
+  # Push branches created by wt new to default_remote and set their upstream (git push -u)
+  auto_set_upstream: %t
+  # Remote new branches are pushed to (wt new --push, --set-upstream-only)
+  default_remote: %s
+  # Permission mode of the directories wt creates to hold worktrees, e.g. "0750" ("" follows the umask)
+  container_dir_mode: %q
+
+selector:
+  # Fuzzy finder for interactive selection (fzf, sk or fzy; numbered selection if not installed)
+  binary: %s
+  # Additional arguments (use [] to rely on FZF_DEFAULT_OPTS; WT_FZF_OPTS is appended at run time)
+  extra_args: %s
+
+editor:
+  # Editor for wt open and wt config edit, with arguments (e.g. "emacsclient -n");
+  # takes precedence over WT_EDITOR, VISUAL and EDITOR
+  command: %q
+  # Additional arguments passed before the path
+  args: %s
+  # Editors started in the background by wt open (use "wt open --wait" to wait for them)
+  gui_editors: %s
+  # Per-repository editors (repository name -> command line), replacing command and args
+  # repos:
+  #   myproject: "idea"
+
+clean:
+  # Delete the remote-tracking ref of a deleted branch when the remote branch is gone
+  prune_remote_refs: %t
+  # Move removed worktrees to the trash instead of deleting them (restore with wt clean --restore)
+  use_trash: %t
+  # Branches never deleted by wt clean (glob patterns; {default} is the repository's default branch)
+  protected_branches: %s
+
+update_check:
+  # Check GitHub for a new wt release at most once a day and print a notice (WT_NO_UPDATE_CHECK=1 disables)
+  enabled: %t
+
+prompts:
+  # Answer to confirmation prompts when Enter is pressed: "yes" or "no"
+  # (without a terminal, prompts fail instead; pass --yes to confirm)
+  default_answer: %s
+
+prompt:
+  # Output of wt current for shell prompts; placeholders: {repo}, {branch}, {path}
+  format: %q
+
+performance:
+  # For repositories on slow network filesystems (NFS, SMB): limit local git commands to git_timeout,
+  # skip the dirty markers in selection lists and cache the worktree list for a few seconds
+  # (it may then be briefly out of date; --no-cache reads it from git)
+  slow_fs_mode: %t
+  # Time limit for each local git command in slow_fs_mode, e.g. "10s" ("0" for no limit)
+  git_timeout: %q
+`, c.Worktree.DirectoryFormat, c.Worktree.SubdirectoryPrefix, c.Worktree.SubdirectorySuffix,
+		c.Worktree.InitSubmodules, c.Worktree.LFSPull, c.Worktree.NestedBranchDirs, c.Worktree.SanitizeASCIIOnly,
+		c.Worktree.LowercaseDirs, c.Worktree.PathTemplate, c.Worktree.CollisionStrategy, c.Worktree.BaseDir,
+		c.Worktree.RemoteCheck, c.Worktree.AutoSetUpstream, c.GetDefaultRemote(), c.Worktree.ContainerDirMode,
+		c.Selector.Binary, flowList(c.Selector.ExtraArgs),
+		c.Editor.Command, flowList(c.Editor.Args), flowList(c.Editor.GUIEditors), c.Clean.PruneRemoteRefs, c.Clean.UseTrash,
+		flowList(c.Clean.ProtectedBranches),
+		c.UpdateCheck.Enabled, c.GetPromptDefaultAnswer(), c.GetPromptFormat(),
+		c.Performance.SlowFSMode, c.getGitTimeout())
+
+	if err := os.WriteFile(c.path, []byte(content), 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// WriteRepoTemplate writes a commented template for a new repository configuration file at path
+// Unlike WriteTemplate, it sets nothing: keys left out keep coming from the configuration file.
+func WriteRepoTemplate(path string) error {
+	content := `# wt repository configuration file
+# Settings here override the configuration file for this repository only; leave out the
+# others. Same format as the configuration file (see CONFIGURATION.md), for example:
+#
+# worktree:
+#   base_dir: "~/worktrees"
+# editor:
+#   command: "idea"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write repository config file: %w", err)
+	}
+	return nil
+}
+
+// flowList formats items as a YAML flow sequence, e.g. ["--height=40%", "--reverse"]
+func flowList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// Unset removes a key from the configuration file, reverting it to its default value
+// Returns the default value now in effect and whether the key was present in the file.
+// Call Save to persist the change.
+func (c *Config) Unset(key string) (string, bool, error) {
+	for _, s := range settings {
+		if s.key != key {
+			continue
+		}
+		if err := s.set(c, s.def); err != nil {
+			return "", false, err
+		}
+		removed := deleteNode(c.doc, key)
+		delete(c.sources, key)
+		return s.def, removed, nil
+	}
+	return "", false, &UnknownKeyError{Key: key}
+}
+
 // Reset removes the configuration file
 func (c *Config) Reset() error {
 	if _, err := os.Stat(c.path); os.IsNotExist(err) {
@@ -168,9 +1437,31 @@ func (c *Config) Reset() error {
 	return nil
 }
 
-// GetDefaultConfigPath returns the default configuration file path
+// PathOverride, when set (e.g. by the --config flag), replaces the default configuration file path
+var PathOverride = ""
+
+// ConfigFileEnv is the environment variable that points wt at an alternate config file
+const ConfigFileEnv = "WT_CONFIG_FILE"
+
+// GetDefaultConfigPath returns the configuration file path
+// Precedence: PathOverride (--config) > WT_CONFIG_FILE > $XDG_CONFIG_HOME/wt/config.yaml
 func GetDefaultConfigPath() (string, error) {
-	// Use XDG_CONFIG_HOME if set, otherwise use ~/.config
+	if PathOverride != "" {
+		return filepath.Abs(PathOverride)
+	}
+	if envPath := os.Getenv(ConfigFileEnv); envPath != "" {
+		return filepath.Abs(envPath)
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// configDir returns the directory of wt's user files: $XDG_CONFIG_HOME/wt, or ~/.config/wt
+func configDir() (string, error) {
 	configHome := os.Getenv("XDG_CONFIG_HOME")
 	if configHome == "" {
 		homeDir, err := os.UserHomeDir()
@@ -179,6 +1470,38 @@ func GetDefaultConfigPath() (string, error) {
 		}
 		configHome = filepath.Join(homeDir, ".config")
 	}
+	return filepath.Join(configHome, "wt"), nil
+}
+
+// yamlLineRegex extracts the line number from yaml parser errors (e.g. "yaml: line 3: ...")
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// ErrorLine returns the 1-based line in data that caused err, or 0 if unknown
+func ErrorLine(data []byte, err error) int {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return KeyLine(data, ve.Key)
+	}
+
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		if line, convErr := strconv.Atoi(m[1]); convErr == nil {
+			return line
+		}
+	}
+
+	return 0
+}
 
-	return filepath.Join(configHome, "wt", "config.yaml"), nil
+// KeyLine returns the 1-based line of a dotted key in YAML data, or 0 if not found
+func KeyLine(data []byte, key string) int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0
+	}
+
+	keyNode, _ := lookupNode(&doc, key)
+	if keyNode == nil {
+		return 0
+	}
+	return keyNode.Line
 }
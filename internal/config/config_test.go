@@ -1,8 +1,12 @@
 package config_test
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/toritori0318/git-wt/internal/config"
@@ -115,6 +119,13 @@ func TestLoadFromFile(t *testing.T) {
   directory_format: invalid`,
 			wantErr: true,
 		},
+		{
+			name: "subdirectory prefix with path separator",
+			yamlContent: `worktree:
+  directory_format: subdirectory
+  subdirectory_prefix: nested/`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -385,6 +396,16 @@ func TestSetSubdirectoryPrefix(t *testing.T) {
 			value:   "prefix-",
 			wantErr: false,
 		},
+		{
+			name:    "prefix with slash is invalid",
+			value:   "foo/",
+			wantErr: true,
+		},
+		{
+			name:    "prefix with backslash is invalid",
+			value:   `foo\`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -457,3 +478,1015 @@ func TestSetSubdirectorySuffix(t *testing.T) {
 		})
 	}
 }
+
+func TestSetBoolSettings(t *testing.T) {
+	cfg := &config.Config{}
+
+	if err := cfg.SetInitSubmodules("true"); err != nil {
+		t.Fatalf("SetInitSubmodules() returned error: %v", err)
+	}
+	if !cfg.GetInitSubmodules() {
+		t.Error("GetInitSubmodules() = false, want true")
+	}
+
+	if err := cfg.SetLFSPull("false"); err != nil {
+		t.Fatalf("SetLFSPull() returned error: %v", err)
+	}
+	if cfg.GetLFSPull() {
+		t.Error("GetLFSPull() = true, want false")
+	}
+
+	if err := cfg.SetNestedBranchDirs("true"); err != nil {
+		t.Fatalf("SetNestedBranchDirs() returned error: %v", err)
+	}
+	if !cfg.GetNestedBranchDirs() {
+		t.Error("GetNestedBranchDirs() = false, want true")
+	}
+
+	if err := cfg.SetLowercaseDirs("true"); err != nil {
+		t.Fatalf("SetLowercaseDirs() returned error: %v", err)
+	}
+	if !cfg.GetLowercaseDirs() {
+		t.Error("GetLowercaseDirs() = false, want true")
+	}
+
+	if err := cfg.SetSanitizeASCIIOnly("true"); err != nil {
+		t.Fatalf("SetSanitizeASCIIOnly() returned error: %v", err)
+	}
+	if !cfg.GetSanitizeASCIIOnly() {
+		t.Error("GetSanitizeASCIIOnly() = false, want true")
+	}
+
+	if err := cfg.SetInitSubmodules("yes"); err == nil {
+		t.Error("SetInitSubmodules(\"yes\") expected error, got nil")
+	}
+	if err := cfg.SetLFSPull(""); err == nil {
+		t.Error("SetLFSPull(\"\") expected error, got nil")
+	}
+}
+
+// TestWriteTemplate tests that the commented template round-trips through Load
+func TestSetPathTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "empty template is valid", value: "", wantErr: false},
+		{name: "nested directories", value: "{base}/{repo}-trees/{branch}", wantErr: false},
+		{name: "relative template", value: "{repo}__{branch_sanitized}", wantErr: false},
+		{name: "home directory with date", value: "~/worktrees/{repo}/{date}-{branch_sanitized}", wantErr: false},
+		{name: "missing branch placeholder", value: "{base}/{repo}-{date}", wantErr: true},
+		{name: "unknown placeholder", value: "{base}/{user}/{branch}", wantErr: true},
+		{name: "parent directory", value: "{base}/../{branch}", wantErr: true},
+		{name: "parent directory with backslash", value: `{base}\..\{branch}`, wantErr: true},
+		{name: "dots inside a name are allowed", value: "{base}/{repo}..wt/{branch}", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+
+			err := cfg.SetPathTemplate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetPathTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && cfg.GetPathTemplate() != tt.value {
+				t.Errorf("GetPathTemplate() = %q, want %q", cfg.GetPathTemplate(), tt.value)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsEscapingPathTemplate(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "worktree:\n  path_template: \"{base}/../{branch}\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := config.Load(configPath)
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "worktree.path_template" {
+		t.Errorf("Load() error = %v, want ValidationError for worktree.path_template", err)
+	}
+}
+
+func TestSetCollisionStrategy(t *testing.T) {
+	for _, value := range []string{"suffix", "error", "prompt"} {
+		cfg := &config.Config{}
+		if err := cfg.SetCollisionStrategy(value); err != nil {
+			t.Errorf("SetCollisionStrategy(%q) returned error: %v", value, err)
+		}
+		if got := cfg.GetCollisionStrategy(); got != value {
+			t.Errorf("GetCollisionStrategy() = %q, want %q", got, value)
+		}
+	}
+
+	cfg := &config.Config{}
+	for _, value := range []string{"", "overwrite", "Suffix"} {
+		if err := cfg.SetCollisionStrategy(value); err == nil {
+			t.Errorf("SetCollisionStrategy(%q) expected error, got nil", value)
+		}
+	}
+	// Unset means the default
+	if got := cfg.GetCollisionStrategy(); got != config.DefaultCollisionStrategy {
+		t.Errorf("GetCollisionStrategy() = %q, want %q", got, config.DefaultCollisionStrategy)
+	}
+}
+
+func TestSetPromptDefaultAnswer(t *testing.T) {
+	for _, value := range []string{"yes", "no"} {
+		cfg := &config.Config{}
+		if err := cfg.SetPromptDefaultAnswer(value); err != nil {
+			t.Errorf("SetPromptDefaultAnswer(%q) returned error: %v", value, err)
+		}
+		if got := cfg.GetPromptDefaultAnswer(); got != value {
+			t.Errorf("GetPromptDefaultAnswer() = %q, want %q", got, value)
+		}
+	}
+
+	cfg := &config.Config{}
+	for _, value := range []string{"", "y", "true", "Yes"} {
+		if err := cfg.SetPromptDefaultAnswer(value); err == nil {
+			t.Errorf("SetPromptDefaultAnswer(%q) expected error, got nil", value)
+		}
+	}
+	// Unset means the default
+	if got := cfg.GetPromptDefaultAnswer(); got != config.DefaultPromptAnswer {
+		t.Errorf("GetPromptDefaultAnswer() = %q, want %q", got, config.DefaultPromptAnswer)
+	}
+}
+
+func TestSetPromptFormat(t *testing.T) {
+	cfg := &config.Config{}
+	if got := cfg.GetPromptFormat(); got != config.DefaultPromptFormat {
+		t.Errorf("GetPromptFormat() = %q, want %q", got, config.DefaultPromptFormat)
+	}
+
+	for _, value := range []string{"{branch}", "[{repo}] {path}", "no placeholders"} {
+		if err := cfg.SetPromptFormat(value); err != nil {
+			t.Errorf("SetPromptFormat(%q) returned error: %v", value, err)
+		}
+		if got := cfg.GetPromptFormat(); got != value {
+			t.Errorf("GetPromptFormat() = %q, want %q", got, value)
+		}
+	}
+
+	for _, value := range []string{"{date}", "{repo}:{branch_sanitized}"} {
+		if err := cfg.SetPromptFormat(value); err == nil {
+			t.Errorf("SetPromptFormat(%q) expected error, got nil", value)
+		}
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "wt", "config.yaml")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	cfg.Worktree.SubdirectoryPrefix = ""
+	cfg.Worktree.SubdirectorySuffix = "-trees"
+
+	if err := cfg.WriteTemplate(); err != nil {
+		t.Fatalf("WriteTemplate() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read template: %v", err)
+	}
+	if !strings.Contains(string(data), "# ") {
+		t.Errorf("template should contain comments, got:\n%s", data)
+	}
+
+	cfg2, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() after WriteTemplate() returned error: %v", err)
+	}
+	if got := cfg2.GetSubdirectoryPrefix(); got != "" {
+		t.Errorf("GetSubdirectoryPrefix() = %q, want %q", got, "")
+	}
+	if got := cfg2.GetSubdirectorySuffix(); got != "-trees" {
+		t.Errorf("GetSubdirectorySuffix() = %q, want %q", got, "-trees")
+	}
+}
+
+func TestErrorLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		wantLine int
+	}{
+		{
+			name: "invalid directory format",
+			yaml: `# comment
+worktree:
+  directory_format: invalid`,
+			wantLine: 3,
+		},
+		{
+			name: "invalid suffix",
+			yaml: `worktree:
+  directory_format: subdirectory
+  subdirectory_suffix: wt`,
+			wantLine: 3,
+		},
+		{
+			name: "syntax error",
+			yaml: `worktree:
+  directory_format: subdirectory
+ bad: [`,
+			wantLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.yaml), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			_, err := config.Load(configPath)
+			if err == nil {
+				t.Fatalf("Load() error = nil, want error")
+			}
+
+			if got := config.ErrorLine([]byte(tt.yaml), err); got != tt.wantLine {
+				t.Errorf("ErrorLine() = %d, want %d (err: %v)", got, tt.wantLine, err)
+			}
+		})
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantFormat string
+		wantPrefix string
+		wantSuffix string
+		wantSource map[string]string
+		wantErrEnv string // Environment variable expected in the error message
+	}{
+		{
+			name:       "no overrides keeps file values",
+			wantFormat: "subdirectory",
+			wantPrefix: ".",
+			wantSuffix: "-wt",
+		},
+		{
+			name:       "directory format override",
+			env:        map[string]string{"WT_DIRECTORY_FORMAT": "sibling"},
+			wantFormat: "sibling",
+			wantPrefix: ".",
+			wantSuffix: "-wt",
+			wantSource: map[string]string{"worktree.directory_format": "WT_DIRECTORY_FORMAT"},
+		},
+		{
+			name: "prefix and suffix overrides",
+			env: map[string]string{
+				"WT_SUBDIRECTORY_PREFIX": "",
+				"WT_SUBDIRECTORY_SUFFIX": "-trees",
+			},
+			wantFormat: "subdirectory",
+			wantPrefix: "",
+			wantSuffix: "-trees",
+			wantSource: map[string]string{
+				"worktree.subdirectory_prefix": "WT_SUBDIRECTORY_PREFIX",
+				"worktree.subdirectory_suffix": "WT_SUBDIRECTORY_SUFFIX",
+			},
+		},
+		{
+			name:       "invalid directory format names the variable",
+			env:        map[string]string{"WT_DIRECTORY_FORMAT": "flat"},
+			wantErrEnv: "WT_DIRECTORY_FORMAT",
+		},
+		{
+			name:       "invalid suffix names the variable",
+			env:        map[string]string{"WT_SUBDIRECTORY_SUFFIX": "trees"},
+			wantErrEnv: "WT_SUBDIRECTORY_SUFFIX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, o := range config.EnvOverrides {
+				t.Setenv(o.Env, "") // Registers restore of the original value
+				os.Unsetenv(o.Env)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+
+			err = config.ApplyEnvOverrides(cfg)
+			if tt.wantErrEnv != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrEnv) {
+					t.Errorf("ApplyEnvOverrides() error = %v, want error naming %s", err, tt.wantErrEnv)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyEnvOverrides() returned error: %v", err)
+			}
+
+			if got := cfg.GetDirectoryFormat(); got != tt.wantFormat {
+				t.Errorf("GetDirectoryFormat() = %q, want %q", got, tt.wantFormat)
+			}
+			if got := cfg.GetSubdirectoryPrefix(); got != tt.wantPrefix {
+				t.Errorf("GetSubdirectoryPrefix() = %q, want %q", got, tt.wantPrefix)
+			}
+			if got := cfg.GetSubdirectorySuffix(); got != tt.wantSuffix {
+				t.Errorf("GetSubdirectorySuffix() = %q, want %q", got, tt.wantSuffix)
+			}
+			for _, o := range config.EnvOverrides {
+				if got, want := cfg.EnvSource(o.Key), tt.wantSource[o.Key]; got != want {
+					t.Errorf("EnvSource(%q) = %q, want %q", o.Key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDefaultConfigPathPrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+	flagPath := filepath.Join(tempDir, "flag.yaml")
+	envPath := filepath.Join(tempDir, "env.yaml")
+	xdgHome := filepath.Join(tempDir, "xdg")
+
+	tests := []struct {
+		name     string
+		override string
+		env      string
+		want     string
+	}{
+		{
+			name: "XDG default",
+			want: filepath.Join(xdgHome, "wt", "config.yaml"),
+		},
+		{
+			name: "WT_CONFIG_FILE over XDG",
+			env:  envPath,
+			want: envPath,
+		},
+		{
+			name:     "--config over WT_CONFIG_FILE",
+			override: flagPath,
+			env:      envPath,
+			want:     flagPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", xdgHome)
+			t.Setenv(config.ConfigFileEnv, tt.env)
+
+			origOverride := config.PathOverride
+			config.PathOverride = tt.override
+			defer func() { config.PathOverride = origOverride }()
+
+			got, err := config.GetDefaultConfigPath()
+			if err != nil {
+				t.Fatalf("GetDefaultConfigPath() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetDefaultConfigPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSavePreservesCommentsAndUnknownKeys tests that Save only rewrites changed keys
+func TestSavePreservesCommentsAndUnknownKeys(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# My wt settings
+worktree:
+  # Keep worktrees hidden
+  directory_format: subdirectory
+  subdirectory_prefix: "."
+  subdirectory_suffix: -wt # short and clear
+  future_option: true
+custom:
+  key: value
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	// Unknown keys are expected here; silence the warnings
+	origOutput := config.WarningOutput
+	config.WarningOutput = io.Discard
+	defer func() { config.WarningOutput = origOutput }()
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := cfg.SetDirectoryFormat(config.DirectoryFormatSibling); err != nil {
+		t.Fatalf("SetDirectoryFormat() returned error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	got := string(data)
+
+	want := strings.Replace(original, "directory_format: subdirectory", "directory_format: sibling", 1)
+	if got != want {
+		t.Errorf("Save() output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	cfg2, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() returned error: %v", err)
+	}
+	if got := cfg2.GetDirectoryFormat(); got != config.DirectoryFormatSibling {
+		t.Errorf("GetDirectoryFormat() = %q, want %q", got, config.DirectoryFormatSibling)
+	}
+}
+
+// TestSaveAddsMissingKey tests that a changed key absent from the file is appended
+func TestSaveAddsMissingKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := "# comment\nworktree:\n  directory_format: subdirectory\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := cfg.SetSubdirectoryPrefix(""); err != nil {
+		t.Fatalf("SetSubdirectoryPrefix() returned error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	want := original + "  subdirectory_prefix: \"\"\n"
+	if string(data) != want {
+		t.Errorf("Save() output mismatch\ngot:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+// TestSaveBoolSetting tests that boolean settings are written unquoted and load back
+func TestSaveBoolSetting(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := "worktree:\n  directory_format: subdirectory\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := cfg.SetInitSubmodules("true"); err != nil {
+		t.Fatalf("SetInitSubmodules() returned error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	want := original + "  init_submodules: true\n"
+	if string(data) != want {
+		t.Errorf("Save() output mismatch\ngot:\n%s\nwant:\n%s", data, want)
+	}
+
+	cfg2, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() returned error: %v", err)
+	}
+	if !cfg2.GetInitSubmodules() {
+		t.Error("After Save/Load: GetInitSubmodules() = false, want true")
+	}
+	if !cfg2.GetLFSPull() {
+		t.Error("After Save/Load: GetLFSPull() = false, want true (default)")
+	}
+}
+
+func TestSaveSelectorExtraArgs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := "selector:\n  binary: sk\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.GetSelectorExtraArgs(); !reflect.DeepEqual(got, []string{"--height=40%", "--reverse"}) {
+		t.Errorf("GetSelectorExtraArgs() = %q, want the defaults", got)
+	}
+	if err := cfg.SetSelectorExtraArgs("--height=50%  --border"); err != nil {
+		t.Fatalf("SetSelectorExtraArgs() returned error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	want := original + "  extra_args: [--height=50%, --border]\n"
+	if string(data) != want {
+		t.Errorf("Save() output mismatch\ngot:\n%s\nwant:\n%s", data, want)
+	}
+
+	cfg2, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() returned error: %v", err)
+	}
+	if got := cfg2.GetSelectorBinary(); got != "sk" {
+		t.Errorf("After Save/Load: GetSelectorBinary() = %q, want %q", got, "sk")
+	}
+	if got := cfg2.GetSelectorExtraArgs(); !reflect.DeepEqual(got, []string{"--height=50%", "--border"}) {
+		t.Errorf("After Save/Load: GetSelectorExtraArgs() = %q, want [--height=50%% --border]", got)
+	}
+	if err := cfg2.SetSelectorBinary(""); err == nil {
+		t.Error("SetSelectorBinary(\"\") should return error")
+	}
+}
+
+func TestUnset(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# comment
+worktree:
+  directory_format: sibling
+  subdirectory_suffix: -trees
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	def, removed, err := cfg.Unset("worktree.subdirectory_suffix")
+	if err != nil {
+		t.Fatalf("Unset() returned error: %v", err)
+	}
+	if def != config.DefaultSubdirectorySuffix || !removed {
+		t.Errorf("Unset() = (%q, %v), want (%q, true)", def, removed, config.DefaultSubdirectorySuffix)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	want := "# comment\nworktree:\n  directory_format: sibling\n"
+	if string(data) != want {
+		t.Errorf("Save() after Unset() output mismatch\ngot:\n%s\nwant:\n%s", data, want)
+	}
+
+	// Key not present in the file
+	_, removed, err = cfg.Unset("worktree.subdirectory_prefix")
+	if err != nil {
+		t.Fatalf("Unset() returned error: %v", err)
+	}
+	if removed {
+		t.Errorf("Unset() of absent key reported removed = true")
+	}
+
+	// Unknown key lists known keys
+	_, _, err = cfg.Unset("worktree.unknown")
+	if err == nil || !strings.Contains(err.Error(), "worktree.directory_format") {
+		t.Errorf("Unset() of unknown key error = %v, want error listing known keys", err)
+	}
+}
+
+func TestLoadUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		yamlContent string
+		strict      bool
+		strictEnv   string
+		wantErr     bool
+		wantOutput  []string
+	}{
+		{
+			name: "typo under worktree warns with suggestion",
+			yamlContent: `worktree:
+  directory_format: subdirectory
+  subdirectory_sufix: -trees`,
+			wantOutput: []string{
+				`unknown config key "worktree.subdirectory_sufix" at line 3`,
+				`did you mean "worktree.subdirectory_suffix"?`,
+			},
+		},
+		{
+			name: "unknown top-level section warns without suggestion",
+			yamlContent: `worktree:
+  directory_format: subdirectory
+plugins:
+  enabled: true`,
+			wantOutput: []string{`unknown config key "plugins"`},
+		},
+		{
+			name: "strict flag turns warnings into errors",
+			yamlContent: `worktree:
+  directroy_format: sibling`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name: "strict env turns warnings into errors",
+			yamlContent: `worktree:
+  directroy_format: sibling`,
+			strictEnv: "1",
+			wantErr:   true,
+		},
+		{
+			name: "known keys produce no warnings",
+			yamlContent: `worktree:
+  directory_format: sibling
+  subdirectory_prefix: _`,
+		},
+		{
+			name: "repository names under editor.repos are not unknown keys",
+			yamlContent: `editor:
+  command: code
+  repos:
+    myproject: idea
+    other-repo: "emacsclient -n"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.yamlContent), 0644); err != nil {
+				t.Fatalf("Failed to write test config file: %v", err)
+			}
+
+			t.Setenv(config.StrictEnv, tt.strictEnv)
+			origStrict, origOutput := config.Strict, config.WarningOutput
+			defer func() { config.Strict, config.WarningOutput = origStrict, origOutput }()
+
+			var buf strings.Builder
+			config.Strict = tt.strict
+			config.WarningOutput = &buf
+
+			_, err := config.Load(configPath)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Load() error = nil, wantErr = true")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+
+			output := buf.String()
+			if len(tt.wantOutput) == 0 && output != "" {
+				t.Errorf("Load() printed unexpected warnings: %s", output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("warning output should contain %q, got: %s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("worktree:\n  directory_format: sibling\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	for _, o := range config.EnvOverrides {
+		t.Setenv(o.Env, "")
+		os.Unsetenv(o.Env)
+	}
+	t.Setenv("WT_SUBDIRECTORY_SUFFIX", "-trees")
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		t.Fatalf("ApplyEnvOverrides() returned error: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want config.Source
+	}{
+		{key: "worktree.directory_format", want: config.SourceFile},
+		{key: "worktree.subdirectory_prefix", want: config.SourceDefault},
+		{key: "worktree.subdirectory_suffix", want: config.SourceEnv},
+	}
+	for _, tt := range tests {
+		if got := cfg.Source(tt.key); got != tt.want {
+			t.Errorf("Source(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if err := cfg.SetFromFlag("worktree.directory_format", "subdirectory"); err != nil {
+		t.Fatalf("SetFromFlag() returned error: %v", err)
+	}
+	if got := cfg.Source("worktree.directory_format"); got != config.SourceFlag {
+		t.Errorf("Source() after SetFromFlag() = %q, want %q", got, config.SourceFlag)
+	}
+	if err := cfg.SetFromFlag("worktree.unknown", "x"); err == nil {
+		t.Errorf("SetFromFlag() with unknown key error = nil, want error")
+	}
+}
+
+func TestEditorConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := `editor:
+  command: emacsclient -n
+  args: [--alternate-editor=]
+  repos:
+    myproject: idea
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if got := cfg.GetEditorCommand(); got != "emacsclient -n" {
+		t.Errorf("GetEditorCommand() = %q, want %q", got, "emacsclient -n")
+	}
+	if got, want := cfg.GetEditorArgs(), []string{"--alternate-editor="}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetEditorArgs() = %q, want %q", got, want)
+	}
+	if got := cfg.GetRepoEditor("myproject"); got != "idea" {
+		t.Errorf("GetRepoEditor(myproject) = %q, want %q", got, "idea")
+	}
+	if got := cfg.GetRepoEditor("other"); got != "" {
+		t.Errorf("GetRepoEditor(other) = %q, want empty", got)
+	}
+
+	// Save keeps the per-repository editors
+	if err := cfg.SetEditorArgs(""); err != nil {
+		t.Fatalf("SetEditorArgs() returned error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	saved, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() after Save() returned error: %v", err)
+	}
+	if got := saved.GetEditorArgs(); len(got) != 0 {
+		t.Errorf("GetEditorArgs() after Save() = %q, want none", got)
+	}
+	if got := saved.GetRepoEditor("myproject"); got != "idea" {
+		t.Errorf("GetRepoEditor(myproject) after Save() = %q, want %q", got, "idea")
+	}
+}
+
+func TestApplyRepoFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	userConfig := "worktree:\n  directory_format: sibling\n  base_dir: /user/trees\neditor:\n  command: vim\n"
+	if err := os.WriteFile(configPath, []byte(userConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	repoPath := filepath.Join(dir, "wt.yaml")
+	repoConfig := "worktree:\n  base_dir: /fast/trees\neditor:\n  command: idea\n"
+	if err := os.WriteFile(repoPath, []byte(repoConfig), 0644); err != nil {
+		t.Fatalf("Failed to write repository config file: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if err := cfg.ApplyRepoFile(repoPath); err != nil {
+		t.Fatalf("ApplyRepoFile() returned error: %v", err)
+	}
+
+	if got := cfg.GetBaseDir(); got != "/fast/trees" {
+		t.Errorf("GetBaseDir() = %q, want %q", got, "/fast/trees")
+	}
+	if got := cfg.GetEditorCommand(); got != "idea" {
+		t.Errorf("GetEditorCommand() = %q, want %q", got, "idea")
+	}
+	if got := cfg.GetDirectoryFormat(); got != "sibling" {
+		t.Errorf("GetDirectoryFormat() = %q, want the user file's %q", got, "sibling")
+	}
+	for key, want := range map[string]config.Source{
+		"worktree.base_dir":         config.SourceRepo,
+		"editor.command":            config.SourceRepo,
+		"worktree.directory_format": config.SourceFile,
+	} {
+		if got := cfg.Source(key); got != want {
+			t.Errorf("Source(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got := cfg.RepoPath(); got != repoPath {
+		t.Errorf("RepoPath() = %q, want %q", got, repoPath)
+	}
+	if err := cfg.Save(); err == nil {
+		t.Errorf("Save() error = nil, want error for a configuration with the repository file applied")
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if err := cfg.ApplyRepoFile(filepath.Join(dir, "missing.yaml")); err != nil {
+			t.Fatalf("ApplyRepoFile() returned error: %v", err)
+		}
+		if got := cfg.GetBaseDir(); got != "/user/trees" {
+			t.Errorf("GetBaseDir() = %q, want %q", got, "/user/trees")
+		}
+		if got := cfg.RepoPath(); got != "" {
+			t.Errorf("RepoPath() = %q, want empty", got)
+		}
+	})
+
+	for name, content := range map[string]string{
+		"relative base_dir": "worktree:\n  base_dir: trees\n",
+		"invalid yaml":      "worktree: [\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wt.yaml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write repository config file: %v", err)
+			}
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			if err := cfg.ApplyRepoFile(path); err == nil {
+				t.Errorf("ApplyRepoFile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestBaseDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("WT_TEST_DISK", "/mnt/fast")
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "/srv/trees", want: "/srv/trees"},
+		{value: "~", want: home},
+		{value: "~/worktrees", want: filepath.Join(home, "worktrees")},
+		{value: "$WT_TEST_DISK/trees", want: "/mnt/fast/trees"},
+		{value: "${WT_TEST_DISK}/trees", want: "/mnt/fast/trees"},
+		{value: "trees", wantErr: true},
+		{value: "~other/trees", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			err = cfg.SetBaseDir(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SetBaseDir(%q) error = nil, want error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetBaseDir(%q) returned error: %v", tt.value, err)
+			}
+			if got := cfg.GetBaseDir(); got != tt.want {
+				t.Errorf("GetBaseDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetContainerDirMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantPerm os.FileMode
+		wantErr  bool
+	}{
+		{value: "", wantPerm: 0},
+		{value: "0750", wantPerm: 0o750},
+		{value: "700", wantPerm: 0o700},
+		{value: "0o755", wantPerm: 0o755},
+		{value: "0644", wantErr: true}, // The owner can't search the directory
+		{value: "01777", wantErr: true},
+		{value: "rwxr-x---", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			err = cfg.SetContainerDirMode(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SetContainerDirMode(%q) error = nil, want error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetContainerDirMode(%q) returned error: %v", tt.value, err)
+			}
+			if got := cfg.ContainerDirPerm(); got != tt.wantPerm {
+				t.Errorf("ContainerDirPerm() = %o, want %o", got, tt.wantPerm)
+			}
+		})
+	}
+}
+
+func TestSetProtectedBranches(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.GetProtectedBranches(); !reflect.DeepEqual(got, []string{"main", "master", "develop", "{default}"}) {
+		t.Errorf("GetProtectedBranches() = %q, want the defaults", got)
+	}
+
+	if err := cfg.SetProtectedBranches("main release/*"); err != nil {
+		t.Fatalf("SetProtectedBranches() returned error: %v", err)
+	}
+	if got := cfg.GetProtectedBranches(); !reflect.DeepEqual(got, []string{"main", "release/*"}) {
+		t.Errorf("GetProtectedBranches() = %q, want [main release/*]", got)
+	}
+
+	if err := cfg.SetProtectedBranches("main release/[0-9"); err == nil {
+		t.Error("SetProtectedBranches() with an invalid glob should return an error")
+	}
+}
+
+func TestLoadRepoFileSavesOnlySetKeys(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "wt.yaml")
+	if err := config.WriteRepoTemplate(repoPath); err != nil {
+		t.Fatalf("WriteRepoTemplate() returned error: %v", err)
+	}
+
+	cfg, err := config.LoadRepoFile(repoPath)
+	if err != nil {
+		t.Fatalf("LoadRepoFile() returned error: %v", err)
+	}
+	if err := cfg.SetBaseDir("/fast/trees"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.MarkSet("worktree.base_dir")
+	cfg.MarkSet("worktree.remote_check") // Default value, still written
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"base_dir: /fast/trees", "remote_check: true"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("repository config file should contain %q, got:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "directory_format") || strings.Contains(string(data), "selector") {
+		t.Errorf("repository config file should contain only the set keys, got:\n%s", data)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lookupNode finds a dotted key in a YAML document node
+// Returns the key node and value node, or nil if the key is not present
+func lookupNode(doc *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if doc == nil || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	node := doc.Content[0]
+	var keyNode *yaml.Node
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				keyNode = node.Content[i]
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, nil
+		}
+		node = next
+	}
+
+	return keyNode, node
+}
+
+// nodeValue returns the string form of a value node: the scalar value, or the items of a
+// sequence separated by spaces (as used by list settings)
+func nodeValue(node *yaml.Node) (string, bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, true
+	case yaml.SequenceNode:
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			items[i] = item.Value
+		}
+		return strings.Join(items, " "), true
+	}
+	return "", false
+}
+
+// setNodeValue sets a dotted key to a scalar value with the given tag ("!!str" if empty),
+// creating intermediate mappings as needed
+// With tag "!!seq", value is split on whitespace into a sequence of strings.
+// Existing nodes are updated in place so that their comments and position are preserved.
+func setNodeValue(doc *yaml.Node, key, value, tag string) {
+	if tag == "" {
+		tag = "!!str"
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				next = node.Content[j+1]
+				break
+			}
+		}
+
+		last := i == len(parts)-1
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if last {
+				next = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+		}
+
+		if last {
+			if tag == "!!seq" {
+				next.Kind = yaml.SequenceNode
+				next.Tag = tag
+				next.Style = yaml.FlowStyle
+				next.Value = ""
+				next.Content = nil
+				for _, item := range strings.Fields(value) {
+					next.Content = append(next.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+				}
+				return
+			}
+			next.Kind = yaml.ScalarNode
+			next.Tag = tag
+			next.Style = 0
+			next.Value = value
+			next.Content = nil
+			return
+		}
+
+		if next.Kind != yaml.MappingNode {
+			// Replace a non-mapping value (e.g. "worktree: null") with a mapping
+			next.Kind = yaml.MappingNode
+			next.Tag = "!!map"
+			next.Value = ""
+			next.Content = nil
+		}
+		node = next
+	}
+}
+
+// deleteNode removes a dotted key from a YAML document node
+// Returns true if the key was present. A section left empty is removed as well.
+func deleteNode(doc *yaml.Node, key string) bool {
+	parts := strings.Split(key, ".")
+	parent := strings.Join(parts[:len(parts)-1], ".")
+
+	var mapping *yaml.Node
+	if parent == "" {
+		if doc == nil || len(doc.Content) == 0 {
+			return false
+		}
+		mapping = doc.Content[0]
+	} else {
+		_, mapping = lookupNode(doc, parent)
+	}
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return false
+	}
+
+	last := parts[len(parts)-1]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == last {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			if len(mapping.Content) == 0 && parent != "" {
+				deleteNode(doc, parent) // Don't leave an empty section behind
+			}
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/toritori0318/git-wt/internal/selectx"
+	"gopkg.in/yaml.v3"
+)
+
+// StrictEnv is the environment variable that turns unknown-key warnings into errors
+const StrictEnv = "WT_STRICT_CONFIG"
+
+var (
+	// Strict turns unknown configuration keys into errors (set by --strict-config)
+	Strict = false
+
+	// WarningOutput is where unknown-key warnings are written
+	WarningOutput io.Writer = os.Stderr
+
+	// warned tracks warnings already printed so repeated loads don't repeat them
+	warned sync.Map
+)
+
+// isStrict reports whether unknown keys should be treated as errors
+func isStrict() bool {
+	if Strict {
+		return true
+	}
+	strict, err := strconv.ParseBool(os.Getenv(StrictEnv))
+	return err == nil && strict
+}
+
+// findUnknownKeys returns the dotted paths of keys in doc that wt doesn't recognize
+func findUnknownKeys(doc *yaml.Node) []string {
+	if doc == nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	sections := make(map[string]bool)
+	for _, key := range KnownKeys() {
+		known[key] = true
+		parts := strings.Split(key, ".")
+		for i := 1; i < len(parts); i++ {
+			sections[strings.Join(parts[:i], ".")] = true
+		}
+	}
+
+	for _, section := range mapSections {
+		known[section] = true
+	}
+
+	var unknown []string
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			path := node.Content[i].Value
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			switch {
+			case known[path]:
+			case sections[path]:
+				walk(node.Content[i+1], path)
+			default:
+				unknown = append(unknown, path)
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+
+	return unknown
+}
+
+// checkUnknownKeys warns about (or, in strict mode, rejects) unrecognized keys
+func checkUnknownKeys(path string, doc *yaml.Node) error {
+	for _, key := range findUnknownKeys(doc) {
+		msg := fmt.Sprintf("unknown config key %q", key)
+		if keyNode, _ := lookupNode(doc, key); keyNode != nil {
+			msg = fmt.Sprintf("%s at line %d", msg, keyNode.Line)
+		}
+		if suggestion := suggestKey(key); suggestion != "" {
+			msg = fmt.Sprintf("%s (did you mean %q?)", msg, suggestion)
+		}
+
+		if isStrict() {
+			return &ValidationError{Key: key, Msg: msg}
+		}
+
+		warning := fmt.Sprintf("Warning: %s: %s", path, msg)
+		if _, seen := warned.LoadOrStore(warning, true); !seen {
+			fmt.Fprintln(WarningOutput, warning)
+		}
+	}
+	return nil
+}
+
+// suggestKey returns the known key closest to key, or "" if none is close enough
+func suggestKey(key string) string {
+	best := ""
+	bestDist := -1
+	for _, known := range KnownKeys() {
+		d := selectx.EditDistance(key, known)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+
+	// Only suggest plausible typos, not arbitrary keys
+	if bestDist < 0 || bestDist > 3 {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is a named group of repositories that wt workspace creates and removes worktrees in together
+type Workspace struct {
+	Name  string
+	Repos []string // Repository roots, in the order of the workspaces file
+}
+
+// GetWorkspacesPath returns the workspaces file path: $XDG_CONFIG_HOME/wt/workspaces.yaml
+// It sits next to the default configuration file, also when --config points elsewhere.
+func GetWorkspacesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspaces.yaml"), nil
+}
+
+// LoadWorkspaces reads the workspaces file at path, sorted by name
+// The file maps each workspace name to a list of repository paths, which must be absolute
+// (they may start with ~ or use $VARIABLES). A missing file has no workspaces.
+func LoadWorkspaces(path string) ([]Workspace, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspaces file: %w", err)
+	}
+
+	var repos map[string][]string
+	if err := yaml.Unmarshal(data, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces file %s: %w", path, err)
+	}
+
+	workspaces := make([]Workspace, 0, len(repos))
+	for name, paths := range repos {
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("invalid workspaces file %s: workspace %q lists no repositories", path, name)
+		}
+		ws := Workspace{Name: name}
+		for _, p := range paths {
+			expanded := ExpandPath(p)
+			if !filepath.IsAbs(expanded) {
+				return nil, fmt.Errorf("invalid workspaces file %s: repository %q of workspace %q must be an absolute path (may start with ~)", path, p, name)
+			}
+			ws.Repos = append(ws.Repos, filepath.Clean(expanded))
+		}
+		workspaces = append(workspaces, ws)
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+	return workspaces, nil
+}
@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/config"
+)
+
+func TestLoadWorkspaces(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(t.TempDir(), "workspaces.yaml")
+	content := "web:\n  - ~/src/web\nservices:\n  - /src/api\n  - /src/billing/\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := config.LoadWorkspaces(path)
+	if err != nil {
+		t.Fatalf("LoadWorkspaces() returned error: %v", err)
+	}
+	want := []config.Workspace{
+		{Name: "services", Repos: []string{"/src/api", "/src/billing"}},
+		{Name: "web", Repos: []string{filepath.Join(home, "src", "web")}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadWorkspaces() = %+v, want %+v", got, want)
+	}
+
+	if got, err := config.LoadWorkspaces(filepath.Join(t.TempDir(), "missing.yaml")); err != nil || got != nil {
+		t.Errorf("LoadWorkspaces() of a missing file = %+v, %v, want no workspaces", got, err)
+	}
+}
+
+func TestLoadWorkspacesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "relative path", content: "web:\n  - src/web\n", wantErr: "must be an absolute path"},
+		{name: "no repositories", content: "web: []\n", wantErr: "lists no repositories"},
+		{name: "not a list", content: "web: /src/web\n", wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "workspaces.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := config.LoadWorkspaces(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadWorkspaces() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
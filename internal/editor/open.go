@@ -4,57 +4,268 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 )
 
+// waitArgs are the arguments that make a GUI editor block until the file is closed
+var waitArgs = map[string][]string{
+	"code":   {"--wait"},
+	"idea":   {"--wait"},
+	"idea64": {"--wait"},
+	"subl":   {"--wait"},
+	"open":   {"-W"},
+}
+
+// launchGrace is how long a detached editor is watched for an immediate failure
+var launchGrace = 500 * time.Millisecond
+
+// Platform hooks, replaced in tests
+var (
+	goos     = runtime.GOOS
+	lookPath = exec.LookPath
+)
+
+// Command is an editor command line before the executable is looked up
+type Command struct {
+	Line string   // Command line split into words like a shell, e.g. `emacsclient -n`
+	Args []string // Arguments appended after the words of Line
+}
+
+// Editor is a resolved editor command
+type Editor struct {
+	Path string   // Absolute path of the executable
+	Args []string // Arguments passed before the path to open
+}
+
+// String returns the command line of the editor, for display
+func (e *Editor) String() string {
+	return strings.Join(append([]string{e.Path}, e.Args...), " ")
+}
+
 // FindEditor finds the best available editor
-func FindEditor(preferredEditor string) (string, error) {
-	// Search for editor in priority order
-	candidates := []string{
-		preferredEditor,
-		os.Getenv("WT_EDITOR"),
-		os.Getenv("VISUAL"),
-		os.Getenv("EDITOR"),
-		"code",   // VS Code
-		"idea",   // IntelliJ IDEA
-		"subl",   // Sublime Text
-		"vim",    // Vim
-		"vi",     // Vi
-	}
-
-	// Add platform-specific fallbacks: "open" for macOS, "xdg-open" for Linux
-	if runtime.GOOS == "darwin" {
-		candidates = append(candidates, "open")
-	} else if runtime.GOOS == "linux" {
-		candidates = append(candidates, "xdg-open")
-	}
-
-	for _, editor := range candidates {
-		if editor == "" {
+// preferred commands (e.g. the --editor flag and the configured editor) are tried in order
+// before the WT_EDITOR, VISUAL and EDITOR environment variables; empty ones are skipped.
+func FindEditor(preferred ...Command) (*Editor, error) {
+	for _, candidate := range candidates(preferred) {
+		if strings.TrimSpace(candidate.Line) == "" {
+			continue
+		}
+
+		// A command that exists as written (e.g. an unquoted Windows path with spaces) isn't split
+		if path, err := lookPath(candidate.Line); err == nil {
+			return &Editor{Path: path, Args: append([]string{}, candidate.Args...)}, nil
+		}
+
+		words, err := SplitCommand(candidate.Line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid editor command %q: %w", candidate.Line, err)
+		}
+		if len(words) == 0 {
 			continue
 		}
 
 		// Check if command exists
-		if path, err := exec.LookPath(editor); err == nil {
-			return path, nil
+		if path, err := lookPath(words[0]); err == nil {
+			args := append(words[1:], candidate.Args...)
+			return &Editor{Path: path, Args: args}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no editor found. Please set WT_EDITOR, VISUAL, or EDITOR environment variable")
+}
+
+// candidates returns the editor commands to try, in priority order
+func candidates(preferred []Command) []Command {
+	list := append([]Command{}, preferred...)
+	list = append(list,
+		Command{Line: os.Getenv("WT_EDITOR")},
+		Command{Line: os.Getenv("VISUAL")},
+		Command{Line: os.Getenv("EDITOR")},
+		Command{Line: "code"}, // VS Code
+		Command{Line: "idea"}, // IntelliJ IDEA
+		Command{Line: "subl"}, // Sublime Text
+	)
+
+	// Editors installed without their launcher on PATH
+	if goos == "windows" {
+		list = append(list, windowsShims()...)
+	}
+
+	list = append(list,
+		Command{Line: "vim"}, // Vim
+		Command{Line: "vi"},  // Vi
+	)
+
+	// Add platform-specific fallbacks: "open" for macOS, "xdg-open" for Linux and the
+	// file association (Explorer for directories) on Windows
+	switch goos {
+	case "darwin":
+		list = append(list, Command{Line: "open"})
+	case "linux":
+		list = append(list, Command{Line: "xdg-open"})
+	case "windows":
+		list = append(list, Command{Line: `cmd /c start ""`})
+	}
+
+	return list
+}
+
+// windowsShims returns the launchers of common editors at their default install locations
+func windowsShims() []Command {
+	var shims []Command
+	addShim := func(envDir string, elem ...string) {
+		if dir := os.Getenv(envDir); dir != "" {
+			path := filepath.Join(append([]string{dir}, elem...)...)
+			shims = append(shims, Command{Line: quoteWindowsPath(path)})
+		}
+	}
+
+	addShim("LOCALAPPDATA", "Programs", "Microsoft VS Code", "bin", "code.cmd") // VS Code (user install)
+	addShim("ProgramFiles", "Microsoft VS Code", "bin", "code.cmd")             // VS Code (system install)
+	shims = append(shims, Command{Line: "idea64"})                              // IntelliJ IDEA bin directory on PATH
+	addShim("LOCALAPPDATA", "JetBrains", "Toolbox", "scripts", "idea.cmd")      // IntelliJ IDEA (Toolbox)
+	return shims
+}
+
+// quoteWindowsPath quotes a Windows path as a single word for SplitCommand
+// Windows paths can't contain double quotes, and backslashes are ordinary characters there.
+func quoteWindowsPath(path string) string {
+	return `"` + path + `"`
+}
+
+// SplitCommand splits a command line into words like a POSIX shell
+// Words are separated by whitespace. Single quotes keep everything literally; inside double
+// quotes a backslash only escapes ", \, $ and `; elsewhere it escapes any character.
+// Variables and other shell expansions are not performed. On Windows, a backslash is an
+// ordinary character so that paths such as C:\tools\vim.exe can be written unquoted.
+func SplitCommand(line string) ([]string, error) {
+	escapes := goos != "windows"
+
+	var words []string
+	var word strings.Builder
+	inWord := false // Distinguishes an empty quoted word ('') from no word
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '\'':
+			inWord = true
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inWord = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if escapes && runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+		case r == '\\' && escapes:
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			inWord = true
+			i++
+			word.WriteRune(runes[i])
+		default:
+			inWord = true
+			word.WriteRune(r)
 		}
 	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
 
-	return "", fmt.Errorf("no editor found. Please set WT_EDITOR, VISUAL, or EDITOR environment variable")
+// indexRune returns the index of the first r in runes at or after start, or -1
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// OpenOptions controls how an editor is launched
+type OpenOptions struct {
+	GUIEditors []string // Executable names of GUI editors, which are started detached
+	Wait       bool     // Wait until a GUI editor closes the file
+}
+
+// name returns the executable name of the editor, without directory and ".exe"
+func (e *Editor) name() string {
+	return executableName(e.Path)
+}
+
+// executableName returns the base name of a command without its Windows extension
+func executableName(command string) string {
+	base := filepath.Base(command)
+	if goos == "windows" {
+		base = strings.ToLower(base)
+		for _, ext := range []string{".exe", ".cmd", ".bat"} {
+			base = strings.TrimSuffix(base, ext)
+		}
+	}
+	return base
+}
+
+// IsGUI reports whether the editor is one of guiEditors (matched by executable name)
+func (e *Editor) IsGUI(guiEditors []string) bool {
+	for _, gui := range guiEditors {
+		if executableName(gui) == e.name() {
+			return true
+		}
+	}
+	return false
 }
 
 // Open opens the specified path with an editor
-func Open(path, editor string) error {
-	editorPath, err := FindEditor(editor)
+func Open(path, editor string, opts OpenOptions) error {
+	e, err := FindEditor(Command{Line: editor})
 	if err != nil {
 		return err
 	}
-	return OpenWithPath(path, editorPath)
+	return OpenWithEditor(path, e, opts)
 }
 
-// OpenWithPath opens the specified path with a resolved editor path
-func OpenWithPath(path, editorPath string) error {
-	cmd := exec.Command(editorPath, path)
+// OpenWithEditor opens the specified path with a resolved editor
+// Terminal editors run attached to the terminal until they exit. GUI editors are started detached
+// with their standard streams released, unless opts.Wait is set; then they run attached with
+// their wait argument (e.g. "code --wait") where it is known.
+func OpenWithEditor(path string, e *Editor, opts OpenOptions) error {
+	args := append([]string{}, e.Args...)
+	gui := e.IsGUI(opts.GUIEditors)
+	if gui && opts.Wait {
+		args = addWaitArgs(args, waitArgs[e.name()])
+	}
+	args = append(args, path)
+
+	cmd := newCommand(e.Path, args)
+	if gui && !opts.Wait {
+		return startDetached(cmd)
+	}
+
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -65,3 +276,92 @@ func OpenWithPath(path, editorPath string) error {
 
 	return nil
 }
+
+// newCommand returns the command running path with args
+// On Windows, batch files (such as the code.cmd shim) and cmd.exe parse their command line
+// themselves, so it is quoted for cmd.exe instead of with the usual C runtime rules.
+func newCommand(path string, args []string) *exec.Cmd {
+	if goos != "windows" {
+		return exec.Command(path, args...)
+	}
+
+	words := append([]string{path}, args...)
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".cmd" || ext == ".bat":
+		comspec := os.Getenv("ComSpec")
+		if comspec == "" {
+			comspec = "cmd.exe"
+		}
+		cmd := exec.Command(comspec)
+		// /s: strip exactly the outer quotes, whatever the quoting inside
+		setCmdLine(cmd, cmdQuote(comspec)+` /d /s /c "`+cmdJoin(words)+`"`)
+		return cmd
+	case executableName(path) == "cmd":
+		cmd := exec.Command(path)
+		setCmdLine(cmd, cmdJoin(words))
+		return cmd
+	}
+	return exec.Command(path, args...)
+}
+
+// cmdJoin quotes args for cmd.exe and joins them into a command line
+func cmdJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = cmdQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// cmdQuote quotes arg for cmd.exe if it is empty or contains spaces or characters special to cmd
+// "%" can't be escaped inside quotes, so it is written as an escaped ^% between quoted parts.
+func cmdQuote(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(arg, " \t&|<>^(),;=%!\"") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `"`, `""`)
+	arg = strings.ReplaceAll(arg, "%", `"^%"`)
+	return `"` + arg + `"`
+}
+
+// addWaitArgs appends the wait arguments to args unless they are already given
+func addWaitArgs(args, wait []string) []string {
+	for _, w := range wait {
+		found := false
+		for _, arg := range args {
+			if arg == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			args = append(args, w)
+		}
+	}
+	return args
+}
+
+// startDetached starts cmd in its own session without standard streams
+// The editor is watched for launchGrace so that a launcher failing right away is reported; an
+// editor still running after that is left running when wt exits.
+func startDetached(cmd *exec.Cmd) error {
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to launch editor: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to launch editor: %w", err)
+		}
+	case <-time.After(launchGrace):
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package editor_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/editor"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "command without arguments", line: "vim", want: []string{"vim"}},
+		{name: "empty", line: "  ", want: nil},
+		{name: "arguments", line: "emacsclient -n  -a ''", want: []string{"emacsclient", "-n", "-a", ""}},
+		{name: "single quotes", line: `code '--user-data-dir=/tmp/my dir'`, want: []string{"code", "--user-data-dir=/tmp/my dir"}},
+		{name: "double quotes", line: `"/Applications/My Editor/bin/edit" --wait`, want: []string{"/Applications/My Editor/bin/edit", "--wait"}},
+		{name: "escapes in double quotes", line: `sh -c "echo \"\$1\" \n"`, want: []string{"sh", "-c", `echo "$1" \n`}},
+		{name: "backslash outside quotes", line: `my\ editor -x`, want: []string{"my editor", "-x"}},
+		{name: "adjacent quoted parts", line: `--opt="a b"'c'd`, want: []string{"--opt=a bcd"}},
+		{name: "unterminated single quote", line: "code 'x", wantErr: true},
+		{name: "unterminated double quote", line: `code "x`, wantErr: true},
+		{name: "trailing backslash", line: `code \`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := editor.SplitCommand(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SplitCommand(%q) = %q, want error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitCommand(%q) returned error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitCommand(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeEditors puts executables with the given names on an otherwise empty PATH
+func fakeEditors(t *testing.T, names ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editors require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("Failed to write fake editor: %v", err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("WT_EDITOR", "")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	return dir
+}
+
+func TestFindEditor(t *testing.T) {
+	dir := fakeEditors(t, "emacsclient", "vi")
+
+	tests := []struct {
+		name      string
+		preferred []editor.Command
+		env       string
+		want      *editor.Editor
+	}{
+		{
+			name:      "command with quoted arguments",
+			preferred: []editor.Command{{Line: `emacsclient -n --eval '(find-file "x")'`}},
+			want:      &editor.Editor{Path: filepath.Join(dir, "emacsclient"), Args: []string{"-n", "--eval", `(find-file "x")`}},
+		},
+		{
+			name:      "command without arguments",
+			preferred: []editor.Command{{Line: "emacsclient"}},
+			want:      &editor.Editor{Path: filepath.Join(dir, "emacsclient"), Args: []string{}},
+		},
+		{
+			name:      "configured arguments follow the command line",
+			preferred: []editor.Command{{}, {Line: "emacsclient -n", Args: []string{"--alternate-editor="}}},
+			want:      &editor.Editor{Path: filepath.Join(dir, "emacsclient"), Args: []string{"-n", "--alternate-editor="}},
+		},
+		{
+			name:      "unavailable preferred command falls through",
+			preferred: []editor.Command{{Line: "code --new-window"}},
+			env:       "emacsclient -c",
+			want:      &editor.Editor{Path: filepath.Join(dir, "emacsclient"), Args: []string{"-c"}},
+		},
+		{
+			name: "fallback",
+			want: &editor.Editor{Path: filepath.Join(dir, "vi"), Args: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WT_EDITOR", tt.env)
+			got, err := editor.FindEditor(tt.preferred...)
+			if err != nil {
+				t.Fatalf("FindEditor() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindEditor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindEditorInvalidCommand(t *testing.T) {
+	fakeEditors(t, "vi")
+
+	if got, err := editor.FindEditor(editor.Command{Line: `code "--new-window`}); err == nil {
+		t.Errorf("FindEditor() = %+v, want error for an unterminated quote", got)
+	}
+}
+
+func TestOpenWithEditorPassesArguments(t *testing.T) {
+	dir := fakeEditors(t)
+	out := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + out + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "myeditor"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake editor: %v", err)
+	}
+
+	e, err := editor.FindEditor(editor.Command{Line: `myeditor --title "my worktree"`})
+	if err != nil {
+		t.Fatalf("FindEditor() returned error: %v", err)
+	}
+	if err := editor.OpenWithEditor("/work/my project", e, editor.OpenOptions{}); err != nil {
+		t.Fatalf("OpenWithEditor() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read editor arguments: %v", err)
+	}
+	if want := "--title\nmy worktree\n/work/my project\n"; string(data) != want {
+		t.Errorf("editor arguments = %q, want %q", data, want)
+	}
+}
+
+func TestIsGUI(t *testing.T) {
+	guiEditors := []string{"code", "/usr/local/bin/subl"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/usr/bin/code", want: true},
+		{path: "/opt/bin/subl", want: true},
+		{path: "/usr/bin/vim", want: false},
+		{path: "/usr/bin/code-server", want: false},
+	}
+
+	for _, tt := range tests {
+		e := &editor.Editor{Path: tt.path}
+		if got := e.IsGUI(guiEditors); got != tt.want {
+			t.Errorf("IsGUI() for %s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOpenWithEditorGUI(t *testing.T) {
+	dir := fakeEditors(t)
+	out := filepath.Join(dir, "args")
+
+	tests := []struct {
+		name     string
+		script   string
+		args     []string
+		wait     bool
+		wantArgs string
+		wantErr  bool
+	}{
+		{
+			name:     "started detached",
+			script:   "printf '%s\\n' \"$@\" > " + out,
+			wantArgs: "/work/feature\n",
+		},
+		{
+			name:     "wait adds the wait argument",
+			script:   "printf '%s\\n' \"$@\" > " + out,
+			args:     []string{"-n"},
+			wait:     true,
+			wantArgs: "-n\n--wait\n/work/feature\n",
+		},
+		{
+			name:     "wait argument isn't repeated",
+			script:   "printf '%s\\n' \"$@\" > " + out,
+			args:     []string{"--wait"},
+			wait:     true,
+			wantArgs: "--wait\n/work/feature\n",
+		},
+		{
+			name:    "launch failure is reported",
+			script:  "exit 3",
+			wantErr: true,
+		},
+		{
+			name:   "editor still running is left running",
+			script: "/bin/sleep 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(out)
+			path := filepath.Join(dir, "code")
+			if err := os.WriteFile(path, []byte("#!/bin/sh\n"+tt.script+"\n"), 0755); err != nil {
+				t.Fatalf("Failed to write fake editor: %v", err)
+			}
+
+			e := &editor.Editor{Path: path, Args: tt.args}
+			err := editor.OpenWithEditor("/work/feature", e, editor.OpenOptions{GUIEditors: []string{"code"}, Wait: tt.wait})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+					t.Errorf("OpenWithEditor() error = %v, want exit status 3", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenWithEditor() returned error: %v", err)
+			}
+			if tt.wantArgs == "" {
+				return
+			}
+
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("Failed to read editor arguments: %v", err)
+			}
+			if string(data) != tt.wantArgs {
+				t.Errorf("editor arguments = %q, want %q", data, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOpenWithEditorTerminalFailure(t *testing.T) {
+	dir := fakeEditors(t)
+	path := filepath.Join(dir, "vim")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake editor: %v", err)
+	}
+
+	e := &editor.Editor{Path: path}
+	if err := editor.OpenWithEditor("/work/feature", e, editor.OpenOptions{GUIEditors: []string{"code"}}); err == nil {
+		t.Error("OpenWithEditor() should report the failure of a terminal editor")
+	}
+}
+
+func TestReveal(t *testing.T) {
+	dir := fakeEditors(t)
+	out := filepath.Join(dir, "args")
+	opener := filepath.Join(dir, "xdg-open")
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr bool
+	}{
+		{name: "opens the path", script: "printf '%s\\n' \"$@\" > " + out},
+		{name: "failure is reported", script: "exit 4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(opener, []byte("#!/bin/sh\n"+tt.script+"\n"), 0755); err != nil {
+				t.Fatalf("Failed to write fake opener: %v", err)
+			}
+
+			err := editor.Reveal("/work/my feature", &editor.Editor{Path: opener})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exit status 4") {
+					t.Errorf("Reveal() error = %v, want exit status 4", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reveal() returned error: %v", err)
+			}
+
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("Failed to read opener arguments: %v", err)
+			}
+			if want := "/work/my feature\n"; string(data) != want {
+				t.Errorf("opener arguments = %q, want %q", data, want)
+			}
+		})
+	}
+}
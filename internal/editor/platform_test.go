@@ -0,0 +1,209 @@
+package editor
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakePlatform makes FindEditor behave as on goosName, where only the commands in installed exist
+func fakePlatform(t *testing.T, goosName string, installed ...string) {
+	t.Helper()
+	origGOOS, origLookPath := goos, lookPath
+	t.Cleanup(func() { goos, lookPath = origGOOS, origLookPath })
+
+	goos = goosName
+	lookPath = func(file string) (string, error) {
+		for _, name := range installed {
+			if file == name {
+				return `C:\bin\` + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+
+	for _, env := range []string{"WT_EDITOR", "VISUAL", "EDITOR"} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{goos: "darwin", want: []string{"code", "idea", "subl", "vim", "vi", "open"}},
+		{goos: "linux", want: []string{"code", "idea", "subl", "vim", "vi", "xdg-open"}},
+		{
+			goos: "windows",
+			want: []string{
+				"code", "idea", "subl",
+				`"` + filepath.Join(`C:\Users\me\AppData\Local`, "Programs", "Microsoft VS Code", "bin", "code.cmd") + `"`,
+				`"` + filepath.Join(`C:\Program Files`, "Microsoft VS Code", "bin", "code.cmd") + `"`,
+				"idea64",
+				`"` + filepath.Join(`C:\Users\me\AppData\Local`, "JetBrains", "Toolbox", "scripts", "idea.cmd") + `"`,
+				"vim", "vi", `cmd /c start ""`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			fakePlatform(t, tt.goos)
+			t.Setenv("LOCALAPPDATA", `C:\Users\me\AppData\Local`)
+			t.Setenv("ProgramFiles", `C:\Program Files`)
+
+			var got []string
+			for _, c := range candidates([]Command{{Line: "nano"}}) {
+				if c.Line != "" {
+					got = append(got, c.Line)
+				}
+			}
+			want := append([]string{"nano"}, tt.want...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("candidates() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCandidatesWindowsWithoutInstallDirs(t *testing.T) {
+	fakePlatform(t, "windows")
+	t.Setenv("LOCALAPPDATA", "")
+	t.Setenv("ProgramFiles", "")
+
+	for _, c := range candidates(nil) {
+		if filepath.Ext(c.Line) == `.cmd"` {
+			t.Errorf("candidates() contains %q without an install directory", c.Line)
+		}
+	}
+}
+
+func TestFindEditorWindows(t *testing.T) {
+	shim := filepath.Join(`C:\Users\me\AppData\Local`, "Programs", "Microsoft VS Code", "bin", "code.cmd")
+
+	tests := []struct {
+		name      string
+		installed []string
+		env       string
+		want      *Editor
+	}{
+		{
+			name:      "VS Code shim outside PATH",
+			installed: []string{shim, "vim"},
+			want:      &Editor{Path: `C:\bin\` + shim, Args: []string{}},
+		},
+		{
+			name:      "IntelliJ IDEA launcher",
+			installed: []string{"idea64", "vim"},
+			want:      &Editor{Path: `C:\bin\idea64`, Args: []string{}},
+		},
+		{
+			name:      "unquoted path with spaces",
+			installed: []string{`C:\Program Files\Notepad++\notepad++.exe`},
+			env:       `C:\Program Files\Notepad++\notepad++.exe`,
+			want:      &Editor{Path: `C:\bin\C:\Program Files\Notepad++\notepad++.exe`, Args: []string{}},
+		},
+		{
+			name:      "backslashes are kept",
+			installed: []string{`C:\tools\vim.exe`},
+			env:       `C:\tools\vim.exe -p`,
+			want:      &Editor{Path: `C:\bin\C:\tools\vim.exe`, Args: []string{"-p"}},
+		},
+		{
+			name:      "falls back to start",
+			installed: []string{"cmd"},
+			want:      &Editor{Path: `C:\bin\cmd`, Args: []string{"/c", "start", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakePlatform(t, "windows", tt.installed...)
+			t.Setenv("LOCALAPPDATA", `C:\Users\me\AppData\Local`)
+			t.Setenv("ProgramFiles", "")
+			t.Setenv("WT_EDITOR", tt.env)
+
+			got, err := FindEditor()
+			if err != nil {
+				t.Fatalf("FindEditor() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindEditor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutableNameWindows(t *testing.T) {
+	fakePlatform(t, "windows")
+
+	for _, path := range []string{`Code.cmd`, `code.exe`, `code.BAT`, `code`} {
+		if got := executableName(path); got != "code" {
+			t.Errorf("executableName(%q) = %q, want %q", path, got, "code")
+		}
+	}
+}
+
+func TestCmdJoin(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "plain", args: []string{"code", `C:\work\feature`}, want: `code C:\work\feature`},
+		{name: "empty argument", args: []string{"cmd", "/c", "start", "", `C:\work`}, want: `cmd /c start "" C:\work`},
+		{name: "spaces", args: []string{`C:\Program Files\code.cmd`, `C:\my work`}, want: `"C:\Program Files\code.cmd" "C:\my work"`},
+		{name: "cmd metacharacters", args: []string{`C:\R&D\a(1)^b`}, want: `"C:\R&D\a(1)^b"`},
+		{name: "percent", args: []string{`C:\100%PATH%`}, want: `"C:\100"^%"PATH"^%""`},
+		{name: "trailing backslash", args: []string{`C:\my dir\`}, want: `"C:\my dir\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cmdJoin(tt.args); got != tt.want {
+				t.Errorf("cmdJoin(%q) = %s, want %s", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFileManager(t *testing.T) {
+	tests := []struct {
+		goos    string
+		want    string
+		wantErr bool
+	}{
+		{goos: "darwin", want: `C:\bin\open`},
+		{goos: "linux", want: `C:\bin\xdg-open`},
+		{goos: "windows", want: `C:\bin\explorer`},
+		{goos: "plan9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			fakePlatform(t, tt.goos, "open", "xdg-open", "explorer")
+			got, err := FindFileManager()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("FindFileManager() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindFileManager() returned error: %v", err)
+			}
+			if got.Path != tt.want {
+				t.Errorf("FindFileManager() = %s, want %s", got.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFileManagerNotInstalled(t *testing.T) {
+	fakePlatform(t, "linux")
+	if got, err := FindFileManager(); err == nil {
+		t.Errorf("FindFileManager() = %+v, want error without xdg-open", got)
+	}
+}
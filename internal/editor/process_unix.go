@@ -0,0 +1,16 @@
+//go:build !windows
+
+package editor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in a new session, so that closing the terminal doesn't close the editor
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// setCmdLine is only needed on Windows, where the command line is passed as a single string
+func setCmdLine(cmd *exec.Cmd, line string) {}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package editor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Process creation flags of the editor (see CreateProcess)
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detach makes cmd run without the console of wt, so that closing it doesn't close the editor
+func detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= createNewProcessGroup | detachedProcess
+}
+
+// setCmdLine passes line to the process as is, instead of quoting the arguments of cmd
+func setCmdLine(cmd *exec.Cmd, line string) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = line
+}
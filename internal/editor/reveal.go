@@ -0,0 +1,47 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// fileManagers are the commands that show a directory in the file manager of each platform
+var fileManagers = map[string]string{
+	"darwin":  "open",
+	"linux":   "xdg-open",
+	"windows": "explorer",
+}
+
+// FindFileManager returns the command that shows a path in the system file manager
+func FindFileManager() (*Editor, error) {
+	name, ok := fileManagers[goos]
+	if !ok {
+		return nil, fmt.Errorf("no file manager known for %s", goos)
+	}
+	path, err := lookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("file manager not found: %s", name)
+	}
+	return &Editor{Path: path, Args: []string{}}, nil
+}
+
+// Reveal shows path in the file manager fm
+func Reveal(path string, fm *Editor) error {
+	cmd := newCommand(fm.Path, append(append([]string{}, fm.Args...), path))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+
+	// explorer.exe exits with status 1 even when it opened the window
+	var exitErr *exec.ExitError
+	if goos == "windows" && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open file manager: %w", err)
+	}
+	return nil
+}
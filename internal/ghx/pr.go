@@ -1,10 +1,16 @@
 package ghx
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/gitx"
+	"github.com/toritori0318/git-wt/internal/logx"
 )
 
 // PRInfo represents Pull Request information
@@ -21,17 +27,35 @@ func IsGhAvailable() bool {
 	return err == nil
 }
 
+// IsGhAuthenticated checks if gh is logged in to a GitHub host
+func IsGhAuthenticated() bool {
+	args := []string{"auth", "status"}
+	start := time.Now()
+	err := exec.Command("gh", args...).Run()
+	logx.LogCommand(logx.Command{Name: "gh", Args: args, Duration: time.Since(start), Err: err})
+	return err == nil
+}
+
 // GetPRInfo retrieves PR information using gh CLI
-func GetPRInfo(prNumber int) (*PRInfo, error) {
+// gh runs in the work directory of ctx (see gitx.WithWorkDir) to find the repository.
+func GetPRInfo(ctx context.Context, prNumber int) (*PRInfo, error) {
 	if !IsGhAvailable() {
 		return nil, fmt.Errorf("GitHub CLI (gh) not found. Please install: https://cli.github.com/")
 	}
 
 	// Get PR info with gh pr view
-	cmd := exec.Command("gh", "pr", "view", fmt.Sprintf("%d", prNumber),
-		"--json", "headRefName,headRepositoryOwner,headRepository,isCrossRepository")
+	args := []string{"pr", "view", fmt.Sprintf("%d", prNumber),
+		"--json", "headRefName,headRepositoryOwner,headRepository,isCrossRepository"}
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = gitx.WorkDir(ctx)
 
+	start := time.Now()
 	output, err := cmd.CombinedOutput()
+	entry := logx.Command{Name: "gh", Args: args, Dir: cmd.Dir, Duration: time.Since(start), Err: err}
+	if err != nil {
+		entry.Stderr = string(output)
+	}
+	logx.LogCommand(entry)
 	if err != nil {
 		return nil, fmt.Errorf("gh pr view failed: %w\nOutput: %s", err, string(output))
 	}
@@ -61,44 +85,48 @@ func GetPRInfo(prNumber int) (*PRInfo, error) {
 }
 
 // FetchPRBranch fetches the PR branch and creates a local branch
-func FetchPRBranch(remote, remoteBranch, localBranch string) error {
+// Credential prompts are disabled and gitx.NetworkTimeout applies.
+func FetchPRBranch(ctx context.Context, remote, remoteBranch, localBranch string) error {
+	opts := gitx.RunOptions{Network: true, Operation: "fetching the PR branch"}
+
 	// git fetch <remote> <remoteBranch>:<localBranch>
-	cmd := exec.Command("git", "fetch", remote,
+	_, err := gitx.RunGitWithOptions(ctx, "", opts, "fetch", remote,
 		fmt.Sprintf("%s:%s", remoteBranch, localBranch))
-
-	output, err := cmd.CombinedOutput()
 	if err != nil {
-		// If branch already exists, try to update
-		if strings.Contains(string(output), "already exists") {
-			// Update existing branch
-			updateCmd := exec.Command("git", "fetch", remote, remoteBranch)
-			if updateErr := updateCmd.Run(); updateErr != nil {
-				return fmt.Errorf("failed to update branch: %w", updateErr)
-			}
-
-			// Reset local branch to match remote (when not checked out)
-			resetCmd := exec.Command("git", "branch", "-f", localBranch,
-				fmt.Sprintf("%s/%s", remote, remoteBranch))
-			if resetErr := resetCmd.Run(); resetErr != nil {
-				return fmt.Errorf("failed to reset branch: %w", resetErr)
-			}
-			return nil
+		var timeoutErr *gitx.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return err
+		}
+
+		// If the branch already exists (e.g. it has diverged), try to update it
+		exists, existsErr := gitx.BranchExists(ctx, localBranch)
+		if existsErr != nil || !exists {
+			return fmt.Errorf("git fetch failed: %w", err)
+		}
+
+		// Update existing branch
+		if _, err := gitx.RunGitWithOptions(ctx, "", opts, "fetch", remote, remoteBranch); err != nil {
+			return fmt.Errorf("failed to update branch: %w", err)
+		}
+
+		// Reset local branch to match remote (when not checked out)
+		if _, err := gitx.RunGit(ctx, "branch", "-f", localBranch,
+			fmt.Sprintf("%s/%s", remote, remoteBranch)); err != nil {
+			return fmt.Errorf("failed to reset branch: %w", err)
 		}
-		return fmt.Errorf("git fetch failed: %w\nOutput: %s", err, string(output))
 	}
 
 	return nil
 }
 
 // GetCurrentRemote gets the current remote name (usually "origin")
-func GetCurrentRemote() (string, error) {
-	cmd := exec.Command("git", "remote")
-	output, err := cmd.Output()
+func GetCurrentRemote(ctx context.Context) (string, error) {
+	output, err := gitx.RunGit(ctx, "remote")
 	if err != nil {
 		return "", fmt.Errorf("failed to get remotes: %w", err)
 	}
 
-	remotes := strings.Split(strings.TrimSpace(string(output)), "\n")
+	remotes := strings.Split(output, "\n")
 	if len(remotes) == 0 {
 		return "", fmt.Errorf("no remotes configured")
 	}
@@ -115,19 +143,18 @@ func GetCurrentRemote() (string, error) {
 }
 
 // RemoteExists checks if a remote exists
-func RemoteExists(remote string) bool {
-	cmd := exec.Command("git", "remote", "get-url", remote)
-	return cmd.Run() == nil
+func RemoteExists(ctx context.Context, remote string) bool {
+	_, err := gitx.RunGit(ctx, "remote", "get-url", remote)
+	return err == nil
 }
 
 // GetOriginURL gets the URL of origin remote
-func GetOriginURL() (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.Output()
+func GetOriginURL(ctx context.Context) (string, error) {
+	output, err := gitx.RunGit(ctx, "remote", "get-url", "origin")
 	if err != nil {
 		return "", fmt.Errorf("failed to get origin remote URL: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return output, nil
 }
 
 // IsSSHURL checks if the URL is SSH format
@@ -136,11 +163,11 @@ func IsSSHURL(url string) bool {
 }
 
 // AddRemote adds a new remote with URL format matching origin
-func AddRemote(name, owner, repo string) error {
+func AddRemote(ctx context.Context, name, owner, repo string) error {
 	var url string
 
 	// Get origin URL format
-	originURL, err := GetOriginURL()
+	originURL, err := GetOriginURL(ctx)
 	if err == nil && IsSSHURL(originURL) {
 		// SSH format
 		url = fmt.Sprintf("git@github.com:%s/%s.git", owner, repo)
@@ -149,15 +176,14 @@ func AddRemote(name, owner, repo string) error {
 		url = fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
 	}
 
-	cmd := exec.Command("git", "remote", "add", name, url)
-	if err := cmd.Run(); err != nil {
+	if _, err := gitx.RunGit(ctx, "remote", "add", name, url); err != nil {
 		return fmt.Errorf("failed to add remote: %w", err)
 	}
 	return nil
 }
 
 // RemoveRemote removes a remote
-func RemoveRemote(name string) error {
-	cmd := exec.Command("git", "remote", "remove", name)
-	return cmd.Run()
+func RemoveRemote(ctx context.Context, name string) error {
+	_, err := gitx.RunGit(ctx, "remote", "remove", name)
+	return err
 }
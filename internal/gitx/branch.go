@@ -3,6 +3,7 @@ package gitx
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -17,12 +18,16 @@ func GetCurrentBranch(ctx context.Context) (string, error) {
 
 // BranchExists checks if a branch exists locally
 func BranchExists(ctx context.Context, branch string) (bool, error) {
-	ref := fmt.Sprintf("refs/heads/%s", branch)
-	_, err := RunGit(ctx, "show-ref", "--verify", ref)
+	return RefExists(ctx, fmt.Sprintf("refs/heads/%s", branch))
+}
+
+// RefExists checks if a fully qualified ref (e.g. "refs/remotes/origin/main") exists
+func RefExists(ctx context.Context, ref string) (bool, error) {
+	_, err := RunGit(ctx, "show-ref", "--verify", "--quiet", ref)
 	if err != nil {
-		// show-ref returns an error when the branch doesn't exist
-		if strings.Contains(err.Error(), "not a valid ref") ||
-		   strings.Contains(err.Error(), "fatal:") {
+		// With --quiet, show-ref exits with 1 only when the ref doesn't exist;
+		// anything else (e.g. not a repository) is a real error
+		if ExitCode(err) == 1 {
 			return false, nil
 		}
 		return false, err
@@ -41,6 +46,70 @@ func DeleteBranch(ctx context.Context, branch string, force bool) error {
 	return err
 }
 
+// CreateBranch creates the local branch pointing at commit, without checking it out
+func CreateBranch(ctx context.Context, branch, commit string) error {
+	_, err := RunGit(ctx, "branch", branch, commit)
+	return err
+}
+
+// RenameBranch renames a local branch (also updates worktrees that have it checked out)
+func RenameBranch(ctx context.Context, oldName, newName string) error {
+	_, err := RunGit(ctx, "branch", "-m", oldName, newName)
+	invalidateSession(ctx) // Worktrees with the branch checked out now show the new name
+	return err
+}
+
+// prConfigKey is the branch configuration variable recording the PR a branch was checked out for
+const prConfigKey = "wt-pr"
+
+// SetBranchPR records that branch was checked out for the pull request number (branch.<name>.wt-pr)
+func SetBranchPR(ctx context.Context, branch string, number int) error {
+	_, err := RunGit(ctx, "config", "branch."+branch+"."+prConfigKey, strconv.Itoa(number))
+	return err
+}
+
+// GetBranchPR returns the pull request number recorded with SetBranchPR, or 0 if there is none
+func GetBranchPR(ctx context.Context, branch string) (int, error) {
+	output, err := RunGit(ctx, "config", "--get", "branch."+branch+"."+prConfigKey)
+	if err != nil {
+		// git config exits with 1 when the variable isn't set
+		if ExitCode(err) == 1 {
+			return 0, nil
+		}
+		return 0, err
+	}
+	number, err := strconv.Atoi(output)
+	if err != nil {
+		return 0, fmt.Errorf("invalid branch.%s.%s: %q", branch, prConfigKey, output)
+	}
+	return number, nil
+}
+
+// IsAncestor reports whether the commit ancestor is reachable from rev
+func IsAncestor(ctx context.Context, ancestor, rev string) (bool, error) {
+	_, err := RunGit(ctx, "merge-base", "--is-ancestor", ancestor, rev)
+	if err != nil {
+		// Exit code 1 means "not an ancestor"; anything else (e.g. an unknown rev) is a real error
+		if ExitCode(err) == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// MergeBase returns the best common ancestor of a and b, or "" if they have no common history
+func MergeBase(ctx context.Context, a, b string) (string, error) {
+	output, err := RunGit(ctx, "merge-base", a, b)
+	if err != nil {
+		if ExitCode(err) == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return output, nil
+}
+
 // IsBranchMerged checks if a branch is merged into the current branch
 func IsBranchMerged(ctx context.Context, branch string) (bool, error) {
 	output, err := RunGit(ctx, "branch", "--merged")
@@ -50,8 +119,8 @@ func IsBranchMerged(ctx context.Context, branch string) (bool, error) {
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
-		// Branch name format: "  branch" or "* branch"
-		branchName := strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		// Branch name format: "  branch", "* branch" (current) or "+ branch" (checked out in another worktree)
+		branchName := strings.TrimSpace(strings.TrimLeft(line, "*+"))
 		if branchName == branch {
 			return true, nil
 		}
@@ -60,6 +129,51 @@ func IsBranchMerged(ctx context.Context, branch string) (bool, error) {
 	return false, nil
 }
 
+// ListLocalBranches returns the names of all local branches
+func ListLocalBranches(ctx context.Context) ([]string, error) {
+	return listBranchRefs(ctx, "for-each-ref", "--format=%(refname)", "refs/heads")
+}
+
+// ListBranchesMergedInto returns the local branches whose tip is reachable from target
+func ListBranchesMergedInto(ctx context.Context, target string) ([]string, error) {
+	return listBranchRefs(ctx, "for-each-ref", "--format=%(refname)", "--merged="+target, "refs/heads")
+}
+
+// listBranchRefs runs git with args printing one "refs/heads/<name>" per line and returns the names
+func listBranchRefs(ctx context.Context, args ...string) ([]string, error) {
+	output, err := RunGit(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for _, line := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(line, "refs/heads/"); ok {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
+
+// DefaultBranch returns the repository's default branch
+// It is the branch origin/HEAD points to, or else "main" or "master" if it exists locally.
+func DefaultBranch(ctx context.Context) (string, error) {
+	if output, err := RunGit(ctx, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); err == nil {
+		if name, ok := strings.CutPrefix(output, "refs/remotes/origin/"); ok {
+			return name, nil
+		}
+	}
+	for _, name := range []string{"main", "master"} {
+		exists, err := BranchExists(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("cannot determine the default branch (set it with: git remote set-head origin --auto)")
+}
+
 // IsUsingBranch checks if any worktree (except the specified path) is using the branch
 func IsUsingBranch(ctx context.Context, branch string, excludePath string) (bool, error) {
 	worktrees, err := List(ctx)
@@ -75,3 +189,74 @@ func IsUsingBranch(ctx context.Context, branch string, excludePath string) (bool
 
 	return false, nil
 }
+
+// ListBranchesWithoutWorktree returns local and remote branch names not checked out in any worktree
+// Remote branches are returned without their remote prefix (e.g. "origin/feature" -> "feature").
+// Uses a single git call so it is fast enough for shell completion.
+func ListBranchesWithoutWorktree(ctx context.Context) ([]string, error) {
+	output, err := RunGit(ctx, "for-each-ref", "--format=%(refname)\t%(worktreepath)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBranchesWithoutWorktree(output), nil
+}
+
+// parseBranchesWithoutWorktree parses "<refname>\t<worktreepath>" lines from git for-each-ref
+func parseBranchesWithoutWorktree(output string) []string {
+	inWorktree := make(map[string]bool)
+	var local, remote []string
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		ref, worktreePath, _ := strings.Cut(line, "\t")
+
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			name := strings.TrimPrefix(ref, "refs/heads/")
+			if worktreePath != "" {
+				inWorktree[name] = true
+				continue
+			}
+			local = append(local, name)
+		case strings.HasPrefix(ref, "refs/remotes/"):
+			// refs/remotes/<remote>/<branch>
+			_, name, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+			if !ok || name == "HEAD" {
+				continue
+			}
+			remote = append(remote, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var branches []string
+	for _, name := range append(local, remote...) {
+		if inWorktree[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		branches = append(branches, name)
+	}
+
+	return branches
+}
+
+// FastForward fast-forwards the branch of the worktree at path to its upstream
+// It fails without changing anything if the branch has diverged from the upstream.
+func FastForward(ctx context.Context, path string) error {
+	_, err := RunGitInDir(ctx, path, "merge", "--ff-only", "--quiet", "@{upstream}")
+	return err
+}
+
+// RebaseOnUpstream rebases the branch of the worktree at path onto its upstream
+// If the rebase stops on a conflict, it is aborted so that the worktree is left as it was.
+func RebaseOnUpstream(ctx context.Context, path string) error {
+	if _, err := RunGitInDir(ctx, path, "rebase", "--quiet", "@{upstream}"); err != nil {
+		_, _ = RunGitInDir(ctx, path, "rebase", "--abort")
+		return err
+	}
+	return nil
+}
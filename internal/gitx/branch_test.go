@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -295,3 +297,169 @@ func TestIsBranchMerged(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAncestorAndMergeBase(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	base := run("rev-parse", "HEAD")
+	run("commit", "--allow-empty", "-m", "Ahead")
+	// A commit with unrelated history
+	orphan := run("commit-tree", "-m", "Unrelated", base+"^{tree}")
+
+	if ok, err := IsAncestor(ctx, base, "HEAD"); err != nil || !ok {
+		t.Errorf("IsAncestor(base, HEAD) = %v, %v, want true", ok, err)
+	}
+	if ok, err := IsAncestor(ctx, "HEAD", base); err != nil || ok {
+		t.Errorf("IsAncestor(HEAD, base) = %v, %v, want false", ok, err)
+	}
+	if _, err := IsAncestor(ctx, "no-such-rev", "HEAD"); err == nil {
+		t.Error("IsAncestor() with an unknown rev expected error, got nil")
+	}
+
+	if got, err := MergeBase(ctx, base, "HEAD"); err != nil || got != base {
+		t.Errorf("MergeBase(base, HEAD) = %q, %v, want %q", got, err, base)
+	}
+	if got, err := MergeBase(ctx, orphan, "HEAD"); err != nil || got != "" {
+		t.Errorf("MergeBase(orphan, HEAD) = %q, %v, want \"\"", got, err)
+	}
+}
+
+func TestParseBranchesWithoutWorktree(t *testing.T) {
+	output := "refs/heads/main\t/repo\n" +
+		"refs/heads/develop\t\n" +
+		"refs/heads/feature/x\t\n" +
+		"refs/remotes/origin/HEAD\t\n" +
+		"refs/remotes/origin/main\t\n" +
+		"refs/remotes/origin/develop\t\n" +
+		"refs/remotes/origin/release/1.0\t\n"
+
+	got := parseBranchesWithoutWorktree(output)
+	want := []string{"develop", "feature/x", "release/1.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBranchesWithoutWorktree() = %v, want %v", got, want)
+	}
+}
+
+func TestBranchExistsOutsideRepository(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	// A real failure must not be reported as "branch not found"
+	if _, err := BranchExists(context.Background(), "main"); err == nil {
+		t.Error("BranchExists() outside a repository expected error, got nil")
+	}
+}
+
+func TestFastForwardAndRebaseOnUpstream(t *testing.T) {
+	ctx := context.Background()
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+	commit := func(dir, file string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		run(dir, "add", file)
+		run(dir, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-m", "Add "+file)
+	}
+
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	run(repoPath, "init", "--bare", remotePath)
+	run(repoPath, "remote", "add", "origin", remotePath)
+	run(repoPath, "push", "-u", "origin", "HEAD")
+
+	otherPath := filepath.Join(t.TempDir(), "other")
+	run(repoPath, "clone", remotePath, otherPath)
+	commit(otherPath, "other1.txt")
+	run(otherPath, "push", "origin", "HEAD")
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	if err := Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if err := FastForward(ctx, repoPath); err != nil {
+		t.Fatalf("FastForward() error = %v", err)
+	}
+	if _, behind, _, _ := AheadBehind(ctx, repoPath); behind != 0 {
+		t.Errorf("behind = %d after FastForward(), want 0", behind)
+	}
+
+	// Diverged: a fast-forward fails, a rebase succeeds
+	commit(otherPath, "other2.txt")
+	run(otherPath, "push", "origin", "HEAD")
+	commit(repoPath, "local.txt")
+	run(repoPath, "fetch", "origin")
+	if err := FastForward(ctx, repoPath); err == nil {
+		t.Error("FastForward() of a diverged branch expected error, got nil")
+	}
+	if err := RebaseOnUpstream(ctx, repoPath); err != nil {
+		t.Fatalf("RebaseOnUpstream() error = %v", err)
+	}
+	if ahead, behind, _, _ := AheadBehind(ctx, repoPath); ahead != 1 || behind != 0 {
+		t.Errorf("AheadBehind() after RebaseOnUpstream() = %d, %d, want 1, 0", ahead, behind)
+	}
+}
+
+func TestBranchPR(t *testing.T) {
+	ctx := context.Background()
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	if pr, err := GetBranchPR(ctx, "feature/x"); err != nil || pr != 0 {
+		t.Errorf("GetBranchPR() without a PR = %d, %v, want 0, nil", pr, err)
+	}
+	if err := SetBranchPR(ctx, "feature/x", 123); err != nil {
+		t.Fatalf("SetBranchPR() error = %v", err)
+	}
+	if pr, err := GetBranchPR(ctx, "feature/x"); err != nil || pr != 123 {
+		t.Errorf("GetBranchPR() = %d, %v, want 123, nil", pr, err)
+	}
+}
@@ -0,0 +1,74 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Checkout describes a working tree that may belong to another repository (e.g. a separate clone)
+type Checkout struct {
+	Root   string // Top-level directory of the working tree
+	Branch string // Checked-out branch, empty if HEAD is detached
+	HEAD   string // Commit hash of HEAD
+}
+
+// InspectCheckout returns the working tree containing dir, which need not belong to the current repository
+func InspectCheckout(ctx context.Context, dir string) (*Checkout, error) {
+	root, err := RunGitInDir(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git checkout: %s", dir)
+	}
+	head, err := RunGitInDir(ctx, dir, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("checkout has no commits: %s", dir)
+	}
+	// symbolic-ref fails for a detached HEAD
+	branch, _ := RunGitInDir(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+
+	return &Checkout{Root: root, Branch: branch, HEAD: head}, nil
+}
+
+// RemoteURL returns the URL of remote in the repository at dir ("" if the remote doesn't exist)
+func RemoteURL(ctx context.Context, dir, remote string) string {
+	url, err := RunGitInDir(ctx, dir, "remote", "get-url", remote)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// RootCommits returns the commits without parents reachable from HEAD in the repository at dir
+func RootCommits(ctx context.Context, dir string) ([]string, error) {
+	output, err := RunGitInDir(ctx, dir, "rev-list", "--max-parents=0", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// CommitExists checks whether the commit exists in the current repository
+func CommitExists(ctx context.Context, commit string) bool {
+	_, err := RunGit(ctx, "cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// ResolveCommit returns the commit hash rev points to in the current repository
+func ResolveCommit(ctx context.Context, rev string) (string, error) {
+	return RunGit(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+// FetchFromCheckout fetches refspec from the repository at dir (a local path) into the current repository
+// Without a "+" prefix, an existing branch is only fast-forwarded.
+func FetchFromCheckout(ctx context.Context, dir, refspec string) error {
+	// Not --quiet: that also hides why a ref was rejected (e.g. "non-fast-forward")
+	_, err := RunGit(ctx, "fetch", "--no-tags", dir, refspec)
+	return err
+}
+
+// AddDetached creates a new worktree with a detached HEAD at commit
+func AddDetached(ctx context.Context, path, commit string) error {
+	_, err := RunGit(ctx, "worktree", "add", "--detach", path, commit)
+	invalidateSession(ctx)
+	return err
+}
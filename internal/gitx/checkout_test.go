@@ -0,0 +1,44 @@
+package gitx
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectCheckout(t *testing.T) {
+	ctx := context.Background()
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	checkout, err := InspectCheckout(ctx, repoPath)
+	if err != nil {
+		t.Fatalf("InspectCheckout() error = %v", err)
+	}
+	if !SamePath(checkout.Root, repoPath) || checkout.Branch == "" || len(checkout.HEAD) != 40 {
+		t.Errorf("InspectCheckout() = %+v, want root %s on a branch", checkout, repoPath)
+	}
+
+	run(repoPath, "checkout", "-q", "--detach")
+	checkout, err = InspectCheckout(ctx, filepath.Join(repoPath, "."))
+	if err != nil {
+		t.Fatalf("InspectCheckout() error = %v", err)
+	}
+	if checkout.Branch != "" {
+		t.Errorf("InspectCheckout() branch = %q for a detached HEAD, want empty", checkout.Branch)
+	}
+
+	if _, err := InspectCheckout(ctx, t.TempDir()); err == nil {
+		t.Error("InspectCheckout() of a plain directory should fail")
+	}
+}
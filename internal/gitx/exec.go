@@ -3,17 +3,70 @@ package gitx
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/logx"
 )
 
+// DefaultNetworkTimeout is the default time limit for git commands that may contact a remote
+const DefaultNetworkTimeout = 5 * time.Minute
+
 var (
 	// Debug controls whether to log git commands to stderr
 	Debug = false
+
+	// NetworkTimeout limits git commands run with RunOptions.Network (0 disables the limit)
+	NetworkTimeout = DefaultNetworkTimeout
+
+	// LocalTimeout limits the other git commands (0 disables the limit, the default)
+	// It is set in slow filesystem mode, where git may hang on an unresponsive network filesystem.
+	LocalTimeout time.Duration
+
+	// RespectGitEnv keeps the variables of repositoryEnvVars in the environment of git commands
+	// By default they are removed, so that wt works on the repository found from the current
+	// directory (or -C) even when a git hook or an IDE sets GIT_DIR for another one.
+	RespectGitEnv = false
 )
 
+// repositoryEnvVars are the environment variables that make git use a repository other than the
+// one it discovers from its working directory
+var repositoryEnvVars = []string{"GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE", "GIT_COMMON_DIR"}
+
+// RunOptions controls how a git command is run
+type RunOptions struct {
+	// Network marks commands that may contact a remote. Credential prompts are disabled
+	// (nobody would see them) and NetworkTimeout applies.
+	Network bool
+	// Operation names the command in a TimeoutError (defaults to "git <subcommand>")
+	Operation string
+}
+
+// networkEnv disables interactive credential prompts from git and Git Credential Manager
+var networkEnv = []string{"GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never"}
+
+// TimeoutError represents a git operation that did not finish within NetworkTimeout (or LocalTimeout)
+type TimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+	Local     bool // Limited by LocalTimeout
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Local {
+		return fmt.Sprintf("%s timed out after %s: the filesystem may be slow or unresponsive (raise performance.git_timeout, or set it to 0 for no limit)", e.Operation, e.Timeout)
+	}
+	return fmt.Sprintf("%s timed out after %s (use --timeout to allow more time)", e.Operation, e.Timeout)
+}
+
 // RunGit executes a git command with the given arguments
 func RunGit(ctx context.Context, args ...string) (string, error) {
 	return RunGitInDir(ctx, "", args...)
@@ -21,40 +74,327 @@ func RunGit(ctx context.Context, args ...string) (string, error) {
 
 // RunGitInDir executes a git command in a specific directory
 func RunGitInDir(ctx context.Context, dir string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	if dir != "" {
-		cmd.Dir = dir
+	return RunGitWithOptions(ctx, dir, RunOptions{}, args...)
+}
+
+// RunGitWithOptions executes a git command in a specific directory with the given options
+func RunGitWithOptions(ctx context.Context, dir string, opts RunOptions, args ...string) (string, error) {
+	ctx, cancel := applyRunOptions(ctx, opts)
+	defer cancel()
+
+	stdout, _, err := runGit(ctx, dir, args...)
+	if err != nil {
+		return "", checkTimeout(ctx, opts, args, err)
+	}
+	return stdout, nil
+}
+
+// applyRunOptions returns a context carrying the environment and deadline for opts
+func applyRunOptions(ctx context.Context, opts RunOptions) (context.Context, context.CancelFunc) {
+	if !opts.Network {
+		if LocalTimeout <= 0 {
+			return ctx, func() {}
+		}
+		return context.WithTimeout(ctx, LocalTimeout)
+	}
+	ctx = context.WithValue(ctx, envKey{}, networkEnv)
+	if NetworkTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, NetworkTimeout)
+}
+
+// checkTimeout converts err into a TimeoutError if the time limit for opts was reached
+func checkTimeout(ctx context.Context, opts RunOptions, args []string, err error) error {
+	limit := NetworkTimeout
+	if !opts.Network {
+		limit = LocalTimeout
 	}
+	if limit <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	operation := opts.Operation
+	if operation == "" {
+		operation = "git " + args[0]
+	}
+	return &TimeoutError{Operation: operation, Timeout: limit, Local: !opts.Network}
+}
+
+type workDirKey struct{}
+
+// WithWorkDir returns a copy of ctx in which git runs in dir instead of the current directory (wt -C)
+// Directories passed to the Run functions that are relative are resolved against dir.
+func WithWorkDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workDirKey{}, dir)
+}
+
+// WorkDir returns the directory set with WithWorkDir, or "" for the current directory
+func WorkDir(ctx context.Context) string {
+	dir, _ := ctx.Value(workDirKey{}).(string)
+	return dir
+}
 
-	if Debug {
-		cmdStr := "git " + strings.Join(args, " ")
-		if dir != "" {
-			cmdStr = fmt.Sprintf("(cd %s && %s)", dir, cmdStr)
+// AbsPath returns path as an absolute path, resolving a relative path against WorkDir(ctx)
+func AbsPath(ctx context.Context, path string) (string, error) {
+	return filepath.Abs(commandDir(ctx, path))
+}
+
+// commandDir returns the directory to run git in for dir ("" is the work directory)
+func commandDir(ctx context.Context, dir string) string {
+	workDir := WorkDir(ctx)
+	switch {
+	case workDir == "" || filepath.IsAbs(dir):
+		return dir
+	case dir == "":
+		return workDir
+	default:
+		return filepath.Join(workDir, dir)
+	}
+}
+
+type envKey struct{}
+
+// commandEnv returns the environment for a git command run with ctx (nil means inherit)
+func commandEnv(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey{}).([]string)
+	if len(env) == 0 && !hasRepositoryEnv() {
+		return nil
+	}
+	return append(Environ(), env...)
+}
+
+// Environ returns the environment for commands run in a worktree: os.Environ() without the
+// variables pointing git at another repository (GIT_DIR, GIT_WORK_TREE, ...) unless RespectGitEnv is set
+func Environ() []string {
+	env := os.Environ()
+	if RespectGitEnv {
+		return env
+	}
+	result := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(repositoryEnvVars, name) {
+			result = append(result, kv)
+		}
+	}
+	return result
+}
+
+// hasRepositoryEnv reports whether Environ removes any variable from the environment
+func hasRepositoryEnv() bool {
+	if RespectGitEnv {
+		return false
+	}
+	for _, name := range repositoryEnvVars {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
 		}
-		fmt.Fprintf(os.Stderr, "[debug] %s\n", cmdStr)
 	}
+	return false
+}
+
+// logCommand prints the git command to stderr in debug mode
+// Independently of debug mode, every git command is recorded in the debug log file (see logx).
+func logCommand(dir string, args []string) {
+	if !Debug {
+		return
+	}
+	cmdStr := "git " + strings.Join(args, " ")
+	if dir != "" {
+		cmdStr = fmt.Sprintf("(cd %s && %s)", dir, cmdStr)
+	}
+	fmt.Fprintf(os.Stderr, "[debug] %s\n", cmdStr)
+}
+
+// Runner executes git commands
+// The default runner shells out to git; tests can replace it with SetRunner. The output of
+// RunGitWithProgress and RunGitStreaming is passed through by the default runner only.
+type Runner interface {
+	Run(ctx context.Context, dir string, args ...string) (stdout, stderr string, err error)
+}
+
+// defaultRunner implements Runner using exec.CommandContext
+type defaultRunner struct{}
+
+func (r *defaultRunner) Run(ctx context.Context, dir string, args ...string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	cmd.Env = commandEnv(ctx)
+	// Don't wait forever for subprocesses (e.g. ssh) that keep the output open after git is killed
+	cmd.WaitDelay = 5 * time.Second
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stream, _ := ctx.Value(streamKey{}).(streamWriters)
+	cmd.Stdout = teeStream(&stdout, stream.stdout)
+	if f, ok := stream.stderr.(*os.File); ok {
+		// git shows progress meters only when its stderr is a terminal, so it gets the file itself;
+		// its messages are then left out of the GitError, having already been shown
+		cmd.Stderr = f
+	} else {
+		cmd.Stderr = teeStream(&stderr, stream.stderr)
+	}
 
 	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// runner is the Runner used by all git commands in this package
+var runner Runner = &defaultRunner{}
+
+// SetRunner replaces the Runner used for git commands and returns a function restoring the previous one
+// It is intended for tests.
+func SetRunner(r Runner) (restore func()) {
+	prev := runner
+	runner = r
+	return func() { runner = prev }
+}
+
+// runGit executes a git command and returns both stdout and stderr
+// Some commands (e.g. 'git worktree repair') report their results on stderr.
+func runGit(ctx context.Context, dir string, args ...string) (string, string, error) {
+	dir = commandDir(ctx, dir)
+	logCommand(dir, args)
+
+	start := time.Now()
+	stdout, stderr, err := runner.Run(ctx, dir, args...)
+	stderrStr := strings.TrimSpace(stderr)
+	logx.LogCommand(logx.Command{Name: "git", Args: args, Dir: dir, Duration: time.Since(start), Err: err, Stderr: stderrStr})
 	if err != nil {
-		stderrStr := strings.TrimSpace(stderr.String())
-		if stderrStr != "" {
-			return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, stderrStr)
-		}
-		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		return "", stderrStr, newGitError(args, stderrStr, err)
+	}
+
+	return strings.TrimSpace(stdout), stderrStr, nil
+}
+
+// GitError is returned when a git command fails
+type GitError struct {
+	Args     []string
+	ExitCode int    // Process exit code (-1 if git could not be run)
+	Stderr   string // Trimmed stderr output
+	Err      error
+}
+
+func (e *GitError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("git %s failed: %v: %s", e.Args[0], e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("git %s failed: %v", e.Args[0], e.Err)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// newGitError wraps err from running git with args, capturing the process exit code
+func newGitError(args []string, stderr string, err error) *GitError {
+	exitCode := -1
+	// *exec.ExitError (and test doubles) report the exit code
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		exitCode = coder.ExitCode()
+	}
+	return &GitError{Args: args, ExitCode: exitCode, Stderr: stderr, Err: err}
+}
+
+// ExitCode returns the exit code of the failed git command in err's chain, or -1 if there is none
+func ExitCode(err error) int {
+	var gitErr *GitError
+	if errors.As(err, &gitErr) {
+		return gitErr.ExitCode
+	}
+	return -1
+}
+
+// IsNotRepository reports whether err is a git command failing outside of a git repository
+func IsNotRepository(err error) bool {
+	var gitErr *GitError
+	return errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "not a git repository")
+}
+
+// RunGitWithProgress executes a git command like RunGit, but lets its progress output (stderr) through to progress
+// An *os.File (e.g. os.Stderr) becomes git's stderr, so that git draws its progress meters on a terminal.
+// Other writers get stderr as it is written, and it is also kept for the GitError.
+func RunGitWithProgress(ctx context.Context, dir string, progress io.Writer, args ...string) (string, error) {
+	ctx, cancel := applyRunOptions(ctx, RunOptions{})
+	defer cancel()
+
+	stdout, _, err := runGit(withStream(ctx, nil, progress), dir, args...)
+	if err != nil {
+		return "", checkTimeout(ctx, RunOptions{}, args, err)
 	}
+	return stdout, nil
+}
+
+// IsLockContention reports whether err is a git command failing because another git process held a lock file
+// (e.g. "Unable to create '.../index.lock': File exists"), so that retrying may succeed
+func IsLockContention(err error) bool {
+	var gitErr *GitError
+	return errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, ".lock': File exists")
+}
+
+// RunGitStreaming executes a git command in dir, streaming its stdout and stderr to w
+// It is used for long-running commands whose progress should be visible to the user.
+func RunGitStreaming(ctx context.Context, dir string, w io.Writer, opts RunOptions, args ...string) error {
+	ctx, cancel := applyRunOptions(ctx, opts)
+	defer cancel()
+
+	// stdout and stderr are copied by separate goroutines
+	lw := &lockedWriter{w: w}
+	if _, _, err := runGit(withStream(ctx, lw, lw), dir, args...); err != nil {
+		return checkTimeout(ctx, opts, args, err)
+	}
+	return nil
+}
+
+type streamKey struct{}
+
+// streamWriters are the writers the default runner passes the output of git through to as it is written
+type streamWriters struct {
+	stdout io.Writer // nil: only captured
+	stderr io.Writer
+}
+
+// withStream returns a copy of ctx in which git's stdout and stderr are also passed through to
+// the given writers (either may be nil). The output is still captured for the result and GitError,
+// except stderr passed to an *os.File (see RunGitWithProgress).
+func withStream(ctx context.Context, stdout, stderr io.Writer) context.Context {
+	return context.WithValue(ctx, streamKey{}, streamWriters{stdout: stdout, stderr: stderr})
+}
+
+// teeStream returns buf, or a writer that also passes what is written on to w
+func teeStream(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// lockedWriter serializes writes to w
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// GitNotFoundError is returned when git is not installed
+type GitNotFoundError struct{}
 
-	return strings.TrimSpace(stdout.String()), nil
+func (e *GitNotFoundError) Error() string {
+	return "git command not found: please install git"
 }
 
 // CheckGitInstalled verifies that git is available
 func CheckGitInstalled() error {
 	_, err := exec.LookPath("git")
 	if err != nil {
-		return fmt.Errorf("git command not found: please install git")
+		return &GitNotFoundError{}
 	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package gitx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ListCacheTTL is how long a worktree list cached on disk is used
+const ListCacheTTL = 5 * time.Second
+
+// ListCacheDir, when set, is the directory in which worktree lists are cached across commands
+// (slow filesystem mode, where 'git worktree list' is slow). A cached list is used for ListCacheTTL,
+// as long as the modification time of <git common dir>/worktrees hasn't changed, so it misses
+// changes made by git itself that don't add or remove a worktree (e.g. a checkout in another worktree).
+var ListCacheDir = ""
+
+// listCacheEntry is the contents of a worktree list cache file
+type listCacheEntry struct {
+	CommonDir string     `json:"common_dir"`
+	ModTime   int64      `json:"mod_time"` // Of <common dir>/worktrees in nanoseconds (0 if it doesn't exist)
+	Saved     time.Time  `json:"saved"`
+	Worktrees []Worktree `json:"worktrees"`
+}
+
+// listCacheKey identifies the cached worktree list of a repository
+type listCacheKey struct {
+	file      string
+	commonDir string
+	modTime   int64
+}
+
+// newListCacheKey returns the cache key for the repository of dir, or nil if caching is off or
+// the repository can't be determined
+// The common directory comes from ctx's session, so git runs for it at most once per command.
+func newListCacheKey(ctx context.Context, dir string) *listCacheKey {
+	if ListCacheDir == "" {
+		return nil
+	}
+	commonDir, err := CommonDir(ctx, dir)
+	if err != nil {
+		return nil
+	}
+
+	var modTime int64
+	if info, err := os.Stat(filepath.Join(commonDir, "worktrees")); err == nil {
+		modTime = info.ModTime().UnixNano()
+	}
+	sum := sha256.Sum256([]byte(commonDir))
+	return &listCacheKey{
+		file:      filepath.Join(ListCacheDir, "worktrees-"+hex.EncodeToString(sum[:])[:16]+".json"),
+		commonDir: commonDir,
+		modTime:   modTime,
+	}
+}
+
+// load returns the cached worktree list if it is still valid
+func (k *listCacheKey) load() ([]Worktree, bool) {
+	data, err := os.ReadFile(k.file)
+	if err != nil {
+		return nil, false
+	}
+	var entry listCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if entry.CommonDir != k.commonDir || entry.ModTime != k.modTime || time.Since(entry.Saved) > ListCacheTTL || entry.Saved.After(time.Now()) {
+		return nil, false
+	}
+	return entry.Worktrees, true
+}
+
+// store caches worktrees; failures are ignored (the list is read from git next time)
+func (k *listCacheKey) store(worktrees []Worktree) {
+	data, err := json.Marshal(listCacheEntry{CommonDir: k.commonDir, ModTime: k.modTime, Saved: time.Now(), Worktrees: worktrees})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(ListCacheDir, 0755); err != nil {
+		return
+	}
+	// Replace the file in one step, so that concurrent commands never read half of it
+	tmp, err := os.CreateTemp(ListCacheDir, ".worktrees-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), k.file) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// invalidateListCache removes the cached worktree list of the repository of ctx, if any
+func invalidateListCache(ctx context.Context) {
+	if k := newListCacheKey(ctx, ""); k != nil {
+		_ = os.Remove(k.file)
+	}
+}
@@ -0,0 +1,169 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PatchConflictError is returned when a patch doesn't apply cleanly, even with a 3-way merge
+type PatchConflictError struct {
+	Files []string
+}
+
+func (e *PatchConflictError) Error() string {
+	return fmt.Sprintf("changes conflict with %s", strings.Join(e.Files, ", "))
+}
+
+// WritePatch writes the uncommitted changes (staged and unstaged) of the worktree at path to file
+// as a binary patch against HEAD, and returns the changed files. With includeUntracked, untracked
+// files (except ignored ones) are included as new files. The worktree's index is not modified.
+func WritePatch(ctx context.Context, path, file string, includeUntracked bool) ([]string, error) {
+	indexCtx, cleanup, err := withTempIndex(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	addArgs := []string{"add", "--update"}
+	if includeUntracked {
+		addArgs = []string{"add", "--all"}
+	}
+	if _, err := RunGitInDir(indexCtx, path, addArgs...); err != nil {
+		return nil, err
+	}
+
+	output, err := RunGitInDir(indexCtx, path, "diff", "--cached", "--name-only", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	if _, err := RunGitInDir(indexCtx, path, "diff", "--cached", "--binary", "--output="+file, "HEAD"); err != nil {
+		return nil, err
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// ApplyPatch applies the patch in file to the worktree at path, falling back to a 3-way merge
+// The patch is checked first: if any file would conflict, nothing is changed and a
+// PatchConflictError lists the files.
+func ApplyPatch(ctx context.Context, path, file string) error {
+	indexCtx, cleanup, err := withTempIndex(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// 'git apply --check --3way' records conflicts in the index, so check against a copy
+	_, stderr, err := runGit(indexCtx, path, "apply", "--3way", "--check", file)
+	if conflicts := parseApplyConflicts(stderr); len(conflicts) > 0 {
+		return &PatchConflictError{Files: conflicts}
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = RunGitInDir(ctx, path, "apply", "--3way", file)
+	return err
+}
+
+// parseApplyConflicts returns the files 'git apply --3way' reports as conflicting or not applying
+func parseApplyConflicts(stderr string) []string {
+	var files []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "Applied patch to '"); ok {
+			if name, ok := strings.CutSuffix(rest, "' with conflicts."); ok {
+				files = append(files, name)
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "error: "); ok {
+			for _, suffix := range []string{": patch does not apply", ": does not match index", ": already exists in working directory"} {
+				if name, ok := strings.CutSuffix(rest, suffix); ok && !slices.Contains(files, name) {
+					files = append(files, name)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// UntrackedFiles returns the untracked files (except ignored ones) of the worktree at path
+func UntrackedFiles(ctx context.Context, path string) ([]string, error) {
+	output, err := RunGitInDir(ctx, path, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// DiscardChanges resets the worktree at path to HEAD and deletes the given untracked files
+func DiscardChanges(ctx context.Context, path string, untracked []string) error {
+	if _, err := RunGitInDir(ctx, path, "reset", "--hard", "--quiet", "HEAD"); err != nil {
+		return err
+	}
+	if len(untracked) == 0 {
+		return nil
+	}
+	args := append([]string{"clean", "--force", "--quiet", "--"}, untracked...)
+	_, err := RunGitInDir(ctx, path, args...)
+	return err
+}
+
+// withTempIndex returns a context in which git uses a copy of the index of the worktree at path
+// The copy is deleted by cleanup.
+func withTempIndex(ctx context.Context, path string) (context.Context, func(), error) {
+	indexPath, err := RunGitInDir(ctx, path, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(commandDir(ctx, path), indexPath)
+	}
+
+	dir, err := os.MkdirTemp("", "wt-index-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	tempIndex := filepath.Join(dir, "index")
+	// Without an index (nothing staged yet), git starts from an empty one
+	if err := copyFile(indexPath, tempIndex); err != nil && !os.IsNotExist(err) {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to copy the index: %w", err)
+	}
+
+	env, _ := ctx.Value(envKey{}).([]string)
+	env = append(append([]string{}, env...), "GIT_INDEX_FILE="+tempIndex)
+	return context.WithValue(ctx, envKey{}, env), cleanup, nil
+}
+
+// copyFile copies the file src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
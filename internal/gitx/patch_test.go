@@ -0,0 +1,112 @@
+package gitx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteAndApplyPatch(t *testing.T) {
+	ctx := context.Background()
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+	write := func(dir, file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	destPath := filepath.Join(t.TempDir(), "dest")
+	run(repoPath, "worktree", "add", "-b", "dest", destPath)
+
+	write(repoPath, "README.md", "# Changed\n")
+	write(repoPath, "staged.txt", "staged\n")
+	run(repoPath, "add", "staged.txt")
+	write(repoPath, "untracked.txt", "untracked\n")
+	indexBefore := run(repoPath, "status", "--porcelain")
+
+	patch := filepath.Join(t.TempDir(), "changes.patch")
+	files, err := WritePatch(ctx, repoPath, patch, false)
+	if err != nil {
+		t.Fatalf("WritePatch() error = %v", err)
+	}
+	if want := []string{"README.md", "staged.txt"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("WritePatch() = %q, want %q", files, want)
+	}
+	files, err = WritePatch(ctx, repoPath, patch, true)
+	if err != nil {
+		t.Fatalf("WritePatch() error = %v", err)
+	}
+	if want := []string{"README.md", "staged.txt", "untracked.txt"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("WritePatch() with untracked = %q, want %q", files, want)
+	}
+	if got := run(repoPath, "status", "--porcelain"); got != indexBefore {
+		t.Errorf("WritePatch() changed the status of the source:\n%s\nwant:\n%s", got, indexBefore)
+	}
+
+	if err := ApplyPatch(ctx, destPath, patch); err != nil {
+		t.Fatalf("ApplyPatch() error = %v", err)
+	}
+	for file, want := range map[string]string{"README.md": "# Changed\n", "staged.txt": "staged\n", "untracked.txt": "untracked\n"} {
+		if got, _ := os.ReadFile(filepath.Join(destPath, file)); string(got) != want {
+			t.Errorf("%s in destination = %q, want %q", file, got, want)
+		}
+	}
+
+	// Applying again conflicts and leaves the destination as it was
+	run(destPath, "reset", "--hard", "--quiet")
+	write(destPath, "README.md", "# Other\n")
+	run(destPath, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--quiet", "-am", "Other")
+	write(destPath, "untracked.txt", "other\n")
+	err = ApplyPatch(ctx, destPath, patch)
+	var conflictErr *PatchConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("ApplyPatch() error = %v, want PatchConflictError", err)
+	}
+	if want := []string{"README.md", "untracked.txt"}; !reflect.DeepEqual(conflictErr.Files, want) {
+		t.Errorf("conflicting files = %q, want %q", conflictErr.Files, want)
+	}
+	if got := run(destPath, "status", "--porcelain"); got != "?? untracked.txt\n" {
+		t.Errorf("destination status after conflict = %q, want only the untracked file", got)
+	}
+
+	untracked, err := UntrackedFiles(ctx, repoPath)
+	if err != nil {
+		t.Fatalf("UntrackedFiles() error = %v", err)
+	}
+	if err := DiscardChanges(ctx, repoPath, untracked); err != nil {
+		t.Fatalf("DiscardChanges() error = %v", err)
+	}
+	if got := run(repoPath, "status", "--porcelain"); got != "" {
+		t.Errorf("status after DiscardChanges() = %q, want clean", got)
+	}
+}
+
+func TestParseApplyConflicts(t *testing.T) {
+	stderr := "Applied patch to 'a.txt' with conflicts.\n" +
+		"Applied patch to 'b.txt' cleanly.\n" +
+		"error: new.txt: already exists in working directory\n" +
+		"error: new.txt: patch does not apply\n" +
+		"error: c.txt: patch does not apply\n"
+
+	got := parseApplyConflicts(stderr)
+	if want := []string{"a.txt", "new.txt", "c.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseApplyConflicts() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package gitx
+
+import (
+	"context"
+	"strings"
+)
+
+// Upstream describes the remote branch a local branch tracks
+type Upstream struct {
+	Remote      string // Remote name, e.g. "origin"
+	Branch      string // Branch name on the remote
+	TrackingRef string // Local remote-tracking ref, e.g. "refs/remotes/origin/feature"
+}
+
+// GetUpstream returns the remote branch that branch tracks, or nil if it has no remote upstream
+func GetUpstream(ctx context.Context, branch string) (*Upstream, error) {
+	output, err := RunGit(ctx, "for-each-ref",
+		"--format=%(upstream)%09%(upstream:remotename)%09%(upstream:remoteref)",
+		"refs/heads/"+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseUpstream(output), nil
+}
+
+// parseUpstream parses "<tracking ref>\t<remote>\t<remote ref>" as printed by GetUpstream
+func parseUpstream(output string) *Upstream {
+	fields := strings.Split(strings.TrimSpace(output), "\t")
+	if len(fields) != 3 || fields[0] == "" {
+		return nil
+	}
+	// Branches tracking another local branch have "." as their remote
+	if fields[1] == "" || fields[1] == "." || !strings.HasPrefix(fields[0], "refs/remotes/") {
+		return nil
+	}
+	return &Upstream{
+		Remote:      fields[1],
+		Branch:      strings.TrimPrefix(fields[2], "refs/heads/"),
+		TrackingRef: fields[0],
+	}
+}
+
+// RemoteBranchExists checks whether branch exists on remote (contacts the remote)
+func RemoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	opts := RunOptions{Network: true, Operation: "checking " + remote + "/" + branch}
+	output, err := RunGitWithOptions(ctx, "", opts, "ls-remote", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return false, err
+	}
+	return output != "", nil
+}
+
+// FindRemoteTrackingBranch returns the remote-tracking ref of branch, e.g. "refs/remotes/origin/feature",
+// or "" if no remote has one. "origin" is checked first. Only refs from the last fetch are known,
+// so the remote is not contacted.
+func FindRemoteTrackingBranch(ctx context.Context, branch string) (string, error) {
+	output, err := RunGit(ctx, "remote")
+	if err != nil {
+		return "", err
+	}
+	remotes := strings.Fields(output)
+	for i, remote := range remotes {
+		if remote == "origin" {
+			remotes[0], remotes[i] = remotes[i], remotes[0]
+			break
+		}
+	}
+
+	for _, remote := range remotes {
+		ref := "refs/remotes/" + remote + "/" + branch
+		exists, err := RefExists(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return ref, nil
+		}
+	}
+	return "", nil
+}
+
+// DeleteRemoteTrackingRef deletes a local remote-tracking ref such as "refs/remotes/origin/feature"
+func DeleteRemoteTrackingRef(ctx context.Context, trackingRef string) error {
+	_, err := RunGit(ctx, "branch", "-d", "-r", strings.TrimPrefix(trackingRef, "refs/remotes/"))
+	return err
+}
+
+// Fetch fetches remote, updating its remote-tracking refs
+func Fetch(ctx context.Context, remote string) error {
+	opts := RunOptions{Network: true, Operation: "fetching " + remote}
+	_, err := RunGitWithOptions(ctx, "", opts, "fetch", "--quiet", remote)
+	return err
+}
+
+// PushSetUpstream pushes branch to remote from the worktree at path and makes it the upstream (git push -u)
+func PushSetUpstream(ctx context.Context, path, remote, branch string) error {
+	opts := RunOptions{Network: true, Operation: "pushing " + branch + " to " + remote}
+	_, err := RunGitWithOptions(ctx, path, opts, "push", "--quiet", "--set-upstream", remote, "refs/heads/"+branch+":refs/heads/"+branch)
+	return err
+}
+
+// SetUpstreamConfig makes branch track the branch of the same name on remote without contacting it
+// Unlike 'git branch --set-upstream-to', the remote branch doesn't need to exist yet: the first
+// 'git push' creates it.
+func SetUpstreamConfig(ctx context.Context, branch, remote string) error {
+	if _, err := RunGit(ctx, "config", "branch."+branch+".remote", remote); err != nil {
+		return err
+	}
+	_, err := RunGit(ctx, "config", "branch."+branch+".merge", "refs/heads/"+branch)
+	return err
+}
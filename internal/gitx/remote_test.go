@@ -0,0 +1,123 @@
+package gitx
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestParseUpstream(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *Upstream
+	}{
+		{
+			name:   "remote upstream",
+			output: "refs/remotes/origin/feature/foo\torigin\trefs/heads/feature/foo\n",
+			want:   &Upstream{Remote: "origin", Branch: "feature/foo", TrackingRef: "refs/remotes/origin/feature/foo"},
+		},
+		{
+			name:   "no upstream",
+			output: "\t\t\n",
+			want:   nil,
+		},
+		{
+			name:   "local upstream",
+			output: "refs/heads/main\t.\trefs/heads/main\n",
+			want:   nil,
+		},
+		{
+			name:   "branch not found",
+			output: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUpstream(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseUpstream(%q) = %+v, want %+v", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRemoteTrackingBranch(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	if ref, err := FindRemoteTrackingBranch(ctx, "feature/x"); err != nil || ref != "" {
+		t.Fatalf("FindRemoteTrackingBranch() without remotes = %q, %v, want \"\"", ref, err)
+	}
+
+	// Remote-tracking refs as left by a fetch; origin wins over other remotes
+	run("remote", "add", "fork", "https://example.com/fork.git")
+	run("remote", "add", "origin", "https://example.com/origin.git")
+	run("update-ref", "refs/remotes/fork/feature/x", "HEAD")
+	if ref, err := FindRemoteTrackingBranch(ctx, "feature/x"); err != nil || ref != "refs/remotes/fork/feature/x" {
+		t.Errorf("FindRemoteTrackingBranch() = %q, %v, want refs/remotes/fork/feature/x", ref, err)
+	}
+	run("update-ref", "refs/remotes/origin/feature/x", "HEAD")
+	if ref, err := FindRemoteTrackingBranch(ctx, "feature/x"); err != nil || ref != "refs/remotes/origin/feature/x" {
+		t.Errorf("FindRemoteTrackingBranch() = %q, %v, want refs/remotes/origin/feature/x", ref, err)
+	}
+	if ref, err := FindRemoteTrackingBranch(ctx, "feature"); err != nil || ref != "" {
+		t.Errorf("FindRemoteTrackingBranch(feature) = %q, %v, want \"\"", ref, err)
+	}
+}
+
+func TestPushSetUpstreamAndSetUpstreamConfig(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	remotePath := t.TempDir()
+	run("init", "--bare", "--quiet", remotePath)
+	run("remote", "add", "origin", remotePath)
+	run("branch", "pushed")
+	run("branch", "configured")
+
+	if err := PushSetUpstream(ctx, repoPath, "origin", "pushed"); err != nil {
+		t.Fatalf("PushSetUpstream() returned error: %v", err)
+	}
+	want := &Upstream{Remote: "origin", Branch: "pushed", TrackingRef: "refs/remotes/origin/pushed"}
+	if got, err := GetUpstream(ctx, "pushed"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUpstream(pushed) = %+v, %v, want %+v", got, err, want)
+	}
+
+	// The remote branch doesn't exist yet: git push creates it
+	if err := SetUpstreamConfig(ctx, "configured", "origin"); err != nil {
+		t.Fatalf("SetUpstreamConfig() returned error: %v", err)
+	}
+	want = &Upstream{Remote: "origin", Branch: "configured", TrackingRef: "refs/remotes/origin/configured"}
+	if got, err := GetUpstream(ctx, "configured"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUpstream(configured) = %+v, %v, want %+v", got, err, want)
+	}
+	if exists, _ := RemoteBranchExists(ctx, "origin", "configured"); exists {
+		t.Error("SetUpstreamConfig() should not push the branch")
+	}
+
+	if err := PushSetUpstream(ctx, repoPath, "missing", "pushed"); err == nil {
+		t.Error("PushSetUpstream() to a missing remote should fail")
+	}
+}
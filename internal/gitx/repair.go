@@ -0,0 +1,44 @@
+package gitx
+
+import (
+	"context"
+	"strings"
+)
+
+// RepairedLink describes an administrative link fixed by 'git worktree repair'
+type RepairedLink struct {
+	Problem string // What was wrong, e.g. "gitdir incorrect" or ".git file broken"
+	Path    string // The file or worktree that was fixed
+}
+
+// Repair fixes worktree administrative files after the repository or worktrees were moved
+// With no paths, it repairs the links of the current repository or worktree.
+func Repair(ctx context.Context, paths ...string) ([]RepairedLink, error) {
+	args := append([]string{"worktree", "repair"}, paths...)
+
+	// git reports each fixed link on stderr
+	_, stderr, err := runGit(ctx, "", args...)
+	invalidateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRepairOutput(stderr), nil
+}
+
+// parseRepairOutput parses the "repair: <problem>: <path>" lines printed by 'git worktree repair'
+func parseRepairOutput(output string) []RepairedLink {
+	var links []RepairedLink
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "repair: ")
+		if !ok {
+			continue
+		}
+		problem, path, ok := strings.Cut(rest, ": ")
+		if !ok {
+			continue
+		}
+		links = append(links, RepairedLink{Problem: problem, Path: path})
+	}
+	return links
+}
@@ -0,0 +1,75 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRepairOutput(t *testing.T) {
+	output := "repair: gitdir incorrect: /work/repo/.git/worktrees/feat/gitdir\n" +
+		"repair: .git file broken: /work/.repo-wt/feat\n" +
+		"unrelated line"
+
+	got := parseRepairOutput(output)
+	want := []RepairedLink{
+		{Problem: "gitdir incorrect", Path: "/work/repo/.git/worktrees/feat/gitdir"},
+		{Problem: ".git file broken", Path: "/work/.repo-wt/feat"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRepairOutput() = %+v, want %+v", got, want)
+	}
+
+	if got := parseRepairOutput(""); got != nil {
+		t.Errorf("parseRepairOutput(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatalf("Failed to change to repo directory: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Create a worktree and move its directory behind git's back
+	oldPath := filepath.Join(t.TempDir(), "feat")
+	addCmd := exec.Command("git", "worktree", "add", "-b", "feat", oldPath)
+	addCmd.Dir = repoPath
+	if err := addCmd.Run(); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+	newPath := filepath.Join(t.TempDir(), "feat-moved")
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatalf("Failed to move worktree: %v", err)
+	}
+
+	links, err := Repair(ctx, newPath)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if len(links) != 1 || links[0].Problem != "gitdir incorrect" {
+		t.Errorf("Repair() = %+v, want one gitdir fix", links)
+	}
+
+	// Nothing left to repair
+	links, err = Repair(ctx, newPath)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if len(links) != 0 {
+		t.Errorf("Repair() = %+v, want no fixes", links)
+	}
+}
@@ -9,55 +9,75 @@ import (
 
 // Repo represents repository information
 type Repo struct {
-	Root   string // Absolute path to repository root
-	Name   string // Repository name (directory name)
+	Root   string // Absolute path to repository root (the bare repository directory for bare repositories)
+	Name   string // Repository name (directory name, without ".git" for bare repositories)
 	Parent string // Parent directory of repository root (for sibling placement)
+	IsBare bool   // Whether the main repository is bare (all work happens in linked worktrees)
+}
+
+// getMainWorktree returns the first entry of the worktree list (the main worktree or bare repository)
+func getMainWorktree(ctx context.Context, dir string) (*Worktree, error) {
+	worktrees, err := listWorktrees(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree list: %w", err)
+	}
+	if len(worktrees) == 0 {
+		return nil, fmt.Errorf("could not find main worktree in output")
+	}
+
+	return &worktrees[0], nil
 }
 
 // getMainWorktreeRoot returns the root of the main worktree
 // When called from a worktree, it returns the main repository root, not the worktree path
 func getMainWorktreeRoot(ctx context.Context, dir string) (string, error) {
-	output, err := RunGitInDir(ctx, dir, "worktree", "list", "--porcelain")
+	main, err := getMainWorktree(ctx, dir)
 	if err != nil {
-		return "", fmt.Errorf("failed to get worktree list: %w", err)
-	}
-
-	// Parse the first worktree entry (main worktree)
-	// Format: "worktree /path/to/repo"
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "worktree ") {
-			return strings.TrimPrefix(line, "worktree "), nil
-		}
+		return "", err
 	}
+	return main.Path, nil
+}
 
-	return "", fmt.Errorf("could not find main worktree in output")
+// isBareRepository checks if dir is inside a bare repository
+func isBareRepository(ctx context.Context, dir string) bool {
+	output, err := RunGitInDir(ctx, dir, "rev-parse", "--is-bare-repository")
+	return err == nil && output == "true"
 }
 
 // GetRepo returns repository information for the current or specified directory
 // If called from a worktree, it returns the main repository information
 func GetRepo(ctx context.Context, dir string) (*Repo, error) {
-	// First check if we're in a git repository
+	if repo, ok := cachedRepo(ctx, dir); ok {
+		return repo, nil
+	}
+
+	// First check if we're in a git repository (a bare repository has no working tree)
 	_, err := RunGitInDir(ctx, dir, "rev-parse", "--show-toplevel")
-	if err != nil {
+	if err != nil && !isBareRepository(ctx, dir) {
 		return nil, fmt.Errorf("not in a git repository: %w", err)
 	}
 
-	// Get the main worktree root (works from both main repo and worktrees)
-	root, err := getMainWorktreeRoot(ctx, dir)
+	// Get the main worktree (works from both main repo and worktrees)
+	main, err := getMainWorktree(ctx, dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get main worktree root: %w", err)
 	}
 
+	root := main.Path
 	name := filepath.Base(root)
-	parent := filepath.Dir(root)
+	if main.IsBare {
+		// proj.git -> proj
+		name = strings.TrimSuffix(name, ".git")
+	}
 
-	return &Repo{
+	repo := &Repo{
 		Root:   root,
 		Name:   name,
-		Parent: parent,
-	}, nil
+		Parent: filepath.Dir(root),
+		IsBare: main.IsBare,
+	}
+	storeRepo(ctx, dir, repo)
+	return repo, nil
 }
 
 // IsInsideWorktree checks if the current directory is inside a git worktree
@@ -65,3 +85,26 @@ func IsInsideWorktree(ctx context.Context, dir string) bool {
 	_, err := RunGitInDir(ctx, dir, "rev-parse", "--is-inside-work-tree")
 	return err == nil
 }
+
+// CommonDir returns the absolute path of the git directory shared by all worktrees of the repository
+// (e.g. "<repo>/.git" when called from a linked worktree, the repository itself when it is bare)
+// With a session in ctx, git is run only once per directory.
+func CommonDir(ctx context.Context, dir string) (string, error) {
+	if commonDir, ok := cachedCommonDir(ctx, dir); ok {
+		return commonDir, nil
+	}
+
+	output, err := RunGitInDir(ctx, dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	commonDir := output
+	if !filepath.IsAbs(commonDir) {
+		// A relative path is relative to the directory git ran in
+		if commonDir, err = filepath.Abs(filepath.Join(commandDir(ctx, dir), output)); err != nil {
+			return "", err
+		}
+	}
+	storeCommonDir(ctx, dir, commonDir)
+	return commonDir, nil
+}
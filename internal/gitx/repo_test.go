@@ -4,6 +4,8 @@ import (
 	"context"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -147,3 +149,153 @@ func TestGetRepo(t *testing.T) {
 		}
 	})
 }
+
+func TestGetRepoBare(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	parent := t.TempDir()
+	barePath := filepath.Join(parent, "proj.git")
+
+	cloneCmd := exec.Command("git", "clone", "--bare", repoPath, barePath)
+	if err := cloneCmd.Run(); err != nil {
+		t.Fatalf("Failed to clone bare repository: %v", err)
+	}
+
+	worktreePath := filepath.Join(parent, "feature")
+	addWorktreeCmd := exec.Command("git", "worktree", "add", "-b", "feature", worktreePath)
+	addWorktreeCmd.Dir = barePath
+	if err := addWorktreeCmd.Run(); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	for name, dir := range map[string]string{
+		"from bare repository": barePath,
+		"from linked worktree": worktreePath,
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo, err := GetRepo(ctx, dir)
+			if err != nil {
+				t.Fatalf("GetRepo() error = %v", err)
+			}
+
+			// Resolve symlinks for comparison (macOS /var -> /private/var)
+			rootResolved, _ := filepath.EvalSymlinks(repo.Root)
+			barePathResolved, _ := filepath.EvalSymlinks(barePath)
+			parentResolved, _ := filepath.EvalSymlinks(repo.Parent)
+			wantParentResolved, _ := filepath.EvalSymlinks(parent)
+
+			if !repo.IsBare {
+				t.Errorf("GetRepo().IsBare = false, want true")
+			}
+			if rootResolved != barePathResolved {
+				t.Errorf("GetRepo().Root = %q, want %q", rootResolved, barePathResolved)
+			}
+			if repo.Name != "proj" {
+				t.Errorf("GetRepo().Name = %q, want %q", repo.Name, "proj")
+			}
+			if parentResolved != wantParentResolved {
+				t.Errorf("GetRepo().Parent = %q, want %q", parentResolved, wantParentResolved)
+			}
+		})
+	}
+
+	t.Run("list marks the bare entry", func(t *testing.T) {
+		output, err := RunGitInDir(ctx, worktreePath, "worktree", "list", "--porcelain")
+		if err != nil {
+			t.Fatalf("worktree list error = %v", err)
+		}
+		worktrees, _ := parseWorktreePorcelain(output)
+		if len(worktrees) != 2 || !worktrees[0].IsBare || worktrees[1].IsBare {
+			t.Errorf("parseWorktreePorcelain() = %+v, want bare entry first", worktrees)
+		}
+	})
+}
+
+func TestCommonDir(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	worktreePath := filepath.Join(t.TempDir(), "feature")
+	addWorktreeCmd := exec.Command("git", "worktree", "add", "-b", "feature", worktreePath)
+	addWorktreeCmd.Dir = repoPath
+	if err := addWorktreeCmd.Run(); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	want, _ := filepath.EvalSymlinks(filepath.Join(repoPath, ".git"))
+	for name, dir := range map[string]string{
+		"from main repository": repoPath,
+		"from linked worktree": worktreePath,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := CommonDir(ctx, dir)
+			if err != nil {
+				t.Fatalf("CommonDir() error = %v", err)
+			}
+			gotResolved, _ := filepath.EvalSymlinks(got)
+			if gotResolved != want {
+				t.Errorf("CommonDir() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("outside a repository", func(t *testing.T) {
+		if _, err := CommonDir(ctx, t.TempDir()); err == nil {
+			t.Error("CommonDir() error = nil, want error")
+		}
+	})
+}
+
+func TestGetRepoIgnoresGitDirEnv(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	otherPath, otherCleanup := setupTestRepo(t)
+	defer otherCleanup()
+
+	// A git hook or an IDE pointing git at an unrelated repository
+	t.Setenv("GIT_DIR", filepath.Join(otherPath, ".git"))
+	t.Setenv("GIT_WORK_TREE", otherPath)
+
+	ctx := context.Background()
+	repo, err := GetRepo(ctx, repoPath)
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if !SamePath(repo.Root, repoPath) {
+		t.Errorf("GetRepo() root = %q, want %q (the repository of the directory)", repo.Root, repoPath)
+	}
+	worktrees, err := listWorktrees(ctx, repoPath)
+	if err != nil || len(worktrees) != 1 || !SamePath(worktrees[0].Path, repoPath) {
+		t.Errorf("listWorktrees() = %v, %v, want the worktree of %q", worktrees, err, repoPath)
+	}
+
+	// --respect-git-env lets git follow the environment
+	RespectGitEnv = true
+	defer func() { RespectGitEnv = false }()
+	repo, err = GetRepo(ctx, repoPath)
+	if err != nil {
+		t.Fatalf("GetRepo() with RespectGitEnv error = %v", err)
+	}
+	if !SamePath(repo.Root, otherPath) {
+		t.Errorf("GetRepo() with RespectGitEnv root = %q, want %q", repo.Root, otherPath)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("GIT_DIR", "/elsewhere/.git")
+	t.Setenv("GIT_INDEX_FILE", "/elsewhere/.git/index")
+	t.Setenv("GIT_AUTHOR_NAME", "Someone")
+
+	env := Environ()
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GIT_DIR=") || strings.HasPrefix(kv, "GIT_INDEX_FILE=") {
+			t.Errorf("Environ() contains %q", kv)
+		}
+	}
+	if !slices.Contains(env, "GIT_AUTHOR_NAME=Someone") {
+		t.Error("Environ() should keep other git variables")
+	}
+}
@@ -0,0 +1,469 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockRunner is a mock implementation of Runner for testing
+type mockRunner struct {
+	calls [][]string
+	// outputs maps space-joined git arguments to their stdout
+	outputs map[string]string
+	stderr  string
+	err     error
+}
+
+func (m *mockRunner) Run(ctx context.Context, dir string, args ...string) (string, string, error) {
+	m.calls = append(m.calls, args)
+	if m.err != nil {
+		return "", m.stderr, m.err
+	}
+	return m.outputs[strings.Join(args, " ")], m.stderr, nil
+}
+
+// useMockRunner installs m as the package runner for the duration of the test
+func useMockRunner(t *testing.T, m *mockRunner) {
+	t.Helper()
+	t.Cleanup(SetRunner(m))
+}
+
+const listPorcelainSample = `worktree /work/myproject
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /work/.myproject-wt/feature-login
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/login
+locked on USB drive
+
+worktree /work/.myproject-wt/detached
+HEAD 3333333333333333333333333333333333333333
+detached
+`
+
+func TestRunGitWrapsErrors(t *testing.T) {
+	m := &mockRunner{err: errors.New("exit status 128"), stderr: "fatal: not a git repository\n"}
+	useMockRunner(t, m)
+
+	_, err := RunGit(context.Background(), "status")
+	if err == nil {
+		t.Fatal("RunGit() expected error, got nil")
+	}
+	want := "git status failed: exit status 128: fatal: not a git repository"
+	if err.Error() != want {
+		t.Errorf("RunGit() error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRunGitInDirPassesDirectory(t *testing.T) {
+	var gotDir string
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		gotDir = dir
+		return "  out \n", "", nil
+	})))
+
+	out, err := RunGitInDir(context.Background(), "/some/dir", "status")
+	if err != nil {
+		t.Fatalf("RunGitInDir() error = %v", err)
+	}
+	if gotDir != "/some/dir" {
+		t.Errorf("runner got dir %q, want %q", gotDir, "/some/dir")
+	}
+	if out != "out" {
+		t.Errorf("RunGitInDir() = %q, want trimmed %q", out, "out")
+	}
+}
+
+func TestRunGitUsesWorkDir(t *testing.T) {
+	workDir := filepath.Join(string(filepath.Separator), "work", "repo")
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "current directory", dir: "", want: workDir},
+		{name: "relative", dir: "sub", want: filepath.Join(workDir, "sub")},
+		{name: "absolute", dir: filepath.Join(string(filepath.Separator), "other"), want: filepath.Join(string(filepath.Separator), "other")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotDir string
+			t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+				gotDir = dir
+				return "", "", nil
+			})))
+
+			ctx := WithWorkDir(context.Background(), workDir)
+			if _, err := RunGitInDir(ctx, tt.dir, "status"); err != nil {
+				t.Fatalf("RunGitInDir() error = %v", err)
+			}
+			if gotDir != tt.want {
+				t.Errorf("runner got dir %q, want %q", gotDir, tt.want)
+			}
+
+			abs, err := AbsPath(ctx, tt.dir)
+			if err != nil {
+				t.Fatalf("AbsPath() error = %v", err)
+			}
+			if abs != tt.want {
+				t.Errorf("AbsPath(%q) = %q, want %q", tt.dir, abs, tt.want)
+			}
+		})
+	}
+}
+
+// runnerFunc adapts a function to the Runner interface
+type runnerFunc func(ctx context.Context, dir string, args ...string) (string, string, error)
+
+func (f runnerFunc) Run(ctx context.Context, dir string, args ...string) (string, string, error) {
+	return f(ctx, dir, args...)
+}
+
+func TestListWithMockRunner(t *testing.T) {
+	m := &mockRunner{outputs: map[string]string{"worktree list --porcelain": listPorcelainSample}}
+	useMockRunner(t, m)
+
+	worktrees, err := List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(worktrees) != 3 {
+		t.Fatalf("List() returned %d worktrees, want 3", len(worktrees))
+	}
+
+	if worktrees[0].Path != "/work/myproject" || worktrees[0].Branch != "main" {
+		t.Errorf("worktrees[0] = %+v, want main at /work/myproject", worktrees[0])
+	}
+	if !worktrees[1].IsLocked || worktrees[1].LockReason != "on USB drive" {
+		t.Errorf("worktrees[1] lock = %v %q, want locked with reason", worktrees[1].IsLocked, worktrees[1].LockReason)
+	}
+	if !worktrees[2].IsDetached || worktrees[2].Branch != "" {
+		t.Errorf("worktrees[2] = %+v, want detached without branch", worktrees[2])
+	}
+
+	if len(m.calls) != 1 || strings.Join(m.calls[0], " ") != "worktree list --porcelain" {
+		t.Errorf("runner calls = %v, want one 'worktree list --porcelain'", m.calls)
+	}
+}
+
+func TestFindWorktreeByBranchWithMockRunner(t *testing.T) {
+	useMockRunner(t, &mockRunner{outputs: map[string]string{"worktree list --porcelain": listPorcelainSample}})
+
+	tests := []struct {
+		name     string
+		branch   string
+		wantPath string
+	}{
+		{name: "found", branch: "feature/login", wantPath: "/work/.myproject-wt/feature-login"},
+		{name: "main", branch: "main", wantPath: "/work/myproject"},
+		{name: "not found", branch: "feature/other", wantPath: ""},
+		{name: "sanitized name is not a branch", branch: "feature-login", wantPath: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt, err := FindWorktreeByBranch(context.Background(), tt.branch)
+			if err != nil {
+				t.Fatalf("FindWorktreeByBranch() error = %v", err)
+			}
+			gotPath := ""
+			if wt != nil {
+				gotPath = wt.Path
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("FindWorktreeByBranch(%q) path = %q, want %q", tt.branch, gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestFindWorktreeByBranchError(t *testing.T) {
+	useMockRunner(t, &mockRunner{err: errors.New("exit status 128")})
+
+	if _, err := FindWorktreeByBranch(context.Background(), "main"); err == nil {
+		t.Error("FindWorktreeByBranch() expected error, got nil")
+	}
+}
+
+func TestIsBranchMergedWithMockRunner(t *testing.T) {
+	merged := "* main\n  merged-branch\n+ in-other-worktree\n  feature/done"
+	useMockRunner(t, &mockRunner{outputs: map[string]string{"branch --merged": merged}})
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{branch: "main", want: true},
+		{branch: "merged-branch", want: true},
+		{branch: "in-other-worktree", want: true},
+		{branch: "feature/done", want: true},
+		{branch: "feature", want: false},
+		{branch: "unmerged", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got, err := IsBranchMerged(context.Background(), tt.branch)
+			if err != nil {
+				t.Fatalf("IsBranchMerged() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBranchMerged(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRunnerImplementsRunner(t *testing.T) {
+	var _ Runner = &defaultRunner{}
+}
+
+// exitError simulates *exec.ExitError for a given exit code
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+func (e *exitError) ExitCode() int { return e.code }
+
+func TestRunGitCapturesExitCode(t *testing.T) {
+	useMockRunner(t, &mockRunner{err: &exitError{code: 128}, stderr: "fatal: bad revision"})
+
+	_, err := RunGit(context.Background(), "rev-parse", "nope")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("RunGit() error = %v, want *GitError", err)
+	}
+	if gitErr.ExitCode != 128 || gitErr.Stderr != "fatal: bad revision" {
+		t.Errorf("GitError = %+v, want exit code 128 with stderr", gitErr)
+	}
+	if got := ExitCode(fmt.Errorf("wrapped: %w", err)); got != 128 {
+		t.Errorf("ExitCode() = %d, want 128", got)
+	}
+	if got := ExitCode(errors.New("other")); got != -1 {
+		t.Errorf("ExitCode() for non-git error = %d, want -1", got)
+	}
+}
+
+func TestBranchExistsWithMockRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    bool
+		wantErr bool
+	}{
+		{name: "exists", err: nil, want: true},
+		{name: "not found (exit 1)", err: &exitError{code: 1}, want: false},
+		{name: "not a repository (exit 128)", err: &exitError{code: 128}, wantErr: true},
+		{name: "git not runnable", err: errors.New("executable file not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRunner{err: tt.err}
+			useMockRunner(t, m)
+
+			got, err := BranchExists(context.Background(), "feature")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BranchExists() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BranchExists() = %v, want %v", got, tt.want)
+			}
+			if want := "show-ref --verify --quiet refs/heads/feature"; strings.Join(m.calls[0], " ") != want {
+				t.Errorf("runner call = %v, want %q", m.calls[0], want)
+			}
+		})
+	}
+}
+
+func TestRunGitWithOptionsNetwork(t *testing.T) {
+	origTimeout := NetworkTimeout
+	defer func() { NetworkTimeout = origTimeout }()
+	NetworkTimeout = time.Minute
+
+	var gotEnv []string
+	var gotDeadline bool
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		gotEnv = commandEnv(ctx)
+		_, gotDeadline = ctx.Deadline()
+		return "", "", nil
+	})))
+
+	ctx := context.Background()
+	if _, err := RunGitWithOptions(ctx, "", RunOptions{Network: true}, "fetch", "origin"); err != nil {
+		t.Fatalf("RunGitWithOptions() error = %v", err)
+	}
+	if !gotDeadline {
+		t.Error("network command should run with a deadline")
+	}
+	for _, want := range []string{"GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never"} {
+		if !slices.Contains(gotEnv, want) {
+			t.Errorf("network command env is missing %s", want)
+		}
+	}
+
+	// Local commands get neither a timeout nor a modified environment
+	if _, err := RunGitInDir(ctx, "", "status"); err != nil {
+		t.Fatalf("RunGitInDir() error = %v", err)
+	}
+	if gotDeadline {
+		t.Error("local command should not run with a deadline")
+	}
+	if gotEnv != nil {
+		t.Errorf("local command env = %v, want inherited (nil)", gotEnv)
+	}
+}
+
+func TestRunGitWithOptionsTimeout(t *testing.T) {
+	origTimeout := NetworkTimeout
+	defer func() { NetworkTimeout = origTimeout }()
+	NetworkTimeout = 10 * time.Millisecond
+
+	// Simulate a command that hangs until it is killed
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		<-ctx.Done()
+		return "", "", &exitError{code: -1}
+	})))
+
+	opts := RunOptions{Network: true, Operation: "fetching the PR branch"}
+	_, err := RunGitWithOptions(context.Background(), "", opts, "fetch", "origin")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("RunGitWithOptions() error = %v, want *TimeoutError", err)
+	}
+	if !strings.Contains(err.Error(), "fetching the PR branch timed out") {
+		t.Errorf("error = %q, want it to name the operation", err.Error())
+	}
+}
+
+func TestRunGitLocalTimeout(t *testing.T) {
+	origTimeout := LocalTimeout
+	defer func() { LocalTimeout = origTimeout }()
+	LocalTimeout = 10 * time.Millisecond
+
+	// Simulate a command hanging on an unresponsive filesystem
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		<-ctx.Done()
+		return "", "", &exitError{code: -1}
+	})))
+
+	_, err := RunGit(context.Background(), "worktree", "list", "--porcelain")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || !timeoutErr.Local {
+		t.Fatalf("RunGit() error = %v, want a local *TimeoutError", err)
+	}
+	if !strings.Contains(err.Error(), "performance.git_timeout") {
+		t.Errorf("error = %q, want it to name the setting", err.Error())
+	}
+}
+
+func TestRunGitWithProgressUsesRunner(t *testing.T) {
+	origTimeout := LocalTimeout
+	defer func() { LocalTimeout = origTimeout }()
+	LocalTimeout = 10 * time.Millisecond
+
+	useMockRunner(t, &mockRunner{err: &exitError{code: 128}, stderr: "fatal: invalid reference: nope"})
+	_, err := RunGitWithProgress(context.Background(), "", os.Stderr, "worktree", "add", "/tmp/x", "nope")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) || gitErr.Stderr != "fatal: invalid reference: nope" {
+		t.Errorf("RunGitWithProgress() error = %v, want a GitError with git's message", err)
+	}
+
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		<-ctx.Done()
+		return "", "", &exitError{code: -1}
+	})))
+	_, err = RunGitWithProgress(context.Background(), "", os.Stderr, "worktree", "add", "/tmp/x")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || !timeoutErr.Local {
+		t.Errorf("RunGitWithProgress() error = %v, want a local *TimeoutError", err)
+	}
+}
+
+func TestAddWithProgressStreamsToRunner(t *testing.T) {
+	// A streaming runner gets the progress writer as is, so that git can tell a terminal
+	var calls [][]string
+	var gotStderr io.Writer
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		calls = append(calls, args)
+		stream, _ := ctx.Value(streamKey{}).(streamWriters)
+		gotStderr = stream.stderr
+		if stream.stderr != nil {
+			fmt.Fprint(stream.stderr, "Preparing worktree (new branch 'feature')\n")
+		}
+		return "", "", nil
+	})))
+
+	progress, err := os.CreateTemp(t.TempDir(), "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer progress.Close()
+	if err := AddWithProgress(context.Background(), "/work/feature", "feature", "", true, progress); err != nil {
+		t.Fatalf("AddWithProgress() error = %v", err)
+	}
+	want := []string{"worktree", "add", "-b", "feature", "/work/feature"}
+	if len(calls) != 1 || strings.Join(calls[0], " ") != strings.Join(want, " ") {
+		t.Errorf("git calls = %v, want [%v]", calls, want)
+	}
+	if gotStderr != io.Writer(progress) {
+		t.Errorf("runner stderr = %T, want the progress file itself", gotStderr)
+	}
+	data, err := os.ReadFile(progress.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Preparing worktree") {
+		t.Errorf("progress = %q, want git's output", data)
+	}
+}
+
+func TestRunGitWithProgressPassesStderrThrough(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := RunGitInDir(context.Background(), dir, "init"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+
+	var progress bytes.Buffer
+	_, err := RunGitWithProgress(context.Background(), dir, &progress, "rev-parse", "--verify", "no-such-ref")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) || gitErr.Stderr == "" {
+		t.Fatalf("RunGitWithProgress() error = %v, want a GitError with git's message", err)
+	}
+	if !strings.Contains(progress.String(), gitErr.Stderr) {
+		t.Errorf("progress = %q, want git's message %q", progress.String(), gitErr.Stderr)
+	}
+
+	// A file is git's stderr itself
+	f, err := os.CreateTemp(t.TempDir(), "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := RunGitWithProgress(context.Background(), dir, f, "rev-parse", "--verify", "no-such-ref"); !errors.As(err, &gitErr) {
+		t.Fatalf("RunGitWithProgress() error = %v, want a GitError", err)
+	}
+	if data, _ := os.ReadFile(f.Name()); !strings.Contains(string(data), "fatal:") {
+		t.Errorf("progress file = %q, want git's message", data)
+	}
+
+	var out bytes.Buffer
+	if err := RunGitStreaming(context.Background(), dir, &out, RunOptions{}, "rev-parse", "--git-dir"); err != nil {
+		t.Fatalf("RunGitStreaming() error = %v", err)
+	}
+	if strings.TrimSpace(out.String()) != ".git" {
+		t.Errorf("RunGitStreaming() output = %q, want .git", out.String())
+	}
+}
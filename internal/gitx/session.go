@@ -0,0 +1,168 @@
+package gitx
+
+import (
+	"context"
+	"sync"
+)
+
+type sessionKey struct{}
+
+// Session memoizes the worktree list and repository information for a single command invocation
+// Attach it to a context with WithSession: List and GetRepo then reuse earlier results instead of
+// running git again, and operations that change worktrees (Add, Remove, Move, ...) invalidate them.
+type Session struct {
+	mu        sync.Mutex
+	worktrees map[string][]Worktree // keyed by the directory git was run in
+	repos     map[string]*Repo
+	// The repository GetRepo resolved last; not invalidated: worktree changes don't move the repository
+	lastRepo *Repo
+	version  *Version // Not invalidated: the installed git doesn't change during a command
+	// Keyed by the directory git was run in; not invalidated: worktree changes don't move the git directory
+	commonDirs map[string]string
+}
+
+// NewSession creates an empty session
+func NewSession() *Session {
+	return &Session{
+		worktrees:  make(map[string][]Worktree),
+		repos:      make(map[string]*Repo),
+		commonDirs: make(map[string]string),
+	}
+}
+
+// WithSession returns a copy of ctx carrying s
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// sessionFrom returns the session attached to ctx, or nil if there is none
+func sessionFrom(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// Invalidate discards all cached results
+func (s *Session) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.worktrees = make(map[string][]Worktree)
+	s.repos = make(map[string]*Repo)
+}
+
+// invalidateSession discards the results cached in ctx's session, if any, and the worktree list
+// cached on disk (see ListCacheDir)
+func invalidateSession(ctx context.Context) {
+	if s := sessionFrom(ctx); s != nil {
+		s.Invalidate()
+	}
+	invalidateListCache(ctx)
+}
+
+// listWorktrees returns the worktrees as seen from dir, using ctx's session if present
+// and the list cached on disk if enabled (see ListCacheDir)
+func listWorktrees(ctx context.Context, dir string) ([]Worktree, error) {
+	s := sessionFrom(ctx)
+	if s != nil {
+		s.mu.Lock()
+		cached, ok := s.worktrees[dir]
+		s.mu.Unlock()
+		if ok {
+			// Return a copy so callers can't modify the cache
+			return append([]Worktree(nil), cached...), nil
+		}
+	}
+
+	var worktrees []Worktree
+	cached := false
+	cacheKey := newListCacheKey(ctx, dir)
+	if cacheKey != nil {
+		worktrees, cached = cacheKey.load()
+	}
+	if !cached {
+		output, err := RunGitInDir(ctx, dir, "worktree", "list", "--porcelain")
+		if err != nil {
+			return nil, err
+		}
+		if worktrees, err = parseWorktreePorcelain(output); err != nil {
+			return nil, err
+		}
+		if cacheKey != nil {
+			cacheKey.store(worktrees)
+		}
+	}
+
+	if s != nil {
+		s.mu.Lock()
+		s.worktrees[dir] = append([]Worktree(nil), worktrees...)
+		s.mu.Unlock()
+	}
+	return worktrees, nil
+}
+
+// cachedRepo returns the repository information cached for dir in ctx's session
+func cachedRepo(ctx context.Context, dir string) (*Repo, bool) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, ok := s.repos[dir]
+	if !ok {
+		return nil, false
+	}
+	copied := *repo
+	return &copied, true
+}
+
+// storeRepo caches repository information for dir in ctx's session
+func storeRepo(ctx context.Context, dir string, repo *Repo) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return
+	}
+	copied := *repo
+	s.mu.Lock()
+	s.repos[dir] = &copied
+	s.lastRepo = &copied
+	s.mu.Unlock()
+}
+
+// SessionRepo returns the repository GetRepo resolved last with ctx's session, without running git
+// It reports false when there is no session or the command didn't need its repository.
+func SessionRepo(ctx context.Context) (*Repo, bool) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastRepo == nil {
+		return nil, false
+	}
+	copied := *s.lastRepo
+	return &copied, true
+}
+
+// cachedCommonDir returns the git common directory cached for dir in ctx's session
+func cachedCommonDir(ctx context.Context, dir string) (string, bool) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	commonDir, ok := s.commonDirs[commandDir(ctx, dir)]
+	return commonDir, ok
+}
+
+// storeCommonDir caches the git common directory of dir in ctx's session
+func storeCommonDir(ctx context.Context, dir, commonDir string) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.commonDirs[commandDir(ctx, dir)] = commonDir
+	s.mu.Unlock()
+}
@@ -0,0 +1,201 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countCalls returns how many runner calls had the given space-joined arguments
+func countCalls(m *mockRunner, args string) int {
+	n := 0
+	for _, call := range m.calls {
+		if strings.Join(call, " ") == args {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSessionCachesWorktreeList(t *testing.T) {
+	m := &mockRunner{outputs: map[string]string{
+		"worktree list --porcelain": listPorcelainSample,
+		"rev-parse --show-toplevel": "/work/myproject",
+	}}
+	useMockRunner(t, m)
+
+	// Without a session, every lookup runs git
+	ctx := context.Background()
+	if _, err := List(ctx); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if _, err := FindWorktreeByBranch(ctx, "main"); err != nil {
+		t.Fatalf("FindWorktreeByBranch() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 2 {
+		t.Errorf("without session: worktree list ran %d times, want 2", got)
+	}
+
+	// With a session, the list is read once, including by GetRepo
+	m.calls = nil
+	ctx = WithSession(context.Background(), NewSession())
+	if _, err := GetRepo(ctx, ""); err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if _, err := List(ctx); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if _, err := FindWorktreeByBranch(ctx, "feature/login"); err != nil {
+		t.Fatalf("FindWorktreeByBranch() error = %v", err)
+	}
+	if _, err := IsUsingBranch(ctx, "main", ""); err != nil {
+		t.Fatalf("IsUsingBranch() error = %v", err)
+	}
+	if _, err := GetRepo(ctx, ""); err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 1 {
+		t.Errorf("with session: worktree list ran %d times, want 1", got)
+	}
+	if got := countCalls(m, "rev-parse --show-toplevel"); got != 1 {
+		t.Errorf("with session: rev-parse ran %d times, want 1", got)
+	}
+
+	// Changing worktrees invalidates the cache
+	if err := Remove(ctx, "/work/.myproject-wt/feature-login", false); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := List(ctx); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 2 {
+		t.Errorf("after Remove: worktree list ran %d times, want 2", got)
+	}
+}
+
+func TestSessionRepo(t *testing.T) {
+	m := &mockRunner{outputs: map[string]string{
+		"worktree list --porcelain": listPorcelainSample,
+		"rev-parse --show-toplevel": "/work/myproject",
+	}}
+	useMockRunner(t, m)
+
+	if _, ok := SessionRepo(context.Background()); ok {
+		t.Error("SessionRepo() without a session reported a repository")
+	}
+	session := NewSession()
+	ctx := WithSession(context.Background(), session)
+	if _, ok := SessionRepo(ctx); ok {
+		t.Error("SessionRepo() before GetRepo reported a repository")
+	}
+	if _, err := GetRepo(ctx, ""); err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+
+	// Still known after worktree changes, without running git again
+	session.Invalidate()
+	calls := len(m.calls)
+	repo, ok := SessionRepo(ctx)
+	if !ok || repo.Root != "/work/myproject" {
+		t.Errorf("SessionRepo() = %v, %v, want /work/myproject", repo, ok)
+	}
+	if len(m.calls) != calls {
+		t.Errorf("SessionRepo() ran git: %v", m.calls[calls:])
+	}
+}
+
+func TestSessionListReturnsCopy(t *testing.T) {
+	useMockRunner(t, &mockRunner{outputs: map[string]string{"worktree list --porcelain": listPorcelainSample}})
+	ctx := WithSession(context.Background(), NewSession())
+
+	first, err := List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	first[0].Branch = "modified"
+
+	second, err := List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if second[0].Branch != "main" {
+		t.Errorf("cached list was modified by caller: Branch = %q", second[0].Branch)
+	}
+}
+
+func TestListCache(t *testing.T) {
+	commonDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(commonDir, "worktrees"), 0755); err != nil {
+		t.Fatalf("Failed to create worktrees directory: %v", err)
+	}
+	m := &mockRunner{outputs: map[string]string{
+		"worktree list --porcelain":  listPorcelainSample,
+		"rev-parse --git-common-dir": commonDir,
+	}}
+	useMockRunner(t, m)
+	origDir := ListCacheDir
+	t.Cleanup(func() { ListCacheDir = origDir })
+	ListCacheDir = t.TempDir()
+
+	// Separate commands (no shared session) read the list from git once
+	for i := 0; i < 2; i++ {
+		if _, err := List(WithSession(context.Background(), NewSession())); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 1 {
+		t.Errorf("worktree list ran %d times, want 1 (cached)", got)
+	}
+
+	// Within a command, a cache hit doesn't run git again for the common directory
+	before := countCalls(m, "rev-parse --git-common-dir")
+	session := NewSession()
+	ctx := WithSession(context.Background(), session)
+	for i := 0; i < 2; i++ {
+		session.Invalidate()
+		if _, err := List(ctx); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+	}
+	if got := countCalls(m, "rev-parse --git-common-dir") - before; got != 1 {
+		t.Errorf("rev-parse --git-common-dir ran %d times in one session, want 1", got)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 1 {
+		t.Errorf("worktree list ran %d times, want 1 (cached)", got)
+	}
+
+	// Adding or removing a worktree changes the modification time of <common dir>/worktrees
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(filepath.Join(commonDir, "worktrees"), later, later); err != nil {
+		t.Fatalf("Failed to change modification time: %v", err)
+	}
+	if _, err := List(context.Background()); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 2 {
+		t.Errorf("worktree list ran %d times, want 2 after the worktrees changed", got)
+	}
+
+	// wt's own changes remove the cached list
+	if err := Remove(context.Background(), "/work/.myproject-wt/feature-login", false); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := List(context.Background()); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 3 {
+		t.Errorf("worktree list ran %d times, want 3 after Remove", got)
+	}
+
+	// Without a cache directory, nothing is cached
+	ListCacheDir = ""
+	if _, err := List(context.Background()); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := countCalls(m, "worktree list --porcelain"); got != 4 {
+		t.Errorf("worktree list ran %d times, want 4 without caching", got)
+	}
+}
@@ -0,0 +1,49 @@
+package gitx
+
+import (
+	"context"
+	"strings"
+)
+
+// Stash is an entry of the stash list
+type Stash struct {
+	Ref     string // e.g. "stash@{0}"; changes as entries are added and dropped
+	Commit  string // Commit hash of the stash entry
+	Base    string // Commit hash the stash was made on
+	Message string // e.g. "WIP on main: 0123abc Add login"
+}
+
+// ListStashes returns the stash entries of the repository, the latest first
+func ListStashes(ctx context.Context) ([]Stash, error) {
+	output, err := RunGit(ctx, "stash", "list", "--format=%gd%x00%H%x00%P%x00%gs")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		// The first parent is the commit the stash was made on; the others hold the index and untracked files
+		base, _, _ := strings.Cut(fields[2], " ")
+		stashes = append(stashes, Stash{Ref: fields[0], Commit: fields[1], Base: base, Message: fields[3]})
+	}
+	return stashes, nil
+}
+
+// ApplyStash applies the stash entry commit to the worktree at path, restoring staged changes as staged
+func ApplyStash(ctx context.Context, path, commit string) error {
+	_, err := RunGitInDir(ctx, path, "stash", "apply", "--index", commit)
+	return err
+}
+
+// DropStash removes the stash entry ref (e.g. "stash@{1}") from the stash list
+func DropStash(ctx context.Context, ref string) error {
+	_, err := RunGit(ctx, "stash", "drop", "--quiet", ref)
+	return err
+}
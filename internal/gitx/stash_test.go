@@ -0,0 +1,75 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListApplyDropStashes(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+
+	stashes, err := ListStashes(ctx)
+	if err != nil || len(stashes) != 0 {
+		t.Fatalf("ListStashes() = %v, %v, want no entries", stashes, err)
+	}
+
+	base := run("rev-parse", "HEAD")
+	readme := filepath.Join(repoPath, "README.md")
+	if err := os.WriteFile(readme, []byte("# Changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README: %v", err)
+	}
+	run("stash", "push", "--quiet", "-m", "first change")
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	run("stash", "push", "--quiet", "--include-untracked", "-m", "untracked file")
+
+	stashes, err = ListStashes(ctx)
+	if err != nil {
+		t.Fatalf("ListStashes() returned error: %v", err)
+	}
+	if len(stashes) != 2 {
+		t.Fatalf("ListStashes() returned %d entries, want 2: %+v", len(stashes), stashes)
+	}
+	if stashes[0].Ref != "stash@{0}" || !strings.HasSuffix(stashes[0].Message, ": untracked file") {
+		t.Errorf("stashes[0] = %+v, want the latest entry first", stashes[0])
+	}
+	for _, s := range stashes {
+		if s.Base != strings.TrimSpace(base) {
+			t.Errorf("%s base = %q, want %q", s.Ref, s.Base, base)
+		}
+	}
+
+	// The untracked file comes back with the stash
+	if err := ApplyStash(ctx, repoPath, stashes[0].Commit); err != nil {
+		t.Fatalf("ApplyStash() returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "new.txt")); err != nil {
+		t.Errorf("untracked file was not restored: %v", err)
+	}
+
+	if err := DropStash(ctx, "stash@{0}"); err != nil {
+		t.Fatalf("DropStash() returned error: %v", err)
+	}
+	stashes, err = ListStashes(ctx)
+	if err != nil || len(stashes) != 1 || !strings.HasSuffix(stashes[0].Message, ": first change") {
+		t.Errorf("ListStashes() after drop = %+v, %v, want only the first change", stashes, err)
+	}
+}
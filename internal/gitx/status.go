@@ -0,0 +1,108 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status summarizes the uncommitted changes in a worktree
+type Status struct {
+	Modified  int // Tracked files with staged or unstaged changes
+	Untracked int // Untracked files (ignored files are not counted)
+}
+
+// IsDirty reports whether there are any uncommitted changes
+func (s Status) IsDirty() bool {
+	return s.Modified > 0 || s.Untracked > 0
+}
+
+// Total returns the number of changed files
+func (s Status) Total() int {
+	return s.Modified + s.Untracked
+}
+
+// GetStatus returns counts of modified and untracked files in the worktree at path
+func GetStatus(ctx context.Context, path string) (Status, error) {
+	output, err := RunGitInDir(ctx, path, "status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return Status{}, err
+	}
+
+	return parseStatusPorcelain(output), nil
+}
+
+// parseStatusPorcelain parses the output of 'git status --porcelain'
+func parseStatusPorcelain(output string) Status {
+	var status Status
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "??") {
+			status.Untracked++
+		} else {
+			status.Modified++
+		}
+	}
+	return status
+}
+
+// IsDirty reports whether the worktree at path has uncommitted changes
+// Returns false if the status cannot be determined.
+func IsDirty(ctx context.Context, path string) bool {
+	status, err := GetStatus(ctx, path)
+	if err != nil {
+		return false
+	}
+	return status.IsDirty()
+}
+
+// AheadBehind returns how many commits HEAD of the worktree at path is ahead of and behind its upstream
+// hasUpstream is false (with a nil error) when the branch has no upstream or HEAD is detached.
+func AheadBehind(ctx context.Context, path string) (ahead, behind int, hasUpstream bool, err error) {
+	if _, err := RunGitInDir(ctx, path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err != nil {
+		return 0, 0, false, nil
+	}
+
+	output, err := RunGitInDir(ctx, path, "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
+	if err != nil {
+		return 0, 0, true, err
+	}
+
+	behind, ahead, err = parseLeftRightCount(output)
+	if err != nil {
+		return 0, 0, true, err
+	}
+	return ahead, behind, true, nil
+}
+
+// LastCommitTime returns the committer date of HEAD in the worktree at path
+func LastCommitTime(ctx context.Context, path string) (time.Time, error) {
+	output, err := RunGitInDir(ctx, path, "log", "-1", "--format=%ct", "HEAD")
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected git log output: %q", output)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// parseLeftRightCount parses the output of 'git rev-list --left-right --count' ("<left>\t<right>")
+func parseLeftRightCount(output string) (left, right int, err error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	if left, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	if right, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	return left, right, nil
+}
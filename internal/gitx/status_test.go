@@ -0,0 +1,147 @@
+package gitx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetStatus(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, repoPath string)
+		want      Status
+		wantDirty bool
+	}{
+		{
+			name:  "clean",
+			setup: func(t *testing.T, repoPath string) {},
+			want:  Status{},
+		},
+		{
+			name: "modified tracked file",
+			setup: func(t *testing.T, repoPath string) {
+				if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+					t.Fatalf("Failed to modify README: %v", err)
+				}
+			},
+			want:      Status{Modified: 1},
+			wantDirty: true,
+		},
+		{
+			name: "untracked only",
+			setup: func(t *testing.T, repoPath string) {
+				for _, name := range []string{"a.txt", "b.txt"} {
+					if err := os.WriteFile(filepath.Join(repoPath, name), []byte("new\n"), 0644); err != nil {
+						t.Fatalf("Failed to create %s: %v", name, err)
+					}
+				}
+			},
+			want:      Status{Untracked: 2},
+			wantDirty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath, cleanup := setupTestRepo(t)
+			defer cleanup()
+
+			tt.setup(t, repoPath)
+
+			got, err := GetStatus(ctx, repoPath)
+			if err != nil {
+				t.Fatalf("GetStatus() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetStatus() = %+v, want %+v", got, tt.want)
+			}
+			if dirty := IsDirty(ctx, repoPath); dirty != tt.wantDirty {
+				t.Errorf("IsDirty() = %v, want %v", dirty, tt.wantDirty)
+			}
+		})
+	}
+}
+
+func TestParseStatusPorcelain(t *testing.T) {
+	// The leading space of the first line is trimmed by RunGit
+	output := "M README.md\nM  staged.go\n?? new.txt\nR  old -> new"
+
+	got := parseStatusPorcelain(output)
+	want := Status{Modified: 3, Untracked: 1}
+	if got != want {
+		t.Errorf("parseStatusPorcelain() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	ctx := context.Background()
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(dir, file string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+		run(dir, "add", file)
+		run(dir, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-m", "Add "+file)
+	}
+	check := func(wantAhead, wantBehind int, wantUpstream bool) {
+		t.Helper()
+		ahead, behind, hasUpstream, err := AheadBehind(ctx, repoPath)
+		if err != nil {
+			t.Fatalf("AheadBehind() error = %v", err)
+		}
+		if ahead != wantAhead || behind != wantBehind || hasUpstream != wantUpstream {
+			t.Errorf("AheadBehind() = %d, %d, %v, want %d, %d, %v",
+				ahead, behind, hasUpstream, wantAhead, wantBehind, wantUpstream)
+		}
+	}
+
+	// No upstream yet
+	check(0, 0, false)
+
+	// Push to a bare remote and track it
+	remotePath := filepath.Join(t.TempDir(), "remote.git")
+	run(repoPath, "init", "--bare", remotePath)
+	run(repoPath, "remote", "add", "origin", remotePath)
+	run(repoPath, "push", "-u", "origin", "HEAD")
+	check(0, 0, true)
+
+	// Local commit: ahead by one
+	commit(repoPath, "local.txt")
+	check(1, 0, true)
+
+	// Someone else pushes two commits: behind by two after fetching
+	otherPath := filepath.Join(t.TempDir(), "other")
+	run(repoPath, "clone", remotePath, otherPath)
+	commit(otherPath, "other1.txt")
+	commit(otherPath, "other2.txt")
+	run(otherPath, "push", "origin", "HEAD")
+	run(repoPath, "fetch", "origin")
+	check(1, 2, true)
+}
+
+func TestParseLeftRightCount(t *testing.T) {
+	left, right, err := parseLeftRightCount("3\t5")
+	if err != nil || left != 3 || right != 5 {
+		t.Errorf("parseLeftRightCount() = %d, %d, %v, want 3, 5, nil", left, right, err)
+	}
+
+	if _, _, err := parseLeftRightCount("garbage"); err == nil {
+		t.Errorf("parseLeftRightCount() error = nil, want error")
+	}
+}
@@ -0,0 +1,45 @@
+package gitx
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// HasSubmodules reports whether the worktree at path declares submodules (.gitmodules)
+func HasSubmodules(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".gitmodules"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// UpdateSubmodules initializes and updates submodules recursively in the worktree at path
+// Progress is streamed to w.
+func UpdateSubmodules(ctx context.Context, path string, w io.Writer) error {
+	opts := RunOptions{Network: true, Operation: "git submodule update"}
+	return RunGitStreaming(ctx, path, w, opts, "submodule", "update", "--init", "--recursive")
+}
+
+// UsesLFS reports whether the worktree at path tracks files with Git LFS
+// It looks for an LFS filter in the top-level .gitattributes.
+func UsesLFS(path string) bool {
+	data, err := os.ReadFile(filepath.Join(path, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "filter=lfs")
+}
+
+// IsLFSAvailable checks if the git-lfs command is available
+func IsLFSAvailable() bool {
+	_, err := exec.LookPath("git-lfs")
+	return err == nil
+}
+
+// LFSPull downloads LFS objects for the checked-out files in the worktree at path
+// Progress is streamed to w.
+func LFSPull(ctx context.Context, path string, w io.Writer) error {
+	return RunGitStreaming(ctx, path, w, RunOptions{Network: true, Operation: "git lfs pull"}, "lfs", "pull")
+}
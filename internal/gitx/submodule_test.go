@@ -0,0 +1,82 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasSubmodulesAndUsesLFS(t *testing.T) {
+	dir := t.TempDir()
+
+	if HasSubmodules(dir) {
+		t.Error("HasSubmodules() = true for a directory without .gitmodules")
+	}
+	if UsesLFS(dir) {
+		t.Error("UsesLFS() = true for a directory without .gitattributes")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte("[submodule \"lib\"]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitmodules: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.txt text\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	if !HasSubmodules(dir) {
+		t.Error("HasSubmodules() = false, want true")
+	}
+	if UsesLFS(dir) {
+		t.Error("UsesLFS() = true for .gitattributes without an LFS filter")
+	}
+
+	attrs := "*.psd filter=lfs diff=lfs merge=lfs -text\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(attrs), 0644); err != nil {
+		t.Fatalf("Failed to write .gitattributes: %v", err)
+	}
+	if !UsesLFS(dir) {
+		t.Error("UsesLFS() = false, want true")
+	}
+}
+
+func TestUpdateSubmodules(t *testing.T) {
+	// Local file:// submodules are blocked by default since git 2.38.1
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	libPath, _ := setupTestRepo(t)
+	repoPath, _ := setupTestRepo(t)
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	run(repoPath, "submodule", "add", libPath, "lib")
+	run(repoPath, "commit", "-m", "Add submodule")
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	run(repoPath, "worktree", "add", "-b", "feature", wtPath)
+
+	if _, err := os.Stat(filepath.Join(wtPath, "lib", "README.md")); err == nil {
+		t.Fatal("submodule should not be checked out before UpdateSubmodules()")
+	}
+
+	var out bytes.Buffer
+	if err := UpdateSubmodules(context.Background(), wtPath, &out); err != nil {
+		t.Fatalf("UpdateSubmodules() error = %v\n%s", err, out.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(wtPath, "lib", "README.md")); err != nil {
+		t.Errorf("submodule not checked out after UpdateSubmodules(): %v", err)
+	}
+	if out.Len() == 0 {
+		t.Error("UpdateSubmodules() streamed no progress output")
+	}
+}
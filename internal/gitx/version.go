@@ -0,0 +1,122 @@
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Feature is a git feature wt relies on, with the git version that introduced it
+type Feature struct {
+	Name    string
+	Version Version
+}
+
+// Git features that older versions lack. Commands check the ones they use with RequireVersion.
+var (
+	// FeatureWorktree is git worktree itself: the baseline every command needs
+	FeatureWorktree       = Feature{Name: "git worktree", Version: Version{Major: 2, Minor: 5}}
+	FeatureWorktreeLock   = Feature{Name: "git worktree lock", Version: Version{Major: 2, Minor: 10}}
+	FeatureWorktreeMove   = Feature{Name: "git worktree move", Version: Version{Major: 2, Minor: 17}}
+	FeatureWorktreeRemove = Feature{Name: "git worktree remove", Version: Version{Major: 2, Minor: 17}}
+	FeatureWorktreeRepair = Feature{Name: "git worktree repair", Version: Version{Major: 2, Minor: 29}}
+)
+
+// Features lists the git features above, oldest first
+var Features = []Feature{FeatureWorktree, FeatureWorktreeLock, FeatureWorktreeMove, FeatureWorktreeRemove, FeatureWorktreeRepair}
+
+// MinGitVersion is the oldest git version that supports all worktree features wt uses
+// (git worktree repair was added in 2.29)
+var MinGitVersion = FeatureWorktreeRepair.Version
+
+// MinVersionError is returned when the installed git is too old for a feature
+type MinVersionError struct {
+	Feature   string
+	Required  Version
+	Installed Version
+}
+
+func (e *MinVersionError) Error() string {
+	return fmt.Sprintf("%s requires git %s or later (installed: %s); please upgrade git", e.Feature, e.Required, e.Installed)
+}
+
+// Version represents a git version number
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is greater than or equal to other
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+var versionRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion parses the output of 'git --version' (e.g. "git version 2.39.2 (Apple Git-143)")
+func ParseVersion(output string) (Version, error) {
+	m := versionRegex.FindStringSubmatch(output)
+	if m == nil {
+		return Version{}, fmt.Errorf("could not parse git version from %q", output)
+	}
+
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// GetVersion returns the installed git version
+// With a session (see WithSession), git --version runs at most once per command.
+func GetVersion(ctx context.Context) (Version, error) {
+	s := sessionFrom(ctx)
+	if s != nil {
+		s.mu.Lock()
+		cached := s.version
+		s.mu.Unlock()
+		if cached != nil {
+			return *cached, nil
+		}
+	}
+
+	output, err := RunGit(ctx, "--version")
+	if err != nil {
+		return Version{}, err
+	}
+	v, err := ParseVersion(output)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if s != nil {
+		s.mu.Lock()
+		s.version = &v
+		s.mu.Unlock()
+	}
+	return v, nil
+}
+
+// RequireVersion returns a MinVersionError if the installed git is older than feature requires
+// If the version can't be determined, it returns nil and leaves it to git to report the problem.
+func RequireVersion(ctx context.Context, feature Feature) error {
+	v, err := GetVersion(ctx)
+	if err != nil || v.AtLeast(feature.Version) {
+		return nil
+	}
+	return &MinVersionError{Feature: feature.Name, Required: feature.Version, Installed: v}
+}
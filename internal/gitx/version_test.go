@@ -0,0 +1,139 @@
+package gitx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    Version
+		wantErr bool
+	}{
+		{
+			name:   "linux",
+			output: "git version 2.43.0",
+			want:   Version{Major: 2, Minor: 43, Patch: 0},
+		},
+		{
+			name:   "macOS",
+			output: "git version 2.39.2 (Apple Git-143)",
+			want:   Version{Major: 2, Minor: 39, Patch: 2},
+		},
+		{
+			name:   "windows",
+			output: "git version 2.42.0.windows.2",
+			want:   Version{Major: 2, Minor: 42, Patch: 0},
+		},
+		{
+			name:   "two components",
+			output: "git version 2.17",
+			want:   Version{Major: 2, Minor: 17},
+		},
+		{
+			name:   "Xcode",
+			output: "git version 2.39.3 (Apple Git-146)\n",
+			want:   Version{Major: 2, Minor: 39, Patch: 3},
+		},
+		{
+			name:   "VFS for Git",
+			output: "git version 2.45.2.vfs.0.0",
+			want:   Version{Major: 2, Minor: 45, Patch: 2},
+		},
+		{
+			name:   "release candidate",
+			output: "git version 2.48.0-rc1",
+			want:   Version{Major: 2, Minor: 48, Patch: 0},
+		},
+		{
+			name:   "ancient",
+			output: "git version 1.8.3.1",
+			want:   Version{Major: 1, Minor: 8, Patch: 3},
+		},
+		{
+			name:    "garbage",
+			output:  "not git",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		v, other Version
+		want     bool
+	}{
+		{Version{2, 17, 0}, Version{2, 17, 0}, true},
+		{Version{2, 16, 9}, Version{2, 17, 0}, false},
+		{Version{3, 0, 0}, Version{2, 17, 0}, true},
+		{Version{2, 17, 1}, Version{2, 17, 2}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.AtLeast(tt.other); got != tt.want {
+			t.Errorf("%v.AtLeast(%v) = %v, want %v", tt.v, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	v, err := GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if v.Major < 2 {
+		t.Errorf("GetVersion() = %v, want major version >= 2", v)
+	}
+}
+
+func TestRequireVersion(t *testing.T) {
+	calls := 0
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		calls++
+		return "git version 2.25.1 (Apple Git-128)", "", nil
+	})))
+	ctx := WithSession(context.Background(), NewSession())
+
+	if err := RequireVersion(ctx, FeatureWorktreeMove); err != nil {
+		t.Errorf("RequireVersion(move) = %v, want nil for git 2.25.1", err)
+	}
+
+	err := RequireVersion(ctx, FeatureWorktreeRepair)
+	var minErr *MinVersionError
+	if !errors.As(err, &minErr) {
+		t.Fatalf("RequireVersion(repair) = %v, want a MinVersionError", err)
+	}
+	want := "git worktree repair requires git 2.29.0 or later (installed: 2.25.1); please upgrade git"
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+	if calls != 1 {
+		t.Errorf("git --version ran %d times, want once per session", calls)
+	}
+}
+
+func TestRequireVersionUnknown(t *testing.T) {
+	t.Cleanup(SetRunner(runnerFunc(func(ctx context.Context, dir string, args ...string) (string, string, error) {
+		return "not git", "", nil
+	})))
+
+	// Leave it to git to fail if the version can't be told
+	if err := RequireVersion(context.Background(), FeatureWorktreeRepair); err != nil {
+		t.Errorf("RequireVersion() = %v, want nil for an unknown version", err)
+	}
+}
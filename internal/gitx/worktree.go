@@ -3,6 +3,7 @@ package gitx
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,22 +11,22 @@ import (
 
 // Worktree represents a git worktree
 type Worktree struct {
-	Path      string // Worktree path
-	Branch    string // Branch name (empty if detached)
-	HEAD      string // HEAD commit SHA
-	IsDetached bool   // Whether in detached HEAD state
-	IsLocked  bool   // Whether locked
-	IsPrunable bool   // Whether prunable
+	Path        string // Worktree path
+	Branch      string // Branch name (empty if detached)
+	HEAD        string // HEAD commit SHA
+	IsDetached  bool   // Whether in detached HEAD state
+	IsBare      bool   // Whether this is a bare repository (no working tree)
+	IsLocked    bool   // Whether locked
+	LockReason  string // Reason given when locking (empty if none)
+	IsPrunable  bool   // Whether prunable
+	PruneReason string // Why the worktree can be pruned (empty if not given)
+	Index       int    // Position in 'git worktree list' (the stable index shown in selection lists)
 }
 
 // List returns all worktrees in the repository
+// Within a Session, the list is only read from git once until it is invalidated.
 func List(ctx context.Context) ([]Worktree, error) {
-	output, err := RunGit(ctx, "worktree", "list", "--porcelain")
-	if err != nil {
-		return nil, err
-	}
-
-	return parseWorktreePorcelain(output)
+	return listWorktrees(ctx, "")
 }
 
 // parseWorktreePorcelain parses the output of 'git worktree list --porcelain'
@@ -35,7 +36,8 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
+		// Only trim line endings: a path may end in a space
+		line = strings.TrimSuffix(line, "\r")
 		if line == "" {
 			if current != nil {
 				worktrees = append(worktrees, *current)
@@ -44,17 +46,12 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 			continue
 		}
 
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key := parts[0]
-		value := parts[1]
+		// Attribute lines are "<key> <value>" or just "<key>" (e.g. "detached", "locked")
+		key, value, _ := strings.Cut(line, " ")
 
 		switch key {
 		case "worktree":
-			current = &Worktree{Path: value}
+			current = &Worktree{Path: value, Index: len(worktrees)}
 		case "HEAD":
 			if current != nil {
 				current.HEAD = value
@@ -64,6 +61,10 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 				// branch refs/heads/main -> main
 				current.Branch = strings.TrimPrefix(value, "refs/heads/")
 			}
+		case "bare":
+			if current != nil {
+				current.IsBare = true
+			}
 		case "detached":
 			if current != nil {
 				current.IsDetached = true
@@ -71,10 +72,12 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 		case "locked":
 			if current != nil {
 				current.IsLocked = true
+				current.LockReason = value
 			}
 		case "prunable":
 			if current != nil {
 				current.IsPrunable = true
+				current.PruneReason = value
 			}
 		}
 	}
@@ -89,6 +92,37 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 
 // Add creates a new worktree
 func Add(ctx context.Context, path, branch, startPoint string, createBranch bool) error {
+	return AddWithProgress(ctx, path, branch, startPoint, createBranch, nil)
+}
+
+// AddWithProgress creates a new worktree like Add, showing git's checkout progress on progress
+// With a nil progress, git's output is buffered as with Add.
+func AddWithProgress(ctx context.Context, path, branch, startPoint string, createBranch bool, progress io.Writer) error {
+	args := addArgs(path, branch, startPoint, createBranch)
+
+	var err error
+	if progress != nil {
+		_, err = RunGitWithProgress(ctx, "", progress, args...)
+	} else {
+		_, err = RunGit(ctx, args...)
+	}
+	invalidateSession(ctx)
+	return err
+}
+
+// AddWithoutCheckout creates a new worktree like Add, but leaves its files unchecked-out
+// Registering is quick; CheckoutWorktree does the slow part, and can run for several worktrees at once.
+func AddWithoutCheckout(ctx context.Context, path, branch, startPoint string, createBranch bool) error {
+	args := addArgs(path, branch, startPoint, createBranch)
+	args = append([]string{"worktree", "add", "--no-checkout"}, args[2:]...)
+
+	_, err := RunGit(ctx, args...)
+	invalidateSession(ctx)
+	return err
+}
+
+// addArgs returns the 'git worktree add' arguments of Add
+func addArgs(path, branch, startPoint string, createBranch bool) []string {
 	args := []string{"worktree", "add"}
 
 	if createBranch {
@@ -102,13 +136,19 @@ func Add(ctx context.Context, path, branch, startPoint string, createBranch bool
 	} else if startPoint != "" {
 		args = append(args, startPoint)
 	}
+	return args
+}
 
-	_, err := RunGit(ctx, args...)
-	if err != nil {
-		return err
-	}
+// CheckoutWorktree checks out the files of a worktree created by AddWithoutCheckout
+func CheckoutWorktree(ctx context.Context, path string) error {
+	_, err := RunGitInDir(ctx, path, "checkout", "--force", "--quiet")
+	return err
+}
 
-	return nil
+// ResetIndex makes the index of the worktree at path match its HEAD, leaving its files untouched
+func ResetIndex(ctx context.Context, path string) error {
+	_, err := RunGitInDir(ctx, path, "reset", "--quiet")
+	return err
 }
 
 // Remove removes a worktree
@@ -120,34 +160,137 @@ func Remove(ctx context.Context, path string, force bool) error {
 	args = append(args, path)
 
 	_, err := RunGit(ctx, args...)
+	invalidateSession(ctx)
+	return err
+}
+
+// Move moves a worktree to a new path
+func Move(ctx context.Context, oldPath, newPath string) error {
+	_, err := RunGit(ctx, "worktree", "move", oldPath, newPath)
+	invalidateSession(ctx)
+	return err
+}
+
+// Lock locks a worktree so it cannot be pruned, moved or removed
+func Lock(ctx context.Context, path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	args = append(args, path)
+
+	_, err := RunGit(ctx, args...)
+	invalidateSession(ctx)
+	return err
+}
+
+// Unlock unlocks a worktree
+func Unlock(ctx context.Context, path string) error {
+	_, err := RunGit(ctx, "worktree", "unlock", path)
+	invalidateSession(ctx)
 	return err
 }
 
 // Prune removes worktree information for deleted directories
 func Prune(ctx context.Context) error {
 	_, err := RunGit(ctx, "worktree", "prune")
+	invalidateSession(ctx)
 	return err
 }
 
-// IsMainWorktree checks if the given path is the main worktree
-func IsMainWorktree(ctx context.Context, path string) (bool, error) {
-	repo, err := GetRepo(ctx, "")
+// PruneOptions controls PruneVerbose
+type PruneOptions struct {
+	DryRun bool   // Only report what would be pruned
+	Expire string // Only prune registrations older than this (git's --expire, e.g. "2.weeks.ago")
+}
+
+// PrunedWorktree is a worktree registration removed by PruneVerbose
+type PrunedWorktree struct {
+	Name   string // Directory of the registration below <common dir>/worktrees
+	Path   string // Where the worktree was ("" if the registration didn't say)
+	Reason string // e.g. "gitdir file points to non-existent location"
+}
+
+// PruneVerbose removes worktree information for deleted directories like Prune, and returns what was pruned
+func PruneVerbose(ctx context.Context, opts PruneOptions) ([]PrunedWorktree, error) {
+	commonDir, err := CommonDir(ctx, "")
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	// Pruning deletes the registrations, so read where the worktrees were first
+	paths := registeredPaths(filepath.Join(commonDir, "worktrees"))
 
-	// Convert path to absolute path for comparison
-	absPath, err := filepath.Abs(path)
+	args := []string{"worktree", "prune", "--verbose"}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if opts.Expire != "" {
+		args = append(args, "--expire", opts.Expire)
+	}
+	// git reports each pruned registration on stderr
+	_, stderr, err := runGit(ctx, "", args...)
+	invalidateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := parsePruneOutput(stderr)
+	for i := range pruned {
+		pruned[i].Path = paths[pruned[i].Name]
+	}
+	return pruned, nil
+}
+
+// registeredPaths maps the registrations in adminDir (<common dir>/worktrees) to their worktree paths,
+// read from their gitdir files ("<worktree>/.git")
+func registeredPaths(adminDir string) map[string]string {
+	paths := make(map[string]string)
+	entries, err := os.ReadDir(adminDir)
+	if err != nil {
+		return paths
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(adminDir, entry.Name(), "gitdir"))
+		if err != nil {
+			continue
+		}
+		gitFile := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(gitFile) {
+			// Relative to the registration (worktree.useRelativePaths)
+			gitFile = filepath.Join(adminDir, entry.Name(), gitFile)
+		}
+		paths[entry.Name()] = filepath.Dir(filepath.Clean(gitFile))
+	}
+	return paths
+}
+
+// parsePruneOutput parses the "Removing worktrees/<name>: <reason>" lines of 'git worktree prune --verbose'
+func parsePruneOutput(output string) []PrunedWorktree {
+	var pruned []PrunedWorktree
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "Removing ")
+		if !ok {
+			continue
+		}
+		name, reason, _ := strings.Cut(rest, ": ")
+		pruned = append(pruned, PrunedWorktree{Name: strings.TrimPrefix(name, "worktrees/"), Reason: reason})
+	}
+	return pruned
+}
+
+// IsMainWorktree checks if the given path is the main worktree
+func IsMainWorktree(ctx context.Context, path string) (bool, error) {
+	repo, err := GetRepo(ctx, "")
 	if err != nil {
 		return false, err
 	}
 
-	return absPath == repo.Root, nil
+	return SamePath(path, repo.Root), nil
 }
 
-// GetCurrentWorktree returns the worktree for the current directory
+// GetCurrentWorktree returns the worktree for the current directory (or the WorkDir of ctx)
 func GetCurrentWorktree(ctx context.Context) (*Worktree, error) {
-	cwd, err := os.Getwd()
+	cwd, err := AbsPath(ctx, ".")
 	if err != nil {
 		return nil, err
 	}
@@ -157,25 +300,52 @@ func GetCurrentWorktree(ctx context.Context) (*Worktree, error) {
 		return nil, err
 	}
 
-	// Find the worktree containing the current directory
-	for _, wt := range worktrees {
-		absPath, err := filepath.Abs(wt.Path)
-		if err != nil {
-			continue
-		}
+	if wt := FindWorktreeContaining(worktrees, cwd); wt != nil {
+		return wt, nil
+	}
+	return nil, fmt.Errorf("current directory is not in any worktree")
+}
 
-		// Check if cwd is under the worktree path
-		relPath, err := filepath.Rel(absPath, cwd)
-		if err != nil {
-			continue
+// FindWorktreeContaining returns the worktree of worktrees that path is in, or nil
+// With worktrees nested in another one (e.g. under .worktrees/ of the main worktree),
+// the innermost one is returned.
+func FindWorktreeContaining(worktrees []Worktree, path string) *Worktree {
+	path = resolvePath(path)
+	var found *Worktree
+	for i := range worktrees {
+		wtPath := resolvePath(worktrees[i].Path)
+		if isWithin(path, wtPath) && (found == nil || len(wtPath) > len(resolvePath(found.Path))) {
+			found = &worktrees[i]
 		}
+	}
+	return found
+}
 
-		if !strings.HasPrefix(relPath, "..") {
-			return &wt, nil
-		}
+// resolvePath returns the absolute path with symlinks resolved (e.g. /var -> /private/var on macOS)
+// If resolution fails (e.g. the path doesn't exist), the cleaned absolute path is returned.
+func resolvePath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved
 	}
+	return absPath
+}
 
-	return nil, fmt.Errorf("current directory is not in any worktree")
+// SamePath reports whether a and b refer to the same location after resolving symlinks
+func SamePath(a, b string) bool {
+	return resolvePath(a) == resolvePath(b)
+}
+
+// isWithin reports whether path is dir or is inside dir (both already resolved)
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // FindWorktreeByBranch finds a worktree by branch name
@@ -0,0 +1,363 @@
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWorktreePorcelain(t *testing.T) {
+	const (
+		sha1 = "1111111111111111111111111111111111111111"
+		sha2 = "2222222222222222222222222222222222222222"
+	)
+
+	tests := []struct {
+		name   string
+		output string
+		want   []Worktree
+	}{
+		{
+			name: "detached",
+			output: "worktree /work/repo\nHEAD " + sha1 + "\nbranch refs/heads/main\n\n" +
+				"worktree /work/.repo-wt/detached\nHEAD " + sha2 + "\ndetached\n\n",
+			want: []Worktree{
+				{Path: "/work/repo", Branch: "main", HEAD: sha1},
+				{Path: "/work/.repo-wt/detached", HEAD: sha2, IsDetached: true, Index: 1},
+			},
+		},
+		{
+			name:   "locked with reason",
+			output: "worktree /work/.repo-wt/feature x\nHEAD " + sha1 + "\nbranch refs/heads/feature/x\nlocked on a USB drive\n\n",
+			want: []Worktree{
+				{Path: "/work/.repo-wt/feature x", Branch: "feature/x", HEAD: sha1, IsLocked: true, LockReason: "on a USB drive"},
+			},
+		},
+		{
+			name:   "path ending in a space",
+			output: "worktree /work/.repo-wt/feature \nHEAD " + sha1 + "\nbranch refs/heads/feature\n\n",
+			want: []Worktree{
+				{Path: "/work/.repo-wt/feature ", Branch: "feature", HEAD: sha1},
+			},
+		},
+		{
+			name:   "locked without reason",
+			output: "worktree /work/.repo-wt/feature\nHEAD " + sha1 + "\nbranch refs/heads/feature\nlocked\n\n",
+			want: []Worktree{
+				{Path: "/work/.repo-wt/feature", Branch: "feature", HEAD: sha1, IsLocked: true},
+			},
+		},
+		{
+			name:   "prunable",
+			output: "worktree /work/.repo-wt/gone\nHEAD " + sha1 + "\nbranch refs/heads/gone\nprunable gitdir file points to non-existent location\n\n",
+			want: []Worktree{
+				{Path: "/work/.repo-wt/gone", Branch: "gone", HEAD: sha1, IsPrunable: true, PruneReason: "gitdir file points to non-existent location"},
+			},
+		},
+		{
+			name:   "bare",
+			output: "worktree /work/repo.git\nbare\n\nworktree /work/.repo-wt/main\nHEAD " + sha1 + "\nbranch refs/heads/main\n\n",
+			want: []Worktree{
+				{Path: "/work/repo.git", IsBare: true},
+				{Path: "/work/.repo-wt/main", Branch: "main", HEAD: sha1, Index: 1},
+			},
+		},
+		{
+			name: "last entry without trailing blank line",
+			output: "worktree /work/repo\nHEAD " + sha1 + "\nbranch refs/heads/main\n\n" +
+				"worktree /work/.repo-wt/detached\nHEAD " + sha2 + "\ndetached\nlocked\nprunable",
+			want: []Worktree{
+				{Path: "/work/repo", Branch: "main", HEAD: sha1},
+				{Path: "/work/.repo-wt/detached", HEAD: sha2, IsDetached: true, IsLocked: true, IsPrunable: true, Index: 1},
+			},
+		},
+		{
+			name:   "empty",
+			output: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWorktreePorcelain(tt.output)
+			if err != nil {
+				t.Fatalf("parseWorktreePorcelain() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWorktreePorcelain() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{path: "/work/repo", dir: "/work/repo", want: true},
+		{path: "/work/repo/src/pkg", dir: "/work/repo", want: true},
+		{path: "/work/repo-other", dir: "/work/repo", want: false},
+		{path: "/work", dir: "/work/repo", want: false},
+		{path: "/work/repo/..hidden", dir: "/work/repo", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("isWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestFindWorktreeContaining(t *testing.T) {
+	worktrees := []Worktree{
+		{Branch: "main", Path: "/work/repo"},
+		{Branch: "feature", Path: "/work/repo/.worktrees/feature"},
+		{Branch: "fix", Path: "/work/repo-fix"},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/work/repo/src", "main"},
+		{"/work/repo/.worktrees/feature/src/pkg", "feature"}, // The innermost worktree
+		{"/work/repo-fix", "fix"},
+		{"/work/other", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if wt := FindWorktreeContaining(worktrees, tt.path); wt != nil {
+			got = wt.Branch
+		}
+		if got != tt.want {
+			t.Errorf("FindWorktreeContaining(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// setupSymlinkedRepo creates a repository with a linked worktree under a symlinked parent directory
+// It returns the repository and worktree paths as seen through the symlink.
+func setupSymlinkedRepo(t *testing.T) (string, string) {
+	t.Helper()
+
+	repoPath, _ := setupTestRepo(t)
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(filepath.Dir(repoPath), link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	linkedRepo := filepath.Join(link, filepath.Base(repoPath))
+	linkedWT := filepath.Join(link, "wt")
+	cmd := exec.Command("git", "worktree", "add", "-b", "feature", linkedWT)
+	cmd.Dir = linkedRepo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, out)
+	}
+	return linkedRepo, linkedWT
+}
+
+// chdirViaSymlink changes into dir and sets PWD like a shell would, so os.Getwd returns the unresolved path
+func chdirViaSymlink(t *testing.T, dir string) {
+	t.Helper()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(originalDir) })
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Setenv("PWD", dir)
+}
+
+func TestGetCurrentWorktreeSymlinkedParent(t *testing.T) {
+	_, linkedWT := setupSymlinkedRepo(t)
+	subdir := filepath.Join(linkedWT, "sub")
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	chdirViaSymlink(t, subdir)
+
+	wt, err := GetCurrentWorktree(context.Background())
+	if err != nil {
+		t.Fatalf("GetCurrentWorktree() error = %v", err)
+	}
+	if wt.Branch != "feature" {
+		t.Errorf("GetCurrentWorktree() branch = %q, want %q", wt.Branch, "feature")
+	}
+}
+
+func TestGetCurrentWorktreeWorkDir(t *testing.T) {
+	_, linkedWT := setupSymlinkedRepo(t)
+	subdir := filepath.Join(linkedWT, "sub")
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	wt, err := GetCurrentWorktree(WithWorkDir(context.Background(), subdir))
+	if err != nil {
+		t.Fatalf("GetCurrentWorktree() error = %v", err)
+	}
+	if wt.Branch != "feature" {
+		t.Errorf("GetCurrentWorktree() branch = %q, want %q", wt.Branch, "feature")
+	}
+}
+
+func TestIsMainWorktreeSymlinkedParent(t *testing.T) {
+	linkedRepo, linkedWT := setupSymlinkedRepo(t)
+	chdirViaSymlink(t, linkedRepo)
+
+	ctx := context.Background()
+	isMain, err := IsMainWorktree(ctx, linkedRepo)
+	if err != nil {
+		t.Fatalf("IsMainWorktree() error = %v", err)
+	}
+	if !isMain {
+		t.Errorf("IsMainWorktree(%q) = false, want true", linkedRepo)
+	}
+
+	isMain, err = IsMainWorktree(ctx, linkedWT)
+	if err != nil {
+		t.Fatalf("IsMainWorktree() error = %v", err)
+	}
+	if isMain {
+		t.Errorf("IsMainWorktree(%q) = true, want false", linkedWT)
+	}
+}
+
+func TestAddWithProgress(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	ctx := context.Background()
+
+	var progress bytes.Buffer
+	path := filepath.Join(t.TempDir(), "feature")
+	if err := AddWithProgress(ctx, path, "feature", "", true, &progress); err != nil {
+		t.Fatalf("AddWithProgress() error = %v", err)
+	}
+	if !strings.Contains(progress.String(), "Preparing worktree") {
+		t.Errorf("progress = %q, want git's output", progress.String())
+	}
+
+	// Failures keep git's message for the error as well
+	progress.Reset()
+	err = AddWithProgress(ctx, path, "feature", "", false, &progress)
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) || gitErr.Stderr == "" {
+		t.Fatalf("AddWithProgress() for an existing path = %v, want GitError with stderr", err)
+	}
+	if !strings.Contains(progress.String(), gitErr.Stderr) {
+		t.Errorf("progress = %q, want it to contain %q", progress.String(), gitErr.Stderr)
+	}
+}
+
+func TestLockUnlock(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	path := filepath.Join(t.TempDir(), "feature")
+	if err := Add(ctx, path, "feature", "", true); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	locked := func() Worktree {
+		t.Helper()
+		worktrees, err := List(ctx)
+		if err != nil || len(worktrees) != 2 {
+			t.Fatalf("List() = %v, %v, want 2 worktrees", worktrees, err)
+		}
+		return worktrees[1]
+	}
+
+	if err := Lock(ctx, path, "on USB drive"); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if wt := locked(); !wt.IsLocked || wt.LockReason != "on USB drive" {
+		t.Errorf("after Lock(): locked = %v, reason = %q", wt.IsLocked, wt.LockReason)
+	}
+	if err := Remove(ctx, path, true); err == nil {
+		t.Error("Remove() of a locked worktree expected error, got nil")
+	}
+
+	if err := Unlock(ctx, path); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if wt := locked(); wt.IsLocked || wt.LockReason != "" {
+		t.Errorf("after Unlock(): locked = %v, reason = %q", wt.IsLocked, wt.LockReason)
+	}
+	if err := Remove(ctx, path, false); err != nil {
+		t.Errorf("Remove() after Unlock() error = %v", err)
+	}
+}
+
+func TestParsePruneOutput(t *testing.T) {
+	output := `Removing worktrees/feature: gitdir file points to non-existent location
+Removing worktrees/feature1: not a valid directory
+warning: something else`
+
+	want := []PrunedWorktree{
+		{Name: "feature", Reason: "gitdir file points to non-existent location"},
+		{Name: "feature1", Reason: "not a valid directory"},
+	}
+	if got := parsePruneOutput(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePruneOutput() = %+v, want %+v", got, want)
+	}
+	if got := parsePruneOutput(""); got != nil {
+		t.Errorf("parsePruneOutput(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestPruneVerbose(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	ctx := WithWorkDir(context.Background(), repoPath)
+
+	path := filepath.Join(t.TempDir(), "feature")
+	if err := Add(ctx, path, "feature", "", true); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := PruneVerbose(ctx, PruneOptions{DryRun: true})
+	if err != nil || len(pruned) != 1 || !SamePath(pruned[0].Path, path) || pruned[0].Name != "feature" {
+		t.Fatalf("PruneVerbose(dry run) = %+v, %v, want the registration of %s", pruned, err, path)
+	}
+	if worktrees, _ := List(ctx); len(worktrees) != 2 {
+		t.Errorf("a dry run should keep the registration, got %d worktrees", len(worktrees))
+	}
+
+	// The registration is younger than the expiry
+	if pruned, err := PruneVerbose(ctx, PruneOptions{Expire: "1.hour.ago"}); err != nil || len(pruned) != 0 {
+		t.Errorf("PruneVerbose(expire) = %+v, %v, want nothing pruned", pruned, err)
+	}
+
+	pruned, err = PruneVerbose(ctx, PruneOptions{})
+	if err != nil || len(pruned) != 1 || !SamePath(pruned[0].Path, path) {
+		t.Fatalf("PruneVerbose() = %+v, %v, want the registration of %s", pruned, err, path)
+	}
+	if worktrees, _ := List(ctx); len(worktrees) != 1 {
+		t.Errorf("the registration should be gone, got %d worktrees", len(worktrees))
+	}
+}
@@ -0,0 +1,151 @@
+// Package journal records what wt removed in a repository, one JSON line per action
+//
+// The journal is a file per repository in the state directory
+// ($XDG_STATE_HOME/wt/journal/<name>-<hash>.jsonl). Entries are only ever appended;
+// when the file grows beyond MaxSize, the oldest entries are dropped.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Actions recorded in the journal
+const (
+	WorktreeRemoved = "worktree_removed"
+	BranchDeleted   = "branch_deleted"
+	BranchRestored  = "branch_restored" // A deleted branch recreated by wt undo
+)
+
+// MaxSize is the size the journal may grow to; beyond it, the oldest half is dropped
+const MaxSize = 256 * 1024
+
+// Entry is an action recorded in the journal
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Branch string    `json:"branch,omitempty"`
+	Commit string    `json:"commit,omitempty"` // Commit the branch or the worktree's HEAD pointed at
+	Path   string    `json:"path,omitempty"`   // Path of a removed worktree
+}
+
+// Path returns the journal file of the repository at root
+func Path(root string) (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	// The name keeps the files recognizable, the hash tells repositories of the same name apart
+	sum := sha256.Sum256([]byte(root))
+	name := fmt.Sprintf("%s-%s.jsonl", filepath.Base(root), hex.EncodeToString(sum[:])[:12])
+	return filepath.Join(stateHome, "wt", "journal", name), nil
+}
+
+// Append adds e to the journal at path, dropping the oldest entries first if it is too large
+// Each entry is written with a single append, so concurrent wt processes don't mix their lines.
+func Append(path string, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > MaxSize {
+		if err := truncate(path); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// truncate keeps the newest entries of the journal at path that fit in half of MaxSize
+// The file is replaced with a rename, so that readers never see a partial journal.
+func truncate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxSize/2 {
+		data = data[len(data)-MaxSize/2:]
+		// Start at a whole line
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read returns the entries of the journal at path, oldest first
+// A missing journal has no entries; lines that can't be parsed (e.g. cut off) are skipped.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil || e.Action == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// LastDeletedBranch returns the most recent branch deletion that wasn't restored since, or nil
+func LastDeletedBranch(entries []Entry) *Entry {
+	restored := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		key := e.Branch + "\x00" + e.Commit
+		switch e.Action {
+		case BranchRestored:
+			restored[key] = true
+		case BranchDeleted:
+			if restored[key] {
+				delete(restored, key) // A restore only undoes one deletion
+				continue
+			}
+			return &entries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	a, err := Path("/src/a/proj")
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	b, _ := Path("/src/b/proj")
+	if !strings.HasPrefix(a, filepath.Join("/state", "wt", "journal", "proj-")) || a == b {
+		t.Errorf("Path() = %q and %q, want distinct files named after the repository", a, b)
+	}
+}
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "proj.jsonl")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	entries, err := Read(path)
+	if err != nil || entries != nil {
+		t.Fatalf("Read() of a missing journal = %v, %v, want no entries", entries, err)
+	}
+
+	want := []Entry{
+		{Time: now, Action: WorktreeRemoved, Branch: "feature", Commit: "abc", Path: "/src/.proj-wt/feature"},
+		{Time: now, Action: BranchDeleted, Branch: "feature", Commit: "abc"},
+	}
+	for _, e := range want {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	// A line cut off by a crash is skipped
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"time":"2024-01-02T03:04:05Z","act` + "\n")
+	f.Close()
+
+	entries, err = Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0] != want[0] || entries[1] != want[1] {
+		t.Errorf("Read() = %+v, want %+v", entries, want)
+	}
+}
+
+func TestAppendDropsOldEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proj.jsonl")
+	e := Entry{Time: time.Now(), Action: BranchDeleted, Branch: strings.Repeat("b", 200), Commit: "abc"}
+	for i := 0; i < MaxSize/200+10; i++ {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	e.Branch = "last"
+	if err := Append(path, e); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > MaxSize {
+		t.Fatalf("journal size = %v (%v), want at most %d", info.Size(), err, MaxSize)
+	}
+	entries, _ := Read(path)
+	if len(entries) == 0 || entries[len(entries)-1].Branch != "last" {
+		t.Errorf("Read() after truncation lost the newest entry")
+	}
+}
+
+func TestLastDeletedBranch(t *testing.T) {
+	entries := []Entry{
+		{Action: BranchDeleted, Branch: "a", Commit: "1"},
+		{Action: WorktreeRemoved, Branch: "b", Commit: "2", Path: "/b"},
+		{Action: BranchDeleted, Branch: "b", Commit: "2"},
+	}
+	if got := LastDeletedBranch(entries); got == nil || got.Branch != "b" {
+		t.Fatalf("LastDeletedBranch() = %+v, want b", got)
+	}
+
+	entries = append(entries, Entry{Action: BranchRestored, Branch: "b", Commit: "2"})
+	if got := LastDeletedBranch(entries); got == nil || got.Branch != "a" {
+		t.Errorf("LastDeletedBranch() after restoring b = %+v, want a", got)
+	}
+
+	entries = append(entries, Entry{Action: BranchRestored, Branch: "a", Commit: "1"})
+	if got := LastDeletedBranch(entries); got != nil {
+		t.Errorf("LastDeletedBranch() with everything restored = %+v, want nil", got)
+	}
+}
@@ -4,91 +4,160 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/toritori0318/git-wt/internal/config"
 )
 
-// GenerateWorktreePath generates a unique worktree path using default configuration
-// Uses subdirectory mode by default: <baseDir>/.<repoName>-wt/<sanitizedBranch>
-func GenerateWorktreePath(baseDir, repoName, sanitizedBranch string) (string, error) {
-	// Load default config (or from default config path if available)
+// GenerateWorktreePath generates a unique worktree path using the configuration file and environment overrides
+// It is a thin compatibility wrapper: the file is read on every call, so commands should load it once
+// and use GenerateWorktreePathWithConfig instead.
+func GenerateWorktreePath(baseDir, repoName, branch string, registered []string) (string, error) {
 	configPath, err := config.GetDefaultConfigPath()
 	if err != nil {
-		// If we can't get config path, use defaults
-		cfg := &config.Config{
-			Worktree: config.WorktreeConfig{
-				DirectoryFormat:    config.DefaultDirectoryFormat,
-				SubdirectoryPrefix: config.DefaultSubdirectoryPrefix,
-				SubdirectorySuffix: config.DefaultSubdirectorySuffix,
-			},
-		}
-		return GenerateWorktreePathWithConfig(baseDir, repoName, sanitizedBranch, cfg)
+		return "", fmt.Errorf("failed to get config path: %w", err)
 	}
-
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		// If config load fails, use defaults
-		cfg = &config.Config{
-			Worktree: config.WorktreeConfig{
-				DirectoryFormat:    config.DefaultDirectoryFormat,
-				SubdirectoryPrefix: config.DefaultSubdirectoryPrefix,
-				SubdirectorySuffix: config.DefaultSubdirectorySuffix,
-			},
-		}
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	// Environment variables take precedence over the file (and work without one)
+	if err := config.ApplyEnvOverrides(cfg); err != nil {
+		return "", err
 	}
 
-	return GenerateWorktreePathWithConfig(baseDir, repoName, sanitizedBranch, cfg)
+	return GenerateWorktreePathWithConfig(baseDir, repoName, branch, registered, cfg)
 }
 
 // GenerateWorktreePathWithConfig generates a unique worktree path using the provided configuration
-func GenerateWorktreePathWithConfig(baseDir, repoName, sanitizedBranch string, cfg *config.Config) (string, error) {
-	const maxAttempts = 100
+// branch is sanitized for use as a directory name, except by the {branch} placeholder of a path template.
+// When the path is taken, worktree.collision_strategy decides between a numbered suffix and a *CollisionError.
+func GenerateWorktreePathWithConfig(baseDir, repoName, branch string, registered []string, cfg *config.Config) (string, error) {
+	sanitizedBranch := sanitizeFor(cfg)(branch)
+	if sanitizedBranch == "" {
+		return "", fmt.Errorf("branch name %q has no characters usable in a directory name", branch)
+	}
+
+	candidate, err := worktreePathCandidate(baseDir, repoName, branch, sanitizedBranch, cfg)
+	if err != nil {
+		return "", err
+	}
+	return uniquePath(candidate, registered, cfg.GetCollisionStrategy())
+}
+
+// worktreePathCandidate returns the preferred path for a new worktree, before checking for collisions
+func worktreePathCandidate(baseDir, repoName, branch, sanitizedBranch string, cfg *config.Config) (string, error) {
+	// A path template replaces directory_format and the subdirectory prefix/suffix
+	if tmpl := cfg.GetPathTemplate(); tmpl != "" {
+		return RenderPathTemplate(tmpl, baseDir, repoName, branch, sanitizedBranch)
+	}
 
 	if cfg.GetDirectoryFormat() == config.DirectoryFormatSubdirectory {
 		// Subdirectory mode: <baseDir>/<prefix><repoName><suffix>/<sanitizedBranch>
 		worktreeDir := cfg.GetSubdirectoryPrefix() + repoName + cfg.GetSubdirectorySuffix()
-		return generateUniquePathInSubdir(baseDir, worktreeDir, sanitizedBranch, maxAttempts)
+		if cfg.GetNestedBranchDirs() {
+			// Nested: <baseDir>/<prefix><repoName><suffix>/<segment>/.../<segment>
+			nested, err := sanitizeNested(branch, sanitizeFor(cfg))
+			if err != nil {
+				return "", err
+			}
+			sanitizedBranch = nested
+		}
+		return filepath.Join(baseDir, worktreeDir, sanitizedBranch), nil
 	}
 
 	// Sibling mode (legacy): <baseDir>/<repoName>-<sanitizedBranch>
-	baseName := fmt.Sprintf("%s-%s", repoName, sanitizedBranch)
-	candidate := filepath.Join(baseDir, baseName)
+	return filepath.Join(baseDir, fmt.Sprintf("%s-%s", repoName, sanitizedBranch)), nil
+}
 
-	// Check for duplicates
-	if !pathExists(candidate) {
-		return candidate, nil
+// sanitizeFor returns the branch name sanitizer selected by worktree.sanitize_ascii_only and worktree.lowercase_dirs
+func sanitizeFor(cfg *config.Config) func(string) string {
+	switch {
+	case cfg.GetSanitizeASCIIOnly() && cfg.GetLowercaseDirs():
+		return func(name string) string { return SanitizeASCII(strings.ToLower(name)) }
+	case cfg.GetSanitizeASCIIOnly():
+		return SanitizeASCII
+	case cfg.GetLowercaseDirs():
+		return SanitizeWithLowercase
 	}
+	return Sanitize
+}
 
-	// Retry with numbered suffix
-	for i := 2; i < maxAttempts; i++ {
-		candidate = filepath.Join(baseDir, fmt.Sprintf("%s-%d", baseName, i))
-		if !pathExists(candidate) {
-			return candidate, nil
+// sanitizeNested sanitizes each slash-separated segment of branch and joins them as nested directories
+func sanitizeNested(branch string, sanitize func(string) string) (string, error) {
+	segments := strings.Split(branch, "/")
+	for i, segment := range segments {
+		s := sanitize(segment)
+		switch {
+		case s == "":
+			return "", fmt.Errorf("branch name %q has a path segment with no characters usable in a directory name", branch)
+		case s == "." || s == ".." || strings.EqualFold(s, ".git"):
+			return "", fmt.Errorf("branch name %q has a path segment %q that can't be used as a directory", branch, segment)
 		}
+		segments[i] = s
 	}
+	return filepath.Join(segments...), nil
+}
+
+// CollisionError represents an error when the path for a new worktree is already taken
+type CollisionError struct {
+	Path        string // The taken path
+	Registered  bool   // Path belongs to a worktree registered with git
+	Alternative string // First free numbered path ("" if none was found)
+}
 
-	return "", fmt.Errorf("could not generate unique path after %d attempts", maxAttempts)
+func (e *CollisionError) Error() string {
+	switch {
+	case e.Registered && !pathExists(e.Path):
+		return fmt.Sprintf("worktree path is registered to a worktree that no longer exists: %s\n"+
+			"Run 'git worktree prune' to forget it, or set worktree.collision_strategy to %q", e.Path, config.CollisionStrategySuffix)
+	case e.Registered:
+		return fmt.Sprintf("worktree path is used by another worktree: %s\n"+
+			"Remove it with 'wt clean', or set worktree.collision_strategy to %q", e.Path, config.CollisionStrategySuffix)
+	default:
+		return fmt.Sprintf("worktree path already exists: %s\n"+
+			"Remove the directory if it is stale, or set worktree.collision_strategy to %q", e.Path, config.CollisionStrategySuffix)
+	}
 }
 
-// generateUniquePathInSubdir generates a unique path in a subdirectory
-func generateUniquePathInSubdir(baseDir, worktreeDir, branchName string, maxAttempts int) (string, error) {
-	// Base path: <baseDir>/<worktreeDir>/<branchName>
-	candidate := filepath.Join(baseDir, worktreeDir, branchName)
+// uniquePath returns candidate if it is free, or handles the collision according to strategy
+// A path is taken if it exists or is a registered worktree path. Numbered suffixes go on the last path component.
+func uniquePath(candidate string, registered []string, strategy string) (string, error) {
+	const maxAttempts = 100
 
-	// Check for duplicates
-	if !pathExists(candidate) {
+	isRegistered := func(path string) bool {
+		for _, r := range registered {
+			if filepath.Clean(r) == path {
+				return true
+			}
+		}
+		return false
+	}
+	taken := func(path string) bool {
+		return pathExists(path) || isRegistered(path)
+	}
+
+	if !taken(candidate) {
 		return candidate, nil
 	}
 
-	// Retry with numbered suffix on the branch name
+	// Retry with numbered suffix
+	alternative := ""
 	for i := 2; i < maxAttempts; i++ {
-		candidate = filepath.Join(baseDir, worktreeDir, fmt.Sprintf("%s-%d", branchName, i))
-		if !pathExists(candidate) {
-			return candidate, nil
+		if next := fmt.Sprintf("%s-%d", candidate, i); !taken(next) {
+			alternative = next
+			break
+		}
+	}
+
+	if strategy == config.CollisionStrategySuffix {
+		if alternative == "" {
+			return "", fmt.Errorf("could not generate unique path after %d attempts", maxAttempts)
 		}
+		return alternative, nil
 	}
 
-	return "", fmt.Errorf("could not generate unique path after %d attempts", maxAttempts)
+	return "", &CollisionError{Path: candidate, Registered: isRegistered(candidate), Alternative: alternative}
 }
 
 // pathExists checks if a path exists
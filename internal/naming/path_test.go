@@ -1,9 +1,12 @@
 package naming_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/toritori0318/git-wt/internal/config"
 	"github.com/toritori0318/git-wt/internal/naming"
@@ -23,6 +26,9 @@ import (
 //    - Returns error when max attempts exceeded
 // 4. When config file doesn't exist ✓
 //    - Generates path in default subdirectory mode (prefix is ".")
+// 5. Path templates ✓
+//    - Placeholders are expanded; {branch} creates nested directories
+//    - Duplicates add a numbered suffix to the last path component
 
 func TestGenerateWorktreePathWithSubdirectoryMode(t *testing.T) {
 	tempDir := t.TempDir()
@@ -36,7 +42,7 @@ func TestGenerateWorktreePathWithSubdirectoryMode(t *testing.T) {
 
 	// Test basic path generation with default prefix "."
 	baseDir := filepath.Join(tempDir, "repos")
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -60,7 +66,7 @@ func TestGenerateWorktreePathWithCustomSuffix(t *testing.T) {
 
 	// Test path generation with custom suffix
 	baseDir := filepath.Join(tempDir, "repos")
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -84,7 +90,7 @@ func TestGenerateWorktreePathWithSiblingMode(t *testing.T) {
 
 	// Test path generation with sibling mode
 	baseDir := filepath.Join(tempDir, "repos")
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -114,7 +120,7 @@ func TestGenerateWorktreePathWithDuplicatesSubdirectory(t *testing.T) {
 	}
 
 	// Generate path (should get -2 suffix)
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -145,7 +151,7 @@ func TestGenerateWorktreePathWithDuplicatesSibling(t *testing.T) {
 	}
 
 	// Generate path (should get -2 suffix)
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -169,7 +175,7 @@ func TestGenerateWorktreePathWithCustomPrefix(t *testing.T) {
 
 	// Test path generation with custom prefix
 	baseDir := filepath.Join(tempDir, "repos")
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -193,7 +199,7 @@ func TestGenerateWorktreePathWithEmptyPrefix(t *testing.T) {
 
 	// Test path generation with empty prefix
 	baseDir := filepath.Join(tempDir, "repos")
-	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", cfg)
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", nil, cfg)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
 	}
@@ -208,9 +214,10 @@ func TestGenerateWorktreePathDefault(t *testing.T) {
 	// Test the default GenerateWorktreePath function (without explicit config)
 	// This should use default subdirectory mode with prefix "."
 	tempDir := t.TempDir()
+	t.Setenv(config.ConfigFileEnv, filepath.Join(tempDir, "missing.yaml"))
 	baseDir := filepath.Join(tempDir, "repos")
 
-	path, err := naming.GenerateWorktreePath(baseDir, "myproject", "feature-login")
+	path, err := naming.GenerateWorktreePath(baseDir, "myproject", "feature-login", nil)
 	if err != nil {
 		t.Fatalf("GenerateWorktreePath() returned error: %v", err)
 	}
@@ -220,3 +227,334 @@ func TestGenerateWorktreePathDefault(t *testing.T) {
 		t.Errorf("GenerateWorktreePath() = %q, want %q", path, want)
 	}
 }
+
+func TestGenerateWorktreePathHonorsConfigFileEnv(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "alt.yaml")
+	if err := os.WriteFile(configPath, []byte("worktree:\n  directory_format: sibling\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv(config.ConfigFileEnv, configPath)
+	t.Setenv("WT_DIRECTORY_FORMAT", "")
+	os.Unsetenv("WT_DIRECTORY_FORMAT")
+
+	baseDir := filepath.Join(tempDir, "repos")
+	path, err := naming.GenerateWorktreePath(baseDir, "myproject", "feature-login", nil)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePath() returned error: %v", err)
+	}
+
+	want := filepath.Join(baseDir, "myproject-feature-login")
+	if path != want {
+		t.Errorf("GenerateWorktreePath() = %q, want %q", path, want)
+	}
+}
+
+func TestGenerateWorktreePathReturnsLoadError(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "broken.yaml")
+	if err := os.WriteFile(configPath, []byte("worktree: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv(config.ConfigFileEnv, configPath)
+
+	if _, err := naming.GenerateWorktreePath(t.TempDir(), "myproject", "feature-login", nil); err == nil {
+		t.Error("GenerateWorktreePath() error = nil, want the config load error")
+	}
+}
+
+func TestGenerateWorktreePathWithTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	// Legacy layout keys are ignored when a template is set
+	cfg.Worktree.DirectoryFormat = config.DirectoryFormatSibling
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "nested directories from branch",
+			template: "{base}/{repo}-trees/{branch}",
+			want:     filepath.Join(baseDir, "myproject-trees", "feature", "login"),
+		},
+		{
+			name:     "sanitized branch",
+			template: "{base}/{repo}-trees/{branch_sanitized}",
+			want:     filepath.Join(baseDir, "myproject-trees", "feature-login"),
+		},
+		{
+			name:     "relative to base",
+			template: "{repo}__{branch_sanitized}",
+			want:     filepath.Join(baseDir, "myproject__feature-login"),
+		},
+		{
+			name:     "date",
+			template: "{base}/{repo}/{date}-{branch_sanitized}",
+			want:     filepath.Join(baseDir, "myproject", time.Now().Format("2006-01-02")+"-feature-login"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cfg.SetPathTemplate(tt.template); err != nil {
+				t.Fatalf("SetPathTemplate() returned error: %v", err)
+			}
+
+			path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/login", nil, cfg)
+			if err != nil {
+				t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+			}
+			if path != tt.want {
+				t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWorktreePathWithTemplateDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.SetPathTemplate("{base}/{repo}-trees/{branch}"); err != nil {
+		t.Fatalf("SetPathTemplate() returned error: %v", err)
+	}
+
+	// Occupy the first two candidates
+	for _, name := range []string{"login", "login-2"} {
+		if err := os.MkdirAll(filepath.Join(baseDir, "myproject-trees", "feature", name), 0755); err != nil {
+			t.Fatalf("Failed to create existing path: %v", err)
+		}
+	}
+
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/login", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+
+	// The suffix goes on the last path component only
+	want := filepath.Join(baseDir, "myproject-trees", "feature", "login-3")
+	if path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+}
+
+func TestWorktreeContainer(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		template   string
+		wantDir    string
+		wantPrefix string
+	}{
+		{name: "no template", template: "", wantDir: filepath.Join("/work", ".myproject-wt"), wantPrefix: ""},
+		{name: "nested", template: "{base}/{repo}-trees/{branch}", wantDir: filepath.Join("/work", "myproject-trees"), wantPrefix: ""},
+		{name: "shared directory", template: "{repo}__{branch_sanitized}", wantDir: "/work", wantPrefix: "myproject__"},
+		{name: "date before branch", template: "{base}/{repo}/{date}/{branch}", wantDir: filepath.Join("/work", "myproject"), wantPrefix: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cfg.SetPathTemplate(tt.template); err != nil {
+				t.Fatalf("SetPathTemplate() returned error: %v", err)
+			}
+			dir, prefix := naming.WorktreeContainer("/work", "myproject", cfg)
+			if dir != tt.wantDir || prefix != tt.wantPrefix {
+				t.Errorf("WorktreeContainer() = (%q, %q), want (%q, %q)", dir, prefix, tt.wantDir, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestGenerateWorktreePathWithSanitizeASCIIOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// ASCII by default, as in earlier versions
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/ログイン", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join(baseDir, ".myproject-wt", "feature"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+
+	cfg.Worktree.SanitizeASCIIOnly = false
+	path, err = naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/ログイン", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join(baseDir, ".myproject-wt", "feature-ログイン"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() without sanitize_ascii_only = %q, want %q", path, want)
+	}
+}
+
+func TestGenerateWorktreePathWithNestedBranchDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Worktree.NestedBranchDirs = true
+
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/auth/log in", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	want := filepath.Join(baseDir, ".myproject-wt", "feature", "auth", "log-in")
+	if path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+
+	// Duplicates get the suffix on the leaf only
+	if err := os.MkdirAll(want, 0755); err != nil {
+		t.Fatalf("Failed to create existing path: %v", err)
+	}
+	path, err = naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/auth/log in", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join(baseDir, ".myproject-wt", "feature", "auth", "log-in-2"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() with duplicate = %q, want %q", path, want)
+	}
+
+	// Sibling mode ignores the setting
+	cfg.Worktree.DirectoryFormat = config.DirectoryFormatSibling
+	path, err = naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/login", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join(baseDir, "myproject-feature-login"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() in sibling mode = %q, want %q", path, want)
+	}
+}
+
+func TestGenerateWorktreePathWithNestedBranchDirsRejectsSegments(t *testing.T) {
+	tempDir := t.TempDir()
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Worktree.NestedBranchDirs = true
+
+	for _, branch := range []string{"feature/../main", "feature/.git", "feature/.GIT/x", "feature/./x", "feature/!!!/x"} {
+		if path, err := naming.GenerateWorktreePathWithConfig(tempDir, "myproject", branch, nil, cfg); err == nil {
+			t.Errorf("GenerateWorktreePathWithConfig(%q) = %q, want error", branch, path)
+		}
+	}
+}
+
+func TestGenerateWorktreePathCollisionStrategy(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+	existing := filepath.Join(baseDir, ".myproject-wt", "feature-login")
+	if err := os.MkdirAll(existing, 0755); err != nil {
+		t.Fatalf("Failed to create existing path: %v", err)
+	}
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.SetCollisionStrategy(config.CollisionStrategyError); err != nil {
+		t.Fatalf("SetCollisionStrategy() returned error: %v", err)
+	}
+
+	_, err = naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature/login", nil, cfg)
+	var collision *naming.CollisionError
+	if !errors.As(err, &collision) {
+		t.Fatalf("GenerateWorktreePathWithConfig() error = %v, want *CollisionError", err)
+	}
+	if collision.Path != existing || collision.Registered {
+		t.Errorf("CollisionError = %+v, want unregistered collision at %q", collision, existing)
+	}
+	if want := existing + "-2"; collision.Alternative != want {
+		t.Errorf("CollisionError.Alternative = %q, want %q", collision.Alternative, want)
+	}
+	if !strings.Contains(err.Error(), existing) {
+		t.Errorf("error should show the conflicting path, got: %v", err)
+	}
+}
+
+func TestGenerateWorktreePathRegisteredMissingWorktree(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+	// Registered with git, but the directory was deleted
+	missing := filepath.Join(baseDir, ".myproject-wt", "feature-login")
+	registered := []string{filepath.Join(baseDir, "myproject"), missing}
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// suffix: the registered path is skipped rather than reused
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", registered, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := missing + "-2"; path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+
+	// error: the registered worktree is reported
+	cfg.Worktree.CollisionStrategy = config.CollisionStrategyError
+	_, err = naming.GenerateWorktreePathWithConfig(baseDir, "myproject", "feature-login", registered, cfg)
+	var collision *naming.CollisionError
+	if !errors.As(err, &collision) || !collision.Registered {
+		t.Fatalf("GenerateWorktreePathWithConfig() error = %v, want registered *CollisionError", err)
+	}
+	if !strings.Contains(err.Error(), "git worktree prune") {
+		t.Errorf("error should suggest git worktree prune, got: %v", err)
+	}
+}
+
+func TestGenerateWorktreePathWithLowercaseDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "repos")
+
+	cfg, err := config.Load(filepath.Join(tempDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Worktree.LowercaseDirs = true
+
+	path, err := naming.GenerateWorktreePathWithConfig(baseDir, "MyProject", "Feature/JIRA-123", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	// Only the branch component is lowercased
+	if want := filepath.Join(baseDir, ".MyProject-wt", "feature-jira-123"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() = %q, want %q", path, want)
+	}
+
+	cfg.Worktree.SanitizeASCIIOnly = true
+	path, err = naming.GenerateWorktreePathWithConfig(baseDir, "MyProject", "Feature/JIRA-123-ÄÖ", nil, cfg)
+	if err != nil {
+		t.Fatalf("GenerateWorktreePathWithConfig() returned error: %v", err)
+	}
+	if want := filepath.Join(baseDir, ".MyProject-wt", "feature-jira-123"); path != want {
+		t.Errorf("GenerateWorktreePathWithConfig() with sanitize_ascii_only = %q, want %q", path, want)
+	}
+}
@@ -3,25 +3,47 @@ package naming
 import (
 	"regexp"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 var (
 	// Regex to replace disallowed characters
+	// Allowed characters: letters, digits and combining marks in any script, ., _, -
+	invalidCharsRegex = regexp.MustCompile(`[^\p{L}\p{M}\p{N}._-]+`)
+
+	// Regex to replace disallowed characters when only ASCII is allowed
 	// Allowed characters: A-Z, a-z, 0-9, ., _, -
-	invalidCharsRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	invalidASCIICharsRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
 
 	// Replace consecutive hyphens with a single hyphen
 	multiHyphenRegex = regexp.MustCompile(`-+`)
 )
 
+// maxLength limits directory names to avoid filesystem issues (max 255 bytes on most systems)
+const maxLength = 200 // Conservative limit for safety, in bytes
+
 // Sanitize converts a branch name to a filesystem-safe directory name
+// Non-ASCII letters and digits (e.g. Japanese) are kept, composed to NFC so that decomposed
+// names (e.g. from macOS) give the same directory name.
 // Example: "feature/new-ui" -> "feature-new-ui"
 func Sanitize(branchName string) string {
+	return sanitize(norm.NFC.String(branchName), invalidCharsRegex)
+}
+
+// SanitizeASCII is like Sanitize but also replaces non-ASCII characters, as earlier versions did
+// Example: "feature/ログイン" -> "feature"
+func SanitizeASCII(branchName string) string {
+	return sanitize(branchName, invalidASCIICharsRegex)
+}
+
+func sanitize(branchName string, invalid *regexp.Regexp) string {
 	// Convert slashes to hyphens
 	s := strings.ReplaceAll(branchName, "/", "-")
 
-	// Convert disallowed characters to hyphens
-	s = invalidCharsRegex.ReplaceAllString(s, "-")
+	// Convert disallowed characters (including invalid UTF-8) to hyphens
+	s = invalid.ReplaceAllString(s, "-")
 
 	// Collapse consecutive hyphens
 	s = multiHyphenRegex.ReplaceAllString(s, "-")
@@ -29,10 +51,8 @@ func Sanitize(branchName string) string {
 	// Trim leading and trailing hyphens
 	s = strings.Trim(s, "-")
 
-	// Limit path length to avoid filesystem issues (max 255 chars on most systems)
-	const maxLength = 200 // Conservative limit for safety
 	if len(s) > maxLength {
-		s = s[:maxLength]
+		s = truncate(s, maxLength)
 		// Re-trim in case we cut in the middle of trailing hyphens
 		s = strings.TrimRight(s, "-")
 	}
@@ -40,6 +60,17 @@ func Sanitize(branchName string) string {
 	return s
 }
 
+// truncate shortens s to at most n bytes without splitting a multi-byte character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 // SanitizeWithLowercase converts a branch name to lowercase and sanitizes it
 func SanitizeWithLowercase(branchName string) string {
 	return Sanitize(strings.ToLower(branchName))
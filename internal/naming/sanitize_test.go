@@ -0,0 +1,108 @@
+package naming_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/toritori0318/git-wt/internal/naming"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantASCII string
+	}{
+		{
+			name:      "ascii branch",
+			input:     "feature/new-ui",
+			want:      "feature-new-ui",
+			wantASCII: "feature-new-ui",
+		},
+		{
+			name:      "japanese branch",
+			input:     "feature/ログイン画面",
+			want:      "feature-ログイン画面",
+			wantASCII: "feature",
+		},
+		{
+			name:      "emoji is replaced",
+			input:     "fix/🐛-crash",
+			want:      "fix-crash",
+			wantASCII: "fix-crash",
+		},
+		{
+			name:      "accented letters",
+			input:     "docs/café",
+			want:      "docs-café",
+			wantASCII: "docs-caf",
+		},
+		{
+			name:      "decomposed letters are composed",
+			input:     "docs/cafe\u0301/\u30ab\u3099",
+			want:      "docs-café-ガ",
+			wantASCII: "docs-cafe",
+		},
+		{
+			name:      "invalid utf-8 is replaced",
+			input:     "bad\xffname",
+			want:      "bad-name",
+			wantASCII: "bad-name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := naming.Sanitize(tt.input); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got := naming.SanitizeASCII(tt.input); got != tt.wantASCII {
+				t.Errorf("SanitizeASCII(%q) = %q, want %q", tt.input, got, tt.wantASCII)
+			}
+		})
+	}
+}
+
+func TestSanitizeLengthLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "exactly at the limit",
+			input: strings.Repeat("a", 200),
+			want:  strings.Repeat("a", 200),
+		},
+		{
+			name:  "one byte over the limit",
+			input: strings.Repeat("a", 201),
+			want:  strings.Repeat("a", 200),
+		},
+		{
+			// 67 three-byte characters are 201 bytes; the 67th would be split at byte 200
+			name:  "multi-byte character at the limit",
+			input: strings.Repeat("あ", 67),
+			want:  strings.Repeat("あ", 66),
+		},
+		{
+			name:  "hyphen at the cut is trimmed",
+			input: strings.Repeat("a", 199) + "-bbb",
+			want:  strings.Repeat("a", 199),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := naming.Sanitize(tt.input)
+			if got != tt.want {
+				t.Errorf("Sanitize() = %q (%d bytes), want %q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Sanitize() returned invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package naming
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toritori0318/git-wt/internal/config"
+)
+
+// dateFormat is the layout used for the {date} placeholder
+const dateFormat = "2006-01-02"
+
+// RenderPathTemplate expands a worktree.path_template value into an absolute path
+// Relative results are resolved against baseDir; a leading "~/" is expanded to the home directory.
+// sanitizedBranch is substituted for {branch_sanitized}.
+func RenderPathTemplate(tmpl, baseDir, repoName, branch, sanitizedBranch string) (string, error) {
+	if err := config.ValidatePathTemplate(tmpl); err != nil {
+		return "", err
+	}
+
+	r := strings.NewReplacer(
+		"{base}", baseDir,
+		"{repo}", repoName,
+		"{branch_sanitized}", sanitizedBranch,
+		"{branch}", branch,
+		"{date}", time.Now().Format(dateFormat),
+	)
+	rendered := r.Replace(tmpl)
+
+	// Branch names can't contain "..", but never let a rendered path climb out of its directory
+	for _, part := range strings.FieldsFunc(rendered, isPathSeparator) {
+		if part == ".." {
+			return "", fmt.Errorf("path template %q renders to %q, which contains '..'", tmpl, rendered)
+		}
+	}
+
+	return absTemplatePath(rendered, baseDir)
+}
+
+// absTemplatePath makes a rendered template absolute
+func absTemplatePath(rendered, baseDir string) (string, error) {
+	if rendered == "~" || strings.HasPrefix(rendered, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~ in path template: %w", err)
+		}
+		rendered = filepath.Join(home, strings.TrimPrefix(rendered, "~"))
+	}
+	if !filepath.IsAbs(rendered) {
+		rendered = filepath.Join(baseDir, rendered)
+	}
+	return filepath.Clean(rendered), nil
+}
+
+// WorktreeContainer returns the directory new worktrees of repoName are created in
+// prefix is the fixed start of worktree directory names within it, when the directory is shared
+// with other repositories (e.g. "<repo>-" in sibling mode); it is empty otherwise.
+// For a path template, dir is the part of the path that doesn't depend on the branch or date.
+func WorktreeContainer(baseDir, repoName string, cfg *config.Config) (dir, prefix string) {
+	if tmpl := cfg.GetPathTemplate(); tmpl != "" {
+		return templateContainer(tmpl, baseDir, repoName)
+	}
+	if cfg.GetDirectoryFormat() == config.DirectoryFormatSubdirectory {
+		return filepath.Join(baseDir, cfg.GetSubdirectoryPrefix()+repoName+cfg.GetSubdirectorySuffix()), ""
+	}
+	return baseDir, repoName + "-"
+}
+
+// NestsBranchDirs reports whether worktree paths can contain a directory per branch name segment
+// (worktree.nested_branch_dirs in subdirectory mode, or {branch} in a path template)
+func NestsBranchDirs(cfg *config.Config) bool {
+	if tmpl := cfg.GetPathTemplate(); tmpl != "" {
+		return strings.Contains(tmpl, "{branch}")
+	}
+	return cfg.GetNestedBranchDirs() && cfg.GetDirectoryFormat() == config.DirectoryFormatSubdirectory
+}
+
+// templateContainer splits a path template at the first component that depends on the branch or date
+func templateContainer(tmpl, baseDir, repoName string) (string, string) {
+	r := strings.NewReplacer("{base}", baseDir, "{repo}", repoName)
+
+	parts := strings.Split(filepath.ToSlash(tmpl), "/")
+	for i, part := range parts {
+		idx := strings.Index(part, "{")
+		if idx < 0 {
+			continue
+		}
+		// {base} and {repo} are fixed for a repository; anything else varies per worktree
+		fixed := r.Replace(part)
+		if !strings.Contains(fixed, "{") {
+			continue
+		}
+		dir, err := absTemplatePath(r.Replace(strings.Join(parts[:i], "/")), baseDir)
+		if err != nil {
+			return baseDir, ""
+		}
+		return dir, fixed[:strings.Index(fixed, "{")]
+	}
+
+	// Unreachable for valid templates, which always contain a branch placeholder
+	dir, err := absTemplatePath(r.Replace(tmpl), baseDir)
+	if err != nil {
+		return baseDir, ""
+	}
+	return filepath.Dir(dir), ""
+}
+
+// isPathSeparator reports whether c separates path components in a template
+func isPathSeparator(c rune) bool {
+	return c == '/' || c == '\\'
+}
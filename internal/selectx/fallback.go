@@ -1,15 +1,23 @@
 package selectx
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 )
 
+// MaxPromptAttempts is the number of invalid answers accepted before the numbered selection gives up
+const MaxPromptAttempts = 3
+
 // SelectWithPrompt provides a simple number-based selection UI
-func SelectWithPrompt(items []string, prompt string) (int, error) {
+// The list and prompt are written to w and the answer is read from r. Invalid answers are asked
+// again, up to MaxPromptAttempts times; "q" or the end of input cancels. Items are numbered 1..N,
+// or by their own index with SelectOptions.Indexed.
+func SelectWithPrompt(r io.Reader, w io.Writer, items []string, opts SelectOptions) (int, error) {
 	if len(items) == 0 {
 		return -1, fmt.Errorf("no items to select from")
 	}
@@ -20,36 +28,207 @@ func SelectWithPrompt(items []string, prompt string) (int, error) {
 	}
 
 	// Display items with numbers
-	fmt.Fprintf(os.Stderr, "%s:\n", prompt)
-	for i, item := range items {
-		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, item)
+	numbers, indexed := itemNumbers(items, opts)
+	printNumberedItems(w, items, numbers, indexed, opts)
+
+	question := fmt.Sprintf("Select number (%s, or q to quit): ", describeNumbers(numbers))
+	return askUntilValid(r, w, question, func(input string) (int, error) {
+		return parseSelectionNumber(input, numbers)
+	})
+}
+
+// SelectMultipleWithPrompt is the number-based selection UI for selecting several items
+// The answer is a comma-separated list of numbers and ranges, e.g. "1,3-5". The indexes are
+// returned in ascending order, without duplicates.
+func SelectMultipleWithPrompt(r io.Reader, w io.Writer, items []string, opts SelectOptions) ([]int, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items to select from")
 	}
-	fmt.Fprintf(os.Stderr, "\nSelect number (1-%d, or q to quit): ", len(items))
 
-	// Read user input
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return -1, fmt.Errorf("failed to read input: %w", err)
+	// Auto-select if only one item
+	if len(items) == 1 {
+		return []int{0}, nil
 	}
 
-	input = strings.TrimSpace(input)
+	// Display items with numbers
+	numbers, indexed := itemNumbers(items, opts)
+	printNumberedItems(w, items, numbers, indexed, opts)
+
+	first, second, last := numbers[0], numbers[1], numbers[len(numbers)-1]
+	question := fmt.Sprintf("Select numbers (%s, e.g. %d,%d or %d-%d, or q to quit): ", describeNumbers(numbers), first, second, first, last)
+	return askUntilValid(r, w, question, func(input string) ([]int, error) {
+		return parseSelectionList(input, numbers)
+	})
+}
+
+// askUntilValid writes question to w until parse accepts the answer read from r
+// Invalid answers are reported and asked again, up to MaxPromptAttempts times; "q" or the end
+// of input returns a CancelledError.
+func askUntilValid[T any](r io.Reader, w io.Writer, question string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	for attempt := 1; ; attempt++ {
+		fmt.Fprintf(w, "\n%s", question)
+
+		// Read user input
+		input, err := ReadLine(r)
+		if errors.Is(err, io.EOF) && input == "" {
+			fmt.Fprintln(w)
+			return zero, &CancelledError{}
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return zero, fmt.Errorf("failed to read input: %w", err)
+		}
 
-	// Check for cancellation
-	if input == "q" || input == "Q" || input == "" {
-		return -1, fmt.Errorf("selection cancelled")
+		input = strings.TrimSpace(input)
+
+		// Check for cancellation
+		if input == "q" || input == "Q" {
+			return zero, &CancelledError{}
+		}
+
+		value, err := parse(input)
+		if err == nil {
+			return value, nil
+		}
+		if attempt == MaxPromptAttempts {
+			return zero, err
+		}
+		fmt.Fprintf(w, "%v\n", err)
 	}
+}
 
-	// Convert to number
+// itemNumbers returns the number each item is selected by and whether these are the items' own indexes
+// With opts.Indexed, the index of each item is used; otherwise, or if an item has none, 1..N.
+func itemNumbers(items []string, opts SelectOptions) ([]int, bool) {
+	numbers := make([]int, len(items))
+	for i := range items {
+		numbers[i] = i + 1
+	}
+	if !opts.Indexed {
+		return numbers, false
+	}
+
+	indexes := make([]int, len(items))
+	for i, item := range items {
+		index, _, ok := SplitIndex(item)
+		if !ok {
+			return numbers, false
+		}
+		indexes[i] = index
+	}
+	return indexes, true
+}
+
+// describeNumbers formats the selectable numbers for a prompt, e.g. "1-3" or "0, 2, 5"
+func describeNumbers(numbers []int) string {
+	contiguous := true
+	for i := 1; i < len(numbers); i++ {
+		if numbers[i] != numbers[i-1]+1 {
+			contiguous = false
+			break
+		}
+	}
+	if contiguous {
+		return fmt.Sprintf("%d-%d", numbers[0], numbers[len(numbers)-1])
+	}
+	parts := make([]string, len(numbers))
+	for i, num := range numbers {
+		parts[i] = strconv.Itoa(num)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseSelectionNumber converts an answer to the position of the item with that number
+func parseSelectionNumber(input string, numbers []int) (int, error) {
 	num, err := strconv.Atoi(input)
 	if err != nil {
-		return -1, fmt.Errorf("invalid input: %s", input)
+		return 0, fmt.Errorf("invalid input: %q", input)
+	}
+	for i, n := range numbers {
+		if n == num {
+			return i, nil
+		}
 	}
+	return 0, fmt.Errorf("number out of range: %d (expected %s)", num, describeNumbers(numbers))
+}
+
+// parseSelectionList converts an answer such as "1,3-5" to sorted, unique item positions
+// Reversed ranges ("5-3") are accepted; both ends of a range must be numbers in the list.
+func parseSelectionList(input string, numbers []int) ([]int, error) {
+	selected := make(map[int]bool)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+
+		first, last := part, part
+		if before, after, found := strings.Cut(part, "-"); found && before != "" {
+			first, last = strings.TrimSpace(before), strings.TrimSpace(after)
+		}
 
-	// Validate range
-	if num < 1 || num > len(items) {
-		return -1, fmt.Errorf("number out of range: %d (expected 1-%d)", num, len(items))
+		start, err := parseSelectionNumber(first, numbers)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseSelectionNumber(last, numbers)
+		if err != nil {
+			return nil, err
+		}
+		low, high := numbers[start], numbers[end]
+		if low > high {
+			low, high = high, low
+		}
+		for i, num := range numbers {
+			if num >= low && num <= high {
+				selected[i] = true
+			}
+		}
 	}
 
-	return num - 1, nil
+	indexes := make([]int, 0, len(selected))
+	for index := range selected {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// printNumberedItems writes the prompt and items as a numbered list
+// Tab-separated columns (e.g. branch and path) are padded so that they line up. Indexed items
+// already show their number, e.g. "[2]", so they aren't numbered again.
+func printNumberedItems(w io.Writer, items []string, numbers []int, indexed bool, opts SelectOptions) {
+	if opts.InitialQuery != "" {
+		fmt.Fprintf(w, "%s (matching %q):\n", opts.Prompt, opts.InitialQuery)
+	} else {
+		fmt.Fprintf(w, "%s:\n", opts.Prompt)
+	}
+
+	width := len(strconv.Itoa(len(items)))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, item := range items {
+		if indexed {
+			fmt.Fprintf(tw, "  %s\n", item)
+			continue
+		}
+		fmt.Fprintf(tw, "  %*d) %s\n", width, numbers[i], item)
+	}
+	tw.Flush()
+}
+
+// ReadLine reads a single line from r without the trailing newline
+// Unlike a bufio.Reader, it never reads past the newline, so that several prompts can share r.
+// The error is io.EOF if r ends before a newline, possibly after a partial line.
+func ReadLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
 }
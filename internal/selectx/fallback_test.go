@@ -0,0 +1,288 @@
+package selectx_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestSelectWithPrompt(t *testing.T) {
+	items := []string{"main\t/work/myproject", "feature\t/work/.myproject-wt/feature", "fix\t/work/.myproject-wt/fix"}
+
+	tests := []struct {
+		name       string
+		input      string
+		want       int
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:  "valid number",
+			input: "2\n",
+			want:  1,
+		},
+		{
+			name:  "surrounding whitespace and CRLF",
+			input: " 3 \r\n",
+			want:  2,
+		},
+		{
+			name:  "answer without newline before EOF",
+			input: "1",
+			want:  0,
+		},
+		{
+			name:       "retry after invalid input",
+			input:      "abc\n3\n",
+			want:       2,
+			wantOutput: []string{`invalid input: "abc"`},
+		},
+		{
+			name:       "retry after out of range",
+			input:      "0\n4\n1\n",
+			want:       0,
+			wantOutput: []string{"number out of range: 0 (expected 1-3)", "number out of range: 4 (expected 1-3)"},
+		},
+		{
+			name:       "retry after empty input",
+			input:      "\n2\n",
+			want:       1,
+			wantOutput: []string{`invalid input: ""`},
+		},
+		{
+			name:    "too many invalid inputs",
+			input:   "x\n9\n-1\n1\n",
+			wantErr: "number out of range: -1 (expected 1-3)",
+		},
+		{
+			name:    "q cancels",
+			input:   "q\n",
+			wantErr: "selection cancelled",
+		},
+		{
+			name:    "Q cancels after invalid input",
+			input:   "x\nQ\n",
+			wantErr: "selection cancelled",
+		},
+		{
+			name:    "EOF cancels",
+			input:   "",
+			wantErr: "selection cancelled",
+		},
+		{
+			name:    "EOF after invalid input cancels",
+			input:   "x\n",
+			wantErr: "selection cancelled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := selectx.SelectWithPrompt(strings.NewReader(tt.input), &out, items, selectx.SelectOptions{Prompt: "Select worktree"})
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("SelectWithPrompt() error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("SelectWithPrompt() returned error: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("SelectWithPrompt() = %d, want %d", got, tt.want)
+				}
+			}
+
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("SelectWithPrompt() output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestSelectWithPromptAlignsColumns(t *testing.T) {
+	items := make([]string, 10)
+	for i := range items {
+		items[i] = "b\t/work/b"
+	}
+	items[0] = "main\t/work/myproject"
+	items[9] = "feature/login\t/work/.myproject-wt/feature-login"
+
+	var out bytes.Buffer
+	if _, err := selectx.SelectWithPrompt(strings.NewReader("1\n"), &out, items, selectx.SelectOptions{Prompt: "Select worktree"}); err != nil {
+		t.Fatalf("SelectWithPrompt() returned error: %v", err)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	want := []string{
+		"Select worktree:",
+		"   1) main           /work/myproject",
+		"   2) b              /work/b",
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Errorf("line %d = %q, want %q", i, lines[i], w)
+		}
+	}
+	if wantLast := "  10) feature/login  /work/.myproject-wt/feature-login"; lines[10] != wantLast {
+		t.Errorf("line 10 = %q, want %q", lines[10], wantLast)
+	}
+}
+
+func TestSelectWithPromptSingleItem(t *testing.T) {
+	var out bytes.Buffer
+	got, err := selectx.SelectWithPrompt(strings.NewReader(""), &out, []string{"main\t/work/myproject"}, selectx.SelectOptions{Prompt: "Select worktree"})
+	if err != nil || got != 0 {
+		t.Errorf("SelectWithPrompt() = %d, %v, want 0 without prompting", got, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("SelectWithPrompt() output = %q, want none", out.String())
+	}
+
+	if _, err := selectx.SelectWithPrompt(strings.NewReader(""), &out, nil, selectx.SelectOptions{Prompt: "Select worktree"}); err == nil {
+		t.Error("SelectWithPrompt() with no items should return error")
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	r := strings.NewReader("first\r\nsecond\nthird")
+
+	for _, want := range []string{"first", "second"} {
+		got, err := selectx.ReadLine(r)
+		if err != nil || got != want {
+			t.Errorf("ReadLine() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	got, err := selectx.ReadLine(r)
+	if err != io.EOF || got != "third" {
+		t.Errorf("ReadLine() = %q, %v, want %q, io.EOF", got, err, "third")
+	}
+}
+
+func TestSelectMultipleWithPrompt(t *testing.T) {
+	items := []string{"a\t/a", "b\t/b", "c\t/c", "d\t/d", "e\t/e"}
+
+	tests := []struct {
+		name          string
+		input         string
+		want          []int
+		wantErr       string
+		wantCancelled bool
+	}{
+		{name: "single number", input: "2\n", want: []int{1}},
+		{name: "comma list", input: "1,3\n", want: []int{0, 2}},
+		{name: "range", input: "3-5\n", want: []int{2, 3, 4}},
+		{name: "list and range", input: "1,3-4\n", want: []int{0, 2, 3}},
+		{name: "spaces", input: " 1 , 2 - 3 \n", want: []int{0, 1, 2}},
+		{name: "reversed range", input: "4-2\n", want: []int{1, 2, 3}},
+		{name: "single-number range", input: "3-3\n", want: []int{2}},
+		{name: "duplicates", input: "2,2,1-3,3\n", want: []int{0, 1, 2}},
+		{name: "sorted", input: "5,1\n", want: []int{0, 4}},
+		{name: "retry after out of range", input: "1-6\n1\n", want: []int{0}},
+		{name: "retry after empty element", input: "1,,2\n2\n", want: []int{1}},
+		{
+			name:    "out of range",
+			input:   "0\n6\n4-9\n",
+			wantErr: "number out of range: 9 (expected 1-5)",
+		},
+		{
+			name:    "negative number",
+			input:   "-1\n-1\n-1\n",
+			wantErr: "number out of range: -1 (expected 1-5)",
+		},
+		{
+			name:    "incomplete range",
+			input:   "1-\n2-\n3-\n",
+			wantErr: `invalid input: ""`,
+		},
+		{name: "q cancels", input: "q\n", wantCancelled: true},
+		{name: "EOF cancels", input: "", wantCancelled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := selectx.SelectMultipleWithPrompt(strings.NewReader(tt.input), &out, items, selectx.SelectOptions{Prompt: "Select worktrees"})
+
+			switch {
+			case tt.wantCancelled:
+				var cancelled *selectx.CancelledError
+				if !errors.As(err, &cancelled) {
+					t.Errorf("SelectMultipleWithPrompt() error = %v, want CancelledError", err)
+				}
+			case tt.wantErr != "":
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("SelectMultipleWithPrompt() error = %v, want %q", err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("SelectMultipleWithPrompt() returned error: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("SelectMultipleWithPrompt(%q) = %v, want %v", tt.input, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectMultipleWithPromptSingleItem(t *testing.T) {
+	var out bytes.Buffer
+	got, err := selectx.SelectMultipleWithPrompt(strings.NewReader(""), &out, []string{"a\t/a"}, selectx.SelectOptions{Prompt: "Select worktrees"})
+	if err != nil || !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("SelectMultipleWithPrompt() = %v, %v, want [0] without prompting", got, err)
+	}
+}
+
+func TestSelectWithPromptShowsQuery(t *testing.T) {
+	items := []string{"feature-a\t/a", "feature-b\t/b"}
+
+	var out bytes.Buffer
+	opts := selectx.SelectOptions{Prompt: "Select worktree", InitialQuery: "feat"}
+	if _, err := selectx.SelectWithPrompt(strings.NewReader("1\n"), &out, items, opts); err != nil {
+		t.Fatalf("SelectWithPrompt() returned error: %v", err)
+	}
+	if want := "Select worktree (matching \"feat\"):\n"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("SelectWithPrompt() output = %q, want prefix %q", out.String(), want)
+	}
+}
+
+func TestSelectWithPromptIndexed(t *testing.T) {
+	items := []string{
+		selectx.IndexedItem(0, "main\t/work/myproject"),
+		selectx.IndexedItem(2, "fix\t/work/.myproject-wt/fix"),
+		selectx.IndexedItem(3, "docs\t/work/.myproject-wt/docs"),
+	}
+	opts := selectx.SelectOptions{Prompt: "Select worktree", Indexed: true}
+
+	var out bytes.Buffer
+	got, err := selectx.SelectWithPrompt(strings.NewReader("1\n2\n"), &out, items, opts)
+	if err != nil {
+		t.Fatalf("SelectWithPrompt() returned error: %v", err)
+	}
+	if got != 1 {
+		t.Errorf("SelectWithPrompt() = %d, want 1 (the item with index 2)", got)
+	}
+	for _, want := range []string{"  [2]  fix ", "Select number (0, 2, 3, or q to quit)", "number out of range: 1 (expected 0, 2, 3)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("SelectWithPrompt() output = %q, want it to contain %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "1) ") {
+		t.Errorf("SelectWithPrompt() output = %q, indexed items should not be numbered again", out.String())
+	}
+
+	selected, err := selectx.SelectMultipleWithPrompt(strings.NewReader("0-2\n"), &out, items, opts)
+	if err != nil || !reflect.DeepEqual(selected, []int{0, 1}) {
+		t.Errorf("SelectMultipleWithPrompt() = %v, %v, want [0 1]", selected, err)
+	}
+}
@@ -2,7 +2,11 @@ package selectx
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // FilterItem represents an item with a score for filtering
@@ -12,8 +16,27 @@ type FilterItem struct {
 	Score int
 }
 
-// FilterByQuery filters items by a query string using simple substring matching
-func FilterByQuery(items []string, query string) ([]FilterItem, error) {
+// Scores of the match tiers; fuzzy matches score between 1 and maxFuzzyScore
+const (
+	scoreExact     = 100
+	scorePrefix    = 80
+	scoreSubstring = 50
+	maxFuzzyScore  = scoreSubstring - 1
+)
+
+// Weights of the fuzzy subsequence scorer
+const (
+	fuzzyMatch       = 16 // Each matched character
+	fuzzyConsecutive = 8  // Character directly follows the previous match
+	fuzzyBoundary    = 8  // Character starts a word (after a separator or at a camelCase hump)
+	fuzzyGap         = 1  // Each character skipped between two matches
+)
+
+// FilterByQuery filters items by a query string, best matches first
+// Items are matched case-insensitively as an exact, prefix or substring match. If fuzzy is true and
+// none match, items containing the query as a subsequence ("falogin" in "feature-auth-login") are
+// returned instead, ranked by how well the characters line up.
+func FilterByQuery(items []string, query string, fuzzy bool) ([]FilterItem, error) {
 	if query == "" {
 		// Return all items when query is empty
 		result := make([]FilterItem, len(items))
@@ -31,26 +54,142 @@ func FilterByQuery(items []string, query string) ([]FilterItem, error) {
 
 		// Exact match
 		if lowerItem == query {
-			matches = append(matches, FilterItem{Index: i, Text: item, Score: 100})
+			matches = append(matches, FilterItem{Index: i, Text: item, Score: scoreExact})
 			continue
 		}
 
 		// Prefix match
 		if strings.HasPrefix(lowerItem, query) {
-			matches = append(matches, FilterItem{Index: i, Text: item, Score: 80})
+			matches = append(matches, FilterItem{Index: i, Text: item, Score: scorePrefix})
 			continue
 		}
 
 		// Substring match
 		if strings.Contains(lowerItem, query) {
-			matches = append(matches, FilterItem{Index: i, Text: item, Score: 50})
+			matches = append(matches, FilterItem{Index: i, Text: item, Score: scoreSubstring})
 			continue
 		}
 	}
 
+	// Subsequence match, only when nothing matches more closely
+	if len(matches) == 0 && fuzzy {
+		for i, item := range items {
+			if score, ok := fuzzyScore(item, query); ok {
+				matches = append(matches, FilterItem{Index: i, Text: item, Score: score})
+			}
+		}
+	}
+
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("no matches found for query: %s", query)
 	}
 
+	// Stable, so equally good matches keep their list order
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches, nil
+}
+
+// FilterIndexedByQuery filters items made with IndexedItem like FilterByQuery, without matching their index column
+// A query that is a bare number selects the item with that index, if there is one. The returned
+// texts are the full items, including the index.
+func FilterIndexedByQuery(items []string, query string, fuzzy bool) ([]FilterItem, error) {
+	if index, err := strconv.Atoi(query); err == nil {
+		for i, item := range items {
+			if itemIndex, _, ok := SplitIndex(item); ok && itemIndex == index {
+				return []FilterItem{{Index: i, Text: item, Score: scoreExact}}, nil
+			}
+		}
+	}
+
+	texts := make([]string, len(items))
+	for i, item := range items {
+		_, texts[i], _ = SplitIndex(item)
+	}
+	matches, err := FilterByQuery(texts, query, fuzzy)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i].Text = items[matches[i].Index]
+	}
 	return matches, nil
 }
+
+// fuzzyScore scores the best alignment of query as a subsequence of item
+// query must be lowercase. The result is scaled to 1..maxFuzzyScore; ok is false if query isn't
+// a subsequence of item.
+func fuzzyScore(item, query string) (int, bool) {
+	text := []rune(item)
+	pattern := []rune(query)
+	if len(pattern) == 0 || len(pattern) > len(text) {
+		return 0, false
+	}
+
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// prev[j] is the best score of pattern[:i] with pattern[i-1] matched at text[j]
+	const none = math.MinInt / 2
+	prev := make([]int, len(text))
+	cur := make([]int, len(text))
+
+	for i, p := range pattern {
+		// gapped is the best score of a previous match at least two characters back, less the gap
+		gapped := none
+		for j := range text {
+			cur[j] = none
+			if lower[j] == p {
+				points := fuzzyMatch + boundaryBonus(text, j)
+				switch {
+				case i == 0:
+					cur[j] = points
+				case j > 0:
+					best := gapped
+					if prev[j-1] != none && prev[j-1]+fuzzyConsecutive > best {
+						best = prev[j-1] + fuzzyConsecutive
+					}
+					if best != none {
+						cur[j] = best + points
+					}
+				}
+			}
+			if i > 0 && j > 0 {
+				if g := max(gapped, prev[j-1]); g != none {
+					gapped = g - fuzzyGap
+				}
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	best := none
+	for _, score := range prev {
+		best = max(best, score)
+	}
+	if best == none {
+		return 0, false
+	}
+
+	perfect := len(pattern)*(fuzzyMatch+fuzzyBoundary) + (len(pattern)-1)*fuzzyConsecutive
+	return max(1, best*maxFuzzyScore/perfect), true
+}
+
+// boundaryBonus returns the bonus for a match at text[j] if it starts a word
+func boundaryBonus(text []rune, j int) int {
+	if j == 0 {
+		return fuzzyBoundary
+	}
+	prev, cur := text[j-1], text[j]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return fuzzyBoundary
+	}
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return fuzzyBoundary
+	}
+	return 0
+}
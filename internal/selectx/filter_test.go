@@ -0,0 +1,178 @@
+package selectx_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestFilterByQueryRanking(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		items []string
+		want  []string // Matching items, best first
+	}{
+		{
+			name:  "exact, prefix and substring tiers",
+			query: "Feature",
+			items: []string{"my-feature", "feature-login", "feature"},
+			want:  []string{"feature", "feature-login", "my-feature"},
+		},
+		{
+			name:  "substring matches hide subsequence matches",
+			query: "login",
+			items: []string{"l-o-g-i-n", "feature-login"},
+			want:  []string{"feature-login"},
+		},
+		{
+			name:  "subsequence across words",
+			query: "falogin",
+			items: []string{"main", "refactor/auth-login-flow", "feature-auth-login"},
+			want:  []string{"feature-auth-login", "refactor/auth-login-flow"},
+		},
+		{
+			name:  "word boundaries beat shorter gaps",
+			query: "fb",
+			items: []string{"xfxb", "fobar", "fooBar"},
+			want:  []string{"fooBar", "fobar", "xfxb"},
+		},
+		{
+			name:  "consecutive characters beat scattered ones",
+			query: "abc",
+			items: []string{"xaxbxc", "abxc"},
+			want:  []string{"abxc", "xaxbxc"},
+		},
+		{
+			name:  "case-insensitive",
+			query: "FAL",
+			items: []string{"Feature-Auth-Login"},
+			want:  []string{"Feature-Auth-Login"},
+		},
+		{
+			name:  "equal scores keep list order",
+			query: "fl",
+			items: []string{"fix-login", "fix-lint"},
+			want:  []string{"fix-login", "fix-lint"},
+		},
+		{
+			name:  "characters out of order don't match",
+			query: "nigol",
+			items: []string{"feature-auth-login", "nig-o-l"},
+			want:  []string{"nig-o-l"},
+		},
+		{
+			name:  "unicode",
+			query: "機能ログ",
+			items: []string{"feature/機能-ログイン"},
+			want:  []string{"feature/機能-ログイン"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := selectx.FilterByQuery(tt.items, tt.query, true)
+			if err != nil {
+				t.Fatalf("FilterByQuery() returned error: %v", err)
+			}
+
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Text)
+				if tt.items[m.Index] != m.Text {
+					t.Errorf("FilterByQuery() item %q has index %d, want the index of %q", m.Text, m.Index, tt.items[m.Index])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterByQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByQueryFuzzyScores(t *testing.T) {
+	items := []string{"feature-auth-login", "f-a-l-o-g-i-n", "xfxaxlxoxgxixn"}
+	matches, err := selectx.FilterByQuery(items, "falogin", true)
+	if err != nil {
+		t.Fatalf("FilterByQuery() returned error: %v", err)
+	}
+	if len(matches) != len(items) {
+		t.Fatalf("FilterByQuery() returned %d matches, want %d", len(matches), len(items))
+	}
+	for _, m := range matches {
+		// Fuzzy matches rank below substring matches (score 50)
+		if m.Score < 1 || m.Score >= 50 {
+			t.Errorf("FilterByQuery() score of %q = %d, want 1..49", m.Text, m.Score)
+		}
+	}
+}
+
+func TestFilterByQueryNoFuzzy(t *testing.T) {
+	if _, err := selectx.FilterByQuery([]string{"feature-auth-login"}, "falogin", false); err == nil {
+		t.Error("FilterByQuery() with fuzzy disabled should return error for a subsequence-only match")
+	}
+
+	matches, err := selectx.FilterByQuery([]string{"feature-auth-login"}, "auth", false)
+	if err != nil {
+		t.Fatalf("FilterByQuery() returned error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Score != 50 {
+		t.Errorf("FilterByQuery() = %+v, want one substring match", matches)
+	}
+}
+
+func TestFilterByQueryEmpty(t *testing.T) {
+	items := []string{"main", "feature"}
+	matches, err := selectx.FilterByQuery(items, "", true)
+	if err != nil {
+		t.Fatalf("FilterByQuery() returned error: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Index != 0 || matches[1].Index != 1 {
+		t.Errorf("FilterByQuery() = %+v, want all items in order", matches)
+	}
+}
+
+func TestFilterIndexedByQuery(t *testing.T) {
+	items := []string{
+		selectx.IndexedItem(0, "main\t/work/myproject"),
+		selectx.IndexedItem(1, "fix-2\t/work/.myproject-wt/fix-2"),
+		selectx.IndexedItem(2, "feature\t/work/.myproject-wt/feature"),
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "index", query: "2", want: []int{2}},
+		{name: "number that is no index matches text", query: "-2", want: []int{1}},
+		{name: "prefix ignores the index column", query: "fea", want: []int{2}},
+		{name: "brackets are not matched", query: "[0]", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := selectx.FilterIndexedByQuery(items, tt.query, false)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("FilterIndexedByQuery(%q) = %+v, want error", tt.query, matches)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FilterIndexedByQuery(%q) returned error: %v", tt.query, err)
+			}
+			var got []int
+			for _, m := range matches {
+				got = append(got, m.Index)
+				if m.Text != items[m.Index] {
+					t.Errorf("FilterIndexedByQuery(%q) text = %q, want the full item %q", tt.query, m.Text, items[m.Index])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterIndexedByQuery(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,62 +3,225 @@ package selectx
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-// IsFzfAvailable checks if fzf is installed
-func IsFzfAvailable() bool {
-	_, err := exec.LookPath("fzf")
+// OptsEnv is the environment variable whose arguments are appended to the fuzzy finder command
+const OptsEnv = "WT_FZF_OPTS"
+
+// Selector describes the fuzzy finder used for interactive selection
+type Selector struct {
+	Binary    string   // Command name or path, e.g. "fzf", "sk" or "fzy"
+	ExtraArgs []string // Additional arguments (from selector.extra_args)
+}
+
+// DefaultSelector returns fzf with the default arguments
+func DefaultSelector() Selector {
+	return Selector{Binary: "fzf", ExtraArgs: []string{"--height=40%", "--reverse"}}
+}
+
+// IsSelectorAvailable checks if the selector's binary is installed
+func IsSelectorAvailable(s Selector) bool {
+	_, err := exec.LookPath(s.Binary)
 	return err == nil
 }
 
-// SelectWithFzf uses fzf to select from a list of items
-func SelectWithFzf(items []string, prompt string) (int, error) {
+// SelectOptions holds the settings of a single selection
+type SelectOptions struct {
+	Prompt       string // Shown before the search field (or above the numbered list)
+	InitialQuery string // Search query to start with, e.g. the query the items were filtered by
+	// Items start with a stable index (see IndexedItem), which the numbered list shows and
+	// accepts instead of numbering the items 1..N
+	Indexed bool
+}
+
+// IndexedItem prefixes text with a stable index column, e.g. "[2]\tfeature\t/path"
+// The index identifies the item across commands and filters (e.g. the position in 'git worktree list').
+func IndexedItem(index int, text string) string {
+	return "[" + strconv.Itoa(index) + "]\t" + text
+}
+
+// SplitIndex returns the index of an item made with IndexedItem and the text after it
+func SplitIndex(item string) (int, string, bool) {
+	prefix, text, found := strings.Cut(item, "\t")
+	if !found || !strings.HasPrefix(prefix, "[") || !strings.HasSuffix(prefix, "]") {
+		return 0, item, false
+	}
+	index, err := strconv.Atoi(prefix[1 : len(prefix)-1])
+	if err != nil || index < 0 {
+		return 0, item, false
+	}
+	return index, text, true
+}
+
+// CancelledError represents an error when the user cancels a selection: the fuzzy finder
+// exited with 130 (Esc or Ctrl-C) or selected nothing, or "q" or the end of input in the numbered list
+type CancelledError struct{}
+
+func (e *CancelledError) Error() string {
+	return "selection cancelled"
+}
+
+// SelectWith uses the selector's fuzzy finder to select from a list of items
+// For fzf-compatible finders (fzf, sk), each line is prefixed with its index, which is hidden
+// with --with-nth and parsed back from the selection.
+func SelectWith(s Selector, items []string, opts SelectOptions) (int, error) {
+	output, err := runSelector(s, items, opts, false)
+	if err != nil {
+		return -1, err
+	}
+
+	if isFzfCompatible(s.Binary) {
+		if index, err := parseIndexedSelection(output, len(items)); err == nil {
+			return index, nil
+		}
+		// A wrapper script or user arguments may print the item without its index; match the text instead
+	}
+	return findSelection(output, items)
+}
+
+// SelectMultipleWith uses the selector's fuzzy finder to select any number of items
+// fzf-compatible finders are run with --multi (Tab marks items); other finders select a single item.
+// The indexes are returned in the order the finder prints them, without duplicates.
+func SelectMultipleWith(s Selector, items []string, opts SelectOptions) ([]int, error) {
+	output, err := runSelector(s, items, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, line := range strings.Split(output, "\n") {
+		index := -1
+		if isFzfCompatible(s.Binary) {
+			index, _ = parseIndexedSelection(line, len(items))
+		}
+		if index < 0 {
+			index, _ = findSelection(line, items)
+		}
+		if index >= 0 && !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("selected item not found in list")
+	}
+	return indexes, nil
+}
+
+// runSelector runs the fuzzy finder on items and returns its trimmed output
+func runSelector(s Selector, items []string, opts SelectOptions, multi bool) (string, error) {
 	if len(items) == 0 {
-		return -1, fmt.Errorf("no items to select from")
+		return "", fmt.Errorf("no items to select from")
 	}
 
-	// Build fzf command
-	cmd := exec.Command("fzf",
-		"--height=40%",
-		"--reverse",
-		"--prompt="+prompt+"> ",
-		"--select-1", // Auto-select if only one item
-	)
+	indexed := isFzfCompatible(s.Binary)
+	cmd := exec.Command(s.Binary, s.Args(opts, multi)...)
 
 	// Pass items to stdin
-	cmd.Stdin = bytes.NewBufferString(strings.Join(items, "\n"))
+	lines := items
+	if indexed {
+		lines = make([]string, len(items))
+		for i, item := range items {
+			lines[i] = strconv.Itoa(i) + "\t" + item
+		}
+	}
+	cmd.Stdin = bytes.NewBufferString(strings.Join(lines, "\n"))
 
 	// Capture stdout and stderr
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// Run fzf
+	// Run the fuzzy finder
 	err := cmd.Run()
 	if err != nil {
 		// User cancelled (exit code 130)
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() == 130 {
-				return -1, fmt.Errorf("selection cancelled")
+				return "", &CancelledError{}
 			}
 		}
-		return -1, fmt.Errorf("fzf failed: %w: %s", err, stderr.String())
+		return "", fmt.Errorf("%s failed: %w: %s", s.Binary, err, stderr.String())
 	}
 
-	// Get selected item
-	selected := strings.TrimSpace(stdout.String())
-	if selected == "" {
-		return -1, fmt.Errorf("no selection made")
+	// Get selected items
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return "", &CancelledError{}
 	}
+	return output, nil
+}
 
-	// Find index of selected item
-	for i, item := range items {
-		if item == selected {
-			return i, nil
+// isFzfCompatible reports whether binary accepts fzf's field options (--delimiter, --with-nth)
+func isFzfCompatible(binary string) bool {
+	switch strings.TrimSuffix(filepath.Base(binary), ".exe") {
+	case "fzf", "sk":
+		return true
+	}
+	return false
+}
+
+// Args returns the command-line arguments the selector is run with
+// wt's own arguments come first, so that selector.extra_args and WT_FZF_OPTS can override them.
+func (s Selector) Args(opts SelectOptions, multi bool) []string {
+	var args []string
+	switch {
+	case isFzfCompatible(s.Binary):
+		args = []string{
+			"--prompt=" + opts.Prompt + "> ",
+			"--select-1",     // Auto-select if only one item
+			"--delimiter=\t", // Hide the index prefix from display and matching
+			"--with-nth=2..",
+		}
+		if multi {
+			args = append(args, "--multi")
+		}
+		if opts.InitialQuery != "" {
+			args = append(args, "--query="+opts.InitialQuery)
+		}
+	case strings.TrimSuffix(filepath.Base(s.Binary), ".exe") == "fzy":
+		args = []string{"--prompt=" + opts.Prompt + "> "}
+		if opts.InitialQuery != "" {
+			args = append(args, "--query="+opts.InitialQuery)
 		}
 	}
 
+	args = append(args, s.ExtraArgs...)
+	return append(args, strings.Fields(os.Getenv(OptsEnv))...)
+}
+
+// parseIndexedSelection returns the index prefixed to the selected line
+// Lines that don't carry an index (e.g. from --print-query or --expect) are skipped.
+func parseIndexedSelection(output string, count int) (int, error) {
+	for _, line := range strings.Split(output, "\n") {
+		prefix, _, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		index, err := strconv.Atoi(prefix)
+		if err != nil || index < 0 || index >= count {
+			continue
+		}
+		return index, nil
+	}
+	return -1, fmt.Errorf("selected item not found in list")
+}
+
+// findSelection returns the index of the item equal to the selected line
+func findSelection(output string, items []string) (int, error) {
+	for _, line := range strings.Split(output, "\n") {
+		for i, item := range items {
+			if item == line {
+				return i, nil
+			}
+		}
+	}
 	return -1, fmt.Errorf("selected item not found in list")
 }
@@ -0,0 +1,237 @@
+package selectx_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+// fakeSelector writes a shell script named binary that prints the output of script for its stdin
+func fakeSelector(t *testing.T, binary, script string) selectx.Selector {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake selector requires a POSIX shell")
+	}
+	t.Setenv(selectx.OptsEnv, "")
+
+	path := filepath.Join(t.TempDir(), binary)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake selector: %v", err)
+	}
+	return selectx.Selector{Binary: path}
+}
+
+func TestSelectWithDuplicateItems(t *testing.T) {
+	items := []string{
+		"feature\t/work/.myproject-wt/feature",
+		"main\t/work/myproject",
+		"feature\t/work/.myproject-wt/feature",
+	}
+
+	tests := []struct {
+		name   string
+		binary string
+		script string
+		want   int
+	}{
+		{
+			name:   "fzf returns the second occurrence",
+			binary: "fzf",
+			script: "sed -n 3p",
+			want:   2,
+		},
+		{
+			name:   "sk returns the first occurrence",
+			binary: "sk",
+			script: "sed -n 1p",
+			want:   0,
+		},
+		{
+			name:   "extra output lines without an index are skipped",
+			binary: "fzf",
+			script: "echo query; sed -n 3p",
+			want:   2,
+		},
+		{
+			name:   "falls back to text comparison without an index",
+			binary: "fzf",
+			script: "sed -n 3p | cut -f2-",
+			want:   0,
+		},
+		{
+			name:   "finder without index support",
+			binary: "fzy",
+			script: "sed -n 2p",
+			want:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSelector(t, tt.binary, tt.script)
+			got, err := selectx.SelectWith(s, items, selectx.SelectOptions{Prompt: "Select worktree"})
+			if err != nil {
+				t.Fatalf("SelectWith() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SelectWith() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectWithErrors(t *testing.T) {
+	items := []string{"main\t/work/myproject"}
+
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{name: "unknown item", script: "echo other"},
+		{name: "index out of range", script: "printf '5\\tmain\\t/work/other\\n'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSelector(t, "fzf", tt.script)
+			if got, err := selectx.SelectWith(s, items, selectx.SelectOptions{Prompt: "Select worktree"}); err == nil {
+				t.Errorf("SelectWith() = %d, want error", got)
+			}
+		})
+	}
+}
+
+func TestSelectMultipleWith(t *testing.T) {
+	items := []string{
+		"feature\t/work/.myproject-wt/feature",
+		"main\t/work/myproject",
+		"feature\t/work/.myproject-wt/feature",
+	}
+
+	tests := []struct {
+		name   string
+		binary string
+		script string
+		want   []int
+	}{
+		{
+			name:   "fzf gets --multi",
+			binary: "fzf",
+			script: `case " $* " in *" --multi "*) sed -n '2p;3p' ;; esac`,
+			want:   []int{1, 2},
+		},
+		{
+			name:   "selection order is kept",
+			binary: "sk",
+			script: `sed -n '3p;1p' | sort -r`,
+			want:   []int{2, 0},
+		},
+		{
+			name:   "repeated lines are returned once",
+			binary: "fzf",
+			script: `sed -n '1p;1p'`,
+			want:   []int{0},
+		},
+		{
+			name:   "finder without multi-select support",
+			binary: "fzy",
+			script: `case " $* " in *" --multi "*) exit 2 ;; esac; sed -n 2p`,
+			want:   []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSelector(t, tt.binary, tt.script)
+			got, err := selectx.SelectMultipleWith(s, items, selectx.SelectOptions{Prompt: "Select worktrees"})
+			if err != nil {
+				t.Fatalf("SelectMultipleWith() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectMultipleWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectWithCancelled(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{name: "esc", script: "exit 130"},
+		{name: "no selection", script: "cat >/dev/null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fakeSelector(t, "fzf", tt.script)
+
+			var cancelled *selectx.CancelledError
+			if _, err := selectx.SelectWith(s, []string{"a", "b"}, selectx.SelectOptions{Prompt: "Select worktree"}); !errors.As(err, &cancelled) {
+				t.Errorf("SelectWith() error = %v, want CancelledError", err)
+			}
+			if _, err := selectx.SelectMultipleWith(s, []string{"a", "b"}, selectx.SelectOptions{Prompt: "Select worktrees"}); !errors.As(err, &cancelled) {
+				t.Errorf("SelectMultipleWith() error = %v, want CancelledError", err)
+			}
+		})
+	}
+}
+
+func TestSelectorArgs(t *testing.T) {
+	t.Setenv(selectx.OptsEnv, "--border  --info=inline")
+
+	tests := []struct {
+		name  string
+		s     selectx.Selector
+		opts  selectx.SelectOptions
+		multi bool
+		want  []string
+	}{
+		{
+			name: "fzf",
+			s:    selectx.Selector{Binary: "fzf", ExtraArgs: []string{"--height=40%"}},
+			opts: selectx.SelectOptions{Prompt: "Select worktree"},
+			want: []string{"--prompt=Select worktree> ", "--select-1", "--delimiter=\t", "--with-nth=2..", "--height=40%", "--border", "--info=inline"},
+		},
+		{
+			name: "fzf with initial query",
+			s:    selectx.Selector{Binary: "/usr/local/bin/fzf"},
+			opts: selectx.SelectOptions{Prompt: "Select worktree", InitialQuery: "feat"},
+			want: []string{"--prompt=Select worktree> ", "--select-1", "--delimiter=\t", "--with-nth=2..", "--query=feat", "--border", "--info=inline"},
+		},
+		{
+			name:  "sk multi-select with initial query",
+			s:     selectx.Selector{Binary: "sk"},
+			opts:  selectx.SelectOptions{Prompt: "Select worktrees", InitialQuery: "fix login"},
+			multi: true,
+			want:  []string{"--prompt=Select worktrees> ", "--select-1", "--delimiter=\t", "--with-nth=2..", "--multi", "--query=fix login", "--border", "--info=inline"},
+		},
+		{
+			name:  "fzy",
+			s:     selectx.Selector{Binary: "fzy", ExtraArgs: []string{"--lines=20"}},
+			opts:  selectx.SelectOptions{Prompt: "Select worktree", InitialQuery: "feat"},
+			multi: true,
+			want:  []string{"--prompt=Select worktree> ", "--query=feat", "--lines=20", "--border", "--info=inline"},
+		},
+		{
+			name: "unknown finder gets only user arguments",
+			s:    selectx.Selector{Binary: "peco", ExtraArgs: []string{"--layout=bottom-up"}},
+			opts: selectx.SelectOptions{Prompt: "Select worktree", InitialQuery: "feat"},
+			want: []string{"--layout=bottom-up", "--border", "--info=inline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Args(tt.opts, tt.multi); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package selectx
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to limit candidates closest to query by edit distance, closest first
+// A candidate is compared both as a whole and by its last path segment ("login" of
+// "feature/login"), so that a short query finds the branch it abbreviates. Candidates
+// further away than a plausible typo aren't suggested.
+func Suggest(query string, candidates []string, limit int) []string {
+	type suggestion struct {
+		text string
+		dist int
+	}
+
+	maxDist := max(2, len(query)/3)
+	var found []suggestion
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		d := EditDistance(query, c)
+		if i := strings.LastIndex(c, "/"); i >= 0 {
+			d = min(d, EditDistance(query, c[i+1:]))
+		}
+		if d <= maxDist {
+			found = append(found, suggestion{text: c, dist: d})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	result := make([]string, len(found))
+	for i, s := range found {
+		result[i] = s.text
+	}
+	return result
+}
+
+// EditDistance computes the Levenshtein distance between a and b
+func EditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,52 @@
+package selectx_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toritori0318/git-wt/internal/selectx"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"main", "feature/login", "feature/logout", "fix/log", "release/2.0"}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{name: "typo of a branch", query: "feature/logn", limit: 3, want: []string{"feature/login", "feature/logout"}},
+		{name: "typo of the last segment", query: "logn", limit: 3, want: []string{"feature/login", "fix/log"}},
+		{name: "limited", query: "logn", limit: 1, want: []string{"feature/login"}},
+		{name: "wildly different", query: "hotfix/payments", limit: 3, want: []string{}},
+		{name: "short query", query: "xyz", limit: 3, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectx.Suggest(tt.query, candidates, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"main", "main", 0},
+		{"logn", "login", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := selectx.EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
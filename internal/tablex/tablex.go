@@ -0,0 +1,229 @@
+// Package tablex renders rows of cells as an aligned table for terminal output
+//
+// Columns are aligned by display width, so that wide characters (e.g. CJK branch names) take two
+// cells like they do in a terminal. Without alignment, rows are printed as tab-separated values
+// for scripts.
+package tablex
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Color is the color of a cell on a terminal
+type Color int
+
+const (
+	NoColor Color = iota
+	Red
+	Green
+	Yellow
+	Dim
+)
+
+// sgr returns the ANSI escape sequence selecting c
+func (c Color) sgr() string {
+	switch c {
+	case Red:
+		return "\x1b[31m"
+	case Green:
+		return "\x1b[32m"
+	case Yellow:
+		return "\x1b[33m"
+	case Dim:
+		return "\x1b[2m"
+	default:
+		return ""
+	}
+}
+
+// ellipsis marks a truncated cell
+const ellipsis = "…"
+
+// Cell is a table cell
+type Cell struct {
+	Text  string
+	Color Color
+}
+
+// Options controls how a table is rendered
+type Options struct {
+	Align  bool   // Pad columns to align them; otherwise print tab-separated values
+	Color  bool   // Color cells (only when aligned)
+	Indent string // Printed before each aligned row
+	Gap    int    // Spaces between aligned columns (2 if zero)
+}
+
+// Table collects rows and renders them
+type Table struct {
+	opts     Options
+	rows     [][]Cell
+	maxWidth map[int]int
+}
+
+// New returns an empty table rendered with opts
+func New(opts Options) *Table {
+	if opts.Gap == 0 {
+		opts.Gap = 2
+	}
+	return &Table{opts: opts, maxWidth: make(map[int]int)}
+}
+
+// AddRow adds a row of uncolored cells
+func (t *Table) AddRow(cells ...string) {
+	row := make([]Cell, len(cells))
+	for i, text := range cells {
+		row[i] = Cell{Text: text}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// AddCells adds a row of cells
+func (t *Table) AddCells(cells ...Cell) {
+	t.rows = append(t.rows, cells)
+}
+
+// SetMaxWidth truncates the cells of column col (0-based) to width, ending them with "…"
+// It only applies to aligned tables: tab-separated values are never cut.
+func (t *Table) SetMaxWidth(col, width int) {
+	t.maxWidth[col] = width
+}
+
+// Render writes the table to w
+func (t *Table) Render(w io.Writer) error {
+	if !t.opts.Align {
+		return t.renderTSV(w)
+	}
+
+	rows := make([][]Cell, len(t.rows))
+	var widths []int
+	for i, row := range t.rows {
+		rows[i] = make([]Cell, len(row))
+		for j, c := range row {
+			c.Text = singleLine(c.Text)
+			if limit, ok := t.maxWidth[j]; ok {
+				c.Text = Truncate(c.Text, limit)
+			}
+			rows[i][j] = c
+			if j >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], Width(c.Text))
+		}
+	}
+
+	gap := strings.Repeat(" ", t.opts.Gap)
+	for _, row := range rows {
+		var b strings.Builder
+		b.WriteString(t.opts.Indent)
+		for j, c := range row {
+			if j > 0 {
+				b.WriteString(gap)
+			}
+			if t.opts.Color && c.Color != NoColor && c.Text != "" {
+				b.WriteString(c.Color.sgr() + c.Text + "\x1b[0m")
+			} else {
+				b.WriteString(c.Text)
+			}
+			if j < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[j]-Width(c.Text)))
+			}
+		}
+		// Empty trailing cells leave no padding behind
+		if _, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTSV writes the rows as tab-separated values, without indent or color
+func (t *Table) renderTSV(w io.Writer) error {
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for j, c := range row {
+			cells[j] = strings.ReplaceAll(singleLine(c.Text), "\t", " ")
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// singleLine replaces line breaks in s, which would break the rows apart
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// Truncate shortens s to at most width display cells, ending it with "…" if it was cut
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if used+rw > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += rw
+	}
+	return b.String() + ellipsis
+}
+
+// Width returns the number of terminal cells s takes
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the number of terminal cells r takes: 0 for combining marks, control
+// characters and the zero width space, 2 for East Asian wide and fullwidth characters, 1 otherwise
+func runeWidth(r rune) int {
+	switch {
+	case r == 0, unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.IsControl(r), r == '\u200b':
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters (and wide emoji)
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, Hangul compatibility, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs and emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x20000, 0x3FFFD}, // CJK unified ideographs extensions B and later
+}
+
+func isWide(r rune) bool {
+	for _, rg := range wideRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
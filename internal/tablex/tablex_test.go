@@ -0,0 +1,111 @@
+package tablex
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// checkGolden compares got with testdata/<name>.golden (rewritten with -update)
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// branchTable returns a table of worktrees with ASCII, CJK and Hangul branch names
+func branchTable(opts Options) *Table {
+	t := New(opts)
+	t.AddRow("main", "/src/proj", "clean")
+	t.AddRow("feature/ログイン画面", "/src/.proj-wt/feature/ログイン画面", "2 modified")
+	t.AddCells(Cell{Text: "fix/버그"}, Cell{Text: "/src/.proj-wt/fix/버그"}, Cell{Text: "locked", Color: Yellow})
+	t.AddRow("feature/a-very-long-branch-name-indeed", "/src/.proj-wt/feature/a-very-long-branch-name-indeed", "")
+	return t
+}
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		table func() *Table
+	}{
+		{name: "aligned", table: func() *Table { return branchTable(Options{Align: true, Indent: "  "}) }},
+		{name: "truncated", table: func() *Table {
+			table := branchTable(Options{Align: true})
+			table.SetMaxWidth(0, 16)
+			return table
+		}},
+		{name: "colored", table: func() *Table { return branchTable(Options{Align: true, Color: true}) }},
+		{name: "tsv", table: func() *Table {
+			table := branchTable(Options{Color: true, Indent: "  "})
+			table.SetMaxWidth(0, 16)
+			return table
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.table().Render(&buf); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			checkGolden(t, tt.name, buf.Bytes())
+		})
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"main", 4},
+		{"ログイン", 8},
+		{"버그", 4},
+		{"ｆｕｌｌ", 8},
+		{"é", 1}, // e and a combining acute accent
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"feature/login", 20, "feature/login"},
+		{"feature/login", 8, "feature…"},
+		{"ログイン画面", 7, "ログイ…"},
+		{"ログイン画面", 6, "ログ…"}, // A wide character doesn't fit in the last cell
+		{"main", 0, ""},
+	}
+
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.width)
+		if got != tt.want || Width(got) > tt.width {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
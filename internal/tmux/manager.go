@@ -1,11 +1,14 @@
 package tmux
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/toritori0318/git-wt/internal/logx"
 )
 
 // CommandExecutor defines the interface for executing commands
@@ -15,16 +18,31 @@ type CommandExecutor interface {
 }
 
 // defaultExecutor implements CommandExecutor using exec.Command
+// Commands are recorded in the debug log file (see logx).
 type defaultExecutor struct{}
 
 func (e *defaultExecutor) Run(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
-	return cmd.Run()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	logx.LogCommand(logx.Command{Name: name, Args: args, Duration: time.Since(start), Err: err, Stderr: stderr.String()})
+	return err
 }
 
 func (e *defaultExecutor) Output(name string, args ...string) ([]byte, error) {
 	cmd := exec.Command(name, args...)
-	return cmd.CombinedOutput()
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	entry := logx.Command{Name: name, Args: args, Duration: time.Since(start), Err: err}
+	if err != nil {
+		entry.Stderr = string(output)
+	}
+	logx.LogCommand(entry)
+	return output, err
 }
 
 // Manager manages tmux sessions
@@ -45,6 +63,7 @@ type SessionConfig struct {
 	Panes       []Pane
 	Layout      string // "tiled", "horizontal", "vertical"
 	SyncPanes   bool
+	Windows     bool // Open each pane in a window of its own (Layout and SyncPanes don't apply)
 	NoAttach    bool
 	Debug       bool // Enable debug logging
 }
@@ -68,7 +87,7 @@ func IsTmuxAvailable() bool {
 	return err == nil
 }
 
-// CreateSession creates a new tmux session with split panes
+// CreateSession creates a new tmux session with split panes (or windows, see SessionConfig.Windows)
 func (m *Manager) CreateSession(cfg SessionConfig) error {
 	if len(cfg.Panes) == 0 {
 		return fmt.Errorf("no panes to create session for")
@@ -102,6 +121,18 @@ func (m *Manager) CreateSession(cfg SessionConfig) error {
 		return fmt.Errorf("tmux session was not created after %d retries", maxRetries)
 	}
 
+	if cfg.Windows {
+		// A window for each remaining pane
+		for i := 1; i < len(cfg.Panes); i++ {
+			pane := cfg.Panes[i]
+			if err := m.executor.Run("tmux", "new-window", "-t", m.sessionName,
+				"-c", pane.WorktreePath, shell); err != nil {
+				return fmt.Errorf("failed to create window for pane %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
 	// Split window for remaining panes
 	for i := 1; i < len(cfg.Panes); i++ {
 		pane := cfg.Panes[i]
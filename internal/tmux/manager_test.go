@@ -367,3 +367,35 @@ func TestCreateSession_MockE2E(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateSession_Windows(t *testing.T) {
+	mockExec := &mockExecutor{}
+	m := NewManagerWithExecutor("test-session", mockExec)
+
+	cfg := SessionConfig{
+		Panes: []Pane{
+			{WorktreePath: "/tmp/pr-1", BranchName: "a"},
+			{WorktreePath: "/tmp/pr-2", BranchName: "b"},
+			{WorktreePath: "/tmp/pr-3", BranchName: "c"},
+		},
+		Layout:    "tiled",
+		SyncPanes: true,
+		Windows:   true,
+	}
+	if err := m.CreateSession(cfg); err != nil {
+		t.Fatalf("CreateSession() returned error: %v", err)
+	}
+
+	var windows []string
+	for _, call := range mockExec.runCalls {
+		switch call[1] {
+		case "new-window":
+			windows = append(windows, call[5])
+		case "split-window", "select-layout", "set-window-option":
+			t.Errorf("unexpected command with Windows: %v", call)
+		}
+	}
+	if !equalSlices(windows, []string{"/tmp/pr-2", "/tmp/pr-3"}) {
+		t.Errorf("expected windows for the remaining panes, got %v", windows)
+	}
+}
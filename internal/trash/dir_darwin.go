@@ -0,0 +1,22 @@
+//go:build darwin
+
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the trash shown by the Finder (~/.Trash)
+// The Finder keeps no info files there, so wt keeps them in ~/Library/Application Support/wt/trash-info.
+func Dir() (Location, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return Location{
+		FilesDir: filepath.Join(homeDir, ".Trash"),
+		InfoDir:  filepath.Join(homeDir, "Library", "Application Support", "wt", "trash-info"),
+	}, nil
+}
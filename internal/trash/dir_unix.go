@@ -0,0 +1,24 @@
+//go:build !windows && !darwin
+
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the home trash of the FreeDesktop.org Trash specification ($XDG_DATA_HOME/Trash,
+// or ~/.local/share/Trash)
+func Dir() (Location, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return Location{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	trash := filepath.Join(dataHome, "Trash")
+	return Location{FilesDir: filepath.Join(trash, "files"), InfoDir: filepath.Join(trash, "info")}, nil
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns a trash folder of wt (%LOCALAPPDATA%\wt\Trash)
+// Directories are not moved to the Recycle Bin: restoring them needs the original path, which only
+// the shell knows for Recycle Bin items.
+func Dir() (Location, error) {
+	localAppData, err := os.UserCacheDir()
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to get local application data directory: %w", err)
+	}
+	trash := filepath.Join(localAppData, "wt", "Trash")
+	return Location{FilesDir: filepath.Join(trash, "files"), InfoDir: filepath.Join(trash, "info")}, nil
+}
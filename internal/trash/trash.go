@@ -0,0 +1,230 @@
+// Package trash moves directories to the trash of the desktop and back
+//
+// Items follow the layout of the FreeDesktop.org Trash specification: the directory goes to a
+// "files" directory and a .trashinfo file next to it in an "info" directory records where it came
+// from. On Linux this is the home trash ($XDG_DATA_HOME/Trash) that file managers show. On macOS
+// directories go to ~/.Trash, with the info files kept by wt; on Windows both are kept in a wt
+// folder (see Dir).
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// infoSuffix is the extension of the files describing trashed items
+const infoSuffix = ".trashinfo"
+
+// dateFormat is the DeletionDate format of the specification (local time)
+const dateFormat = "2006-01-02T15:04:05"
+
+// Location is a trash: trashed directories go to FilesDir, their info files to InfoDir
+type Location struct {
+	FilesDir string
+	InfoDir  string
+}
+
+// Item is a directory in the trash
+type Item struct {
+	Name         string            // Unique name in the trash
+	Path         string            // Where the directory is now
+	OriginalPath string            // Where the directory was trashed from
+	DeletedAt    time.Time         // When it was trashed
+	Meta         map[string]string // Additional keys of the info file (e.g. "X-Wt-Branch")
+}
+
+// Move moves the directory at path to the trash, recording meta in its info file
+// Moving is a rename: a directory on another filesystem than the trash can't be trashed.
+func Move(path string, meta map[string]string) (*Item, error) {
+	loc, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return loc.Move(path, meta)
+}
+
+// List returns the items in the trash, oldest first
+func List() ([]Item, error) {
+	loc, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return loc.List()
+}
+
+// Restore moves item back to its original path, which must not exist
+func Restore(item Item) error {
+	loc, err := Dir()
+	if err != nil {
+		return err
+	}
+	return loc.Restore(item)
+}
+
+// Move moves the directory at path to the trash at loc (see Move)
+func (loc Location) Move(path string, meta map[string]string) (*Item, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{loc.FilesDir, loc.InfoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+
+	item := &Item{OriginalPath: path, DeletedAt: time.Now(), Meta: meta}
+	// The info file is created first and exclusively: it reserves the name
+	info, err := loc.reserveName(item)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := info.WriteString(formatInfo(item)); err != nil {
+		info.Close()
+		os.Remove(info.Name())
+		return nil, fmt.Errorf("failed to write trash info: %w", err)
+	}
+	info.Close()
+
+	if err := os.Rename(path, item.Path); err != nil {
+		os.Remove(info.Name())
+		return nil, fmt.Errorf("failed to move %s to the trash: %w", path, err)
+	}
+	return item, nil
+}
+
+// reserveName creates the info file of item under a free name, setting item.Name and item.Path
+func (loc Location) reserveName(item *Item) (*os.File, error) {
+	base := filepath.Base(item.OriginalPath)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d", base, i)
+		}
+		if _, err := os.Lstat(filepath.Join(loc.FilesDir, name)); err == nil {
+			continue
+		}
+		f, err := os.OpenFile(filepath.Join(loc.InfoDir, name+infoSuffix), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trash info: %w", err)
+		}
+		item.Name = name
+		item.Path = filepath.Join(loc.FilesDir, name)
+		return f, nil
+	}
+}
+
+// List returns the items in the trash at loc, oldest first
+// Info files that can't be read, or whose directory is gone (e.g. the trash was emptied), are skipped.
+func (loc Location) List() ([]Item, error) {
+	entries, err := os.ReadDir(loc.InfoDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), infoSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		item, err := readInfo(filepath.Join(loc.InfoDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		item.Name = name
+		item.Path = filepath.Join(loc.FilesDir, name)
+		if _, err := os.Lstat(item.Path); err != nil {
+			continue
+		}
+		items = append(items, *item)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.Before(items[j].DeletedAt) })
+	return items, nil
+}
+
+// Restore moves item back to its original path (see Restore)
+func (loc Location) Restore(item Item) error {
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return fmt.Errorf("cannot restore %s: the path already exists", item.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(item.OriginalPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(loc.FilesDir, item.Name), item.OriginalPath); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", item.OriginalPath, err)
+	}
+	// Without its directory the info file is stale; failing to remove it only leaves it behind
+	_ = os.Remove(filepath.Join(loc.InfoDir, item.Name+infoSuffix))
+	return nil
+}
+
+// formatInfo returns the contents of the info file of item
+func formatInfo(item *Item) string {
+	var b strings.Builder
+	b.WriteString("[Trash Info]\n")
+	fmt.Fprintf(&b, "Path=%s\n", (&url.URL{Path: filepath.ToSlash(item.OriginalPath)}).EscapedPath())
+	fmt.Fprintf(&b, "DeletionDate=%s\n", item.DeletedAt.Format(dateFormat))
+
+	keys := make([]string, 0, len(item.Meta))
+	for key := range item.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, url.PathEscape(item.Meta[key]))
+	}
+	return b.String()
+}
+
+// readInfo parses the info file at path
+func readInfo(path string) (*Item, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	item := &Item{Meta: make(map[string]string)}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue // The [Trash Info] header
+		}
+		switch key {
+		case "Path":
+			p, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Path in %s: %w", path, err)
+			}
+			item.OriginalPath = filepath.FromSlash(p)
+		case "DeletionDate":
+			item.DeletedAt, _ = time.ParseInLocation(dateFormat, value, time.Local)
+		default:
+			if v, err := url.PathUnescape(value); err == nil {
+				item.Meta[key] = v
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if item.OriginalPath == "" {
+		return nil, fmt.Errorf("no Path in %s", path)
+	}
+	return item, nil
+}
@@ -0,0 +1,135 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testLocation(t *testing.T) Location {
+	t.Helper()
+	trash := filepath.Join(t.TempDir(), "Trash")
+	return Location{FilesDir: filepath.Join(trash, "files"), InfoDir: filepath.Join(trash, "info")}
+}
+
+func makeDir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "file.txt"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMoveAndRestore(t *testing.T) {
+	loc := testLocation(t)
+	path := filepath.Join(t.TempDir(), "my feature")
+	makeDir(t, path)
+
+	item, err := loc.Move(path, map[string]string{"X-Wt-Branch": "feature/my feature"})
+	if err != nil {
+		t.Fatalf("Move() returned error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s should have been moved, stat error: %v", path, err)
+	}
+	if item.Path != filepath.Join(loc.FilesDir, "my feature") {
+		t.Errorf("item.Path = %s, want it in %s", item.Path, loc.FilesDir)
+	}
+
+	info, err := os.ReadFile(filepath.Join(loc.InfoDir, "my feature.trashinfo"))
+	if err != nil {
+		t.Fatalf("info file not written: %v", err)
+	}
+	for _, want := range []string{"[Trash Info]\n", "Path=" + filepath.ToSlash(filepath.Dir(path)) + "/my%20feature\n", "DeletionDate=", "X-Wt-Branch=feature%2Fmy%20feature\n"} {
+		if !strings.Contains(string(info), want) {
+			t.Errorf("info file should contain %q, got:\n%s", want, info)
+		}
+	}
+
+	items, err := loc.List()
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if len(items) != 1 || items[0].OriginalPath != path || items[0].Meta["X-Wt-Branch"] != "feature/my feature" {
+		t.Fatalf("List() = %+v, want the trashed directory", items)
+	}
+
+	if err := loc.Restore(items[0]); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "file.txt")); err != nil {
+		t.Errorf("directory was not restored: %v", err)
+	}
+	if items, _ := loc.List(); len(items) != 0 {
+		t.Errorf("List() after Restore() = %+v, want none", items)
+	}
+}
+
+func TestMoveNameCollision(t *testing.T) {
+	loc := testLocation(t)
+	first := filepath.Join(t.TempDir(), "feature")
+	second := filepath.Join(t.TempDir(), "feature")
+	makeDir(t, first)
+	makeDir(t, second)
+
+	if _, err := loc.Move(first, nil); err != nil {
+		t.Fatalf("Move() returned error: %v", err)
+	}
+	item, err := loc.Move(second, nil)
+	if err != nil {
+		t.Fatalf("Move() returned error: %v", err)
+	}
+	if item.Name != "feature.2" {
+		t.Errorf("second item name = %q, want feature.2", item.Name)
+	}
+
+	items, err := loc.List()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("List() = %+v, %v, want 2 items", items, err)
+	}
+	origins := map[string]bool{items[0].OriginalPath: true, items[1].OriginalPath: true}
+	if !origins[first] || !origins[second] {
+		t.Errorf("List() = %+v, want both directories", items)
+	}
+}
+
+func TestRestoreExistingPath(t *testing.T) {
+	loc := testLocation(t)
+	path := filepath.Join(t.TempDir(), "feature")
+	makeDir(t, path)
+
+	item, err := loc.Move(path, nil)
+	if err != nil {
+		t.Fatalf("Move() returned error: %v", err)
+	}
+	makeDir(t, path)
+
+	if err := loc.Restore(*item); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Restore() error = %v, want 'already exists'", err)
+	}
+	if _, err := os.Stat(item.Path); err != nil {
+		t.Errorf("item should still be in the trash: %v", err)
+	}
+}
+
+func TestListSkipsEmptiedItems(t *testing.T) {
+	loc := testLocation(t)
+	path := filepath.Join(t.TempDir(), "feature")
+	makeDir(t, path)
+
+	item, err := loc.Move(path, nil)
+	if err != nil {
+		t.Fatalf("Move() returned error: %v", err)
+	}
+	// Emptying the trash in a file manager may leave the info file
+	if err := os.RemoveAll(item.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if items, err := loc.List(); err != nil || len(items) != 0 {
+		t.Errorf("List() = %+v, %v, want none", items, err)
+	}
+}
@@ -0,0 +1,165 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DisableEnv is the environment variable that turns off the passive update check
+const DisableEnv = "WT_NO_UPDATE_CHECK"
+
+// CheckInterval is how often the passive update check contacts GitHub
+const CheckInterval = 24 * time.Hour
+
+// ReleasesURL is the GitHub API endpoint for the latest wt release (replaced in tests)
+var ReleasesURL = "https://api.github.com/repos/toritori0318/git-wt/releases/latest"
+
+// Release is a published wt release
+type Release struct {
+	Version string `json:"tag_name"` // e.g. "v1.2.3"
+	URL     string `json:"html_url"`
+}
+
+// Latest fetches the latest release from GitHub
+func Latest(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: GitHub returned %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release information: %w", err)
+	}
+	if release.Version == "" {
+		return nil, fmt.Errorf("failed to parse release information: no version")
+	}
+	return &release, nil
+}
+
+// IsNewer reports whether version latest is newer than current (both semver, "v" optional)
+// A current version that isn't semver (e.g. "dev") is never out of date.
+func IsNewer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l.core {
+		if l.core[i] != c.core[i] {
+			return l.core[i] > c.core[i]
+		}
+	}
+	// A release is newer than its pre-releases (1.2.0 > 1.2.0-rc.1)
+	return l.pre == "" && c.pre != ""
+}
+
+// IsVersion reports whether v is a semver release version (e.g. not "dev")
+func IsVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version (build metadata is ignored)
+type semver struct {
+	core [3]int
+	pre  string
+}
+
+func parseVersion(v string) (semver, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ := strings.Cut(v, "-")
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	var s semver
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		s.core[i] = n
+	}
+	s.pre = pre
+	return s, true
+}
+
+// state is the result of the last passive check, stored in the state directory
+type state struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    Release   `json:"latest"`
+}
+
+// StatePath returns the file the last update check is stored in
+// ($XDG_STATE_HOME/wt/update-check.json, or ~/.local/state/wt/update-check.json)
+func StatePath() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "wt", "update-check.json"), nil
+}
+
+// CheckDaily returns the latest release if it is newer than current and the last check is more
+// than CheckInterval ago, recording the check in the state directory
+// It is meant to run in the background of other commands: any failure (network, state file)
+// results in no release and no error, and it does nothing when WT_NO_UPDATE_CHECK is set.
+func CheckDaily(ctx context.Context, current string, now time.Time) *Release {
+	if os.Getenv(DisableEnv) != "" {
+		return nil
+	}
+	path, err := StatePath()
+	if err != nil {
+		return nil
+	}
+
+	var last state
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &last) == nil {
+		if now.Sub(last.CheckedAt) < CheckInterval {
+			return nil
+		}
+	}
+
+	// Record the attempt even if it fails so an unreachable GitHub is only tried once a day
+	release, err := Latest(ctx)
+	checked := state{CheckedAt: now}
+	if err == nil {
+		checked.Latest = *release
+	}
+	if data, err := json.Marshal(checked); err == nil && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		_ = os.WriteFile(path, data, 0644)
+	}
+
+	if err != nil || !IsNewer(release.Version, current) {
+		return nil
+	}
+	return release
+}
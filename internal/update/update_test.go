@@ -0,0 +1,161 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// serveRelease points ReleasesURL at a test server answering with body and status
+// It returns a pointer to the number of requests received.
+func serveRelease(t *testing.T, status int, body string) *int {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	orig := ReleasesURL
+	ReleasesURL = server.URL
+	t.Cleanup(func() { ReleasesURL = orig })
+	return &requests
+}
+
+const releaseBody = `{"tag_name": "v1.3.0", "html_url": "https://github.com/toritori0318/git-wt/releases/tag/v1.3.0"}`
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		latest  string
+		current string
+		want    bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"v1.3.0", "1.2.9", true},
+		{"v2.0.0", "v1.10.0", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.3.0", "v1.3.0", false},
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.3.0", "v1.3.0-rc.1", true},
+		{"v1.3.0-rc.1", "v1.3.0", false},
+		{"v1.3.0", "v1.3.0+dirty", false},
+		{"v1.3.0", "dev", false},
+		{"latest", "v1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	serveRelease(t, http.StatusOK, releaseBody)
+
+	release, err := Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest() returned error: %v", err)
+	}
+	if release.Version != "v1.3.0" || release.URL != "https://github.com/toritori0318/git-wt/releases/tag/v1.3.0" {
+		t.Errorf("Latest() = %+v", release)
+	}
+}
+
+func TestLatestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"http error", http.StatusForbidden, `{"message": "API rate limit exceeded"}`},
+		{"invalid json", http.StatusOK, `not json`},
+		{"no version", http.StatusOK, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveRelease(t, tt.status, tt.body)
+			if _, err := Latest(context.Background()); err == nil {
+				t.Error("Latest() should return an error")
+			}
+		})
+	}
+}
+
+func TestCheckDaily(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(DisableEnv, "")
+	requests := serveRelease(t, http.StatusOK, releaseBody)
+	now := time.Now()
+
+	release := CheckDaily(context.Background(), "v1.2.0", now)
+	if release == nil || release.Version != "v1.3.0" {
+		t.Fatalf("CheckDaily() = %+v, want v1.3.0", release)
+	}
+
+	// Within a day, GitHub isn't asked again
+	if release := CheckDaily(context.Background(), "v1.2.0", now.Add(time.Hour)); release != nil {
+		t.Errorf("CheckDaily() within a day = %+v, want nil", release)
+	}
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+
+	// A day later, it checks again
+	if release := CheckDaily(context.Background(), "v1.2.0", now.Add(25*time.Hour)); release == nil {
+		t.Error("CheckDaily() a day later should report the new version")
+	}
+	if *requests != 2 {
+		t.Errorf("requests = %d, want 2", *requests)
+	}
+}
+
+func TestCheckDailyUpToDate(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(DisableEnv, "")
+	serveRelease(t, http.StatusOK, releaseBody)
+
+	if release := CheckDaily(context.Background(), "v1.3.0", time.Now()); release != nil {
+		t.Errorf("CheckDaily() = %+v, want nil when up to date", release)
+	}
+}
+
+func TestCheckDailyFailureIsSilent(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	t.Setenv(DisableEnv, "")
+	requests := serveRelease(t, http.StatusInternalServerError, "")
+	now := time.Now()
+
+	if release := CheckDaily(context.Background(), "v1.2.0", now); release != nil {
+		t.Errorf("CheckDaily() = %+v, want nil on failure", release)
+	}
+	// The failed attempt counts as the day's check
+	if _, err := os.Stat(filepath.Join(stateHome, "wt", "update-check.json")); err != nil {
+		t.Errorf("state file not written: %v", err)
+	}
+	CheckDaily(context.Background(), "v1.2.0", now.Add(time.Hour))
+	if *requests != 1 {
+		t.Errorf("requests = %d, want 1", *requests)
+	}
+}
+
+func TestCheckDailyDisabled(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(DisableEnv, "1")
+	requests := serveRelease(t, http.StatusOK, releaseBody)
+
+	if release := CheckDaily(context.Background(), "v1.2.0", time.Now()); release != nil {
+		t.Errorf("CheckDaily() = %+v, want nil with %s set", release, DisableEnv)
+	}
+	if *requests != 0 {
+		t.Errorf("requests = %d, want 0", *requests)
+	}
+}
@@ -61,17 +61,5 @@ function wt
     end
 end
 
-# Completion configuration
-complete -c wt -f
-
-# Subcommand completion
-complete -c wt -n "__fish_use_subcommand" -a "new" -d "Create new worktree"
-complete -c wt -n "__fish_use_subcommand" -a "go" -d "Navigate between worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "clean" -d "Remove worktrees"
-complete -c wt -n "__fish_use_subcommand" -a "open" -d "Open worktree in editor"
-complete -c wt -n "__fish_use_subcommand" -a "pr" -d "Create worktree for PR review"
-complete -c wt -n "__fish_use_subcommand" -a "hook" -d "Output shell hook scripts"
-complete -c wt -n "__fish_use_subcommand" -a "help" -d "Show help"
-
-# Branch name completion for wt go
-complete -c wt -n "__fish_seen_subcommand_from go" -a "(git worktree list --porcelain 2>/dev/null | grep '^branch' | awk '{print \$2}' | sed 's|refs/heads/||')"
+# Completion configuration: the dynamic completion of "wt completion fish"
+command wt completion fish | source
@@ -67,51 +67,13 @@ function wt() {
   fi
 }
 
-# Completion configuration (optional)
+# Completion configuration (optional): the dynamic completion of "wt completion"
 # For bash
 if [[ -n "$BASH_VERSION" ]]; then
-  _wt_completion() {
-    local cur="${COMP_WORDS[COMP_CWORD]}"
-    local prev="${COMP_WORDS[COMP_CWORD-1]}"
-
-    # Subcommands
-    if [[ $COMP_CWORD -eq 1 ]]; then
-      COMPREPLY=($(compgen -W "new go clean open pr hook help" -- "$cur"))
-      return
-    fi
-
-    # Complete branch names for wt go
-    if [[ "${COMP_WORDS[1]}" == "go" ]] && [[ $COMP_CWORD -eq 2 ]]; then
-      local branches=$(git worktree list --porcelain 2>/dev/null | grep "^branch" | awk '{print $2}' | sed 's|refs/heads/||')
-      COMPREPLY=($(compgen -W "$branches" -- "$cur"))
-      return
-    fi
-  }
-  complete -F _wt_completion wt
+  eval "$(command wt completion bash)"
 fi
 
 # For zsh
 if [[ -n "$ZSH_VERSION" ]]; then
-  _wt() {
-    local -a subcmds
-    subcmds=(
-      'new:Create new worktree'
-      'go:Navigate between worktrees'
-      'clean:Remove worktrees'
-      'open:Open worktree in editor'
-      'pr:Create worktree for PR review'
-      'hook:Output shell hook scripts'
-      'help:Show help'
-    )
-
-    if (( CURRENT == 2 )); then
-      _describe 'wt commands' subcmds
-    elif (( CURRENT == 3 )) && [[ "${words[2]}" == "go" ]]; then
-      local -a branches
-      branches=(${(f)"$(git worktree list --porcelain 2>/dev/null | grep '^branch' | awk '{print $2}' | sed 's|refs/heads/||')"})
-      _describe 'branches' branches
-    fi
-  }
-
-  compdef _wt wt
+  eval "$(command wt completion zsh)"
 fi